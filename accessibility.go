@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/muesli/termenv"
+)
+
+// Bell modes for violations: the terminal bell, a screen flash, or nothing.
+const (
+	bellAudible = "audible"
+	bellVisual  = "visual"
+	bellOff     = "off"
+)
+
+// Output preferences. They start from the environment and are refined by
+// applyOutputPrefs once a check has resolved its config.
+var (
+	bellMode    = bellAudible
+	plainOutput = false
+)
+
+func init() {
+	if plainFromEnv() {
+		setPlainOutput()
+	}
+}
+
+// plainFromEnv auto-detects when plain output is wanted: SNAG_PLAIN=1, a
+// dumb terminal (Emacs shells, screen-reader consoles), or a desktop that
+// advertises assistive technology.
+func plainFromEnv() bool {
+	return os.Getenv("SNAG_PLAIN") == "1" || os.Getenv("TERM") == "dumb" || os.Getenv("ACCESSIBILITY_ENABLED") == "1"
+}
+
+// setPlainOutput switches to screen-reader-friendly output: no color, the
+// severity spelled out instead of implied by color, and ASCII punctuation.
+func setPlainOutput() {
+	plainOutput = true
+	renderer.SetColorProfile(termenv.Ascii)
+	stdoutRenderer.SetColorProfile(termenv.Ascii)
+}
+
+// applyOutputPrefs applies [notify] bell settings and [behavior]
+// plain_output from the resolved config.
+func applyOutputPrefs(bc *BlockConfig) {
+	switch {
+	case bc.BellOff:
+		bellMode = bellOff
+	case bc.VisualBell:
+		bellMode = bellVisual
+	}
+	if bc.PlainOutput && !plainOutput {
+		setPlainOutput()
+	}
+}
+
+// plainPunctuation maps the typographic characters snag prints to ASCII a
+// screen reader reads cleanly (or skips, rather than announcing "em dash").
+var plainPunctuation = strings.NewReplacer(
+	"—", "-", // em dash
+	"–", "-", // en dash
+	"→", "->",
+	"…", "...",
+	"•", "*",
+	"×", "x",
+	"✓", "ok",
+	"✗", "failed",
+)
+
+// plainText rewrites s for plain output; unchanged otherwise.
+func plainText(s string) string {
+	if !plainOutput {
+		return s
+	}
+	return plainPunctuation.Replace(s)
+}
+
+// fprintPlain is fmt.Fprintf through plainText, for report lines written
+// directly rather than through errorf and friends.
+func fprintPlain(w io.Writer, format string, a ...any) {
+	fmt.Fprint(w, plainText(fmt.Sprintf(format, a...)))
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlainOutput(t *testing.T) {
+	defer func(p bool, b string) { plainOutput, bellMode = p, b }(plainOutput, bellMode)
+
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[notify]\nbell = false\n\n[behavior]\nplain_output = true\n"), 0644)
+	orig, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(orig)
+	if _, err := resolveBlockConfig(buildRootCmd()); err != nil {
+		t.Fatal(err)
+	}
+	if !plainOutput || bellMode != bellOff {
+		t.Fatalf("plainOutput = %v, bellMode = %q", plainOutput, bellMode)
+	}
+
+	r, w, _ := os.Pipe()
+	oldStderr := os.Stderr
+	os.Stderr = w
+	errorf("push of %d commits blocked — see above", 3)
+	warnf("careful…")
+	w.Close()
+	os.Stderr = oldStderr
+	out, _ := io.ReadAll(r)
+
+	want := "snag: error: push of 3 commits blocked - see above\nsnag: warning: careful...\n"
+	if string(out) != want {
+		t.Errorf("plain output = %q, want %q", out, want)
+	}
+	if bytes.Contains(out, []byte("\x1b[")) {
+		t.Error("plain output contains ANSI escapes")
+	}
+}
+
+// Report lines printed directly, not through errorf and friends, get the
+// same ASCII punctuation.
+func TestPlainOutput_Reports(t *testing.T) {
+	defer func(p bool, b string) { plainOutput, bellMode = p, b }(plainOutput, bellMode)
+
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	commitFile(t, dir, "a.txt", "clean\n", "wip: later")
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[block]\nmsg = [\"wip\"]\n\n[behavior]\nplain_output = true\n"), 0644)
+	orig, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(orig)
+
+	r, w, _ := os.Pipe()
+	oldStdout := os.Stdout
+	os.Stdout = w
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"audit"})
+	err := rootCmd.Execute()
+	w.Close()
+	os.Stdout = oldStdout
+	out, _ := io.ReadAll(r)
+
+	if err == nil {
+		t.Fatal("expected a violation")
+	}
+	if !bytes.Contains(out, []byte(` - "wip: later"`)) || bytes.ContainsRune(out, '—') {
+		t.Errorf("audit report not plain:\n%s", out)
+	}
+}
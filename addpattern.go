@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// addPatternHooks are the [block] lists add-pattern can append to.
+var addPatternHooks = []string{"diff", "msg", "push", "branch"}
+
+func buildAddPatternCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add-pattern PATTERN...",
+		Short: "Add a block pattern to the shared or local config",
+		Long: `Append patterns to a [block] list without opening an editor.
+
+For the moment a check passes but you spot something that should have been
+blocked. The patterns go into the nearest snag.toml (--shared, committed,
+the whole team gets them) or the snag-local.toml beside it (--local,
+gitignored, just you). With neither flag snag asks, or fails when there is
+no terminal to ask on. The nearest file is found from the current directory
+up to the repository root; without one, the repository root is used.
+
+The edited file is validated before it is written, and the change is shown
+as a diff. Patterns already in the list are skipped.`,
+		Example: `  snag add-pattern INTERNAL_HOST --local
+  snag add-pattern "DO NOT MERGE" --hook msg --shared
+  snag add-pattern -n acme-corp --local     # show the diff, write nothing`,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE:         runAddPattern,
+	}
+	cmd.Flags().String("hook", "diff", "list to add to: "+strings.Join(addPatternHooks, ", "))
+	cmd.Flags().Bool("local", false, "add to snag-local.toml (personal, gitignored)")
+	cmd.Flags().Bool("shared", false, "add to snag.toml (committed, team-wide)")
+	cmd.Flags().BoolP("dry-run", "n", false, "show the diff without writing the file")
+	return cmd
+}
+
+// promptForPatternTarget asks whether new patterns are for the team or just
+// this developer. Returns "shared" or "local".
+var promptForPatternTarget = func(shared, local string) (string, error) {
+	fmt.Fprintln(os.Stderr, "Where should the pattern go?")
+	fprintPlain(os.Stderr, "  1) Shared config (%s) — checked in, whole team gets it\n", shared)
+	fprintPlain(os.Stderr, "  2) Local config (%s) — gitignored, just for you\n", local)
+	fmt.Fprint(os.Stderr, "Choice [1/2]: ")
+
+	scanner := bufio.NewScanner(os.Stdin)
+	if !scanner.Scan() {
+		return "", fmt.Errorf("prompt cancelled")
+	}
+	switch strings.TrimSpace(scanner.Text()) {
+	case "1", "shared":
+		return "shared", nil
+	case "2", "local":
+		return "local", nil
+	default:
+		return "", fmt.Errorf("invalid choice %q — expected 1 or 2", scanner.Text())
+	}
+}
+
+func runAddPattern(cmd *cobra.Command, args []string) error {
+	hook, _ := cmd.Flags().GetString("hook")
+	useLocal, _ := cmd.Flags().GetBool("local")
+	useShared, _ := cmd.Flags().GetBool("shared")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+
+	if !slices.Contains(addPatternHooks, hook) {
+		return fmt.Errorf("--hook must be one of %s, got %q", strings.Join(addPatternHooks, ", "), hook)
+	}
+	if useLocal && useShared {
+		return fmt.Errorf("--local and --shared are mutually exclusive")
+	}
+	for _, p := range args {
+		if strings.TrimSpace(p) == "" {
+			return fmt.Errorf("patterns must not be empty")
+		}
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting working directory: %w", err)
+	}
+	dir := addPatternDir(cwd)
+	shared := filepath.Join(dir, "snag.toml")
+	local := filepath.Join(dir, "snag-local.toml")
+
+	target := "shared"
+	switch {
+	case useLocal:
+		target = "local"
+	case useShared:
+	case isTTY():
+		if target, err = promptForPatternTarget(relPath(cwd, shared), relPath(cwd, local)); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("choose --shared or --local (no terminal to ask on)")
+	}
+	path := shared
+	if target == "local" {
+		path = local
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	old := string(data)
+	if old != "" {
+		if _, err := loadSnagTOML(path); err != nil {
+			return err
+		}
+	}
+	updated, err := setTOMLKey(old, []string{"block", hook}, args, true)
+	if err != nil {
+		return err
+	}
+	name := relPath(cwd, path)
+	if updated == old {
+		if !quiet {
+			infof("block.%s in %s already has %s", hook, name, strings.Join(quoteAll(args), ", "))
+		}
+		return nil
+	}
+	if err := validateEditedConfig(path, updated); err != nil {
+		return err
+	}
+	fmt.Fprint(cmd.OutOrStdout(), unifiedDiff(name, old, updated))
+	if dryRun {
+		return nil
+	}
+
+	pushWasInherited := false
+	if hook == "push" {
+		if bc, err := resolveBlockConfig(cmd); err == nil && bc.Push == nil {
+			pushWasInherited = true
+		}
+	}
+	if err := writeFileAtomic(path, func(f *os.File) error {
+		_, err := f.WriteString(updated)
+		return err
+	}); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	if !quiet {
+		infof("updated block.%s in %s", hook, name)
+		if pushWasInherited {
+			hintf("block.push was unset, so pre-push checked diff + msg; it now checks only block.push")
+		}
+		if target == "local" && old == "" {
+			hintf("add snag-local.toml to .gitignore")
+		}
+	}
+	return nil
+}
+
+// addPatternDir returns the directory whose config add-pattern edits: the
+// nearest one from cwd up to the repository root holding snag.toml or a
+// plaintext snag-local.toml, else the repository root, else cwd.
+func addPatternDir(cwd string) string {
+	top := cwd
+	if out, err := gitCommand("rev-parse", "--show-toplevel").Output(); err == nil {
+		top = filepath.FromSlash(strings.TrimSpace(string(out)))
+	}
+	for d := cwd; ; d = filepath.Dir(d) {
+		if fileExists(filepath.Join(d, "snag.toml")) || fileExists(filepath.Join(d, "snag-local.toml")) {
+			return d
+		}
+		if dirKey(runtime.GOOS, d) == dirKey(runtime.GOOS, top) || filepath.Dir(d) == d {
+			return top
+		}
+	}
+}
+
+// relPath shows path relative to base when it is below it.
+func relPath(base, path string) string {
+	if rel, err := filepath.Rel(base, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// quoteAll returns each of ss in Go quotes.
+func quoteAll(ss []string) []string {
+	out := make([]string, len(ss))
+	for i, s := range ss {
+		out[i] = fmt.Sprintf("%q", s)
+	}
+	return out
+}
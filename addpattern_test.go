@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runAddPatternIn(t *testing.T, dir string, args ...string) (string, error) {
+	t.Helper()
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	var out bytes.Buffer
+	rootCmd := buildRootCmd()
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs(append([]string{"add-pattern", "--quiet"}, args...))
+	err := rootCmd.Execute()
+	return out.String(), err
+}
+
+func TestAddPattern_LocalCreatesFile(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"todo\"]\n"), 0644)
+
+	out, err := runAddPatternIn(t, dir, "INTERNAL_HOST", "--local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, `+diff = ["INTERNAL_HOST"]`) {
+		t.Errorf("expected a diff of the new file, got:\n%s", out)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "snag-local.toml"))
+	if string(data) != "[block]\ndiff = [\"INTERNAL_HOST\"]\n" {
+		t.Errorf("snag-local.toml = %q", data)
+	}
+	if shared, _ := os.ReadFile(filepath.Join(dir, "snag.toml")); string(shared) != "[block]\ndiff = [\"todo\"]\n" {
+		t.Errorf("snag.toml should be untouched, got %q", shared)
+	}
+}
+
+func TestAddPattern_NearestConfigFromSubdir(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("# team policy\n[block]\nmsg = [\"wip\"] # squash first\n"), 0644)
+	sub := filepath.Join(dir, "src", "api")
+	os.MkdirAll(sub, 0755)
+
+	if _, err := runAddPatternIn(t, sub, "--shared", "--hook", "msg", "DO NOT MERGE", "wip"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "snag.toml"))
+	if want := "# team policy\n[block]\nmsg = [\"wip\", \"DO NOT MERGE\"] # squash first\n"; string(data) != want {
+		t.Errorf("snag.toml = %q, want %q", data, want)
+	}
+}
+
+func TestAddPattern_ChoosingTarget(t *testing.T) {
+	dir := initGitRepo(t)
+
+	origIsTTY := isTTY
+	defer func() { isTTY = origIsTTY }()
+	isTTY = func() bool { return false }
+	if _, err := runAddPatternIn(t, dir, "secret"); err == nil {
+		t.Error("without a terminal, --shared or --local should be required")
+	}
+
+	isTTY = func() bool { return true }
+	origPrompt := promptForPatternTarget
+	defer func() { promptForPatternTarget = origPrompt }()
+	promptForPatternTarget = func(shared, local string) (string, error) { return "local", nil }
+	if _, err := runAddPatternIn(t, dir, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if !fileExists(filepath.Join(dir, "snag-local.toml")) {
+		t.Error("the prompt's choice should pick snag-local.toml")
+	}
+}
+
+func TestAddPattern_DryRunAndValidation(t *testing.T) {
+	dir := initGitRepo(t)
+	path := filepath.Join(dir, "snag.toml")
+	os.WriteFile(path, []byte("[block]\ndiff = [\"todo\"]\n"), 0644)
+
+	out, err := runAddPatternIn(t, dir, "-n", "--shared", "fixme")
+	if err != nil || !strings.Contains(out, `+diff = ["todo", "fixme"]`) {
+		t.Errorf("dry run: out=%q err=%v", out, err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "[block]\ndiff = [\"todo\"]\n" {
+		t.Errorf("dry run wrote the file: %q", data)
+	}
+
+	if _, err := runAddPatternIn(t, dir, "--shared", "norm: -- "); err == nil {
+		t.Error("a pattern the config rejects should not be written")
+	}
+	if _, err := runAddPatternIn(t, dir, "--hook", "commit", "--shared", "x"); err == nil {
+		t.Error("an unknown --hook should be rejected")
+	}
+}
+
+func TestAddPattern_KeepsSymlinkAndMode(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"todo\"]\n"), 0644)
+	// snag-local.toml often links to a private file kept outside the repo.
+	target := filepath.Join(t.TempDir(), "private.toml")
+	os.WriteFile(target, []byte("[block]\ndiff = [\"acme\"]\n"), 0600)
+	link := filepath.Join(dir, "snag-local.toml")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	if _, err := runAddPatternIn(t, dir, "INTERNAL_HOST", "--local"); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("snag-local.toml is no longer a symlink: %v", err)
+	}
+	data, _ := os.ReadFile(target)
+	if want := "[block]\ndiff = [\"acme\", \"INTERNAL_HOST\"]\n"; string(data) != want {
+		t.Errorf("link target = %q, want %q", data, want)
+	}
+	if info, _ := os.Stat(target); info.Mode().Perm() != 0600 {
+		t.Errorf("target mode = %v, want 0600", info.Mode().Perm())
+	}
+}
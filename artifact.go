@@ -0,0 +1,305 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// artifactMaxMember caps how much of any one file or archive member is
+	// read into memory; larger members are reported as skipped.
+	artifactMaxMember = 64 << 20
+	// artifactMaxDepth bounds archive nesting (a docker save tarball holds
+	// layer tarballs, which hold jars...).
+	artifactMaxDepth = 4
+	// artifactMinString is the shortest printable run extracted from
+	// binary content, as with strings(1).
+	artifactMinString = 6
+)
+
+// artifactHit is one pattern match inside a build output. Location uses
+// "!" to step into archive members: dist/app.tar.gz!etc/app.env.
+type artifactHit struct {
+	Location string
+	Line     int   // 1-based line for text content; 0 for binary
+	Col      int   // 1-based column for text content
+	Offset   int64 // byte offset of the string for binary content
+	Pattern  string
+}
+
+// artifactScanner walks files and archives, collecting hits and members
+// too large to read.
+type artifactScanner struct {
+	patterns []string
+	hits     []artifactHit
+	skipped  []skippedFile
+	files    int
+}
+
+func artifactFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("blame", false, "attribute matches in git-tracked files to their authors, grouped by author")
+}
+
+func runArtifact(cmd *cobra.Command, args []string) error {
+	bc, err := resolveBlockConfig(cmd)
+	if err != nil {
+		return err
+	}
+	patterns := bc.Diff
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	s := &artifactScanner{patterns: patterns}
+	for _, root := range args {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			return s.scan(filepath.ToSlash(path), f, 0)
+		})
+		if err != nil {
+			return fmt.Errorf("scanning %s: %w", root, err)
+		}
+	}
+
+	reportSkipped(cmd, s.skipped)
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	if len(s.hits) == 0 {
+		if !quiet {
+			infof("%d files scanned, no matches", s.files)
+		}
+		return nil
+	}
+	if !quiet {
+		for _, h := range s.hits {
+			if problemOutput(cmd) {
+				line, col := h.Line, h.Col
+				if line == 0 {
+					line, col = 1, 1
+				}
+				problemf(idArtifactPattern, h.Location, line, col, "match %q in artifact", bc.display(h.Pattern))
+				continue
+			}
+			if h.Line > 0 {
+				blockf(idArtifactPattern, "match %q at %s:%d:%d", bc.display(h.Pattern), h.Location, h.Line, h.Col)
+			} else {
+				blockf(idArtifactPattern, "match %q in %s (binary, offset %#x)", bc.display(h.Pattern), h.Location, h.Offset)
+			}
+		}
+		if outputFormat(cmd) == formatText {
+			if blame, _ := cmd.Flags().GetBool("blame"); blame {
+				if hits := blameArtifactHits(s.hits, bc); len(hits) > 0 {
+					writeBlameGroups(os.Stderr, hits)
+				}
+			}
+			bell()
+			hintf("rebuild from a clean tree and rotate anything that shipped")
+		}
+	}
+	return violationf(idArtifactPattern, "%d match(es) in build artifacts", len(s.hits))
+}
+
+// scan sniffs r's format and descends into gzip, tar, and zip content;
+// anything else is scanned as a leaf file.
+func (s *artifactScanner) scan(name string, r io.Reader, depth int) error {
+	br := bufio.NewReaderSize(r, 1024)
+	head, _ := br.Peek(512)
+
+	if depth < artifactMaxDepth {
+		switch {
+		case bytes.HasPrefix(head, []byte{0x1f, 0x8b}):
+			gz, err := gzip.NewReader(br)
+			if err != nil {
+				return s.leaf(name, br)
+			}
+			defer gz.Close()
+			return s.scan(name, gz, depth+1)
+		case len(head) >= 262 && string(head[257:262]) == "ustar":
+			return s.scanTar(name, br, depth)
+		case bytes.HasPrefix(head, []byte("PK\x03\x04")):
+			return s.scanZip(name, br, depth)
+		}
+	}
+	return s.leaf(name, br)
+}
+
+func (s *artifactScanner) scanTar(name string, r io.Reader, depth int) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := s.scan(name+"!"+hdr.Name, tr, depth+1); err != nil {
+			return err
+		}
+	}
+}
+
+// scanZip buffers the archive (zip needs random access) within the member cap.
+func (s *artifactScanner) scanZip(name string, r io.Reader, depth int) error {
+	data, ok, err := readCapped(r)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if !ok {
+		s.skipped = append(s.skipped, skippedFile{Path: name, Reason: "larger than 64 MiB", TooLong: true})
+		return nil
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return s.leaf(name, bytes.NewReader(data))
+	}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("%s!%s: %w", name, f.Name, err)
+		}
+		err = s.scan(name+"!"+f.Name, rc, depth+1)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// leaf scans one file's content: line by line when it looks like text,
+// otherwise the printable strings embedded in it.
+func (s *artifactScanner) leaf(name string, r io.Reader) error {
+	data, ok, err := readCapped(r)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if !ok {
+		s.skipped = append(s.skipped, skippedFile{Path: name, Reason: "larger than 64 MiB", TooLong: true})
+		return nil
+	}
+	s.files++
+	if !isBinary(data) {
+		for _, m := range scanBuffer(string(data), s.patterns) {
+			s.hits = append(s.hits, artifactHit{Location: name, Line: m.Line, Col: m.Col, Pattern: m.Pattern})
+		}
+		return nil
+	}
+	for _, str := range printableStrings(data, artifactMinString) {
+		if pattern, found := matchesPattern(str.Text, s.patterns); found {
+			s.hits = append(s.hits, artifactHit{Location: name, Offset: str.Offset, Pattern: pattern})
+		}
+	}
+	return nil
+}
+
+// readCapped reads r up to artifactMaxMember. ok is false when r is larger.
+func readCapped(r io.Reader) (data []byte, ok bool, err error) {
+	data, err = io.ReadAll(io.LimitReader(r, artifactMaxMember+1))
+	if err != nil {
+		return nil, false, err
+	}
+	return data, len(data) <= artifactMaxMember, nil
+}
+
+// isBinary applies git's heuristic: a NUL byte in the first 8000 bytes.
+func isBinary(data []byte) bool {
+	return bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0
+}
+
+// embeddedString is a run of printable ASCII inside binary content.
+type embeddedString struct {
+	Offset int64
+	Text   string
+}
+
+// printableStrings returns runs of at least minLen printable ASCII bytes,
+// like strings(1).
+func printableStrings(data []byte, minLen int) []embeddedString {
+	var out []embeddedString
+	start := -1
+	flush := func(end int) {
+		if start >= 0 && end-start >= minLen {
+			out = append(out, embeddedString{Offset: int64(start), Text: string(data[start:end])})
+		}
+		start = -1
+	}
+	for i, c := range data {
+		if c == '\t' || (c >= 0x20 && c < 0x7f) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		flush(i)
+	}
+	flush(len(data))
+	return out
+}
+
+func testArtifact(cmd *cobra.Command, dir string, patterns []string) bool {
+	orig, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(orig)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	body := []byte(fmt.Sprintf("API_HOST=%s\n", patterns[0]))
+	tw.WriteHeader(&tar.Header{Name: "app/.env", Mode: 0644, Size: int64(len(body)), Typeflag: tar.TypeReg})
+	tw.Write(body)
+	tw.Close()
+	gz.Close()
+
+	path := filepath.Join(dir, "release.tar.gz")
+	os.WriteFile(path, buf.Bytes(), 0644)
+	return runArtifact(cmd, []string{path}) != nil
+}
+
+// blameArtifactHits attributes text matches in plain files git tracks.
+// Archive members, binary content, and untracked build outputs have no
+// history to blame and are left out.
+func blameArtifactHits(hits []artifactHit, bc *BlockConfig) []blamedHit {
+	var out []blamedHit
+	for _, h := range hits {
+		if h.Line == 0 || strings.Contains(h.Location, "!") {
+			continue
+		}
+		abs, err := filepath.Abs(h.Location) // blameLine runs from the repo root
+		if err != nil {
+			continue
+		}
+		info, err := blameLine("", abs, h.Line)
+		if err != nil {
+			continue
+		}
+		out = append(out, blamedHit{blameInfo: info, Where: fmt.Sprintf("%s:%d", h.Location, h.Line), Pattern: bc.display(h.Pattern)})
+	}
+	return out
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func tarGz(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, body := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(body)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write(body)
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestArtifactScanner_NestedArchives(t *testing.T) {
+	var zbuf bytes.Buffer
+	zw := zip.NewWriter(&zbuf)
+	w, _ := zw.Create("config/app.properties")
+	w.Write([]byte("name=demo\nhost=internal.corp\n"))
+	zw.Close()
+
+	binary := append([]byte{0x7f, 'E', 'L', 'F', 0, 0, 1}, []byte("\x00\x00token=INTERNAL.CORP/v1\x00")...)
+	archive := tarGz(t, map[string][]byte{
+		"lib/app.jar": zbuf.Bytes(),
+		"bin/app":     binary,
+		"README":      []byte("nothing to see\n"),
+	})
+
+	s := &artifactScanner{patterns: []string{"internal.corp"}}
+	if err := s.scan("dist.tar.gz", bytes.NewReader(archive), 0); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]artifactHit{}
+	for _, h := range s.hits {
+		got[h.Location] = h
+	}
+	if h, ok := got["dist.tar.gz!lib/app.jar!config/app.properties"]; !ok || h.Line != 2 || h.Col != 6 {
+		t.Errorf("zip-in-tar hit = %+v (all: %v)", h, s.hits)
+	}
+	if h, ok := got["dist.tar.gz!bin/app"]; !ok || h.Line != 0 || h.Offset != 9 {
+		t.Errorf("binary hit = %+v (all: %v)", h, s.hits)
+	}
+	if len(s.hits) != 2 || s.files != 3 {
+		t.Errorf("got %d hits over %d files, want 2 over 3", len(s.hits), s.files)
+	}
+}
+
+func TestPrintableStrings(t *testing.T) {
+	got := printableStrings([]byte("ab\x00hello world\x01xyz123456"), 6)
+	if len(got) != 2 || got[0].Text != "hello world" || got[0].Offset != 3 || got[1].Text != "xyz123456" {
+		t.Errorf("printableStrings = %+v", got)
+	}
+}
+
+func TestCheckArtifact(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"hunter2\"]\n"), 0644)
+	dist := filepath.Join(dir, "dist")
+	os.MkdirAll(dist, 0755)
+	os.WriteFile(filepath.Join(dist, "clean.tar.gz"), tarGz(t, map[string][]byte{"a.txt": []byte("ok\n")}), 0644)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	run := func() error {
+		rootCmd := buildRootCmd()
+		rootCmd.SetArgs([]string{"check", "artifact", "dist", "-q"})
+		return rootCmd.Execute()
+	}
+	if err := run(); err != nil {
+		t.Fatalf("clean artifacts: %v", err)
+	}
+
+	os.WriteFile(filepath.Join(dist, "leak.tar.gz"), tarGz(t, map[string][]byte{".env": []byte("PASS=hunter2\n")}), 0644)
+	err := run()
+	if err == nil || !strings.Contains(err.Error(), "1 match(es)") {
+		t.Errorf("expected 1 match, got %v", err)
+	}
+}
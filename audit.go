@@ -1,7 +1,11 @@
 package main
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
 	"strings"
 
@@ -12,6 +16,9 @@ import (
 type violation struct {
 	Kind    string // "msg" or "diff"
 	Pattern string
+	Path    string // diff violations: file the match was in
+	Line    int    // 1-based; file line for diff, message line for msg
+	Col     int
 }
 
 // commitReport groups violations for a single commit.
@@ -28,12 +35,29 @@ func buildAuditCmd() *cobra.Command {
 		Long: `Scan commits for block-pattern matches in messages and diffs.
 
 Default range: config value or last 10 commits (HEAD~10..HEAD).
-Override with an explicit range like main..HEAD or --limit 0 for all.`,
+Override with an explicit range like main..HEAD or --limit 0 for all.
+
+--format junit prints a JUnit XML report on stdout instead: one passing
+test case per clean commit and one failed case per violation.
+
+--blame attributes each violation with git blame (the matched line at its
+commit; the commit author for messages) and groups them by author.
+
+--record appends a timestamped summary to .git/snag/history.jsonl; see
+snag stats --trend.
+
+--remote origin/feature-x fetches that branch and scans the commits it
+would bring into HEAD — triage an external contribution before merging.`,
 		SilenceUsage: true,
 		Args:         cobra.MaximumNArgs(1),
 		RunE:         runAudit,
 	}
 	cmd.Flags().Int("limit", -1, "max commits to scan (default: config or 10, 0 = unlimited)")
+	cmd.Flags().String("remote", "", "scan REMOTE/BRANCH commits not yet in HEAD (fetches it first)")
+	cmd.Flags().Bool("no-fetch", false, "with --remote, use the existing remote-tracking ref")
+	cmd.Flags().Bool("blame", false, "attribute each violation to the author who introduced it, grouped by author")
+	cmd.Flags().Bool("record", false, "append a summary to .git/snag/history.jsonl for snag stats")
+	cmd.Flags().Bool("quarantine", false, "quarantine every commit with a violation so pre-push refuses it")
 	return cmd
 }
 
@@ -42,7 +66,11 @@ func runAudit(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	junit := outputFormat(cmd) == formatJUnit
 	if len(bc.Diff) == 0 && len(bc.Msg) == 0 {
+		if junit {
+			return writeJUnit(cmd.OutOrStdout(), "snag audit", nil, nil)
+		}
 		return nil
 	}
 
@@ -56,6 +84,24 @@ func runAudit(cmd *cobra.Command, args []string) error {
 		limit = defaultAuditLimit(bc)
 	}
 
+	if remote, _ := cmd.Flags().GetString("remote"); remote != "" {
+		if len(args) > 0 {
+			return fmt.Errorf("--remote and an explicit RANGE are mutually exclusive")
+		}
+		noFetch, _ := cmd.Flags().GetBool("no-fetch")
+		if ok, why := networkAllowed(bc); !ok && !noFetch {
+			noFetch = true
+			if !quiet {
+				infof("not fetching %s: network disabled by %s", remote, why)
+			}
+		}
+		rng, err := remoteAuditRange(remote, !noFetch)
+		if err != nil {
+			return err
+		}
+		args = []string{rng}
+	}
+
 	shas, err := auditRevList(args, limit)
 	if err != nil {
 		return err
@@ -64,6 +110,9 @@ func runAudit(cmd *cobra.Command, args []string) error {
 		if !quiet {
 			infof("no commits to scan")
 		}
+		if junit {
+			return writeJUnit(cmd.OutOrStdout(), "snag audit", nil, nil)
+		}
 		return nil
 	}
 
@@ -71,20 +120,36 @@ func runAudit(cmd *cobra.Command, args []string) error {
 		infof("scanning %d commits...", len(shas))
 	}
 
-	reports := scanCommits(shas, bc)
+	// Violations print as each commit finishes, and Ctrl-C stops the scan
+	// but still writes the report, record, and summary for the commits
+	// already scanned.
+	ctx, stop := interruptible(cmd.Context())
+	defer stop()
+	problems := problemOutput(cmd)
+	bar := newProgress(quiet || junit, "commits", len(shas))
+	reports, done := streamCommits(ctx, shas, bc, func(r commitReport) {
+		if !quiet && !junit && len(r.Matches) > 0 {
+			bar.Clear()
+			printAuditReport(bc, r, problems)
+		}
+		bar.Step()
+	})
+	bar.Done()
+	interrupted := ctx.Err() != nil
+	total := len(shas)
+	shas = shas[:done]
 
-	if !quiet {
-		for _, r := range reports {
-			fmt.Println()
-			fmt.Printf("  %s — %q\n", shaStyle.Render(r.SHA[:7]), r.Subject)
-			for _, m := range r.Matches {
-				fmt.Printf("    %s match %s in commit %s\n",
-					dimStyle.Render(m.Kind+":"),
-					patternStyle.Render(fmt.Sprintf("%q", m.Pattern)),
-					m.Kind)
-			}
+	if junit {
+		// The report is the point of --format junit, so -q doesn't suppress it.
+		if err := writeJUnit(cmd.OutOrStdout(), "snag audit", shas, ciFindings(shas, reports, bc)); err != nil {
+			return err
 		}
+	} else if !quiet && !problems {
 		fmt.Println()
+		if blame, _ := cmd.Flags().GetBool("blame"); blame && len(reports) > 0 {
+			writeBlameGroups(os.Stdout, blameReports(reports, bc))
+			fmt.Println()
+		}
 	}
 
 	totalViolations := 0
@@ -92,6 +157,30 @@ func runAudit(cmd *cobra.Command, args []string) error {
 		totalViolations += len(r.Matches)
 	}
 
+	if record, _ := cmd.Flags().GetBool("record"); record {
+		rng := fmt.Sprintf("last %d commits", total)
+		if len(args) == 1 {
+			rng = args[0]
+		}
+		if interrupted {
+			rng += " (interrupted)"
+		}
+		if err := recordAuditHistory(bc, rng, len(shas), reports); err != nil {
+			warnf("not recorded: %v", err)
+		}
+	}
+	if q, _ := cmd.Flags().GetBool("quarantine"); q && len(reports) > 0 {
+		if err := quarantineReports(bc, reports); err != nil {
+			warnf("not quarantined: %v", err)
+		} else if !quiet {
+			infof("quarantined %d commit(s): snag quarantine list", len(reports))
+		}
+	}
+
+	if interrupted {
+		infof("interrupted after %d of %d commits: %d violations found in %d of them", done, total, totalViolations, len(reports))
+		return interruptedf("audit stopped after %d of %d commits", done, total)
+	}
 	if totalViolations > 0 {
 		infof("%d violations found in %d of %d commits", totalViolations, len(reports), len(shas))
 		return fmt.Errorf("%d policy violations found", totalViolations)
@@ -101,6 +190,53 @@ func runAudit(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// printAuditReport prints one commit's violations as a problem line each
+// (--format vscode or json) or as a block under the commit's subject.
+func printAuditReport(bc *BlockConfig, r commitReport, problems bool) {
+	if problems {
+		for _, m := range r.Matches {
+			if m.Kind == "msg" {
+				problemf(idMsgPattern, commitProblemFile(r.SHA), 1, 1, "match %q in commit msg of %s (message line %d)", bc.display(m.Pattern), r.SHA[:7], max(m.Line, 1))
+				continue
+			}
+			problemf(patternCheckID(m.Kind), m.Path, m.Line, m.Col, "match %q in commit %s of %s", bc.display(m.Pattern), m.Kind, r.SHA[:7])
+		}
+		return
+	}
+	fmt.Println()
+	fprintPlain(os.Stdout, "  %s — %q\n", shaStyle.Render(r.SHA[:7]), r.Subject)
+	for _, m := range r.Matches {
+		fmt.Printf("    %s match %s in commit %s %s\n",
+			dimStyle.Render(m.Kind+":"),
+			patternStyle.Render(fmt.Sprintf("%q", bc.display(m.Pattern))),
+			m.Kind, dimStyle.Render("["+patternCheckID(m.Kind)+"]"))
+	}
+}
+
+// remoteAuditRange resolves REMOTE/BRANCH to a remote-tracking ref,
+// fetching it first when fetch is set, and returns the range of its commits
+// not reachable from HEAD.
+func remoteAuditRange(ref string, fetch bool) (string, error) {
+	remote, branch, ok := strings.Cut(ref, "/")
+	if !ok || remote == "" || branch == "" {
+		return "", fmt.Errorf("--remote wants REMOTE/BRANCH, got %q", ref)
+	}
+	if err := gitCommand("remote", "get-url", remote).Run(); err != nil {
+		return "", fmt.Errorf("%q is not a configured remote", remote)
+	}
+	tracking := "refs/remotes/" + remote + "/" + branch
+	if fetch {
+		spec := fmt.Sprintf("+refs/heads/%s:%s", branch, tracking)
+		if out, err := gitCommand("fetch", "--quiet", remote, spec).CombinedOutput(); err != nil {
+			return "", fmt.Errorf("git fetch %s %s: %w\n%s", remote, branch, err, out)
+		}
+	}
+	if err := gitCommand("rev-parse", "--verify", "--quiet", tracking).Run(); err != nil {
+		return "", fmt.Errorf("no remote-tracking ref %s/%s (drop --no-fetch to fetch it)", remote, branch)
+	}
+	return "HEAD.." + tracking, nil
+}
+
 // auditRevList builds and runs the git rev-list command for the audit range.
 func auditRevList(args []string, limit int) ([]string, error) {
 	var revArgs []string
@@ -115,15 +251,15 @@ func auditRevList(args []string, limit int) ([]string, error) {
 	}
 
 	// Check if HEAD exists (repo might be empty).
-	if err := exec.Command("git", "rev-parse", "--verify", "HEAD").Run(); err != nil {
+	if err := gitCommand("rev-parse", "--verify", "HEAD").Run(); err != nil {
 		return nil, nil // empty repo, no commits
 	}
 
-	out, err := exec.Command("git", revArgs...).CombinedOutput()
+	out, err := gitCommand(revArgs...).CombinedOutput()
 	if err != nil {
 		// If HEAD~N doesn't exist (fewer commits than N), list everything.
 		if len(args) == 0 && limit > 0 {
-			out, err = exec.Command("git", "rev-list", "HEAD").CombinedOutput()
+			out, err = gitCommand("rev-list", "HEAD").CombinedOutput()
 			if err != nil {
 				return nil, fmt.Errorf("git rev-list: %w\n%s", err, out)
 			}
@@ -150,7 +286,25 @@ func auditRevList(args []string, limit int) ([]string, error) {
 // scanCommits checks all commits' messages and diffs in bulk using
 // batched git calls instead of per-commit forks.
 func scanCommits(shas []string, bc *BlockConfig) []commitReport {
-	reports := make([]commitReport, len(shas))
+	reports, _ := streamCommits(context.Background(), shas, bc, nil)
+	return reports
+}
+
+// scanCommitsProgress is scanCommits with a progress meter for long ranges.
+func scanCommitsProgress(shas []string, bc *BlockConfig, quiet bool) []commitReport {
+	bar := newProgress(quiet, "commits", len(shas))
+	defer bar.Done()
+	reports, _ := streamCommits(context.Background(), shas, bc, func(commitReport) { bar.Step() })
+	return reports
+}
+
+// streamCommits is scanCommits that also hands each commit to each, in shas
+// order, as soon as its checks are done — clean commits included, so
+// callers can show progress and print violations without waiting for the
+// whole range. If ctx is canceled it stops early and returns the reports
+// for the first done commits only.
+func streamCommits(ctx context.Context, shas []string, bc *BlockConfig, each func(commitReport)) (reports []commitReport, done int) {
+	reports = make([]commitReport, len(shas))
 	shaIndex := make(map[string]int, len(shas))
 	for i, sha := range shas {
 		reports[i].SHA = sha
@@ -158,11 +312,13 @@ func scanCommits(shas []string, bc *BlockConfig) []commitReport {
 	}
 
 	// Batch fetch subjects and full messages in one git log call.
-	// Format: <sha>\x00<subject>\x00<body>\x00\x01 per commit
+	// Format: <sha>\t<author ident>\x00<subject>\x00<body>\x00\x01 per commit
 	// \x01 is the record separator (%B can contain newlines).
-	logArgs := []string{"log", "--format=%H%x00%s%x00%B%x00%x01", "--no-walk"}
+	// Commits by [exempt] authors are dropped from both checks.
+	exempt := map[string]bool{}
+	logArgs := []string{"log", "--format=%H%x09%an <%ae>%x00%s%x00%B%x00%x01", "--no-walk"}
 	logArgs = append(logArgs, shas...)
-	if logOut, err := exec.Command("git", logArgs...).CombinedOutput(); err == nil {
+	if logOut, err := exec.CommandContext(ctx, "git", logArgs...).CombinedOutput(); err == nil {
 		for _, entry := range strings.Split(string(logOut), "\x01") {
 			entry = strings.TrimSpace(entry)
 			if entry == "" {
@@ -172,79 +328,121 @@ func scanCommits(shas []string, bc *BlockConfig) []commitReport {
 			if len(parts) < 3 {
 				continue
 			}
-			sha := strings.TrimSpace(parts[0])
+			sha, author, _ := strings.Cut(parts[0], "\t")
+			sha = strings.TrimSpace(sha)
 			idx, ok := shaIndex[sha]
 			if !ok {
 				continue
 			}
 			reports[idx].Subject = parts[1]
+			if bc.exemptAuthor(parseAuthorIdent(author)) {
+				exempt[sha] = true
+				continue
+			}
 			if len(bc.Msg) > 0 {
 				body := strings.TrimSuffix(parts[2], "\x00")
 				if pattern, found := matchesPattern(body, bc.Msg); found {
-					reports[idx].Matches = append(reports[idx].Matches, violation{Kind: "msg", Pattern: pattern})
+					line, col := locateInText(body, pattern)
+					reports[idx].Matches = append(reports[idx].Matches, violation{Kind: "msg", Pattern: pattern, Line: line, Col: col})
 				}
 			}
 		}
 	}
 
-	// Batch fetch diffs via git diff-tree --stdin.
+	// Commits before next are finished and have been handed to each.
+	next := 0
+	finish := func(upTo int) {
+		for ; next < upTo; next++ {
+			if each != nil {
+				each(reports[next])
+			}
+		}
+	}
+
+	// Stream diffs from git diff-tree --stdin, which prints each commit's
+	// SHA on its own line before its patch, in input order. A commit is
+	// done once its patch is checked; so is every commit before it, since
+	// merges and empty commits print nothing.
 	if len(bc.Diff) > 0 {
-		cmd := exec.Command("git", "diff-tree", "-p", "--stdin")
-		cmd.Stdin = strings.NewReader(strings.Join(shas, "\n") + "\n")
-		if diffOut, err := cmd.CombinedOutput(); err == nil {
-			// diff-tree --stdin output starts each commit with the SHA on its own line.
-			// Split on SHA boundaries.
-			chunks := splitDiffByCommit(string(diffOut), shas)
-			for sha, diff := range chunks {
-				idx := shaIndex[sha]
-				if pattern, found := matchesPattern(stripDiffNoise(stripDiffMeta(diff)), bc.Diff); found {
-					reports[idx].Matches = append(reports[idx].Matches, violation{Kind: "diff", Pattern: pattern})
+		rules := bc.skipRulesFor("audit")
+		check := func(sha, diff string) {
+			if ctx.Err() != nil {
+				return
+			}
+			idx := shaIndex[sha]
+			if !exempt[sha] {
+				if hit, _, found := matchDiff(diff, bc.Diff, rules); found {
+					reports[idx].Matches = append(reports[idx].Matches, violation{
+						Kind: "diff", Pattern: hit.Pattern, Path: hit.Path, Line: hit.Line, Col: hit.Col,
+					})
 				}
 			}
+			finish(idx + 1)
+		}
+		cmd := exec.CommandContext(ctx, "git", "diff-tree", "-p", "--stdin")
+		cmd.Stdin = strings.NewReader(strings.Join(shas, "\n") + "\n")
+		if out, err := cmd.StdoutPipe(); err == nil && cmd.Start() == nil {
+			splitDiffStream(out, shaIndex, check)
+			cmd.Wait()
 		}
 	}
+	if ctx.Err() == nil {
+		finish(len(shas))
+	}
 
-	// Filter to only reports with violations.
+	// Filter to only finished reports with violations.
 	var result []commitReport
-	for _, r := range reports {
+	for _, r := range reports[:next] {
 		if len(r.Matches) > 0 {
 			result = append(result, r)
 		}
 	}
-	return result
+	return result, next
 }
 
-// splitDiffByCommit splits combined diff-tree --stdin output into
-// per-commit chunks keyed by full SHA.
-func splitDiffByCommit(output string, shas []string) map[string]string {
-	shaSet := make(map[string]bool, len(shas))
-	for _, sha := range shas {
-		shaSet[sha] = true
+// commitProblemFile is the file a commit-message violation is reported
+// against in problem output, since a message has no path of its own: the
+// first file the commit touches, so the entry still opens something in the
+// workspace, or "." for a commit that touches none.
+func commitProblemFile(sha string) string {
+	out, err := gitCommand("diff-tree", "--no-commit-id", "--name-only", "-z", "-r", "--root", sha).Output()
+	if err != nil {
+		return "."
+	}
+	if first, _, _ := strings.Cut(string(out), "\x00"); first != "" {
+		return first
 	}
+	return "."
+}
 
-	chunks := make(map[string]string, len(shas))
+// splitDiffStream reads diff-tree --stdin output from r and calls chunk with
+// each commit's SHA and patch as soon as the next commit (or EOF) starts.
+func splitDiffStream(r io.Reader, shaIndex map[string]int, chunk func(sha, diff string)) {
+	br := bufio.NewReader(r)
 	var currentSHA string
 	var buf strings.Builder
-
-	for _, line := range strings.Split(output, "\n") {
-		trimmed := strings.TrimSpace(line)
-		if len(trimmed) == 40 && shaSet[trimmed] {
-			if currentSHA != "" {
-				chunks[currentSHA] = buf.String()
+	for {
+		line, err := br.ReadString('\n')
+		if line != "" {
+			trimmed := strings.TrimSpace(line)
+			if _, ok := shaIndex[trimmed]; ok && len(trimmed) == 40 {
+				if currentSHA != "" {
+					chunk(currentSHA, buf.String())
+				}
+				currentSHA = trimmed
+				buf.Reset()
+			} else if currentSHA != "" {
+				buf.WriteString(strings.TrimSuffix(line, "\n"))
+				buf.WriteByte('\n')
 			}
-			currentSHA = trimmed
-			buf.Reset()
-			continue
 		}
-		if currentSHA != "" {
-			buf.WriteString(line)
-			buf.WriteByte('\n')
+		if err != nil {
+			break
 		}
 	}
 	if currentSHA != "" {
-		chunks[currentSHA] = buf.String()
+		chunk(currentSHA, buf.String())
 	}
-	return chunks
 }
 
 const builtinAuditLimit = 10
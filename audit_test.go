@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -357,3 +359,82 @@ func TestAudit_LimitFlagOverridesConfig(t *testing.T) {
 		t.Fatalf("expected CLI --limit to override config and skip older violation, got: %v", err)
 	}
 }
+
+func TestAudit_RemoteBranch(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	commitFile(t, dir, "old.txt", "HACK from before\n", "old work")
+	bare := initBareRemote(t, dir)
+
+	// A contributor pushes feature-x with a violation to the remote.
+	contrib := t.TempDir()
+	for _, args := range [][]string{
+		{"clone", "-q", bare, contrib},
+		{"-C", contrib, "config", "user.email", "c@test.com"},
+		{"-C", contrib, "config", "user.name", "C"},
+		{"-C", contrib, "switch", "-q", "-c", "feature-x"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	commitFile(t, contrib, "new.txt", "another HACK\n", "feature work")
+	if out, err := exec.Command("git", "-C", contrib, "push", "-q", "origin", "feature-x").CombinedOutput(); err != nil {
+		t.Fatalf("git push: %v\n%s", err, out)
+	}
+
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"hack\"]\n"), 0644)
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	run := func(args ...string) error {
+		rootCmd := buildRootCmd()
+		rootCmd.SetArgs(append([]string{"audit"}, append(args, "-q")...))
+		return rootCmd.Execute()
+	}
+
+	err := run("--remote", "origin/feature-x", "--no-fetch")
+	if err == nil || !strings.Contains(err.Error(), "no remote-tracking ref") {
+		t.Fatalf("expected missing-ref error before fetch, got %v", err)
+	}
+
+	// Only feature-x's own commit is scanned, not HEAD's existing HACK.
+	err = run("--remote", "origin/feature-x")
+	if err == nil || !strings.Contains(err.Error(), "1 policy violations") {
+		t.Errorf("expected 1 violation on feature-x, got %v", err)
+	}
+
+	if err := run("--remote", "nope/feature-x"); err == nil {
+		t.Error("expected error for unknown remote")
+	}
+}
+
+func TestStreamCommits_InOrderWithCleanCommits(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	commitFile(t, dir, "a.txt", "this is a HACK\n", "add a")
+	commitFile(t, dir, "b.txt", "fine\n", "add b")
+	commitFile(t, dir, "c.txt", "another hack\n", "add c")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	shas, err := auditRevList([]string{"HEAD~3..HEAD"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var seen []string
+	var flagged int
+	reports, done := streamCommits(context.Background(), shas, &BlockConfig{Diff: []string{"hack"}}, func(r commitReport) {
+		seen = append(seen, r.SHA)
+		flagged += min(len(r.Matches), 1)
+	})
+	if strings.Join(seen, ",") != strings.Join(shas, ",") {
+		t.Errorf("streamed %v, want every commit in order %v", seen, shas)
+	}
+	if done != len(shas) || flagged != 2 || len(reports) != 2 {
+		t.Errorf("flagged %d streamed / %d returned, want 2 each", flagged, len(reports))
+	}
+}
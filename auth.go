@@ -0,0 +1,167 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+func buildAuthCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Keep tokens snag features need in the OS credential store",
+		Long: fmt.Sprintf(`Store the tokens and secrets snag features use in the OS credential store
+instead of environment variables. A set environment variable still wins,
+so CI can keep passing credentials that way.
+
+Services: %s
+
+Backends, picked with SNAG_SECRETS_BACKEND (default: the first available):
+%s  none              environment variables only`, strings.Join(secretServiceNames(), ", "), secretBackendHelp()),
+	}
+
+	login := &cobra.Command{
+		Use:   "login SERVICE",
+		Short: "Store a token for SERVICE",
+		Long: `Store a token for SERVICE. On a terminal snag prompts for it without
+echoing; otherwise it reads the token from stdin.`,
+		Example: `  snag auth login gitea
+  printf %s "$TOKEN" | snag auth login gitlab`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE:         runAuthLogin,
+	}
+	logout := &cobra.Command{
+		Use:          "logout SERVICE",
+		Short:        "Remove the stored token for SERVICE",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE:         runAuthLogout,
+	}
+	status := &cobra.Command{
+		Use:          "status",
+		Short:        "Show where each service's token comes from, without printing it",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runAuthStatus,
+	}
+	cmd.AddCommand(login, logout, status)
+	return cmd
+}
+
+func secretBackendHelp() string {
+	var b strings.Builder
+	for _, sb := range secretBackends {
+		fmt.Fprintf(&b, "  %-17s %s\n", sb.Name, sb.Summary)
+	}
+	return b.String()
+}
+
+// writableSecretBackend resolves the backend for login and logout, which
+// need one that can store.
+func writableSecretBackend(service string) (*secretBackend, secretService, error) {
+	svc, ok := findSecretService(service)
+	if !ok {
+		return nil, svc, fmt.Errorf("unknown service %q (known: %s)", service, strings.Join(secretServiceNames(), ", "))
+	}
+	b, err := currentSecretBackend()
+	if err != nil {
+		return nil, svc, err
+	}
+	if b.Set == nil {
+		return nil, svc, fmt.Errorf("the %s backend is read-only; store %s with the tool behind it", b.Name, service)
+	}
+	return b, svc, nil
+}
+
+// readSecret prompts for a secret on a terminal, without echo, or reads
+// all of stdin otherwise. One trailing newline is dropped.
+func readSecret(cmd *cobra.Command, service string) (string, error) {
+	var data []byte
+	var err error
+	if isTTY() {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Token for %s: ", service)
+		data, err = term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(cmd.ErrOrStderr())
+	} else {
+		data, err = io.ReadAll(cmd.InOrStdin())
+	}
+	if err != nil {
+		return "", err
+	}
+	secret := strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+	if secret == "" {
+		return "", errors.New("empty token; nothing stored")
+	}
+	return secret, nil
+}
+
+func runAuthLogin(cmd *cobra.Command, args []string) error {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	b, svc, err := writableSecretBackend(args[0])
+	if err != nil {
+		return err
+	}
+	secret, err := readSecret(cmd, svc.Name)
+	if err != nil {
+		return err
+	}
+	if err := b.Set(svc.Name, secret); err != nil {
+		return fmt.Errorf("storing %s in %s: %w", svc.Name, b.Name, err)
+	}
+	if !quiet {
+		infof("stored %s in %s", svc.Name, b.Name)
+		if os.Getenv(svc.Env) != "" {
+			hintf("$%s is set and takes precedence; unset it to use the stored token", svc.Env)
+		}
+	}
+	return nil
+}
+
+func runAuthLogout(cmd *cobra.Command, args []string) error {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	b, svc, err := writableSecretBackend(args[0])
+	if err != nil {
+		return err
+	}
+	err = b.Delete(svc.Name)
+	if errors.Is(err, errSecretNotFound) {
+		if !quiet {
+			infof("no %s token stored in %s", svc.Name, b.Name)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("removing %s from %s: %w", svc.Name, b.Name, err)
+	}
+	if !quiet {
+		infof("removed %s from %s", svc.Name, b.Name)
+	}
+	return nil
+}
+
+func runAuthStatus(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+	if b, err := currentSecretBackend(); err != nil {
+		fmt.Fprintf(out, "store: %v\n", err)
+	} else {
+		fmt.Fprintf(out, "store: %s\n", b.Name)
+	}
+	for _, svc := range secretServices {
+		_, source, err := lookupSecret(svc.Name)
+		switch {
+		case err != nil:
+			fmt.Fprintf(out, "%-15s error: %v\n", svc.Name, err)
+		case source == "":
+			fmt.Fprintf(out, "%-15s not set\n", svc.Name)
+		default:
+			fmt.Fprintf(out, "%-15s from %s\n", svc.Name, source)
+		}
+	}
+	return nil
+}
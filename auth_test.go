@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// memorySecretBackend installs an in-memory backend selected through
+// SNAG_SECRETS_BACKEND and returns its contents.
+func memorySecretBackend(t *testing.T) map[string]string {
+	t.Helper()
+	store := map[string]string{}
+	old := secretBackends
+	t.Cleanup(func() { secretBackends = old })
+	secretBackends = append([]*secretBackend{{
+		Name:      "memory",
+		Available: func() bool { return false },
+		Get: func(service string) (string, error) {
+			v, ok := store[service]
+			if !ok {
+				return "", errSecretNotFound
+			}
+			return v, nil
+		},
+		Set:    func(service, secret string) error { store[service] = secret; return nil },
+		Delete: func(service string) error { delete(store, service); return nil },
+	}}, old...)
+	t.Setenv("SNAG_SECRETS_BACKEND", "memory")
+	return store
+}
+
+func runAuth(t *testing.T, stdin string, args ...string) (string, error) {
+	t.Helper()
+	oldIsTTY := isTTY
+	isTTY = func() bool { return false }
+	defer func() { isTTY = oldIsTTY }()
+
+	var out bytes.Buffer
+	rootCmd := buildRootCmd()
+	rootCmd.SetOut(&out)
+	rootCmd.SetIn(strings.NewReader(stdin))
+	rootCmd.SetArgs(append([]string{"auth"}, args...))
+	err := rootCmd.Execute()
+	return out.String(), err
+}
+
+func TestAuthLoginLogout(t *testing.T) {
+	store := memorySecretBackend(t)
+	t.Setenv("SNAG_GITEA_TOKEN", "")
+
+	if _, err := runAuth(t, "tok-123\n", "login", "gitea", "--quiet"); err != nil {
+		t.Fatal(err)
+	}
+	if store["gitea"] != "tok-123" {
+		t.Errorf("stored %q, want the token without its newline", store["gitea"])
+	}
+	if v, source, err := lookupSecret("gitea"); err != nil || v != "tok-123" || source != "memory" {
+		t.Errorf("lookupSecret = %q, %q, %v", v, source, err)
+	}
+
+	out, err := runAuth(t, "", "status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "gitea") || !strings.Contains(out, "from memory") || strings.Contains(out, "tok-123") {
+		t.Errorf("status should name the source and never the token:\n%s", out)
+	}
+
+	if _, err := runAuth(t, "", "logout", "gitea", "--quiet"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store["gitea"]; ok {
+		t.Error("logout left the token behind")
+	}
+}
+
+func TestAuthLoginErrors(t *testing.T) {
+	memorySecretBackend(t)
+	if _, err := runAuth(t, "x", "login", "github"); err == nil || !strings.Contains(err.Error(), "unknown service") {
+		t.Errorf("unknown service: err = %v", err)
+	}
+	if _, err := runAuth(t, "\n", "login", "gitlab"); err == nil || !strings.Contains(err.Error(), "empty token") {
+		t.Errorf("empty token: err = %v", err)
+	}
+	t.Setenv("SNAG_SECRETS_BACKEND", "command")
+	t.Setenv("SNAG_SECRETS_COMMAND", "echo x")
+	if _, err := runAuth(t, "x", "login", "gitlab"); err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Errorf("read-only backend: err = %v", err)
+	}
+}
+
+func TestLookupSecretPrecedence(t *testing.T) {
+	store := memorySecretBackend(t)
+	store["gitlab"] = "from-store"
+
+	t.Setenv("SNAG_GITLAB_TOKEN", "from-env")
+	if v, source, _ := lookupSecret("gitlab"); v != "from-env" || source != "$SNAG_GITLAB_TOKEN" {
+		t.Errorf("env should win: %q from %q", v, source)
+	}
+	t.Setenv("SNAG_GITLAB_TOKEN", "")
+	if v, _, _ := lookupSecret("gitlab"); v != "from-store" {
+		t.Errorf("store: got %q", v)
+	}
+	t.Setenv("SNAG_SECRETS_BACKEND", "none")
+	if v, source, err := lookupSecret("gitlab"); v != "" || source != "" || err != nil {
+		t.Errorf("backend none: %q, %q, %v", v, source, err)
+	}
+}
+
+func TestCommandSecretBackend(t *testing.T) {
+	t.Setenv("SNAG_SECRETS_BACKEND", "")
+	t.Setenv("SNAG_GERRIT_PASSWORD", "")
+	t.Setenv("SNAG_SECRETS_COMMAND", `echo "pw-for-$1"`)
+	if v, source, err := lookupSecret("gerrit"); err != nil || v != "pw-for-gerrit" || source != "command" {
+		t.Errorf("lookupSecret = %q, %q, %v", v, source, err)
+	}
+	t.Setenv("SNAG_SECRETS_COMMAND", `echo "vault sealed" >&2; exit 2`)
+	if _, _, err := lookupSecret("gerrit"); err == nil || !strings.Contains(err.Error(), "vault sealed") {
+		t.Errorf("failing command: err = %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// blameInfo is who introduced a line and when.
+type blameInfo struct {
+	Commit string
+	Author string
+	Email  string
+	Time   time.Time
+}
+
+// blamedHit is one violation with its attribution.
+type blamedHit struct {
+	blameInfo
+	Where   string // file:line, or "message of SHA"
+	Pattern string // as displayed
+}
+
+// notCommitted is the zero commit git blame reports for working-tree lines.
+const notCommitted = "0000000000000000000000000000000000000000"
+
+// blameLine runs git blame on one line of path as of rev ("" for the
+// working tree). path is relative to the repository root, as diffs print
+// it, so git runs from the root.
+func blameLine(rev, path string, line int) (blameInfo, error) {
+	args := []string{"-C", repoRoot("."), "--literal-pathspecs", "blame", "--porcelain", "-L", fmt.Sprintf("%d,%d", line, line)}
+	if rev != "" {
+		args = append(args, rev)
+	}
+	out, err := gitCommand(append(args, "--", path)...).Output()
+	if err != nil {
+		return blameInfo{}, fmt.Errorf("git blame %s:%d: %w", path, line, err)
+	}
+	return parseBlamePorcelain(out), nil
+}
+
+func parseBlamePorcelain(out []byte) blameInfo {
+	var info blameInfo
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for first := true; scanner.Scan(); first = false {
+		text := scanner.Text()
+		if first {
+			info.Commit, _, _ = strings.Cut(text, " ")
+			continue
+		}
+		key, val, _ := strings.Cut(text, " ")
+		switch key {
+		case "author":
+			info.Author = val
+		case "author-mail":
+			info.Email = strings.Trim(val, "<>")
+		case "author-time":
+			if sec, err := strconv.ParseInt(val, 10, 64); err == nil {
+				info.Time = time.Unix(sec, 0)
+			}
+		}
+	}
+	return info
+}
+
+// commitAuthor attributes a commit-message violation: the message was
+// written by whoever authored the commit.
+func commitAuthor(sha string) (blameInfo, error) {
+	out, err := gitCommand("log", "-1", "--format=%H%x00%an%x00%ae%x00%at", sha).Output()
+	if err != nil {
+		return blameInfo{}, fmt.Errorf("git log %s: %w", sha, err)
+	}
+	parts := strings.Split(strings.TrimSpace(string(out)), "\x00")
+	if len(parts) != 4 {
+		return blameInfo{}, fmt.Errorf("git log %s: unexpected output", sha)
+	}
+	info := blameInfo{Commit: parts[0], Author: parts[1], Email: parts[2]}
+	if sec, err := strconv.ParseInt(parts[3], 10, 64); err == nil {
+		info.Time = time.Unix(sec, 0)
+	}
+	return info, nil
+}
+
+// blameReports attributes every audit match: diff matches by blaming the
+// matched line at the commit, message matches to the commit's author.
+func blameReports(reports []commitReport, bc *BlockConfig) []blamedHit {
+	var hits []blamedHit
+	for _, r := range reports {
+		for _, m := range r.Matches {
+			hit := blamedHit{Pattern: bc.display(m.Pattern)}
+			var err error
+			if m.Kind == "diff" {
+				hit.Where = fmt.Sprintf("%s:%d", m.Path, m.Line)
+				hit.blameInfo, err = blameLine(r.SHA, m.Path, m.Line)
+			} else {
+				hit.Where = "message of " + r.SHA[:7]
+				hit.blameInfo, err = commitAuthor(r.SHA)
+			}
+			if err != nil {
+				hit.Author = "unknown"
+			}
+			hits = append(hits, hit)
+		}
+	}
+	return hits
+}
+
+// writeBlameGroups prints hits grouped by author, most violations first,
+// so cleanup can be handed to the people who know the code.
+func writeBlameGroups(w io.Writer, hits []blamedHit) {
+	type group struct {
+		name string
+		hits []blamedHit
+	}
+	byAuthor := map[string]*group{}
+	var groups []*group
+	for _, h := range hits {
+		name := h.Author
+		switch {
+		case h.Commit == notCommitted:
+			name = "(not committed yet)"
+		case h.Email != "":
+			name = fmt.Sprintf("%s <%s>", h.Author, h.Email)
+		}
+		g, ok := byAuthor[name]
+		if !ok {
+			g = &group{name: name}
+			byAuthor[name] = g
+			groups = append(groups, g)
+		}
+		g.hits = append(g.hits, h)
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return len(groups[i].hits) > len(groups[j].hits) })
+
+	fmt.Fprintln(w, "by author:")
+	for _, g := range groups {
+		fprintPlain(w, "\n  %s — %d violation(s)\n", g.name, len(g.hits))
+		for _, h := range g.hits {
+			when, sha := "", ""
+			if h.Commit != "" && h.Commit != notCommitted {
+				sha = h.Commit[:min(len(h.Commit), 7)]
+				when = h.Time.Format("2006-01-02")
+			}
+			fmt.Fprintf(w, "    %-7s %-10s %s %q\n", sha, when, h.Where, h.Pattern)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBlameReportsGroupsByAuthor(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"todo\"]\nmsg = [\"wip\"]\n"), 0644)
+	initialCommit(t, dir)
+	t.Setenv("GIT_AUTHOR_NAME", "Ada")
+	t.Setenv("GIT_AUTHOR_EMAIL", "ada@example.com")
+	commitFile(t, dir, "a.go", "package a\n\n// TODO: one\n", "wip: a")
+	t.Setenv("GIT_AUTHOR_NAME", "Grace")
+	t.Setenv("GIT_AUTHOR_EMAIL", "grace@example.com")
+	commitFile(t, dir, "b.go", "// TODO: two\n", "Add b")
+
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+	os.Chdir(dir)
+
+	bc, _, err := walkConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shas, _ := auditRevList([]string{"HEAD~2..HEAD"}, 0)
+	hits := blameReports(scanCommits(shas, bc), bc)
+	if len(hits) != 3 {
+		t.Fatalf("got %d hits, want 3: %+v", len(hits), hits)
+	}
+
+	var out bytes.Buffer
+	writeBlameGroups(&out, hits)
+	got := out.String()
+	ada := strings.Index(got, "Ada <ada@example.com> — 2 violation(s)")
+	grace := strings.Index(got, "Grace <grace@example.com> — 1 violation(s)")
+	if ada < 0 || grace < 0 || ada > grace {
+		t.Errorf("want Ada (2) before Grace (1):\n%s", got)
+	}
+	if !strings.Contains(got, "a.go:3") || !strings.Contains(got, "message of ") {
+		t.Errorf("missing locations:\n%s", got)
+	}
+}
+
+func TestBlameLineFromSubdirectory(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+	t.Setenv("GIT_AUTHOR_NAME", "Ada")
+	commitFile(t, dir, "sub/a.go", "// TODO: one\n", "Add a")
+
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+	os.Chdir(filepath.Join(dir, "sub"))
+
+	info, err := blameLine("HEAD", "sub/a.go", 1)
+	if err != nil || info.Author != "Ada" {
+		t.Errorf("blameLine from sub/ = %+v, %v", info, err)
+	}
+	hits := blameArtifactHits([]artifactHit{{Location: "a.go", Line: 1, Pattern: "todo"}}, &BlockConfig{})
+	if len(hits) != 1 || hits[0].Author != "Ada" {
+		t.Errorf("artifact blame from sub/ = %+v", hits)
+	}
+}
+
+func TestBlameArtifactHits(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	commitFile(t, dir, "tracked.txt", "ok\ntodo here\n", "Add tracked")
+	os.WriteFile(filepath.Join(dir, "tracked.txt"), []byte("ok\ntodo here\ntodo new\n"), 0644)
+
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+	os.Chdir(dir)
+
+	hits := blameArtifactHits([]artifactHit{
+		{Location: "tracked.txt", Line: 2, Pattern: "todo"},
+		{Location: "tracked.txt", Line: 3, Pattern: "todo"},
+		{Location: "dist.tar!x.env", Line: 1, Pattern: "todo"},
+		{Location: "app.bin", Offset: 12, Pattern: "todo"},
+	}, &BlockConfig{})
+	if len(hits) != 2 {
+		t.Fatalf("got %d hits, want the 2 tracked text lines: %+v", len(hits), hits)
+	}
+	if hits[0].Author != "Test" || hits[1].Commit != notCommitted {
+		t.Errorf("hits = %+v", hits)
+	}
+	var out bytes.Buffer
+	writeBlameGroups(&out, hits)
+	if !strings.Contains(out.String(), "(not committed yet)") {
+		t.Errorf("working-tree line not labelled:\n%s", out.String())
+	}
+}
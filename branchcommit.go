@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// checkProtectedCommit rejects a commit made while HEAD is on a protected
+// branch when [branch] block_commit is set. Detached HEAD and the root
+// commit of a new repository are allowed; SNAG_ALLOW_COMMIT=1 overrides.
+func checkProtectedCommit(cmd *cobra.Command, bc *BlockConfig) error {
+	if !bc.BlockCommitOnProtected || os.Getenv("SNAG_ALLOW_COMMIT") == "1" {
+		return nil
+	}
+	branch, err := currentBranch()
+	if err != nil {
+		return nil
+	}
+	if !isProtected(branch, bc.Branch) {
+		return nil
+	}
+	if gitCommand("rev-parse", "--verify", "-q", "HEAD").Run() != nil {
+		return nil
+	}
+
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	if !quiet {
+		blockf(idProtectedCommit, "direct commit to protected branch %q blocked", branch)
+		bell()
+		hintf("protected branches: %s", strings.Join(bc.Branch, ", "))
+		hintf("commit on a topic branch instead: git switch -c my-change")
+		hintf("to override: SNAG_ALLOW_COMMIT=1 git commit ...")
+	}
+	return violationf(idProtectedCommit, "%q is a protected branch", branch)
+}
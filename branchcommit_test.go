@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckDiff_BlockCommitOnProtected(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[branch]\nblock_commit = true\n"), 0644)
+	stageFile(t, dir, "a.go", "package a\n")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	run := func() error {
+		rootCmd := buildRootCmd()
+		rootCmd.SetArgs([]string{"check", "diff", "-q"})
+		return rootCmd.Execute()
+	}
+
+	// initGitRepo's default branch is main or master — both protected.
+	err := run()
+	if err == nil || !strings.Contains(err.Error(), "protected branch") {
+		t.Fatalf("expected protected-branch violation, got %v", err)
+	}
+
+	t.Setenv("SNAG_ALLOW_COMMIT", "1")
+	if err := run(); err != nil {
+		t.Errorf("SNAG_ALLOW_COMMIT=1 should override, got %v", err)
+	}
+	t.Setenv("SNAG_ALLOW_COMMIT", "")
+
+	if out, err := exec.Command("git", "switch", "-q", "-c", "topic").CombinedOutput(); err != nil {
+		t.Fatalf("git switch: %v\n%s", err, out)
+	}
+	if err := run(); err != nil {
+		t.Errorf("commit on topic branch should pass, got %v", err)
+	}
+}
+
+func TestCheckDiff_BlockCommitAllowsRootCommit(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[branch]\nblock_commit = true\n"), 0644)
+	stageFile(t, dir, "snag.toml", "[branch]\nblock_commit = true\n")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"check", "diff", "-q"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Errorf("first commit of a new repo should pass, got %v", err)
+	}
+}
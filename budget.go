@@ -0,0 +1,55 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// limitsSection caps warn-level findings and how long a hook may run:
+//
+//	[limits]
+//	max_warnings = 5
+//	hook_timeout = "5s"
+//	on_timeout = "allow"   # default "block"
+type limitsSection struct {
+	MaxWarnings int    `toml:"max_warnings"` // 0 = unlimited
+	HookTimeout string `toml:"hook_timeout"` // Go duration; "" = no watchdog
+	OnTimeout   string `toml:"on_timeout"`   // "block" (fail closed) or "allow" (fail open)
+}
+
+// checkWarningBudget escalates a commit to a violation once its warn-level
+// findings (lines matching warn-only patterns) exceed [limits] max_warnings.
+// A few TODOs pass; a commit that piles them up is blocked.
+func checkWarningBudget(cmd *cobra.Command, bc *BlockConfig, n int, where string) error {
+	if bc.MaxWarnings == 0 || n <= bc.MaxWarnings {
+		return nil
+	}
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	if !quiet {
+		blockf(idWarningBudget, "%d warnings in %s exceed max_warnings (%d)", n, where, bc.MaxWarnings)
+		hintf("clean up some of the warnings above, or split the change")
+		bell()
+	}
+	return violationf(idWarningBudget, "%d warnings in %s exceed max_warnings (%d)", n, where, bc.MaxWarnings)
+}
+
+// countDiffLines counts added lines in diff matching pattern, skipping
+// files rules excludes.
+func countDiffLines(diff, pattern string, rules skipRules) int {
+	n := 0
+	for _, f := range splitDiffFiles(diff) {
+		if rules.reason(f) != "" {
+			continue
+		}
+		for _, l := range scannedLines(f.Body, rules.Scan) {
+			if _, ok := matchesPattern(l.Text, []string{pattern}); ok {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// countTextLines counts lines of text matching pattern.
+func countTextLines(text, pattern string) int {
+	return len(scanBuffer(text, []string{pattern}))
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCountDiffLines(t *testing.T) {
+	diff := "diff --git a/x b/x\n--- a/x\n+++ b/x\n@@ -1,2 +1,3 @@\n TODO old\n-TODO gone\n+TODO one\n+todo two\n+done\n"
+	if n := countDiffLines(diff, "todo", skipRules{}); n != 2 {
+		t.Errorf("countDiffLines = %d, want 2", n)
+	}
+	if n := countDiffLines(diff, "todo", skipRules{Extensions: []string{"x"}}); n != 0 {
+		t.Errorf("skipped file counted: %d", n)
+	}
+}
+
+func TestRunDiff_WarningBudget(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[block]\ndiff = [\"todo\"]\n\n[rollout]\nmode = \"warn\"\n\n[limits]\nmax_warnings = 2\n"), 0644)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	run := func() error {
+		rootCmd := buildRootCmd()
+		rootCmd.SetArgs([]string{"check", "diff", "-q"})
+		return rootCmd.Execute()
+	}
+
+	stageFile(t, dir, "a.go", "// TODO one\n// TODO two\n")
+	if err := run(); err != nil {
+		t.Fatalf("two warnings within budget blocked: %v", err)
+	}
+
+	stageFile(t, dir, "b.go", "// TODO three\n")
+	err := run()
+	if err == nil || !strings.Contains(err.Error(), "exceed max_warnings (2)") {
+		t.Errorf("expected budget violation, got %v", err)
+	}
+}
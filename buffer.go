@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func bufferFlags(cmd *cobra.Command) {
+	cmd.Flags().String("path", "", "repo path of the file being edited (selects config and path rules)")
+	cmd.MarkFlagRequired("path")
+}
+
+// bufferMatch is one policy hit inside an editor buffer.
+type bufferMatch struct {
+	Line    int // 1-based
+	Col     int // 1-based byte column; 1 when the pattern is hashed
+	Pattern string
+}
+
+func runBuffer(cmd *cobra.Command, args []string) error {
+	path, _ := cmd.Flags().GetString("path")
+	return checkBuffer(cmd, path, cmd.InOrStdin())
+}
+
+// checkBuffer evaluates diff patterns against unsaved editor content. Every
+// line counts as an addition. Config resolution starts at the file's own
+// directory so nested snag.toml files apply, and path-scoped skip rules
+// (extensions) are honored.
+func checkBuffer(cmd *cobra.Command, path string, r io.Reader) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("resolving %s: %w", path, err)
+	}
+	bc, err := resolveBlockConfigAt(cmd, filepath.Dir(abs))
+	if err != nil {
+		return err
+	}
+	if len(bc.Diff) == 0 {
+		return nil
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("reading buffer: %w", err)
+	}
+	rules := bc.skipRules()
+	rules.MaxBytes = 0 // the editor already has the whole buffer in memory
+	if why := rules.reason(diffFile{Path: path, Body: string(data)}); why != "" {
+		reportSkipped(cmd, []skippedFile{{Path: path, Reason: why}})
+		return nil
+	}
+
+	patterns, _ := rules.unsuppressed(bc.repoPath(abs), bc.Diff)
+	matches := scanBuffer(string(data), patterns)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	if !quiet {
+		for _, m := range matches {
+			if problemOutput(cmd) {
+				problemf(idDiffPattern, path, m.Line, m.Col, "match %q", bc.display(m.Pattern))
+			} else {
+				blockf(idDiffPattern, "match %q at %s:%d:%d", bc.display(m.Pattern), path, m.Line, m.Col)
+			}
+		}
+	}
+	return violationf(idDiffPattern, "%d match(es) in %s", len(matches), path)
+}
+
+// locateInText returns the 1-based line and column of the first line in
+// text matching pattern, or (1, 1) when the match spans lines.
+func locateInText(text, pattern string) (line, col int) {
+	if m := scanBuffer(text, []string{pattern}); len(m) > 0 {
+		return m[0].Line, m[0].Col
+	}
+	return 1, 1
+}
+
+// scanBuffer reports the first matching pattern on each line of text.
+func scanBuffer(text string, patterns []string) []bufferMatch {
+	var matches []bufferMatch
+	for i, line := range strings.Split(text, "\n") {
+		pattern, found := matchesPattern(line, patterns)
+		if !found {
+			continue
+		}
+		matches = append(matches, bufferMatch{Line: i + 1, Col: matchColumn(line, pattern), Pattern: pattern})
+	}
+	return matches
+}
+
+func testBuffer(cmd *cobra.Command, dir string, patterns []string) bool {
+	orig, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(orig)
+
+	buf := fmt.Sprintf("package demo\n\n// %s: unsaved edit\n", patterns[0])
+	err := checkBuffer(cmd, filepath.Join(dir, "unsaved.go"), strings.NewReader(buf))
+	return err != nil // error means violation detected = pass
+}
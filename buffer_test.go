@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScanBuffer(t *testing.T) {
+	text := "package x\n\n\t// TODO: one\nclean\nx := 1 // fixme\n"
+	got := scanBuffer(text, []string{"todo", "fixme"})
+	want := []bufferMatch{
+		{Line: 3, Col: 5, Pattern: "todo"},
+		{Line: 5, Col: 11, Pattern: "fixme"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d matches, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("match %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestCheckBuffer_UsesNestedConfig(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "src")
+	os.MkdirAll(sub, 0755)
+	os.WriteFile(filepath.Join(root, "snag.toml"), []byte("[block]\ndiff = [\"todo\"]\n"), 0644)
+	os.WriteFile(filepath.Join(sub, "snag.toml"), []byte("[block]\ndiff = [\"debugger\"]\n"), 0644)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(t.TempDir()) // outside the tree: config must come from --path
+	defer os.Chdir(oldDir)
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetIn(strings.NewReader("function f() {\n  debugger;\n}\n"))
+	rootCmd.SetArgs([]string{"check", "buffer", "-q", "--path", filepath.Join(sub, "app.js")})
+	err := rootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "1 match") {
+		t.Fatalf("expected one match from nested config, got %v", err)
+	}
+}
+
+func TestCheckBuffer_CleanAndSkipped(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[block]\ndiff = [\"todo\"]\n\n[skip]\nextensions = [\".md\"]\n"), 0644)
+
+	for name, content := range map[string]string{
+		"clean.go": "package clean\n",
+		"NOTES.md": "TODO: write docs\n",
+	} {
+		rootCmd := buildRootCmd()
+		rootCmd.SetIn(strings.NewReader(content))
+		rootCmd.SetArgs([]string{"check", "buffer", "-q", "--path", filepath.Join(dir, name)})
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("%s: expected no violation, got %v", name, err)
+		}
+	}
+}
+
+func TestCheckBuffer_RequiresPath(t *testing.T) {
+	rootCmd := buildRootCmd()
+	rootCmd.SetIn(strings.NewReader(""))
+	rootCmd.SetArgs([]string{"check", "buffer"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Fatal("expected error without --path")
+	}
+}
+
+func TestCheckBuffer_NonASCIIColumn(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"todo\"]\n"), 0644)
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	// Ⱥ lowercases to a longer character, so the column must be taken from
+	// the original line: TODO is at column 11, not 14.
+	rootCmd := buildRootCmd()
+	rootCmd.SetIn(strings.NewReader("// ȺȺȺ TODO\n"))
+	rootCmd.SetArgs([]string{"check", "buffer", "--format", "vscode", "--path", filepath.Join(dir, "x.go")})
+	err := rootCmd.Execute()
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	if err == nil {
+		t.Fatal("expected violation")
+	}
+	buf := make([]byte, 1024)
+	n, _ := r.Read(buf)
+	if got := string(buf[:n]); !strings.Contains(got, "x.go:1:11: error:") {
+		t.Errorf("stdout = %q, want column 11", got)
+	}
+}
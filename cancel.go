@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// errInterrupted marks a run stopped by SIGINT or SIGTERM after it saved
+// what it had; main exits 130 for it, as shells do for Ctrl-C.
+var errInterrupted = errors.New("interrupted")
+
+// interruptible returns a context canceled by the first SIGINT or SIGTERM,
+// for long scans that should stop early and keep their partial results.
+// Only the first signal is caught: a second Ctrl-C kills snag as usual, in
+// case saving partial results hangs. Call stop when the scan is over.
+func interruptible(parent context.Context) (ctx context.Context, stop func()) {
+	ctx, stop = signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
+
+// interruptedf wraps errInterrupted with a summary of what was kept.
+func interruptedf(format string, a ...any) error {
+	return fmt.Errorf("%w: %s", errInterrupted, fmt.Sprintf(format, a...))
+}
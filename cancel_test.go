@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestStreamCommits_CanceledKeepsNothingUnfinished(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	commitFile(t, dir, "a.txt", "this is a HACK\n", "add a")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	shas, err := auditRevList(nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	reports, done := streamCommits(ctx, shas, &BlockConfig{Diff: []string{"hack"}}, nil)
+	if done != 0 || len(reports) != 0 {
+		t.Errorf("canceled scan returned %d reports for %d commits, want none", len(reports), done)
+	}
+}
+
+func TestInterruptedf_Wraps(t *testing.T) {
+	if err := interruptedf("stopped after %d", 3); !errors.Is(err, errInterrupted) {
+		t.Errorf("%v should wrap errInterrupted", err)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// capability is a named config feature a snag.toml can declare it depends
+// on with requires = [...]. Names are stable: once shipped, never renamed
+// or reused, so a config's requires list means the same thing to every
+// release.
+type capability struct {
+	Name    string
+	Summary string
+}
+
+var capabilities = []capability{
+	{"hash", "sha256:<hex> patterns matched against hashed tokens"},
+	{"sensitive", "[block] sensitive = true redacts patterns in output"},
+	{"encrypted-local", "snag-local.toml.age and snag-local.sops.toml overlays"},
+	{"detect", "[detect.NAME] built-in detectors"},
+	{"redact", "[redact] literal replacements for snag redact"},
+	{"rollout", "[rollout] warn-only windows for new patterns"},
+	{"limits", "[limits] max_warnings, hook_timeout, on_timeout"},
+	{"format", "[format] whitespace and line-ending checks"},
+	{"consistency", "[consistency] manifest and lockfile pairs"},
+	{"push-policy", "[push] allowed_remotes and commit-shape rules"},
+	{"branch-commit", "[branch] block_commit on protected branches"},
+	{"behavior", "[behavior] network and version_check switches"},
+	{"min-version-policy", "min_version_policy = \"degrade\""},
+	{"policy-trailer", "[behavior] policy_trailer records the snag config hash digest"},
+	{"include", "include = [...] merges policy files relative to the including file"},
+	{"conditional-rules", "[[block.rule]] patterns gated on remote, default branch, or repo name"},
+	{"packs-auto", "packs_auto = true enables debug and artifact rules for detected languages"},
+	{"exempt-authors", "[exempt] authors skips message and diff checks for bot commits in push and audit"},
+	{"push-max-commits", "[push] max_commits caps the pre-push scan (on_max_commits = warn or block)"},
+	{"notify", "[notify] desktop notifications when a hook blocks"},
+	{"accessibility", "[notify] bell / visual_bell and [behavior] plain_output"},
+	{"near-miss", "[detect.near_miss] warns on words one edit from a blocked pattern"},
+	{"normalize", "norm:<text> patterns and [[block.rule]] normalize match obfuscated spellings"},
+	{"scan-modes", "[scan] diff/push/audit choose added, added+context, or full diff lines"},
+	{"worktree-config", ".git/snag/worktree.toml per-worktree overrides with ignore entries"},
+	{"branch-rules", "[[block.rule]] branches = [...] scopes a pattern to the checked-out branch"},
+	{"token-detector", "[detect.token] flags known credential formats, updatable with snag packs update"},
+	{"priority", "priority = N orders the files of one directory for single-value settings"},
+	{"host-sections", "[host.\"GLOB\"] sections overlay a config file on matching hostnames"},
+	{"msg-language", "[msg] language flags commit messages in another language"},
+	{"pii-detector", "[detect] pii = [...] flags emails, card numbers, IBANs, phone numbers and national IDs"},
+	{"inclusive-detector", "[detect.inclusive] flags non-inclusive terms and profanity, with terms and allow"},
+	{"credfile-detector", "[detect.credfile] flags values in dotenv files and under JSON credential keys"},
+	{"block-urls", "[block] urls globs block URLs and hostnames in added lines"},
+	{"license-header", "[require] license_header checks new files for a header; snag check license --fix inserts it"},
+	{"changelog-reminder", "[require] changelog warns or blocks when source changes without a changelog entry"},
+	{"ticket-verify", "[tickets] verify looks up ticket IDs in commit messages in Jira or Linear"},
+	{"prepare-prefix", "[prepare] prepends the branch's ticket and commit type to new messages"},
+}
+
+// missingCapabilities returns the entries of requires this build lacks.
+func missingCapabilities(requires []string) []string {
+	have := make(map[string]bool, len(capabilities))
+	for _, c := range capabilities {
+		have[c.Name] = true
+	}
+	var missing []string
+	for _, r := range requires {
+		if !have[strings.ToLower(strings.TrimSpace(r))] {
+			missing = append(missing, r)
+		}
+	}
+	return missing
+}
+
+func buildCapabilitiesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "capabilities",
+		Short: "List the config features this snag supports (for requires = [...])",
+		Long: `List the config features this snag build supports.
+
+A snag.toml can declare the features it depends on:
+
+  requires = ["hash", "rollout"]
+
+A snag that lacks any of them refuses the file and names what's missing,
+instead of quietly ignoring settings it doesn't understand.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 2, 4, 2, ' ', 0)
+			for _, c := range capabilities {
+				fmt.Fprintf(tw, "%s\t%s\n", c.Name, c.Summary)
+			}
+			tw.Flush()
+		},
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadSnagTOML_Requires(t *testing.T) {
+	tests := []struct {
+		requires string
+		wantErr  string
+	}{
+		{`["hash", "Rollout"]`, ""},
+		{`["regex", "hash", "per-path"]`, "lacks: regex, per-path"},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "snag.toml")
+		os.WriteFile(path, []byte("requires = "+tt.requires+"\n[block]\ndiff = [\"todo\"]\n"), 0644)
+		_, err := loadSnagTOML(path)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: %v", tt.requires, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: got %v, want %q", tt.requires, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCapabilitiesCmd(t *testing.T) {
+	var buf bytes.Buffer
+	rootCmd := buildRootCmd()
+	rootCmd.SetOut(&buf)
+	rootCmd.SetArgs([]string{"capabilities"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	seen := map[string]bool{}
+	for _, c := range capabilities {
+		if seen[c.Name] {
+			t.Errorf("duplicate capability %q", c.Name)
+		}
+		seen[c.Name] = true
+		if !strings.Contains(buf.String(), c.Name) {
+			t.Errorf("capability %q not listed", c.Name)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Lines bracketing the snag block snag install --chain adds to hook scripts
+// other tools own. Everything between them, inclusive, is snag's; --remove
+// deletes exactly that.
+const (
+	chainBegin = "# >>> snag: added by snag install --chain; remove with snag install --chain --remove"
+	chainEnd   = "# <<< snag"
+)
+
+// hookOwner is a tool found managing the repository's git hooks.
+type hookOwner struct {
+	Name string // "husky", "pre-commit", "lefthook", "custom scripts"
+	Dir  string // where its hook scripts live
+}
+
+// gitHooksDir returns the directory git runs hooks from, honoring
+// core.hooksPath, or "" outside a repository.
+func gitHooksDir() string {
+	out, err := gitCommand("rev-parse", "--path-format=absolute", "--git-path", "hooks").Output()
+	if err != nil {
+		return ""
+	}
+	return filepath.FromSlash(strings.TrimSpace(string(out)))
+}
+
+// huskyDir returns the directory holding husky's editable hook scripts, or
+// "" when the repository doesn't use husky. husky points core.hooksPath at
+// .husky/_ (v9) or .husky (v4–v8); the scripts to edit are in .husky.
+func huskyDir() string {
+	out, err := gitCommand("rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return ""
+	}
+	dir := filepath.Join(filepath.FromSlash(strings.TrimSpace(string(out))), ".husky")
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return ""
+	}
+	return dir
+}
+
+// detectHookOwners reports the tools that already manage this repository's
+// hooks. Scripts snag emit-hooks wrote don't count.
+func detectHookOwners() []hookOwner {
+	if dir := huskyDir(); dir != "" {
+		return []hookOwner{{"husky", dir}}
+	}
+	dir := gitHooksDir()
+	if dir == "" {
+		return nil
+	}
+	owners := map[string]bool{}
+	for _, h := range gitHooks {
+		data, err := os.ReadFile(filepath.Join(dir, h.Name))
+		if err != nil || strings.Contains(string(data), emitMarker) {
+			continue
+		}
+		switch s := string(data); {
+		case strings.Contains(s, "pre-commit.com") || strings.Contains(s, "pre_commit"):
+			owners["pre-commit"] = true
+		case strings.Contains(s, "lefthook"):
+			owners["lefthook"] = true
+		default:
+			owners["custom scripts"] = true
+		}
+	}
+	var out []hookOwner
+	for _, name := range []string{"pre-commit", "lefthook", "custom scripts"} {
+		if owners[name] {
+			out = append(out, hookOwner{name, dir})
+		}
+	}
+	return out
+}
+
+// chainBlock is the snag block for h. It checks that snag exists, so a
+// missing binary fails the hook instead of skipping the policy. For hooks
+// git feeds stdin, the block reads it and hands it back to the rest of
+// the script through a here-document.
+func chainBlock(h gitHook) string {
+	var b strings.Builder
+	b.WriteString(chainBegin + "\n")
+	b.WriteString(`command -v snag >/dev/null 2>&1 || { echo "snag: not found on PATH; install it or run snag install --chain --remove" >&2; exit 1; }` + "\n")
+	if h.Stdin {
+		b.WriteString("snag_input=$(cat)\n")
+		fmt.Fprintf(&b, "printf '%%s\\n' \"$snag_input\" | snag hook %s \"$@\" || exit $?\n", h.Name)
+		b.WriteString("exec <<SNAG_INPUT\n$snag_input\nSNAG_INPUT\n")
+	} else {
+		fmt.Fprintf(&b, "snag hook %s \"$@\" || exit $?\n", h.Name)
+	}
+	b.WriteString(chainEnd + "\n")
+	return b.String()
+}
+
+// removeChainBlock returns content without the snag block, and whether
+// there was one.
+func removeChainBlock(content string) (string, bool) {
+	start := strings.Index(content, chainBegin)
+	if start < 0 {
+		return content, false
+	}
+	end := strings.Index(content[start:], chainEnd+"\n")
+	if end < 0 {
+		return content, false
+	}
+	return content[:start] + content[start+end+len(chainEnd)+1:], true
+}
+
+// addChainBlock puts the snag block for h into a hook script, replacing an
+// older one. The block goes right after the shebang rather than at the
+// end, where a trailing exec or exit would skip it.
+func addChainBlock(content string, h gitHook) string {
+	content, _ = removeChainBlock(content)
+	block := chainBlock(h)
+	if strings.HasPrefix(content, "#!") {
+		nl := strings.IndexByte(content, '\n')
+		if nl < 0 {
+			return content + "\n" + block
+		}
+		return content[:nl+1] + block + content[nl+1:]
+	}
+	return block + content
+}
+
+// chainOnlyShebang reports whether content is nothing but a shebang and
+// blank lines, as left when removing the block from a script snag created.
+func chainOnlyShebang(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#!") {
+			return false
+		}
+	}
+	return true
+}
+
+// runChainInstall adds snag to (or, with remove, takes it out of) the hook
+// scripts another tool owns, leaving the rest of each script untouched.
+// Missing scripts are created; a script left empty by removal is deleted.
+func runChainInstall(cmd *cobra.Command, remove, dryRun bool) error {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	owners := detectHookOwners()
+	dir := gitHooksDir()
+	if len(owners) > 0 {
+		dir = owners[0].Dir
+	}
+	if dir == "" {
+		return fmt.Errorf("not in a git repository")
+	}
+	if !quiet && !remove {
+		for _, o := range owners {
+			infof("found %s hooks in %s", o.Name, o.Dir)
+		}
+	}
+
+	if !dryRun && !remove {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	cwd, _ := os.Getwd()
+	var diffs strings.Builder
+	changed := 0
+	for _, h := range gitHooks {
+		path := filepath.Join(dir, h.Name)
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		old := string(data)
+		mode := os.FileMode(0755)
+		if info, err := os.Stat(path); err == nil {
+			mode = info.Mode().Perm()
+		}
+
+		var updated string
+		if remove {
+			var found bool
+			if updated, found = removeChainBlock(old); !found {
+				continue
+			}
+		} else {
+			if strings.Contains(old, emitMarker) {
+				continue // snag emit-hooks scripts already run snag
+			}
+			if old == "" {
+				updated = addChainBlock("#!/bin/sh\n", h)
+			} else if updated = addChainBlock(old, h); updated == old {
+				continue
+			}
+		}
+		changed++
+		if dryRun {
+			diffs.WriteString(unifiedDiff(relPath(cwd, path), old, updated))
+			continue
+		}
+		if remove && chainOnlyShebang(updated) {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			continue
+		}
+		err = writeFileAtomic(path, func(f *os.File) error {
+			if _, err := f.WriteString(updated); err != nil {
+				return err
+			}
+			return f.Chmod(mode)
+		})
+		if err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+	}
+
+	if dryRun {
+		showDiffOutput(diffs.String())
+		return nil
+	}
+	if quiet {
+		return nil
+	}
+	switch {
+	case remove && changed == 0:
+		infof("no snag blocks found in %s", dir)
+	case remove:
+		infof("removed snag from %d hook(s) in %s", changed, dir)
+	case changed == 0:
+		infof("snag is already chained into the hooks in %s", dir)
+	default:
+		infof("chained snag into %d hook(s) in %s", changed, dir)
+		for _, o := range owners {
+			switch o.Name {
+			case "pre-commit":
+				hintf("pre-commit install rewrites its hook scripts; run snag install --chain again afterwards")
+			case "lefthook":
+				hintf("lefthook install rewrites its hook scripts; snag install (without --chain) adds snag to lefthook's config instead")
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstallChain_CustomScripts(t *testing.T) {
+	dir := initGitRepo(t)
+	hooksDir := filepath.Join(dir, ".git", "hooks")
+	os.MkdirAll(hooksDir, 0755)
+	orig := "#!/bin/sh\nmake lint\nexec true\n"
+	os.WriteFile(filepath.Join(hooksDir, "pre-commit"), []byte(orig), 0700)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"install"})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "snag install --chain") {
+		t.Errorf("err = %v, want a pointer to --chain", err)
+	}
+
+	if owners := detectHookOwners(); len(owners) != 1 || owners[0].Name != "custom scripts" {
+		t.Errorf("owners = %+v", owners)
+	}
+	for range 2 { // idempotent
+		if err := runChainInstall(buildRootCmd(), false, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, _ := os.ReadFile(filepath.Join(hooksDir, "pre-commit"))
+	if !strings.HasPrefix(string(data), "#!/bin/sh\n"+chainBegin) || strings.Count(string(data), chainBegin) != 1 || !strings.HasSuffix(string(data), "make lint\nexec true\n") {
+		t.Errorf("pre-commit =\n%s", data)
+	}
+	if info, _ := os.Stat(filepath.Join(hooksDir, "pre-commit")); info.Mode().Perm() != 0700 {
+		t.Errorf("mode = %v, want the original 0700", info.Mode().Perm())
+	}
+	if data, _ := os.ReadFile(filepath.Join(hooksDir, "pre-push")); !strings.Contains(string(data), "snag hook pre-push") {
+		t.Errorf("missing pre-push created as %q", data)
+	}
+
+	if err := runChainInstall(buildRootCmd(), true, false); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(hooksDir, "pre-commit")); string(data) != orig {
+		t.Errorf("after --remove pre-commit = %q, want the original", data)
+	}
+	if fileExists(filepath.Join(hooksDir, "pre-push")) {
+		t.Error("pre-push was created by --chain and should be removed")
+	}
+}
+
+func TestInstallChain_Husky(t *testing.T) {
+	dir := initGitRepo(t)
+	husky := filepath.Join(dir, ".husky")
+	os.MkdirAll(filepath.Join(husky, "_"), 0755)
+	os.WriteFile(filepath.Join(husky, "pre-commit"), []byte("npx lint-staged\n"), 0644)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	if owners := detectHookOwners(); len(owners) != 1 || owners[0].Name != "husky" {
+		t.Fatalf("owners = %+v", owners)
+	}
+	if err := runChainInstall(buildRootCmd(), false, false); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(husky, "pre-commit"))
+	if !strings.HasPrefix(string(data), chainBegin) || !strings.HasSuffix(string(data), chainEnd+"\nnpx lint-staged\n") {
+		t.Errorf(".husky/pre-commit =\n%s", data)
+	}
+	if fileExists(filepath.Join(husky, "_", "pre-commit")) {
+		t.Error("husky's generated directory should be left alone")
+	}
+	if !snagHooksInstalled() {
+		t.Error("chained husky hooks should count as installed")
+	}
+}
+
+func TestChainBlock_StdinHandedOn(t *testing.T) {
+	h, _ := findGitHook("pre-push")
+	block := chainBlock(h)
+	if !strings.Contains(block, "exec <<SNAG_INPUT\n$snag_input\nSNAG_INPUT\n") {
+		t.Errorf("pre-push block doesn't hand stdin back:\n%s", block)
+	}
+	got, found := removeChainBlock("#!/bin/sh\n" + block + "echo rest\n")
+	if !found || got != "#!/bin/sh\necho rest\n" {
+		t.Errorf("removeChainBlock = %q, %v", got, found)
+	}
+}
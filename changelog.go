@@ -0,0 +1,78 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// [require] changelog on_missing values.
+const (
+	onChangelogWarn  = "warn"
+	onChangelogBlock = "block"
+)
+
+// defaultChangelogFiles count as documenting a change when [require]
+// changelog sets no files.
+var defaultChangelogFiles = []string{"CHANGELOG.md", "docs/**"}
+
+// changelogOn reports whether [require] changelog is configured.
+func (bc *BlockConfig) changelogOn() bool {
+	return len(bc.ChangelogPaths) > 0
+}
+
+func (bc *BlockConfig) changelogFiles() []string {
+	if len(bc.ChangelogFiles) == 0 {
+		return defaultChangelogFiles
+	}
+	return bc.ChangelogFiles
+}
+
+// changelogGap returns the first of changed under [require] changelog
+// paths when none of changed is a changelog file. A file matching both
+// counts as the changelog.
+func changelogGap(bc *BlockConfig, changed []string) (string, bool) {
+	src := ""
+	for _, p := range changed {
+		if matchTreeGlob(bc.changelogFiles(), p) {
+			return "", false
+		}
+		if src == "" && matchTreeGlob(bc.ChangelogPaths, p) {
+			src = p
+		}
+	}
+	return src, src != ""
+}
+
+// checkChangelog applies [require] changelog to the paths changed in
+// where, e.g. "staged changes" or "push range". It warns unless
+// on_missing = "block".
+func checkChangelog(cmd *cobra.Command, bc *BlockConfig, changed []string, where string) error {
+	src, missing := changelogGap(bc, changed)
+	if !missing {
+		return nil
+	}
+	files := strings.Join(bc.changelogFiles(), ", ")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	if bc.OnChangelogMissing != onChangelogBlock {
+		if quiet {
+			return nil
+		}
+		if problemOutput(cmd) {
+			problem(src, 1, 1, "warning", "changed without a changelog entry in %s (%s) [%s]", where, files, idChangelog)
+		} else {
+			warnf("%s changed but %s has no changelog entry (%s) [%s]", src, where, files, idChangelog)
+		}
+		return nil
+	}
+	if !quiet {
+		if problemOutput(cmd) {
+			problemf(idChangelog, src, 1, 1, "changed without a changelog entry in %s (%s)", where, files)
+		} else {
+			blockf(idChangelog, "%s changed but %s has no changelog entry (%s)", src, where, files)
+			hintf("add an entry to one of: %s", files)
+			bell()
+		}
+	}
+	return violationf(idChangelog, "no changelog entry for %s in %s", src, where)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestChangelogGap(t *testing.T) {
+	bc := &BlockConfig{ChangelogPaths: []string{"src/**", "*.go"}}
+	cases := []struct {
+		changed []string
+		want    string // "" = no gap
+	}{
+		{[]string{"src/app/main.c"}, "src/app/main.c"},
+		{[]string{"README.md", "cmd.go"}, "cmd.go"},
+		{[]string{"src/app/main.c", "CHANGELOG.md"}, ""},
+		{[]string{"src/app/main.c", "docs/guide/install.md"}, ""},
+		{[]string{"README.md"}, ""},
+		{nil, ""},
+	}
+	for _, c := range cases {
+		if got, _ := changelogGap(bc, c.changed); got != c.want {
+			t.Errorf("changelogGap(%v) = %q, want %q", c.changed, got, c.want)
+		}
+	}
+	bc.ChangelogFiles = []string{"NEWS"}
+	if got, _ := changelogGap(bc, []string{"src/x.c", "CHANGELOG.md"}); got != "src/x.c" {
+		t.Errorf("custom files: got %q", got)
+	}
+}
+
+func TestChangelogDiffAndPush(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	os.MkdirAll(filepath.Join(dir, "src"), 0755)
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[require]\nchangelog = { paths = [\"src/**\"], on_missing = \"block\" }\n"), 0644)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	run := func(args ...string) error {
+		rootCmd := buildRootCmd()
+		rootCmd.SetArgs(append([]string{"check"}, append(args, "-q")...))
+		return rootCmd.Execute()
+	}
+
+	stageFile(t, dir, "src/main.c", "int main() {}\n")
+	if err := run("diff"); err == nil || !strings.Contains(err.Error(), "src/main.c") {
+		t.Fatalf("check diff: expected changelog violation, got %v", err)
+	}
+	stageFile(t, dir, "CHANGELOG.md", "- add main\n")
+	if err := run("diff"); err != nil {
+		t.Errorf("check diff with changelog: %v", err)
+	}
+
+	// The entry may land in a later commit of the same push.
+	commitFile(t, dir, "src/util.c", "void util() {}\n", "add util")
+	commitFile(t, dir, "NOTES.txt", "x\n", "notes")
+	if err := run("push"); err != nil {
+		t.Errorf("check push: CHANGELOG.md in the range should satisfy it, got %v", err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[require]\nchangelog = { paths = [\"src/**\"], files = [\"NEWS\"], on_missing = \"block\" }\n"), 0644)
+	if err := run("push"); err == nil || !strings.Contains(err.Error(), "push range") {
+		t.Errorf("check push: expected changelog violation, got %v", err)
+	}
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[require]\nchangelog = { paths = [\"src/**\"], files = [\"NEWS\"] }\n"), 0644)
+	if err := run("push"); err != nil {
+		t.Errorf("check push: on_missing defaults to warn, got %v", err)
+	}
+}
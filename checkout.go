@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -30,16 +31,73 @@ func snagHooksInstalled() bool {
 			return true
 		}
 	}
-	// Path 2: direct .git/hooks/ files containing "snag"
-	for _, name := range []string{"pre-commit", "commit-msg", "pre-push"} {
-		data, err := os.ReadFile(filepath.Join(".git", "hooks", name))
-		if err == nil && strings.Contains(string(data), "snag") {
-			return true
+	// Path 2: hook scripts containing "snag" — in .git/hooks, core.hooksPath,
+	// or husky's .husky (snag emit-hooks, snag install --chain, hand-written)
+	for _, dir := range []string{filepath.Join(".git", "hooks"), gitHooksDir(), huskyDir()} {
+		if dir == "" {
+			continue
+		}
+		for _, name := range []string{"pre-commit", "commit-msg", "pre-push"} {
+			data, err := os.ReadFile(filepath.Join(dir, name))
+			if err == nil && strings.Contains(string(data), "snag") {
+				return true
+			}
 		}
 	}
 	return false
 }
 
+// farBehind is how many commits a protected branch may trail its upstream
+// before post-checkout suggests pulling.
+const farBehind = 20
+
+// commitsBehind returns how many upstream commits HEAD lacks, as of the last
+// fetch. ok is false without an upstream.
+func commitsBehind() (n int, upstream string, ok bool) {
+	out, err := gitCommand("rev-parse", "--abbrev-ref", "@{upstream}").Output()
+	if err != nil {
+		return 0, "", false
+	}
+	upstream = strings.TrimSpace(string(out))
+	out, err = gitCommand("rev-list", "--count", "HEAD..@{upstream}").Output()
+	if err != nil {
+		return 0, "", false
+	}
+	n, err = strconv.Atoi(strings.TrimSpace(string(out)))
+	return n, upstream, err == nil
+}
+
+// checkoutHygiene prints advisory hints after a branch switch: a protected
+// branch far behind its upstream, and uncommitted changes carried over that
+// contain blocked diff patterns. It never fails the hook.
+func checkoutHygiene(cmd *cobra.Command, bc *BlockConfig) {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	if quiet {
+		return
+	}
+	if branch, err := currentBranch(); err == nil && isProtected(branch, bc.Branch) {
+		if n, upstream, ok := commitsBehind(); ok && n >= farBehind {
+			warnf("protected branch %q is %d commits behind %s", branch, n, upstream)
+			hintf("update before branching from it: git pull --ff-only")
+		}
+	}
+
+	patterns := dropSnoozed(cmd, "diff", bc.Diff)
+	if len(patterns) == 0 {
+		return
+	}
+	out, err := gitCommand("diff", "HEAD").Output()
+	if err != nil || len(out) == 0 {
+		return
+	}
+	if hit, _, found := matchDiff(string(out), patterns, bc.skipRulesFor("diff")); found {
+		warnf("uncommitted changes contain blocked pattern %q", bc.display(hit.Pattern))
+		if hit.Path != "" {
+			hintf("in %s — it will block the next commit on this branch", hit.Path)
+		}
+	}
+}
+
 func runCheckout(cmd *cobra.Command, args []string) error {
 	// If no patterns exist, nothing to protect — skip silently.
 	bc, err := resolveBlockConfig(cmd)
@@ -50,6 +108,12 @@ func runCheckout(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// post-checkout passes <prev> <new> <flag>; flag 1 is a branch switch,
+	// 0 a file checkout, which gets no branch advice.
+	if len(args) < 3 || args[2] == "1" {
+		checkoutHygiene(cmd, bc)
+	}
+
 	if snagHooksInstalled() {
 		return nil
 	}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runCheckoutHook runs `snag check checkout` with post-checkout style args
+// and returns its stderr.
+func runCheckoutHook(t *testing.T, args ...string) string {
+	t.Helper()
+	oldStderr := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs(append([]string{"check", "checkout"}, args...))
+	rootCmd.Execute()
+
+	w.Close()
+	os.Stderr = oldStderr
+	out, _ := io.ReadAll(r)
+	return string(out)
+}
+
+func TestCheckout_WarnsOnBlockedPatternInWorkingTree(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"do not merge\"]\n"), 0644)
+	commitFile(t, dir, "a.txt", "ok\n", "add a")
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("ok\nDO NOT MERGE\n"), 0644)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	out := runCheckoutHook(t, "0000000", "0000000", "1")
+	if !strings.Contains(out, `blocked pattern "do not merge"`) || !strings.Contains(out, "a.txt") {
+		t.Errorf("expected working-tree warning, got:\n%s", out)
+	}
+
+	if out := runCheckoutHook(t, "0000000", "0000000", "0"); strings.Contains(out, "blocked pattern") {
+		t.Errorf("file checkout should not get branch hints, got:\n%s", out)
+	}
+}
+
+func TestCheckout_WarnsWhenProtectedBranchFarBehind(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	for i := 0; i < farBehind; i++ {
+		commitFile(t, dir, "n.txt", fmt.Sprint(i), fmt.Sprintf("commit %d", i))
+	}
+	initBareRemote(t, dir)
+
+	git := func(args ...string) {
+		t.Helper()
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		if out, err := c.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	branch := strings.TrimSpace(func() string {
+		out, _ := exec.Command("git", "-C", dir, "symbolic-ref", "--short", "HEAD").Output()
+		return string(out)
+	}())
+	git("branch", "-q", "--set-upstream-to", "origin/"+branch)
+	git("reset", "-q", "--hard", fmt.Sprintf("HEAD~%d", farBehind))
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	out := runCheckoutHook(t)
+	if !strings.Contains(out, fmt.Sprintf("%d commits behind origin/%s", farBehind, branch)) {
+		t.Errorf("expected behind-upstream warning, got:\n%s", out)
+	}
+
+	git("reset", "-q", "--hard", "HEAD@{1}")
+	if out := runCheckoutHook(t); strings.Contains(out, "behind") {
+		t.Errorf("up-to-date branch should not warn, got:\n%s", out)
+	}
+}
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Stable IDs for every built-in check. An ID is never reused or renumbered:
+// people grep logs and CI output for them, and docs link to them.
+const (
+	idDiffPattern     = "SNAG001"
+	idMsgPattern      = "SNAG002"
+	idMsgMaxLen       = "SNAG003"
+	idMsgMaxLines     = "SNAG004"
+	idArtifactPattern = "SNAG005"
+	idMsgLanguage     = "SNAG006"
+	idTicket          = "SNAG007"
+
+	idProtectedRebase   = "SNAG010"
+	idProtectedCommit   = "SNAG011"
+	idProtectedMismatch = "SNAG012"
+
+	idAllowedRemotes = "SNAG020"
+	idMergeCommit    = "SNAG021"
+	idFixupCommit    = "SNAG022"
+	idEmptyCommit    = "SNAG023"
+	idWhitespaceOnly = "SNAG024"
+	idCommitDate     = "SNAG025"
+	idMaxCommits     = "SNAG026"
+	idQuarantined    = "SNAG027"
+
+	idDebugStatement    = "SNAG030"
+	idConflictMarker    = "SNAG031"
+	idSuspiciousUnicode = "SNAG032"
+	idBuildArtifact     = "SNAG033"
+	idNearMiss          = "SNAG034"
+	idSecretToken       = "SNAG035"
+	idInclusiveTerm     = "SNAG036"
+	idPersonalData      = "SNAG037"
+	idCredentialFile    = "SNAG038"
+	idURLPolicy         = "SNAG039"
+
+	idFileMode = "SNAG040"
+	idLockfile = "SNAG041"
+	idFormat   = "SNAG042"
+
+	idLicenseHeader = "SNAG043"
+	idChangelog     = "SNAG044"
+
+	idWarningBudget = "SNAG050"
+	idHookTimeout   = "SNAG051"
+)
+
+// checksDocURL is where docs/checks.md is published; each check has an
+// anchor named after its lowercased ID.
+const checksDocURL = "https://github.com/dpritchett/snag/blob/main/docs/checks.md"
+
+// checkInfo documents one built-in check for snag explain and docs/checks.md.
+type checkInfo struct {
+	ID      string
+	Name    string // kebab-case alias accepted by snag explain
+	Summary string
+	Config  string // what turns it on or configures it
+	Doc     string // why it exists and how to get unstuck
+}
+
+// URL links to the check's section of docs/checks.md.
+func (c checkInfo) URL() string {
+	return checksDocURL + "#" + strings.ToLower(c.ID)
+}
+
+// checkRegistry lists every check in ID order.
+var checkRegistry = []checkInfo{
+	{idDiffPattern, "diff-pattern", "Blocked pattern in a diff",
+		"[block] diff (and push, which defaults to diff + msg)",
+		`An added line of the staged diff, a pushed commit, a patch, or an editor
+buffer contains a configured pattern. Matching is case-insensitive substring
+matching on added lines by default; [scan] widens it per hook to
+"added+context" (the unchanged lines git shows around each change) or
+"full" (also removed lines, reported at the line that followed). Remove the
+text, or if it is legitimate, drop the pattern for one run with
+SNAG_IGNORE=diff:PATTERN or snooze it with snag snooze. --explain shows the
+matching hunk and which config file added the pattern.`},
+	{idMsgPattern, "msg-pattern", "Blocked pattern in a commit message",
+		"[block] msg (and push)",
+		`The commit message (including auto-generated ones from merges and
+squashes, and messages of unpushed commits) contains a configured pattern.
+Reword with git commit --amend, or git rebase -i for older commits.`},
+	{idMsgMaxLen, "msg-max-len", "Commit subject too long",
+		"[block] msg_max_len",
+		`The first non-comment line of the message is longer than msg_max_len
+characters. Shorten the subject and move detail into the body.`},
+	{idMsgMaxLines, "msg-max-lines", "Commit message too long",
+		"[block] msg_max_lines",
+		`The message has more non-blank, non-comment lines than msg_max_lines.`},
+	{idArtifactPattern, "artifact-pattern", "Blocked pattern inside a build artifact",
+		"[block] diff, checked by snag check artifact",
+		`A file or archive member produced by the build contains a configured
+pattern, typically a secret or internal hostname baked in at build time.
+Fix the build input, not the artifact.`},
+	{idMsgLanguage, "msg-language", "Commit message in the wrong language",
+		"[msg] language, on_language",
+		`The commit message reads as a different language than the one [msg]
+language asks for, judged from its script and its most common words. Short
+messages are never judged. Rewrite it, or if the language is intended (a
+translation update, a quote), add a Snag-Language: trailer to the message.`},
+	{idTicket, "ticket", "Commit message references a missing or closed ticket",
+		"[tickets] verify = true",
+		`A ticket ID in the commit message (ENG-123) doesn't exist in the
+configured Jira or Linear, or is already closed: usually a typo. Fix the ID
+with git commit -eF .git/COMMIT_EDITMSG. Lookups fail open: without a token
+or network, the ticket isn't verified and the commit goes through.`},
+	{idProtectedRebase, "protected-rebase", "Rebase of a protected branch",
+		"[block] branch, SNAG_PROTECTED_BRANCHES (default: main, master)",
+		`Rebasing a protected branch rewrites history others have pulled. Rebase
+your feature branch instead, or override once with
+SNAG_ALLOW_REBASE=1 git rebase ...`},
+	{idProtectedCommit, "protected-commit", "Direct commit to a protected branch",
+		"[branch] block_commit = true",
+		`Commits go on a feature branch and reach protected branches through
+review. Move the commit with git switch -c NEW-BRANCH, or override once with
+SNAG_ALLOW_COMMIT=1 git commit ...`},
+	{idProtectedMismatch, "protected-mismatch", "Protected branch pushed to a different ref",
+		"[push] block_protected_mismatch = true",
+		`Pushing local main to someone's feature ref grafts all of main onto it.
+Push the branch you meant: git push origin HEAD:refs/heads/NAME.`},
+	{idAllowedRemotes, "allowed-remotes", "Push to a remote outside allowed_remotes",
+		"[push] allowed_remotes",
+		`The repository may only be pushed to matching remote URLs. Check git
+remote -v, or override once with SNAG_ALLOW_REMOTE=1 git push ...`},
+	{idMergeCommit, "merge-commit", "Unpushed merge commit",
+		"[push] forbid_merge_commits = true",
+		`The branch expects linear history. Rebase onto the upstream instead:
+git pull --rebase.`},
+	{idFixupCommit, "fixup-commit", "Unsquashed fixup commit",
+		"[push] forbid_fixup_commits = true",
+		`A fixup!, squash! or amend! commit was never folded in. Run
+git rebase -i --autosquash @{upstream}.`},
+	{idEmptyCommit, "empty-commit", "Empty commit",
+		"[block] empty = true",
+		`An unpushed commit changes nothing. Drop it with git rebase -i.`},
+	{idWhitespaceOnly, "whitespace-only", "Whitespace-only commit",
+		"[block] whitespace_only = true",
+		`A commit only changes whitespace. Squash it into a real change.`},
+	{idCommitDate, "commit-date", "Commit date outside policy",
+		"[block] commit_hours, date_tolerance",
+		`The author or committer date falls in a blocked window, or is backdated
+further than date_tolerance. Override once with SNAG_ALLOW_DATE=1.`},
+	{idMaxCommits, "max-commits", "Push larger than max_commits",
+		"[push] max_commits, on_max_commits = \"block\"",
+		`The push contains more commits than the pre-push scan is allowed to
+take on. Confirm a full scan with SNAG_ALLOW_LARGE_PUSH=1 git push ...`},
+	{idQuarantined, "quarantined-commit", "Push contains a quarantined commit",
+		"snag quarantine SHA, or snag audit --quarantine",
+		`Someone marked this commit as suspect, usually after snag audit found a
+violation in history that was not pushed yet. Drop or rewrite the commit
+(git rebase -i), which gives it a new SHA, or after review release it with
+snag quarantine resolve SHA. snag quarantine list shows why it was held.`},
+	{idDebugStatement, "debug-statement", "Debug statement left in code",
+		"[detect.debug], or packs_auto",
+		`fmt.Println, console.log, debugger, binding.pry, breakpoint() and
+friends in source files of the matching language. Remove it, or exclude
+paths with [detect.debug] exclude.`},
+	{idConflictMarker, "conflict-marker", "Unresolved merge conflict marker",
+		"[detect.conflict] (on by default)",
+		`A <<<<<<<, =======, ||||||| or >>>>>>> line survived a merge. Finish
+resolving the conflict.`},
+	{idSuspiciousUnicode, "suspicious-unicode", "Bidi control, invisible or homoglyph character",
+		"[detect.unicode] (on by default)",
+		`"Trojan source" bidi controls, zero-width characters, or words mixing
+Latin with look-alike Cyrillic or Greek letters. Retype the text, or exclude
+files that legitimately hold RTL text.`},
+	{idBuildArtifact, "build-artifact", "Build output committed",
+		"[detect.artifact], or packs_auto",
+		`An added file is a build output of the repository's language
+(node_modules/, __pycache__/, target/, *.exe ...). Unstage it and add it to
+.gitignore.`},
+	{idNearMiss, "near-miss", "Word one edit from a blocked pattern (warning)",
+		"[detect.near_miss] enabled = true",
+		`Nothing matched, but a word is one typo, dropped letter or swapped pair
+away from a blocked pattern of five or more letters ("pasword" for
+"password"). It can mean a deliberate dodge or an honest typo, so it only
+warns and never blocks. Exclude noisy paths with [detect.near_miss] exclude.`},
+	{idSecretToken, "secret-token", "Credential in a known token format",
+		"[detect.token] enabled = true",
+		`An added line holds something shaped like a live credential: an AWS
+access key, a GitHub or GitLab token, a Slack or Stripe key, a private key
+block. Remove it and revoke it with the provider, since it may already have
+been copied. snag packs update fetches new formats between releases.`},
+	{idCredentialFile, "credential-file", "Value assigned in a dotenv file or under a JSON credential key",
+		"[detect.credfile] enabled = true",
+		`An added line sets a value in a dotenv file (.env, .env.local,
+prod.env), or a long value under a JSON key named like a token, secret or API
+key. It catches credentials in formats the token detector doesn't know.
+Commit a template with empty or placeholder values (.env.example, ${VAR},
+<your-key>, changeme) and keep real values out of the repository.`},
+	{idURLPolicy, "url-policy", "URL or hostname matching [block] urls",
+		"[block] urls = [\"*.corp.internal\", \"jdbc:*@*\"]",
+		`An added line holds a URL, connection string or hostname matching a
+[block] urls glob, typically an internal endpoint that shouldn't reach a
+public repository. Only URL-shaped tokens are matched, so prose naming the
+company passes. Replace it with a public or placeholder address, or exclude
+paths under [detect.urls] exclude.`},
+	{idInclusiveTerm, "inclusive-term", "Non-inclusive or profane term",
+		"[detect.inclusive] enabled = true, terms, allow",
+		`An added line or the commit message uses a term from the inclusive
+language list, such as whitelist or slave, or profanity. The violation names
+the suggested alternative. Teams extend the list with terms = { OLD = "NEW" }
+and drop entries with allow = [...]; snag packs update fetches list updates.`},
+	{idPersonalData, "personal-data", "Personal data in an added line",
+		"[detect] pii = [\"email\", \"us-ssn\", \"iban\", ...]",
+		`An added line holds something shaped like personal data: an email
+address, a card number that passes the Luhn check, an IBAN with a valid
+checksum, a phone number or a national ID. Replace it with a placeholder
+(user@example.com, 4111 1111 1111 1111 and 555-0100 numbers are never
+flagged), or exclude fixture paths under [detect.pii] exclude.`},
+	{idFileMode, "file-mode", "Executable bit wrong",
+		"[block] executable, require_executable",
+		`A file is staged with (or without) the executable bit against policy.
+Fix with git update-index --chmod=+x FILE (or -x).`},
+	{idLockfile, "lockfile", "Manifest and lockfile changed separately",
+		"[consistency] ecosystems",
+		`A manifest changed without its regenerated lockfile, or the other way
+round. Re-run the package manager and stage both.`},
+	{idFormat, "format", "Whitespace formatting problem",
+		"[format]",
+		`Trailing whitespace, a missing final newline, or CRLF line endings in a
+staged file. snag check format --fix repairs them.`},
+	{idLicenseHeader, "license-header", "New file without the license header",
+		"[require] license_header = { paths, template }",
+		`A newly added file matching license_header paths doesn't start with the
+header in the template file. snag check license --fix inserts it, commented
+for the file's language, and restages the file.`},
+	{idChangelog, "changelog", "Source changed without a changelog entry",
+		"[require] changelog = { paths, files, on_missing }",
+		`Files under [require] changelog paths changed, but the commit (at
+pre-commit) or the push range (at pre-push) touches none of the changelog
+files, CHANGELOG.md and docs/** unless set. It warns by default; with
+on_missing = "block" it fails. Add an entry describing the change.`},
+	{idWarningBudget, "warning-budget", "Too many warn-only findings",
+		"[limits] max_warnings",
+		`Patterns in rollout mode only warn, but more than max_warnings of them
+in one commit block it anyway.`},
+	{idHookTimeout, "hook-timeout", "Check timed out",
+		"[limits] hook_timeout, on_timeout",
+		`A check ran longer than hook_timeout, usually because a git subprocess
+hung on a prompt or slow filesystem. snag kills the check's git
+subprocesses. Set on_timeout = "allow" to fail open.`},
+}
+
+// findCheck looks a check up by ID (any case) or name.
+func findCheck(key string) *checkInfo {
+	for i, c := range checkRegistry {
+		if strings.EqualFold(c.ID, key) || c.Name == strings.ToLower(key) {
+			return &checkRegistry[i]
+		}
+	}
+	return nil
+}
+
+// violationf returns the error a blocked check exits with, tagged with the
+// check's ID so logs and CI output are greppable.
+func violationf(id, format string, a ...any) error {
+	return fmt.Errorf("policy violation: "+format+" [%s]", append(a, id)...)
+}
+
+// blockf prints the human-readable line for a blocked check.
+func blockf(id, format string, a ...any) {
+	errorf(format+" [%s]", append(a, id)...)
+}
+
+// problemf prints an editor problem line for a blocked check.
+func problemf(id, file string, line, col int, format string, a ...any) {
+	problem(file, line, col, "error", format+" [%s]", append(a, id)...)
+}
+
+var checkIDPattern = regexp.MustCompile(`\[(SNAG\d{3})\]`)
+
+// checkIDOf extracts the check ID from a violation error, or "".
+func checkIDOf(err error) string {
+	return checkIDIn(err.Error())
+}
+
+// checkIDIn extracts the first check ID tagged in a message, or "".
+func checkIDIn(msg string) string {
+	if m := checkIDPattern.FindStringSubmatch(msg); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// patternCheckID maps a violation kind ("diff" or "msg") to its check.
+func patternCheckID(kind string) string {
+	if kind == "msg" {
+		return idMsgPattern
+	}
+	return idDiffPattern
+}
+
+// hintCheckDocs points at a failed check's documentation after the error.
+func hintCheckDocs(cmd *cobra.Command, err error) {
+	c := findCheck(checkIDOf(err))
+	if c == nil || cmd == nil {
+		return
+	}
+	if quiet, _ := cmd.Flags().GetBool("quiet"); quiet || outputFormat(cmd) != formatText {
+		return
+	}
+	hintf("learn more: snag explain %s (%s)", c.ID, c.URL())
+}
+
+func buildExplainCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "explain [ID|NAME]",
+		Short: "Document a check by its ID (e.g. SNAG010)",
+		Long: `Print what a check looks for, what configures it, and how to get unstuck.
+Every violation ends with its check ID in brackets, e.g. [SNAG001]. Without
+an argument, list all checks.`,
+		Example: `  snag explain SNAG010
+  snag explain protected-rebase
+  snag explain --markdown > docs/checks.md`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			w := cmd.OutOrStdout()
+			if md, _ := cmd.Flags().GetBool("markdown"); md {
+				writeChecksMarkdown(w)
+				return nil
+			}
+			if len(args) == 0 {
+				for _, c := range checkRegistry {
+					fmt.Fprintf(w, "%s  %-20s %s\n", c.ID, c.Name, c.Summary)
+				}
+				return nil
+			}
+			c := findCheck(args[0])
+			if c == nil {
+				return fmt.Errorf("unknown check %q (see: snag explain)", args[0])
+			}
+			fmt.Fprintf(w, "%s %s: %s\n\n", c.ID, c.Name, c.Summary)
+			fmt.Fprintf(w, "Configured by: %s\n\n", c.Config)
+			fmt.Fprintln(w, c.Doc)
+			fmt.Fprintf(w, "\n%s\n", c.URL())
+			return nil
+		},
+	}
+	cmd.Flags().Bool("markdown", false, "print docs/checks.md for all checks")
+	return cmd
+}
+
+// writeChecksMarkdown renders docs/checks.md from the registry.
+func writeChecksMarkdown(w io.Writer) {
+	fmt.Fprintln(w, "# snag checks")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Every violation snag reports ends with the ID of the check that fired,")
+	fmt.Fprintln(w, "e.g. `[SNAG001]`. IDs are stable. `snag explain ID` prints the same text.")
+	fmt.Fprintln(w, "This file is generated by `snag explain --markdown`.")
+	for _, c := range checkRegistry {
+		fmt.Fprintf(w, "\n## %s\n\n", c.ID)
+		fmt.Fprintf(w, "**%s** — %s\n\n", c.Name, c.Summary)
+		fmt.Fprintf(w, "Configured by: %s\n\n", c.Config)
+		fmt.Fprintln(w, c.Doc)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCheckRegistry(t *testing.T) {
+	seen := map[string]bool{}
+	for _, c := range checkRegistry {
+		if !checkIDPattern.MatchString("[" + c.ID + "]") {
+			t.Errorf("%s: malformed ID", c.ID)
+		}
+		if seen[c.ID] || seen[c.Name] {
+			t.Errorf("%s/%s: duplicate", c.ID, c.Name)
+		}
+		seen[c.ID], seen[c.Name] = true, true
+		if c.Summary == "" || c.Config == "" || c.Doc == "" {
+			t.Errorf("%s: missing documentation", c.ID)
+		}
+	}
+	for _, d := range detectors {
+		if findCheck(d.ID) == nil {
+			t.Errorf("detector %s has no registered check ID (%q)", d.Name, d.ID)
+		}
+	}
+}
+
+func TestFindCheck(t *testing.T) {
+	for _, key := range []string{"SNAG010", "snag010", "protected-rebase"} {
+		if c := findCheck(key); c == nil || c.ID != idProtectedRebase {
+			t.Errorf("findCheck(%q) = %v, want %s", key, c, idProtectedRebase)
+		}
+	}
+	if findCheck("SNAG999") != nil {
+		t.Error("findCheck(SNAG999) should be nil")
+	}
+}
+
+func TestViolationfTagsID(t *testing.T) {
+	err := violationf(idLockfile, "%s changed without %s", "go.mod", "go.sum")
+	if got := err.Error(); got != "policy violation: go.mod changed without go.sum [SNAG041]" {
+		t.Errorf("err = %q", got)
+	}
+	if id := checkIDOf(err); id != idLockfile {
+		t.Errorf("checkIDOf = %q, want %s", id, idLockfile)
+	}
+	if id := checkIDOf(errors.New("git diff: exit status 128")); id != "" {
+		t.Errorf("checkIDOf(plain error) = %q, want empty", id)
+	}
+}
+
+func TestExplainCmd(t *testing.T) {
+	var out bytes.Buffer
+	rootCmd := buildRootCmd()
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"explain", "SNAG010"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"protected-rebase", "SNAG_ALLOW_REBASE", "checks.md#snag010"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	rootCmd = buildRootCmd()
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"explain", "SNAG999"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("expected error for unknown check")
+	}
+}
+
+// docs/checks.md is generated from the registry; regenerate it with
+// snag explain --markdown > docs/checks.md.
+func TestChecksMarkdownInSync(t *testing.T) {
+	want, err := os.ReadFile("docs/checks.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got bytes.Buffer
+	writeChecksMarkdown(&got)
+	if got.String() != string(want) {
+		t.Error("docs/checks.md is stale; run: snag explain --markdown > docs/checks.md")
+	}
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// ciFinding is one violation in a CI range, with the pattern already
+// redacted for display. Reporters only ever see findings, never raw
+// patterns.
+type ciFinding struct {
+	SHA     string
+	Subject string
+	Kind    string // "diff" or "msg"
+	Pattern string
+	Path    string // diff findings only
+	Line    int
+	Col     int
+}
+
+// Message returns the human-readable description shared by reporters.
+func (f ciFinding) Message() string {
+	return fmt.Sprintf("blocked pattern %q in commit %s of %s (%s) [%s]", f.Pattern, f.Kind, f.SHA[:min(len(f.SHA), 7)], f.Subject, f.CheckID())
+}
+
+// CheckID returns the stable ID of the check the finding came from.
+func (f ciFinding) CheckID() string {
+	return patternCheckID(f.Kind)
+}
+
+// ciMsgPath stands in for a file path where a reporter needs one but the
+// finding is in a commit message.
+const ciMsgPath = "COMMIT_EDITMSG"
+
+// File returns the finding's path, or ciMsgPath for message findings.
+func (f ciFinding) File() string {
+	if f.Kind == "msg" {
+		return ciMsgPath
+	}
+	return f.Path
+}
+
+// Fingerprint identifies the finding stably across runs, for platforms that
+// deduplicate or compare annotations between builds.
+func (f ciFinding) Fingerprint() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{f.SHA, f.Kind, f.File(), fmt.Sprint(f.Line), f.Pattern}, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// ciReporter renders findings in a CI platform's annotation format.
+// Reporters for platforms that take annotations over an API also Publish
+// them; Publish skips quietly when not running on that platform.
+type ciReporter struct {
+	Name    string
+	Short   string
+	Write   func(w io.Writer, findings []ciFinding) error
+	Publish func(findings []ciFinding) error
+}
+
+var ciReporters = []ciReporter{
+	{"gitlab-codequality", "GitLab Code Quality JSON (artifacts:reports:codequality)", writeGitLabCodeQuality, nil},
+	{"bitbucket", "Bitbucket Code Insights report; posted to the commit in Pipelines", writeBitbucketReport, publishBitbucketReport},
+	{"azure", "Azure DevOps ##vso[task.logissue] logging commands", writeAzureLogIssues, nil},
+}
+
+func findCIReporter(name string) (ciReporter, bool) {
+	for _, r := range ciReporters {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return ciReporter{}, false
+}
+
+func ciReporterNames() []string {
+	var names []string
+	for _, r := range ciReporters {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+// ciRangeSource derives the commit range under review from a CI
+// platform's environment.
+type ciRangeSource struct {
+	Platform string
+	Range    func() string // "" when the variables aren't set
+}
+
+var ciRangeSources = []ciRangeSource{
+	{"GitLab merge request", func() string {
+		return baseRange(os.Getenv("CI_MERGE_REQUEST_DIFF_BASE_SHA"))
+	}},
+	{"GitLab branch pipeline", func() string {
+		return baseRange(os.Getenv("CI_COMMIT_BEFORE_SHA"))
+	}},
+	{"Bitbucket pull request", func() string {
+		return baseRange(os.Getenv("BITBUCKET_PR_DESTINATION_COMMIT"))
+	}},
+	{"Azure DevOps pull request", func() string {
+		target := os.Getenv("SYSTEM_PULLREQUEST_TARGETBRANCH")
+		if target == "" {
+			return ""
+		}
+		return "origin/" + strings.TrimPrefix(target, "refs/heads/") + "..HEAD"
+	}},
+}
+
+// baseRange returns BASE..HEAD, or "" for an unset or all-zero base (GitLab
+// reports 0000... for a branch's first pipeline).
+func baseRange(base string) string {
+	if strings.Trim(base, "0") == "" {
+		return ""
+	}
+	return base + "..HEAD"
+}
+
+// detectCIRange returns the first range a CI platform provides, and which
+// platform provided it.
+func detectCIRange() (rng, platform string) {
+	for _, s := range ciRangeSources {
+		if r := s.Range(); r != "" {
+			return r, s.Platform
+		}
+	}
+	return "", ""
+}
+
+func buildCICmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ci [RANGE]",
+		Short: "Audit the commits under review and write CI annotations",
+		Long: fmt.Sprintf(`Scan the commits a pipeline is building, as snag audit does, and write the
+violations in a CI platform's annotation format so they appear inline in
+merge request diffs.
+
+The range defaults to the one the CI platform provides (a merge request's
+diff base, or the commit before a branch push), falling back to the audit
+default. Message violations, which have no file, are reported against %s.
+Reporters that post to a platform API (bitbucket) honor [behavior] network.
+
+Reporters (--report):
+%s
+With --format junit, a JUnit XML test report also goes to stdout.
+
+Exits non-zero when violations are found, after writing the report.`, ciMsgPath, ciReporterHelp()),
+		Example: `  # .gitlab-ci.yml
+  snag:
+    script: snag ci --report gitlab-codequality -o gl-code-quality-report.json
+    artifacts:
+      when: always
+      reports:
+        codequality: gl-code-quality-report.json`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE:         runCI,
+	}
+	cmd.Flags().String("report", "", "annotation format: "+strings.Join(ciReporterNames(), ", "))
+	cmd.Flags().StringP("output", "o", "", "write the report to FILE instead of stdout")
+	cmd.Flags().Int("limit", -1, "max commits to scan when no range is given (default: config or 10, 0 = unlimited)")
+	return cmd
+}
+
+func ciReporterHelp() string {
+	var b strings.Builder
+	for _, r := range ciReporters {
+		fmt.Fprintf(&b, "  %-20s %s\n", r.Name, r.Short)
+	}
+	return b.String()
+}
+
+func runCI(cmd *cobra.Command, args []string) error {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	name, _ := cmd.Flags().GetString("report")
+	var reporter ciReporter
+	if name != "" {
+		var ok bool
+		if reporter, ok = findCIReporter(name); !ok {
+			return fmt.Errorf("unknown --report %q (choose %s)", name, strings.Join(ciReporterNames(), ", "))
+		}
+	}
+	if out, _ := cmd.Flags().GetString("output"); outputFormat(cmd) == formatJUnit && reporter.Write != nil && out == "" {
+		return fmt.Errorf("--format junit and --report both write to stdout; send the report to a file with -o")
+	}
+
+	bc, err := resolveBlockConfig(cmd)
+	if err != nil {
+		return err
+	}
+	limit, _ := cmd.Flags().GetInt("limit")
+	if limit < 0 {
+		limit = defaultAuditLimit(bc)
+	}
+	if len(args) == 0 {
+		if rng, platform := detectCIRange(); rng != "" {
+			args = []string{rng}
+			if !quiet {
+				infof("scanning %s (%s)", rng, platform)
+			}
+		}
+	}
+
+	// Ctrl-C or a CI job timeout (SIGTERM) stops the scan; the report for
+	// the commits already scanned is still written, but not published.
+	ctx, stop := interruptible(cmd.Context())
+	defer stop()
+	findings, shas, err := collectCIFindings(ctx, bc, args, limit)
+	if err != nil {
+		return err
+	}
+	interrupted := ctx.Err() != nil
+	if outputFormat(cmd) == formatJUnit {
+		if err := writeJUnit(cmd.OutOrStdout(), "snag ci", shas, findings); err != nil {
+			return err
+		}
+	}
+	if reporter.Write != nil {
+		if err := writeCIReport(cmd, reporter, findings); err != nil {
+			return err
+		}
+	}
+	if reporter.Publish != nil && interrupted {
+		if !quiet {
+			infof("not publishing the %s report: scan interrupted", reporter.Name)
+		}
+	} else if reporter.Publish != nil {
+		if ok, why := networkAllowed(bc); !ok {
+			if !quiet {
+				infof("not publishing the %s report: network disabled by %s", reporter.Name, why)
+			}
+		} else if err := reporter.Publish(findings); err != nil {
+			return err
+		}
+	}
+	if !quiet {
+		for _, f := range findings {
+			where := f.SHA[:min(len(f.SHA), 7)]
+			if f.Kind == "diff" {
+				where = fmt.Sprintf("%s:%d", f.Path, f.Line)
+			}
+			fmt.Fprintf(os.Stderr, "  %s: %s\n", where, f.Message())
+		}
+	}
+	if interrupted {
+		return interruptedf("%d policy violations found in the %d commits scanned before the interrupt", len(findings), len(shas))
+	}
+	if len(findings) > 0 {
+		return fmt.Errorf("%d policy violations found in %d commits", len(findings), len(shas))
+	}
+	if !quiet {
+		infof("0 violations found in %d commits", len(shas))
+	}
+	return nil
+}
+
+// collectCIFindings scans the range and returns the commits scanned with
+// their findings. If ctx is canceled, only the commits finished by then are
+// returned.
+func collectCIFindings(ctx context.Context, bc *BlockConfig, args []string, limit int) ([]ciFinding, []string, error) {
+	shas, err := auditRevList(args, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(shas) == 0 || (len(bc.Diff) == 0 && len(bc.Msg) == 0) {
+		return nil, shas, nil
+	}
+	reports, done := streamCommits(ctx, shas, bc, nil)
+	return ciFindings(shas[:done], reports, bc), shas[:done], nil
+}
+
+// ciFindings flattens scanCommits results, oldest commit first so
+// annotations read in history order.
+func ciFindings(shas []string, reports []commitReport, bc *BlockConfig) []ciFinding {
+	order := make(map[string]int, len(shas))
+	for i, sha := range shas {
+		order[sha] = i
+	}
+	reports = slices.Clone(reports)
+	sort.SliceStable(reports, func(i, j int) bool { return order[reports[i].SHA] > order[reports[j].SHA] })
+
+	var findings []ciFinding
+	for _, r := range reports {
+		for _, m := range r.Matches {
+			findings = append(findings, ciFinding{
+				SHA: r.SHA, Subject: r.Subject, Kind: m.Kind,
+				Pattern: bc.display(m.Pattern), Path: m.Path, Line: max(m.Line, 1), Col: max(m.Col, 1),
+			})
+		}
+	}
+	return findings
+}
+
+func writeCIReport(cmd *cobra.Command, reporter ciReporter, findings []ciFinding) error {
+	out, _ := cmd.Flags().GetString("output")
+	if out == "" {
+		return reporter.Write(cmd.OutOrStdout(), findings)
+	}
+	err := writeFileAtomic(out, func(f *os.File) error {
+		return reporter.Write(f, findings)
+	})
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", out, err)
+	}
+	return nil
+}
+
+// gitlabIssue is one entry in GitLab's Code Quality report, a subset of
+// the Code Climate issue format.
+type gitlabIssue struct {
+	Description string         `json:"description"`
+	CheckName   string         `json:"check_name"`
+	Fingerprint string         `json:"fingerprint"`
+	Severity    string         `json:"severity"`
+	Location    gitlabLocation `json:"location"`
+}
+
+type gitlabLocation struct {
+	Path  string `json:"path"`
+	Lines struct {
+		Begin int `json:"begin"`
+	} `json:"lines"`
+}
+
+// writeGitLabCodeQuality writes the Code Quality JSON array. GitLab
+// compares fingerprints between the source and target branches, so each
+// is stable for a given commit, location, and pattern.
+func writeGitLabCodeQuality(w io.Writer, findings []ciFinding) error {
+	issues := []gitlabIssue{}
+	for _, f := range findings {
+		issue := gitlabIssue{
+			Description: f.Message(),
+			CheckName:   f.CheckID(),
+			Fingerprint: f.Fingerprint(),
+			Severity:    "major",
+		}
+		issue.Location.Path = f.File()
+		issue.Location.Lines.Begin = f.Line
+		issues = append(issues, issue)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(issues)
+}
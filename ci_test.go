@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCIGitLabCodeQuality(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"todo\"]\nmsg = [\"wip\"]\n"), 0644)
+	initialCommit(t, dir)
+	base, _ := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	commitFile(t, dir, "a.go", "package a\n\n// TODO: fix\n", "Add a")
+	commitFile(t, dir, "b.go", "package b\n", "wip: b")
+
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+	os.Chdir(dir)
+	t.Setenv("CI_MERGE_REQUEST_DIFF_BASE_SHA", strings.TrimSpace(string(base)))
+
+	out := filepath.Join(t.TempDir(), "gl-code-quality-report.json")
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"ci", "--report", "gitlab-codequality", "-o", out, "-q"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Fatal("expected violations to fail the job")
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var issues []gitlabIssue
+	if err := json.Unmarshal(data, &issues); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, data)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("got %d issues, want 2:\n%s", len(issues), data)
+	}
+	if got := issues[0]; got.CheckName != idDiffPattern || got.Location.Path != "a.go" || got.Location.Lines.Begin != 3 {
+		t.Errorf("diff issue = %+v", got)
+	}
+	if got := issues[1]; got.CheckName != idMsgPattern || got.Location.Path != ciMsgPath {
+		t.Errorf("msg issue = %+v", got)
+	}
+	if issues[0].Fingerprint == issues[1].Fingerprint {
+		t.Error("fingerprints should be unique")
+	}
+}
+
+func TestCICleanRangeWritesEmptyReport(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"todo\"]\n"), 0644)
+	initialCommit(t, dir)
+	commitFile(t, dir, "a.go", "package a\n", "Add a")
+
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+	os.Chdir(dir)
+
+	out := filepath.Join(t.TempDir(), "report.json")
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"ci", "HEAD~1..HEAD", "--report", "gitlab-codequality", "-o", out, "-q"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("clean range: %v", err)
+	}
+	data, _ := os.ReadFile(out)
+	if strings.TrimSpace(string(data)) != "[]" {
+		t.Errorf("report = %s, want []", data)
+	}
+}
+
+func TestCIUnknownReporter(t *testing.T) {
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"ci", "--report", "nope", "-q"})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "gitlab-codequality") {
+		t.Errorf("err = %v, want the reporter list", err)
+	}
+}
+
+func TestBaseRange(t *testing.T) {
+	for in, want := range map[string]string{
+		"":         "",
+		"00000000": "",
+		"abc123":   "abc123..HEAD",
+	} {
+		if got := baseRange(in); got != want {
+			t.Errorf("baseRange(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// ciViolatingRepo returns a repo whose last commit adds a diff violation.
+func ciViolatingRepo(t *testing.T) string {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"todo\"]\n"), 0644)
+	initialCommit(t, dir)
+	commitFile(t, dir, "a;b.go", "package a\n\n// TODO: fix\n", "Add a")
+	return dir
+}
+
+func TestCIAzureLogIssues(t *testing.T) {
+	dir := ciViolatingRepo(t)
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+	os.Chdir(dir)
+
+	var out bytes.Buffer
+	rootCmd := buildRootCmd()
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"ci", "HEAD~1..HEAD", "--report", "azure", "-q"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Fatal("expected violations to fail the job")
+	}
+	want := "##vso[task.logissue type=error;sourcepath=a%3Bb.go;linenumber=3;columnnumber=4;code=SNAG001]blocked pattern"
+	if !strings.HasPrefix(out.String(), want) {
+		t.Errorf("got %q\nwant prefix %q", out.String(), want)
+	}
+}
+
+func TestCIBitbucketPublish(t *testing.T) {
+	dir := ciViolatingRepo(t)
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+	os.Chdir(dir)
+
+	var requests []string
+	var annotations []bitbucketAnnotation
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		if strings.HasSuffix(r.URL.Path, "/annotations") {
+			json.Unmarshal(body, &annotations)
+		}
+	}))
+	defer srv.Close()
+	oldAPI, oldProxy := bitbucketAPI, bitbucketProxy
+	bitbucketAPI, bitbucketProxy = srv.URL, ""
+	defer func() { bitbucketAPI, bitbucketProxy = oldAPI, oldProxy }()
+	t.Setenv("BITBUCKET_REPO_OWNER", "acme")
+	t.Setenv("BITBUCKET_REPO_SLUG", "api")
+	t.Setenv("BITBUCKET_COMMIT", "abc123")
+	t.Setenv("BITBUCKET_PR_DESTINATION_COMMIT", "HEAD~1")
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetOut(io.Discard)
+	rootCmd.SetArgs([]string{"ci", "--report", "bitbucket", "-q"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Fatal("expected violations to fail the job")
+	}
+	wantReqs := []string{
+		"PUT /repositories/acme/api/commit/abc123/reports/snag",
+		"POST /repositories/acme/api/commit/abc123/reports/snag/annotations",
+	}
+	if strings.Join(requests, "\n") != strings.Join(wantReqs, "\n") {
+		t.Errorf("requests = %q, want %q", requests, wantReqs)
+	}
+	if len(annotations) != 1 || annotations[0].Path != "a;b.go" || annotations[0].Line != 3 {
+		t.Errorf("annotations = %+v", annotations)
+	}
+
+	// Offline, the report is still written but nothing is posted.
+	requests = nil
+	t.Setenv("SNAG_OFFLINE", "1")
+	rootCmd = buildRootCmd()
+	rootCmd.SetOut(io.Discard)
+	rootCmd.SetArgs([]string{"ci", "--report", "bitbucket", "-q"})
+	rootCmd.Execute()
+	if len(requests) != 0 {
+		t.Errorf("offline run posted: %q", requests)
+	}
+}
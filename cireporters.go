@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Bitbucket Pipelines exposes an authenticated proxy to the Bitbucket API;
+// both are vars so tests can point them at a local server.
+var (
+	bitbucketAPI   = "http://api.bitbucket.org/2.0"
+	bitbucketProxy = "http://localhost:29418"
+)
+
+const (
+	bitbucketReportID = "snag"
+	// bitbucketAnnotationBatch is the API's limit per annotations request.
+	bitbucketAnnotationBatch = 100
+	bitbucketTimeout         = 10 * time.Second
+)
+
+type bitbucketReport struct {
+	Title      string          `json:"title"`
+	Details    string          `json:"details"`
+	ReportType string          `json:"report_type"`
+	Reporter   string          `json:"reporter"`
+	Result     string          `json:"result"`
+	Data       []bitbucketData `json:"data"`
+}
+
+type bitbucketData struct {
+	Title string `json:"title"`
+	Type  string `json:"type"`
+	Value int    `json:"value"`
+}
+
+type bitbucketAnnotation struct {
+	ExternalID     string `json:"external_id"`
+	Title          string `json:"title"`
+	AnnotationType string `json:"annotation_type"`
+	Summary        string `json:"summary"`
+	Severity       string `json:"severity"`
+	Path           string `json:"path"`
+	Line           int    `json:"line"`
+	Link           string `json:"link,omitempty"`
+}
+
+func bitbucketPayload(findings []ciFinding) (bitbucketReport, []bitbucketAnnotation) {
+	report := bitbucketReport{
+		Title:      "snag policy",
+		Details:    fmt.Sprintf("%d blocked pattern match(es) in the commits under review.", len(findings)),
+		ReportType: "SECURITY",
+		Reporter:   "snag",
+		Result:     "PASSED",
+		Data:       []bitbucketData{{Title: "Violations", Type: "NUMBER", Value: len(findings)}},
+	}
+	if len(findings) > 0 {
+		report.Result = "FAILED"
+	}
+	annotations := []bitbucketAnnotation{}
+	for _, f := range findings {
+		annotations = append(annotations, bitbucketAnnotation{
+			ExternalID:     f.Fingerprint(),
+			Title:          f.CheckID(),
+			AnnotationType: "BUG",
+			Summary:        f.Message(),
+			Severity:       "HIGH",
+			Path:           f.File(),
+			Line:           f.Line,
+			Link:           findCheck(f.CheckID()).URL(),
+		})
+	}
+	return report, annotations
+}
+
+// writeBitbucketReport writes the report and its annotations as one JSON
+// document, for inspection or for posting with other tooling.
+func writeBitbucketReport(w io.Writer, findings []ciFinding) error {
+	report, annotations := bitbucketPayload(findings)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(map[string]any{"report": report, "annotations": annotations})
+}
+
+// publishBitbucketReport creates the Code Insights report on the pipeline's
+// commit, replacing the previous run's, then adds its annotations. Outside
+// Bitbucket Pipelines it does nothing.
+func publishBitbucketReport(findings []ciFinding) error {
+	owner, slug, commit := os.Getenv("BITBUCKET_REPO_OWNER"), os.Getenv("BITBUCKET_REPO_SLUG"), os.Getenv("BITBUCKET_COMMIT")
+	if owner == "" || slug == "" || commit == "" {
+		return nil
+	}
+	client := &http.Client{Timeout: bitbucketTimeout}
+	if bitbucketProxy != "" {
+		proxy, err := url.Parse(bitbucketProxy)
+		if err != nil {
+			return err
+		}
+		client.Transport = &http.Transport{Proxy: http.ProxyURL(proxy)}
+	}
+	base := fmt.Sprintf("%s/repositories/%s/%s/commit/%s/reports/%s",
+		bitbucketAPI, url.PathEscape(owner), url.PathEscape(slug), url.PathEscape(commit), bitbucketReportID)
+
+	report, annotations := bitbucketPayload(findings)
+	if err := bitbucketSend(client, http.MethodPut, base, report); err != nil {
+		return err
+	}
+	for i := 0; i < len(annotations); i += bitbucketAnnotationBatch {
+		batch := annotations[i:min(i+bitbucketAnnotationBatch, len(annotations))]
+		if err := bitbucketSend(client, http.MethodPost, base+"/annotations", batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func bitbucketSend(client *http.Client, method, endpoint string, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(method, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("bitbucket reports API: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("bitbucket reports API: %s %s: %s\n%s", method, endpoint, resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// azureEscaper escapes text for a logging command message, where a newline
+// would end the command.
+var azureEscaper = strings.NewReplacer("%", "%AZP25", "\r", "%0D", "\n", "%0A")
+
+// azurePropEscaper additionally escapes the property delimiters.
+var azurePropEscaper = strings.NewReplacer("%", "%AZP25", "\r", "%0D", "\n", "%0A", ";", "%3B", "]", "%5D")
+
+// writeAzureLogIssues writes one ##vso[task.logissue] command per finding.
+// The agent reads them from stdout and attaches them to the build summary
+// and the pull request.
+func writeAzureLogIssues(w io.Writer, findings []ciFinding) error {
+	for _, f := range findings {
+		_, err := fmt.Fprintf(w, "##vso[task.logissue type=error;sourcepath=%s;linenumber=%d;columnnumber=%d;code=%s]%s\n",
+			azurePropEscaper.Replace(f.File()), f.Line, f.Col, azurePropEscaper.Replace(f.CheckID()), azureEscaper.Replace(f.Message()))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/spf13/cobra"
@@ -13,9 +16,34 @@ import (
 // snagTOML represents the top-level structure of a snag.toml file.
 // Unknown sections are silently ignored (forward compatible).
 type snagTOML struct {
-	MinVersion string       `toml:"min_version"`
-	Block      blockSection `toml:"block"`
-	Audit      auditSection `toml:"audit"`
+	MinVersion  string                       `toml:"min_version"`
+	MinPolicy   string                       `toml:"min_version_policy"` // "strict" (default) or "degrade"
+	Requires    []string                     `toml:"requires"`           // capability names, see snag capabilities
+	Include     []string                     `toml:"include"`            // files merged with this one, relative to it
+	Priority    int                          `toml:"priority"`           // merge order among files of one directory; higher wins
+	PacksAuto   bool                         `toml:"packs_auto"`         // enable language packs detected at the repo root
+	Ignore      []string                     `toml:"ignore"`             // SNAG_IGNORE-style entries; worktree.toml only
+	Block       blockSection                 `toml:"block"`
+	Message     msgSection                   `toml:"msg"`
+	Tickets     ticketsSection               `toml:"tickets"`
+	Prepare     prepareSection               `toml:"prepare"`
+	Audit       auditSection                 `toml:"audit"`
+	Skip        skipSection                  `toml:"skip"`
+	Scan        scanSection                  `toml:"scan"`
+	Push        pushSection                  `toml:"push"`
+	Consistency consistencySection           `toml:"consistency"`
+	Format      formatSection                `toml:"format"`
+	Require     requireSection               `toml:"require"`
+	Rollout     rolloutSection               `toml:"rollout"`
+	Exempt      exemptSection                `toml:"exempt"`
+	Notify      notifySection                `toml:"notify"`
+	Limits      limitsSection                `toml:"limits"`
+	Branch      branchSection                `toml:"branch"`
+	Behavior    behaviorSection              `toml:"behavior"`
+	Redact      map[string]string            `toml:"redact"` // literal → replacement, applied by `snag redact`
+	Detect      map[string]detectRuleSection `toml:"detect"` // built-in detector name → settings
+
+	Hosts []string `toml:"-"` // [host."GLOB"] sections that matched this machine, see hostconfig.go
 }
 
 // blockSection maps each hook phase to its own pattern list.
@@ -26,12 +54,97 @@ type blockSection struct {
 	Branch      []string  `toml:"branch"`
 	MsgMaxLen   int       `toml:"msg_max_len"`
 	MsgMaxLines int       `toml:"msg_max_lines"`
+	Sensitive   bool      `toml:"sensitive"` // redact this file's patterns in output
+
+	CommitHours   string `toml:"commit_hours"`   // "HH:MM-HH:MM" local window in which commits are blocked
+	DateTolerance string `toml:"date_tolerance"` // max skew between commit dates and now (Go duration)
+
+	Empty          bool `toml:"empty"`           // block commits that change nothing
+	WhitespaceOnly bool `toml:"whitespace_only"` // block commits whose changes are all whitespace
+
+	Executable        []string `toml:"executable"`         // path globs that must not be staged +x
+	RequireExecutable []string `toml:"require_executable"` // path globs that must be staged +x
+
+	URLs []string `toml:"urls"` // globs for URLs and hostnames that must not be added
+
+	Rule []conditionalRule `toml:"rule"` // [[block.rule]] patterns gated on repo metadata
+}
+
+// msgSection holds commit message policy beyond [block] msg patterns.
+type msgSection struct {
+	Language   string `toml:"language"`    // code from msgLanguages, e.g. "en"
+	OnLanguage string `toml:"on_language"` // "block" (default) or "warn"
 }
 
 type auditSection struct {
 	Limit *int `toml:"limit"`
 }
 
+// pushSection holds pre-push policy beyond pattern matching.
+type pushSection struct {
+	AllowedRemotes         []string `toml:"allowed_remotes"`
+	BlockProtectedMismatch bool     `toml:"block_protected_mismatch"`
+	ForbidMergeCommits     bool     `toml:"forbid_merge_commits"`
+	ForbidFixupCommits     bool     `toml:"forbid_fixup_commits"`
+	MaxCommits             int      `toml:"max_commits"`    // pre-push scan cap; 0 = unlimited
+	OnMaxCommits           string   `toml:"on_max_commits"` // "warn" (default, scan the newest) or "block"
+}
+
+// branchSection holds policy for work on protected branches ([block] branch).
+type branchSection struct {
+	BlockCommit bool `toml:"block_commit"` // reject commits made while on a protected branch
+}
+
+// consistencySection enables manifest/lockfile pairing checks at pre-commit.
+type consistencySection struct {
+	Ecosystems        []string `toml:"ecosystems"`          // keys of lockfilePairs, e.g. "go", "npm"
+	AllowLockfileOnly bool     `toml:"allow_lockfile_only"` // permit lockfile changes without the manifest
+}
+
+// detectRuleSection configures one built-in detector under [detect.NAME].
+type detectRuleSection struct {
+	Enabled *bool             `toml:"enabled"` // nil = detector's default
+	Exclude []string          `toml:"exclude"` // path globs the detector ignores
+	Terms   map[string]string `toml:"terms"`   // inclusive only: term → suggestion
+	Allow   []string          `toml:"allow"`   // inclusive only: terms not to flag
+	Kinds   []string          `toml:"kinds"`   // pii only: kinds or profiles to flag
+}
+
+// formatSection enables whitespace checks on staged text (snag check format).
+type formatSection struct {
+	TrailingWhitespace bool     `toml:"trailing_whitespace"`
+	FinalNewline       bool     `toml:"final_newline"`
+	CRLF               bool     `toml:"crlf"`    // flag CRLF line endings
+	Exclude            []string `toml:"exclude"` // path globs left alone
+}
+
+// requireSection holds content staged files must have.
+type requireSection struct {
+	LicenseHeader *licenseHeaderSection `toml:"license_header"`
+	Changelog     *changelogSection     `toml:"changelog"`
+}
+
+// licenseHeaderSection is [require] license_header (snag check license).
+type licenseHeaderSection struct {
+	Paths    []string `toml:"paths"`    // globs for files needing the header; "**/" spans directories
+	Exclude  []string `toml:"exclude"`  // globs exempt from it
+	Template string   `toml:"template"` // header text, relative to the config file
+}
+
+// changelogSection is [require] changelog: changes to paths should come
+// with a change to one of files.
+type changelogSection struct {
+	Paths     []string `toml:"paths"`      // source globs whose changes need a changelog entry
+	Files     []string `toml:"files"`      // globs that count as the entry; default CHANGELOG.md and docs/**
+	OnMissing string   `toml:"on_missing"` // "warn" (default) or "block"
+}
+
+// skipSection controls which files the diff and push scanners pass over.
+type skipSection struct {
+	Extensions   []string `toml:"extensions"`
+	MaxFileBytes *int     `toml:"max_file_bytes"`
+}
+
 // BlockConfig holds the resolved per-hook pattern lists.
 // Push is nil when not explicitly set (fallback to Diff+Msg union).
 type BlockConfig struct {
@@ -42,6 +155,99 @@ type BlockConfig struct {
 	MsgMaxLen   int  // max characters on first content line (0 = unlimited)
 	MsgMaxLines int  // max non-blank, non-comment lines (0 = unlimited)
 	AuditLimit  *int // nil = use built-in default
+
+	MsgLanguage   string // [msg] language; "" = unchecked
+	OnMsgLanguage string // [msg] on_language: "block" or "warn"
+
+	TicketProvider  string // [tickets]; see tickets.go
+	TicketURL       string
+	TicketPattern   string
+	TicketProjects  []string
+	TicketVerify    bool
+	OnTicketInvalid string
+	PrepareTicket   bool // [prepare]; see prepare.go
+	PrepareType     bool
+	PrepareTypes    map[string]string // branch prefix → commit type, first file wins per prefix
+
+	Sensitive map[string]bool // lowercased patterns from files marked sensitive = true
+
+	Rollout     map[string]rolloutRule // lowercased pattern → warn-only window from its file's [rollout]
+	MaxWarnings int                    // warn-level findings per commit before it blocks; 0 = unlimited
+	HookTimeout time.Duration          // [limits] hook_timeout; 0 = no watchdog
+	OnTimeout   string                 // [limits] on_timeout: "block" or "allow"
+	strict      map[string]bool        // patterns some config declares without [rollout]; these always block
+
+	CommitHours   string        // "" = no blocked window
+	DateTolerance time.Duration // 0 = dates unchecked
+
+	Executable        []string // path globs that must not have the executable bit
+	RequireExecutable []string // path globs that must have it
+
+	URLs []string // [block] urls globs, lowercased; see urlpolicy.go
+
+	BlockEmpty          bool // reject unpushed commits with no changes
+	BlockWhitespaceOnly bool // reject staged diffs and commits that only change whitespace
+
+	Redact map[string]string // literal → replacement; nearest config wins per key
+
+	DetectEnabled map[string]bool     // detector name → on/off; unset = detector default
+	DetectExclude map[string][]string // detector name → path globs it skips
+
+	InclusiveTerms map[string]string // [detect.inclusive] terms; nearest config wins per term
+	InclusiveAllow []string          // [detect.inclusive] allow
+	inclusive      []inclusiveTerm   // effective word list, built on first use
+	PIIKinds       []string          // [detect.pii] kinds; empty = defaultPIIKinds
+
+	ExemptAuthors []string // [exempt] authors: identities whose push/audit message and diff checks are skipped
+
+	Suppressions []suppression // .snagignore entries at the repo root, expired ones included
+	SuppressRoot string        // repo root .snagignore paths are relative to
+
+	PacksAuto   bool     // some config sets packs_auto = true
+	Packs       []string // language packs detected at the repo root, when PacksAuto
+	PackEnabled []string // detectors switched on by packs rather than [detect.NAME]
+
+	AllowedRemotes []string // remote URL globs pushes may target; empty = any
+
+	BlockProtectedMismatch bool   // reject pushing a protected branch to a differently named remote ref
+	BlockCommitOnProtected bool   // reject commits made directly on a protected branch
+	ForbidMergeCommits     bool   // reject unpushed commits with more than one parent
+	ForbidFixupCommits     bool   // reject unpushed fixup!/squash!/amend! commits
+	MaxPushCommits         int    // [push] max_commits; 0 = unlimited
+	OnMaxPushCommits       string // [push] on_max_commits: "warn" or "block"
+
+	Ecosystems        []string // [consistency] ecosystems whose manifest and lockfile must change together
+	AllowLockfileOnly bool     // lockfile-only changes (npm update, go mod tidy) pass
+
+	FormatTrailingWhitespace bool // [format] checks on added lines of staged files
+	FormatFinalNewline       bool
+	FormatCRLF               bool
+	FormatExclude            []string // path globs snag check format ignores
+
+	LicensePaths    []string // [require] license_header paths
+	LicenseExclude  []string
+	LicenseTemplate string // absolute path of the header template
+
+	ChangelogPaths     []string // [require] changelog paths
+	ChangelogFiles     []string
+	OnChangelogMissing string // "warn" (default) or "block"
+
+	SkipExtensions []string // file suffixes never scanned (e.g. ".min.js")
+	MaxFileBytes   *int     // per-file diff size cap; nil = built-in default, 0 = unlimited
+
+	Scan map[string]string // [scan] hook → diff lines patterns see; unset = added only
+
+	Ignore []string // worktree.toml ignore entries, applied just before SNAG_IGNORE
+
+	NetworkOffBy    string // config file whose [behavior] network = false disables the network; "" = allowed
+	VersionCheckOff bool   // some config sets [behavior] version_check = false
+	PolicyTrailer   bool   // commit-msg records the policy digest as a Snag-Policy trailer
+
+	NotifyDesktop  bool          // [notify] desktop: notification when a hook blocks
+	NotifyInterval time.Duration // [notify] interval between notifications; 0 = default
+	BellOff        bool          // some config sets [notify] bell = false
+	VisualBell     bool          // [notify] visual_bell flashes the screen instead of ringing
+	PlainOutput    bool          // [behavior] plain_output
 }
 
 // PushPatterns returns Push if explicitly set, otherwise the union of Diff and Msg.
@@ -55,30 +261,199 @@ func (bc *BlockConfig) PushPatterns() []string {
 // HasAnyPatterns reports whether any field has at least one pattern.
 func (bc *BlockConfig) HasAnyPatterns() bool {
 	return len(bc.Diff) > 0 || len(bc.Msg) > 0 || len(bc.Push) > 0 || len(bc.Branch) > 0 ||
-		bc.MsgMaxLen > 0 || bc.MsgMaxLines > 0 || bc.AuditLimit != nil
+		bc.MsgMaxLen > 0 || bc.MsgMaxLines > 0 || bc.MsgLanguage != "" || bc.ticketsOn() || bc.prepareOn() || bc.AuditLimit != nil ||
+		len(bc.SkipExtensions) > 0 || bc.MaxFileBytes != nil || len(bc.AllowedRemotes) > 0 ||
+		bc.BlockProtectedMismatch || bc.BlockCommitOnProtected || bc.ForbidMergeCommits || bc.ForbidFixupCommits ||
+		bc.CommitHours != "" || bc.DateTolerance > 0 || bc.BlockEmpty || bc.BlockWhitespaceOnly ||
+		len(bc.Ecosystems) > 0 || len(bc.DetectEnabled) > 0 || bc.PacksAuto || bc.formatEnabled() ||
+		len(bc.Executable) > 0 || len(bc.RequireExecutable) > 0 || len(bc.URLs) > 0 || bc.licenseOn() || bc.changelogOn()
 }
 
 // loadSnagTOML parses a single snag.toml file. A missing file returns zero value with no error.
 func loadSnagTOML(path string) (snagTOML, error) {
 	var cfg snagTOML
-	data, err := os.ReadFile(path)
+	data, err := readConfigFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return cfg, nil
 		}
 		return cfg, err
 	}
-	if err := toml.Unmarshal(data, &cfg); err != nil {
+	data, hosts, err := applyHostSections(data, path)
+	if err != nil {
+		return cfg, err
+	}
+	md, err := toml.Decode(string(data), &cfg)
+	if err != nil {
 		return cfg, fmt.Errorf("parsing %s: %w", path, err)
 	}
+	cfg.Hosts = hosts
+	switch cfg.MinPolicy {
+	case "", minVersionStrict, minVersionDegrade:
+	default:
+		return cfg, fmt.Errorf("%s: min_version_policy must be %q or %q, got %q", path, minVersionStrict, minVersionDegrade, cfg.MinPolicy)
+	}
+	if missing := missingCapabilities(cfg.Requires); len(missing) > 0 {
+		return cfg, fmt.Errorf("%s requires features this snag %s lacks: %s (see snag capabilities)",
+			path, Version, strings.Join(missing, ", "))
+	}
+	// degraded: this snag is older than min_version but the file opted into
+	// applying what it understands; newer features are skipped, not fatal.
+	var degraded bool
+	var skipped []string
 	if cfg.MinVersion != "" {
 		if err := checkMinVersion(cfg.MinVersion, path); err != nil {
-			return cfg, err
+			if cfg.MinPolicy != minVersionDegrade {
+				return cfg, err
+			}
+			degraded = true
+			for _, key := range md.Undecoded() {
+				skipped = append(skipped, key.String())
+			}
+		}
+	}
+	if err := validateRules(cfg.Block.Rule); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := validateNormPatterns(cfg.Block); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, key := range md.Undecoded() {
+		if k := key.String(); strings.HasPrefix(k, "block.rule.when.") && !degraded {
+			return cfg, fmt.Errorf("%s: %s: unknown condition (known: %s)", path, k, strings.Join(ruleConditions, ", "))
 		}
 	}
 	if cfg.Audit.Limit != nil && *cfg.Audit.Limit < 0 {
 		return cfg, fmt.Errorf("%s: audit.limit must be >= 0", path)
 	}
+	if cfg.Block.CommitHours != "" {
+		if _, _, err := parseHourWindow(cfg.Block.CommitHours); err != nil {
+			return cfg, fmt.Errorf("%s: block.commit_hours: %w", path, err)
+		}
+	}
+	if cfg.Block.DateTolerance != "" {
+		if d, err := time.ParseDuration(cfg.Block.DateTolerance); err != nil || d < 0 {
+			return cfg, fmt.Errorf("%s: block.date_tolerance must be a positive duration like \"24h\"", path)
+		}
+	}
+	if lh := cfg.Require.LicenseHeader; lh != nil {
+		if len(lh.Paths) == 0 || lh.Template == "" {
+			return cfg, fmt.Errorf("%s: require.license_header needs both paths and template", path)
+		}
+		for _, g := range append(append([]string{}, lh.Paths...), lh.Exclude...) {
+			if _, err := treeGlobRE(g); err != nil {
+				return cfg, fmt.Errorf("%s: require.license_header: bad glob %q", path, g)
+			}
+		}
+		if t := filepath.Join(filepath.Dir(path), filepath.FromSlash(lh.Template)); !fileExists(t) {
+			return cfg, fmt.Errorf("%s: require.license_header template %q: no such file", path, lh.Template)
+		}
+	}
+	if cl := cfg.Require.Changelog; cl != nil {
+		if len(cl.Paths) == 0 {
+			return cfg, fmt.Errorf("%s: require.changelog needs paths", path)
+		}
+		for _, g := range append(append([]string{}, cl.Paths...), cl.Files...) {
+			if _, err := treeGlobRE(g); err != nil {
+				return cfg, fmt.Errorf("%s: require.changelog: bad glob %q", path, g)
+			}
+		}
+		switch cl.OnMissing {
+		case "", onChangelogWarn, onChangelogBlock:
+		default:
+			return cfg, fmt.Errorf("%s: require.changelog.on_missing must be %q or %q, got %q", path, onChangelogWarn, onChangelogBlock, cl.OnMissing)
+		}
+	}
+	for _, u := range cfg.Block.URLs {
+		if strings.Trim(u, "* ") == "" {
+			return cfg, fmt.Errorf("%s: block.urls: %q would match every URL", path, u)
+		}
+	}
+	if cfg.Limits.MaxWarnings < 0 {
+		return cfg, fmt.Errorf("%s: limits.max_warnings must be >= 0", path)
+	}
+	if cfg.Limits.HookTimeout != "" {
+		if d, err := time.ParseDuration(cfg.Limits.HookTimeout); err != nil || d <= 0 {
+			return cfg, fmt.Errorf("%s: limits.hook_timeout must be a positive duration like \"5s\"", path)
+		}
+	}
+	if cfg.Notify.Interval != "" {
+		if d, err := time.ParseDuration(cfg.Notify.Interval); err != nil || d < 0 {
+			return cfg, fmt.Errorf("%s: notify.interval must be a duration like \"1m\"", path)
+		}
+	}
+	if cfg.Push.MaxCommits < 0 {
+		return cfg, fmt.Errorf("%s: push.max_commits must be >= 0", path)
+	}
+	switch cfg.Push.OnMaxCommits {
+	case "", onMaxCommitsWarn, onMaxCommitsBlock:
+	default:
+		return cfg, fmt.Errorf("%s: push.on_max_commits must be %q or %q, got %q", path, onMaxCommitsWarn, onMaxCommitsBlock, cfg.Push.OnMaxCommits)
+	}
+	switch cfg.Limits.OnTimeout {
+	case "", onTimeoutBlock, onTimeoutAllow:
+	default:
+		return cfg, fmt.Errorf("%s: limits.on_timeout must be %q or %q, got %q", path, onTimeoutBlock, onTimeoutAllow, cfg.Limits.OnTimeout)
+	}
+	if err := validateMsgSection(cfg.Message); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := validateTicketsSection(cfg.Tickets); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := validatePrepareSection(cfg.Prepare); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := validateScan(cfg.Scan); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := validateIgnore(path, cfg.Ignore); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := validateRollout(cfg.Rollout); err != nil {
+		return cfg, fmt.Errorf("%s: rollout: %w", path, err)
+	}
+	for name, rule := range cfg.Detect {
+		if findDetector(name) != nil {
+			if name != inclusiveDetector && (len(rule.Terms) > 0 || len(rule.Allow) > 0) {
+				return cfg, fmt.Errorf("%s: detect.%s: terms and allow apply only to detect.%s", path, name, inclusiveDetector)
+			}
+			if name != piiDetector && len(rule.Kinds) > 0 {
+				return cfg, fmt.Errorf("%s: detect.%s: kinds apply only to detect.%s", path, name, piiDetector)
+			}
+			if err := validatePIIKinds(rule.Kinds); err != nil {
+				return cfg, fmt.Errorf("%s: detect.%s: %w", path, name, err)
+			}
+			continue
+		}
+		if degraded {
+			delete(cfg.Detect, name)
+			skipped = append(skipped, "detect."+name)
+			continue
+		}
+		return cfg, fmt.Errorf("%s: detect.%s: unknown detector (known: %s)",
+			path, name, strings.Join(detectorNames(), ", "))
+	}
+	var ecosystems []string
+	for _, eco := range cfg.Consistency.Ecosystems {
+		if _, ok := lockfilePairs[strings.ToLower(eco)]; ok {
+			ecosystems = append(ecosystems, eco)
+			continue
+		}
+		if degraded {
+			skipped = append(skipped, "consistency.ecosystems "+eco)
+			continue
+		}
+		return cfg, fmt.Errorf("%s: consistency.ecosystems: unknown ecosystem %q (known: %s)",
+			path, eco, strings.Join(knownEcosystems(), ", "))
+	}
+	cfg.Consistency.Ecosystems = ecosystems
+	if cfg.Skip.MaxFileBytes != nil && *cfg.Skip.MaxFileBytes < 0 {
+		return cfg, fmt.Errorf("%s: skip.max_file_bytes must be >= 0", path)
+	}
+	if degraded {
+		warnDegraded(path, cfg.MinVersion, skipped)
+	}
 	return cfg, nil
 }
 
@@ -123,38 +498,172 @@ func compareSemver(a, b string) int {
 
 // walkConfig performs a single-pass walk from dir up to the filesystem root,
 // checking for snag.toml and snag-local.toml at each level. Both are merged
-// additively up the tree. Returns the resolved BlockConfig, whether any
-// config was found, and any error.
+// additively up the tree. Directories listed in SNAG_CONFIG_DIRS are merged
+// after the walk, as if they sat above the root, and the worktree's
+// .git/snag/worktree.toml after those. Returns the resolved
+// BlockConfig, whether any config was found, and any error.
 func walkConfig(dir string) (*BlockConfig, bool, error) {
+	defer traceSpan("config", "config walk", "dir", dir)()
 	bc := &BlockConfig{}
 	found := false
-	current := dir
-
-	for {
-		tomlPath := filepath.Join(current, "snag.toml")
-		localPath := filepath.Join(current, "snag-local.toml")
 
-		if fileExists(tomlPath) {
-			if err := mergeTOML(bc, tomlPath, false); err != nil {
-				return nil, false, err
-			}
-			found = true
-		}
-		if fileExists(localPath) {
-			if err := mergeTOML(bc, localPath, true); err != nil {
-				return nil, false, err
-			}
-			found = true
+	for _, d := range configChain(dir) {
+		ok, err := mergeConfigDir(bc, d)
+		if err != nil {
+			return nil, false, err
 		}
+		found = found || ok
+	}
+	ok, err := mergeWorktreeConfig(bc, dir)
+	if err != nil {
+		return nil, false, err
+	}
+	found = found || ok
+
+	return bc, found, nil
+}
 
+// configChain lists the directories consulted for config, in precedence
+// order: dir and each of its ancestors, then SNAG_CONFIG_DIRS entries. A
+// directory reached twice (say, an ancestor also named in SNAG_CONFIG_DIRS)
+// is listed once, where it first appears.
+func configChain(dir string) []string {
+	var chain []string
+	seen := make(map[string]bool)
+	add := func(d string) {
+		if k := dirKey(runtime.GOOS, d); !seen[k] {
+			seen[k] = true
+			chain = append(chain, d)
+		}
+	}
+	current := dir
+	for {
+		add(current)
 		parent := filepath.Dir(current)
 		if parent == current {
 			break
 		}
 		current = parent
 	}
+	for _, d := range configDirsFromEnv() {
+		add(d)
+	}
+	return chain
+}
 
-	return bc, found, nil
+// dirKey returns the form of dir used to tell whether two paths name the same
+// directory. On Windows paths are case-insensitive and the working directory,
+// SNAG_CONFIG_DIRS and git disagree on drive-letter case and separators
+// (C:\Repo, c:\repo, C:/Repo), so the key is lowercased with backslashes.
+func dirKey(goos, dir string) string {
+	if goos == "windows" {
+		k := strings.ToLower(strings.ReplaceAll(dir, "/", `\`))
+		if len(k) > len(`c:\`) {
+			k = strings.TrimRight(k, `\`)
+		}
+		return k
+	}
+	return filepath.Clean(dir)
+}
+
+// configDirsFromEnv parses SNAG_CONFIG_DIRS, a PATH-style list (colon-
+// separated; semicolons on Windows) of extra directories holding snag.toml
+// or snag-local.toml. Lets dotfile managers keep machine-specific policy
+// outside the repo hierarchy. Empty entries are skipped.
+func configDirsFromEnv() []string {
+	env := os.Getenv("SNAG_CONFIG_DIRS")
+	if env == "" {
+		return nil
+	}
+	var dirs []string
+	for _, d := range filepath.SplitList(env) {
+		d = strings.TrimSpace(d)
+		if d == "" {
+			continue
+		}
+		if abs, err := filepath.Abs(d); err == nil {
+			d = abs
+		}
+		dirs = append(dirs, d)
+	}
+	return dirs
+}
+
+// mergeConfigDir merges the config files of dir (see dirConfigFiles) into
+// bc. Reports whether any file existed.
+func mergeConfigDir(bc *BlockConfig, dir string) (bool, error) {
+	defer traceSpan("config", "config dir", "dir", dir)()
+	files, err := dirConfigFiles(dir, "")
+	if err != nil {
+		return false, err
+	}
+	mergeConfigFiles(bc, files)
+	return len(files) > 0, nil
+}
+
+// configFile is one file merged from a directory.
+type configFile struct {
+	Path     string
+	Cfg      snagTOML
+	Included bool // reached through another file's include
+}
+
+// dirConfigFiles returns the config files of one directory, the one whose
+// settings win first. By default that is the snag-local overlays (later
+// names in localConfigNames first), then snag.toml; each file comes before
+// the files it includes, and sibling includes in the order listed. A file's
+// priority = N moves it: higher priorities win, and ties keep the default
+// order. shared stands in for dir's snag.toml when not "".
+func dirConfigFiles(dir, shared string) ([]configFile, error) {
+	var roots []string
+	for i := len(localConfigNames) - 1; i >= 0; i-- {
+		if path := filepath.Join(dir, localConfigNames[i]); fileExists(path) {
+			roots = append(roots, path)
+		}
+	}
+	if shared == "" {
+		shared = filepath.Join(dir, "snag.toml")
+	}
+	if fileExists(shared) {
+		roots = append(roots, shared)
+	}
+
+	var files []configFile
+	var add func(path string, included bool, stack []string) error
+	add = func(path string, included bool, stack []string) error {
+		cfg, err := loadSnagTOML(path)
+		if err != nil {
+			return err
+		}
+		includes, err := includePaths(path, cfg.Include, stack)
+		if err != nil {
+			return err
+		}
+		files = append(files, configFile{Path: path, Cfg: cfg, Included: included})
+		stack = append(stack, absPath(path))
+		for _, inc := range includes {
+			if err := add(inc, true, stack); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, path := range roots {
+		if err := add(path, false, nil); err != nil {
+			return nil, err
+		}
+	}
+	slices.SortStableFunc(files, func(a, b configFile) int { return b.Cfg.Priority - a.Cfg.Priority })
+	return files, nil
+}
+
+// mergeConfigFiles merges files in the order dirConfigFiles returns them.
+// Scalar settings keep the first value set, so the winning file's value
+// holds both within the directory and over directories farther up.
+func mergeConfigFiles(bc *BlockConfig, files []configFile) {
+	for _, f := range files {
+		applyTOML(bc, f.Cfg, f.Path, false)
+	}
 }
 
 // fileExists reports whether path exists and is not a directory.
@@ -167,16 +676,80 @@ func fileExists(path string) bool {
 }
 
 // mergeTOML reads a snag.toml and appends its patterns into bc.
-// If forceAuditOverride is true, scalar audit settings from this file override
-// any previously resolved value. This lets `snag-local.toml` override
-// `snag.toml` in the same directory while still preserving nearest-config-wins
-// behavior as the walk moves toward parent directories.
+// If forceAuditOverride is true, scalar audit/skip settings from this file override
+// any previously resolved value, as worktree.toml does for the whole chain.
+// Directory walks go through mergeConfigDir instead.
 func mergeTOML(bc *BlockConfig, path string, forceAuditOverride ...bool) error {
+	overrideAudit := len(forceAuditOverride) > 0 && forceAuditOverride[0]
+	return mergeTOMLIncludes(bc, path, overrideAudit, nil)
+}
+
+// mergeTOMLIncludes merges path and, recursively, the files it includes.
+// stack holds the including files, for cycle detection. A file's own
+// settings win over its includes': includes merge after it when the first
+// value wins, and before it when the last value wins (overrideAudit).
+func mergeTOMLIncludes(bc *BlockConfig, path string, overrideAudit bool, stack []string) error {
 	cfg, err := loadSnagTOML(path)
 	if err != nil {
 		return err
 	}
-	overrideAudit := len(forceAuditOverride) > 0 && forceAuditOverride[0]
+	includes, err := includePaths(path, cfg.Include, stack)
+	if err != nil {
+		return err
+	}
+	stack = append(stack, absPath(path))
+	if !overrideAudit {
+		applyTOML(bc, cfg, path, overrideAudit)
+	}
+	for _, inc := range includes {
+		if err := mergeTOMLIncludes(bc, inc, overrideAudit, stack); err != nil {
+			return err
+		}
+	}
+	if overrideAudit {
+		applyTOML(bc, cfg, path, overrideAudit)
+	}
+	return nil
+}
+
+// includePaths resolves a file's include list relative to its directory.
+// Includes must stay within that directory tree and must not lead back to a
+// file already being merged.
+func includePaths(path string, include, stack []string) ([]string, error) {
+	if len(include) == 0 {
+		return nil, nil
+	}
+	self := absPath(path)
+	dir := filepath.Dir(self)
+	var out []string
+	for _, inc := range include {
+		rel := filepath.Clean(filepath.FromSlash(inc))
+		if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("%s: include %q must be a path inside %s", path, inc, dir)
+		}
+		full := filepath.Join(dir, rel)
+		if full == self || slices.Contains(stack, full) {
+			chain := append(append([]string{}, stack...), self, full)
+			return nil, fmt.Errorf("include cycle: %s", strings.Join(chain, " -> "))
+		}
+		if !fileExists(full) {
+			return nil, fmt.Errorf("%s: include %q: no such file", path, inc)
+		}
+		out = append(out, full)
+	}
+	return out, nil
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// applyTOML merges one parsed file's settings into bc.
+func applyTOML(bc *BlockConfig, cfg snagTOML, path string, overrideAudit bool) {
+	applyRules(&cfg.Block)
 	bc.Diff = append(bc.Diff, cfg.Block.Diff...)
 	bc.Msg = append(bc.Msg, cfg.Block.Msg...)
 	if cfg.Block.Push != nil {
@@ -185,6 +758,7 @@ func mergeTOML(bc *BlockConfig, path string, forceAuditOverride ...bool) error {
 		bc.Push = merged
 	}
 	bc.Branch = append(bc.Branch, cfg.Block.Branch...)
+	bc.Ignore = append(bc.Ignore, cfg.Ignore...)
 	if cfg.Block.MsgMaxLen > bc.MsgMaxLen {
 		bc.MsgMaxLen = cfg.Block.MsgMaxLen
 	}
@@ -195,7 +769,154 @@ func mergeTOML(bc *BlockConfig, path string, forceAuditOverride ...bool) error {
 		limit := *cfg.Audit.Limit
 		bc.AuditLimit = &limit
 	}
-	return nil
+	if cfg.Block.CommitHours != "" && (bc.CommitHours == "" || overrideAudit) {
+		bc.CommitHours = cfg.Block.CommitHours
+	}
+	if cfg.Block.DateTolerance != "" && (bc.DateTolerance == 0 || overrideAudit) {
+		bc.DateTolerance, _ = time.ParseDuration(cfg.Block.DateTolerance)
+	}
+	bc.Executable = append(bc.Executable, cfg.Block.Executable...)
+	bc.RequireExecutable = append(bc.RequireExecutable, cfg.Block.RequireExecutable...)
+	bc.URLs = append(bc.URLs, cfg.Block.URLs...)
+	bc.BlockEmpty = bc.BlockEmpty || cfg.Block.Empty
+	bc.BlockWhitespaceOnly = bc.BlockWhitespaceOnly || cfg.Block.WhitespaceOnly
+	markRollout(bc, cfg)
+	if cfg.Message.Language != "" && (bc.MsgLanguage == "" || overrideAudit) {
+		bc.MsgLanguage = strings.ToLower(cfg.Message.Language)
+	}
+	if cfg.Message.OnLanguage != "" && (bc.OnMsgLanguage == "" || overrideAudit) {
+		bc.OnMsgLanguage = cfg.Message.OnLanguage
+	}
+	if t := cfg.Tickets; t.Provider != "" && (bc.TicketProvider == "" || overrideAudit) {
+		bc.TicketProvider, bc.TicketURL = t.Provider, t.URL
+	}
+	if cfg.Tickets.Pattern != "" && (bc.TicketPattern == "" || overrideAudit) {
+		bc.TicketPattern = cfg.Tickets.Pattern
+	}
+	if cfg.Tickets.OnInvalid != "" && (bc.OnTicketInvalid == "" || overrideAudit) {
+		bc.OnTicketInvalid = cfg.Tickets.OnInvalid
+	}
+	bc.TicketProjects = append(bc.TicketProjects, cfg.Tickets.Projects...)
+	bc.TicketVerify = bc.TicketVerify || cfg.Tickets.Verify
+	bc.PrepareTicket = bc.PrepareTicket || cfg.Prepare.Ticket
+	bc.PrepareType = bc.PrepareType || cfg.Prepare.Type
+	for prefix, typ := range cfg.Prepare.Types {
+		prefix = strings.ToLower(prefix)
+		if _, ok := bc.PrepareTypes[prefix]; !ok || overrideAudit {
+			if bc.PrepareTypes == nil {
+				bc.PrepareTypes = map[string]string{}
+			}
+			bc.PrepareTypes[prefix] = typ
+		}
+	}
+	if cfg.Limits.MaxWarnings > 0 && (bc.MaxWarnings == 0 || overrideAudit) {
+		bc.MaxWarnings = cfg.Limits.MaxWarnings
+	}
+	if cfg.Limits.HookTimeout != "" && (bc.HookTimeout == 0 || overrideAudit) {
+		bc.HookTimeout, _ = time.ParseDuration(cfg.Limits.HookTimeout)
+	}
+	if cfg.Limits.OnTimeout != "" && (bc.OnTimeout == "" || overrideAudit) {
+		bc.OnTimeout = cfg.Limits.OnTimeout
+	}
+	if cfg.Block.Sensitive {
+		markSensitive(bc, cfg.Block)
+	}
+	for from, to := range cfg.Redact {
+		if bc.Redact == nil {
+			bc.Redact = make(map[string]string)
+		}
+		if _, ok := bc.Redact[from]; !ok {
+			bc.Redact[from] = to
+		}
+	}
+	for name, rule := range cfg.Detect {
+		if rule.Enabled != nil {
+			if bc.DetectEnabled == nil {
+				bc.DetectEnabled = make(map[string]bool)
+			}
+			if _, set := bc.DetectEnabled[name]; !set || overrideAudit {
+				bc.DetectEnabled[name] = *rule.Enabled
+			}
+		}
+		for term, suggest := range rule.Terms {
+			if bc.InclusiveTerms == nil {
+				bc.InclusiveTerms = make(map[string]string)
+			}
+			if _, ok := bc.InclusiveTerms[term]; !ok {
+				bc.InclusiveTerms[term] = suggest
+			}
+		}
+		bc.InclusiveAllow = append(bc.InclusiveAllow, rule.Allow...)
+		for _, k := range rule.Kinds {
+			if !slices.Contains(bc.PIIKinds, k) {
+				bc.PIIKinds = append(bc.PIIKinds, k)
+			}
+		}
+		if len(rule.Exclude) > 0 {
+			if bc.DetectExclude == nil {
+				bc.DetectExclude = make(map[string][]string)
+			}
+			bc.DetectExclude[name] = append(bc.DetectExclude[name], rule.Exclude...)
+		}
+	}
+	bc.AllowedRemotes = append(bc.AllowedRemotes, cfg.Push.AllowedRemotes...)
+	bc.BlockProtectedMismatch = bc.BlockProtectedMismatch || cfg.Push.BlockProtectedMismatch
+	bc.ForbidMergeCommits = bc.ForbidMergeCommits || cfg.Push.ForbidMergeCommits
+	bc.BlockCommitOnProtected = bc.BlockCommitOnProtected || cfg.Branch.BlockCommit
+	if cfg.Behavior.Network != nil && !*cfg.Behavior.Network && bc.NetworkOffBy == "" {
+		bc.NetworkOffBy = path
+	}
+	bc.VersionCheckOff = bc.VersionCheckOff || (cfg.Behavior.VersionCheck != nil && !*cfg.Behavior.VersionCheck)
+	bc.PolicyTrailer = bc.PolicyTrailer || cfg.Behavior.PolicyTrailer
+	bc.NotifyDesktop = bc.NotifyDesktop || cfg.Notify.Desktop
+	bc.BellOff = bc.BellOff || (cfg.Notify.Bell != nil && !*cfg.Notify.Bell)
+	bc.VisualBell = bc.VisualBell || cfg.Notify.VisualBell
+	bc.PlainOutput = bc.PlainOutput || cfg.Behavior.PlainOutput
+	if cfg.Notify.Interval != "" && (bc.NotifyInterval == 0 || overrideAudit) {
+		bc.NotifyInterval, _ = time.ParseDuration(cfg.Notify.Interval)
+	}
+	bc.ForbidFixupCommits = bc.ForbidFixupCommits || cfg.Push.ForbidFixupCommits
+	if cfg.Push.MaxCommits > 0 && (bc.MaxPushCommits == 0 || overrideAudit) {
+		bc.MaxPushCommits = cfg.Push.MaxCommits
+	}
+	if cfg.Push.OnMaxCommits != "" && (bc.OnMaxPushCommits == "" || overrideAudit) {
+		bc.OnMaxPushCommits = cfg.Push.OnMaxCommits
+	}
+	for _, e := range cfg.Scan.settings() {
+		if bc.Scan == nil {
+			bc.Scan = make(map[string]string)
+		}
+		if _, set := bc.Scan[e.Hook]; !set || overrideAudit {
+			bc.Scan[e.Hook] = e.Mode
+		}
+	}
+	bc.Ecosystems = append(bc.Ecosystems, cfg.Consistency.Ecosystems...)
+	bc.AllowLockfileOnly = bc.AllowLockfileOnly || cfg.Consistency.AllowLockfileOnly
+	bc.FormatTrailingWhitespace = bc.FormatTrailingWhitespace || cfg.Format.TrailingWhitespace
+	bc.FormatFinalNewline = bc.FormatFinalNewline || cfg.Format.FinalNewline
+	bc.FormatCRLF = bc.FormatCRLF || cfg.Format.CRLF
+	bc.FormatExclude = append(bc.FormatExclude, cfg.Format.Exclude...)
+	if lh := cfg.Require.LicenseHeader; lh != nil {
+		bc.LicensePaths = append(bc.LicensePaths, lh.Paths...)
+		bc.LicenseExclude = append(bc.LicenseExclude, lh.Exclude...)
+		if lh.Template != "" && (bc.LicenseTemplate == "" || overrideAudit) {
+			bc.LicenseTemplate = filepath.Join(filepath.Dir(absPath(path)), filepath.FromSlash(lh.Template))
+		}
+	}
+	if cl := cfg.Require.Changelog; cl != nil {
+		bc.ChangelogPaths = append(bc.ChangelogPaths, cl.Paths...)
+		bc.ChangelogFiles = append(bc.ChangelogFiles, cl.Files...)
+		if cl.OnMissing != "" && (bc.OnChangelogMissing == "" || overrideAudit) {
+			bc.OnChangelogMissing = cl.OnMissing
+		}
+	}
+	bc.PacksAuto = bc.PacksAuto || cfg.PacksAuto
+	bc.ExemptAuthors = append(bc.ExemptAuthors, cfg.Exempt.Authors...)
+	bc.SkipExtensions = append(bc.SkipExtensions, cfg.Skip.Extensions...)
+	if cfg.Skip.MaxFileBytes != nil && (bc.MaxFileBytes == nil || overrideAudit) {
+		max := *cfg.Skip.MaxFileBytes
+		bc.MaxFileBytes = &max
+	}
 }
 
 // pushOrNil returns bc.Push or nil if not set.
@@ -209,7 +930,8 @@ func (bc *BlockConfig) pushOrNil() []string {
 // resolveBlockConfig builds the per-hook BlockConfig using all config sources.
 //
 // Precedence:
-//  1. snag.toml walk (CWD → root, additive merge of snag.toml + snag-local.toml)
+//  1. snag.toml walk (CWD → root, additive merge of snag.toml + snag-local.toml),
+//     followed by any SNAG_CONFIG_DIRS entries
 //  2. SNAG_PROTECTED_BRANCHES env var → always merges into Branch
 //  3. Default protected branches ["main", "master"] → only when Branch is still empty
 func resolveBlockConfig(cmd *cobra.Command) (*BlockConfig, error) {
@@ -217,10 +939,31 @@ func resolveBlockConfig(cmd *cobra.Command) (*BlockConfig, error) {
 	if err != nil {
 		return nil, fmt.Errorf("getting working directory: %w", err)
 	}
-	bc, _, err := walkConfig(cwd)
+	return resolveBlockConfigAt(cmd, cwd)
+}
+
+// resolveBlockConfigAt is resolveBlockConfig with the walk starting at dir
+// instead of the working directory.
+func resolveBlockConfigAt(cmd *cobra.Command, dir string) (*BlockConfig, error) {
+	bc, _, err := walkConfig(dir)
 	if err != nil {
 		return nil, err
 	}
+	normalizeBlockConfig(bc, dir)
+	if err := loadSuppressions(bc, dir); err != nil {
+		return nil, err
+	}
+	if cmd != nil {
+		applyOutputPrefs(bc)
+	}
+	return bc, nil
+}
+
+// normalizeBlockConfig applies environment overlays, defaults, lowercasing,
+// deduplication, SNAG_IGNORE and packs_auto to a freshly merged config for
+// the repository at dir.
+func normalizeBlockConfig(bc *BlockConfig, dir string) {
+	applyPacks(bc, dir)
 
 	// Overlay SNAG_PROTECTED_BRANCHES env var into Branch.
 	if env := os.Getenv("SNAG_PROTECTED_BRANCHES"); env != "" {
@@ -251,12 +994,21 @@ func resolveBlockConfig(cmd *cobra.Command) (*BlockConfig, error) {
 		bc.Push = deduplicatePatterns(bc.Push)
 	}
 	bc.Branch = deduplicatePatterns(bc.Branch)
+	bc.SkipExtensions = deduplicatePatterns(lowercaseAll(bc.SkipExtensions))
+	bc.AllowedRemotes = deduplicatePatterns(bc.AllowedRemotes)
+	bc.Ecosystems = deduplicatePatterns(lowercaseAll(bc.Ecosystems))
+	bc.Executable = deduplicatePatterns(bc.Executable)
+	bc.RequireExecutable = deduplicatePatterns(bc.RequireExecutable)
+	bc.URLs = deduplicatePatterns(lowercaseAll(bc.URLs))
+	bc.ExemptAuthors = deduplicatePatterns(bc.ExemptAuthors)
 
-	// Apply SNAG_IGNORE suppressions.
+	// Apply worktree.toml ignore entries, then SNAG_IGNORE.
+	if len(bc.Ignore) > 0 {
+		applyIgnore(bc, strings.Join(bc.Ignore, ","))
+	}
 	if env := os.Getenv("SNAG_IGNORE"); env != "" {
 		applyIgnore(bc, env)
 	}
-	return bc, nil
 }
 
 // applyIgnore parses the SNAG_IGNORE value and removes matching patterns from bc.
@@ -4,13 +4,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
 )
 
 func buildConfigCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "config",
 		Short: "Show resolved block patterns and their sources",
 		Long: `Show resolved block patterns and their sources.
@@ -21,21 +22,71 @@ a ~ prefix.`,
 		SilenceUsage: true,
 		RunE:         runConfig,
 	}
+	cmd.Flags().Bool("effective", false, "show the merge order and the resolved settings instead of each source")
+	cmd.AddCommand(buildConfigHashCmd(), buildConfigHistoryCmd(), buildConfigSetCmd(), buildConfigGetCmd(), buildConfigFuzzCmd())
+	return cmd
 }
 
 // configSource pairs a source label with the patterns it contributes.
 type configSource struct {
 	Label       string
-	Kind        string // "toml", "env", "default"
+	Kind        string // "toml", "env", "default", "ignore"
 	Diff        []string
 	Msg         []string
 	Push        *[]string // nil = not set
 	Branch      []string
 	MsgMaxLen   int
 	MsgMaxLines int
+	Message     msgSection
+	Tickets     ticketsSection
+	Prepare     prepareSection
+	Sensitive   bool // patterns are redacted when printed
+
+	CommitHours    string
+	DateTolerance  string
+	Empty          bool
+	WhitespaceOnly bool
+
+	Executable        []string
+	RequireExecutable []string
+	URLs              []string
+	Rules             []conditionalRule
+
+	SkipExtensions []string
+	MaxFileBytes   *int
+	Scan           scanSection
+	AllowedRemotes []string
+	ExemptAuthors  []string
+
+	BlockProtectedMismatch bool
+	ForbidMergeCommits     bool
+	ForbidFixupCommits     bool
+	MaxCommits             int
+	OnMaxCommits           string
+	BlockCommit            bool
+
+	Ecosystems        []string
+	AllowLockfileOnly bool
+
+	Detect  map[string]detectRuleSection
+	Format  formatSection
+	Require requireSection
+	Rollout rolloutSection
+	Limits  limitsSection
+
+	Network       *bool
+	VersionCheck  *bool
+	PolicyTrailer bool
+	PlainOutput   bool
+	PacksAuto     bool
+	Notify        notifySection
+	Ignore        []string // worktree.toml only
 }
 
 func runConfig(cmd *cobra.Command, args []string) error {
+	if effective, _ := cmd.Flags().GetBool("effective"); effective {
+		return runConfigEffective(cmd)
+	}
 	sources, err := collectSources(cmd)
 	if err != nil {
 		return err
@@ -54,18 +105,158 @@ func runConfig(cmd *cobra.Command, args []string) error {
 
 		switch src.Kind {
 		case "toml":
-			printSection("diff", src.Diff)
-			printSection("msg", src.Msg)
+			show := func(ps []string) []string { return ps }
+			if src.Sensitive {
+				show = redactAll
+			}
+			printSection("diff", show(src.Diff))
+			printSection("msg", show(src.Msg))
 			if src.Push != nil {
-				printSection("push", *src.Push)
+				printSection("push", show(*src.Push))
 			}
 			printSection("branch", src.Branch)
+			if len(src.Rules) > 0 {
+				meta := currentRepoMeta()
+				for _, r := range src.Rules {
+					state := "inactive here"
+					if r.applies(meta) {
+						state = "active"
+					}
+					pattern := r.Pattern
+					if src.Sensitive {
+						pattern = redact(pattern)
+					}
+					if r.Normalize {
+						pattern = normPatternPrefix + pattern
+					}
+					fprintPlain(os.Stdout, "  %-8s %s (%s) when %s — %s\n", "rule:", pattern, strings.Join(r.hooks(), ", "), r.scope(), state)
+				}
+			}
 			if src.MsgMaxLen > 0 {
 				fmt.Printf("  %-8s %d\n", "msg_max_len:", src.MsgMaxLen)
 			}
 			if src.MsgMaxLines > 0 {
 				fmt.Printf("  %-8s %d\n", "msg_max_lines:", src.MsgMaxLines)
 			}
+			if src.CommitHours != "" {
+				fmt.Printf("  %-8s %s\n", "commit_hours:", src.CommitHours)
+			}
+			if src.DateTolerance != "" {
+				fmt.Printf("  %-8s %s\n", "date_tolerance:", src.DateTolerance)
+			}
+			if src.Message.Language != "" {
+				fmt.Printf("  %-8s %s\n", "msg.language:", src.Message.Language)
+			}
+			if src.Message.OnLanguage != "" {
+				fmt.Printf("  %-8s %s\n", "msg.on_language:", src.Message.OnLanguage)
+			}
+			printTickets(src.Tickets)
+			printPrepare(src.Prepare)
+			if src.Empty {
+				fmt.Printf("  %-8s %v\n", "empty:", true)
+			}
+			if src.WhitespaceOnly {
+				fmt.Printf("  %-8s %v\n", "whitespace_only:", true)
+			}
+			for _, e := range src.Scan.settings() {
+				fmt.Printf("  %-8s %s\n", "scan."+e.Hook+":", e.Mode)
+			}
+			printSection("executable", src.Executable)
+			printSection("require_executable", src.RequireExecutable)
+			printSection("urls", src.URLs)
+			printSection("allowed_remotes", src.AllowedRemotes)
+			printSection("exempt_authors", src.ExemptAuthors)
+			if src.BlockProtectedMismatch {
+				fmt.Printf("  %-8s %v\n", "block_protected_mismatch:", true)
+			}
+			if src.ForbidMergeCommits {
+				fmt.Printf("  %-8s %v\n", "forbid_merge_commits:", true)
+			}
+			if src.ForbidFixupCommits {
+				fmt.Printf("  %-8s %v\n", "forbid_fixup_commits:", true)
+			}
+			if src.MaxCommits > 0 {
+				fmt.Printf("  %-8s %d\n", "max_commits:", src.MaxCommits)
+			}
+			if src.OnMaxCommits != "" {
+				fmt.Printf("  %-8s %s\n", "on_max_commits:", src.OnMaxCommits)
+			}
+			if src.BlockCommit {
+				fmt.Printf("  %-8s %v\n", "block_commit:", true)
+			}
+			printSection("consistency", src.Ecosystems)
+			if src.AllowLockfileOnly {
+				fmt.Printf("  %-8s %v\n", "allow_lockfile_only:", true)
+			}
+			printDetect(src.Detect)
+			printFormat(src.Format)
+			if lh := src.Require.LicenseHeader; lh != nil {
+				printSection("license_header.paths", lh.Paths)
+				printSection("license_header.exclude", lh.Exclude)
+				fmt.Printf("  %-8s %s\n", "license_header.template:", lh.Template)
+			}
+			if cl := src.Require.Changelog; cl != nil {
+				printSection("changelog.paths", cl.Paths)
+				printSection("changelog.files", cl.Files)
+				if cl.OnMissing != "" {
+					fmt.Printf("  %-8s %s\n", "changelog.on_missing:", cl.OnMissing)
+				}
+			}
+			if src.Limits.MaxWarnings > 0 {
+				fmt.Printf("  %-8s %d\n", "max_warnings:", src.Limits.MaxWarnings)
+			}
+			if src.Limits.HookTimeout != "" {
+				fmt.Printf("  %-8s %s\n", "hook_timeout:", src.Limits.HookTimeout)
+			}
+			if src.Limits.OnTimeout != "" {
+				fmt.Printf("  %-8s %s\n", "on_timeout:", src.Limits.OnTimeout)
+			}
+			if src.Network != nil {
+				fmt.Printf("  %-8s %v\n", "network:", *src.Network)
+			}
+			if src.VersionCheck != nil {
+				fmt.Printf("  %-8s %v\n", "version_check:", *src.VersionCheck)
+			}
+			if src.PolicyTrailer {
+				fmt.Printf("  %-8s %v\n", "policy_trailer:", true)
+			}
+			if src.PacksAuto {
+				fmt.Printf("  %-8s %v\n", "packs_auto:", true)
+			}
+			if src.Notify.Desktop {
+				fmt.Printf("  %-8s %v\n", "notify.desktop:", true)
+			}
+			if src.Notify.Interval != "" {
+				fmt.Printf("  %-8s %s\n", "notify.interval:", src.Notify.Interval)
+			}
+			if src.Notify.Bell != nil {
+				fmt.Printf("  %-8s %v\n", "notify.bell:", *src.Notify.Bell)
+			}
+			if src.Notify.VisualBell {
+				fmt.Printf("  %-8s %v\n", "notify.visual_bell:", true)
+			}
+			if src.PlainOutput {
+				fmt.Printf("  %-8s %v\n", "plain_output:", true)
+			}
+			if src.Rollout.Date != "" {
+				fmt.Printf("  %-8s %s %s\n", "rollout:", src.Rollout.Mode, src.Rollout.Date)
+			} else if src.Rollout.Days > 0 {
+				fmt.Printf("  %-8s %s %d days after first seen\n", "rollout:", src.Rollout.Mode, src.Rollout.Days)
+			} else if src.Rollout.Mode != "" {
+				fmt.Printf("  %-8s %s\n", "rollout:", src.Rollout.Mode)
+			}
+			printSection("skip", src.SkipExtensions)
+			if src.MaxFileBytes != nil {
+				fmt.Printf("  %-8s %d\n", "max_file_bytes:", *src.MaxFileBytes)
+			}
+			if ig := parseIgnoreSource(strings.Join(src.Ignore, ",")); ig != nil {
+				printIgnoreSection("ignore.diff", ig.Diff)
+				printIgnoreSection("ignore.msg", ig.Msg)
+				if ig.Push != nil {
+					printIgnoreSection("ignore.push", *ig.Push)
+				}
+				printIgnoreSection("ignore.branch", ig.Branch)
+			}
 		case "env":
 			printSection("branch", src.Branch)
 		case "default":
@@ -80,6 +271,35 @@ func runConfig(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Show what packs_auto detected, since it enables rules no file names.
+	for _, src := range sources {
+		if !src.PacksAuto {
+			continue
+		}
+		if bc, err := resolveBlockConfig(cmd); err == nil {
+			cwd, _ := os.Getwd()
+			fmt.Println()
+			fmt.Println(hintStyle.Render("# packs_auto"))
+			for _, line := range packsReport(bc, cwd) {
+				fmt.Printf("  %s\n", line)
+			}
+		}
+		break
+	}
+
+	// .snagignore isn't a config layer, but it changes what blocks.
+	if bc, err := resolveBlockConfig(cmd); err == nil && len(bc.Suppressions) > 0 {
+		active := 0
+		for _, s := range bc.Suppressions {
+			if s.Active() {
+				active++
+			}
+		}
+		fmt.Println()
+		fmt.Println(hintStyle.Render("# " + suppressFile))
+		fmt.Printf("  %d active, %d expired (snag suppressions list)\n", active, len(bc.Suppressions)-active)
+	}
+
 	// Show the effective push behavior if no source explicitly set push.
 	hasPush := false
 	for _, src := range sources {
@@ -96,11 +316,81 @@ func runConfig(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// printDetect prints [detect.NAME] overrides in registry order.
+func printDetect(rules map[string]detectRuleSection) {
+	for _, name := range detectorNames() {
+		rule, ok := rules[name]
+		if !ok {
+			continue
+		}
+		if rule.Enabled != nil {
+			fmt.Printf("  %-8s %v\n", "detect."+name+":", *rule.Enabled)
+		}
+		printSection("detect."+name+".exclude", rule.Exclude)
+		terms := make([]string, 0, len(rule.Terms))
+		for term, suggest := range rule.Terms {
+			terms = append(terms, term+" → "+suggest)
+		}
+		sort.Strings(terms)
+		printSection("detect."+name+".terms", terms)
+		printSection("detect."+name+".allow", rule.Allow)
+		printSection("detect."+name+".kinds", rule.Kinds)
+	}
+}
+
+// printTickets prints the [tickets] settings that are set.
+func printTickets(t ticketsSection) {
+	for _, kv := range [][2]string{
+		{"tickets.provider:", t.Provider}, {"tickets.url:", t.URL},
+		{"tickets.pattern:", t.Pattern}, {"tickets.on_invalid:", t.OnInvalid},
+	} {
+		if kv[1] != "" {
+			fmt.Printf("  %-8s %s\n", kv[0], kv[1])
+		}
+	}
+	printSection("tickets.projects", t.Projects)
+	if t.Verify {
+		fmt.Printf("  %-8s %v\n", "tickets.verify:", true)
+	}
+}
+
+// printPrepare prints the [prepare] settings that are set.
+func printPrepare(p prepareSection) {
+	if p.Ticket {
+		fmt.Printf("  %-8s %v\n", "prepare.ticket:", true)
+	}
+	if p.Type {
+		fmt.Printf("  %-8s %v\n", "prepare.type:", true)
+	}
+	types := make([]string, 0, len(p.Types))
+	for prefix, typ := range p.Types {
+		types = append(types, prefix+" → "+typ)
+	}
+	sort.Strings(types)
+	printSection("prepare.types", types)
+}
+
+// printFormat prints the enabled [format] checks.
+func printFormat(f formatSection) {
+	var checks []string
+	if f.TrailingWhitespace {
+		checks = append(checks, "trailing_whitespace")
+	}
+	if f.FinalNewline {
+		checks = append(checks, "final_newline")
+	}
+	if f.CRLF {
+		checks = append(checks, "crlf")
+	}
+	printSection("format", checks)
+	printSection("format.exclude", f.Exclude)
+}
+
 func printSection(name string, patterns []string) {
 	if len(patterns) == 0 {
 		return
 	}
-	fmt.Printf("  %-8s %s\n", name+":", strings.Join(patterns, ", "))
+	fprintPlain(os.Stdout, "  %-8s %s\n", name+":", strings.Join(patterns, ", "))
 }
 
 // collectSources gathers config sources with provenance for display.
@@ -158,7 +448,8 @@ func collectSources(cmd *cobra.Command) ([]configSource, error) {
 	return sources, nil
 }
 
-// walkConfigSources walks from CWD to root collecting config files with paths.
+// walkConfigSources walks from CWD to root, then SNAG_CONFIG_DIRS, then the
+// worktree's worktree.toml, collecting config files with paths.
 func walkConfigSources() ([]configSource, error) {
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -166,37 +457,62 @@ func walkConfigSources() ([]configSource, error) {
 	}
 
 	var sources []configSource
-	current := cwd
 
-	for {
-		tomlPath := filepath.Join(current, "snag.toml")
-		localPath := filepath.Join(current, "snag-local.toml")
-
-		if fileExists(tomlPath) {
-			if src, err := tomlSource(tomlPath); err != nil {
-				return nil, err
-			} else if src != nil {
-				sources = append(sources, *src)
-			}
-		}
-		if fileExists(localPath) {
-			if src, err := tomlSource(localPath); err != nil {
-				return nil, err
-			} else if src != nil {
-				sources = append(sources, *src)
-			}
+	files, err := configMergeOrder(cwd)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if src, err := tomlSource(f.Path); err != nil {
+			return nil, err
+		} else if src != nil {
+			src.Label = configFileLabel(f)
+			sources = append(sources, *src)
 		}
+	}
 
-		parent := filepath.Dir(current)
-		if parent == current {
-			break
+	if path := worktreeConfigPath(cwd); path != "" && fileExists(path) {
+		if src, err := tomlSource(path); err != nil {
+			return nil, err
+		} else if src != nil {
+			src.Label = "worktree: " + src.Label
+			sources = append(sources, *src)
 		}
-		current = parent
 	}
 
 	return sources, nil
 }
 
+// configMergeOrder lists the config files in the chain from dir in the
+// order they merge: directory by directory, nearest first, and within a
+// directory as dirConfigFiles orders them.
+func configMergeOrder(dir string) ([]configFile, error) {
+	var out []configFile
+	for _, d := range configChain(dir) {
+		files, err := dirConfigFiles(d, "")
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, files...)
+	}
+	return out, nil
+}
+
+// configFileLabel names a merged file for display.
+func configFileLabel(f configFile) string {
+	label, _ := filepath.Abs(f.Path)
+	if f.Included {
+		label += " (included)"
+	}
+	if f.Cfg.Priority != 0 {
+		label += fmt.Sprintf(" (priority %d)", f.Cfg.Priority)
+	}
+	if len(f.Cfg.Hosts) > 0 {
+		label += " (host " + strings.Join(quoteAll(f.Cfg.Hosts), ", ") + ")"
+	}
+	return label
+}
+
 func tomlSource(path string) (*configSource, error) {
 	cfg, err := loadSnagTOML(path)
 	if err != nil {
@@ -212,10 +528,60 @@ func tomlSource(path string) (*configSource, error) {
 		Branch:      cfg.Block.Branch,
 		MsgMaxLen:   cfg.Block.MsgMaxLen,
 		MsgMaxLines: cfg.Block.MsgMaxLines,
+		Message:     cfg.Message,
+		Tickets:     cfg.Tickets,
+		Prepare:     cfg.Prepare,
+		Sensitive:   cfg.Block.Sensitive,
+
+		CommitHours:    cfg.Block.CommitHours,
+		DateTolerance:  cfg.Block.DateTolerance,
+		Empty:          cfg.Block.Empty,
+		WhitespaceOnly: cfg.Block.WhitespaceOnly,
+
+		Executable:        cfg.Block.Executable,
+		RequireExecutable: cfg.Block.RequireExecutable,
+		URLs:              cfg.Block.URLs,
+		Rules:             cfg.Block.Rule,
+
+		SkipExtensions: cfg.Skip.Extensions,
+		MaxFileBytes:   cfg.Skip.MaxFileBytes,
+		Scan:           cfg.Scan,
+		AllowedRemotes: cfg.Push.AllowedRemotes,
+		ExemptAuthors:  cfg.Exempt.Authors,
+
+		BlockProtectedMismatch: cfg.Push.BlockProtectedMismatch,
+		ForbidMergeCommits:     cfg.Push.ForbidMergeCommits,
+		ForbidFixupCommits:     cfg.Push.ForbidFixupCommits,
+		MaxCommits:             cfg.Push.MaxCommits,
+		OnMaxCommits:           cfg.Push.OnMaxCommits,
+		BlockCommit:            cfg.Branch.BlockCommit,
+
+		Ecosystems:        cfg.Consistency.Ecosystems,
+		AllowLockfileOnly: cfg.Consistency.AllowLockfileOnly,
+
+		Detect:  cfg.Detect,
+		Format:  cfg.Format,
+		Require: cfg.Require,
+		Rollout: cfg.Rollout,
+		Limits:  cfg.Limits,
+
+		Network:       cfg.Behavior.Network,
+		VersionCheck:  cfg.Behavior.VersionCheck,
+		PolicyTrailer: cfg.Behavior.PolicyTrailer,
+		PlainOutput:   cfg.Behavior.PlainOutput,
+		PacksAuto:     cfg.PacksAuto,
+		Notify:        cfg.Notify,
+		Ignore:        cfg.Ignore,
 	}
 	// Skip empty sources
 	if len(src.Diff) == 0 && len(src.Msg) == 0 && src.Push == nil && len(src.Branch) == 0 &&
-		src.MsgMaxLen == 0 && src.MsgMaxLines == 0 {
+		src.MsgMaxLen == 0 && src.MsgMaxLines == 0 && src.Message == (msgSection{}) && src.Tickets.empty() && src.Prepare.empty() && src.CommitHours == "" && src.DateTolerance == "" &&
+		!src.Empty && !src.WhitespaceOnly && len(src.Executable) == 0 && len(src.RequireExecutable) == 0 && len(src.URLs) == 0 && len(src.Rules) == 0 &&
+		len(src.SkipExtensions) == 0 && src.MaxFileBytes == nil && len(src.Scan.settings()) == 0 && len(src.AllowedRemotes) == 0 && len(src.ExemptAuthors) == 0 &&
+		!src.BlockProtectedMismatch && !src.ForbidMergeCommits && !src.ForbidFixupCommits && src.MaxCommits == 0 && src.OnMaxCommits == "" && !src.BlockCommit &&
+		len(src.Ecosystems) == 0 && len(src.Detect) == 0 && src.Limits.MaxWarnings == 0 &&
+		src.Limits.HookTimeout == "" && src.Limits.OnTimeout == "" && src.Network == nil && src.VersionCheck == nil && !src.PolicyTrailer && !src.PacksAuto && !src.Notify.Desktop && src.Notify.Interval == "" && src.Notify.Bell == nil && !src.Notify.VisualBell && !src.PlainOutput &&
+		!src.Format.TrailingWhitespace && !src.Format.FinalNewline && !src.Format.CRLF && len(src.Ignore) == 0 && src.Require == (requireSection{}) {
 		return nil, nil
 	}
 	return src, nil
@@ -273,3 +639,101 @@ func parseIgnoreSource(env string) *configSource {
 	}
 	return src
 }
+
+// runConfigEffective prints the config files in merge order, then the
+// settings they resolve to.
+func runConfigEffective(cmd *cobra.Command) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting working directory: %w", err)
+	}
+	files, err := configMergeOrder(cwd)
+	if err != nil {
+		return err
+	}
+	bc, err := resolveBlockConfig(cmd)
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+
+	fmt.Fprintln(out, hintStyle.Render(fmt.Sprintf("# merge order on host %s (a single-value setting keeps the first value set)", currentHostname())))
+	for i, f := range files {
+		fmt.Fprintf(out, "  %d. %s\n", i+1, configFileLabel(f))
+	}
+	if path := worktreeConfigPath(cwd); path != "" && fileExists(path) {
+		fmt.Fprintf(out, "  %d. worktree: %s (overrides all of the above)\n", len(files)+1, path)
+	} else if len(files) == 0 {
+		fmt.Fprintln(out, "  no snag config found")
+	}
+
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, hintStyle.Render("# effective"))
+	line := func(key string, value any) { fmt.Fprintf(out, "  %-22s %v\n", key+":", value) }
+	list := func(key string, patterns []string) {
+		if len(patterns) == 0 {
+			return
+		}
+		shown := make([]string, len(patterns))
+		for i, p := range patterns {
+			shown[i] = bc.display(p)
+		}
+		line(key, strings.Join(shown, ", "))
+	}
+	list("diff", bc.Diff)
+	list("msg", bc.Msg)
+	if bc.Push == nil {
+		line("push", "diff + msg")
+	} else {
+		list("push", bc.Push)
+	}
+	list("branch", bc.Branch)
+	if bc.MsgMaxLen > 0 {
+		line("msg_max_len", bc.MsgMaxLen)
+	}
+	if bc.MsgMaxLines > 0 {
+		line("msg_max_lines", bc.MsgMaxLines)
+	}
+	if bc.MsgLanguage != "" {
+		line("msg.language", bc.MsgLanguage)
+	}
+	if bc.OnMsgLanguage != "" {
+		line("msg.on_language", bc.OnMsgLanguage)
+	}
+	if bc.TicketProvider != "" {
+		line("tickets.provider", bc.TicketProvider)
+		line("tickets.verify", bc.TicketVerify)
+	}
+	if bc.prepareOn() {
+		line("prepare.ticket", bc.PrepareTicket)
+		line("prepare.type", bc.PrepareType)
+	}
+	if bc.AuditLimit != nil {
+		line("audit.limit", *bc.AuditLimit)
+	}
+	if bc.CommitHours != "" {
+		line("commit_hours", bc.CommitHours)
+	}
+	if bc.DateTolerance > 0 {
+		line("date_tolerance", bc.DateTolerance)
+	}
+	if bc.MaxWarnings > 0 {
+		line("limits.max_warnings", bc.MaxWarnings)
+	}
+	if bc.HookTimeout > 0 {
+		line("limits.hook_timeout", bc.HookTimeout)
+	}
+	if bc.OnTimeout != "" {
+		line("limits.on_timeout", bc.OnTimeout)
+	}
+	if bc.MaxPushCommits > 0 {
+		line("push.max_commits", bc.MaxPushCommits)
+	}
+	if bc.OnMaxPushCommits != "" {
+		line("push.on_max_commits", bc.OnMaxPushCommits)
+	}
+	if bc.MaxFileBytes != nil {
+		line("skip.max_file_bytes", *bc.MaxFileBytes)
+	}
+	return nil
+}
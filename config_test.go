@@ -1,8 +1,11 @@
 package main
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
@@ -794,3 +797,250 @@ func TestPushPatterns(t *testing.T) {
 		}
 	})
 }
+
+func TestMergeTOML_Skip(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[skip]\nextensions = [\".min.js\"]\nmax_file_bytes = 500\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "snag-local.toml"),
+		[]byte("[skip]\nextensions = [\".svg\"]\nmax_file_bytes = 0\n"), 0644)
+
+	bc, _, err := walkConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bc.SkipExtensions) != 2 {
+		t.Errorf("SkipExtensions = %v, want both files merged", bc.SkipExtensions)
+	}
+	if bc.MaxFileBytes == nil || *bc.MaxFileBytes != 0 {
+		t.Errorf("MaxFileBytes = %v, want local override 0", bc.MaxFileBytes)
+	}
+
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[skip]\nmax_file_bytes = -5\n"), 0644)
+	if _, err := loadSnagTOML(filepath.Join(dir, "snag.toml")); err == nil {
+		t.Error("expected error for negative skip.max_file_bytes")
+	}
+}
+
+func TestWalkConfig_ConfigDirsEnv(t *testing.T) {
+	repo := t.TempDir()
+	dotA := t.TempDir()
+	dotB := t.TempDir()
+
+	os.WriteFile(filepath.Join(repo, "snag.toml"), []byte("[block]\ndiff = [\"REPO\"]\n\n[audit]\nlimit = 3\n"), 0644)
+	os.WriteFile(filepath.Join(dotA, "snag.toml"), []byte("[block]\ndiff = [\"DOTFILE\"]\n\n[audit]\nlimit = 50\n"), 0644)
+	os.WriteFile(filepath.Join(dotB, "snag-local.toml"), []byte("[block]\nmsg = [\"MACHINE\"]\n"), 0644)
+
+	t.Setenv("SNAG_CONFIG_DIRS", dotA+string(filepath.ListSeparator)+"  "+string(filepath.ListSeparator)+dotB)
+
+	bc, found, err := walkConfig(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found {
+		t.Fatal("expected found=true")
+	}
+	if len(bc.Diff) != 2 || bc.Diff[0] != "REPO" || bc.Diff[1] != "DOTFILE" {
+		t.Errorf("diff: got %v, want [REPO DOTFILE]", bc.Diff)
+	}
+	if len(bc.Msg) != 1 || bc.Msg[0] != "MACHINE" {
+		t.Errorf("msg: got %v, want [MACHINE]", bc.Msg)
+	}
+	// Repo hierarchy outranks SNAG_CONFIG_DIRS for scalar settings.
+	if bc.AuditLimit == nil || *bc.AuditLimit != 3 {
+		t.Errorf("audit limit: got %v, want 3", bc.AuditLimit)
+	}
+
+	// Pointing SNAG_CONFIG_DIRS at a directory already in the walk doesn't double-merge.
+	t.Setenv("SNAG_CONFIG_DIRS", repo)
+	bc, _, err = walkConfig(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bc.Diff) != 1 {
+		t.Errorf("diff: got %v, want [REPO] only", bc.Diff)
+	}
+}
+
+func TestDirKey(t *testing.T) {
+	same := [][2]string{
+		{`C:\`, `c:\`},
+		{`C:\Users\Dev\repo`, `c:\users\dev\repo\`},
+		{`C:/Users/Dev/repo`, `C:\Users\Dev\repo`},
+	}
+	for _, p := range same {
+		if dirKey("windows", p[0]) != dirKey("windows", p[1]) {
+			t.Errorf("windows: %q and %q should be the same directory", p[0], p[1])
+		}
+	}
+	if dirKey("linux", "/src/Repo") == dirKey("linux", "/src/repo") {
+		t.Error("linux paths are case-sensitive")
+	}
+}
+
+func TestWalkConfig_PathWithSpaces(t *testing.T) {
+	repo := filepath.Join(t.TempDir(), "My Projects", "web app")
+	sub := filepath.Join(repo, "src dir")
+	os.MkdirAll(sub, 0755)
+	os.WriteFile(filepath.Join(repo, "snag.toml"), []byte("[block]\ndiff = [\"SPACED\"]\n"), 0644)
+
+	t.Setenv("SNAG_CONFIG_DIRS", repo)
+	bc, found, err := walkConfig(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found || len(bc.Diff) != 1 || bc.Diff[0] != "SPACED" {
+		t.Errorf("got found=%v diff=%v, want the spaced repo's config once", found, bc.Diff)
+	}
+}
+
+func TestMergeTOML_Include(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "policies"), 0755)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte(`include = ["policies/secrets.toml", "policies/hygiene.toml"]
+[block]
+diff = ["own"]
+msg_max_len = 50
+`), 0644)
+	os.WriteFile(filepath.Join(dir, "policies", "secrets.toml"), []byte("include = [\"nested.toml\"]\n[block]\ndiff = [\"apikey\"]\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "policies", "nested.toml"), []byte("[block]\nsensitive = true\ndiff = [\"projectx\"]\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "policies", "hygiene.toml"), []byte("[block]\nmsg = [\"wip\"]\nmsg_max_len = 72\n"), 0644)
+
+	bc, found, err := walkConfig(dir)
+	if err != nil || !found {
+		t.Fatalf("walkConfig: found=%v err=%v", found, err)
+	}
+	for _, want := range []string{"own", "apikey", "projectx"} {
+		if !slices.Contains(bc.Diff, want) {
+			t.Errorf("diff %v missing %q", bc.Diff, want)
+		}
+	}
+	if !slices.Contains(bc.Msg, "wip") {
+		t.Errorf("msg = %v, want wip from hygiene.toml", bc.Msg)
+	}
+	if !bc.Sensitive["projectx"] {
+		t.Error("sensitive flag from a nested include was lost")
+	}
+	if bc.MsgMaxLen != 72 {
+		t.Errorf("msg_max_len = %d, want 72 (the strictest)", bc.MsgMaxLen)
+	}
+}
+
+func TestMergeTOML_IncludeErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		files map[string]string
+		want  string
+	}{
+		"cycle": {map[string]string{
+			"snag.toml": `include = ["a.toml"]`,
+			"a.toml":    `include = ["b.toml"]`,
+			"b.toml":    `include = ["a.toml"]`,
+		}, "include cycle"},
+		"self":    {map[string]string{"snag.toml": `include = ["snag.toml"]`}, "include cycle"},
+		"escape":  {map[string]string{"snag.toml": `include = ["../outside.toml"]`}, "must be a path inside"},
+		"missing": {map[string]string{"snag.toml": `include = ["nope.toml"]`}, "no such file"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			for f, content := range tc.files {
+				os.WriteFile(filepath.Join(dir, f), []byte(content+"\n"), 0644)
+			}
+			_, _, err := walkConfig(dir)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("err = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestDirConfigFiles_Priority(t *testing.T) {
+	write := func(dir, name, content string) {
+		t.Helper()
+		os.MkdirAll(dir, 0755)
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	limit := func(dir string) int {
+		t.Helper()
+		bc := &BlockConfig{}
+		if _, err := mergeConfigDir(bc, dir); err != nil {
+			t.Fatal(err)
+		}
+		if bc.AuditLimit == nil {
+			return 0
+		}
+		return *bc.AuditLimit
+	}
+
+	t.Run("default order: local, then snag.toml, each before its includes", func(t *testing.T) {
+		dir := t.TempDir()
+		write(dir, "snag.toml", "include = [\"a.toml\"]\n[audit]\nlimit = 5\n")
+		write(dir, "a.toml", "[audit]\nlimit = 7\n")
+		write(dir, "snag-local.toml", "[audit]\nlimit = 9\n")
+		files, err := dirConfigFiles(dir, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, f := range files {
+			names = append(names, filepath.Base(f.Path))
+		}
+		if got := strings.Join(names, " "); got != "snag-local.toml snag.toml a.toml" {
+			t.Errorf("order = %s", got)
+		}
+		if got := limit(dir); got != 9 {
+			t.Errorf("limit = %d, want snag-local.toml's 9", got)
+		}
+	})
+
+	t.Run("priority moves a file ahead", func(t *testing.T) {
+		dir := t.TempDir()
+		write(dir, "snag.toml", "priority = 10\ninclude = [\"a.toml\"]\n[audit]\nlimit = 5\n")
+		write(dir, "a.toml", "priority = 20\n[audit]\nlimit = 7\n")
+		write(dir, "snag-local.toml", "[audit]\nlimit = 9\n")
+		if got := limit(dir); got != 7 {
+			t.Errorf("limit = %d, want the priority 20 include's 7", got)
+		}
+	})
+
+	t.Run("a parent's overlay doesn't override a nearer directory", func(t *testing.T) {
+		parent := t.TempDir()
+		child := filepath.Join(parent, "child")
+		write(parent, "snag-local.toml", "priority = 100\n[audit]\nlimit = 9\n")
+		write(child, "snag.toml", "[audit]\nlimit = 5\n")
+		bc, _, err := walkConfig(child)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bc.AuditLimit == nil || *bc.AuditLimit != 5 {
+			t.Errorf("limit = %v, want the nearer 5", bc.AuditLimit)
+		}
+	})
+}
+
+func TestConfigEffective(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("priority = 5\n[block]\ndiff = [\"todo\"]\n[audit]\nlimit = 5\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "snag-local.toml"), []byte("[block]\ndiff = [\"acme\"]\n[audit]\nlimit = 9\n"), 0644)
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	var out bytes.Buffer
+	rootCmd := buildRootCmd()
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"config", "--effective"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	first := strings.Index(got, "snag.toml (priority 5)")
+	second := strings.Index(got, "snag-local.toml")
+	if first < 0 || second < first {
+		t.Errorf("snag.toml with priority 5 should be listed first:\n%s", got)
+	}
+	if !strings.Contains(got, "audit.limit:           5") || !strings.Contains(got, "push:                  diff + msg") {
+		t.Errorf("effective settings missing:\n%s", got)
+	}
+}
@@ -0,0 +1,563 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+)
+
+func buildConfigSetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set KEY VALUE...",
+		Short: "Set a key in a config file, keeping its comments and layout",
+		Long: `Set a key in a config file without hand-editing it.
+
+KEY is a dotted path as it appears in snag.toml: block.diff, audit.limit,
+scan.push, detect.secrets.enabled. List keys take every VALUE given; --add
+appends them to the current list instead of replacing it. Other keys take
+exactly one VALUE, parsed as the key's type.
+
+Only the edited key's value changes: comments, blank lines and the order of
+everything else are kept. A new key goes at the end of its table, and a new
+table at the end of the file. The result is validated before it is written,
+so a bad value leaves the file untouched.`,
+		Example: `  snag config set block.diff "DO NOT MERGE" todo
+  snag config set block.diff --add fixme
+  snag config set audit.limit 200 --file snag-local.toml`,
+		Args:         cobra.MinimumNArgs(2),
+		SilenceUsage: true,
+		RunE:         runConfigSet,
+	}
+	cmd.Flags().String("file", "snag.toml", "config file to edit (created if missing)")
+	cmd.Flags().Bool("add", false, "append to a list key instead of replacing it")
+	return cmd
+}
+
+func buildConfigGetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get KEY",
+		Short: "Print a key from a config file",
+		Long: `Print a key's value from one config file, for scripts.
+
+Lists print one entry per line; other values print as-is. A key the file
+doesn't set is an error, so scripts can tell unset from empty.`,
+		Example: `  snag config get block.branch
+  snag config get audit.limit --file snag-local.toml`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE:         runConfigGet,
+	}
+	cmd.Flags().String("file", "snag.toml", "config file to read")
+	return cmd
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	path, _ := cmd.Flags().GetString("file")
+	add, _ := cmd.Flags().GetBool("add")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+
+	if strings.HasSuffix(path, ".age") || strings.HasSuffix(path, ".sops.toml") {
+		return fmt.Errorf("%s is encrypted: decrypt it, edit the plaintext, then re-encrypt", path)
+	}
+	keys, leaf, err := configKeyPath(args[0])
+	if err != nil {
+		return err
+	}
+	if add && leaf.Kind() != reflect.Slice {
+		return fmt.Errorf("--add needs a list key; %s is a %s", args[0], leaf.Kind())
+	}
+	value, err := parseConfigValue(args[0], leaf, args[1:])
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(data) > 0 {
+		if _, err := toml.Decode(string(data), new(map[string]any)); err != nil {
+			return fmt.Errorf("parsing %s: %w", path, err)
+		}
+	}
+	out, err := setTOMLKey(string(data), keys, value, add)
+	if err != nil {
+		return err
+	}
+	if err := validateEditedConfig(path, out); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path, func(f *os.File) error {
+		_, err := f.WriteString(out)
+		return err
+	}); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	if !quiet {
+		infof("set %s in %s", args[0], path)
+	}
+	return nil
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	path, _ := cmd.Flags().GetString("file")
+	keys, _, err := configKeyPath(args[0])
+	if err != nil {
+		return err
+	}
+	data, err := readConfigFile(path)
+	if err != nil {
+		return err
+	}
+	var doc map[string]any
+	if _, err := toml.Decode(string(data), &doc); err != nil {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+	var v any = doc
+	for _, k := range keys {
+		m, ok := v.(map[string]any)
+		if !ok {
+			v = nil
+			break
+		}
+		v = m[k]
+	}
+	if v == nil {
+		return fmt.Errorf("%s is not set in %s", args[0], path)
+	}
+	out := cmd.OutOrStdout()
+	if list, ok := v.([]any); ok {
+		for _, e := range list {
+			fmt.Fprintln(out, e)
+		}
+		return nil
+	}
+	fmt.Fprintln(out, v)
+	return nil
+}
+
+// configKeyPath checks a dotted key against the snagTOML schema and returns
+// its TOML key segments and the Go type of its value. A map-valued table
+// ([redact], [detect.NAME]) takes the next segment as its key; for a map of
+// plain values that is the rest of the path, dots included.
+func configKeyPath(key string) ([]string, reflect.Type, error) {
+	parts := strings.Split(key, ".")
+	var keys []string
+	t := reflect.TypeOf(snagTOML{})
+	for i := 0; i < len(parts); i++ {
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Struct:
+			f, ok := tomlField(t, parts[i])
+			if !ok {
+				return nil, nil, fmt.Errorf("unknown config key %s", key)
+			}
+			keys = append(keys, parts[i])
+			t = f.Type
+		case reflect.Map:
+			if t.Elem().Kind() == reflect.Struct {
+				keys = append(keys, parts[i])
+				t = t.Elem()
+				continue
+			}
+			return append(keys, strings.Join(parts[i:], ".")), t.Elem(), nil
+		default:
+			return nil, nil, fmt.Errorf("unknown config key %s", key)
+		}
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t.Kind() == reflect.Struct, t.Kind() == reflect.Map:
+		return nil, nil, fmt.Errorf("%s is a table; name one of its keys", key)
+	case t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.String:
+		return nil, nil, fmt.Errorf("%s is an array of tables; edit it by hand", key)
+	}
+	return keys, t, nil
+}
+
+// tomlField finds the field of struct type t whose toml tag is name.
+func tomlField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if tag, _, _ := strings.Cut(f.Tag.Get("toml"), ","); tag == name {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// parseConfigValue converts command-line values to the key's type.
+func parseConfigValue(key string, t reflect.Type, args []string) (any, error) {
+	if t.Kind() == reflect.Slice {
+		return args, nil
+	}
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%s takes one value, got %d", key, len(args))
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		n, err := strconv.Atoi(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an integer, got %q", key, args[0])
+		}
+		return n, nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false, got %q", key, args[0])
+		}
+		return b, nil
+	}
+	return args[0], nil
+}
+
+// validateEditedConfig loads the edited text the way snag would, from a
+// scratch file with the same name so name-dependent checks still apply.
+func validateEditedConfig(path, content string) error {
+	dir, err := os.MkdirTemp("", "snag-config-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	scratch := filepath.Join(dir, filepath.Base(path))
+	if err := os.WriteFile(scratch, []byte(content), 0644); err != nil {
+		return err
+	}
+	if _, err := loadSnagTOML(scratch); err != nil {
+		return fmt.Errorf("not written: %s", strings.ReplaceAll(err.Error(), scratch, path))
+	}
+	return nil
+}
+
+// tomlLine is a key = value entry found by scanTOML. The value runs from
+// byte from on line start to byte to on line end.
+type tomlLine struct {
+	start, end int
+	from, to   int
+}
+
+// tomlLayout is where scanTOML found each key and table, by "\x00"-joined
+// key path. last records the final line of each table's body.
+type tomlLayout struct {
+	keys   map[string]tomlLine
+	tables map[string]bool
+	last   map[string]int
+}
+
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// scanTOML locates keys and tables in a TOML document's lines without
+// parsing values, so edits can splice text in place. Keys under [[array]]
+// tables are skipped.
+func scanTOML(lines []string) tomlLayout {
+	l := tomlLayout{keys: map[string]tomlLine{}, tables: map[string]bool{"": true}, last: map[string]int{"": -1}}
+	table := ""
+	inArray := false
+	for i := 0; i < len(lines); i++ {
+		s := strings.TrimSpace(lines[i])
+		switch {
+		case s == "" || strings.HasPrefix(s, "#"):
+			continue
+		case strings.HasPrefix(s, "[["):
+			inArray = true
+			continue
+		case strings.HasPrefix(s, "["):
+			end := closingBracket(s)
+			table = strings.Join(splitTOMLKey(s[1:end]), "\x00")
+			inArray = false
+			l.tables[table] = true
+			l.last[table] = i
+			continue
+		}
+		eq := indexOutsideQuotes(lines[i], '=')
+		if eq < 0 {
+			continue
+		}
+		from := eq + 1
+		for from < len(lines[i]) && (lines[i][from] == ' ' || lines[i][from] == '\t') {
+			from++
+		}
+		end, to := tomlValueEnd(lines, i, from)
+		if !inArray {
+			full := strings.Join(splitTOMLKey(lines[i][:eq]), "\x00")
+			if table != "" {
+				full = table + "\x00" + full
+			}
+			l.keys[full] = tomlLine{start: i, end: end, from: from, to: to}
+			l.last[table] = end
+		}
+		i = end
+	}
+	return l
+}
+
+// closingBracket returns the index of the ] ending a [table] header.
+func closingBracket(s string) int {
+	if i := indexOutsideQuotes(s, ']'); i > 0 {
+		return i
+	}
+	return len(s)
+}
+
+// indexOutsideQuotes returns the first index of c outside a quoted string.
+func indexOutsideQuotes(s string, c byte) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote != 0:
+			if s[i] == '\\' && quote == '"' {
+				i++
+			} else if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case s[i] == c:
+			return i
+		}
+	}
+	return -1
+}
+
+// splitTOMLKey splits a dotted key into its unquoted segments.
+func splitTOMLKey(s string) []string {
+	var parts []string
+	for {
+		s = strings.TrimSpace(s)
+		dot := indexOutsideQuotes(s, '.')
+		part := s
+		if dot >= 0 {
+			part = s[:dot]
+		}
+		part = strings.TrimSpace(part)
+		if u, err := strconv.Unquote(part); err == nil && strings.HasPrefix(part, `"`) {
+			part = u
+		} else if len(part) >= 2 && part[0] == '\'' && part[len(part)-1] == '\'' {
+			part = part[1 : len(part)-1]
+		}
+		parts = append(parts, part)
+		if dot < 0 {
+			return parts
+		}
+		s = s[dot+1:]
+	}
+}
+
+// tomlValueEnd finds where the value starting at line i, byte from ends:
+// the line holding its last character and the byte just past it, before
+// any trailing comment. Arrays and strings may span lines.
+func tomlValueEnd(lines []string, i, from int) (int, int) {
+	depth := 0
+	var quote string
+	for ; i < len(lines); i, from = i+1, 0 {
+		s := lines[i]
+		last := from
+		for j := from; j < len(s); j++ {
+			if quote != "" {
+				if s[j] == '\\' && quote[0] == '"' {
+					j++
+				} else if strings.HasPrefix(s[j:], quote) {
+					j += len(quote) - 1
+					quote = ""
+				}
+				last = j + 1
+				continue
+			}
+			c := s[j]
+			switch {
+			case c == '#':
+				j = len(s)
+				continue
+			case c == '"' || c == '\'':
+				quote = string(c)
+				if strings.HasPrefix(s[j:], strings.Repeat(quote, 3)) {
+					quote = strings.Repeat(quote, 3)
+					j += 2
+				}
+			case c == '[' || c == '{':
+				depth++
+			case c == ']' || c == '}':
+				depth--
+			}
+			if c != ' ' && c != '\t' && c != '\r' {
+				last = j + 1
+			}
+		}
+		if depth <= 0 && quote == "" {
+			return i, last
+		}
+	}
+	return len(lines) - 1, len(lines[len(lines)-1])
+}
+
+// renderTOMLValue formats v as a TOML value. A list that was written one
+// entry per line stays that way, with indent before each entry.
+func renderTOMLValue(v any, multiline bool, indent string) (string, error) {
+	if list, ok := v.([]string); ok && multiline && len(list) > 0 {
+		var b strings.Builder
+		b.WriteString("[\n")
+		for _, e := range list {
+			s, err := renderTOMLValue(e, false, "")
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(indent + s + ",\n")
+		}
+		b.WriteString("]")
+		return b.String(), nil
+	}
+	var b strings.Builder
+	if err := toml.NewEncoder(&b).Encode(map[string]any{"v": v}); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(b.String(), "v = "), "\n"), nil
+}
+
+// tomlKeyText formats one key segment, quoting it when it isn't bare.
+func tomlKeyText(k string) string {
+	if bareKey.MatchString(k) {
+		return k
+	}
+	return strconv.Quote(k)
+}
+
+// setTOMLKey returns doc with keys set to value, changing only that entry's
+// text. With add, value's entries are appended to the existing list, skipping
+// ones already present.
+func setTOMLKey(doc string, keys []string, value any, add bool) (string, error) {
+	lines, eol := splitMsgLines([]byte(doc))
+	layout := scanTOML(lines)
+	full := strings.Join(keys, "\x00")
+
+	if at, ok := layout.keys[full]; ok {
+		multiline := at.end > at.start
+		indent := "  "
+		if multiline {
+			line := lines[at.start+1]
+			indent = line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		}
+		if add {
+			var old struct{ V []string }
+			var text string
+			if multiline {
+				parts := append([]string{lines[at.start][at.from:]}, lines[at.start+1:at.end]...)
+				text = strings.Join(append(parts, lines[at.end][:at.to]), "\n")
+			} else {
+				text = lines[at.start][at.from:at.to]
+			}
+			if _, err := toml.Decode("V = "+text, &old); err != nil {
+				return "", fmt.Errorf("%s is not a list of strings: %w", strings.Join(keys, "."), err)
+			}
+			var added []string
+			for _, v := range value.([]string) {
+				if !slices.Contains(old.V, v) && !slices.Contains(added, v) {
+					added = append(added, v)
+				}
+			}
+			// A list written one entry per line, closed on its own line
+			// after a trailing comma, takes new entries as new lines, so
+			// comments beside the existing ones survive.
+			if multiline && strings.HasPrefix(strings.TrimSpace(lines[at.end]), "]") && endsWithComma(lines[at.end-1]) {
+				var entries []string
+				for _, v := range added {
+					s, err := renderTOMLValue(v, false, "")
+					if err != nil {
+						return "", err
+					}
+					entries = append(entries, indent+s+",")
+				}
+				lines = slices.Insert(lines, at.end, entries...)
+				return strings.Join(lines, eol), nil
+			}
+			value = append(old.V, added...)
+		}
+		rendered, err := renderTOMLValue(value, multiline, indent)
+		if err != nil {
+			return "", err
+		}
+		edited := lines[at.start][:at.from] + rendered + lines[at.end][at.to:]
+		lines = slices.Replace(lines, at.start, at.end+1, strings.Split(edited, "\n")...)
+		return strings.Join(lines, eol), nil
+	}
+
+	rendered, err := renderTOMLValue(value, false, "")
+	if err != nil {
+		return "", err
+	}
+	table := strings.Join(keys[:len(keys)-1], "\x00")
+	entry := tomlKeyText(keys[len(keys)-1]) + " = " + rendered
+
+	if table == "" {
+		// Top-level keys must come before the first table. Put a new one
+		// after the existing top-level keys, or else above the first table
+		// and the comment block introducing it.
+		at := layout.last[""] + 1
+		if at == 0 {
+			for at < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[at]), "[") {
+				at++
+			}
+			if at == len(lines) {
+				at = endOfContent(lines)
+			} else {
+				first := at
+				for at > 0 && strings.HasPrefix(strings.TrimSpace(lines[at-1]), "#") {
+					at--
+				}
+				if at == 0 {
+					at = first
+				}
+				if strings.TrimSpace(lines[at]) != "" {
+					entry += eol
+				}
+			}
+		}
+		lines = slices.Insert(lines, at, entry)
+		return strings.Join(lines, eol), nil
+	}
+	if layout.tables[table] {
+		lines = slices.Insert(lines, layout.last[table]+1, entry)
+		return strings.Join(lines, eol), nil
+	}
+
+	header := make([]string, len(keys)-1)
+	for i, k := range keys[:len(keys)-1] {
+		header[i] = tomlKeyText(k)
+	}
+	block := []string{"[" + strings.Join(header, ".") + "]", entry}
+	end := endOfContent(lines)
+	if end > 0 {
+		block = append([]string{""}, block...)
+	}
+	lines = slices.Insert(lines[:end], end, append(block, "")...)
+	return strings.Join(lines, eol), nil
+}
+
+// endOfContent is where text appended to lines goes: before the empty
+// string a trailing newline leaves at the end.
+func endOfContent(lines []string) int {
+	if n := len(lines); n > 0 && lines[n-1] == "" {
+		return n - 1
+	}
+	return len(lines)
+}
+
+// endsWithComma reports whether line's content, before any comment, ends
+// with a comma.
+func endsWithComma(line string) bool {
+	if i := indexOutsideQuotes(line, '#'); i >= 0 {
+		line = line[:i]
+	}
+	return strings.HasSuffix(strings.TrimSpace(line), ",")
+}
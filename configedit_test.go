@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const configEditDoc = `# Team policy
+min_version = "0.1.0"
+
+[block]
+# never ship these
+diff = [
+  "todo", # from the old linter
+  "fixme",
+]
+msg = ["wip"] # squash first
+
+[audit]
+limit = 3
+`
+
+func TestSetTOMLKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		keys  []string
+		value any
+		add   bool
+		want  string
+	}{
+		{"replace scalar", []string{"audit", "limit"}, 200, false,
+			strings.Replace(configEditDoc, "limit = 3", "limit = 200", 1)},
+		{"replace keeps inline comment", []string{"block", "msg"}, []string{"wip", "fixup!"}, false,
+			strings.Replace(configEditDoc, `msg = ["wip"]`, `msg = ["wip", "fixup!"]`, 1)},
+		{"add to multi-line list", []string{"block", "diff"}, []string{"fixme", "DO NOT MERGE"}, true,
+			strings.Replace(configEditDoc, "  \"fixme\",\n", "  \"fixme\",\n  \"DO NOT MERGE\",\n", 1)},
+		{"new key in table", []string{"block", "branch"}, []string{"main"}, false,
+			strings.Replace(configEditDoc, "# squash first\n", "# squash first\nbranch = [\"main\"]\n", 1)},
+		{"new top-level key", []string{"packs_auto"}, true, false,
+			strings.Replace(configEditDoc, "min_version = \"0.1.0\"\n", "min_version = \"0.1.0\"\npacks_auto = true\n", 1)},
+		{"new table", []string{"redact", "acme corp"}, "CLIENT", false,
+			configEditDoc + "\n[redact]\n\"acme corp\" = \"CLIENT\"\n"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := setTOMLKey(configEditDoc, tc.keys, tc.value, tc.add)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSetTOMLKey_CRLF(t *testing.T) {
+	doc := "[audit]\r\nlimit = 3\r\n"
+	got, err := setTOMLKey(doc, []string{"block", "diff"}, []string{"x"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[audit]\r\nlimit = 3\r\n\r\n[block]\r\ndiff = [\"x\"]\r\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestConfigKeyPath(t *testing.T) {
+	for key, want := range map[string]string{
+		"block.diff":            "",
+		"detect.debug.enabled":  "",
+		"redact.acme.com":       "",
+		"blok.diff":             "unknown config key",
+		"block":                 "is a table",
+		"block.rule":            "array of tables",
+		"audit.limit.something": "unknown config key",
+	} {
+		_, _, err := configKeyPath(key)
+		switch {
+		case want == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", key, err)
+		case want != "" && (err == nil || !strings.Contains(err.Error(), want)):
+			t.Errorf("%s: got %v, want an error containing %q", key, err, want)
+		}
+	}
+	if keys, _, _ := configKeyPath("redact.acme.com"); len(keys) != 2 || keys[1] != "acme.com" {
+		t.Errorf("redact keys = %q, want [redact acme.com]", keys)
+	}
+}
+
+func TestRunConfigSetGet(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "snag.toml")
+	os.WriteFile(path, []byte(configEditDoc), 0644)
+
+	run := func(args ...string) (string, error) {
+		var out bytes.Buffer
+		rootCmd := buildRootCmd()
+		rootCmd.SetOut(&out)
+		rootCmd.SetArgs(append(append([]string{"config"}, args...), "--file", path, "--quiet"))
+		err := rootCmd.Execute()
+		return out.String(), err
+	}
+
+	if _, err := run("set", "block.branch", "main", "release"); err != nil {
+		t.Fatal(err)
+	}
+	out, err := run("get", "block.branch")
+	if err != nil || out != "main\nrelease\n" {
+		t.Errorf("get block.branch = %q, %v", out, err)
+	}
+	if _, err := run("get", "block.push"); err == nil {
+		t.Error("get of an unset key should fail")
+	}
+
+	before, _ := os.ReadFile(path)
+	if _, err := run("set", "scan.push", "sideways"); err == nil {
+		t.Error("an invalid value should be rejected")
+	}
+	if after, _ := os.ReadFile(path); !bytes.Equal(before, after) {
+		t.Error("a rejected edit must leave the file untouched")
+	}
+}
+
+func TestRunConfigSet_KeepsSymlinkAndMode(t *testing.T) {
+	dir := t.TempDir()
+	// A dotfiles-style setup: snag.toml links to a private, shared file.
+	target := filepath.Join(dir, "dotfiles", "snag.toml")
+	os.MkdirAll(filepath.Dir(target), 0755)
+	os.WriteFile(target, []byte(configEditDoc), 0600)
+	link := filepath.Join(dir, "snag.toml")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"config", "set", "block.branch", "main", "--file", link, "--quiet"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("snag.toml is no longer a symlink: %v, %v", info, err)
+	}
+	data, _ := os.ReadFile(target)
+	if !strings.Contains(string(data), `branch = ["main"]`) {
+		t.Errorf("edit did not reach the link target:\n%s", data)
+	}
+	if info, _ := os.Stat(target); info.Mode().Perm() != 0600 {
+		t.Errorf("target mode = %v, want 0600", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,491 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// snag config fuzz generates random config hierarchies and content and
+// checks properties the resolution and matching pipeline promises:
+//
+//	deterministic  resolving the same tree twice gives the same policy
+//	order          reordering patterns and includes doesn't change the policy
+//	split          moving patterns into an included file doesn't change it
+//	monotonic      adding a pattern never unblocks a line that was blocked
+//	match          a plain pattern matches exactly when it is a
+//	               case-insensitive substring, in any letter case
+//
+// "Policy" is compared with policyHash, which ignores order by design.
+
+// fuzzFile is one generated snag.toml, snag-local.toml or included file.
+type fuzzFile struct {
+	Diff, Msg, Branch []string
+	Push              *[]string
+	MsgMaxLen         int
+	Includes          []*fuzzFile
+}
+
+// fuzzTree is a generated hierarchy: levels[0] is the root directory,
+// the last level the directory checks run in.
+type fuzzTree struct {
+	Levels []fuzzLevel
+}
+
+type fuzzLevel struct {
+	Shared, Local *fuzzFile // nil = file absent
+}
+
+// fuzzFailure is one property a run broke.
+type fuzzFailure struct {
+	Run      int
+	Seed     uint64
+	Property string
+	Detail   string
+}
+
+// fuzzAlphabet keeps patterns short and overlapping, so they collide with
+// each other and with generated content often enough to be interesting.
+var fuzzAlphabet = []rune("abcdeABCDE_-é ")
+
+func fuzzWord(r *rand.Rand, min, max int) string {
+	n := min + r.IntN(max-min+1)
+	var b strings.Builder
+	for range n {
+		b.WriteRune(fuzzAlphabet[r.IntN(len(fuzzAlphabet))])
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func fuzzWords(r *rand.Rand, max int) []string {
+	var out []string
+	for range r.IntN(max + 1) {
+		if w := fuzzWord(r, 2, 5); w != "" {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+func genFuzzFile(r *rand.Rand, depth int) *fuzzFile {
+	f := &fuzzFile{Diff: fuzzWords(r, 4), Msg: fuzzWords(r, 3)}
+	if r.IntN(3) == 0 {
+		f.Branch = fuzzWords(r, 2)
+	}
+	if r.IntN(4) == 0 {
+		push := fuzzWords(r, 3)
+		f.Push = &push
+	}
+	// Scalars only at the top of a file's include tree: which sibling
+	// include wins a scalar is order-dependent by design.
+	if depth == 0 && r.IntN(3) == 0 {
+		f.MsgMaxLen = 20 + r.IntN(80)
+	}
+	if depth < 2 {
+		for range r.IntN(3) {
+			f.Includes = append(f.Includes, genFuzzFile(r, depth+1))
+		}
+	}
+	return f
+}
+
+func genFuzzTree(r *rand.Rand) fuzzTree {
+	var t fuzzTree
+	for range 1 + r.IntN(3) {
+		var l fuzzLevel
+		if r.IntN(4) != 0 {
+			l.Shared = genFuzzFile(r, 0)
+		}
+		if r.IntN(3) == 0 {
+			l.Local = genFuzzFile(r, 0)
+		}
+		t.Levels = append(t.Levels, l)
+	}
+	return t
+}
+
+func (f *fuzzFile) clone() *fuzzFile {
+	if f == nil {
+		return nil
+	}
+	c := *f
+	c.Diff = slices.Clone(f.Diff)
+	c.Msg = slices.Clone(f.Msg)
+	c.Branch = slices.Clone(f.Branch)
+	if f.Push != nil {
+		push := slices.Clone(*f.Push)
+		c.Push = &push
+	}
+	c.Includes = nil
+	for _, inc := range f.Includes {
+		c.Includes = append(c.Includes, inc.clone())
+	}
+	return &c
+}
+
+func (t fuzzTree) clone() fuzzTree {
+	var c fuzzTree
+	for _, l := range t.Levels {
+		c.Levels = append(c.Levels, fuzzLevel{l.Shared.clone(), l.Local.clone()})
+	}
+	return c
+}
+
+// files lists every file of the tree, includes too, for mutations.
+func (t fuzzTree) files() []*fuzzFile {
+	var out []*fuzzFile
+	var walk func(f *fuzzFile)
+	walk = func(f *fuzzFile) {
+		if f == nil {
+			return
+		}
+		out = append(out, f)
+		for _, inc := range f.Includes {
+			walk(inc)
+		}
+	}
+	for _, l := range t.Levels {
+		walk(l.Shared)
+		walk(l.Local)
+	}
+	return out
+}
+
+func shuffled[T any](r *rand.Rand, s []T) []T {
+	r.Shuffle(len(s), func(i, j int) { s[i], s[j] = s[j], s[i] })
+	return s
+}
+
+// permute reorders every pattern list and include list. Includes with
+// scalar settings don't exist (see genFuzzFile), so the policy must not
+// change.
+func (t fuzzTree) permute(r *rand.Rand) fuzzTree {
+	c := t.clone()
+	for _, f := range c.files() {
+		shuffled(r, f.Diff)
+		shuffled(r, f.Msg)
+		shuffled(r, f.Branch)
+		if f.Push != nil {
+			shuffled(r, *f.Push)
+		}
+		shuffled(r, f.Includes)
+	}
+	return c
+}
+
+// split moves part of one file's diff patterns into a new include.
+func (t fuzzTree) split(r *rand.Rand) (fuzzTree, bool) {
+	c := t.clone()
+	var candidates []*fuzzFile
+	for _, f := range c.files() {
+		if len(f.Diff) >= 2 {
+			candidates = append(candidates, f)
+		}
+	}
+	if len(candidates) == 0 {
+		return c, false
+	}
+	f := candidates[r.IntN(len(candidates))]
+	cut := 1 + r.IntN(len(f.Diff)-1)
+	f.Includes = append(f.Includes, &fuzzFile{Diff: f.Diff[cut:]})
+	f.Diff = f.Diff[:cut]
+	return c, true
+}
+
+// withPattern adds one diff pattern to a random file, creating the leaf
+// snag.toml when the tree has no files.
+func (t fuzzTree) withPattern(r *rand.Rand, pattern string) fuzzTree {
+	c := t.clone()
+	files := c.files()
+	if len(files) == 0 {
+		leaf := &c.Levels[len(c.Levels)-1]
+		leaf.Shared = &fuzzFile{}
+		files = []*fuzzFile{leaf.Shared}
+	}
+	f := files[r.IntN(len(files))]
+	f.Diff = append(f.Diff, pattern)
+	return c
+}
+
+func tomlList(key string, ss []string) string {
+	return fmt.Sprintf("%s = [%s]\n", key, strings.Join(quoteAll(ss), ", "))
+}
+
+// write renders f as name in dir, and its includes beside it.
+func (f *fuzzFile) write(dir, name string, seq *int) error {
+	var b strings.Builder
+	var incs []string
+	for _, inc := range f.Includes {
+		*seq++
+		incName := fmt.Sprintf("include-%d.toml", *seq)
+		if err := inc.write(dir, incName, seq); err != nil {
+			return err
+		}
+		incs = append(incs, incName)
+	}
+	if len(incs) > 0 {
+		b.WriteString(tomlList("include", incs))
+	}
+	b.WriteString("[block]\n")
+	b.WriteString(tomlList("diff", f.Diff))
+	b.WriteString(tomlList("msg", f.Msg))
+	if len(f.Branch) > 0 {
+		b.WriteString(tomlList("branch", f.Branch))
+	}
+	if f.Push != nil {
+		b.WriteString(tomlList("push", *f.Push))
+	}
+	if f.MsgMaxLen > 0 {
+		fmt.Fprintf(&b, "msg_max_len = %d\n", f.MsgMaxLen)
+	}
+	return os.WriteFile(filepath.Join(dir, name), []byte(b.String()), 0644)
+}
+
+// materialize writes the tree below a fresh directory and returns the
+// directories from leaf to root, the order the walk visits them.
+func (t fuzzTree) materialize(base string) ([]string, error) {
+	dir, err := os.MkdirTemp(base, "case-")
+	if err != nil {
+		return nil, err
+	}
+	var chain []string
+	for i, l := range t.Levels {
+		if i > 0 {
+			dir = filepath.Join(dir, fmt.Sprintf("l%d", i))
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+		seq := 0
+		if l.Shared != nil {
+			if err := l.Shared.write(dir, "snag.toml", &seq); err != nil {
+				return nil, err
+			}
+		}
+		if l.Local != nil {
+			if err := l.Local.write(dir, "snag-local.toml", &seq); err != nil {
+				return nil, err
+			}
+		}
+		chain = append([]string{dir}, chain...)
+	}
+	return chain, nil
+}
+
+// fuzzResolve merges a materialized tree the way walkConfig does, without
+// the real ancestors, then extra (the --with file) as if it sat in
+// SNAG_CONFIG_DIRS.
+func fuzzResolve(chain []string, extra string) (*BlockConfig, error) {
+	bc := &BlockConfig{}
+	for _, d := range chain {
+		if _, err := mergeConfigDir(bc, d); err != nil {
+			return nil, err
+		}
+	}
+	if extra != "" {
+		if err := mergeTOML(bc, extra); err != nil {
+			return nil, err
+		}
+	}
+	normalizeBlockConfig(bc, chain[0])
+	return bc, nil
+}
+
+// fuzzContent returns lines mixing random text with patterns in random
+// letter case, some of them one character off.
+func fuzzContent(r *rand.Rand, patterns []string) []string {
+	var lines []string
+	for range 8 {
+		line := fuzzWord(r, 0, 12)
+		if len(patterns) > 0 && r.IntN(2) == 0 {
+			p := []rune(patterns[r.IntN(len(patterns))])
+			for i := range p {
+				if r.IntN(2) == 0 {
+					p[i] = []rune(strings.ToUpper(string(p[i])))[0]
+				}
+			}
+			if r.IntN(4) == 0 && len(p) > 0 {
+				p[r.IntN(len(p))] = fuzzAlphabet[r.IntN(len(fuzzAlphabet))]
+			}
+			cut := r.IntN(len(line) + 1)
+			line = line[:cut] + string(p) + line[cut:]
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// plainPatterns drops sha256: and norm: patterns, which the reference
+// substring matcher can't model.
+func plainPatterns(patterns []string) []string {
+	return slices.DeleteFunc(slices.Clone(patterns), func(p string) bool {
+		return isHashPattern(p) || isNormPattern(p)
+	})
+}
+
+func referenceMatch(line string, patterns []string) bool {
+	for _, p := range patterns {
+		if strings.Contains(strings.ToLower(line), strings.ToLower(p)) {
+			return true
+		}
+	}
+	return false
+}
+
+// fuzzOnce runs every property over one generated tree.
+func fuzzOnce(base string, run int, seed uint64, extra string) ([]fuzzFailure, error) {
+	r := rand.New(rand.NewPCG(seed, 0))
+	fail := func(prop, format string, args ...any) fuzzFailure {
+		return fuzzFailure{Run: run, Seed: seed, Property: prop, Detail: fmt.Sprintf(format, args...)}
+	}
+	resolve := func(t fuzzTree) (*BlockConfig, string, error) {
+		chain, err := t.materialize(base)
+		if err != nil {
+			return nil, "", err
+		}
+		bc, err := fuzzResolve(chain, extra)
+		if err != nil {
+			return nil, "", fmt.Errorf("generated config failed to load: %w", err)
+		}
+		h, err := policyHash(bc)
+		return bc, h, err
+	}
+
+	tree := genFuzzTree(r)
+	bc, hash, err := resolve(tree)
+	if err != nil {
+		return nil, err
+	}
+	var failures []fuzzFailure
+
+	if _, again, err := resolve(tree); err != nil {
+		return nil, err
+	} else if again != hash {
+		failures = append(failures, fail("deterministic", "the same tree resolved to %s, then %s", hash[:12], again[:12]))
+	}
+	if _, h, err := resolve(tree.permute(r)); err != nil {
+		return nil, err
+	} else if h != hash {
+		failures = append(failures, fail("order", "reordering patterns and includes changed the policy from %s to %s", hash[:12], h[:12]))
+	}
+	if split, ok := tree.split(r); ok {
+		if _, h, err := resolve(split); err != nil {
+			return nil, err
+		} else if h != hash {
+			failures = append(failures, fail("split", "moving diff patterns into an include changed the policy from %s to %s", hash[:12], h[:12]))
+		}
+	}
+
+	content := fuzzContent(r, bc.Diff)
+	added := fuzzWord(r, 2, 4)
+	if added != "" {
+		wider, _, err := resolve(tree.withPattern(r, added))
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range content {
+			if _, before := matchesPattern(line, bc.Diff); before {
+				if _, after := matchesPattern(line, wider.Diff); !after {
+					failures = append(failures, fail("monotonic", "adding diff pattern %q unblocked %q", added, line))
+				}
+			}
+		}
+	}
+
+	plain := plainPatterns(bc.Diff)
+	for _, line := range content {
+		p, got := matchesPattern(line, bc.Diff)
+		if isHashPattern(p) || isNormPattern(p) {
+			continue
+		}
+		if want := referenceMatch(line, plain); got != want {
+			failures = append(failures, fail("match", "%q: matched=%v, but substring search says %v (patterns %s)", line, got, want, strings.Join(quoteAll(plain), ", ")))
+		}
+		if _, upper := matchesPattern(strings.ToUpper(line), bc.Diff); upper != got {
+			failures = append(failures, fail("match", "%q matched=%v but its upper case matched=%v", line, got, upper))
+		}
+	}
+	return failures, nil
+}
+
+func buildConfigFuzzCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fuzz",
+		Short: "Check config merging and matching against random configs and content",
+		Long: `Generate random config hierarchies (nested directories with snag.toml,
+snag-local.toml and includes) and random content, then check properties the
+resolution and matching pipeline promises:
+
+  deterministic  resolving the same tree twice gives the same policy
+  order          reordering patterns and includes doesn't change the policy
+  split          moving patterns into an included file doesn't change it
+  monotonic      adding a pattern never unblocks a line that was blocked
+  match          a plain pattern matches exactly when it is a
+                 case-insensitive substring, in any letter case
+
+--with merges your own config file into every generated tree, to check that
+custom rule packs behave. Each run prints its seed; re-run one with
+--seed SEED --runs 1. Exits non-zero when a property fails.`,
+		Example: `  snag config fuzz
+  snag config fuzz --runs 2000 --with policies/secrets.toml
+  snag config fuzz --seed 1729 --runs 1`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runConfigFuzz,
+	}
+	cmd.Flags().Int("runs", 200, "number of random trees to generate")
+	cmd.Flags().Uint64("seed", 0, "seed of the first run (default: from the clock)")
+	cmd.Flags().String("with", "", "config file to merge into every generated tree")
+	return cmd
+}
+
+func runConfigFuzz(cmd *cobra.Command, args []string) error {
+	runs, _ := cmd.Flags().GetInt("runs")
+	seed, _ := cmd.Flags().GetUint64("seed")
+	with, _ := cmd.Flags().GetString("with")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	if runs < 1 {
+		return fmt.Errorf("--runs must be at least 1")
+	}
+	if !cmd.Flags().Changed("seed") {
+		seed = uint64(time.Now().UnixNano())
+	}
+	if with != "" {
+		with = absPath(with)
+		if _, err := loadSnagTOML(with); err != nil {
+			return err
+		}
+	}
+	base, err := os.MkdirTemp("", "snag-fuzz-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(base)
+
+	var failures []fuzzFailure
+	for i := range runs {
+		f, err := fuzzOnce(base, i, seed+uint64(i), with)
+		if err != nil {
+			return fmt.Errorf("run %d (seed %d): %w", i, seed+uint64(i), err)
+		}
+		failures = append(failures, f...)
+	}
+
+	out := cmd.OutOrStdout()
+	for _, f := range failures {
+		fmt.Fprintf(out, "%s: run %d (seed %d): %s\n", f.Property, f.Run, f.Seed, f.Detail)
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%d inconsistencies in %d runs; reproduce one with snag config fuzz --seed SEED --runs 1", len(failures), runs)
+	}
+	if !quiet {
+		infof("%d runs from seed %d: no inconsistencies", runs, seed)
+	}
+	return nil
+}
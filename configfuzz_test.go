@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigFuzz(t *testing.T) {
+	dir := t.TempDir()
+	with := filepath.Join(dir, "pack.toml")
+	os.WriteFile(with, []byte("[block]\ndiff = [\"norm:secret\", \"ABC\"]\n"), 0644)
+
+	var out bytes.Buffer
+	rootCmd := buildRootCmd()
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"config", "fuzz", "--runs", "30", "--seed", "1729", "--with", with, "--quiet"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("fuzz found inconsistencies: %v\n%s", err, out.String())
+	}
+
+	os.WriteFile(with, []byte("[block\n"), 0644)
+	rootCmd = buildRootCmd()
+	rootCmd.SetArgs([]string{"config", "fuzz", "--runs", "1", "--with", with})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("a --with file that doesn't parse should fail up front")
+	}
+}
+
+func TestFuzzTreeMutations(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	tree := fuzzTree{Levels: []fuzzLevel{{Shared: &fuzzFile{Diff: []string{"aa", "bb", "cc"}, Includes: []*fuzzFile{{Msg: []string{"dd"}}}}}}}
+
+	split, ok := tree.split(r)
+	if !ok {
+		t.Fatal("a file with three diff patterns can be split")
+	}
+	if got := len(tree.Levels[0].Shared.Diff); got != 3 {
+		t.Errorf("split changed the original tree: %d diff patterns", got)
+	}
+	top := split.Levels[0].Shared
+	moved := top.Includes[len(top.Includes)-1].Diff
+	if len(top.Diff)+len(moved) != 3 || len(moved) == 0 {
+		t.Errorf("split kept %v and moved %v", top.Diff, moved)
+	}
+
+	chain, err := split.materialize(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	bc, err := fuzzResolve(chain, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bc.Diff) != 3 || len(bc.Msg) != 1 {
+		t.Errorf("resolved diff %v, msg %v", bc.Diff, bc.Msg)
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+)
+
+// policyChange is one commit's effect on a config file's patterns.
+type policyChange struct {
+	SHA, Author, Date, Subject string
+	Added, Removed             []string // "diff \"todo\"" style entries
+	RenamedFrom                string   // repo-relative path before a rename in this commit
+	Other                      bool     // the file changed in ways other than patterns
+}
+
+func buildConfigHistoryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show when patterns were added or removed, and by whom",
+		Long: `Render a changelog of the governing policy from git history.
+
+Every plain-text config file in the chain (snag.toml and snag-local.toml,
+from the current directory up, then SNAG_CONFIG_DIRS) that is tracked in a
+git repository is followed through its log. Each commit is listed with the
+patterns it added (+) or removed (-). Patterns from sensitive = true files
+are redacted. Untracked and encrypted files are noted and skipped.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runConfigHistory,
+	}
+	cmd.Flags().IntP("max-count", "n", 0, "show at most N commits per file (0 = all)")
+	return cmd
+}
+
+func runConfigHistory(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	limit, _ := cmd.Flags().GetInt("max-count")
+	out := cmd.OutOrStdout()
+	quiet, _ := cmd.Flags().GetBool("quiet")
+
+	shown := 0
+	for _, dir := range configChain(cwd) {
+		for _, name := range append([]string{"snag.toml"}, localConfigNames...) {
+			path := filepath.Join(dir, name)
+			if !fileExists(path) {
+				continue
+			}
+			if name != "snag.toml" && name != "snag-local.toml" {
+				if !quiet {
+					hintf("%s: encrypted, history not shown", path)
+				}
+				continue
+			}
+			changes, tracked, err := configHistory(path, limit)
+			if err != nil {
+				return err
+			}
+			if !tracked {
+				if !quiet {
+					hintf("%s: not tracked by git, no history", path)
+				}
+				continue
+			}
+			if shown > 0 {
+				fmt.Fprintln(out)
+			}
+			shown++
+			renderPolicyChanges(out, path, changes)
+		}
+	}
+	if shown == 0 {
+		return fmt.Errorf("no tracked snag config in the chain")
+	}
+	return nil
+}
+
+// configHistory walks git log for path, newest first. tracked is false when
+// path is outside any repository or not committed.
+func configHistory(path string, limit int) ([]policyChange, bool, error) {
+	dir, base := filepath.Dir(path), filepath.Base(path)
+	logArgs := []string{"-C", dir, "log", "--follow", "--find-renames", "--date=short", "--name-status",
+		"--format=%x01%H%x00%an%x00%ad%x00%s"}
+	if limit > 0 {
+		logArgs = append(logArgs, fmt.Sprintf("--max-count=%d", limit))
+	}
+	logArgs = append(logArgs, "--", base)
+	raw, err := gitCommand(logArgs...).Output()
+	if err != nil || len(strings.TrimSpace(string(raw))) == 0 {
+		return nil, false, nil
+	}
+	top, err := gitCommand("-C", dir, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return nil, false, nil
+	}
+	root := strings.TrimSpace(string(top))
+
+	var changes []policyChange
+	for _, rec := range strings.Split(string(raw), "\x01") {
+		head, names, _ := strings.Cut(strings.TrimSpace(rec), "\n")
+		fields := strings.Split(head, "\x00")
+		if len(fields) != 4 {
+			continue
+		}
+		// With --follow, the name listed is the file's path in that commit;
+		// a rename lists "R<score>\told\tnew", and the parent has it at old.
+		status := strings.Split(strings.TrimSpace(names), "\t")
+		if len(status) < 2 {
+			continue
+		}
+		rel, prev := unquoteGitPath(status[len(status)-1]), unquoteGitPath(status[1])
+		c := policyChange{SHA: fields[0], Author: fields[1], Date: fields[2], Subject: fields[3]}
+		if strings.HasPrefix(status[0], "R") {
+			c.RenamedFrom = prev
+		}
+		after := showConfigAt(root, c.SHA, rel)
+		before := showConfigAt(root, c.SHA+"^", prev)
+		c.Added, c.Removed = patternDelta(before, after)
+		c.Other = len(c.Added) == 0 && len(c.Removed) == 0 && c.RenamedFrom == ""
+		changes = append(changes, c)
+	}
+	return changes, true, nil
+}
+
+// showConfigAt parses the config at rev:rel; a missing or unparsable file
+// reads as empty.
+func showConfigAt(root, rev, rel string) snagTOML {
+	var cfg snagTOML
+	data, err := gitCommand("-C", root, "show", rev+":"+rel).Output()
+	if err == nil {
+		toml.Unmarshal(data, &cfg)
+	}
+	return cfg
+}
+
+// patternDelta lists patterns present in only one of before and after,
+// per block phase. Comparison is case-insensitive, as matching is.
+func patternDelta(before, after snagTOML) (added, removed []string) {
+	push := func(c snagTOML) []string {
+		if c.Block.Push == nil {
+			return nil
+		}
+		return *c.Block.Push
+	}
+	phases := []struct {
+		name          string
+		before, after []string
+	}{
+		{"diff", before.Block.Diff, after.Block.Diff},
+		{"msg", before.Block.Msg, after.Block.Msg},
+		{"push", push(before), push(after)},
+		{"branch", before.Block.Branch, after.Block.Branch},
+	}
+	for _, ph := range phases {
+		old, cur := setOf(ph.before), setOf(ph.after)
+		for _, p := range ph.after {
+			if !old[strings.ToLower(p)] {
+				added = append(added, fmt.Sprintf("%s %q", ph.name, displayAt(after, p)))
+			}
+		}
+		for _, p := range ph.before {
+			if !cur[strings.ToLower(p)] {
+				removed = append(removed, fmt.Sprintf("%s %q", ph.name, displayAt(before, p)))
+			}
+		}
+	}
+	return added, removed
+}
+
+func setOf(patterns []string) map[string]bool {
+	m := make(map[string]bool, len(patterns))
+	for _, p := range patterns {
+		m[strings.ToLower(p)] = true
+	}
+	return m
+}
+
+// displayAt redacts p when the file was marked sensitive at that revision.
+func displayAt(cfg snagTOML, p string) string {
+	if cfg.Block.Sensitive {
+		return redact(p)
+	}
+	return p
+}
+
+func renderPolicyChanges(w io.Writer, path string, changes []policyChange) {
+	fmt.Fprintln(w, path)
+	for _, c := range changes {
+		fmt.Fprintf(w, "  %s  %.7s  %s  %s\n", c.Date, c.SHA, c.Author, c.Subject)
+		if c.RenamedFrom != "" {
+			fmt.Fprintf(w, "      (renamed from %s)\n", c.RenamedFrom)
+		}
+		for _, a := range c.Added {
+			fmt.Fprintf(w, "      + %s\n", a)
+		}
+		for _, r := range c.Removed {
+			fmt.Fprintf(w, "      - %s\n", r)
+		}
+		if c.Other {
+			fmt.Fprintln(w, "      (settings changed, no pattern changes)")
+		}
+	}
+}
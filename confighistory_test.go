@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestConfigHistory(t *testing.T) {
+	dir := initGitRepo(t)
+	commitFile(t, dir, "snag.toml", "[block]\ndiff = [\"todo\"]\nmsg = [\"wip\"]\n", "Add snag policy")
+	commitFile(t, dir, "snag.toml", "[block]\ndiff = [\"todo\", \"hack\"]\n", "Block hack, allow wip")
+	commitFile(t, dir, "snag.toml", "[block]\ndiff = [\"todo\", \"hack\"]\n\n[limits]\nmax_warnings = 3\n", "Cap warnings")
+	commitFile(t, dir, "snag.toml", "[block]\nsensitive = true\ndiff = [\"todo\", \"hack\", \"projectx\"]\n\n[limits]\nmax_warnings = 3\n", "Add codename")
+	os.WriteFile(dir+"/snag-local.toml", []byte("[block]\ndiff = [\"mine\"]\n"), 0644)
+
+	orig, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(orig)
+	var buf bytes.Buffer
+	rootCmd := buildRootCmd()
+	rootCmd.SetOut(&buf)
+	rootCmd.SetArgs([]string{"config", "history", "-q"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("config history: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"  Test  Add snag policy",
+		"Add snag policy\n      + diff \"todo\"\n      + msg \"wip\"",
+		"Block hack, allow wip\n      + diff \"hack\"\n      - msg \"wip\"",
+		"Cap warnings\n      (settings changed, no pattern changes)",
+		"Add codename\n      + diff \"pr****tx\"",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "projectx") || strings.Contains(out, "mine") {
+		t.Errorf("history leaks sensitive or untracked patterns:\n%s", out)
+	}
+	if strings.Index(out, "Add codename") > strings.Index(out, "Add snag policy") {
+		t.Errorf("expected newest first:\n%s", out)
+	}
+}
+
+// A rename is followed back to the old path instead of reading as every
+// pattern added.
+func TestConfigHistory_Rename(t *testing.T) {
+	dir := initGitRepo(t)
+	os.MkdirAll(dir+"/policy", 0755)
+	commitFile(t, dir, "policy/snag.toml", "[block]\ndiff = [\"todo\", \"hack\"]\n", "Add snag policy")
+	if out, err := exec.Command("git", "-C", dir, "mv", "policy/snag.toml", "snag.toml").CombinedOutput(); err != nil {
+		t.Fatalf("git mv: %v\n%s", err, out)
+	}
+	if out, err := exec.Command("git", "-C", dir, "commit", "-qm", "Move policy to the root").CombinedOutput(); err != nil {
+		t.Fatalf("git commit: %v\n%s", err, out)
+	}
+
+	orig, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(orig)
+	var buf bytes.Buffer
+	rootCmd := buildRootCmd()
+	rootCmd.SetOut(&buf)
+	rootCmd.SetArgs([]string{"config", "history", "-q"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("config history: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "Move policy to the root\n      (renamed from policy/snag.toml)\n  ") {
+		t.Errorf("rename not reported:\n%s", out)
+	}
+	if strings.Count(out, `+ diff "todo"`) != 1 {
+		t.Errorf("rename should not re-add patterns:\n%s", out)
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"path"
+	"strings"
+)
+
+// conflictMarkers are the merge markers git writes at the start of a line.
+// The diff3 base marker (|||||||) is included.
+var conflictMarkers = []string{"<<<<<<<", "|||||||", ">>>>>>>"}
+
+// conflictSeparator is the middle marker. Exactly seven '=' is also a valid
+// Markdown/reStructuredText heading underline, so it is not flagged in
+// prose files.
+const conflictSeparator = "======="
+
+var proseExts = []string{".md", ".markdown", ".rst", ".adoc", ".txt"}
+
+// checkConflictMarker is the "conflict" detector: it flags unresolved merge
+// markers left in added lines.
+func checkConflictMarker(p, line string) (string, int, bool) {
+	line = strings.TrimRight(line, "\r")
+	for _, m := range conflictMarkers {
+		if line == m || strings.HasPrefix(line, m+" ") {
+			return m, 1, true
+		}
+	}
+	if line == conflictSeparator && !hasExt(proseExts, strings.ToLower(path.Ext(p))) {
+		return conflictSeparator, 1, true
+	}
+	return "", 0, false
+}
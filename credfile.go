@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// The "credfile" detector catches secrets by where they sit rather than
+// what they look like, so a token format no rule knows is still caught: any
+// value assigned in a dotenv file, and long values under credential-named
+// keys in JSON.
+
+const credFileDetector = "credfile"
+
+// isDotenvPath reports whether p is a dotenv file: .env, .env.local,
+// production.env. Templates (.env.example ...) are excluded by the
+// detector's default globs instead, so [detect.credfile] can narrow them.
+func isDotenvPath(p string) bool {
+	base := path.Base(p)
+	return base == ".env" || strings.HasPrefix(base, ".env.") || strings.HasSuffix(base, ".env")
+}
+
+var (
+	dotenvAssign = regexp.MustCompile(`^\s*(?:export\s+)?([A-Za-z_][A-Za-z0-9_.]*)\s*=\s*(.*)$`)
+	jsonStringKV = regexp.MustCompile(`"([^"\\]+)"\s*:\s*"((?:[^"\\]|\\.)*)"`)
+)
+
+// credentialKeySuffixes name JSON keys that hold credentials, compared
+// against the key lowercased with separators dropped: accessToken,
+// client_secret, api-key.
+var credentialKeySuffixes = []string{"token", "secret", "secretkey", "apikey"}
+
+// minCredentialValueLen is the shortest JSON value treated as a credential;
+// shorter values under those keys are usually names or flags.
+const minCredentialValueLen = 16
+
+// placeholderValue matches values that stand in for a credential: variable
+// references, <angle> markers, and the usual filler words.
+var placeholderValue = regexp.MustCompile(`(?i)^(\$\{?\w+\}?|<[^>]*>|\{\{.*\}\}|x{3,}|\*{3,}|changeme|change-me|replace-?me|todo|none|null|false|true|your[-_].*)$`)
+
+// dotenvValue returns the value of an assignment: unquoted, with a
+// trailing " # comment" dropped from unquoted values.
+func dotenvValue(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if len(raw) >= 2 && (raw[0] == '"' || raw[0] == '\'') {
+		if end := strings.IndexByte(raw[1:], raw[0]); end >= 0 {
+			return raw[1 : end+1]
+		}
+	}
+	if i := strings.Index(raw, " #"); i >= 0 {
+		raw = raw[:i]
+	}
+	return strings.TrimSpace(raw)
+}
+
+// credentialKey reports whether a JSON key names a credential.
+func credentialKey(key string) bool {
+	k := strings.NewReplacer("_", "", "-", "", ".", "").Replace(strings.ToLower(key))
+	for _, s := range credentialKeySuffixes {
+		if strings.HasSuffix(k, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCredentialFile is the "credfile" detector. Like the token detector
+// it reports the value redacted.
+func checkCredentialFile(p, line string) (string, int, bool) {
+	switch {
+	case isDotenvPath(p):
+		m := dotenvAssign.FindStringSubmatchIndex(line)
+		if m == nil {
+			return "", 0, false
+		}
+		key, value := line[m[2]:m[3]], dotenvValue(line[m[4]:m[5]])
+		if value == "" || placeholderValue.MatchString(value) {
+			return "", 0, false
+		}
+		return fmt.Sprintf("%s=%s (dotenv)", key, redact(value)), m[2] + 1, true
+	case strings.EqualFold(path.Ext(p), ".json"):
+		for _, m := range jsonStringKV.FindAllStringSubmatchIndex(line, -1) {
+			key, value := line[m[2]:m[3]], line[m[4]:m[5]]
+			if !credentialKey(key) || len(value) < minCredentialValueLen || strings.ContainsAny(value, " \t") || placeholderValue.MatchString(value) {
+				continue
+			}
+			return fmt.Sprintf("%q: %s (json)", key, redact(value)), m[0] + 1, true
+		}
+	}
+	return "", 0, false
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckCredentialFile(t *testing.T) {
+	cases := []struct {
+		path, line string
+		want       bool
+	}{
+		{".env", `DATABASE_URL=postgres://app:hunter2@db/app`, true},
+		{"deploy/prod.env", `export API_KEY="q8Zt0"`, true},
+		{".env.local", `PORT=8080 # local only`, true},
+		{".env", `API_KEY=`, false},
+		{".env", `API_KEY=""`, false},
+		{".env", `API_KEY=${API_KEY}`, false},
+		{".env", `API_KEY=<your-key>`, false},
+		{".env", `# API_KEY=abc`, false},
+		{"config/app.json", `  "accessToken": "9f8e7d6c5b4a39281706f5e4",`, true},
+		{"config/app.json", `{"name": "x", "client_secret": "Zm9vYmFyYmF6cXV4MTIz"}`, true},
+		{"config/app.json", `  "accessToken": "short",`, false},
+		{"config/app.json", `  "tokenizer": "wordpiece-uncased-large",`, false},
+		{"config/app.json", `  "api_key": "xxxxxxxxxxxxxxxxxxxx",`, false},
+		{"config/app.yaml", `api_key: 9f8e7d6c5b4a39281706f5e4`, false},
+		{"main.go", `KEY=value`, false},
+	}
+	for _, c := range cases {
+		match, col, ok := checkCredentialFile(c.path, c.line)
+		if ok != c.want {
+			t.Errorf("%s %q: got %q, %v; want %v", c.path, c.line, match, ok, c.want)
+		}
+		if ok && (col < 1 || strings.Contains(match, "hunter2") || strings.Contains(match, "9f8e7d6c5b4a")) {
+			t.Errorf("%s %q: match %q at col %d should be redacted", c.path, c.line, match, col)
+		}
+	}
+}
+
+func TestCredentialFileExcludesTemplates(t *testing.T) {
+	d := findDetector(credFileDetector)
+	bc := &BlockConfig{}
+	for p, want := range map[string]bool{".env.example": true, "config/.env.sample": true, "secrets.example.json": true, ".env": false} {
+		if got := bc.detectExcluded(d, p); got != want {
+			t.Errorf("detectExcluded(%q) = %v, want %v", p, got, want)
+		}
+	}
+}
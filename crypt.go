@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// localConfigNames lists the personal overlay filenames checked at each
+// config level, in merge order. The encrypted variants let a blocklist of
+// real client names or hostnames live on disk without being plaintext:
+//
+//	snag-local.toml.age   age-encrypted; identity from SNAG_AGE_IDENTITY
+//	snag-local.sops.toml  SOPS binary-format; keys from SOPS's own env
+var localConfigNames = []string{
+	"snag-local.toml",
+	"snag-local.toml.age",
+	"snag-local.sops.toml",
+}
+
+// readConfigFile returns the plaintext of a config file, decrypting it via
+// the age or sops CLI when its name says it is encrypted. Decryption happens
+// in memory only — the plaintext is never written to disk.
+func readConfigFile(path string) ([]byte, error) {
+	switch {
+	case strings.HasSuffix(path, ".age"):
+		if _, err := os.Stat(path); err != nil {
+			return nil, err
+		}
+		return decryptAge(path)
+	case strings.HasSuffix(path, ".sops.toml"):
+		if _, err := os.Stat(path); err != nil {
+			return nil, err
+		}
+		return decryptSOPS(path)
+	}
+	return os.ReadFile(path)
+}
+
+// decryptAge decrypts path with `age --decrypt`, using the identity file
+// named by SNAG_AGE_IDENTITY.
+func decryptAge(path string) ([]byte, error) {
+	identity := os.Getenv("SNAG_AGE_IDENTITY")
+	if identity == "" {
+		return nil, fmt.Errorf("%s is age-encrypted: set SNAG_AGE_IDENTITY to your age identity file", path)
+	}
+	return runDecrypt(path, "age", "--decrypt", "-i", identity, path)
+}
+
+// decryptSOPS decrypts path with `sops --decrypt`. SOPS has no TOML store,
+// so the file is treated as an opaque binary blob.
+func decryptSOPS(path string) ([]byte, error) {
+	return runDecrypt(path, "sops", "--decrypt", "--input-type", "binary", "--output-type", "binary", path)
+}
+
+func runDecrypt(path, name string, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return nil, fmt.Errorf("%s is encrypted but %s is not on PATH", path, name)
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command(name, args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("decrypting %s: %w\n%s", path, err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeDecryptor puts a stub binary named name on PATH that prints plaintext
+// and records its arguments to argsFile.
+func fakeDecryptor(t *testing.T, name, plaintext string) (argsFile string) {
+	t.Helper()
+	bin := t.TempDir()
+	argsFile = filepath.Join(bin, "args")
+	script := "#!/bin/sh\necho \"$@\" > " + argsFile + "\ncat <<'SNAG_EOF'\n" + plaintext + "SNAG_EOF\n"
+	if err := os.WriteFile(filepath.Join(bin, name), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(filepath.ListSeparator)+os.Getenv("PATH"))
+	return argsFile
+}
+
+func TestWalkConfig_AgeEncryptedLocal(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"TEAM\"]\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "snag-local.toml.age"), []byte("age-encryption.org/v1 ..."), 0600)
+
+	argsFile := fakeDecryptor(t, "age", "[block]\ndiff = [\"acme-client\"]\n")
+	t.Setenv("SNAG_AGE_IDENTITY", "/keys/me.txt")
+
+	bc, found, err := walkConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found {
+		t.Fatal("expected found=true")
+	}
+	if len(bc.Diff) != 2 || bc.Diff[0] != "acme-client" {
+		t.Errorf("diff: got %v, want [acme-client TEAM] (overlays merge first)", bc.Diff)
+	}
+	args, _ := os.ReadFile(argsFile)
+	if !strings.Contains(string(args), "-i /keys/me.txt") {
+		t.Errorf("age should be called with identity, got args %q", args)
+	}
+}
+
+func TestWalkConfig_AgeMissingIdentity(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "snag-local.toml.age"), []byte("ciphertext"), 0600)
+	fakeDecryptor(t, "age", "")
+	t.Setenv("SNAG_AGE_IDENTITY", "")
+
+	_, _, err := walkConfig(dir)
+	if err == nil || !strings.Contains(err.Error(), "SNAG_AGE_IDENTITY") {
+		t.Fatalf("expected identity hint, got %v", err)
+	}
+}
+
+func TestWalkConfig_SOPSEncryptedLocal(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "snag-local.sops.toml"), []byte(`{"data": "ENC[...]"}`), 0600)
+	fakeDecryptor(t, "sops", "[block]\nmsg = [\"internal.corp\"]\n")
+
+	bc, _, err := walkConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bc.Msg) != 1 || bc.Msg[0] != "internal.corp" {
+		t.Errorf("msg: got %v, want [internal.corp]", bc.Msg)
+	}
+}
+
+func TestReadConfigFile_MissingDecryptor(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "snag-local.sops.toml")
+	os.WriteFile(path, []byte("x"), 0600)
+	t.Setenv("PATH", t.TempDir())
+
+	if _, err := readConfigFile(path); err == nil || !strings.Contains(err.Error(), "not on PATH") {
+		t.Fatalf("expected missing-binary error, got %v", err)
+	}
+}
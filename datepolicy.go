@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// now is the clock used by date policy checks; tests replace it.
+var now = time.Now
+
+// parseHourWindow parses "HH:MM-HH:MM" into minutes after midnight. The
+// window may wrap midnight ("22:00-06:00").
+func parseHourWindow(s string) (start, end int, err error) {
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("want HH:MM-HH:MM, got %q", s)
+	}
+	if start, err = parseClock(strings.TrimSpace(from)); err != nil {
+		return 0, 0, err
+	}
+	if end, err = parseClock(strings.TrimSpace(to)); err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("bad time %q (want HH:MM)", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// inHourWindow reports whether t's local wall-clock time falls in window.
+// Start is inclusive, end exclusive.
+func inHourWindow(t time.Time, window string) bool {
+	start, end, err := parseHourWindow(window)
+	if err != nil || start == end {
+		return false
+	}
+	m := t.Hour()*60 + t.Minute()
+	if start < end {
+		return m >= start && m < end
+	}
+	return m >= start || m < end
+}
+
+// datePolicyViolation checks one commit date against commit_hours and
+// date_tolerance. Returns "" when the date is acceptable.
+func datePolicyViolation(bc *BlockConfig, label string, t time.Time) string {
+	if bc.CommitHours != "" && inHourWindow(t.Local(), bc.CommitHours) {
+		return fmt.Sprintf("%s %s falls in blocked hours %s", label, t.Local().Format("15:04"), bc.CommitHours)
+	}
+	if bc.DateTolerance > 0 {
+		skew := t.Sub(now())
+		if skew > bc.DateTolerance {
+			return fmt.Sprintf("%s is %s in the future (tolerance %s)", label, skew.Round(time.Minute), bc.DateTolerance)
+		}
+	}
+	return ""
+}
+
+// gitIdentDate returns the date git will record for a commit, honoring
+// GIT_AUTHOR_DATE / GIT_COMMITTER_DATE overrides. which is "AUTHOR" or
+// "COMMITTER"; git var parses every date format git accepts.
+func gitIdentDate(which string) (time.Time, error) {
+	out, err := gitCommand("var", "GIT_"+which+"_IDENT").Output()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("git var GIT_%s_IDENT: %w", which, err)
+	}
+	// "Name <email> 1700000000 +0000"
+	f := strings.Fields(strings.TrimSpace(string(out)))
+	if len(f) < 2 {
+		return time.Time{}, fmt.Errorf("unexpected ident %q", out)
+	}
+	secs, err := strconv.ParseInt(f[len(f)-2], 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unexpected ident %q", out)
+	}
+	return time.Unix(secs, 0), nil
+}
+
+// checkCommitDates enforces the date policy for the commit being created
+// (commit-msg). Backdating at commit time is caught here too: any explicit
+// date further than date_tolerance from now in either direction is blocked.
+func checkCommitDates(cmd *cobra.Command, bc *BlockConfig) error {
+	if (bc.CommitHours == "" && bc.DateTolerance == 0) || os.Getenv("SNAG_ALLOW_DATE") == "1" {
+		return nil
+	}
+	for _, which := range []string{"AUTHOR", "COMMITTER"} {
+		t, err := gitIdentDate(which)
+		if err != nil {
+			return err
+		}
+		label := strings.ToLower(which) + " date"
+		why := datePolicyViolation(bc, label, t)
+		if why == "" && bc.DateTolerance > 0 && now().Sub(t) > bc.DateTolerance {
+			why = fmt.Sprintf("%s is backdated by %s (tolerance %s)", label, now().Sub(t).Round(time.Minute), bc.DateTolerance)
+		}
+		if why != "" {
+			return dateViolation(cmd, why)
+		}
+	}
+	return nil
+}
+
+// checkPushDates enforces the date policy on an unpushed commit. Old author
+// dates are normal on long-lived branches, so only commit_hours and
+// future-dated commits are checked here.
+func checkPushDates(cmd *cobra.Command, bc *BlockConfig, c pushCommit) error {
+	if (bc.CommitHours == "" && bc.DateTolerance == 0) || os.Getenv("SNAG_ALLOW_DATE") == "1" {
+		return nil
+	}
+	for _, d := range []struct {
+		label string
+		t     time.Time
+	}{{"author date", c.AuthorDate}, {"committer date", c.CommitDate}} {
+		if d.t.IsZero() {
+			continue
+		}
+		if why := datePolicyViolation(bc, d.label+" of "+c.SHA[:7], d.t); why != "" {
+			return dateViolation(cmd, why)
+		}
+	}
+	return nil
+}
+
+func dateViolation(cmd *cobra.Command, why string) error {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	if !quiet {
+		blockf(idCommitDate, "%s", why)
+		hintf("to override: SNAG_ALLOW_DATE=1 git commit ... (or git push ...)")
+		bell()
+	}
+	return violationf(idCommitDate, "%s", why)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInHourWindow(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2024, 1, 1, h, m, 0, 0, time.Local) }
+	tests := []struct {
+		window string
+		t      time.Time
+		want   bool
+	}{
+		{"22:00-06:00", at(23, 30), true},
+		{"22:00-06:00", at(3, 0), true},
+		{"22:00-06:00", at(6, 0), false},
+		{"22:00-06:00", at(12, 0), false},
+		{"09:00-17:00", at(9, 0), true},
+		{"09:00-17:00", at(17, 0), false},
+		{"09:00-09:00", at(9, 0), false},
+		{"bogus", at(9, 0), false},
+	}
+	for _, tc := range tests {
+		if got := inHourWindow(tc.t, tc.window); got != tc.want {
+			t.Errorf("inHourWindow(%s, %q) = %v, want %v", tc.t.Format("15:04"), tc.window, got, tc.want)
+		}
+	}
+}
+
+func TestParseHourWindow_Invalid(t *testing.T) {
+	for _, s := range []string{"", "22:00", "25:00-06:00", "22-06"} {
+		if _, _, err := parseHourWindow(s); err == nil {
+			t.Errorf("parseHourWindow(%q) should fail", s)
+		}
+	}
+}
+
+func TestDatePolicyViolation_Future(t *testing.T) {
+	fixed := time.Date(2024, 6, 1, 12, 0, 0, 0, time.Local)
+	old := now
+	now = func() time.Time { return fixed }
+	defer func() { now = old }()
+
+	bc := &BlockConfig{DateTolerance: time.Hour}
+	if why := datePolicyViolation(bc, "date", fixed.Add(30*time.Minute)); why != "" {
+		t.Errorf("within tolerance flagged: %s", why)
+	}
+	if why := datePolicyViolation(bc, "date", fixed.Add(3*time.Hour)); !strings.Contains(why, "future") {
+		t.Errorf("future date not flagged, got %q", why)
+	}
+	if why := datePolicyViolation(bc, "date", fixed.Add(-72*time.Hour)); why != "" {
+		t.Errorf("old date flagged by push-side check: %s", why)
+	}
+}
+
+func TestParsePushRecord_Dates(t *testing.T) {
+	c, ok := parsePushRecord("abc123 1700000000 1700000060\x00msg\x00")
+	if !ok {
+		t.Fatal("record not parsed")
+	}
+	if c.SHA != "abc123" || c.AuthorDate.Unix() != 1700000000 || c.CommitDate.Unix() != 1700000060 {
+		t.Errorf("got %+v", c)
+	}
+}
+
+func TestRunMsg_BackdatedCommit(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[block]\ndate_tolerance = \"24h\"\n"), 0644)
+	msgFile := filepath.Join(dir, "COMMIT_EDITMSG")
+	os.WriteFile(msgFile, []byte("add feature\n"), 0644)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	run := func() error {
+		rootCmd := buildRootCmd()
+		rootCmd.SetArgs([]string{"check", "msg", "-q", msgFile})
+		return rootCmd.Execute()
+	}
+
+	if err := run(); err != nil {
+		t.Fatalf("current date blocked: %v", err)
+	}
+
+	t.Setenv("GIT_AUTHOR_DATE", "2001-01-01T00:00:00Z")
+	err := run()
+	if err == nil || !strings.Contains(err.Error(), "backdated") {
+		t.Fatalf("expected backdated violation, got %v", err)
+	}
+
+	t.Setenv("SNAG_ALLOW_DATE", "1")
+	if err := run(); err != nil {
+		t.Errorf("SNAG_ALLOW_DATE=1 should override, got %v", err)
+	}
+}
+
+func TestLoadSnagTOML_BadCommitHours(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snag.toml")
+	os.WriteFile(path, []byte("[block]\ncommit_hours = \"late\"\n"), 0644)
+	if _, err := loadSnagTOML(path); err == nil || !strings.Contains(err.Error(), "commit_hours") {
+		t.Errorf("expected commit_hours error, got %v", err)
+	}
+}
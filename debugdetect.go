@@ -0,0 +1,124 @@
+package main
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// debugLang holds the debug-statement patterns for one language family.
+type debugLang struct {
+	Exts     []string
+	Comments []string // line-comment prefixes; commented-out calls are ignored
+	Patterns []*regexp.Regexp
+}
+
+var (
+	slashComments = []string{"//", "/*", "*"}
+	hashComments  = []string{"#"}
+)
+
+// debugLangs maps source extensions to the debug leftovers typical there.
+// Patterns only fire in files of matching extension, so a README that
+// mentions console.log is never flagged.
+var debugLangs = []debugLang{
+	{
+		Exts:     []string{".go"},
+		Comments: slashComments,
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`\bfmt\.Print(ln|f)?\(`),
+			regexp.MustCompile(`\bprintln\(`),
+			regexp.MustCompile(`\bspew\.Dump\(`),
+		},
+	},
+	{
+		Exts:     []string{".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx", ".vue", ".svelte"},
+		Comments: slashComments,
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`\bconsole\.(log|debug|trace|dir)\(`),
+			regexp.MustCompile(`\bdebugger\s*;?\s*$`),
+		},
+	},
+	{
+		Exts:     []string{".rb", ".rake", ".erb"},
+		Comments: hashComments,
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`\bbinding\.(pry|irb)\b`),
+			regexp.MustCompile(`\bbyebug\b`),
+			regexp.MustCompile(`^\s*debugger\b`),
+		},
+	},
+	{
+		Exts:     []string{".py"},
+		Comments: hashComments,
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`\bbreakpoint\(\)`),
+			regexp.MustCompile(`\bi?pdb\.set_trace\(\)`),
+		},
+	},
+	{
+		Exts:     []string{".php"},
+		Comments: append(slashComments, "#"),
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`\b(var_dump|dd|dump)\(`),
+		},
+	},
+	{
+		Exts:     []string{".rs"},
+		Comments: slashComments,
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`\bdbg!\(`),
+		},
+	},
+	{
+		Exts:     []string{".ex", ".exs"},
+		Comments: hashComments,
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`\bIO\.inspect\b`),
+			regexp.MustCompile(`\bIEx\.pry\b`),
+		},
+	},
+	{
+		Exts:     []string{".java", ".kt"},
+		Comments: slashComments,
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`\bSystem\.out\.print(ln)?\(`),
+			regexp.MustCompile(`\.printStackTrace\(\)`),
+		},
+	},
+}
+
+// checkDebugStatement is the "debug" detector: it flags debug leftovers in
+// source files of the matching language, ignoring commented-out lines.
+func checkDebugStatement(p, line string) (string, int, bool) {
+	ext := strings.ToLower(path.Ext(p))
+	for _, lang := range debugLangs {
+		if !hasExt(lang.Exts, ext) {
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		for _, c := range lang.Comments {
+			if strings.HasPrefix(trimmed, c) {
+				return "", 0, false
+			}
+		}
+		for _, re := range lang.Patterns {
+			if loc := re.FindStringIndex(line); loc != nil {
+				match := strings.TrimSpace(line[loc[0]:loc[1]])
+				col := loc[0] + 1 + strings.Index(line[loc[0]:loc[1]], match)
+				return match, col, true
+			}
+		}
+		return "", 0, false
+	}
+	return "", 0, false
+}
+
+func hasExt(exts []string, ext string) bool {
+	for _, e := range exts {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// detector is a built-in rule that inspects added lines for problems plain
+// substring patterns can't express. Detectors are toggled and scoped under
+// [detect.NAME] in snag.toml.
+type detector struct {
+	Name      string
+	ID        string // stable check ID, see checks.go
+	DefaultOn bool
+	Summary   string   // noun phrase for messages, e.g. "debug statement"
+	Exclude   []string // built-in path globs, extended by [detect.NAME] exclude
+	// Check inspects one added line of the file at path. It returns the
+	// offending text and its 1-based byte column.
+	Check func(path, line string) (match string, col int, ok bool)
+	// CheckConfig is Check for detectors whose rules come from config.
+	CheckConfig func(bc *BlockConfig, path, line string) (match string, col int, ok bool)
+	// Configured, when set, turns the detector on without [detect.NAME]
+	// enabled, for detectors driven by settings elsewhere in the config.
+	Configured func(bc *BlockConfig) bool
+	// CheckPath, when set, inspects the path of each added or changed file
+	// instead. It runs before skip rules: build outputs are often binary.
+	CheckPath func(bc *BlockConfig, path string) bool
+}
+
+// detectors is the registry of built-in detectors, in reporting order.
+var detectors = []detector{
+	{
+		Name:    "debug",
+		ID:      idDebugStatement,
+		Summary: "debug statement",
+		Check:   checkDebugStatement,
+	},
+	{
+		Name:      "conflict",
+		ID:        idConflictMarker,
+		DefaultOn: true,
+		Summary:   "conflict marker",
+		Exclude:   []string{"*.patch", "*.diff", "*.rej"},
+		Check:     checkConflictMarker,
+	},
+	{
+		Name:      "unicode",
+		ID:        idSuspiciousUnicode,
+		DefaultOn: true,
+		Summary:   "suspicious unicode",
+		Exclude:   []string{"*.po", "*.pot", "*.xlf", "*.xliff", "*.arb"}, // translation catalogs carry real RTL text
+		Check:     checkSuspiciousUnicode,
+	},
+	{
+		Name:      "artifact",
+		ID:        idBuildArtifact,
+		Summary:   "build artifact",
+		CheckPath: checkArtifactPath,
+	},
+	{
+		Name:    "token",
+		ID:      idSecretToken,
+		Summary: "secret token",
+		Check:   checkSecretToken,
+	},
+	{
+		Name:    credFileDetector,
+		ID:      idCredentialFile,
+		Summary: "credential in config file",
+		Exclude: []string{"*.example", "*.sample", "*.template", "*.dist", "*.example.json", "*.sample.json"},
+		Check:   checkCredentialFile,
+	},
+	{
+		Name:        piiDetector,
+		ID:          idPersonalData,
+		Summary:     "personal data",
+		Exclude:     []string{".mailmap", "AUTHORS*", "CONTRIBUTORS*", "MAINTAINERS*", "CODEOWNERS", "go.sum", "*.lock"},
+		CheckConfig: checkPII,
+	},
+	{
+		// Also runs over commit messages; see checkMsgInclusive.
+		Name:        inclusiveDetector,
+		ID:          idInclusiveTerm,
+		Summary:     "term to avoid",
+		CheckConfig: checkInclusive,
+	},
+	{
+		// Enabled by [block] urls; see urlpolicy.go.
+		Name:        urlDetector,
+		ID:          idURLPolicy,
+		Summary:     "blocked URL",
+		Configured:  func(bc *BlockConfig) bool { return len(bc.URLs) > 0 },
+		CheckConfig: checkURLPolicy,
+	},
+	{
+		// Pattern-relative, so it runs from the diff and msg checks rather
+		// than runDetectors; see nearmiss.go.
+		Name:    nearMissDetector,
+		ID:      idNearMiss,
+		Summary: "near miss",
+	},
+}
+
+func findDetector(name string) *detector {
+	for i := range detectors {
+		if detectors[i].Name == name {
+			return &detectors[i]
+		}
+	}
+	return nil
+}
+
+func detectorNames() []string {
+	names := make([]string, len(detectors))
+	for i, d := range detectors {
+		names[i] = d.Name
+	}
+	return names
+}
+
+// enabledDetectors returns the detectors switched on for this config.
+func (bc *BlockConfig) enabledDetectors() []detector {
+	var out []detector
+	for _, d := range detectors {
+		on, set := bc.DetectEnabled[d.Name]
+		if !set {
+			on = d.DefaultOn || d.Configured != nil && d.Configured(bc)
+		}
+		if on {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// checksLines reports whether d inspects added lines.
+func (d *detector) checksLines() bool {
+	return d.Check != nil || d.CheckConfig != nil
+}
+
+// checkLine runs d's line check on one line of the file at path.
+func (d *detector) checkLine(bc *BlockConfig, path, line string) (string, int, bool) {
+	if d.CheckConfig != nil {
+		return d.CheckConfig(bc, path, line)
+	}
+	return d.Check(path, line)
+}
+
+// detectExcluded reports whether p matches one of the detector's built-in or
+// configured exclude globs.
+func (bc *BlockConfig) detectExcluded(d *detector, p string) bool {
+	return matchPathGlob(d.Exclude, p) || matchPathGlob(bc.DetectExclude[d.Name], p)
+}
+
+// matchPathGlob reports whether repo path p matches any glob, tried against
+// both the full path ("docs/*.md") and the base name ("*.md").
+func matchPathGlob(globs []string, p string) bool {
+	for _, g := range globs {
+		if ok, _ := path.Match(g, p); ok {
+			return true
+		}
+		if ok, _ := path.Match(g, path.Base(p)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// treeGlobRE compiles a glob where "**/" spans any number of directories
+// and * and ? stop at slashes.
+func treeGlobRE(glob string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString(`(?:.*/)?`)
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(`.*`)
+			i++
+		case glob[i] == '*':
+			b.WriteString(`[^/]*`)
+		case glob[i] == '?':
+			b.WriteString(`[^/]`)
+		default:
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	return regexp.Compile(`^` + b.String() + `$`)
+}
+
+// matchTreeGlob is matchPathGlob for settings that take "**" globs
+// ([require] paths). Globs without "**" match exactly as matchPathGlob does.
+func matchTreeGlob(globs []string, p string) bool {
+	for _, g := range globs {
+		if !strings.Contains(g, "**") {
+			if matchPathGlob([]string{g}, p) {
+				return true
+			}
+			continue
+		}
+		if re, err := treeGlobRE(g); err == nil && re.MatchString(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// detectHit is a detector finding inside a unified diff.
+type detectHit struct {
+	Detector *detector
+	Match    string
+	Path     string
+	Line     int
+	Col      int
+}
+
+// numberedLine is an added diff line with its post-image line number.
+type numberedLine struct {
+	Line int
+	Text string
+}
+
+// addedLines returns the added lines of a single file's diff, numbered from
+// the @@ hunk headers.
+func addedLines(body string) []numberedLine {
+	return scannedLines(body, scanAdded)
+}
+
+// runDetectors runs the enabled detectors over the added lines of diff and
+// returns the first finding. Files rules says to skip are not inspected.
+func runDetectors(bc *BlockConfig, diff string, rules skipRules) (detectHit, bool) {
+	defer traceSpan("match", "detectors")()
+	active := bc.enabledDetectors()
+	if len(active) == 0 {
+		return detectHit{}, false
+	}
+	for _, f := range splitDiffFiles(diff) {
+		if !strings.Contains(f.Body, "\ndeleted file mode ") {
+			for i := range active {
+				d := &active[i]
+				if d.CheckPath != nil && !bc.detectExcluded(d, f.Path) && d.CheckPath(bc, f.Path) {
+					return detectHit{Detector: d, Match: f.Path, Path: f.Path, Line: 1, Col: 1}, true
+				}
+			}
+		}
+		if rules.reason(f) != "" {
+			continue
+		}
+		lines := addedLines(f.Body)
+		for i := range active {
+			d := &active[i]
+			if !d.checksLines() || bc.detectExcluded(d, f.Path) {
+				continue
+			}
+			for _, l := range lines {
+				if match, col, ok := d.checkLine(bc, f.Path, l.Text); ok {
+					return detectHit{Detector: d, Match: match, Path: f.Path, Line: l.Line, Col: col}, true
+				}
+			}
+		}
+	}
+	return detectHit{}, false
+}
+
+// reportDetectHit prints a detector finding and returns the violation error.
+// where describes the scanned diff, e.g. "staged diff" or "diff of abc1234".
+func reportDetectHit(cmd *cobra.Command, hit detectHit, where string) error {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	if !quiet {
+		if problemOutput(cmd) {
+			problemf(hit.Detector.ID, hit.Path, hit.Line, hit.Col, "%s %q in %s", hit.Detector.Summary, hit.Match, where)
+		} else {
+			blockf(hit.Detector.ID, "%s %q in %s", hit.Detector.Summary, hit.Match, where)
+			hintf("at %s:%d", hit.Path, hit.Line)
+			hintf("to turn this check off: [detect.%s] enabled = false", hit.Detector.Name)
+			bell()
+		}
+	}
+	return violationf(hit.Detector.ID, "%s %q found in %s", hit.Detector.Summary, hit.Match, where)
+}
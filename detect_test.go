@@ -0,0 +1,196 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckDebugStatement(t *testing.T) {
+	tests := []struct {
+		path, line string
+		want       string
+		col        int
+	}{
+		{"main.go", `	fmt.Println("here")`, "fmt.Println(", 2},
+		{"main.go", `	// fmt.Println("here")`, "", 0},
+		{"app.ts", `  console.log(x)`, "console.log(", 3},
+		{"app.js", `  debugger;`, "debugger;", 3},
+		{"app.js", `  const debuggerEnabled = true`, "", 0},
+		{"user.rb", `    binding.pry`, "binding.pry", 5},
+		{"user.rb", `  # binding.pry`, "", 0},
+		{"tool.py", `breakpoint()`, "breakpoint()", 1},
+		{"lib.rs", `let x = dbg!(y);`, "dbg!(", 9},
+		{"README.md", `call console.log(x) to debug`, "", 0},
+	}
+	for _, tc := range tests {
+		match, col, ok := checkDebugStatement(tc.path, tc.line)
+		if ok != (tc.want != "") || match != tc.want || col != tc.col {
+			t.Errorf("checkDebugStatement(%q, %q) = (%q, %d, %v), want (%q, %d)",
+				tc.path, tc.line, match, col, ok, tc.want, tc.col)
+		}
+	}
+}
+
+func TestAddedLines(t *testing.T) {
+	body := "diff --git a/x b/x\n--- a/x\n+++ b/x\n@@ -3,2 +3,3 @@\n keep\n-old\n+new\n+newer\n"
+	got := addedLines(body)
+	if len(got) != 2 || got[0] != (numberedLine{4, "new"}) || got[1] != (numberedLine{5, "newer"}) {
+		t.Errorf("addedLines = %+v", got)
+	}
+}
+
+func TestRunDetectors_OnlyAddedLines(t *testing.T) {
+	bc := &BlockConfig{DetectEnabled: map[string]bool{"debug": true}}
+	diff := "diff --git a/app.js b/app.js\n--- a/app.js\n+++ b/app.js\n@@ -1,2 +1,2 @@\n console.log(a)\n-console.log(b)\n+log(b)\n"
+	if hit, ok := runDetectors(bc, diff, skipRules{}); ok {
+		t.Errorf("context/removed lines flagged: %+v", hit)
+	}
+
+	diff = strings.Replace(diff, "+log(b)", "+console.log(b)", 1)
+	hit, ok := runDetectors(bc, diff, skipRules{})
+	if !ok || hit.Path != "app.js" || hit.Line != 2 || hit.Match != "console.log(" {
+		t.Errorf("runDetectors = (%+v, %v)", hit, ok)
+	}
+
+	bc.DetectExclude = map[string][]string{"debug": {"*.js"}}
+	if _, ok := runDetectors(bc, diff, skipRules{}); ok {
+		t.Error("excluded path should not be inspected")
+	}
+}
+
+func TestRunDiff_DebugDetector(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	run := func() error {
+		rootCmd := buildRootCmd()
+		rootCmd.SetArgs([]string{"check", "diff", "-q"})
+		return rootCmd.Execute()
+	}
+
+	stageFile(t, dir, "main.go", "package main\n\nfunc main() { fmt.Println(1) }\n")
+	if err := run(); err != nil {
+		t.Fatalf("debug detector should be off by default, got %v", err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[detect.debug]\nenabled = true\n"), 0644)
+	err := run()
+	if err == nil || !strings.Contains(err.Error(), "debug statement") {
+		t.Fatalf("expected debug statement violation, got %v", err)
+	}
+	if id := checkIDOf(err); id != idDebugStatement {
+		t.Errorf("violation ID = %q, want %s", id, idDebugStatement)
+	}
+}
+
+func TestLoadSnagTOML_UnknownDetector(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snag.toml")
+	os.WriteFile(path, []byte("[detect.nope]\nenabled = true\n"), 0644)
+	if _, err := loadSnagTOML(path); err == nil || !strings.Contains(err.Error(), "unknown detector") {
+		t.Errorf("expected unknown detector error, got %v", err)
+	}
+}
+
+func TestCheckConflictMarker(t *testing.T) {
+	tests := []struct {
+		path, line string
+		want       string
+	}{
+		{"main.go", "<<<<<<< HEAD", "<<<<<<<"},
+		{"main.go", ">>>>>>> feature/x", ">>>>>>>"},
+		{"main.go", "||||||| merged common ancestors", "|||||||"},
+		{"main.go", "=======", "======="},
+		{"main.go", "=======\r", "======="},
+		{"README.md", "=======", ""},
+		{"README.md", "<<<<<<< HEAD", "<<<<<<<"},
+		{"main.go", `	s := "<<<<<<< HEAD"`, ""},
+		{"main.go", "<<<<<<<<", ""},
+		{"main.go", "========", ""},
+	}
+	for _, tc := range tests {
+		match, _, ok := checkConflictMarker(tc.path, tc.line)
+		if ok != (tc.want != "") || match != tc.want {
+			t.Errorf("checkConflictMarker(%q, %q) = (%q, %v), want %q", tc.path, tc.line, match, ok, tc.want)
+		}
+	}
+}
+
+func TestRunDiff_ConflictMarkersDefaultOn(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	run := func() error {
+		rootCmd := buildRootCmd()
+		rootCmd.SetArgs([]string{"check", "diff", "-q"})
+		return rootCmd.Execute()
+	}
+
+	stageFile(t, dir, "fix.patch", "<<<<<<< ours\n")
+	if err := run(); err != nil {
+		t.Fatalf("*.patch is excluded by default, got %v", err)
+	}
+
+	stageFile(t, dir, "main.go", "package main\n<<<<<<< HEAD\nvar x = 1\n=======\nvar x = 2\n>>>>>>> topic\n")
+	err := run()
+	if err == nil || !strings.Contains(err.Error(), "conflict marker") {
+		t.Fatalf("expected conflict marker violation with no config, got %v", err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[detect.conflict]\nexclude = [\"main.go\"]\n"), 0644)
+	if err := run(); err != nil {
+		t.Errorf("configured exclude ignored: %v", err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[detect.conflict]\nenabled = false\n"), 0644)
+	if err := run(); err != nil {
+		t.Errorf("disabled detector still ran: %v", err)
+	}
+}
+
+func TestCheckSuspiciousUnicode(t *testing.T) {
+	tests := []struct {
+		name, line string
+		want       string
+		col        int
+	}{
+		{"ascii", `if isAdmin { return }`, "", 0},
+		{"trojan source override", "/* \u202e } if (isAdmin) \u2066 begin */", "U+202E RIGHT-TO-LEFT OVERRIDE", 4},
+		{"isolate", "x := \u2067y", "U+2067 RIGHT-TO-LEFT ISOLATE", 6},
+		{"zero width space", "pass\u200bword", "U+200B ZERO WIDTH SPACE", 5},
+		{"cyrillic a in latin word", "if p\u0430ypal {", "p\u0430ypal (Latin mixed with Cyrillic)", 4},
+		{"greek omicron", "g\u03bfogle.com", "g\u03bfogle (Latin mixed with Greek)", 1},
+		{"pure cyrillic text", "// Привет world", "", 0},
+		{"accented latin", "café naïve", "", 0},
+		{"hebrew with rlm", "shalom שלום\u200f!", "", 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			match, col, ok := checkSuspiciousUnicode("x.go", tc.line)
+			if ok != (tc.want != "") || match != tc.want || col != tc.col {
+				t.Errorf("got (%q, %d, %v), want (%q, %d)", match, col, ok, tc.want, tc.col)
+			}
+		})
+	}
+}
+
+func TestRunDetectors_UnicodeTranslationsExcluded(t *testing.T) {
+	bc := &BlockConfig{}
+	diff := "diff --git a/ar.po b/ar.po\n--- a/ar.po\n+++ b/ar.po\n@@ -0,0 +1 @@\n+msgstr \"\u202bمرحبا\u202c\"\n"
+	if hit, ok := runDetectors(bc, diff, skipRules{}); ok {
+		t.Errorf("translation catalog flagged: %+v", hit)
+	}
+	diff = strings.ReplaceAll(diff, "ar.po", "main.go")
+	if hit, ok := runDetectors(bc, diff, skipRules{}); !ok || hit.Detector.Name != "unicode" {
+		t.Errorf("bidi control in source not flagged: (%+v, %v)", hit, ok)
+	}
+}
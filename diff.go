@@ -2,7 +2,6 @@ package main
 
 import (
 	"fmt"
-	"os/exec"
 
 	"github.com/spf13/cobra"
 )
@@ -12,24 +11,77 @@ func runDiff(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	if len(bc.Diff) == 0 {
+	if err := checkProtectedCommit(cmd, bc); err != nil {
+		return err
+	}
+	if len(bc.Diff) == 0 && !bc.BlockWhitespaceOnly && len(bc.Ecosystems) == 0 &&
+		len(bc.enabledDetectors()) == 0 && len(bc.Executable) == 0 && len(bc.RequireExecutable) == 0 && !bc.licenseOn() && !bc.changelogOn() {
 		return nil
 	}
 
-	out, err := exec.Command("git", "diff", "--staged").CombinedOutput()
+	out, err := gitCommand("diff", "--staged").CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("git diff --staged: %w\n%s", err, out)
 	}
+	if bc.BlockWhitespaceOnly && isWhitespaceOnlyDiff(string(out)) {
+		return shapeViolation(cmd, idWhitespaceOnly, "staged changes are whitespace-only",
+			"stage a real change with it, or drop it: git restore --staged .")
+	}
+	if err := checkLockfiles(cmd, bc); err != nil {
+		return err
+	}
+	if err := checkFileModes(cmd, bc); err != nil {
+		return err
+	}
+	if bc.licenseOn() {
+		if err := checkLicenseHeaders(cmd, bc, false); err != nil {
+			return err
+		}
+	}
+	if bc.changelogOn() {
+		changed, err := stagedPaths()
+		if err != nil {
+			return err
+		}
+		if err := checkChangelog(cmd, bc, changed, "staged changes"); err != nil {
+			return err
+		}
+	}
 
-	pattern, found := matchesPattern(stripDiffNoise(stripDiffMeta(string(out))), bc.Diff)
+	rules := bc.skipRulesFor("diff")
+	block, warn := bc.splitRollout(dropSnoozed(cmd, "diff", bc.Diff))
+	hit, skipped, found := matchDiff(string(out), block, rules)
+	reportSkipped(cmd, skipped)
 	if !found {
+		n := rolloutWarnings(cmd, bc, warn, "staged diff", func(p string) int {
+			return countDiffLines(string(out), p, rules)
+		})
+		if err := checkWarningBudget(cmd, bc, n, "staged diff"); err != nil {
+			return err
+		}
+		if dh, ok := runDetectors(bc, string(out), rules); ok {
+			return reportDetectHit(cmd, dh, "staged diff")
+		}
+		if bc.nearMissOn() {
+			warnNearMisses(cmd, bc, diffNearMisses(bc, string(out), bc.Diff, rules), "staged diff")
+		}
 		return nil
 	}
 
 	quiet, _ := cmd.Flags().GetBool("quiet")
 	if !quiet {
-		errorf("match %q in staged diff", pattern)
-		bell()
+		if problemOutput(cmd) {
+			problemf(idDiffPattern, hit.Path, hit.Line, hit.Col, "match %q in staged diff", bc.display(hit.Pattern))
+		} else {
+			blockf(idDiffPattern, "match %q in staged diff", bc.display(hit.Pattern))
+			if hit.Path != "" {
+				hintf("in %s", hit.Path)
+			}
+			bell()
+			explainViolation(cmd, bc, explanation{
+				Phase: "diff", Pattern: hit.Pattern, Path: hit.Path, Line: hit.Line, Diff: string(out),
+			})
+		}
 	}
-	return fmt.Errorf("policy violation: %q found in staged diff", pattern)
+	return violationf(idDiffPattern, "%q found in staged diff", bc.display(hit.Pattern))
 }
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"encoding/json"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -204,3 +206,168 @@ func TestRunDiff_MatchFound(t *testing.T) {
 		t.Errorf("stderr should contain match message, got: %q", stderr)
 	}
 }
+
+func TestRunDiff_UnicodeFilenameNotMatched(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[block]\ndiff = [\"todo\"]\n"), 0644)
+
+	// core.quotepath (on by default) quotes this name in the diff headers.
+	stageFile(t, dir, "todo-café.txt", "nothing to see\n")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"check", "diff"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("quoted filename should not trigger a violation, got: %v", err)
+	}
+}
+
+func TestRunDiff_Latin1Content(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[block]\ndiff = [\"hack\"]\n"), 0644)
+
+	stageFile(t, dir, "legacy.txt", "caf\xe9 HACK\n")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	oldStderr := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"check", "diff"})
+	err := rootCmd.Execute()
+
+	w.Close()
+	os.Stderr = oldStderr
+
+	if err == nil {
+		t.Fatal("expected violation in latin-1 file")
+	}
+	buf := make([]byte, 1024)
+	n, _ := r.Read(buf)
+	if stderr := string(buf[:n]); !strings.Contains(stderr, "legacy.txt") {
+		t.Errorf("stderr should name the file, got: %q", stderr)
+	}
+}
+
+func TestRunDiff_VSCodeFormat(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[block]\ndiff = [\"todo\"]\n"), 0644)
+	stageFile(t, dir, "code.go", "package x\n\nfunc f() {} // TODO\n")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"check", "diff", "--format", "vscode"})
+	err := rootCmd.Execute()
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	if err == nil {
+		t.Fatal("expected violation")
+	}
+	buf := make([]byte, 1024)
+	n, _ := r.Read(buf)
+	want := "code.go:3:16: error: match \"todo\" in staged diff [SNAG001]\n"
+	if got := string(buf[:n]); got != want {
+		t.Errorf("stdout = %q, want %q", got, want)
+	}
+}
+
+func TestRunDiff_VSCodeFormatNonASCIIColumn(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[block]\ndiff = [\"todo\"]\n"), 0644)
+	// Ⱥ grows by a byte when lowercased; the column counts original bytes.
+	stageFile(t, dir, "code.go", "package x\n\n// ȺȺȺ TODO\n")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"check", "diff", "--format", "vscode"})
+	err := rootCmd.Execute()
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	if err == nil {
+		t.Fatal("expected violation")
+	}
+	buf := make([]byte, 1024)
+	n, _ := r.Read(buf)
+	want := "code.go:3:11: error: match \"todo\" in staged diff [SNAG001]\n"
+	if got := string(buf[:n]); got != want {
+		t.Errorf("stdout = %q, want %q", got, want)
+	}
+}
+
+func TestRunDiff_JSONFormat(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[block]\ndiff = [\"todo\"]\n"), 0644)
+	stageFile(t, dir, "code.go", "package x\n\nfunc f() {} // TODO\n")
+	stageFile(t, dir, "other.go", "package x\n")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+	defer func(f string) { problemFormat = f }(problemFormat)
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"check", "diff", "--format", "json"})
+	err := rootCmd.Execute()
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	if err == nil {
+		t.Fatal("expected violation")
+	}
+	out, _ := io.ReadAll(r)
+	var p jsonProblem
+	if err := json.Unmarshal(out, &p); err != nil {
+		t.Fatalf("stdout is not one JSON object: %v\n%s", err, out)
+	}
+	if p.File != "code.go" || p.Line != 3 || p.Check != idDiffPattern || p.Severity != "error" {
+		t.Errorf("problem = %+v", p)
+	}
+	if p.Env.Branch == "" || p.Env.StagedFiles != 2 || len(p.Env.ConfigHash) != 12 || p.Env.Upstream != "" {
+		t.Errorf("env = %+v, want the branch, 2 staged files, a config hash and no upstream", p.Env)
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// digestFormats maps each --format value to its renderer.
+var digestFormats = map[string]func(repos []repoReport) any{
+	"slack": slackDigest,
+	"teams": teamsDigest,
+}
+
+// digestMaxPatterns caps the most-hit patterns listed in a digest.
+const digestMaxPatterns = 5
+
+func buildDigestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "digest [REPO...]",
+		Short: "Summarize the latest audit as a Slack or Teams message payload",
+		Long: `Audit each repository (default: the current one) and print a chat message
+payload summarizing policy health, for a cron job to post:
+
+  slack  Block Kit JSON for chat.postMessage or an incoming webhook
+  teams  an Adaptive Card message for a Teams incoming webhook
+
+Sensitive patterns are redacted.`,
+		Example: `  snag report digest --format slack ~/src/api ~/src/web |
+    curl -sf -X POST -H 'Content-Type: application/json' -d @- "$SLACK_WEBHOOK_URL"`,
+		SilenceUsage: true,
+		RunE:         runDigest,
+	}
+	cmd.Flags().String("format", "slack", "payload format: slack or teams")
+	cmd.Flags().StringP("output", "o", "", "write to FILE instead of stdout")
+	cmd.Flags().Int("limit", -1, "max commits to scan per repo (default: config or 10, 0 = unlimited)")
+	return cmd
+}
+
+func runDigest(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	render, ok := digestFormats[format]
+	if !ok {
+		return fmt.Errorf("--format must be slack or teams, got %q", format)
+	}
+	limit, _ := cmd.Flags().GetInt("limit")
+	repos, err := collectRepoReports(cmd, args, limit)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(render(repos), "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if out, _ := cmd.Flags().GetString("output"); out != "" {
+		if err := os.WriteFile(out, data, 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", out, err)
+		}
+		return nil
+	}
+	_, err = cmd.OutOrStdout().Write(data)
+	return err
+}
+
+// digestHeadline is the one-line verdict shared by every format.
+func digestHeadline(t reportTotals) string {
+	if t.Violating == 0 && t.Unprotected == 0 {
+		return fmt.Sprintf("snag: all clear across %d repo(s)", t.Repos)
+	}
+	return fmt.Sprintf("snag: %d violating commit(s), %d repo(s) without hooks", t.Violating, t.Unprotected)
+}
+
+// digestRepoLine summarizes one repository. bold is the format's emphasis
+// marker: * for Slack mrkdwn, ** for Teams markdown.
+func digestRepoLine(r repoReport, bold string) string {
+	switch {
+	case r.Error != "":
+		return fmt.Sprintf("%s%s%s: could not audit (%s)", bold, r.Name, bold, r.Error)
+	case !r.Hooks:
+		return fmt.Sprintf("%s%s%s: %d of %d commits violating, hooks not installed", bold, r.Name, bold, len(r.Commits), r.Scanned)
+	default:
+		return fmt.Sprintf("%s%s%s: %d of %d commits violating", bold, r.Name, bold, len(r.Commits), r.Scanned)
+	}
+}
+
+func digestPatternLine(t reportTotals, code string) string {
+	var parts []string
+	for i, p := range t.Patterns {
+		if i == digestMaxPatterns {
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%s%s%s ×%d", code, p.Pattern, code, p.Count))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func slackDigest(repos []repoReport) any {
+	t := sumReports(repos)
+	text := func(s string) map[string]any { return map[string]any{"type": "mrkdwn", "text": s} }
+	blocks := []map[string]any{
+		{"type": "header", "text": map[string]any{"type": "plain_text", "text": digestHeadline(t)}},
+		{"type": "section", "fields": []map[string]any{
+			text(fmt.Sprintf("*Repositories*\n%d", t.Repos)),
+			text(fmt.Sprintf("*Commits scanned*\n%d", t.Scanned)),
+			text(fmt.Sprintf("*Violating commits*\n%d", t.Violating)),
+			text(fmt.Sprintf("*Without hooks*\n%d", t.Unprotected)),
+		}},
+	}
+	var lines []string
+	for _, r := range repos {
+		lines = append(lines, "• "+digestRepoLine(r, "*"))
+	}
+	blocks = append(blocks, map[string]any{"type": "section", "text": text(strings.Join(lines, "\n"))})
+	if p := digestPatternLine(t, "`"); p != "" {
+		blocks = append(blocks, map[string]any{"type": "section", "text": text("*Most-hit patterns:* " + p)})
+	}
+	blocks = append(blocks, map[string]any{"type": "context", "elements": []map[string]any{
+		text(fmt.Sprintf("snag %s · run `snag audit` in a repo for details", Version)),
+	}})
+	// text is the notification fallback for clients that can't render blocks.
+	return map[string]any{"text": digestHeadline(t), "blocks": blocks}
+}
+
+func teamsDigest(repos []repoReport) any {
+	t := sumReports(repos)
+	block := func(s string) map[string]any { return map[string]any{"type": "TextBlock", "text": s, "wrap": true} }
+	fact := func(title string, n int) map[string]any {
+		return map[string]any{"title": title, "value": fmt.Sprint(n)}
+	}
+
+	body := []map[string]any{
+		{"type": "TextBlock", "text": digestHeadline(t), "size": "Large", "weight": "Bolder", "wrap": true},
+		{"type": "FactSet", "facts": []map[string]any{
+			fact("Repositories", t.Repos), fact("Commits scanned", t.Scanned),
+			fact("Violating commits", t.Violating), fact("Without hooks", t.Unprotected),
+		}},
+	}
+	var lines []string
+	for _, r := range repos {
+		lines = append(lines, "- "+digestRepoLine(r, "**"))
+	}
+	body = append(body, block(strings.Join(lines, "\n")))
+	if p := digestPatternLine(t, "`"); p != "" {
+		body = append(body, block("**Most-hit patterns:** "+p))
+	}
+	body = append(body, map[string]any{"type": "TextBlock", "text": "snag " + Version, "isSubtle": true, "size": "Small"})
+
+	return map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": map[string]any{
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"body":    body,
+			},
+		}},
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReportDigest(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag-local.toml"), []byte("[block]\nsensitive = true\ndiff = [\"projectx\"]\n"), 0644)
+	initialCommit(t, dir)
+	commitFile(t, dir, "a.go", "// projectx launch\n", "Add a")
+
+	for _, format := range []string{"slack", "teams"} {
+		t.Run(format, func(t *testing.T) {
+			var out bytes.Buffer
+			rootCmd := buildRootCmd()
+			rootCmd.SetOut(&out)
+			rootCmd.SetArgs([]string{"report", "digest", "--format", format, dir, "-q"})
+			if err := rootCmd.Execute(); err != nil {
+				t.Fatalf("report digest: %v", err)
+			}
+			var payload map[string]any
+			if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+				t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+			}
+			s := out.String()
+			if strings.Contains(s, "projectx") {
+				t.Error("sensitive pattern leaked into the digest")
+			}
+			for _, want := range []string{"1 violating commit(s)", "pr****tx", filepath.Base(dir)} {
+				if !strings.Contains(s, want) {
+					t.Errorf("digest missing %q:\n%s", want, s)
+				}
+			}
+			switch format {
+			case "slack":
+				if _, ok := payload["blocks"].([]any); !ok {
+					t.Error("slack payload has no blocks")
+				}
+			case "teams":
+				if payload["type"] != "message" || !strings.Contains(s, "AdaptiveCard") {
+					t.Error("teams payload is not an Adaptive Card message")
+				}
+			}
+		})
+	}
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"report", "digest", "--format", "irc", dir, "-q"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("unknown --format should fail")
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+func buildDoctorCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Check this repository's snag setup and report network use",
+		Long: `Check the snag setup for the current directory: which config files are
+found and whether they parse, whether hooks are installed, and whether the
+network is allowed. Lists every feature that can touch the network and what
+it does when the network is off (SNAG_OFFLINE=1 or [behavior] network =
+false). Hooks themselves never use the network.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runDoctor,
+	}
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	problems := 0
+	fmt.Fprintf(out, "snag version %s\n", Version)
+
+	var files []string
+	for _, dir := range configChain(cwd) {
+		for _, name := range append([]string{"snag.toml"}, localConfigNames...) {
+			if path := filepath.Join(dir, name); fileExists(path) {
+				files = append(files, path)
+			}
+		}
+	}
+	if path := worktreeConfigPath(cwd); path != "" && fileExists(path) {
+		files = append(files, path)
+	}
+	bc, _, cfgErr := walkConfig(cwd)
+	if hint := updateHint(bc); hint != "" {
+		fmt.Fprintln(out, hint)
+	}
+	fmt.Fprintln(out)
+	switch {
+	case cfgErr != nil:
+		problems++
+		fmt.Fprintf(out, "config:  error: %v\n", cfgErr)
+	case len(files) == 0:
+		fmt.Fprintln(out, "config:  none found (run: snag init)")
+	default:
+		fmt.Fprintf(out, "config:  %d file(s)\n", len(files))
+		for _, f := range files {
+			fmt.Fprintf(out, "         %s\n", f)
+		}
+	}
+
+	if snagHooksInstalled() {
+		fmt.Fprintln(out, "hooks:   installed")
+	} else {
+		if len(files) > 0 {
+			problems++
+		}
+		fmt.Fprintln(out, "hooks:   not installed (run: snag install && lefthook install)")
+	}
+
+	allowed, why := networkAllowed(bc)
+	if allowed {
+		fmt.Fprintln(out, "network: allowed (set SNAG_OFFLINE=1 to turn it off)")
+	} else {
+		fprintPlain(out, "network: off — %s\n", why)
+	}
+	fmt.Fprintln(out, "\nfeatures that can use the network (never run inside hooks):")
+	tw := tabwriter.NewWriter(out, 2, 4, 2, ' ', 0)
+	for _, f := range networkFeatures {
+		state := f.Uses
+		if !allowed {
+			state = "off: " + f.Offline
+		}
+		fmt.Fprintf(tw, "  %s\t%s\n", f.Name, state)
+	}
+	tw.Flush()
+
+	if problems > 0 {
+		return fmt.Errorf("%d problem(s) found", problems)
+	}
+	return nil
+}
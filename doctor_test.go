@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runDoctorIn(t *testing.T, dir string) (string, error) {
+	t.Helper()
+	orig, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(orig)
+	var buf bytes.Buffer
+	rootCmd := buildRootCmd()
+	rootCmd.SetOut(&buf)
+	rootCmd.SetArgs([]string{"doctor", "-q"})
+	err := rootCmd.Execute()
+	return buf.String(), err
+}
+
+func TestDoctor_NetworkSwitch(t *testing.T) {
+	tests := []struct {
+		name    string
+		toml    string
+		offline bool
+		want    string
+	}{
+		{"allowed by default", "", false, "network: allowed"},
+		{"env kill switch", "", true, "network: off — SNAG_OFFLINE=1"},
+		{"config kill switch", "[behavior]\nnetwork = false\n", false, "[behavior] network = false in"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initGitRepo(t)
+			os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"todo\"]\n"+tt.toml), 0644)
+			os.WriteFile(filepath.Join(dir, "lefthook.yml"), []byte("pre-commit:\n  commands:\n    snag:\n      run: snag check diff\n"), 0644)
+			if tt.offline {
+				t.Setenv("SNAG_OFFLINE", "1")
+			}
+			out, err := runDoctorIn(t, dir)
+			if err != nil {
+				t.Fatalf("doctor: %v\n%s", err, out)
+			}
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("missing %q:\n%s", tt.want, out)
+			}
+			if !strings.Contains(out, "audit --remote") {
+				t.Errorf("network features not listed:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestDoctor_ReportsMissingHooks(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"todo\"]\n"), 0644)
+	out, err := runDoctorIn(t, dir)
+	if err == nil || !strings.Contains(out, "hooks:   not installed") {
+		t.Errorf("expected a hooks problem, got %v:\n%s", err, out)
+	}
+}
+
+func TestAudit_RemoteOfflineSkipsFetch(t *testing.T) {
+	dir := initGitRepo(t)
+	commitFile(t, dir, "a.txt", "clean\n", "init")
+	bare := initBareRemote(t, dir)
+	// A branch only the remote has: auditing it needs a fetch.
+	if out, err := exec.Command("git", "-C", bare, "branch", "feature", "HEAD").CombinedOutput(); err != nil {
+		t.Fatalf("git branch: %v\n%s", err, out)
+	}
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"todo\"]\n"), 0644)
+
+	orig, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(orig)
+	audit := func() error {
+		rootCmd := buildRootCmd()
+		rootCmd.SetArgs([]string{"audit", "--remote", "origin/feature", "-q"})
+		return rootCmd.Execute()
+	}
+
+	t.Setenv("SNAG_OFFLINE", "1")
+	if err := audit(); err == nil || !strings.Contains(err.Error(), "no remote-tracking ref") {
+		t.Errorf("offline: expected the fetch to be skipped, got %v", err)
+	}
+	os.Unsetenv("SNAG_OFFLINE")
+	if err := audit(); err != nil {
+		t.Errorf("online: %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// eventsFile logs each check that blocked on a configured pattern, one
+// hookEvent per line, for snag stats --patterns.
+const eventsFile = "events.jsonl"
+
+// hookEvent is one blocked check. Pattern is as displayed, so sensitive
+// patterns are stored redacted.
+type hookEvent struct {
+	Time    time.Time `json:"time"`
+	Hook    string    `json:"hook"`
+	Pattern string    `json:"pattern"`
+}
+
+// recordHookEvent logs a policy violation from hook when its message names
+// a configured pattern. Like recordHookError it never fails the hook.
+func recordHookEvent(dir, hook string, err error) {
+	msg := err.Error()
+	if !strings.HasPrefix(msg, "policy violation:") {
+		return
+	}
+	cwd, werr := os.Getwd()
+	if werr != nil {
+		return
+	}
+	bc, rerr := resolveBlockConfigAt(nil, cwd)
+	if rerr != nil {
+		return
+	}
+	configured := map[string]bool{}
+	for _, p := range configuredPatterns(bc) {
+		configured[p] = true
+	}
+	for _, q := range quotedText.FindAllString(msg, -1) {
+		shown, uerr := strconv.Unquote(q)
+		if uerr != nil || !configured[shown] {
+			continue
+		}
+		line, _ := json.Marshal(hookEvent{Time: time.Now().UTC(), Hook: hook, Pattern: shown})
+		appendStateLine(dir, eventsFile, line)
+		return
+	}
+}
+
+// configuredPatterns lists bc's diff, msg, and push patterns as displayed,
+// without duplicates.
+func configuredPatterns(bc *BlockConfig) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, list := range [][]string{bc.Diff, bc.Msg, bc.PushPatterns()} {
+		for _, p := range list {
+			shown := bc.display(p)
+			if !seen[shown] {
+				seen[shown] = true
+				out = append(out, shown)
+			}
+		}
+	}
+	return out
+}
+
+func loadHookEvents() ([]hookEvent, error) {
+	dir, err := snagStateDir()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filepath.Join(dir, eventsFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var events []hookEvent
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var ev hookEvent
+		if json.Unmarshal(scanner.Bytes(), &ev) == nil {
+			events = append(events, ev)
+		}
+	}
+	return events, scanner.Err()
+}
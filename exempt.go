@@ -0,0 +1,47 @@
+package main
+
+import (
+	"path"
+	"strings"
+)
+
+// exemptSection lists commit identities whose messages and diffs are not
+// pattern-checked during push and audit — typically dependency bots whose
+// generated changelogs quote whatever upstream wrote.
+type exemptSection struct {
+	Authors []string `toml:"authors"` // author names or emails; globs allowed ("renovate*")
+}
+
+// literalBrackets escapes [ and ] so bot names like "dependabot[bot]" aren't
+// read as character classes; * and ? stay wildcards.
+var literalBrackets = strings.NewReplacer("[", `\[`, "]", `\]`)
+
+// exemptAuthor reports whether a commit by name <email> is exempt. Entries
+// match the name or the email case-insensitively.
+func (bc *BlockConfig) exemptAuthor(name, email string) bool {
+	for _, pat := range bc.ExemptAuthors {
+		pat = literalBrackets.Replace(strings.ToLower(pat))
+		for _, id := range []string{name, email} {
+			if id == "" {
+				continue
+			}
+			if ok, _ := path.Match(pat, strings.ToLower(id)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ignoreAuthorExemptions drops [exempt] authors for checks run on the
+// receiving side (server-hook, webhook serve). There the pusher wrote the
+// author field, and anyone can commit as "dependabot[bot]".
+func (bc *BlockConfig) ignoreAuthorExemptions() {
+	bc.ExemptAuthors = nil
+}
+
+// parseAuthorIdent splits "Name <email>" as printed by %an <%ae>.
+func parseAuthorIdent(s string) (name, email string) {
+	name, email, _ = strings.Cut(s, " <")
+	return strings.TrimSpace(name), strings.TrimSuffix(email, ">")
+}
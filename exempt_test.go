@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestExemptAuthor(t *testing.T) {
+	bc := &BlockConfig{ExemptAuthors: []string{"dependabot[bot]", "renovate*", "ci@example.com"}}
+	for _, tc := range []struct {
+		name, email string
+		want        bool
+	}{
+		{"dependabot[bot]", "49699333+dependabot[bot]@users.noreply.github.com", true},
+		{"Dependabot[bot]", "", true},
+		{"dependabotb", "", false}, // [bot] is literal, not a character class
+		{"renovate[bot]", "bot@renovateapp.com", true},
+		{"Build Bot", "CI@example.com", true},
+		{"Jane Dev", "jane@example.com", false},
+	} {
+		if got := bc.exemptAuthor(tc.name, tc.email); got != tc.want {
+			t.Errorf("exemptAuthor(%q, %q) = %v, want %v", tc.name, tc.email, got, tc.want)
+		}
+	}
+	if name, email := parseAuthorIdent("renovate[bot] <bot@renovateapp.com>"); name != "renovate[bot]" || email != "bot@renovateapp.com" {
+		t.Errorf("parseAuthorIdent = %q, %q", name, email)
+	}
+}
+
+// commitAs commits name with content under the given author identity.
+func commitAs(t *testing.T, dir, author, name, content, message string) {
+	t.Helper()
+	os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	for _, args := range [][]string{{"add", name}, {"commit", "-q", "--author", author, "-m", message}} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+}
+
+func TestExemptAuthorsPushAndAudit(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[block]\ndiff = [\"todo\"]\nmsg = [\"todo\"]\n\n[exempt]\nauthors = [\"dependabot[bot]\"]\n"), 0644)
+	commitAs(t, dir, "dependabot[bot] <support@github.com>", "CHANGELOG.md", "- TODO upstream\n", "Bump x: fixes TODO")
+
+	orig, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(orig)
+
+	for _, args := range [][]string{{"check", "push"}, {"audit", "-q"}} {
+		root := buildRootCmd()
+		root.SetArgs(args)
+		if err := root.Execute(); err != nil {
+			t.Errorf("%v: bot commit not exempt: %v", args, err)
+		}
+	}
+
+	commitAs(t, dir, "Jane Dev <jane@example.com>", "b.txt", "TODO\n", "wip")
+	for _, args := range [][]string{{"check", "push"}, {"audit", "-q"}} {
+		root := buildRootCmd()
+		root.SetArgs(args)
+		if err := root.Execute(); err == nil {
+			t.Errorf("%v: human commit passed, want violation", args)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// explanation describes a pattern violation in enough detail for --explain
+// to show where it is, where the rule came from, and how to get unstuck.
+type explanation struct {
+	Phase   string // "diff", "msg", or "push"
+	Pattern string // lowercased pattern as matched
+	Path    string // file within the diff; "" for message matches
+	Line    int    // post-image line of the match; 0 if unknown
+	Diff    string // the diff that was scanned; "" for message matches
+	SHA     string // push: the offending commit
+}
+
+// explainRequested reports whether --explain was passed.
+func explainRequested(cmd *cobra.Command) bool {
+	explain, _ := cmd.Flags().GetBool("explain")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	return explain && !quiet && outputFormat(cmd) == formatText
+}
+
+// explainViolation prints the hunk containing the match, the config files
+// that contributed the pattern, and copy-pasteable commands to fix it.
+func explainViolation(cmd *cobra.Command, bc *BlockConfig, e explanation) {
+	if !explainRequested(cmd) {
+		return
+	}
+	w := os.Stderr
+
+	if hunk := explainHunk(e.Diff, e.Path, e.Line); hunk != "" {
+		fmt.Fprintln(w, hintStyle.Render("  --- hunk ---"))
+		for _, l := range strings.Split(strings.TrimRight(hunk, "\n"), "\n") {
+			fmt.Fprintln(w, "  "+bc.displayLine(l, e.Pattern))
+		}
+	}
+
+	fmt.Fprintln(w, hintStyle.Render("  --- rule ---"))
+	for _, line := range patternOrigins(cmd, bc, e.Phase, e.Pattern) {
+		fprintPlain(w, "  %s\n", line)
+	}
+
+	fmt.Fprintln(w, hintStyle.Render("  --- fix ---"))
+	for _, line := range fixCommands(e) {
+		fmt.Fprintln(w, "  "+line)
+	}
+}
+
+// explainHunk returns the @@ hunk of path's diff that contains post-image
+// line, or "" when it can't be found.
+func explainHunk(diff, path string, line int) string {
+	if diff == "" || line == 0 {
+		return ""
+	}
+	for _, f := range splitDiffFiles(diff) {
+		if f.Path != path {
+			continue
+		}
+		var hunk []string
+		start := 0
+		for _, l := range strings.Split(f.Body, "\n") {
+			if strings.HasPrefix(l, "@@ ") {
+				if hunk != nil && inHunk(hunk, start, line) {
+					return strings.Join(hunk, "\n")
+				}
+				hunk = []string{l}
+				start = hunkNewStart(l)
+				continue
+			}
+			if hunk != nil {
+				hunk = append(hunk, l)
+			}
+		}
+		if hunk != nil && inHunk(hunk, start, line) {
+			return strings.Join(hunk, "\n")
+		}
+	}
+	return ""
+}
+
+// inHunk reports whether post-image line falls within a hunk starting at start.
+func inHunk(hunk []string, start, line int) bool {
+	n := start
+	for _, l := range hunk[1:] {
+		if strings.HasPrefix(l, "-") || strings.HasPrefix(l, "\\") {
+			continue
+		}
+		if n == line {
+			return true
+		}
+		n++
+	}
+	return false
+}
+
+// patternOrigins names every config source that contributes pattern to the
+// given phase, and explains push's inheritance from diff and msg.
+func patternOrigins(cmd *cobra.Command, bc *BlockConfig, phase, pattern string) []string {
+	sources, err := collectSources(cmd)
+	if err != nil {
+		return []string{fmt.Sprintf("could not read config: %v", err)}
+	}
+	has := func(list []string) bool {
+		for _, p := range list {
+			if strings.ToLower(p) == pattern {
+				return true
+			}
+		}
+		return false
+	}
+
+	var out []string
+	shown := bc.display(pattern)
+	for _, src := range sources {
+		if src.Kind != "toml" {
+			continue
+		}
+		switch phase {
+		case "push":
+			if src.Push != nil && has(*src.Push) {
+				out = append(out, fmt.Sprintf("%q from [block] push in %s", shown, src.Label))
+			}
+			if bc.Push == nil {
+				if has(src.Diff) {
+					out = append(out, fmt.Sprintf("%q from [block] diff in %s", shown, src.Label))
+				}
+				if has(src.Msg) {
+					out = append(out, fmt.Sprintf("%q from [block] msg in %s", shown, src.Label))
+				}
+			}
+		case "msg":
+			if has(src.Msg) {
+				out = append(out, fmt.Sprintf("%q from [block] msg in %s", shown, src.Label))
+			}
+		default:
+			if has(src.Diff) {
+				out = append(out, fmt.Sprintf("%q from [block] diff in %s", shown, src.Label))
+			}
+		}
+	}
+	if len(out) == 0 {
+		out = append(out, fmt.Sprintf("%q (source not found — check snag config)", shown))
+	}
+	if phase == "push" && bc.Push == nil {
+		out = append(out, "no [block] push list is set, so push checks the union of diff and msg patterns")
+	}
+	out = append(out, fmt.Sprintf("to skip it for one command: SNAG_IGNORE=%q", shown))
+	return out
+}
+
+// fixCommands suggests commands for getting past a violation.
+func fixCommands(e explanation) []string {
+	switch e.Phase {
+	case "diff":
+		return []string{
+			fmt.Sprintf("edit %s:%d, then: git add %s", e.Path, e.Line, e.Path),
+			fmt.Sprintf("or unstage the file: git restore --staged %s", e.Path),
+		}
+	case "msg":
+		return []string{
+			"reword the message and retry: git commit -eF .git/COMMIT_EDITMSG",
+		}
+	case "push":
+		short := e.SHA
+		if len(short) > 7 {
+			short = short[:7]
+		}
+		what := "message"
+		if e.Path != "" {
+			what = fmt.Sprintf("%s:%d", e.Path, e.Line)
+		}
+		return []string{
+			fmt.Sprintf("fix %s in %s, then if it's HEAD: git commit --amend", what, short),
+			fmt.Sprintf("otherwise: git rebase -i %s^ and mark %s as edit", short, short),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExplainHunk(t *testing.T) {
+	diff := `diff --git a/x.go b/x.go
+--- a/x.go
++++ b/x.go
+@@ -1,2 +1,2 @@
+ package x
+-var a = 1
++var a = 2
+@@ -10,2 +10,3 @@ func f() {
+ 	b := 1
++	// TODO later
+ 	c := 2
+`
+	got := explainHunk(diff, "x.go", 11)
+	if !strings.HasPrefix(got, "@@ -10,2 +10,3 @@") || !strings.Contains(got, "TODO later") {
+		t.Errorf("explainHunk(line 11) = %q", got)
+	}
+	if got := explainHunk(diff, "x.go", 2); !strings.Contains(got, "var a = 2") || strings.Contains(got, "TODO") {
+		t.Errorf("explainHunk(line 2) = %q", got)
+	}
+	if got := explainHunk(diff, "other.go", 2); got != "" {
+		t.Errorf("unknown path should give no hunk, got %q", got)
+	}
+}
+
+func TestPatternOrigins_PushInheritance(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"TODO\"]\n"), 0644)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	cmd := buildRootCmd()
+	bc, err := resolveBlockConfig(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(patternOrigins(cmd, bc, "push", "todo"), "\n")
+	if !strings.Contains(got, "[block] diff in") || !strings.Contains(got, "snag.toml") {
+		t.Errorf("origin missing config file: %s", got)
+	}
+	if !strings.Contains(got, "union of diff and msg") {
+		t.Errorf("push inheritance not explained: %s", got)
+	}
+}
+
+func TestRunDiff_Explain(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"todo\"]\n"), 0644)
+	stageFile(t, dir, "code.go", "package x\n\n// TODO fix\n")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	oldStderr := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"check", "diff", "--explain"})
+	err := rootCmd.Execute()
+
+	w.Close()
+	os.Stderr = oldStderr
+	out, _ := io.ReadAll(r)
+
+	if err == nil {
+		t.Fatal("expected violation")
+	}
+	for _, want := range []string{"+// TODO fix", "[block] diff in", "git restore --staged code.go"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("explain output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+// A term from a sensitive = true config is masked in the hunk too, not
+// just in the rule line.
+func TestRunDiff_ExplainRedactsSensitive(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\nsensitive = true\ndiff = [\"projectx\", \"norm:bluebird\"]\n"), 0644)
+	stageFile(t, dir, "code.go", "package x\n\n// ProjectX launch\n")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	explain := func() string {
+		t.Helper()
+		oldStderr := os.Stderr
+		r, w, _ := os.Pipe()
+		os.Stderr = w
+		rootCmd := buildRootCmd()
+		rootCmd.SetArgs([]string{"check", "diff", "--explain"})
+		err := rootCmd.Execute()
+		w.Close()
+		os.Stderr = oldStderr
+		out, _ := io.ReadAll(r)
+		if err == nil {
+			t.Fatal("expected violation")
+		}
+		return string(out)
+	}
+
+	out := explain()
+	if strings.Contains(strings.ToLower(out), "projectx") {
+		t.Errorf("sensitive term leaked:\n%s", out)
+	}
+	if !strings.Contains(out, "+// Pr****tX launch") {
+		t.Errorf("hunk should keep the line with the term masked:\n%s", out)
+	}
+
+	stageFile(t, dir, "code.go", "package x\n\n// b-l-u-e-b-i-r-d launch\n")
+	if out := explain(); strings.Contains(out, "b-l-u-e-b-i-r-d") {
+		t.Errorf("sensitive norm: match leaked:\n%s", out)
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// exportTargets maps each --to value to its renderer.
+var exportTargets = map[string]func(w io.Writer, rules []exportRule, skipExt []string) error{
+	"gitleaks":       exportGitleaks,
+	"trufflehog":     exportTrufflehog,
+	"detect-secrets": exportDetectSecrets,
+}
+
+// exportRule is one snag diff pattern in a form other scanners understand.
+type exportRule struct {
+	ID      string
+	Pattern string // lowercased literal
+	Regex   string // case-insensitive RE2/Python-compatible regex for Pattern
+}
+
+func buildExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export --to gitleaks|trufflehog|detect-secrets",
+		Short: "Convert the diff rule set into another secret scanner's config",
+		Long: `Render the resolved [block] diff patterns as a config for another scanner,
+so org-level scanning and local hooks share one source of truth:
+
+  gitleaks        .gitleaks.toml rules (plus [skip] extensions as an allowlist)
+  trufflehog      custom detectors YAML (trufflehog --config FILE)
+  detect-secrets  a regex plugin (detect-secrets scan --plugin FILE)
+
+Hashed (sha256:) patterns have no plain form and are left out. Patterns from
+sensitive = true configs are left out unless --include-sensitive is given.`,
+		Example: `  snag export --to gitleaks -o .gitleaks.toml
+  snag export --to trufflehog > trufflehog.yaml
+  snag export --to detect-secrets -o snag_plugin.py`,
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE:         runExport,
+	}
+	cmd.Flags().String("to", "", "target tool: gitleaks, trufflehog, or detect-secrets")
+	cmd.Flags().StringP("output", "o", "", "write to FILE instead of stdout")
+	cmd.Flags().Bool("include-sensitive", false, "also export patterns from sensitive = true configs")
+	cmd.MarkFlagRequired("to")
+	return cmd
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	to, _ := cmd.Flags().GetString("to")
+	render, ok := exportTargets[to]
+	if !ok {
+		return fmt.Errorf("--to must be gitleaks, trufflehog, or detect-secrets, got %q", to)
+	}
+	bc, err := resolveBlockConfig(cmd)
+	if err != nil {
+		return err
+	}
+	includeSensitive, _ := cmd.Flags().GetBool("include-sensitive")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+
+	rules, left := exportRules(bc, includeSensitive)
+	if len(rules) == 0 {
+		return fmt.Errorf("no exportable diff patterns")
+	}
+
+	var buf bytes.Buffer
+	if err := render(&buf, rules, bc.SkipExtensions); err != nil {
+		return err
+	}
+	if out, _ := cmd.Flags().GetString("output"); out != "" {
+		if err := os.WriteFile(out, buf.Bytes(), 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", out, err)
+		}
+		if !quiet {
+			infof("wrote %d rules to %s", len(rules), out)
+		}
+	} else {
+		cmd.OutOrStdout().Write(buf.Bytes())
+	}
+	if left > 0 && !quiet {
+		warnf("%d hashed, norm:, or sensitive pattern(s) not exported", left)
+	}
+	return nil
+}
+
+// exportRules converts bc.Diff, returning how many patterns were left out.
+func exportRules(bc *BlockConfig, includeSensitive bool) ([]exportRule, int) {
+	var rules []exportRule
+	left := 0
+	for _, p := range bc.Diff {
+		if isHashPattern(p) || isNormPattern(p) || (bc.Sensitive[p] && !includeSensitive) {
+			left++
+			continue
+		}
+		rules = append(rules, exportRule{
+			ID:      fmt.Sprintf("snag-%d", len(rules)+1),
+			Pattern: p,
+			Regex:   "(?i)" + regexp.QuoteMeta(p),
+		})
+	}
+	return rules, left
+}
+
+// extAllowRegex turns a [skip] extension into a path regex.
+func extAllowRegex(ext string) string {
+	return regexp.QuoteMeta(ext) + "$"
+}
+
+func exportGitleaks(w io.Writer, rules []exportRule, skipExt []string) error {
+	type rule struct {
+		ID          string   `toml:"id"`
+		Description string   `toml:"description"`
+		Regex       string   `toml:"regex"`
+		Keywords    []string `toml:"keywords"`
+	}
+	type allowlist struct {
+		Description string   `toml:"description"`
+		Paths       []string `toml:"paths"`
+	}
+	cfg := struct {
+		Title     string     `toml:"title"`
+		Rules     []rule     `toml:"rules"`
+		Allowlist *allowlist `toml:"allowlist,omitempty"`
+	}{Title: "snag policy (generated by snag export; edit snag.toml instead)"}
+	for _, r := range rules {
+		cfg.Rules = append(cfg.Rules, rule{
+			ID: r.ID, Description: fmt.Sprintf("snag block pattern %q", r.Pattern),
+			Regex: r.Regex, Keywords: []string{r.Pattern},
+		})
+	}
+	if len(skipExt) > 0 {
+		cfg.Allowlist = &allowlist{Description: "snag [skip] extensions"}
+		for _, ext := range skipExt {
+			cfg.Allowlist.Paths = append(cfg.Allowlist.Paths, extAllowRegex(ext))
+		}
+	}
+	return toml.NewEncoder(w).Encode(cfg)
+}
+
+func exportTrufflehog(w io.Writer, rules []exportRule, _ []string) error {
+	type detector struct {
+		Name     string            `yaml:"name"`
+		Keywords []string          `yaml:"keywords"`
+		Regex    map[string]string `yaml:"regex"`
+	}
+	var cfg struct {
+		Detectors []detector `yaml:"detectors"`
+	}
+	for _, r := range rules {
+		cfg.Detectors = append(cfg.Detectors, detector{
+			Name: r.ID, Keywords: []string{r.Pattern}, Regex: map[string]string{"match": r.Regex},
+		})
+	}
+	fmt.Fprintln(w, "# Generated by snag export; edit snag.toml instead.")
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	defer enc.Close()
+	return enc.Encode(cfg)
+}
+
+func exportDetectSecrets(w io.Writer, rules []exportRule, _ []string) error {
+	var b strings.Builder
+	b.WriteString(`# Generated by snag export; edit snag.toml instead.
+# Usage: detect-secrets scan --plugin THIS_FILE
+import re
+
+from detect_secrets.plugins.base import RegexBasedDetector
+
+
+class SnagPolicyDetector(RegexBasedDetector):
+    """Block patterns from snag.toml."""
+
+    secret_type = "snag policy pattern"
+
+    denylist = [
+`)
+	for _, r := range rules {
+		fmt.Fprintf(&b, "        re.compile(re.escape(%q), re.IGNORECASE),\n", r.Pattern)
+	}
+	b.WriteString("    ]\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+func TestExportRules(t *testing.T) {
+	bc := &BlockConfig{
+		Diff:      []string{"api.corp", hashToken("x"), "secret-host"},
+		Sensitive: map[string]bool{"secret-host": true},
+	}
+	rules, left := exportRules(bc, false)
+	if len(rules) != 1 || left != 2 || rules[0].Regex != `(?i)api\.corp` {
+		t.Errorf("rules = %+v, left = %d", rules, left)
+	}
+	if !regexp.MustCompile(rules[0].Regex).MatchString("see API.CORP now") {
+		t.Error("exported regex should match case-insensitively")
+	}
+	if rules, _ := exportRules(bc, true); len(rules) != 2 {
+		t.Errorf("--include-sensitive: got %d rules, want 2", len(rules))
+	}
+}
+
+func TestExport_Targets(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[block]\ndiff = [\"api.corp\", \"do not merge\"]\n\n[skip]\nextensions = [\".min.js\"]\n"), 0644)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	export := func(to string) string {
+		t.Helper()
+		var out bytes.Buffer
+		rootCmd := buildRootCmd()
+		rootCmd.SetOut(&out)
+		rootCmd.SetArgs([]string{"export", "--to", to, "-q"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("export --to %s: %v", to, err)
+		}
+		return out.String()
+	}
+
+	var gl struct {
+		Rules []struct {
+			ID       string
+			Regex    string
+			Keywords []string
+		}
+		Allowlist struct{ Paths []string }
+	}
+	if _, err := toml.Decode(export("gitleaks"), &gl); err != nil {
+		t.Fatalf("gitleaks output is not TOML: %v", err)
+	}
+	if len(gl.Rules) != 2 || gl.Rules[0].Regex != `(?i)api\.corp` || gl.Rules[1].Keywords[0] != "do not merge" {
+		t.Errorf("gitleaks rules = %+v", gl.Rules)
+	}
+	if len(gl.Allowlist.Paths) != 1 || gl.Allowlist.Paths[0] != `\.min\.js$` {
+		t.Errorf("gitleaks allowlist = %+v", gl.Allowlist)
+	}
+
+	var th struct {
+		Detectors []struct {
+			Name     string
+			Keywords []string
+			Regex    map[string]string
+		}
+	}
+	if err := yaml.Unmarshal([]byte(export("trufflehog")), &th); err != nil {
+		t.Fatalf("trufflehog output is not YAML: %v", err)
+	}
+	if len(th.Detectors) != 2 || th.Detectors[0].Regex["match"] != `(?i)api\.corp` {
+		t.Errorf("trufflehog detectors = %+v", th.Detectors)
+	}
+
+	if ds := export("detect-secrets"); !strings.Contains(ds, `re.compile(re.escape("do not merge"), re.IGNORECASE)`) {
+		t.Errorf("detect-secrets plugin:\n%s", ds)
+	}
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"export", "--to", "semgrep", "-q"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("expected error for unknown target")
+	}
+}
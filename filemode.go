@@ -0,0 +1,75 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+const (
+	modeRegular    = "100644"
+	modeExecutable = "100755"
+)
+
+// modeMismatch is a staged file whose executable bit breaks policy.
+type modeMismatch struct {
+	Path string
+	Want bool // true = must be executable
+}
+
+// findModeMismatches checks staged regular files against [block] executable
+// (must not be +x) and require_executable (must be +x). Symlinks and
+// submodules are ignored.
+func findModeMismatches(files []stagedFile, forbid, require []string) []modeMismatch {
+	var out []modeMismatch
+	for _, f := range files {
+		switch f.Mode {
+		case modeExecutable:
+			if matchPathGlob(forbid, f.Path) {
+				out = append(out, modeMismatch{Path: f.Path, Want: false})
+			}
+		case modeRegular:
+			if matchPathGlob(require, f.Path) {
+				out = append(out, modeMismatch{Path: f.Path, Want: true})
+			}
+		}
+	}
+	return out
+}
+
+// checkFileModes enforces the executable-bit policy on staged files.
+func checkFileModes(cmd *cobra.Command, bc *BlockConfig) error {
+	if len(bc.Executable) == 0 && len(bc.RequireExecutable) == 0 {
+		return nil
+	}
+	files, err := stagedFiles()
+	if err != nil {
+		return err
+	}
+	mismatches := findModeMismatches(files, bc.Executable, bc.RequireExecutable)
+	if len(mismatches) == 0 {
+		return nil
+	}
+
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	if !quiet {
+		for _, m := range mismatches {
+			what, fix := "is executable", "-x"
+			if m.Want {
+				what, fix = "is not executable", "+x"
+			}
+			if problemOutput(cmd) {
+				problemf(idFileMode, m.Path, 1, 1, "%s", what)
+			} else {
+				blockf(idFileMode, "%s %s", m.Path, what)
+				hintf("git update-index --chmod=%s %s", fix, hintArg(m.Path))
+			}
+		}
+		if !problemOutput(cmd) {
+			bell()
+		}
+	}
+	m := mismatches[0]
+	if m.Want {
+		return violationf(idFileMode, "%s must be executable", m.Path)
+	}
+	return violationf(idFileMode, "%s must not be executable", m.Path)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindModeMismatches(t *testing.T) {
+	files := []stagedFile{
+		{Mode: "100755", Path: "README.md"},
+		{Mode: "100644", Path: "docs/guide.md"},
+		{Mode: "100644", Path: "scripts/deploy.sh"},
+		{Mode: "100755", Path: "scripts/build.sh"},
+		{Mode: "120000", Path: "scripts/link.sh"},
+	}
+	got := findModeMismatches(files, []string{"*.md", "*.json"}, []string{"scripts/*.sh"})
+	want := []modeMismatch{{Path: "README.md", Want: false}, {Path: "scripts/deploy.sh", Want: true}}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("mismatch %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRunDiff_ExecutableBit(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[block]\nexecutable = [\"*.md\"]\nrequire_executable = [\"bin/*\"]\n"), 0644)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	run := func() error {
+		rootCmd := buildRootCmd()
+		rootCmd.SetArgs([]string{"check", "diff", "-q"})
+		return rootCmd.Execute()
+	}
+	chmod := func(flag, p string) {
+		t.Helper()
+		if out, err := exec.Command("git", "update-index", "--chmod="+flag, p).CombinedOutput(); err != nil {
+			t.Fatalf("git update-index: %v\n%s", err, out)
+		}
+	}
+
+	stageFile(t, dir, "NOTES.md", "notes\n")
+	chmod("+x", "NOTES.md")
+	err := run()
+	if err == nil || !strings.Contains(err.Error(), "must not be executable") {
+		t.Fatalf("expected executable violation, got %v", err)
+	}
+	chmod("-x", "NOTES.md")
+
+	os.MkdirAll(filepath.Join(dir, "bin"), 0755)
+	stageFile(t, dir, "bin/run", "#!/bin/sh\n")
+	err = run()
+	if err == nil || !strings.Contains(err.Error(), "must be executable") {
+		t.Fatalf("expected require_executable violation, got %v", err)
+	}
+	chmod("+x", "bin/run")
+	if err := run(); err != nil {
+		t.Errorf("correct modes blocked: %v", err)
+	}
+}
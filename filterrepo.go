@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// filterRepoRule is one literal the generated script replaces.
+type filterRepoRule struct {
+	From string
+	To   string
+}
+
+// sensitiveScrubRules collects the policy's sensitive rules: patterns from
+// configs marked sensitive = true, which are replaced with replacement (or
+// matched by token digest when hashed), and [redact] literals, which keep
+// their configured replacements. extra adds --pattern values.
+func sensitiveScrubRules(bc *BlockConfig, extra []string, replacement string) (rules []filterRepoRule, hashes []string) {
+	seen := make(map[string]bool)
+	add := func(p, to string) {
+		// A norm: pattern can only be rewritten in its plain spelling.
+		p = strings.TrimPrefix(strings.ToLower(p), normPatternPrefix)
+		if p == "" || seen[p] {
+			return
+		}
+		seen[p] = true
+		if isHashPattern(p) {
+			hashes = append(hashes, strings.TrimPrefix(p, hashPatternPrefix))
+			return
+		}
+		rules = append(rules, filterRepoRule{From: p, To: to})
+	}
+	for _, r := range bc.redactRules() {
+		add(r.From, r.To)
+	}
+	var sensitive []string
+	for p := range bc.Sensitive {
+		sensitive = append(sensitive, p)
+	}
+	sort.Strings(sensitive)
+	for _, p := range append(sensitive, extra...) {
+		add(p, replacement)
+	}
+	// Longest literal first, as with [redact], so specific terms win.
+	sort.SliceStable(rules, func(i, j int) bool { return len(rules[i].From) > len(rules[j].From) })
+	sort.Strings(hashes)
+	return rules, hashes
+}
+
+// pyBytes renders s as a Python bytes literal, escaping everything outside
+// printable ASCII so the generated script is encoding-proof.
+func pyBytes(s string) string {
+	var b strings.Builder
+	b.WriteString(`b"`)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' || c == '"':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c >= 0x20 && c < 0x7f:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// filterRepoScript renders a standalone git-filter-repo script whose blob
+// and commit callbacks apply the same matching as enforcement: literals
+// case-insensitively, sha256: patterns per token using hashpattern.go's
+// delimiters and trailing-punctuation trim.
+func filterRepoScript(rules []filterRepoRule, hashes []string, replacement string) string {
+	var b strings.Builder
+	b.WriteString(`#!/usr/bin/env python3
+# Generated by snag scrub --emit-filter-repo-script from this repo's
+# sensitive rules. It contains those terms in plain text: keep it out of
+# the repo and delete it after use. Run from the repository root:
+#
+#   python3 THIS_FILE
+import hashlib
+import re
+
+import git_filter_repo as fr
+
+LITERALS = [
+`)
+	for _, r := range rules {
+		fmt.Fprintf(&b, "    (re.compile(re.escape(%s), re.IGNORECASE), %s),\n", pyBytes(r.From), pyBytes(r.To))
+	}
+	b.WriteString("]\n\nHASHES = {\n")
+	for _, h := range hashes {
+		fmt.Fprintf(&b, "    %q,\n", h)
+	}
+	fmt.Fprintf(&b, "}\n\nPLACEHOLDER = %s\n", pyBytes(replacement))
+	b.WriteString(`TOKEN_DELIMS = re.compile(rb"([\s\"'` + "`" + `=:,;()\[\]{}<>]+)")
+
+
+def scrub(data):
+    for pattern, replacement in LITERALS:
+        # A function, not a template: keeps backslashes in replacement literal.
+        data = pattern.sub(lambda _match, r=replacement: r, data)
+    if HASHES:
+        parts = TOKEN_DELIMS.split(data)
+        for i in range(0, len(parts), 2):
+            token = parts[i].rstrip(b".!?")
+            if token and hashlib.sha256(token.lower()).hexdigest() in HASHES:
+                parts[i] = PLACEHOLDER + parts[i][len(token):]
+        data = b"".join(parts)
+    return data
+
+
+def blob_callback(blob, _metadata):
+    blob.data = scrub(blob.data)
+
+
+def commit_callback(commit, _metadata):
+    commit.message = scrub(commit.message)
+
+
+args = fr.FilteringOptions.parse_args(["--force"])
+fr.RepoFilter(args, blob_callback=blob_callback, commit_callback=commit_callback).run()
+`)
+	return b.String()
+}
+
+// emitFilterRepoScript writes the script for the current policy to path
+// ("-" for the command's output).
+func emitFilterRepoScript(cmd *cobra.Command, bc *BlockConfig, extra []string, replacement, path string) error {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	rules, hashes := sensitiveScrubRules(bc, extra, replacement)
+	if len(rules) == 0 && len(hashes) == 0 {
+		return fmt.Errorf("no sensitive rules to emit: mark a config [block] sensitive = true, add [redact], or pass --pattern")
+	}
+	script := filterRepoScript(rules, hashes, replacement)
+	if path == "-" {
+		_, err := io.WriteString(cmd.OutOrStdout(), script)
+		return err
+	}
+	if err := os.WriteFile(path, []byte(script), 0700); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	if !quiet {
+		infof("wrote %s (%d literal, %d hashed rules)", path, len(rules), len(hashes))
+		hintf("it contains the sensitive terms — run it, then delete it: python3 %s", hintArg(path))
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func formatFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("fix", false, "rewrite offending lines and restage the corrected files")
+}
+
+// formatEnabled reports whether any [format] check is switched on.
+func (bc *BlockConfig) formatEnabled() bool {
+	return bc.FormatTrailingWhitespace || bc.FormatFinalNewline || bc.FormatCRLF
+}
+
+// formatProblem is one whitespace issue on a line of a staged file.
+type formatProblem struct {
+	Line int
+	Kind string
+}
+
+// fixWhitespace checks the given added lines of content (1-based post-image
+// line numbers) and returns the problems found plus the corrected content.
+// Only added lines are touched, like git diff --check, so committing to a
+// file never drags unrelated cleanup into the diff.
+func fixWhitespace(bc *BlockConfig, content string, added map[int]bool) (string, []formatProblem) {
+	var problems []formatProblem
+	lines := strings.Split(content, "\n")
+	last := len(lines)
+	if strings.HasSuffix(content, "\n") {
+		last-- // the split leaves an empty element after the final newline
+	}
+	for i := 0; i < last; i++ {
+		n := i + 1
+		if !added[n] {
+			continue
+		}
+		line := lines[i]
+		eol := ""
+		if strings.HasSuffix(line, "\r") {
+			line = line[:len(line)-1]
+			eol = "\r"
+			if bc.FormatCRLF {
+				problems = append(problems, formatProblem{n, "CRLF line ending"})
+				eol = ""
+			}
+		}
+		if bc.FormatTrailingWhitespace {
+			if trimmed := strings.TrimRight(line, " \t"); trimmed != line {
+				problems = append(problems, formatProblem{n, "trailing whitespace"})
+				line = trimmed
+			}
+		}
+		lines[i] = line + eol
+	}
+	fixed := strings.Join(lines, "\n")
+	if bc.FormatFinalNewline && content != "" && !strings.HasSuffix(content, "\n") && added[last] {
+		problems = append(problems, formatProblem{last, "no newline at end of file"})
+		fixed += "\n"
+	}
+	return fixed, problems
+}
+
+// stagedAddedLines maps each staged path to the post-image line numbers of
+// its added lines.
+func stagedAddedLines() (map[string]map[int]bool, error) {
+	out, err := gitCommand("diff", "--staged", "-U0", "--no-color", "--no-ext-diff", "--diff-filter=ACM").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff --staged: %w", err)
+	}
+	added := make(map[string]map[int]bool)
+	for _, f := range splitDiffFiles(string(out)) {
+		set := make(map[int]bool)
+		for _, l := range addedLines(f.Body) {
+			set[l.Line] = true
+		}
+		added[f.Path] = set
+	}
+	return added, nil
+}
+
+func runFormat(cmd *cobra.Command, args []string) error {
+	bc, err := resolveBlockConfig(cmd)
+	if err != nil {
+		return err
+	}
+	if !bc.formatEnabled() {
+		return nil
+	}
+	fix, _ := cmd.Flags().GetBool("fix")
+	return checkFormat(cmd, bc, fix)
+}
+
+// checkFormat runs the [format] checks over staged text files. With fix,
+// offending lines are corrected in the staged blob (and the working tree
+// when it matches) instead of failing.
+func checkFormat(cmd *cobra.Command, bc *BlockConfig, fix bool) error {
+	added, err := stagedAddedLines()
+	if err != nil {
+		return err
+	}
+	files, err := stagedFiles()
+	if err != nil {
+		return err
+	}
+	rules := bc.skipRules()
+	rules.MaxBytes = 0 // whitespace checks are cheap; size doesn't matter
+
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	var bad []string
+	for _, f := range files {
+		if f.Mode == "120000" || f.Mode == "160000" || matchPathGlob(bc.FormatExclude, f.Path) || len(added[f.Path]) == 0 {
+			continue // symlinks and submodules have no text to format
+		}
+		staged, err := gitCommand("show", ":"+f.Path).Output()
+		if err != nil {
+			return fmt.Errorf("git show :%s: %w", f.Path, err)
+		}
+		if rules.reason(diffFile{Path: f.Path, Body: string(staged)}) != "" {
+			continue
+		}
+		fixed, problems := fixWhitespace(bc, string(staged), added[f.Path])
+		if len(problems) == 0 {
+			continue
+		}
+		if fix {
+			if err := restageFile(f, string(staged), fixed); err != nil {
+				return err
+			}
+			if !quiet {
+				infof("fixed %d formatting problem(s) in %s", len(problems), f.Path)
+			}
+			continue
+		}
+		bad = append(bad, f.Path)
+		if quiet {
+			continue
+		}
+		for _, p := range problems {
+			if problemOutput(cmd) {
+				problemf(idFormat, f.Path, p.Line, 1, "%s", p.Kind)
+			} else {
+				blockf(idFormat, "%s:%d: %s", f.Path, p.Line, p.Kind)
+			}
+		}
+	}
+	if len(bad) == 0 {
+		return nil
+	}
+	if !quiet && !problemOutput(cmd) {
+		hintf("to fix and restage: snag check format --fix")
+		bell()
+	}
+	return violationf(idFormat, "formatting problems in %s", strings.Join(bad, ", "))
+}
+
+func testFormat(cmd *cobra.Command, dir string, patterns []string) bool {
+	orig, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(orig)
+
+	if err := os.WriteFile(filepath.Join(dir, "format.txt"), []byte("trailing   \r\nno newline"), 0644); err != nil {
+		return false
+	}
+	if out, err := gitCommand("add", "format.txt").CombinedOutput(); err != nil {
+		fmt.Fprintf(os.Stderr, "git add: %s\n", out)
+		return false
+	}
+	bc := &BlockConfig{FormatTrailingWhitespace: true, FormatFinalNewline: true, FormatCRLF: true}
+	err := checkFormat(cmd, bc, false)
+	return err != nil // error means violation detected = pass
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFixWhitespace(t *testing.T) {
+	all := &BlockConfig{FormatTrailingWhitespace: true, FormatFinalNewline: true, FormatCRLF: true}
+	tests := []struct {
+		name      string
+		bc        *BlockConfig
+		content   string
+		added     map[int]bool
+		wantFixed string
+		wantKinds []string
+	}{
+		{"clean", all, "a\nb\n", map[int]bool{1: true, 2: true}, "a\nb\n", nil},
+		{"trailing on added line only", all, "a  \nb \n", map[int]bool{2: true}, "a  \nb\n", []string{"trailing whitespace"}},
+		{"crlf", all, "a\r\nb\n", map[int]bool{1: true}, "a\nb\n", []string{"CRLF line ending"}},
+		{"crlf kept when unchecked", &BlockConfig{FormatTrailingWhitespace: true}, "a \r\n", map[int]bool{1: true}, "a\r\n", []string{"trailing whitespace"}},
+		{"missing final newline", all, "a\nb", map[int]bool{2: true}, "a\nb\n", []string{"no newline at end of file"}},
+		{"final newline on untouched line", all, "a\nb", map[int]bool{1: true}, "a\nb", nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fixed, problems := fixWhitespace(tc.bc, tc.content, tc.added)
+			if fixed != tc.wantFixed {
+				t.Errorf("fixed = %q, want %q", fixed, tc.wantFixed)
+			}
+			var kinds []string
+			for _, p := range problems {
+				kinds = append(kinds, p.Kind)
+			}
+			if strings.Join(kinds, ",") != strings.Join(tc.wantKinds, ",") {
+				t.Errorf("problems = %v, want %v", kinds, tc.wantKinds)
+			}
+		})
+	}
+}
+
+func TestRunFormat_FixRestages(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[format]\ntrailing_whitespace = true\nfinal_newline = true\nexclude = [\"*.md\"]\n"), 0644)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	run := func(args ...string) error {
+		rootCmd := buildRootCmd()
+		rootCmd.SetArgs(append([]string{"check", "format", "-q"}, args...))
+		return rootCmd.Execute()
+	}
+
+	stageFile(t, dir, "notes.md", "hard break  \n")
+	stageFile(t, dir, "main.go", "package main \n\nfunc main() {}")
+	err := run()
+	if err == nil || !strings.Contains(err.Error(), "main.go") || strings.Contains(err.Error(), "notes.md") {
+		t.Fatalf("expected violation in main.go only, got %v", err)
+	}
+
+	if err := run("--fix"); err != nil {
+		t.Fatalf("--fix failed: %v", err)
+	}
+	out, _ := exec.Command("git", "show", ":main.go").Output()
+	if string(out) != "package main\n\nfunc main() {}\n" {
+		t.Errorf("staged content after fix = %q", out)
+	}
+	if err := run(); err != nil {
+		t.Errorf("check after fix should pass, got %v", err)
+	}
+}
+
+func TestRunFormat_FixFromSubdirectory(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[format]\ntrailing_whitespace = true\n"), 0644)
+	os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+	stageFile(t, dir, "sub/a.go", "package a \n")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(filepath.Join(dir, "sub"))
+	defer os.Chdir(oldDir)
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"check", "format", "-q", "--fix"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("--fix from sub/: %v", err)
+	}
+	if out, _ := exec.Command("git", "show", ":sub/a.go").Output(); string(out) != "package a\n" {
+		t.Errorf("staged content after fix = %q", out)
+	}
+	if wt, _ := os.ReadFile("a.go"); string(wt) != "package a\n" {
+		t.Errorf("worktree after fix = %q", wt)
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// gitHook maps a git hook to the snag check it runs. Stdin marks hooks git
+// feeds input on, which a chained pre-existing hook must see too.
+type gitHook struct {
+	Name  string // git's hook name
+	Check string // Hook.Name of the check
+	Stdin bool
+}
+
+// gitHooks mirrors recipes/lefthook-snag-filter.yml.
+var gitHooks = []gitHook{
+	{"pre-commit", "diff", false},
+	{"commit-msg", "msg", false},
+	{"pre-push", "push", true},
+	{"post-checkout", "checkout", false},
+	{"prepare-commit-msg", "prepare", false},
+	{"pre-rebase", "rebase", false},
+}
+
+func findGitHook(name string) (gitHook, bool) {
+	for _, h := range gitHooks {
+		if h.Name == name {
+			return h, true
+		}
+	}
+	return gitHook{}, false
+}
+
+func gitHookNames() []string {
+	names := make([]string, len(gitHooks))
+	for i, h := range gitHooks {
+		names[i] = h.Name
+	}
+	return names
+}
+
+func buildHookCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "hook GIT-HOOK [ARGS...]",
+		Short: "Run the snag check for a git hook, passing git's arguments through",
+		Long: fmt.Sprintf(`Run the check snag uses for a git hook, with the arguments and stdin git
+gave the hook. Hook scripts can then stay one line long and never need
+editing when snag changes which check a hook runs.
+
+Hooks: %s`, strings.Join(gitHookNames(), ", ")),
+		Example:            `  exec snag hook pre-push "$@"`,
+		Args:               cobra.MinimumNArgs(1),
+		DisableFlagParsing: true,
+		SilenceUsage:       true,
+		RunE:               runHook,
+	}
+}
+
+func runHook(cmd *cobra.Command, args []string) error {
+	h, ok := findGitHook(args[0])
+	if !ok {
+		return fmt.Errorf("snag has no check for git hook %q (known: %s)", args[0], strings.Join(gitHookNames(), ", "))
+	}
+	check, _, err := cmd.Root().Find([]string{"check", h.Check})
+	if err != nil {
+		return err
+	}
+	check.SetContext(cmd.Context())
+	check.SetIn(cmd.InOrStdin())
+	check.SetOut(cmd.OutOrStdout())
+	check.SetErr(cmd.ErrOrStderr())
+	if err := check.ValidateArgs(args[1:]); err != nil {
+		return fmt.Errorf("%s: %w", h.Name, err)
+	}
+	err = check.RunE(check, args[1:])
+	if err != nil {
+		recordHookError(check, err)
+	}
+	return err
+}
+
+// emitMarker identifies hook scripts snag emit-hooks wrote, so re-running
+// it updates them instead of chaining to them.
+const emitMarker = "# Written by snag emit-hooks."
+
+// chainSuffix is appended to a pre-existing hook's name when emit-hooks
+// moves it aside; the emitted script runs it first.
+const chainSuffix = ".pre-snag"
+
+// emittedHookScript is the POSIX sh script for h. A pre-existing hook runs
+// first with the same arguments (and stdin, for hooks that get any), and
+// its failure stops the hook. A missing snag fails the hook rather than
+// letting the commit or push through unchecked.
+func emittedHookScript(h gitHook) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "#!/bin/sh\n%s Re-run it to update this file.\n", emitMarker)
+	fmt.Fprintf(&b, "# A hook that was here before is kept as %s%s and runs first.\n", h.Name, chainSuffix)
+	b.WriteString(`command -v snag >/dev/null 2>&1 || { echo "snag: not found on PATH; install it or remove this hook" >&2; exit 1; }` + "\n")
+	fmt.Fprintf(&b, "prev=\"$(dirname \"$0\")/%s%s\"\n", h.Name, chainSuffix)
+	if h.Stdin {
+		b.WriteString("if [ -x \"$prev\" ]; then\n")
+		b.WriteString("\tinput=$(cat)\n")
+		b.WriteString("\tprintf '%s\\n' \"$input\" | \"$prev\" \"$@\" || exit $?\n")
+		fmt.Fprintf(&b, "\tprintf '%%s\\n' \"$input\" | snag hook %s \"$@\"\n", h.Name)
+		b.WriteString("\texit $?\n")
+		b.WriteString("fi\n")
+	} else {
+		b.WriteString("if [ -x \"$prev\" ]; then\n")
+		b.WriteString("\t\"$prev\" \"$@\" || exit $?\n")
+		b.WriteString("fi\n")
+	}
+	fmt.Fprintf(&b, "exec snag hook %s \"$@\"\n", h.Name)
+	return b.String()
+}
+
+func buildEmitHooksCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "emit-hooks",
+		Short: "Write plain git hook scripts that run snag, without lefthook",
+		Long: `Write a small POSIX sh script for each git hook snag checks. Each script
+calls snag hook, for environments where installing lefthook isn't possible.
+
+The directory defaults to the one git runs hooks from: .git/hooks, or
+core.hooksPath when it is set. A hook already there that snag didn't write is
+renamed to <hook>.pre-snag. The new script runs that hook first with the same
+arguments and stdin, and stops if it fails. Re-running emit-hooks updates
+the scripts it wrote and leaves the chained hooks alone.`,
+		Example: `  snag emit-hooks
+  snag emit-hooks --dir .githooks && git config core.hooksPath .githooks
+  snag emit-hooks -n`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runEmitHooks,
+	}
+	cmd.Flags().String("dir", "", "directory to write hooks to (default: git's hooks directory)")
+	cmd.Flags().BoolP("dry-run", "n", false, "show what would be written without changing anything")
+	return cmd
+}
+
+func runEmitHooks(cmd *cobra.Command, args []string) error {
+	dir, _ := cmd.Flags().GetString("dir")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+
+	gitDir := gitHooksDir()
+	if dir == "" {
+		if gitDir == "" {
+			return fmt.Errorf("not in a git repository (pass --dir)")
+		}
+		dir = gitDir
+	}
+	if !dryRun {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	// Check every hook before touching any, so a conflict leaves the
+	// directory as it was.
+	type plan struct {
+		hook  gitHook
+		path  string
+		chain bool // move the existing hook aside
+	}
+	var plans []plan
+	for _, h := range gitHooks {
+		path := filepath.Join(dir, h.Name)
+		p := plan{hook: h, path: path}
+		if data, err := os.ReadFile(path); err == nil && !strings.Contains(string(data), emitMarker) {
+			if fileExists(path + chainSuffix) {
+				return fmt.Errorf("%s exists and so does %s%s; merge them by hand, then re-run", path, h.Name, chainSuffix)
+			}
+			p.chain = true
+		}
+		plans = append(plans, p)
+	}
+
+	for _, p := range plans {
+		if dryRun {
+			if p.chain {
+				fmt.Fprintf(cmd.OutOrStdout(), "would move %s to %s%s\n", p.path, p.hook.Name, chainSuffix)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "would write %s\n", p.path)
+			continue
+		}
+		if p.chain {
+			if err := os.Rename(p.path, p.path+chainSuffix); err != nil {
+				return err
+			}
+			if !quiet {
+				infof("kept the existing %s as %s%s; it runs before snag", p.hook.Name, p.hook.Name, chainSuffix)
+			}
+		}
+		err := writeFileAtomic(p.path, func(f *os.File) error {
+			if _, err := f.WriteString(emittedHookScript(p.hook)); err != nil {
+				return err
+			}
+			return f.Chmod(0755)
+		})
+		if err != nil {
+			return fmt.Errorf("writing %s: %w", p.path, err)
+		}
+	}
+	if !quiet && !dryRun {
+		infof("wrote %d hooks to %s", len(plans), dir)
+		if abs, _ := filepath.Abs(dir); gitDir != "" && dirKey(runtime.GOOS, abs) != dirKey(runtime.GOOS, gitDir) {
+			hintf("point git at them: git config core.hooksPath %s", hintArg(filepath.ToSlash(dir)))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEmitHooks(t *testing.T) {
+	dir := initGitRepo(t)
+	hooksDir := filepath.Join(dir, ".git", "hooks")
+	os.MkdirAll(hooksDir, 0755)
+	old := "#!/bin/sh\necho old\n"
+	os.WriteFile(filepath.Join(hooksDir, "pre-commit"), []byte(old), 0755)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	for range 2 { // a second run updates the scripts without chaining to them
+		rootCmd := buildRootCmd()
+		rootCmd.SetArgs([]string{"emit-hooks", "--quiet"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(hooksDir, "pre-commit"+chainSuffix)); string(data) != old {
+		t.Errorf("chained hook = %q, want the original", data)
+	}
+	for _, h := range gitHooks {
+		path := filepath.Join(hooksDir, h.Name)
+		data, err := os.ReadFile(path)
+		if err != nil || !strings.Contains(string(data), "snag hook "+h.Name) {
+			t.Errorf("%s = %q, %v", h.Name, data, err)
+		}
+		if info, _ := os.Stat(path); info == nil || info.Mode().Perm()&0100 == 0 {
+			t.Errorf("%s is not executable", h.Name)
+		}
+		if h.Name != "pre-commit" && fileExists(path+chainSuffix) {
+			t.Errorf("%s was chained with nothing to chain", h.Name)
+		}
+	}
+
+	// A foreign hook with a .pre-snag already beside it is a conflict, and
+	// nothing is written.
+	os.WriteFile(filepath.Join(hooksDir, "pre-commit"), []byte(old), 0755)
+	before, _ := os.ReadFile(filepath.Join(hooksDir, "pre-push"))
+	os.WriteFile(filepath.Join(hooksDir, "pre-push"), []byte("#!/bin/sh\n"), 0755)
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"emit-hooks", "--quiet"})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "by hand") {
+		t.Errorf("err = %v, want a conflict", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(hooksDir, "pre-push")); string(data) == string(before) {
+		t.Error("pre-push was rewritten despite the conflict")
+	}
+}
+
+func TestEmittedHookScript_Chains(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no sh")
+	}
+	dir := t.TempDir()
+	log := filepath.Join(dir, "log")
+	// A stand-in snag that records how it was called.
+	bin := filepath.Join(dir, "bin")
+	os.Mkdir(bin, 0755)
+	os.WriteFile(filepath.Join(bin, "snag"), []byte("#!/bin/sh\necho \"snag $*: $(cat)\" >> "+log+"\n"), 0755)
+	h, _ := findGitHook("pre-push")
+	os.WriteFile(filepath.Join(dir, "pre-push"), []byte(emittedHookScript(h)), 0755)
+	os.WriteFile(filepath.Join(dir, "pre-push"+chainSuffix), []byte("#!/bin/sh\necho \"prev $*: $(cat)\" >> "+log+"\n"), 0755)
+
+	run := func() error {
+		c := exec.Command(filepath.Join(dir, "pre-push"), "origin", "git@example.com:x.git")
+		c.Env = append(os.Environ(), "PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+		c.Stdin = strings.NewReader("refs/heads/main abc refs/heads/main def\n")
+		return c.Run()
+	}
+	if err := run(); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(log)
+	want := "prev origin git@example.com:x.git: refs/heads/main abc refs/heads/main def\n" +
+		"snag hook pre-push origin git@example.com:x.git: refs/heads/main abc refs/heads/main def\n"
+	if string(data) != want {
+		t.Errorf("log:\n%s\nwant:\n%s", data, want)
+	}
+
+	// A failing chained hook stops the push before snag runs.
+	os.Remove(log)
+	os.WriteFile(filepath.Join(dir, "pre-push"+chainSuffix), []byte("#!/bin/sh\nexit 3\n"), 0755)
+	if err := run(); err == nil {
+		t.Error("expected the chained hook's failure")
+	}
+	if fileExists(log) {
+		t.Error("snag ran after the chained hook failed")
+	}
+}
+
+func TestRunHook(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\nmsg = [\"wip\"]\n"), 0644)
+	msgFile := filepath.Join(dir, "COMMIT_EDITMSG")
+	os.WriteFile(msgFile, []byte("half done, wip\n"), 0644)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"hook", "commit-msg", msgFile, "--quiet"}, ""}, // flags reach the check as arguments
+		{[]string{"hook", "commit-msg", msgFile}, idMsgPattern},
+		{[]string{"hook", "post-merge"}, "no check for git hook"},
+		{[]string{"hook", "commit-msg"}, "commit-msg: accepts 1 arg"},
+	} {
+		rootCmd := buildRootCmd()
+		rootCmd.SetArgs(tc.args)
+		err := rootCmd.Execute()
+		if tc.want == "" {
+			if err == nil {
+				t.Errorf("%v: expected an argument error", tc.args)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%v: err = %v, want %q", tc.args, err, tc.want)
+		}
+	}
+}
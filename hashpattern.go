@@ -0,0 +1,73 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// hashPatternPrefix marks a pattern that matches by digest rather than by
+// substring, so a shared policy can block a specific secret value without
+// spelling it out in snag.toml.
+const hashPatternPrefix = "sha256:"
+
+// isHashPattern reports whether p is a sha256:<hex> pattern.
+func isHashPattern(p string) bool {
+	return strings.HasPrefix(p, hashPatternPrefix)
+}
+
+// hashToken returns the sha256:<hex> pattern for a single term, applying
+// the same normalization used on candidate tokens (lowercase).
+func hashToken(term string) string {
+	sum := sha256.Sum256([]byte(lowerBytesafe(term)))
+	return hashPatternPrefix + hex.EncodeToString(sum[:])
+}
+
+// isTokenDelim reports whether r separates candidate tokens. Whitespace,
+// quotes, and assignment/structural punctuation split tokens; dots, dashes,
+// underscores, slashes, and @ do not, so hostnames, emails, and API keys
+// stay whole.
+func isTokenDelim(r rune) bool {
+	switch r {
+	case ' ', '\t', '\n', '\r', '"', '\'', '`', '=', ':', ',', ';', '(', ')', '[', ']', '{', '}', '<', '>':
+		return true
+	}
+	return false
+}
+
+// tokenHashes splits lowercased text into candidate tokens and returns the
+// set of their sha256:<hex> patterns. Trailing sentence punctuation is
+// trimmed so "blocked acme.corp." still yields "acme.corp".
+func tokenHashes(lower string) map[string]bool {
+	set := make(map[string]bool)
+	for _, tok := range strings.FieldsFunc(lower, isTokenDelim) {
+		tok = strings.TrimRight(tok, ".!?")
+		if tok == "" {
+			continue
+		}
+		sum := sha256.Sum256([]byte(tok))
+		set[hashPatternPrefix+hex.EncodeToString(sum[:])] = true
+	}
+	return set
+}
+
+func buildHashCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "hash TERM...",
+		Short: "Print sha256: patterns for sensitive terms",
+		Long: `Print a sha256:<hex> pattern for each TERM.
+
+Hashed patterns match whole tokens (split on whitespace, quotes, and
+punctuation like = : , ;) case-insensitively, without revealing the term
+in snag.toml. Paste the output into any [block] list.`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			for _, a := range args {
+				fmt.Fprintln(cmd.OutOrStdout(), hashToken(a))
+			}
+		},
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestHashToken_Normalizes(t *testing.T) {
+	if hashToken("Hunter2") != hashToken("hunter2") {
+		t.Error("hashToken should be case-insensitive")
+	}
+	if !isHashPattern(hashToken("x")) {
+		t.Error("hashToken output should carry the sha256: prefix")
+	}
+}
+
+func TestMatchesPattern_Hashed(t *testing.T) {
+	secret := hashToken("acme.corp")
+
+	tests := []struct {
+		name string
+		text string
+		want bool
+	}{
+		{"bare token", "deploy to acme.corp today", true},
+		{"assignment", `HOST="ACME.CORP"`, true},
+		{"trailing period", "blocked acme.corp.", true},
+		{"substring is not a token", "notacme.corporate", false},
+		{"absent", "nothing here", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p, ok := matchesPattern(tc.text, []string{"todo", secret})
+			if ok != tc.want {
+				t.Fatalf("matchesPattern(%q) = %v, want %v", tc.text, ok, tc.want)
+			}
+			if ok && p != secret {
+				t.Errorf("matched %q, want the hash pattern", p)
+			}
+		})
+	}
+}
+
+func TestHashCmd(t *testing.T) {
+	rootCmd := buildRootCmd()
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"hash", "one", "two"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || lines[0] != hashToken("one") || lines[1] != hashToken("two") {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+}
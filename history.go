@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// historyFile holds one auditRecord per line, appended by snag audit --record.
+const historyFile = "history.jsonl"
+
+// auditRecord summarizes one recorded audit run. Patterns are stored as
+// displayed, so sensitive ones are redacted on disk too.
+type auditRecord struct {
+	Time       time.Time      `json:"time"`
+	Head       string         `json:"head"`
+	Range      string         `json:"range"`
+	Policy     string         `json:"policy"`
+	Scanned    int            `json:"scanned"`
+	Violating  int            `json:"violating"`  // commits with at least one match
+	Violations int            `json:"violations"` // matches
+	Patterns   map[string]int `json:"patterns,omitempty"`
+}
+
+func recordAuditHistory(bc *BlockConfig, rng string, scanned int, reports []commitReport) error {
+	rec := auditRecord{Time: time.Now().UTC(), Range: rng, Scanned: scanned, Violating: len(reports)}
+	if out, err := gitCommand("rev-parse", "--short", "HEAD").Output(); err == nil {
+		rec.Head = strings.TrimSpace(string(out))
+	}
+	rec.Policy, _ = shortPolicyHash(bc)
+	for _, r := range reports {
+		for _, m := range r.Matches {
+			if rec.Patterns == nil {
+				rec.Patterns = map[string]int{}
+			}
+			rec.Patterns[bc.display(m.Pattern)]++
+			rec.Violations++
+		}
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	dir, err := snagStateDir()
+	if err != nil {
+		return err
+	}
+	return appendStateLine(dir, historyFile, line)
+}
+
+// loadAuditHistory returns the recorded runs, oldest first. Lines that
+// don't parse (a torn write, a hand edit) are skipped.
+func loadAuditHistory() ([]auditRecord, error) {
+	dir, err := snagStateDir()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filepath.Join(dir, historyFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var recs []auditRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var rec auditRecord
+		if json.Unmarshal(scanner.Bytes(), &rec) == nil {
+			recs = append(recs, rec)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", historyFile, err)
+	}
+	return recs, nil
+}
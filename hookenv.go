@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+)
+
+// hookEnv is the repository state a check ran against, attached to
+// machine-readable violations so consumers (CI annotations, webhooks,
+// dashboards) have the context without querying git again.
+type hookEnv struct {
+	Branch      string `json:"branch,omitempty"`   // "" on a detached HEAD
+	Upstream    string `json:"upstream,omitempty"` // e.g. origin/main; "" without one
+	StagedFiles int    `json:"staged_files"`
+	ConfigHash  string `json:"config_hash,omitempty"` // as snag config hash prints it
+}
+
+// hookEnvCache memoizes the snapshot per working directory: a check can
+// report several violations, and each lookup costs git calls.
+var hookEnvCache = map[string]hookEnv{}
+
+// currentHookEnv snapshots the repository in the working directory. Parts
+// that can't be read are left empty rather than failing the check.
+func currentHookEnv() hookEnv {
+	cwd, _ := os.Getwd()
+	if env, ok := hookEnvCache[cwd]; ok {
+		return env
+	}
+	env := hookEnv{Branch: currentRepoMeta().Branch}
+	if out, err := gitCommand("rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}").Output(); err == nil {
+		env.Upstream = strings.TrimSpace(string(out))
+	}
+	if out, err := gitCommand("diff", "--cached", "--name-only", "-z").Output(); err == nil {
+		env.StagedFiles = bytes.Count(out, []byte{0})
+	}
+	if bc, err := resolveBlockConfigAt(nil, cwd); err == nil {
+		env.ConfigHash, _ = shortPolicyHash(bc)
+	}
+	hookEnvCache[cwd] = env
+	return env
+}
@@ -4,12 +4,13 @@ import "github.com/spf13/cobra"
 
 // Hook describes a single policy check that snag can run.
 type Hook struct {
-	Name   string                                      // "diff", "msg", "push", "checkout", "prepare", "rebase"
+	Name   string                                      // "diff", "msg", "push", "checkout", "prepare", "rebase", "buffer", "format", "patch", "artifact"
 	Use    string                                      // cobra Use string
 	Short  string                                      // cobra Short description
 	Args   cobra.PositionalArgs                        // nil = no positional args
 	RunE   func(*cobra.Command, []string) error        // the check itself
 	TestFn func(*cobra.Command, string, []string) bool // demo/test scenario
+	Flags  func(*cobra.Command)                        // optional: register command flags
 }
 
 var hooks = []Hook{
@@ -30,22 +31,25 @@ var hooks = []Hook{
 	},
 	{
 		Name:   "push",
-		Use:    "push",
+		Use:    "push [REMOTE] [URL]",
 		Short:  "Check pre-push policies",
+		Args:   cobra.RangeArgs(0, 2),
 		RunE:   runPush,
 		TestFn: testPush,
+		Flags:  pushFlags,
 	},
 	{
 		Name:   "checkout",
-		Use:    "checkout",
-		Short:  "Warn if hooks aren't installed (post-checkout)",
+		Use:    "checkout [PREV NEW FLAG]",
+		Short:  "Warn if hooks aren't installed, plus branch hygiene hints (post-checkout)",
+		Args:   cobra.MaximumNArgs(3),
 		RunE:   runCheckout,
 		TestFn: testCheckout,
 	},
 	{
 		Name:   "prepare",
 		Use:    "prepare FILE [SOURCE] [SHA]",
-		Short:  "Check auto-generated commit message against policies (prepare-commit-msg)",
+		Short:  "Prefix new commit messages and check auto-generated ones (prepare-commit-msg)",
 		Args:   cobra.RangeArgs(1, 3),
 		RunE:   runPrepare,
 		TestFn: testPrepare,
@@ -58,6 +62,47 @@ var hooks = []Hook{
 		RunE:   runRebase,
 		TestFn: testRebase,
 	},
+	{
+		Name:   "buffer",
+		Use:    "buffer --path FILE",
+		Short:  "Check editor buffer content from stdin against diff policies",
+		RunE:   runBuffer,
+		TestFn: testBuffer,
+		Flags:  bufferFlags,
+	},
+	{
+		Name:   "format",
+		Use:    "format [--fix]",
+		Short:  "Check staged text for trailing whitespace, missing final newline, and CRLF",
+		RunE:   runFormat,
+		TestFn: testFormat,
+		Flags:  formatFlags,
+	},
+	{
+		Name:   "license",
+		Use:    "license [--fix]",
+		Short:  "Check newly added files for the [require] license_header",
+		RunE:   runLicense,
+		TestFn: testLicense,
+		Flags:  licenseFlags,
+	},
+	{
+		Name:   "patch",
+		Use:    "patch [FILE|-]",
+		Short:  "Check a mailbox or patch file (format-patch emails, exported diffs) against policies",
+		Args:   cobra.MaximumNArgs(1),
+		RunE:   runPatch,
+		TestFn: testPatch,
+	},
+	{
+		Name:   "artifact",
+		Use:    "artifact PATH...",
+		Short:  "Scan build outputs (files, tarballs, zips, docker save images) for blocked patterns",
+		Args:   cobra.MinimumNArgs(1),
+		RunE:   runArtifact,
+		TestFn: testArtifact,
+		Flags:  artifactFlags,
+	},
 }
 
 // hookNames returns the Name field of every registered hook.
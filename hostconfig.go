@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// [host."GLOB"] sections overlay the file they sit in when the machine's
+// hostname matches GLOB, so one committed snag.toml can relax personal
+// features on CI runners and tighten rules on shared build machines:
+//
+//	[notify]
+//	desktop = true
+//
+//	[host."ci-*"]
+//	notify.desktop = false       # scalars replace the file's value
+//	block.diff = ["staging-key"] # lists are appended to
+//
+// Matching sections apply in glob order before the file is decoded, so
+// every key a file accepts works in a host section and is validated the
+// same way.
+
+// hostSectionKeys can't appear inside a host section.
+var hostSectionKeys = []string{"host", "include", "priority"}
+
+// currentHostname returns the name host sections match against:
+// SNAG_HOSTNAME when set (containers often have random hostnames), else
+// the OS hostname, lowercased.
+func currentHostname() string {
+	if h := os.Getenv("SNAG_HOSTNAME"); h != "" {
+		return strings.ToLower(h)
+	}
+	h, _ := os.Hostname()
+	return strings.ToLower(h)
+}
+
+// hostMatches reports whether glob matches host or its short name (the
+// part before the first dot), case-insensitively.
+func hostMatches(glob, host string) bool {
+	glob = strings.ToLower(glob)
+	short, _, _ := strings.Cut(host, ".")
+	for _, name := range []string{host, short} {
+		if ok, _ := path.Match(glob, name); ok && name != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// applyHostSections returns data with the [host.*] sections matching this
+// machine merged into the top level and all host sections removed, plus
+// the globs that matched. Data without host sections is returned as is.
+func applyHostSections(data []byte, file string) ([]byte, []string, error) {
+	var raw map[string]any
+	if _, err := toml.Decode(string(data), &raw); err != nil {
+		return nil, nil, fmt.Errorf("parsing %s: %w", file, err)
+	}
+	section, ok := raw["host"]
+	if !ok {
+		return data, nil, nil
+	}
+	hosts, ok := section.(map[string]any)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s: host must be a table of [host.\"GLOB\"] sections", file)
+	}
+	delete(raw, "host")
+
+	globs := make([]string, 0, len(hosts))
+	for glob := range hosts {
+		globs = append(globs, glob)
+	}
+	slices.Sort(globs)
+	name := currentHostname()
+	var matched []string
+	for _, glob := range globs {
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, nil, fmt.Errorf("%s: host.%q: bad pattern", file, glob)
+		}
+		overlay, ok := hosts[glob].(map[string]any)
+		if !ok {
+			return nil, nil, fmt.Errorf("%s: host.%q must be a table", file, glob)
+		}
+		for _, key := range hostSectionKeys {
+			if _, set := overlay[key]; set {
+				return nil, nil, fmt.Errorf("%s: host.%q: %s can't be set per host", file, glob, key)
+			}
+		}
+		if hostMatches(glob, name) {
+			mergeHostTable(raw, overlay)
+			matched = append(matched, glob)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(raw); err != nil {
+		return nil, nil, fmt.Errorf("%s: applying host sections: %w", file, err)
+	}
+	return buf.Bytes(), matched, nil
+}
+
+// mergeHostTable merges overlay into base: tables merge key by key, lists
+// are appended to, and anything else replaces the base value.
+func mergeHostTable(base, overlay map[string]any) {
+	for k, v := range overlay {
+		switch ov := v.(type) {
+		case map[string]any:
+			if bv, ok := base[k].(map[string]any); ok {
+				mergeHostTable(bv, ov)
+				continue
+			}
+		case []any:
+			if bv, ok := base[k].([]any); ok {
+				base[k] = append(bv, ov...)
+				continue
+			}
+		case []map[string]any:
+			if bv, ok := base[k].([]map[string]any); ok {
+				base[k] = append(bv, ov...)
+				continue
+			}
+		}
+		base[k] = v
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestHostSections(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "snag.toml")
+	os.WriteFile(path, []byte(`[block]
+diff = ["todo"]
+
+[notify]
+desktop = true
+
+[host."ci-*"]
+notify.desktop = false
+block.diff = ["staging-key"]
+
+[host."build-??"]
+limits.max_warnings = 1
+`), 0644)
+
+	t.Setenv("SNAG_HOSTNAME", "CI-runner-7.example.com")
+	cfg, err := loadSnagTOML(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Notify.Desktop {
+		t.Error("host section should turn notify.desktop off")
+	}
+	if !slices.Equal(cfg.Block.Diff, []string{"todo", "staging-key"}) {
+		t.Errorf("diff = %v, want the host's patterns appended", cfg.Block.Diff)
+	}
+	if !slices.Equal(cfg.Hosts, []string{"ci-*"}) || cfg.Limits.MaxWarnings != 0 {
+		t.Errorf("hosts = %v, max_warnings = %d", cfg.Hosts, cfg.Limits.MaxWarnings)
+	}
+
+	t.Setenv("SNAG_HOSTNAME", "build-01")
+	if cfg, err = loadSnagTOML(path); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Notify.Desktop || cfg.Limits.MaxWarnings != 1 || len(cfg.Block.Diff) != 1 {
+		t.Errorf("build-01: desktop=%v max_warnings=%d diff=%v", cfg.Notify.Desktop, cfg.Limits.MaxWarnings, cfg.Block.Diff)
+	}
+}
+
+func TestHostSectionErrors(t *testing.T) {
+	t.Setenv("SNAG_HOSTNAME", "laptop")
+	for name, tc := range map[string]struct{ content, want string }{
+		"include":    {"[host.laptop]\ninclude = [\"x.toml\"]\n", "include can't be set per host"},
+		"bad glob":   {"[host.\"[ci\"]\nblock.diff = [\"x\"]\n", "bad pattern"},
+		"not table":  {"host = \"laptop\"\n", "must be a table"},
+		"validation": {"[host.laptop]\naudit.limit = -1\n", "audit.limit must be >= 0"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "snag.toml")
+			os.WriteFile(path, []byte(tc.content), 0644)
+			if _, err := loadSnagTOML(path); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("err = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+)
+
+// gitleaksConfig is the subset of a gitleaks v8 config snag can read.
+type gitleaksConfig struct {
+	Rules     []gitleaksRule    `toml:"rules"`
+	Allowlist gitleaksAllowlist `toml:"allowlist"`
+}
+
+type gitleaksRule struct {
+	ID         string              `toml:"id"`
+	Regex      string              `toml:"regex"`
+	Path       string              `toml:"path"`
+	Allowlist  *gitleaksAllowlist  `toml:"allowlist"`
+	Allowlists []gitleaksAllowlist `toml:"allowlists"`
+}
+
+type gitleaksAllowlist struct {
+	Paths   []string `toml:"paths"`
+	Regexes []string `toml:"regexes"`
+}
+
+// importedRule is a gitleaks rule mapped to snag patterns, or the reason
+// it couldn't be.
+type importedRule struct {
+	ID       string
+	Patterns []string
+	Problem  string // non-empty when the rule was not imported
+	Note     string // caveat for an imported rule
+}
+
+// minImportLen drops literals too short to be a useful substring pattern.
+const minImportLen = 3
+
+// maxImportAlternatives caps how many patterns one rule may expand into.
+const maxImportAlternatives = 16
+
+func buildImportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import FILE",
+		Short: "Convert a gitleaks config into snag.toml sections",
+		Long: `Read a gitleaks config and print the snag.toml equivalent.
+
+Rules whose regex is a literal, or a small alternation of literals (with
+optional (?i), \b, and ^/$ anchors), become [block] diff patterns. Global
+allowlist paths of the form \.ext$ become [skip] extensions. Every rule
+that can't be represented is listed on stderr so nothing is dropped
+silently.`,
+		Example: `  snag import .gitleaks.toml
+  snag import .gitleaks.toml -o snag-imported.toml`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE:         runImport,
+	}
+	cmd.Flags().StringP("output", "o", "", "write to FILE instead of stdout")
+	return cmd
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	var cfg gitleaksConfig
+	if _, err := toml.DecodeFile(args[0], &cfg); err != nil {
+		return fmt.Errorf("parsing %s: %w", args[0], err)
+	}
+	if len(cfg.Rules) == 0 {
+		return fmt.Errorf("%s has no [[rules]] — is it a gitleaks config?", args[0])
+	}
+
+	rules := make([]importedRule, len(cfg.Rules))
+	for i, r := range cfg.Rules {
+		rules[i] = importGitleaksRule(r)
+	}
+	exts, dropped := importAllowlistPaths(cfg.Allowlist.Paths)
+	out := renderImport(args[0], rules, exts)
+
+	if path, _ := cmd.Flags().GetString("output"); path != "" {
+		if err := os.WriteFile(path, []byte(out), 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+	} else {
+		fmt.Fprint(cmd.OutOrStdout(), out)
+	}
+
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	if quiet {
+		return nil
+	}
+	imported := 0
+	for _, r := range rules {
+		switch {
+		case r.Problem != "":
+			warnf("rule %s not imported: %s", r.ID, r.Problem)
+		case r.Note != "":
+			imported++
+			hintf("rule %s: %s", r.ID, r.Note)
+		default:
+			imported++
+		}
+	}
+	for _, p := range dropped {
+		warnf("allowlist path %q not imported: only \\.ext$ suffixes map to [skip] extensions", p)
+	}
+	if len(cfg.Allowlist.Regexes) > 0 {
+		warnf("%d allowlist regexes not imported: snag has no line-level allowlist", len(cfg.Allowlist.Regexes))
+	}
+	infof("imported %d of %d rules", imported, len(rules))
+	return nil
+}
+
+// importGitleaksRule maps one rule to literal patterns when its regex is
+// representable.
+func importGitleaksRule(r gitleaksRule) importedRule {
+	out := importedRule{ID: r.ID}
+	if r.Regex == "" {
+		out.Problem = "path-only rule (snag patterns match content)"
+		return out
+	}
+	re, err := syntax.Parse(r.Regex, syntax.Perl)
+	if err != nil {
+		out.Problem = fmt.Sprintf("regex does not parse: %v", err)
+		return out
+	}
+	lits, ok := regexLiterals(re.Simplify())
+	if !ok || len(lits) == 0 {
+		out.Problem = "regex is not a plain literal"
+		return out
+	}
+	if len(lits) > maxImportAlternatives {
+		out.Problem = fmt.Sprintf("regex expands to %d literals (max %d)", len(lits), maxImportAlternatives)
+		return out
+	}
+	for _, l := range lits {
+		if len(l) < minImportLen {
+			out.Problem = fmt.Sprintf("literal %q is too short to match on its own", l)
+			return out
+		}
+		out.Patterns = append(out.Patterns, strings.ToLower(l))
+	}
+
+	var notes []string
+	if !strings.Contains(r.Regex, "(?i") && hasUpper(lits) {
+		notes = append(notes, "now case-insensitive")
+	}
+	if r.Path != "" {
+		notes = append(notes, "path restriction dropped")
+	}
+	if r.Allowlist != nil || len(r.Allowlists) > 0 {
+		notes = append(notes, "per-rule allowlist dropped")
+	}
+	out.Note = strings.Join(notes, "; ")
+	return out
+}
+
+func hasUpper(ss []string) bool {
+	for _, s := range ss {
+		if strings.ToLower(s) != s {
+			return true
+		}
+	}
+	return false
+}
+
+// regexLiterals returns the finite set of strings re matches, ignoring
+// zero-width assertions, or false when re matches anything open-ended.
+func regexLiterals(re *syntax.Regexp) ([]string, bool) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return []string{string(re.Rune)}, true
+	case syntax.OpEmptyMatch, syntax.OpWordBoundary, syntax.OpBeginLine, syntax.OpEndLine,
+		syntax.OpBeginText, syntax.OpEndText:
+		return []string{""}, true
+	case syntax.OpCapture:
+		return regexLiterals(re.Sub[0])
+	case syntax.OpCharClass:
+		if r, ok := caseFoldedRune(re.Rune); ok {
+			return []string{string(r)}, true
+		}
+		return nil, false
+	case syntax.OpAlternate:
+		var out []string
+		for _, sub := range re.Sub {
+			lits, ok := regexLiterals(sub)
+			if !ok {
+				return nil, false
+			}
+			out = append(out, lits...)
+		}
+		return out, true
+	case syntax.OpConcat:
+		out := []string{""}
+		for _, sub := range re.Sub {
+			lits, ok := regexLiterals(sub)
+			if !ok {
+				return nil, false
+			}
+			var next []string
+			for _, prefix := range out {
+				for _, l := range lits {
+					next = append(next, prefix+l)
+				}
+			}
+			if len(next) > maxImportAlternatives*4 {
+				return nil, false
+			}
+			out = next
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+// caseFoldedRune reports whether a char class's ranges are exactly one
+// letter in its case variants, as (?i)a compiles to [Aa].
+func caseFoldedRune(ranges []rune) (rune, bool) {
+	var runes []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		if ranges[i+1]-ranges[i] > 3 {
+			return 0, false
+		}
+		for r := ranges[i]; r <= ranges[i+1]; r++ {
+			runes = append(runes, r)
+		}
+	}
+	if len(runes) == 0 {
+		return 0, false
+	}
+	base := unicode.ToLower(runes[0])
+	for _, r := range runes {
+		if unicode.ToLower(r) != base {
+			return 0, false
+		}
+	}
+	return base, true
+}
+
+// importAllowlistPaths maps \.ext$ path regexes to [skip] extensions.
+func importAllowlistPaths(paths []string) (exts, dropped []string) {
+	for _, p := range paths {
+		re, err := syntax.Parse(p, syntax.Perl)
+		if err == nil {
+			re = re.Simplify()
+			if re.Op == syntax.OpConcat && len(re.Sub) == 2 &&
+				re.Sub[0].Op == syntax.OpLiteral && re.Sub[1].Op == syntax.OpEndText &&
+				strings.HasPrefix(string(re.Sub[0].Rune), ".") {
+				exts = append(exts, string(re.Sub[0].Rune))
+				continue
+			}
+		}
+		dropped = append(dropped, p)
+	}
+	return exts, dropped
+}
+
+func renderImport(source string, rules []importedRule, exts []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Imported from %s by snag import.\n", source)
+	b.WriteString("[block]\ndiff = [\n")
+	for _, r := range rules {
+		if r.Problem != "" {
+			continue
+		}
+		for _, p := range r.Patterns {
+			fmt.Fprintf(&b, "  %q, # %s\n", p, r.ID)
+		}
+	}
+	b.WriteString("]\n")
+	if len(exts) > 0 {
+		fmt.Fprintf(&b, "\n[skip]\nextensions = [%s]\n", quotedList(exts))
+	}
+	return b.String()
+}
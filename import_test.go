@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestImportGitleaksRule(t *testing.T) {
+	tests := []struct {
+		regex    string
+		want     []string
+		problem  bool
+		wantNote string
+	}{
+		{`internal\.corp\.com`, []string{"internal.corp.com"}, false, ""},
+		{`(?i)\b(acme-token|acme-key)\b`, []string{"acme-token", "acme-key"}, false, ""},
+		{`(?i)do not (merge|ship)`, []string{"do not merge", "do not ship"}, false, ""},
+		{`HACK`, []string{"hack"}, false, "now case-insensitive"},
+		{`ghp_[0-9a-zA-Z]{36}`, nil, true, ""},
+		{`(?i)ab`, nil, true, ""},
+	}
+	for _, tt := range tests {
+		got := importGitleaksRule(gitleaksRule{ID: "r", Regex: tt.regex})
+		if (got.Problem != "") != tt.problem {
+			t.Errorf("%s: problem = %q, want problem=%v", tt.regex, got.Problem, tt.problem)
+			continue
+		}
+		if !slices.Equal(got.Patterns, tt.want) || got.Note != tt.wantNote {
+			t.Errorf("%s: got %v (note %q), want %v (note %q)", tt.regex, got.Patterns, got.Note, tt.want, tt.wantNote)
+		}
+	}
+
+	if got := importGitleaksRule(gitleaksRule{ID: "p", Path: `\.pem$`}); got.Problem == "" {
+		t.Error("path-only rule should not import")
+	}
+}
+
+func TestImportAllowlistPaths(t *testing.T) {
+	exts, dropped := importAllowlistPaths([]string{`\.min\.js$`, `(.*?)(jpg|gif)$`, `vendor/`})
+	if !slices.Equal(exts, []string{".min.js"}) || len(dropped) != 2 {
+		t.Errorf("exts = %v, dropped = %v", exts, dropped)
+	}
+}
+
+func TestImport_RoundTripsThroughSnagConfig(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, ".gitleaks.toml")
+	os.WriteFile(src, []byte(`title = "org"
+
+[[rules]]
+id = "corp-host"
+regex = '''(?i)internal\.corp\.com'''
+keywords = ["internal.corp.com"]
+
+[[rules]]
+id = "github-pat"
+regex = '''ghp_[0-9a-zA-Z]{36}'''
+
+[allowlist]
+paths = ['''\.lock$''']
+`), 0644)
+
+	var out bytes.Buffer
+	rootCmd := buildRootCmd()
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"import", src, "-q"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+
+	var cfg snagTOML
+	if _, err := toml.Decode(out.String(), &cfg); err != nil {
+		t.Fatalf("output is not a valid snag.toml: %v\n%s", err, out.String())
+	}
+	if !slices.Equal(cfg.Block.Diff, []string{"internal.corp.com"}) || !slices.Equal(cfg.Skip.Extensions, []string{".lock"}) {
+		t.Errorf("imported config = %+v", cfg)
+	}
+	if !strings.Contains(out.String(), "# corp-host") {
+		t.Errorf("patterns should be annotated with rule ids:\n%s", out.String())
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+)
+
+// inclusiveTerm is one entry of the "inclusive" detector's word list: a
+// word or phrase to avoid and what to write instead ("" = just reword).
+// The list ships built in, arrives in the "inclusive" rule pack, and is
+// extended or trimmed per repo under [detect.inclusive].
+type inclusiveTerm struct {
+	Term    string `json:"term"`
+	Suggest string `json:"suggest,omitempty"`
+}
+
+const inclusiveDetector = "inclusive"
+
+// inclusiveOn reports whether [detect.inclusive] is enabled; it is off by default.
+func (bc *BlockConfig) inclusiveOn() bool {
+	return bc.DetectEnabled[inclusiveDetector]
+}
+
+var builtinInclusiveTerms = []inclusiveTerm{
+	{"whitelist", "allowlist"},
+	{"blacklist", "denylist"},
+	{"slave", "replica"},
+	{"sanity check", "quick check"},
+	{"dummy value", "placeholder value"},
+	{"grandfathered", "legacy"},
+	{"man hours", "person hours"},
+	{"manpower", "workforce"},
+	{"blackhat", "malicious"},
+	{"whitehat", "ethical"},
+	{"cripple", "degrade"},
+	{"wtf", ""},
+	{"fuck", ""},
+	{"shit", ""},
+}
+
+// inclusiveSuffixes are the endings a term may carry and still match:
+// "whitelisted", "slaves", "blacklisting".
+var inclusiveSuffixes = []string{"ing", "ed", "es", "s", ""}
+
+var (
+	inclusiveREMu sync.Mutex
+	inclusiveREs  = map[string]*regexp.Regexp{}
+)
+
+// inclusiveRE compiles term case-insensitively, with the words of a phrase
+// joined by nothing, a space, "_" or "-" so that "sanity check" also finds
+// sanityCheck and sanity_check.
+func inclusiveRE(term string) *regexp.Regexp {
+	inclusiveREMu.Lock()
+	defer inclusiveREMu.Unlock()
+	if re, ok := inclusiveREs[term]; ok {
+		return re
+	}
+	words := strings.Fields(term)
+	for i, w := range words {
+		words[i] = regexp.QuoteMeta(w)
+	}
+	re := regexp.MustCompile(`(?i)` + strings.Join(words, `[\s_-]?`))
+	inclusiveREs[term] = re
+	return re
+}
+
+// inclusiveTerms returns the word list in effect for bc, longest term
+// first so a phrase wins over a word inside it: config terms override the
+// rule pack's, which override the built-in ones, and allow drops terms.
+func (bc *BlockConfig) inclusiveTerms() []inclusiveTerm {
+	if bc.inclusive != nil {
+		return bc.inclusive
+	}
+	merged := map[string]string{}
+	for _, t := range builtinInclusiveTerms {
+		merged[strings.ToLower(t.Term)] = t.Suggest
+	}
+	if pack, ok := installedRulePack(inclusiveDetector); ok {
+		for _, t := range pack.Terms {
+			merged[strings.ToLower(t.Term)] = t.Suggest
+		}
+	}
+	for term, suggest := range bc.InclusiveTerms {
+		merged[strings.ToLower(term)] = suggest
+	}
+	for _, term := range bc.InclusiveAllow {
+		delete(merged, strings.ToLower(term))
+	}
+	terms := make([]inclusiveTerm, 0, len(merged))
+	for term, suggest := range merged {
+		terms = append(terms, inclusiveTerm{term, suggest})
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if len(terms[i].Term) != len(terms[j].Term) {
+			return len(terms[i].Term) > len(terms[j].Term)
+		}
+		return terms[i].Term < terms[j].Term
+	})
+	bc.inclusive = terms
+	return terms
+}
+
+// wordBoundary reports whether a match may start at s[i:] or end at s[:i]:
+// at the string's edge, next to a non-letter, or at a camelCase hump
+// (loadWhitelist, and after an acronym, IPWhitelist).
+func wordBoundary(s string, i int) bool {
+	if i <= 0 || i >= len(s) {
+		return true
+	}
+	before, _ := utf8.DecodeLastRuneInString(s[:i])
+	after, n := utf8.DecodeRuneInString(s[i:])
+	next, _ := utf8.DecodeRuneInString(s[i+n:])
+	return !unicode.IsLetter(before) || !unicode.IsLetter(after) ||
+		unicode.IsLower(before) && unicode.IsUpper(after) ||
+		unicode.IsUpper(before) && unicode.IsUpper(after) && unicode.IsLower(next)
+}
+
+// checkInclusive is the "inclusive" detector. The match names the
+// suggested alternative, so the violation itself says what to write.
+func checkInclusive(bc *BlockConfig, _, line string) (string, int, bool) {
+	for _, t := range bc.inclusiveTerms() {
+		for _, loc := range inclusiveRE(t.Term).FindAllStringIndex(line, -1) {
+			if !wordBoundary(line, loc[0]) {
+				continue
+			}
+			for _, suffix := range inclusiveSuffixes {
+				end := loc[1] + len(suffix)
+				if end > len(line) || !strings.EqualFold(line[loc[1]:end], suffix) || !wordBoundary(line, end) {
+					continue
+				}
+				if t.Suggest == "" {
+					return line[loc[0]:end] + " (reword)", loc[0] + 1, true
+				}
+				return fmt.Sprintf("%s (try %s)", line[loc[0]:end], t.Suggest), loc[0] + 1, true
+			}
+		}
+	}
+	return "", 0, false
+}
+
+// checkMsgInclusive runs the inclusive detector over the commit message
+// when it is enabled. Comment lines are skipped; trailers are checked.
+func checkMsgInclusive(cmd *cobra.Command, bc *BlockConfig, path string, lines []string) error {
+	if !bc.inclusiveOn() {
+		return nil
+	}
+	d := findDetector(inclusiveDetector)
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		match, col, ok := checkInclusive(bc, "", line)
+		if !ok {
+			continue
+		}
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		if !quiet {
+			if problemOutput(cmd) {
+				problemf(d.ID, path, i+1, col, "%s %q in commit message", d.Summary, match)
+			} else {
+				blockf(d.ID, "%s %q in commit message", d.Summary, match)
+				bell()
+				hintf("to recover: git commit -eF .git/COMMIT_EDITMSG")
+				hintf("to allow the term: [detect.inclusive] allow = [...]")
+			}
+		}
+		return violationf(d.ID, "%s %q found in commit message", d.Summary, match)
+	}
+	return nil
+}
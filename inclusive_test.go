@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckInclusive(t *testing.T) {
+	cachedPackState = &packState{Packs: []rulePack{{Name: "inclusive", Version: 1, Terms: []inclusiveTerm{{"guys", "folks"}}}}}
+	defer func() { cachedPackState = nil }()
+	bc := &BlockConfig{
+		InclusiveTerms: map[string]string{"master": "main"},
+		InclusiveAllow: []string{"Cripple"},
+	}
+	cases := []struct {
+		line string
+		want string // "" = no match
+	}{
+		{"add the host to the whitelist", "whitelist (try allowlist)"},
+		{"ips := loadIPWhitelist()", "Whitelist (try allowlist)"},
+		{"BLACKLISTED_HOSTS = []", "BLACKLISTED (try denylist)"},
+		{"replicate to the slaves", "slaves (try replica)"},
+		{"run a sanity_check first", "sanity_check (try quick check)"},
+		{"git push origin master", "master (try main)"},
+		{"wtf is this", "wtf (reword)"},
+		{"thanks guys", "guys (try folks)"}, // from the installed pack
+		{"don't cripple the cache", ""},     // allowed by config
+		{"enslaved, mastery, shiitake", ""},
+	}
+	for _, c := range cases {
+		match, col, ok := checkInclusive(bc, "x.go", c.line)
+		if ok != (c.want != "") || match != c.want {
+			t.Errorf("%q: got %q, %v; want %q", c.line, match, ok, c.want)
+		}
+		if ok && !strings.HasPrefix(strings.ToLower(c.line[col-1:]), strings.ToLower(strings.Fields(c.want)[0])) {
+			t.Errorf("%q: col %d doesn't point at the term", c.line, col)
+		}
+	}
+}
+
+func TestInclusive_DiffAndMsg(t *testing.T) {
+	cachedPackState = &packState{}
+	defer func() { cachedPackState = nil }()
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte(`[detect.inclusive]
+enabled = true
+terms = { "dummy" = "placeholder" }
+`), 0644)
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+	run := func(args ...string) error {
+		rootCmd := buildRootCmd()
+		rootCmd.SetArgs(append(args, "-q"))
+		return rootCmd.Execute()
+	}
+
+	stageFile(t, dir, "hosts.txt", "blacklist = 10.0.0.1\n")
+	if err := run("check", "diff"); err == nil || !strings.Contains(err.Error(), `"blacklist (try denylist)"`) {
+		t.Errorf("diff: err = %v", err)
+	}
+
+	msg := filepath.Join(dir, "COMMIT_EDITMSG")
+	os.WriteFile(msg, []byte("Replace the dummy user in fixtures\n"), 0644)
+	if err := run("check", "msg", msg); err == nil || !strings.Contains(err.Error(), idInclusiveTerm) {
+		t.Errorf("msg: err = %v", err)
+	}
+	os.WriteFile(msg, []byte("Replace the placeholder user in fixtures\n"), 0644)
+	if err := run("check", "msg", msg); err != nil {
+		t.Errorf("clean msg: %v", err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[detect.debug]\nallow = [\"x\"]\n"), 0644)
+	if err := run("check", "msg", msg); err == nil || !strings.Contains(err.Error(), "apply only to detect.inclusive") {
+		t.Errorf("allow on another detector: err = %v", err)
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 )
@@ -41,13 +44,31 @@ const minVersionForInit = "0.10.0"
 
 func buildInitCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:          "init",
-		Short:        "Generate a starter snag.toml in the current directory",
+		Use:   "init",
+		Short: "Generate a starter snag.toml in the current directory",
+		Long: `Generate a starter snag.toml in the current directory.
+
+--template NAME bootstraps a new repository in one step. It writes a
+template's files: typically snag.toml, lefthook.yml with hook stubs, and
+.snagignore. Templates come from a templates repository (--from, or
+SNAG_TEMPLATES) and from the built-in set. The templates repository is a
+local directory or a git URL, with one top-level directory per template. It
+is searched first, so an organization can replace a built-in template.
+The template's snag.toml and .snagignore are validated, and nothing is
+written if any of its files already exist (unless --force).`,
+		Example: `  snag init
+  snag init --template strict
+  snag init --template org-default --from git@github.com:acme/snag-templates.git
+  SNAG_TEMPLATES=~/src/snag-templates snag init --list-templates`,
+		Args:         cobra.NoArgs,
 		SilenceUsage: true,
 		RunE:         runInit,
 	}
 	cmd.Flags().Bool("force", false, "overwrite existing config file")
 	cmd.Flags().Bool("local", false, "generate snag-local.toml (gitignored, personal patterns)")
+	cmd.Flags().String("template", "", "write the named template's files")
+	cmd.Flags().String("from", "", "templates repository: directory or git URL (default $SNAG_TEMPLATES)")
+	cmd.Flags().Bool("list-templates", false, "list the available templates")
 	return cmd
 }
 
@@ -60,7 +81,18 @@ func runInit(cmd *cobra.Command, args []string) error {
 	local, _ := cmd.Flags().GetBool("local")
 	force, _ := cmd.Flags().GetBool("force")
 	quiet, _ := cmd.Flags().GetBool("quiet")
+	template, _ := cmd.Flags().GetString("template")
+	from, _ := cmd.Flags().GetString("from")
 
+	if list, _ := cmd.Flags().GetBool("list-templates"); list {
+		return listTemplates(cmd, templateSource(from))
+	}
+	if template != "" {
+		if local {
+			return fmt.Errorf("--template and --local are mutually exclusive")
+		}
+		return initFromTemplate(dir, template, templateSource(from), force, quiet)
+	}
 	if local {
 		return initLocal(dir, force, quiet)
 	}
@@ -100,3 +132,42 @@ func initLocal(dir string, force, quiet bool) error {
 	}
 	return nil
 }
+
+func initFromTemplate(dir, name, source string, force, quiet bool) error {
+	files, origin, err := loadTemplate(name, source)
+	if err != nil {
+		return err
+	}
+	if err := validateTemplate(files); err != nil {
+		return err
+	}
+	written, err := writeTemplate(dir, files, force)
+	if err != nil {
+		return err
+	}
+	if !quiet {
+		infof("created %s from template %s (%s)", strings.Join(written, ", "), name, origin)
+		if slices.Contains(written, "lefthook.yml") {
+			hintf("run lefthook install to activate the hooks")
+		}
+	}
+	return nil
+}
+
+func listTemplates(cmd *cobra.Command, source string) error {
+	tw := tabwriter.NewWriter(cmd.OutOrStdout(), 2, 4, 2, ' ', 0)
+	if source != "" {
+		dir, cleanup, err := openTemplateSource(source)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		for _, name := range sourceTemplates(dir) {
+			fmt.Fprintf(tw, "%s\t%s\n", name, source)
+		}
+	}
+	for _, t := range builtinTemplates {
+		fmt.Fprintf(tw, "%s\tbuilt-in: %s\n", t.Name, t.Summary)
+	}
+	return tw.Flush()
+}
@@ -194,3 +194,81 @@ func TestCheckMinVersion(t *testing.T) {
 		}
 	})
 }
+
+func TestRunInitTemplate(t *testing.T) {
+	source := t.TempDir()
+	org := filepath.Join(source, "org-default")
+	os.MkdirAll(filepath.Join(org, "policies"), 0755)
+	os.WriteFile(filepath.Join(org, "snag.toml"), []byte("include = [\"policies/secrets.toml\"]\n"), 0644)
+	os.WriteFile(filepath.Join(org, "policies", "secrets.toml"), []byte("[block]\ndiff = [\"acme-internal\"]\n"), 0644)
+	os.WriteFile(filepath.Join(org, ".snagignore"), []byte("# fixtures\nacme-internal testdata/**\n"), 0644)
+	broken := filepath.Join(source, "broken")
+	os.MkdirAll(broken, 0755)
+	os.WriteFile(filepath.Join(broken, "snag.toml"), []byte("[block]\ndiff = \"not a list\"\n"), 0644)
+
+	run := func(dir string, args ...string) error {
+		orig, _ := os.Getwd()
+		os.Chdir(dir)
+		defer os.Chdir(orig)
+		cmd := buildInitCmd()
+		cmd.PersistentFlags().BoolP("quiet", "q", true, "")
+		cmd.SetArgs(args)
+		return cmd.Execute()
+	}
+
+	t.Run("from a templates directory", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := run(dir, "--template", "org-default", "--from", source); err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{"snag.toml", "policies/secrets.toml", ".snagignore"} {
+			if !fileExists(filepath.Join(dir, name)) {
+				t.Errorf("%s not written", name)
+			}
+		}
+		bc, _, err := walkConfig(dir)
+		if err != nil || len(bc.Diff) != 1 || bc.Diff[0] != "acme-internal" {
+			t.Errorf("written config: diff=%v err=%v", bc.Diff, err)
+		}
+	})
+
+	t.Run("built-in via SNAG_TEMPLATES fallback", func(t *testing.T) {
+		t.Setenv("SNAG_TEMPLATES", source)
+		dir := t.TempDir()
+		if err := run(dir, "--template", "strict"); err != nil {
+			t.Fatal(err)
+		}
+		data, _ := os.ReadFile(filepath.Join(dir, "lefthook.yml"))
+		if !strings.Contains(string(data), snagRemoteURL) || !strings.Contains(string(data), "pre-push:") {
+			t.Errorf("lefthook.yml = %q, want the snag remote and hook stubs", data)
+		}
+		if _, err := loadSnagTOML(filepath.Join(dir, "snag.toml")); err != nil {
+			t.Errorf("strict snag.toml doesn't load: %v", err)
+		}
+	})
+
+	t.Run("refuses before writing anything", func(t *testing.T) {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, ".snagignore"), []byte("keep\n"), 0644)
+		err := run(dir, "--template", "default")
+		if err == nil || !strings.Contains(err.Error(), ".snagignore") {
+			t.Fatalf("err = %v, want a refusal naming .snagignore", err)
+		}
+		if fileExists(filepath.Join(dir, "snag.toml")) {
+			t.Error("snag.toml written despite the conflict")
+		}
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := run(dir, "--template", "broken", "--from", source); err == nil {
+			t.Error("expected the broken snag.toml to be rejected")
+		}
+		if fileExists(filepath.Join(dir, "snag.toml")) {
+			t.Error("broken template was written")
+		}
+		if err := run(dir, "--template", "missing", "--from", source); err == nil || !strings.Contains(err.Error(), "org-default") {
+			t.Errorf("err = %v, want the available templates listed", err)
+		}
+	})
+}
@@ -210,7 +210,7 @@ func installOrUpdateSnagRemote(filename string, createIfMissing bool, dryRun boo
 	}
 
 	if existingRef == ref {
-		fmt.Fprintf(os.Stderr, "snag remote already configured at %s in %s — no changes needed\n", ref, filename)
+		fprintPlain(os.Stderr, "snag remote already configured at %s in %s — no changes needed\n", ref, filename)
 		return "", nil
 	}
 
@@ -240,8 +240,8 @@ var isTTY = func() bool {
 // Returns "shared" or "local".
 var promptForConfigTarget = func() (string, error) {
 	fmt.Fprintln(os.Stderr, "Where should snag hooks be installed?")
-	fmt.Fprintln(os.Stderr, "  1) Shared config (lefthook.yml) — checked in, whole team gets it")
-	fmt.Fprintln(os.Stderr, "  2) Local config (lefthook-local.yml) — gitignored, just for you")
+	fprintPlain(os.Stderr, "  1) Shared config (lefthook.yml) — checked in, whole team gets it\n")
+	fprintPlain(os.Stderr, "  2) Local config (lefthook-local.yml) — gitignored, just for you\n")
 	fmt.Fprint(os.Stderr, "Choice [1/2]: ")
 
 	scanner := bufio.NewScanner(os.Stdin)
@@ -262,13 +262,36 @@ func runInstallHooks(cmd *cobra.Command, args []string) error {
 	useLocal, _ := cmd.Flags().GetBool("local")
 	useShared, _ := cmd.Flags().GetBool("shared")
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	chain, _ := cmd.Flags().GetBool("chain")
+	remove, _ := cmd.Flags().GetBool("remove")
 
 	if useLocal && useShared {
 		return fmt.Errorf("--local and --shared are mutually exclusive")
 	}
+	if remove && !chain {
+		return fmt.Errorf("--remove only applies to --chain")
+	}
+	if chain {
+		if err := runChainInstall(cmd, remove, dryRun); err != nil || remove || dryRun {
+			return err
+		}
+		runPostInstallAudit(cmd)
+		return nil
+	}
 
 	sharedFile, sharedErr := findLefthookConfig()
 	localFile, _ := findLefthookLocalConfig()
+	if sharedErr != nil {
+		var names []string
+		for _, o := range detectHookOwners() {
+			if o.Name != "lefthook" {
+				names = append(names, o.Name)
+			}
+		}
+		if len(names) > 0 {
+			sharedErr = fmt.Errorf("%w\n  hooks here are managed by %s; to add snag to them instead: snag install --chain", sharedErr, strings.Join(names, ", "))
+		}
+	}
 
 	// Check for existing snag remotes in both configs.
 	sharedHasSnag := false
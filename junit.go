@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// JUnit XML as read by Jenkins' junit step and most CI dashboards: every
+// scanned commit is a test case, and each violation is a failed case of
+// its own, so clean runs still report passing tests.
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitCase     `xml:"testcase"`
+}
+
+// junitProperty carries the hookEnv snapshot, as snag.branch and so on.
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// writeJUnit writes one suite covering shas. Commits without findings pass;
+// each finding is a failed case named for its commit and location.
+func writeJUnit(w io.Writer, suite string, shas []string, findings []ciFinding) error {
+	bySHA := map[string][]ciFinding{}
+	for _, f := range findings {
+		bySHA[f.SHA] = append(bySHA[f.SHA], f)
+	}
+	s := junitSuite{Name: suite, Timestamp: time.Now().UTC().Format("2006-01-02T15:04:05")}
+	env := currentHookEnv()
+	for _, p := range []junitProperty{
+		{"snag.branch", env.Branch},
+		{"snag.upstream", env.Upstream},
+		{"snag.staged_files", strconv.Itoa(env.StagedFiles)},
+		{"snag.config_hash", env.ConfigHash},
+	} {
+		if p.Value != "" {
+			s.Properties = append(s.Properties, p)
+		}
+	}
+	// rev-list order is newest first; report history order.
+	for i := len(shas) - 1; i >= 0; i-- {
+		sha := shas[i]
+		short := sha[:min(len(sha), 7)]
+		if len(bySHA[sha]) == 0 {
+			s.Cases = append(s.Cases, junitCase{ClassName: "snag.commit", Name: short})
+			continue
+		}
+		for _, f := range bySHA[sha] {
+			where := "message"
+			if f.Kind == "diff" {
+				where = fmt.Sprintf("%s:%d", f.Path, f.Line)
+			}
+			s.Cases = append(s.Cases, junitCase{
+				ClassName: "snag." + f.Kind,
+				Name:      short + " " + where,
+				Failure: &junitFailure{
+					Message: fmt.Sprintf("blocked pattern %q", f.Pattern),
+					Type:    f.CheckID(),
+					Body:    f.Message(),
+				},
+			})
+			s.Failures++
+		}
+	}
+	s.Tests = len(s.Cases)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitSuites{Suites: []junitSuite{s}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
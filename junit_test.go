@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAuditJUnit(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"todo\"]\nmsg = [\"wip\"]\n"), 0644)
+	initialCommit(t, dir)
+	commitFile(t, dir, "a.go", "package a\n\n// TODO: fix\n", "wip: a")
+	commitFile(t, dir, "b.go", "package b\n", "Add b")
+
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+	os.Chdir(dir)
+
+	var out bytes.Buffer
+	rootCmd := buildRootCmd()
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"audit", "HEAD~2..HEAD", "--format", "junit", "-q"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Fatal("expected violations to fail the audit")
+	}
+
+	var suites junitSuites
+	if err := xml.Unmarshal(out.Bytes(), &suites); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, out.String())
+	}
+	if len(suites.Suites) != 1 {
+		t.Fatalf("got %d suites", len(suites.Suites))
+	}
+	s := suites.Suites[0]
+	if s.Tests != 3 || s.Failures != 2 {
+		t.Errorf("tests=%d failures=%d, want 3 and 2:\n%s", s.Tests, s.Failures, out.String())
+	}
+	var names []string
+	for _, c := range s.Cases[:2] {
+		if c.Failure == nil {
+			t.Errorf("case %q should fail", c.Name)
+		}
+		names = append(names, c.ClassName+" "+c.Name)
+	}
+	if got := strings.Join(names, "\n"); !strings.Contains(got, "snag.diff") || !strings.Contains(got, "a.go:3") || !strings.Contains(got, "snag.msg") {
+		t.Errorf("failed cases = %q, want the diff and msg violations", names)
+	}
+	if s.Cases[2].Failure != nil || s.Cases[2].ClassName != "snag.commit" {
+		t.Errorf("last case = %+v, want a passing commit", s.Cases[2])
+	}
+	props := map[string]string{}
+	for _, p := range s.Properties {
+		props[p.Name] = p.Value
+	}
+	if props["snag.branch"] == "" || len(props["snag.config_hash"]) != 12 || props["snag.staged_files"] != "0" {
+		t.Errorf("properties = %v, want the branch, config hash and staged count", props)
+	}
+}
+
+func TestCIJUnitNeedsReportFile(t *testing.T) {
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"ci", "--report", "azure", "--format", "junit", "-q"})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "-o") {
+		t.Errorf("err = %v, want a stdout conflict", err)
+	}
+}
+
+func TestJUnitFormatOnlyForAuditAndCI(t *testing.T) {
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"check", "diff", "--format", "junit", "-q"})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "only supported") {
+		t.Errorf("err = %v", err)
+	}
+}
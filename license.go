@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// [require] license_header makes newly added files matching paths start
+// with the header in template. The template is plain text, commented for
+// each file by licenseCommentPrefixes, or already commented, in which case
+// it is used as is. {{year}} in the template matches any year when
+// checking and becomes the current year when --fix inserts it.
+
+func licenseFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("fix", false, "insert the header into offending files and restage them")
+}
+
+// licenseOn reports whether [require] license_header is configured.
+func (bc *BlockConfig) licenseOn() bool {
+	return len(bc.LicensePaths) > 0 && bc.LicenseTemplate != ""
+}
+
+// licenseYear stands for the copyright year in a header template.
+const licenseYear = "{{year}}"
+
+// licenseCommentPrefixes is the line comment used for a plain-text template
+// in files with each extension.
+var licenseCommentPrefixes = map[string]string{
+	".go": "//", ".js": "//", ".mjs": "//", ".cjs": "//", ".ts": "//", ".tsx": "//", ".jsx": "//",
+	".java": "//", ".kt": "//", ".scala": "//", ".swift": "//", ".dart": "//", ".rs": "//",
+	".c": "//", ".h": "//", ".cc": "//", ".cpp": "//", ".hpp": "//", ".cs": "//", ".proto": "//", ".php": "//",
+	".py": "#", ".sh": "#", ".bash": "#", ".zsh": "#", ".rb": "#", ".pl": "#", ".r": "#",
+	".yaml": "#", ".yml": "#", ".toml": "#", ".tf": "#", ".ex": "#", ".exs": "#", ".ps1": "#",
+	".sql": "--", ".lua": "--", ".hs": "--",
+}
+
+// licenseCommentMarker matches the comment syntax around a header line, so
+// headers compare by their text whatever the comment style.
+var licenseCommentMarker = regexp.MustCompile(`^\s*(//+|#+|--|/\*+|\*+/|\*|;+|<!--|-->)?\s*|\s*(\*/|-->)\s*$`)
+
+// licenseText strips comment markers and blank lines from text.
+func licenseText(text string) []string {
+	var out []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = licenseCommentMarker.ReplaceAllString(strings.TrimRight(line, "\r"), ""); line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// hasLicenseHeader reports whether the header text appears near the top of
+// content, after any shebang, build tags or other preamble.
+func hasLicenseHeader(content, template string) bool {
+	want := licenseText(template)
+	if len(want) == 0 {
+		return true
+	}
+	for i, w := range want {
+		w = regexp.QuoteMeta(w)
+		want[i] = strings.ReplaceAll(w, regexp.QuoteMeta(licenseYear), `\d{4}(?:\s*[-,]\s*\d{4})*`)
+	}
+	re := regexp.MustCompile(`(?m)^` + strings.Join(want, `\n`) + `$`)
+	head := licenseText(content)
+	if n := len(want) + 10; len(head) > n {
+		head = head[:n]
+	}
+	return re.MatchString(strings.Join(head, "\n"))
+}
+
+// commentedHeader renders template as a comment block for the file at p.
+// A template whose first line is already a comment is used unchanged.
+func commentedHeader(template, p string, year int) (string, error) {
+	text := strings.TrimRight(strings.ReplaceAll(template, licenseYear, strconv.Itoa(year)), "\n")
+	first := strings.TrimSpace(strings.SplitN(text, "\n", 2)[0])
+	if licenseCommentMarker.FindString(first) != "" {
+		return text + "\n", nil
+	}
+	prefix, ok := licenseCommentPrefixes[strings.ToLower(path.Ext(p))]
+	if !ok {
+		return "", fmt.Errorf("%s: no comment style known for %q files; write the comment markers into the template", p, path.Ext(p))
+	}
+	var b strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		b.WriteString(strings.TrimRight(prefix+" "+line, " ") + "\n")
+	}
+	return b.String(), nil
+}
+
+// insertLicenseHeader puts header at the top of content, after a shebang
+// line, followed by a blank line.
+func insertLicenseHeader(content, header string) string {
+	if strings.HasPrefix(content, "#!") {
+		line, rest, _ := strings.Cut(content, "\n")
+		return line + "\n" + header + "\n" + rest
+	}
+	return header + "\n" + content
+}
+
+func runLicense(cmd *cobra.Command, args []string) error {
+	bc, err := resolveBlockConfig(cmd)
+	if err != nil {
+		return err
+	}
+	if !bc.licenseOn() {
+		return nil
+	}
+	fix, _ := cmd.Flags().GetBool("fix")
+	return checkLicenseHeaders(cmd, bc, fix)
+}
+
+// checkLicenseHeaders checks the staged content of newly added files
+// matching [require] license_header paths. With fix, the header is
+// inserted and the file restaged instead of failing.
+func checkLicenseHeaders(cmd *cobra.Command, bc *BlockConfig, fix bool) error {
+	data, err := os.ReadFile(bc.LicenseTemplate)
+	if err != nil {
+		return fmt.Errorf("[require] license_header template: %w", err)
+	}
+	template := string(data)
+	files, err := stagedFilesFiltered("A")
+	if err != nil {
+		return err
+	}
+
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	var bad []string
+	for _, f := range files {
+		if f.Mode == "120000" || f.Mode == "160000" || !matchTreeGlob(bc.LicensePaths, f.Path) || matchTreeGlob(bc.LicenseExclude, f.Path) {
+			continue
+		}
+		staged, err := gitCommand("show", ":"+f.Path).Output()
+		if err != nil {
+			return fmt.Errorf("git show :%s: %w", f.Path, err)
+		}
+		if hasLicenseHeader(string(staged), template) {
+			continue
+		}
+		if fix {
+			header, err := commentedHeader(template, f.Path, now().Year())
+			if err != nil {
+				return err
+			}
+			if err := restageFile(f, string(staged), insertLicenseHeader(string(staged), header)); err != nil {
+				return err
+			}
+			if !quiet {
+				infof("added license header to %s", f.Path)
+			}
+			continue
+		}
+		bad = append(bad, f.Path)
+		if quiet {
+			continue
+		}
+		if problemOutput(cmd) {
+			problemf(idLicenseHeader, f.Path, 1, 1, "missing license header")
+		} else {
+			blockf(idLicenseHeader, "%s: missing license header", f.Path)
+		}
+	}
+	if len(bad) == 0 {
+		return nil
+	}
+	if !quiet && !problemOutput(cmd) {
+		hintf("to insert it and restage: snag check license --fix")
+		bell()
+	}
+	return violationf(idLicenseHeader, "license header missing from %s", strings.Join(bad, ", "))
+}
+
+func testLicense(cmd *cobra.Command, dir string, patterns []string) bool {
+	orig, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(orig)
+
+	tmpl, err := os.CreateTemp("", "snag-license-*.txt")
+	if err != nil {
+		return false
+	}
+	defer os.Remove(tmpl.Name())
+	tmpl.WriteString("Copyright {{year}} Example Authors\nSPDX-License-Identifier: Apache-2.0\n")
+	tmpl.Close()
+
+	if err := os.WriteFile("license.go", []byte("package main\n"), 0644); err != nil {
+		return false
+	}
+	if out, err := gitCommand("add", "license.go").CombinedOutput(); err != nil {
+		fmt.Fprintf(os.Stderr, "git add: %s\n", out)
+		return false
+	}
+	bc := &BlockConfig{LicensePaths: []string{"**/*.go"}, LicenseTemplate: tmpl.Name()}
+	err = checkLicenseHeaders(cmd, bc, false)
+	return err != nil // error means violation detected = pass
+}
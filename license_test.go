@@ -0,0 +1,128 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testLicenseTemplate = "Copyright {{year}} Example Authors\nSPDX-License-Identifier: Apache-2.0\n"
+
+func TestHasLicenseHeader(t *testing.T) {
+	cases := []struct {
+		content string
+		want    bool
+	}{
+		{"// Copyright 2024 Example Authors\n// SPDX-License-Identifier: Apache-2.0\n\npackage main\n", true},
+		{"#!/bin/sh\n# Copyright 2019-2024 Example Authors\n# SPDX-License-Identifier: Apache-2.0\necho hi\n", true},
+		{"/*\n * Copyright 2024 Example Authors\n * SPDX-License-Identifier: Apache-2.0\n */\n", true},
+		{"// Copyright 2024 Someone Else\n// SPDX-License-Identifier: Apache-2.0\n", false},
+		{"package main\n", false},
+	}
+	for _, c := range cases {
+		if got := hasLicenseHeader(c.content, testLicenseTemplate); got != c.want {
+			t.Errorf("hasLicenseHeader(%q) = %v, want %v", c.content, got, c.want)
+		}
+	}
+}
+
+func TestCommentedHeader(t *testing.T) {
+	got, err := commentedHeader(testLicenseTemplate, "cmd/main.go", 2026)
+	if err != nil || got != "// Copyright 2026 Example Authors\n// SPDX-License-Identifier: Apache-2.0\n" {
+		t.Errorf("go: %q, %v", got, err)
+	}
+	if got, _ := commentedHeader(testLicenseTemplate, "run.sh", 2026); !strings.HasPrefix(got, "# Copyright 2026") {
+		t.Errorf("sh: %q", got)
+	}
+	if got, _ := commentedHeader("/* Copyright {{year}} */\n", "x.css", 2026); got != "/* Copyright 2026 */\n" {
+		t.Errorf("pre-commented: %q", got)
+	}
+	if _, err := commentedHeader(testLicenseTemplate, "x.css", 2026); err == nil {
+		t.Error("expected an error for an unknown comment style")
+	}
+	if got := insertLicenseHeader("#!/bin/sh\necho hi\n", "# H\n"); got != "#!/bin/sh\n# H\n\necho hi\n" {
+		t.Errorf("shebang: %q", got)
+	}
+}
+
+func TestMatchTreeGlob(t *testing.T) {
+	for p, want := range map[string]bool{"main.go": true, "internal/x/y.go": true, "x/y_test.go": false, "README.md": false} {
+		if got := matchTreeGlob([]string{"**/*.go"}, p) && !matchTreeGlob([]string{"*_test.go"}, p); got != want {
+			t.Errorf("%s: got %v, want %v", p, got, want)
+		}
+	}
+}
+
+func TestRunLicense_FixRestages(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	os.MkdirAll(filepath.Join(dir, "headers"), 0755)
+	os.WriteFile(filepath.Join(dir, "headers", "apache.txt"), []byte(testLicenseTemplate), 0644)
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[require]\nlicense_header = { paths = [\"**/*.go\"], template = \"headers/apache.txt\" }\n"), 0644)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	run := func(args ...string) error {
+		rootCmd := buildRootCmd()
+		rootCmd.SetArgs(append([]string{"check", "-q"}, args...))
+		return rootCmd.Execute()
+	}
+
+	stageFile(t, dir, "notes.md", "no header\n")
+	stageFile(t, dir, "main.go", "package main\n")
+	if err := run("diff"); err == nil || !strings.Contains(err.Error(), "main.go") || strings.Contains(err.Error(), "notes.md") {
+		t.Fatalf("check diff: expected violation in main.go only, got %v", err)
+	}
+	if err := run("license", "--fix"); err != nil {
+		t.Fatalf("--fix failed: %v", err)
+	}
+	out, _ := exec.Command("git", "show", ":main.go").Output()
+	if !strings.HasPrefix(string(out), "// Copyright ") || !strings.HasSuffix(string(out), "Apache-2.0\n\npackage main\n") {
+		t.Errorf("staged content after fix = %q", out)
+	}
+	if err := run("license"); err != nil {
+		t.Errorf("check after fix should pass, got %v", err)
+	}
+}
+
+func TestLicenseHeaderConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "snag.toml")
+	os.WriteFile(path, []byte("[require]\nlicense_header = { paths = [\"**/*.go\"], template = \"missing.txt\" }\n"), 0644)
+	if _, err := loadSnagTOML(path); err == nil || !strings.Contains(err.Error(), "no such file") {
+		t.Errorf("missing template: err = %v", err)
+	}
+	os.WriteFile(path, []byte("[require]\nlicense_header = { template = \"missing.txt\" }\n"), 0644)
+	if _, err := loadSnagTOML(path); err == nil || !strings.Contains(err.Error(), "paths and template") {
+		t.Errorf("no paths: err = %v", err)
+	}
+}
+
+func TestRunLicense_FixFromSubdirectory(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+	os.WriteFile(filepath.Join(dir, "header.txt"), []byte(testLicenseTemplate), 0644)
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[require]\nlicense_header = { paths = [\"**/*.go\"], template = \"header.txt\" }\n"), 0644)
+	stageFile(t, dir, "sub/a.go", "package a\n")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(filepath.Join(dir, "sub"))
+	defer os.Chdir(oldDir)
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"check", "-q", "license", "--fix"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("--fix from sub/: %v", err)
+	}
+	out, _ := exec.Command("git", "show", ":sub/a.go").Output()
+	if !strings.HasPrefix(string(out), "// Copyright ") || !strings.HasSuffix(string(out), "\n\npackage a\n") {
+		t.Errorf("staged content after fix = %q", out)
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// liveFile is the file the live self-test stages in its throwaway worktree.
+const liveFile = "snag-live-test.txt"
+
+// liveCheck is one real hook exercised by snag test --live.
+type liveCheck struct {
+	Name    string   // snag hook name: diff, msg, push
+	GitHook string   // the git hook expected to run it
+	Pattern string   // configured pattern used to provoke a violation
+	IDs     []string // checks whose violation means the hook fired
+	Run     func(wt, pattern string) (string, error)
+}
+
+// firedBy reports whether hook output names one of ids, so a refusal for
+// another reason, such as allowed_remotes, isn't taken for the check.
+func firedBy(out string, ids []string) bool {
+	for _, m := range checkIDPattern.FindAllStringSubmatch(out, -1) {
+		if slices.Contains(ids, m[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// runLiveChecks provokes a violation through the repository's installed
+// hooks. It works in a temporary worktree on a throwaway branch, so the
+// user's working tree and index are never touched, and removes both after.
+func runLiveChecks(cmd *cobra.Command, args []string, bc *BlockConfig) error {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	top, err := gitCommand("rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return fmt.Errorf("snag test --live must run inside a git work tree")
+	}
+	root := strings.TrimSpace(string(top))
+
+	first := func(patterns []string) string {
+		for _, p := range patterns {
+			if !isHashPattern(p) && !isNormPattern(p) {
+				return p
+			}
+		}
+		return ""
+	}
+	checks := []liveCheck{
+		{"diff", "pre-commit", first(bc.Diff), []string{idDiffPattern}, liveCommitDiff},
+		{"msg", "commit-msg", first(bc.Msg), []string{idMsgPattern}, liveCommitMsg},
+		{"push", "pre-push", first(bc.PushPatterns()), []string{idDiffPattern, idMsgPattern}, livePush},
+	}
+	if len(args) == 1 {
+		var picked []liveCheck
+		for _, c := range checks {
+			if c.Name == args[0] {
+				picked = append(picked, c)
+			}
+		}
+		if len(picked) == 0 {
+			return fmt.Errorf("--live exercises diff, msg, and push; got %q", args[0])
+		}
+		checks = picked
+	}
+
+	branch := fmt.Sprintf("snag-live-test-%d", time.Now().Unix())
+	wt, err := os.MkdirTemp("", "snag-live-*")
+	if err != nil {
+		return fmt.Errorf("creating temp dir: %w", err)
+	}
+	os.Remove(wt) // git worktree add wants to create it
+	if out, err := gitCommand("worktree", "add", "-q", "-b", branch, wt, "HEAD").CombinedOutput(); err != nil {
+		return fmt.Errorf("git worktree add: %w\n%s", err, out)
+	}
+	defer func() {
+		gitCommand("worktree", "remove", "--force", wt).Run()
+		gitCommand("branch", "-D", branch).Run()
+	}()
+
+	// Test the hook and policy files as they are now, not as last committed.
+	for _, names := range [][]string{lefthookCandidates, lefthookLocalCandidates, {"snag.toml"}, localConfigNames} {
+		for _, name := range names {
+			data, err := os.ReadFile(filepath.Join(root, name))
+			if err != nil {
+				continue
+			}
+			os.WriteFile(filepath.Join(wt, name), data, 0644)
+		}
+	}
+	if os.Getenv("LEFTHOOK") == "0" && !quiet {
+		warnf("LEFTHOOK=0 is set — lefthook-managed hooks will not run")
+	}
+	if !quiet {
+		infof("live test on throwaway branch %s", branch)
+	}
+
+	passed, total := 0, 0
+	for _, c := range checks {
+		if c.Pattern == "" {
+			if !quiet {
+				fmt.Fprintf(os.Stderr, "\n=== %s ===\nSKIP: no %s patterns configured\n", c.GitHook, c.Name)
+			}
+			continue
+		}
+		total++
+		if !quiet {
+			fmt.Fprintf(os.Stderr, "\n=== %s ===\n", c.GitHook)
+		}
+		out, err := c.Run(wt, c.Pattern)
+		fired := err != nil && firedBy(out, c.IDs)
+		if fired {
+			passed++
+			if !quiet {
+				fmt.Fprintln(os.Stderr, infoStyle.Render("PASS:")+" "+c.GitHook+" ran snag check "+c.Name+" and blocked the attempt")
+			}
+			continue
+		}
+		if !quiet {
+			fmt.Fprintln(os.Stderr, errorStyle.Render("FAIL:")+" "+c.GitHook+" did not block a violating "+c.Name)
+			if err != nil && strings.TrimSpace(out) != "" {
+				fmt.Fprintln(os.Stderr, strings.TrimSpace(out))
+			}
+		}
+	}
+
+	if !quiet {
+		fmt.Fprintf(os.Stderr, "\nsnag: %d/%d hooks fired\n", passed, total)
+		if passed < total {
+			hintf("run: snag install && lefthook install")
+		}
+	}
+	if passed < total {
+		return fmt.Errorf("%d/%d hooks did not fire", total-passed, total)
+	}
+	return nil
+}
+
+// liveGit runs git in the live worktree, returning combined output.
+// Signing is disabled so a passphrase prompt can't stall the test.
+func liveGit(wt string, args ...string) (string, error) {
+	c := gitCommand(append([]string{"-C", wt, "-c", "commit.gpgsign=false"}, args...)...)
+	var out bytes.Buffer
+	c.Stdout = &out
+	c.Stderr = &out
+	err := c.Run()
+	return out.String(), err
+}
+
+func liveStage(wt, content string) error {
+	if err := os.WriteFile(filepath.Join(wt, liveFile), []byte(content), 0644); err != nil {
+		return err
+	}
+	_, err := liveGit(wt, "add", liveFile)
+	return err
+}
+
+func liveCommitDiff(wt, pattern string) (string, error) {
+	if err := liveStage(wt, fmt.Sprintf("snag live test: %s\n", pattern)); err != nil {
+		return "", err
+	}
+	out, err := liveGit(wt, "commit", "-m", "snag live test: pre-commit")
+	liveGit(wt, "reset", "-q", "HEAD", "--", liveFile)
+	return out, err
+}
+
+func liveCommitMsg(wt, pattern string) (string, error) {
+	if err := liveStage(wt, "snag live test: clean change\n"); err != nil {
+		return "", err
+	}
+	out, err := liveGit(wt, "commit", "-m", "snag live test: "+pattern)
+	liveGit(wt, "reset", "-q", "HEAD", "--", liveFile)
+	return out, err
+}
+
+// livePush commits a violation without hooks, then dry-runs a push of it to
+// an empty local repository: pre-push still runs, nothing is transferred, and
+// no real remote is contacted.
+func livePush(wt, pattern string) (string, error) {
+	if err := liveStage(wt, fmt.Sprintf("snag live test: %s\n", pattern)); err != nil {
+		return "", err
+	}
+	if out, err := liveGit(wt, "commit", "--no-verify", "-m", "snag live test: pre-push"); err != nil {
+		return out, err
+	}
+	bare, err := os.MkdirTemp("", "snag-live-remote-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(bare)
+	if out, err := gitCommand("init", "-q", "--bare", bare).CombinedOutput(); err != nil {
+		return string(out), err
+	}
+	return liveGit(wt, "push", "--dry-run", bare, "HEAD:refs/heads/snag-live-test")
+}
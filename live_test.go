@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeHookTriggers is what each fake hook looks at before failing, so a
+// commit-msg run isn't refused by pre-commit first.
+var fakeHookTriggers = map[string]string{
+	"pre-commit": "git diff --cached | grep -qi todo",
+	"commit-msg": `grep -qi wip "$1"`,
+	"pre-push":   "true",
+}
+
+// writeHook installs a raw git hook that reports a violation of check id
+// and fails when it sees its trigger, standing in for an installed snag.
+func writeHook(t *testing.T, dir, name, id string) {
+	t.Helper()
+	script := "#!/bin/sh\n" + fakeHookTriggers[name] + " || exit 0\n" +
+		"echo 'Error: policy violation: live test [" + id + "]' >&2\nexit 1\n"
+	if err := os.WriteFile(filepath.Join(dir, ".git", "hooks", name), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func runLive(t *testing.T, dir string) error {
+	t.Helper()
+	orig, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(orig)
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"test", "--live", "-q"})
+	return rootCmd.Execute()
+}
+
+func TestTestLive_DetectsMissingHook(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"todo\"]\nmsg = [\"wip\"]\n"), 0644)
+	initialCommit(t, dir)
+	writeHook(t, dir, "pre-commit", idDiffPattern)
+	writeHook(t, dir, "commit-msg", idMsgPattern)
+
+	err := runLive(t, dir)
+	if err == nil || !strings.Contains(err.Error(), "1/3 hooks did not fire") {
+		t.Fatalf("expected pre-push to be reported missing, got %v", err)
+	}
+
+	branches, _ := exec.Command("git", "-C", dir, "branch", "--list", "snag-live-test-*").Output()
+	if len(strings.TrimSpace(string(branches))) > 0 {
+		t.Errorf("throwaway branch left behind: %s", branches)
+	}
+	worktrees, _ := exec.Command("git", "-C", dir, "worktree", "list").Output()
+	if n := len(strings.Split(strings.TrimSpace(string(worktrees)), "\n")); n != 1 {
+		t.Errorf("worktree left behind:\n%s", worktrees)
+	}
+}
+
+func TestTestLive_AllHooksFire(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"todo\"]\nmsg = [\"wip\"]\n"), 0644)
+	initialCommit(t, dir)
+	for h, id := range map[string]string{"pre-commit": idDiffPattern, "commit-msg": idMsgPattern, "pre-push": idDiffPattern} {
+		writeHook(t, dir, h, id)
+	}
+	if err := runLive(t, dir); err != nil {
+		t.Fatalf("expected all hooks to fire: %v", err)
+	}
+	if out, _ := exec.Command("git", "-C", dir, "status", "--porcelain").Output(); strings.Contains(string(out), liveFile) {
+		t.Errorf("live test touched the working tree:\n%s", out)
+	}
+}
+
+func TestTestLive_OtherRefusalIsNotAPass(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"todo\"]\nmsg = [\"wip\"]\n"), 0644)
+	initialCommit(t, dir)
+	writeHook(t, dir, "pre-commit", idDiffPattern)
+	writeHook(t, dir, "commit-msg", idMsgPattern)
+	// pre-push refuses the throwaway remote, never reaching the pattern scan.
+	writeHook(t, dir, "pre-push", idAllowedRemotes)
+
+	err := runLive(t, dir)
+	if err == nil || !strings.Contains(err.Error(), "1/3 hooks did not fire") {
+		t.Fatalf("expected pre-push to be reported as not firing, got %v", err)
+	}
+}
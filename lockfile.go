@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// lockfilePair is a manifest and the lockfile generated from it, both
+// base names within the same directory.
+type lockfilePair struct {
+	Manifest string
+	Lockfile string
+}
+
+// lockfilePairs are the built-in ecosystems [consistency] ecosystems can enable.
+var lockfilePairs = map[string]lockfilePair{
+	"go":       {"go.mod", "go.sum"},
+	"npm":      {"package.json", "package-lock.json"},
+	"yarn":     {"package.json", "yarn.lock"},
+	"pnpm":     {"package.json", "pnpm-lock.yaml"},
+	"cargo":    {"Cargo.toml", "Cargo.lock"},
+	"bundler":  {"Gemfile", "Gemfile.lock"},
+	"poetry":   {"pyproject.toml", "poetry.lock"},
+	"composer": {"composer.json", "composer.lock"},
+}
+
+func knownEcosystems() []string {
+	names := make([]string, 0, len(lockfilePairs))
+	for name := range lockfilePairs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lockfileMismatch is a staged file whose partner should have been staged too.
+type lockfileMismatch struct {
+	Changed string
+	Missing string
+}
+
+// findLockfileMismatches pairs staged manifests with their lockfiles. A
+// manifest change needs its lockfile staged when that lockfile is tracked
+// (libraries often don't commit one); a lockfile change needs its manifest
+// unless allowLockfileOnly. tracked reports whether a path is in the index.
+func findLockfileMismatches(staged []string, ecosystems []string, allowLockfileOnly bool, tracked func(string) bool) []lockfileMismatch {
+	inStage := make(map[string]bool, len(staged))
+	for _, p := range staged {
+		inStage[p] = true
+	}
+	var out []lockfileMismatch
+	seen := make(map[string]bool)
+	add := func(changed, missing string) {
+		if !seen[changed+"\x00"+missing] {
+			seen[changed+"\x00"+missing] = true
+			out = append(out, lockfileMismatch{Changed: changed, Missing: missing})
+		}
+	}
+	for _, p := range staged {
+		dir, base := path.Split(p)
+		for _, eco := range ecosystems {
+			pair, ok := lockfilePairs[eco]
+			if !ok {
+				continue
+			}
+			switch base {
+			case pair.Manifest:
+				lock := dir + pair.Lockfile
+				if !inStage[lock] && tracked(lock) {
+					add(p, lock)
+				}
+			case pair.Lockfile:
+				manifest := dir + pair.Manifest
+				if !allowLockfileOnly && !inStage[manifest] {
+					add(p, manifest)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// stagedPaths lists every path in the staged diff, including deletions.
+func stagedPaths() ([]string, error) {
+	out, err := gitCommand("diff", "--staged", "--name-only", "-z").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff --staged --name-only: %w", err)
+	}
+	var paths []string
+	for _, p := range strings.Split(string(out), "\x00") {
+		if p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths, nil
+}
+
+// inIndex reports whether path is tracked in the index.
+func inIndex(p string) bool {
+	return gitCommand("cat-file", "-e", ":"+p).Run() == nil
+}
+
+// checkLockfiles enforces [consistency] at pre-commit.
+func checkLockfiles(cmd *cobra.Command, bc *BlockConfig) error {
+	if len(bc.Ecosystems) == 0 {
+		return nil
+	}
+	staged, err := stagedPaths()
+	if err != nil {
+		return err
+	}
+	mismatches := findLockfileMismatches(staged, bc.Ecosystems, bc.AllowLockfileOnly, inIndex)
+	if len(mismatches) == 0 {
+		return nil
+	}
+
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	if !quiet {
+		for _, m := range mismatches {
+			if problemOutput(cmd) {
+				problemf(idLockfile, m.Changed, 1, 1, "%s changed without %s", m.Changed, m.Missing)
+			} else {
+				blockf(idLockfile, "%s changed without %s", m.Changed, m.Missing)
+			}
+		}
+		if !problemOutput(cmd) {
+			hintf("regenerate and stage it, e.g. go mod tidy / npm install, then git add %s", hintArg(mismatches[0].Missing))
+			bell()
+		}
+	}
+	m := mismatches[0]
+	return violationf(idLockfile, "%s changed without %s", m.Changed, m.Missing)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindLockfileMismatches(t *testing.T) {
+	tracked := map[string]bool{"go.sum": true, "web/package-lock.json": true}
+	isTracked := func(p string) bool { return tracked[p] }
+
+	tests := []struct {
+		name     string
+		staged   []string
+		ecos     []string
+		lockOnly bool
+		want     []string // "changed>missing"
+	}{
+		{"both staged", []string{"go.mod", "go.sum"}, []string{"go"}, false, nil},
+		{"manifest only", []string{"go.mod"}, []string{"go"}, false, []string{"go.mod>go.sum"}},
+		{"lockfile only", []string{"go.sum"}, []string{"go"}, false, []string{"go.sum>go.mod"}},
+		{"lockfile only allowed", []string{"go.sum"}, []string{"go"}, true, nil},
+		{"nested dir", []string{"web/package.json"}, []string{"npm"}, false, []string{"web/package.json>web/package-lock.json"}},
+		{"untracked lockfile", []string{"package.json"}, []string{"npm", "yarn"}, false, nil},
+		{"ecosystem not enabled", []string{"go.mod"}, []string{"npm"}, false, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, m := range findLockfileMismatches(tc.staged, tc.ecos, tc.lockOnly, isTracked) {
+				got = append(got, m.Changed+">"+m.Missing)
+			}
+			if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRunDiff_LockfileConsistency(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[consistency]\necosystems = [\"go\"]\n"), 0644)
+	commitFile(t, dir, "go.mod", "module x\n", "add go.mod")
+	commitFile(t, dir, "go.sum", "", "add go.sum")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	run := func() error {
+		rootCmd := buildRootCmd()
+		rootCmd.SetArgs([]string{"check", "diff", "-q"})
+		return rootCmd.Execute()
+	}
+
+	stageFile(t, dir, "go.mod", "module x\n\nrequire y v1.0.0\n")
+	err := run()
+	if err == nil || !strings.Contains(err.Error(), "without go.sum") {
+		t.Fatalf("expected lockfile violation, got %v", err)
+	}
+
+	stageFile(t, dir, "go.sum", "y v1.0.0 h1:abc=\n")
+	if err := run(); err != nil {
+		t.Errorf("manifest and lockfile staged together blocked: %v", err)
+	}
+}
+
+func TestLoadSnagTOML_UnknownEcosystem(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snag.toml")
+	os.WriteFile(path, []byte("[consistency]\necosystems = [\"maven\"]\n"), 0644)
+	if _, err := loadSnagTOML(path); err == nil || !strings.Contains(err.Error(), "maven") {
+		t.Errorf("expected unknown ecosystem error, got %v", err)
+	}
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func buildLSPCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "lsp",
+		Short: "Run a diagnostics-only Language Server on stdio",
+		Long: `Run a minimal Language Server Protocol server on stdin/stdout.
+
+Publishes snag policy violations as diagnostics for open files (diff
+patterns) and commit message buffers named COMMIT_EDITMSG (msg patterns and
+msg_max_len). Config is resolved from each file's directory, exactly as
+snag check buffer does. Point any LSP-capable editor at "snag lsp".`,
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s := newLSPServer(cmd, cmd.InOrStdin(), cmd.OutOrStdout())
+			return s.serve()
+		},
+	}
+}
+
+// lspMessage is an incoming request or notification, or an outgoing
+// notification. Requests carry an ID; notifications don't.
+type lspMessage struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+// lspResult is a successful response. Result is always present, even null.
+type lspResult struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  any              `json:"result"`
+}
+
+// lspErrorResponse is a failed response.
+type lspErrorResponse struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Error   *lspError        `json:"error"`
+}
+
+type lspError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspDiagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"` // 1 = Error
+	Source   string   `json:"source"`
+	Code     string   `json:"code,omitempty"` // check ID, e.g. SNAG001
+	// CodeDescription links the check's documentation.
+	CodeDescription *lspCodeDescription `json:"codeDescription,omitempty"`
+	Message         string              `json:"message"`
+}
+
+type lspCodeDescription struct {
+	Href string `json:"href"`
+}
+
+type lspTextDocument struct {
+	URI  string `json:"uri"`
+	Text string `json:"text"`
+}
+
+// lspServer holds the stdio streams; it keeps no document state because
+// every open/change carries the full text (TextDocumentSyncKind.Full).
+type lspServer struct {
+	cmd *cobra.Command
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func newLSPServer(cmd *cobra.Command, in io.Reader, out io.Writer) *lspServer {
+	return &lspServer{cmd: cmd, in: bufio.NewReader(in), out: out}
+}
+
+// serve reads messages until exit or EOF.
+func (s *lspServer) serve() error {
+	for {
+		body, err := readLSPMessage(s.in)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var msg lspMessage
+		if err := json.Unmarshal(body, &msg); err != nil {
+			continue
+		}
+		if done := s.handle(msg); done {
+			return nil
+		}
+	}
+}
+
+// handle dispatches one message. Returns true on "exit".
+func (s *lspServer) handle(msg lspMessage) bool {
+	switch msg.Method {
+	case "initialize":
+		s.reply(msg.ID, map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync": map[string]any{"openClose": true, "change": 1},
+			},
+			"serverInfo": map[string]any{"name": "snag", "version": Version},
+		}, nil)
+	case "shutdown":
+		s.reply(msg.ID, nil, nil)
+	case "exit":
+		return true
+	case "textDocument/didOpen":
+		var p struct {
+			TextDocument lspTextDocument `json:"textDocument"`
+		}
+		if json.Unmarshal(msg.Params, &p) == nil {
+			s.publish(p.TextDocument.URI, p.TextDocument.Text)
+		}
+	case "textDocument/didChange":
+		var p struct {
+			TextDocument   lspTextDocument `json:"textDocument"`
+			ContentChanges []struct {
+				Text string `json:"text"`
+			} `json:"contentChanges"`
+		}
+		if json.Unmarshal(msg.Params, &p) == nil && len(p.ContentChanges) > 0 {
+			s.publish(p.TextDocument.URI, p.ContentChanges[len(p.ContentChanges)-1].Text)
+		}
+	case "textDocument/didClose":
+		var p struct {
+			TextDocument lspTextDocument `json:"textDocument"`
+		}
+		if json.Unmarshal(msg.Params, &p) == nil {
+			s.notify("textDocument/publishDiagnostics", map[string]any{
+				"uri": p.TextDocument.URI, "diagnostics": []lspDiagnostic{},
+			})
+		}
+	default:
+		if msg.ID != nil && msg.Method != "" {
+			s.reply(msg.ID, nil, &lspError{Code: -32601, Message: "method not found: " + msg.Method})
+		}
+	}
+	return false
+}
+
+// publish computes and sends diagnostics for one document.
+func (s *lspServer) publish(uri, text string) {
+	diags, err := s.diagnose(uri, text)
+	if err != nil {
+		s.notify("window/logMessage", map[string]any{"type": 1, "message": "snag: " + err.Error()})
+		return
+	}
+	s.notify("textDocument/publishDiagnostics", map[string]any{"uri": uri, "diagnostics": diags})
+}
+
+// diagnose resolves config from the document's directory and scans text.
+// COMMIT_EDITMSG buffers get msg rules; everything else gets diff rules.
+func (s *lspServer) diagnose(uri, text string) ([]lspDiagnostic, error) {
+	path := uriToPath(uri)
+	bc, err := resolveBlockConfigAt(s.cmd, filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(text, "\n")
+	diags := []lspDiagnostic{}
+
+	if filepath.Base(path) == "COMMIT_EDITMSG" {
+		first := true
+		for i, line := range lines {
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			if first && bc.MsgMaxLen > 0 && len(line) > bc.MsgMaxLen {
+				diags = append(diags, lineDiagnostic(idMsgMaxLen, line, i, bc.MsgMaxLen+1, len(line)-bc.MsgMaxLen,
+					fmt.Sprintf("first line is %d chars (limit: %d)", len(line), bc.MsgMaxLen)))
+			}
+			first = false
+			if p, ok := matchesPattern(line, bc.Msg); ok {
+				diags = append(diags, patternDiagnostic(bc, idMsgPattern, line, i, p, "commit message"))
+			}
+		}
+		return diags, nil
+	}
+
+	rules := bc.skipRules()
+	rules.MaxBytes = 0
+	if rules.reason(diffFile{Path: path, Body: text}) != "" {
+		return diags, nil
+	}
+	patterns, _ := rules.unsuppressed(bc.repoPath(path), bc.Diff)
+	for _, m := range scanBuffer(text, patterns) {
+		diags = append(diags, patternDiagnostic(bc, idDiffPattern, lines[m.Line-1], m.Line-1, m.Pattern, "file"))
+	}
+	return diags, nil
+}
+
+// patternDiagnostic builds a diagnostic spanning pattern's match on line.
+func patternDiagnostic(bc *BlockConfig, id, line string, lineNo int, pattern, where string) lspDiagnostic {
+	col, width := 1, len(line)
+	if start, end, ok := matchSpan(line, pattern); ok {
+		col, width = start+1, end-start
+	}
+	return lineDiagnostic(id, line, lineNo, col, width, fmt.Sprintf("snag: match %q in %s", bc.display(pattern), where))
+}
+
+// lineDiagnostic converts a 1-based byte column and byte width on line into
+// an LSP range (0-based, UTF-16 code units), coded with check id.
+func lineDiagnostic(id, line string, lineNo, col, width int, message string) lspDiagnostic {
+	start := min(max(col-1, 0), len(line))
+	end := min(start+max(width, 0), len(line))
+	d := lspDiagnostic{
+		Range: lspRange{
+			Start: lspPosition{Line: lineNo, Character: utf16Len(line[:start])},
+			End:   lspPosition{Line: lineNo, Character: utf16Len(line[:end])},
+		},
+		Severity: 1,
+		Source:   "snag",
+		Code:     id,
+		Message:  message,
+	}
+	if c := findCheck(id); c != nil {
+		d.CodeDescription = &lspCodeDescription{Href: c.URL()}
+	}
+	return d
+}
+
+// utf16Len counts UTF-16 code units in s, the unit LSP positions use.
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+// uriToPath converts a file:// URI to a local path; other strings are
+// returned unchanged.
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	p := u.Path
+	// file:///C:/x on Windows parses to /C:/x.
+	if len(p) >= 3 && p[0] == '/' && p[2] == ':' {
+		p = p[1:]
+	}
+	return filepath.FromSlash(p)
+}
+
+func (s *lspServer) reply(id *json.RawMessage, result any, rpcErr *lspError) {
+	if rpcErr != nil {
+		writeLSPMessage(s.out, lspErrorResponse{JSONRPC: "2.0", ID: id, Error: rpcErr})
+		return
+	}
+	writeLSPMessage(s.out, lspResult{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+func (s *lspServer) notify(method string, params any) {
+	raw, _ := json.Marshal(params)
+	writeLSPMessage(s.out, lspMessage{JSONRPC: "2.0", Method: method, Params: raw})
+}
+
+// readLSPMessage reads one Content-Length framed message body.
+func readLSPMessage(r *bufio.Reader) ([]byte, error) {
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF || (len(header) == 0 && err == io.ErrUnexpectedEOF) {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+	n, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("lsp: bad Content-Length %q", header.Get("Content-Length"))
+	}
+	body := make([]byte, n)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// writeLSPMessage frames v as a Content-Length message.
+func writeLSPMessage(w io.Writer, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "snag lsp:", err)
+	}
+	return err
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// lspFrame encodes v as a Content-Length framed LSP message.
+func lspFrame(t *testing.T, v any) string {
+	t.Helper()
+	body, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+// runLSPSession feeds msgs to a server and returns every message it wrote.
+func runLSPSession(t *testing.T, msgs ...any) []map[string]any {
+	t.Helper()
+	var in strings.Builder
+	for _, m := range msgs {
+		in.WriteString(lspFrame(t, m))
+	}
+	var out bytes.Buffer
+	s := newLSPServer(buildRootCmd(), strings.NewReader(in.String()), &out)
+	if err := s.serve(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []map[string]any
+	r := bufio.NewReader(&out)
+	for {
+		body, err := readLSPMessage(r)
+		if err != nil {
+			break
+		}
+		var m map[string]any
+		json.Unmarshal(body, &m)
+		got = append(got, m)
+	}
+	return got
+}
+
+func TestLSP_InitializeAndDiagnostics(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[block]\ndiff = [\"todo\"]\nmsg = [\"wip\"]\nmsg_max_len = 10\n"), 0644)
+	uri := "file://" + filepath.ToSlash(filepath.Join(dir, "main.go"))
+	msgURI := "file://" + filepath.ToSlash(filepath.Join(dir, "COMMIT_EDITMSG"))
+
+	got := runLSPSession(t,
+		map[string]any{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": map[string]any{}},
+		map[string]any{"jsonrpc": "2.0", "method": "initialized", "params": map[string]any{}},
+		map[string]any{"jsonrpc": "2.0", "method": "textDocument/didOpen", "params": map[string]any{
+			"textDocument": map[string]any{"uri": uri, "text": "package main\n\n// é TODO\n"},
+		}},
+		map[string]any{"jsonrpc": "2.0", "method": "textDocument/didOpen", "params": map[string]any{
+			"textDocument": map[string]any{"uri": msgURI, "text": "WIP on parser\n# WIP in a comment\n"},
+		}},
+		map[string]any{"jsonrpc": "2.0", "id": 2, "method": "shutdown"},
+		map[string]any{"jsonrpc": "2.0", "method": "exit"},
+	)
+
+	if len(got) != 4 {
+		t.Fatalf("got %d messages, want 4: %v", len(got), got)
+	}
+	caps, _ := got[0]["result"].(map[string]any)
+	if caps == nil || caps["capabilities"] == nil {
+		t.Errorf("initialize result missing capabilities: %v", got[0])
+	}
+
+	diags := got[1]["params"].(map[string]any)["diagnostics"].([]any)
+	if len(diags) != 1 {
+		t.Fatalf("file diagnostics = %v, want 1", diags)
+	}
+	start := diags[0].(map[string]any)["range"].(map[string]any)["start"].(map[string]any)
+	// "// é TODO": é is 2 bytes but 1 UTF-16 unit, so TODO starts at character 5.
+	if start["line"].(float64) != 2 || start["character"].(float64) != 5 {
+		t.Errorf("diagnostic start = %v, want line 2 character 5", start)
+	}
+	if code := diags[0].(map[string]any)["code"]; code != idDiffPattern {
+		t.Errorf("diagnostic code = %v, want %s", code, idDiffPattern)
+	}
+
+	msgDiags := got[2]["params"].(map[string]any)["diagnostics"].([]any)
+	if len(msgDiags) != 2 {
+		t.Errorf("commit message diagnostics = %v, want length + pattern (comment ignored)", msgDiags)
+	}
+
+	if _, ok := got[3]["result"]; !ok || got[3]["id"].(float64) != 2 {
+		t.Errorf("shutdown response = %v, want null result for id 2", got[3])
+	}
+}
+
+func TestLSP_DidCloseClearsAndUnknownMethod(t *testing.T) {
+	got := runLSPSession(t,
+		map[string]any{"jsonrpc": "2.0", "method": "textDocument/didClose", "params": map[string]any{
+			"textDocument": map[string]any{"uri": "file:///tmp/x.go"},
+		}},
+		map[string]any{"jsonrpc": "2.0", "id": 7, "method": "textDocument/hover", "params": map[string]any{}},
+	)
+	if len(got) != 2 {
+		t.Fatalf("got %d messages, want 2", len(got))
+	}
+	if d := got[0]["params"].(map[string]any)["diagnostics"].([]any); len(d) != 0 {
+		t.Errorf("didClose should clear diagnostics, got %v", d)
+	}
+	if got[1]["error"] == nil {
+		t.Errorf("unknown request should get an error response, got %v", got[1])
+	}
+}
+
+func TestURIToPath(t *testing.T) {
+	if got := uriToPath("file:///home/me/a%20b.go"); got != filepath.FromSlash("/home/me/a b.go") {
+		t.Errorf("uriToPath = %q", got)
+	}
+	if got := uriToPath("untitled:Untitled-1"); got != "untitled:Untitled-1" {
+		t.Errorf("non-file URI changed: %q", got)
+	}
+}
+
+func TestLSP_DiagnosticAfterLengthChangingLowercase(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"todo\"]\n"), 0644)
+	uri := "file://" + filepath.ToSlash(filepath.Join(dir, "main.go"))
+
+	// Ⱥ is 2 bytes but lowercases to 3, so offsets in the lowered line run
+	// past the original and used to slice beyond it.
+	got := runLSPSession(t,
+		map[string]any{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": map[string]any{}},
+		map[string]any{"jsonrpc": "2.0", "method": "textDocument/didOpen", "params": map[string]any{
+			"textDocument": map[string]any{"uri": uri, "text": "ȺȺȺȺȺȺ TODO\n"},
+		}},
+		map[string]any{"jsonrpc": "2.0", "id": 2, "method": "shutdown"},
+		map[string]any{"jsonrpc": "2.0", "method": "exit"},
+	)
+
+	if len(got) != 3 {
+		t.Fatalf("got %d messages, want 3: %v", len(got), got)
+	}
+	diags := got[1]["params"].(map[string]any)["diagnostics"].([]any)
+	if len(diags) != 1 {
+		t.Fatalf("diagnostics = %v, want 1", diags)
+	}
+	rng := diags[0].(map[string]any)["range"].(map[string]any)
+	start := rng["start"].(map[string]any)["character"].(float64)
+	end := rng["end"].(map[string]any)["character"].(float64)
+	if start != 7 || end != 11 {
+		t.Errorf("diagnostic range = %v..%v, want 7..11", start, end)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"runtime/debug"
@@ -53,6 +54,19 @@ func buildRootCmd() *cobra.Command {
 		Long: fmt.Sprintf(`snag %s — Composable git hook policy kit
 
 Environment variables:
+  SNAG_CONFIG_DIRS          Colon-separated directories whose snag.toml and
+                            snag-local.toml are merged after the directory walk
+                            (e.g. "$HOME/.config/snag")
+  SNAG_ALLOW_REMOTE=1       Skip [push] allowed_remotes for one push
+  SNAG_ALLOW_COMMIT=1       Skip [branch] block_commit for one commit
+  SNAG_ALLOW_LARGE_PUSH=1   Scan every commit of a push over [push] max_commits
+  SNAG_OFFLINE=1            Never touch the network (see snag doctor)
+  SNAG_PLAIN=1              Plain output: no color, spelled-out severity, ASCII
+                            punctuation (also on with TERM=dumb)
+  SNAG_TRACE=1              Write a Chrome trace-event file of this run (config
+                            walk, git commands, matching) for Perfetto; set it to
+                            a directory to write the file there
+  SNAG_AGE_IDENTITY         age identity file used to decrypt snag-local.toml.age
   SNAG_PROTECTED_BRANCHES   Comma-separated branch names to merge into the
                             protected branches list (e.g. "develop,staging")
   SNAG_IGNORE               Comma-separated entries to suppress specific phases
@@ -67,6 +81,10 @@ Environment variables:
 	rootCmd.SetVersionTemplate("snag version {{.Version}}\n")
 
 	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "suppress non-error output")
+	rootCmd.PersistentFlags().Bool("verbose", false, "report extra detail (e.g. files skipped by scan heuristics)")
+	rootCmd.PersistentFlags().Bool("explain", false, "on violation, show the hunk, the rule's config source, and fix commands")
+	rootCmd.PersistentFlags().String("format", formatText, "violation output format: text, vscode (file:line:col: severity: message), json (one object per line), junit (audit and ci)")
+	rootCmd.PersistentPreRunE = validateFormat
 
 	checkCmd := &cobra.Command{
 		Use:   "check",
@@ -79,7 +97,10 @@ Environment variables:
 			Short:        h.Short,
 			Args:         h.Args,
 			SilenceUsage: true,
-			RunE:         h.RunE,
+			RunE:         withHookTimeout(h.RunE),
+		}
+		if h.Flags != nil {
+			h.Flags(cmd)
 		}
 		checkCmd.AddCommand(cmd)
 	}
@@ -87,10 +108,20 @@ Environment variables:
 	versionCmd := &cobra.Command{
 		Use:   "version",
 		Short: "Print version and exit",
-		Run: func(cmd *cobra.Command, args []string) {
+		Long: `Print the snag version. With --check-compat, compare it with the snag
+remote ref pinned in lefthook.yml / lefthook-local.yml and the min_version of
+every governing config file, and exit non-zero on a mismatch that would
+otherwise surface at hook time.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if compat, _ := cmd.Flags().GetBool("check-compat"); compat {
+				return runVersionCompat(cmd)
+			}
 			fmt.Printf("snag version %s\n", Version)
+			return nil
 		},
 	}
+	versionCmd.Flags().Bool("check-compat", false, "report version mismatches with lefthook.yml and min_version requirements")
 
 	installCmd := &cobra.Command{
 		Use:          "install",
@@ -101,14 +132,26 @@ Environment variables:
 	installCmd.Flags().Bool("local", false, "install to lefthook-local.yml (gitignored, just for you)")
 	installCmd.Flags().Bool("shared", false, "install to lefthook.yml (checked in, whole team)")
 	installCmd.Flags().BoolP("dry-run", "n", false, "show what would be changed without writing files")
+	installCmd.Flags().Bool("chain", false, "add snag to existing hook scripts (husky, pre-commit, custom) instead of lefthook")
+	installCmd.Flags().Bool("remove", false, "with --chain: take snag back out of the hook scripts")
 	installCmd.MarkFlagsMutuallyExclusive("local", "shared")
+	installCmd.MarkFlagsMutuallyExclusive("chain", "local")
+	installCmd.MarkFlagsMutuallyExclusive("chain", "shared")
 
-	rootCmd.AddCommand(checkCmd, versionCmd, installCmd, buildInitCmd(), buildConfigCmd(), buildTestCmd(), buildDemoCmd(), buildAuditCmd(), buildShellCmd())
+	rootCmd.AddCommand(checkCmd, versionCmd, installCmd, buildInitCmd(), buildConfigCmd(), buildTestCmd(), buildDemoCmd(), buildAuditCmd(), buildShellCmd(), buildHashCmd(), buildRedactCmd(), buildLSPCmd(), buildSnoozeCmd(), buildScrubCmd(), buildExportCmd(), buildImportCmd(), buildSetupCmd(), buildReposCmd(), buildDebugBundleCmd(), buildDoctorCmd(), buildCapabilitiesCmd(), buildReportCmd(), buildCICmd(), buildStatsCmd(), buildSimulateCmd(), buildServerHookCmd(), buildWebhookCmd(), buildExplainCmd(), buildSuppressionsCmd(), buildTryCmd(), buildAddPatternCmd(), buildQuarantineCmd(), buildHookCmd(), buildEmitHooksCmd(), buildAuthCmd(), buildPacksCmd())
 	return rootCmd
 }
 
 func main() {
-	if err := buildRootCmd().Execute(); err != nil {
+	startTrace(os.Args[1:])
+	cmd, err := buildRootCmd().ExecuteC()
+	finishTrace()
+	if err != nil {
+		recordHookError(cmd, err)
+		hintCheckDocs(cmd, err)
+		if errors.Is(err, errInterrupted) {
+			os.Exit(130)
+		}
 		os.Exit(1)
 	}
 }
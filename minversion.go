@@ -0,0 +1,39 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// min_version_policy values. "strict" refuses to load a config that needs a
+// newer snag; "degrade" loads it anyway, applying every pattern and rule
+// this version understands, so one out-of-date install isn't left with no
+// protection at all.
+const (
+	minVersionStrict  = "strict"
+	minVersionDegrade = "degrade"
+)
+
+var (
+	degradedMu     sync.Mutex
+	degradedWarned = map[string]bool{}
+)
+
+// warnDegraded tells the user, once per file per run, that path wants a
+// newer snag and which of its settings this version skipped.
+func warnDegraded(path, minVer string, skipped []string) {
+	degradedMu.Lock()
+	defer degradedMu.Unlock()
+	if degradedWarned[path] {
+		return
+	}
+	degradedWarned[path] = true
+
+	warnf("%s wants snag >= %s (running %s) — applying the rules this version understands", path, minVer, Version)
+	if len(skipped) > 0 {
+		sort.Strings(skipped)
+		warnf("skipped: %s", strings.Join(skipped, ", "))
+	}
+	hintf("upgrade: go install github.com/dpritchett/snag@latest")
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadSnagTOML_MinVersionPolicy(t *testing.T) {
+	old := Version
+	Version = "0.9.0"
+	defer func() { Version = old }()
+
+	newer := `min_version = "9.0.0"
+%s
+[block]
+diff = ["todo"]
+future_option = true
+
+[detect.quantum]
+enabled = true
+
+[consistency]
+ecosystems = ["go", "zig"]
+`
+	write := func(policy string) string {
+		path := filepath.Join(t.TempDir(), "snag.toml")
+		os.WriteFile(path, []byte(strings.Replace(newer, "%s", policy, 1)), 0644)
+		return path
+	}
+
+	if _, err := loadSnagTOML(write("")); err == nil || !strings.Contains(err.Error(), "requires snag >= 9.0.0") {
+		t.Errorf("strict by default: got %v", err)
+	}
+
+	cfg, err := loadSnagTOML(write(`min_version_policy = "degrade"`))
+	if err != nil {
+		t.Fatalf("degrade: %v", err)
+	}
+	if len(cfg.Block.Diff) != 1 || cfg.Block.Diff[0] != "todo" {
+		t.Errorf("known patterns must still apply, got %v", cfg.Block.Diff)
+	}
+	if _, ok := cfg.Detect["quantum"]; ok {
+		t.Error("unknown detector should be skipped")
+	}
+	if len(cfg.Consistency.Ecosystems) != 1 || cfg.Consistency.Ecosystems[0] != "go" {
+		t.Errorf("unknown ecosystem should be skipped, got %v", cfg.Consistency.Ecosystems)
+	}
+
+	if _, err := loadSnagTOML(write(`min_version_policy = "lenient"`)); err == nil {
+		t.Error("expected an error for an unknown policy")
+	}
+}
+
+func TestLoadSnagTOML_DegradeStillValidatesCurrentVersion(t *testing.T) {
+	// Running a new enough snag, degrade changes nothing: typos still fail.
+	path := filepath.Join(t.TempDir(), "snag.toml")
+	os.WriteFile(path, []byte("min_version = \"0.1.0\"\nmin_version_policy = \"degrade\"\n[detect.quantum]\nenabled = true\n"), 0644)
+	if _, err := loadSnagTOML(path); err == nil || !strings.Contains(err.Error(), "unknown detector") {
+		t.Errorf("expected unknown detector error, got %v", err)
+	}
+}
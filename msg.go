@@ -31,12 +31,37 @@ func stripMatchingTrailers(lines []string, patterns []string) ([]string, int) {
 	return kept, removed
 }
 
+// splitMsgLines splits a commit message file into lines without their line
+// endings, and returns the ending to rejoin them with. Editors on Windows
+// save CRLF; left on, the \r would count toward msg_max_len and defeat
+// patterns anchored with $, and a rewrite joined with bare \n would leave
+// the file with mixed endings.
+func splitMsgLines(data []byte) ([]string, string) {
+	text := string(data)
+	if !strings.Contains(text, "\r\n") {
+		return strings.Split(text, "\n"), "\n"
+	}
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n"), "\r\n"
+}
+
 func runMsg(cmd *cobra.Command, args []string) error {
 	bc, err := resolveBlockConfig(cmd)
 	if err != nil {
 		return err
 	}
-	if len(bc.Msg) == 0 && bc.MsgMaxLen == 0 && bc.MsgMaxLines == 0 {
+	if err := checkMsg(cmd, bc, args); err != nil {
+		return err
+	}
+	return recordPolicyTrailer(bc, args[0])
+}
+
+// checkMsg applies the commit-msg policy to the message file in args[0].
+func checkMsg(cmd *cobra.Command, bc *BlockConfig, args []string) error {
+	defer traceSpan("match", "commit message")()
+	if err := checkCommitDates(cmd, bc); err != nil {
+		return err
+	}
+	if len(bc.Msg) == 0 && bc.MsgMaxLen == 0 && bc.MsgMaxLines == 0 && bc.MsgLanguage == "" && !bc.inclusiveOn() && !bc.ticketsOn() {
 		return nil
 	}
 
@@ -46,14 +71,15 @@ func runMsg(cmd *cobra.Command, args []string) error {
 	}
 
 	quiet, _ := cmd.Flags().GetBool("quiet")
+	patterns := dropSnoozed(cmd, "msg", bc.Msg)
 
 	// Pass 1 — silent removal: strip trailer lines (like Generated-by) that
 	// match block patterns. The commit message file is rewritten in place so
 	// the commit proceeds cleanly without the matched trailers.
-	lines := strings.Split(string(data), "\n")
-	cleaned, removed := stripMatchingTrailers(lines, bc.Msg)
+	lines, eol := splitMsgLines(data)
+	cleaned, removed := stripMatchingTrailers(lines, patterns)
 	if removed > 0 {
-		if err := os.WriteFile(args[0], []byte(strings.Join(cleaned, "\n")), 0644); err != nil {
+		if err := os.WriteFile(args[0], []byte(strings.Join(cleaned, eol)), 0644); err != nil {
 			return fmt.Errorf("rewriting commit message: %w", err)
 		}
 		if !quiet {
@@ -67,36 +93,76 @@ func runMsg(cmd *cobra.Command, args []string) error {
 		first := content[0]
 		if len(first) > bc.MsgMaxLen {
 			if !quiet {
-				errorf("first line is %d chars (limit: %d)", len(first), bc.MsgMaxLen)
-				bell()
-				hintf("to recover: git commit -eF .git/COMMIT_EDITMSG")
+				if problemOutput(cmd) {
+					line := 1
+					for i, l := range cleaned {
+						if l == first {
+							line = i + 1
+							break
+						}
+					}
+					problemf(idMsgMaxLen, args[0], line, bc.MsgMaxLen+1, "first line is %d chars (limit: %d)", len(first), bc.MsgMaxLen)
+				} else {
+					blockf(idMsgMaxLen, "first line is %d chars (limit: %d)", len(first), bc.MsgMaxLen)
+					bell()
+					hintf("to recover: git commit -eF .git/COMMIT_EDITMSG")
+				}
 			}
-			return fmt.Errorf("policy violation: first line exceeds %d characters (%d)", bc.MsgMaxLen, len(first))
+			return violationf(idMsgMaxLen, "first line exceeds %d characters (%d)", bc.MsgMaxLen, len(first))
 		}
 	}
 	if bc.MsgMaxLines > 0 && len(content) > bc.MsgMaxLines {
 		if !quiet {
-			errorf("commit message has %d lines (limit: %d)", len(content), bc.MsgMaxLines)
-			bell()
-			hintf("to recover: git commit -eF .git/COMMIT_EDITMSG")
+			if problemOutput(cmd) {
+				problemf(idMsgMaxLines, args[0], 1, 1, "commit message has %d lines (limit: %d)", len(content), bc.MsgMaxLines)
+			} else {
+				blockf(idMsgMaxLines, "commit message has %d lines (limit: %d)", len(content), bc.MsgMaxLines)
+				bell()
+				hintf("to recover: git commit -eF .git/COMMIT_EDITMSG")
+			}
 		}
-		return fmt.Errorf("policy violation: commit message exceeds %d lines (%d)", bc.MsgMaxLines, len(content))
+		return violationf(idMsgMaxLines, "commit message exceeds %d lines (%d)", bc.MsgMaxLines, len(content))
+	}
+	if err := checkMsgLanguage(cmd, bc, args[0], cleaned); err != nil {
+		return err
+	}
+	if err := checkMsgInclusive(cmd, bc, args[0], cleaned); err != nil {
+		return err
+	}
+	if err := checkMsgTickets(cmd, bc, args[0], cleaned); err != nil {
+		return err
 	}
 
 	// Pass 2 — hard reject: check the remaining message body. Unlike pass 1,
 	// a match here blocks the commit entirely.
 	body := strings.Join(cleaned, "\n")
-	pattern, found := matchesPattern(body, bc.Msg)
+	block, warn := bc.splitRollout(patterns)
+	pattern, found := matchesPattern(body, block)
 	if !found {
+		n := rolloutWarnings(cmd, bc, warn, "commit message", func(p string) int {
+			return countTextLines(body, p)
+		})
+		if err := checkWarningBudget(cmd, bc, n, "commit message"); err != nil {
+			return err
+		}
+		if bc.nearMissOn() {
+			warnNearMisses(cmd, bc, textNearMisses(body, patterns), "commit message")
+		}
 		return nil
 	}
 
 	if !quiet {
-		errorf("match %q in commit message", pattern)
-		bell()
-		hintf("to recover: git commit -eF .git/COMMIT_EDITMSG")
+		if problemOutput(cmd) {
+			line, col := locateInText(body, pattern)
+			problemf(idMsgPattern, args[0], line, col, "match %q in commit message", bc.display(pattern))
+		} else {
+			blockf(idMsgPattern, "match %q in commit message", bc.display(pattern))
+			bell()
+			hintf("to recover: git commit -eF .git/COMMIT_EDITMSG")
+			explainViolation(cmd, bc, explanation{Phase: "msg", Pattern: pattern})
+		}
 	}
-	return fmt.Errorf("policy violation: %q found in commit message", pattern)
+	return violationf(idMsgPattern, "%q found in commit message", bc.display(pattern))
 }
 
 // msgContentLines returns non-blank, non-comment lines from a commit message.
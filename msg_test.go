@@ -240,6 +240,30 @@ func TestRunMsg_MaxLenOK(t *testing.T) {
 	}
 }
 
+func TestRunMsg_CRLF(t *testing.T) {
+	dir := t.TempDir()
+
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[block]\nmsg = [\"bot\"]\nmsg_max_len = 7\n"), 0644)
+
+	msgFile := filepath.Join(dir, "COMMIT_EDITMSG")
+	os.WriteFile(msgFile, []byte("fix bug\r\n\r\nSigned-off-by: Bot\r\n"), 0644)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"check", "msg", "--quiet", msgFile})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("a 7-char subject ending in CRLF should fit msg_max_len = 7, got: %v", err)
+	}
+	data, _ := os.ReadFile(msgFile)
+	if string(data) != "fix bug\r\n\r\n" {
+		t.Errorf("rewritten message = %q, want CRLF endings kept", data)
+	}
+}
+
 func TestRunMsg_MaxLenSkipsComments(t *testing.T) {
 	dir := t.TempDir()
 
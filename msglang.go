@@ -0,0 +1,238 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+)
+
+// [msg] on_language values: what a message in the wrong language does.
+const (
+	onLanguageBlock = "block"
+	onLanguageWarn  = "warn"
+)
+
+// languageTrailer marks a message as deliberately written in another
+// language; its presence skips the [msg] language check for that commit.
+const languageTrailer = "Snag-Language"
+
+// msgLanguage is one language [msg] language accepts. Languages written in
+// the Latin alphabet are told apart by their common short words; the rest
+// are recognized by script alone.
+type msgLanguage struct {
+	Code   string
+	Name   string
+	Script string
+	Words  []string // frequent words, distinctive enough to vote; Latin only
+}
+
+var msgLanguages = []msgLanguage{
+	{"en", "English", "latin", []string{"the", "and", "of", "to", "is", "in", "for", "with", "this", "that", "it", "on", "be", "are", "was", "not", "from", "by", "when", "should", "add", "fix", "use", "remove", "update", "instead"}},
+	{"de", "German", "latin", []string{"der", "die", "das", "und", "ist", "nicht", "mit", "für", "von", "zu", "den", "dem", "ein", "eine", "auf", "auch", "wird", "werden", "bei", "hinzugefügt", "entfernt", "behoben", "statt"}},
+	{"fr", "French", "latin", []string{"le", "la", "les", "et", "est", "des", "du", "un", "une", "pour", "dans", "pas", "avec", "sur", "que", "qui", "au", "ajout", "ajoute", "correction", "corrige", "supprime"}},
+	{"es", "Spanish", "latin", []string{"el", "la", "los", "las", "y", "es", "del", "para", "con", "por", "una", "que", "se", "al", "añade", "agrega", "corrige", "elimina", "cuando"}},
+	{"it", "Italian", "latin", []string{"il", "di", "che", "della", "per", "con", "non", "sono", "gli", "nel", "una", "aggiunto", "aggiunge", "corretto", "corregge", "rimuove", "quando"}},
+	{"pt", "Portuguese", "latin", []string{"o", "os", "da", "do", "das", "dos", "para", "com", "não", "uma", "que", "ao", "adiciona", "corrige", "quando", "arquivo"}},
+	{"nl", "Dutch", "latin", []string{"het", "een", "van", "niet", "voor", "met", "zijn", "wordt", "ook", "toegevoegd", "verwijderd", "opgelost", "bij"}},
+	{"ru", "Russian", "cyrillic", nil},
+	{"uk", "Ukrainian", "cyrillic", nil},
+	{"bg", "Bulgarian", "cyrillic", nil},
+	{"el", "Greek", "greek", nil},
+	{"he", "Hebrew", "hebrew", nil},
+	{"ar", "Arabic", "arabic", nil},
+	{"hi", "Hindi", "devanagari", nil},
+	{"zh", "Chinese", "han", nil},
+	{"ja", "Japanese", "kana", nil},
+	{"ko", "Korean", "hangul", nil},
+}
+
+// scriptNames describes a script when no single language can be named.
+var scriptNames = map[string]string{
+	"latin": "Latin-script text", "cyrillic": "Cyrillic", "greek": "Greek",
+	"hebrew": "Hebrew", "arabic": "Arabic", "devanagari": "Devanagari",
+	"han": "Chinese", "kana": "Japanese", "hangul": "Korean",
+}
+
+func findMsgLanguage(code string) *msgLanguage {
+	for i, l := range msgLanguages {
+		if l.Code == strings.ToLower(code) {
+			return &msgLanguages[i]
+		}
+	}
+	return nil
+}
+
+func msgLanguageCodes() []string {
+	codes := make([]string, len(msgLanguages))
+	for i, l := range msgLanguages {
+		codes[i] = l.Code
+	}
+	return codes
+}
+
+// msgNoise is text that says nothing about the prose language: URLs, code
+// spans, and paths or identifiers with dots, slashes or underscores.
+var msgNoise = regexp.MustCompile("`[^`]*`|\\bhttps?://\\S+|\\S*\\w[./_]\\w\\S*")
+
+// minLanguageLetters is the least prose a verdict is based on; a two-word
+// subject like "Bump deps" is too short to call.
+const minLanguageLetters = 20
+
+// letterScript names the script of a letter as used by msgLanguages.
+func letterScript(r rune) string {
+	switch {
+	case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+		return "kana"
+	case unicode.Is(unicode.Han, r):
+		return "han"
+	case unicode.Is(unicode.Hangul, r):
+		return "hangul"
+	case unicode.Is(unicode.Cyrillic, r):
+		return "cyrillic"
+	case unicode.Is(unicode.Greek, r):
+		return "greek"
+	case unicode.Is(unicode.Hebrew, r):
+		return "hebrew"
+	case unicode.Is(unicode.Arabic, r):
+		return "arabic"
+	case unicode.Is(unicode.Devanagari, r):
+		return "devanagari"
+	case unicode.Is(unicode.Latin, r):
+		return "latin"
+	}
+	return ""
+}
+
+// detectMsgLanguage guesses the language of text relative to want. It
+// returns a description of what the text looks like and true only when the
+// text is clearly not in want; short or mixed text always passes.
+func detectMsgLanguage(text string, want *msgLanguage) (string, bool) {
+	text = msgNoise.ReplaceAllString(text, " ")
+	scripts := map[string]int{}
+	letters := 0
+	for _, r := range text {
+		if s := letterScript(r); s != "" {
+			scripts[s]++
+			letters++
+		}
+	}
+	if letters < minLanguageLetters {
+		return "", false
+	}
+	// Japanese mixes kanji with kana; any kana at all makes Han text Japanese.
+	if scripts["kana"] > 0 {
+		scripts["kana"] += scripts["han"]
+		delete(scripts, "han")
+	}
+	dominant := ""
+	for s, n := range scripts {
+		if n*2 > letters {
+			dominant = s
+		}
+	}
+	if dominant == "" {
+		return "", false
+	}
+	if dominant != want.Script {
+		var names []string
+		for _, l := range msgLanguages {
+			if l.Script == dominant {
+				names = append(names, l.Name)
+			}
+		}
+		if len(names) == 1 {
+			return names[0], true
+		}
+		return scriptNames[dominant], true
+	}
+	if dominant != "latin" {
+		return "", false
+	}
+
+	votes := map[string]int{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		for _, l := range msgLanguages {
+			if slices.Contains(l.Words, w) {
+				votes[l.Code]++
+			}
+		}
+	}
+	best, bestVotes := "", 0
+	codes := make([]string, 0, len(votes))
+	for code := range votes {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if votes[code] > bestVotes {
+			best, bestVotes = code, votes[code]
+		}
+	}
+	// A clear verdict only: enough votes, and well ahead of the wanted language.
+	if best == "" || best == want.Code || bestVotes < 3 || bestVotes <= 2*votes[want.Code] {
+		return "", false
+	}
+	return findMsgLanguage(best).Name, true
+}
+
+// checkMsgLanguage enforces [msg] language on the message's content lines.
+// A Snag-Language trailer skips the check for messages that are meant to
+// be in another language, such as a translation update.
+func checkMsgLanguage(cmd *cobra.Command, bc *BlockConfig, path string, lines []string) error {
+	want := findMsgLanguage(bc.MsgLanguage)
+	if want == nil {
+		return nil
+	}
+	var prose []string
+	for i, line := range msgContentLines(lines) {
+		// The subject is prose even when it reads "fix: ..." like a trailer.
+		if i > 0 && isTrailerLine(line) {
+			if key, _, _ := strings.Cut(line, ":"); strings.EqualFold(key, languageTrailer) {
+				return nil
+			}
+			continue
+		}
+		prose = append(prose, line)
+	}
+	looks, wrong := detectMsgLanguage(strings.Join(prose, "\n"), want)
+	if !wrong {
+		return nil
+	}
+
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	if bc.OnMsgLanguage == onLanguageWarn {
+		if !quiet {
+			warnf("commit message looks like %s, not %s ([msg] language = %q)", looks, want.Name, want.Code)
+		}
+		return nil
+	}
+	if !quiet {
+		if problemOutput(cmd) {
+			problemf(idMsgLanguage, path, 1, 1, "commit message looks like %s, not %s", looks, want.Name)
+		} else {
+			blockf(idMsgLanguage, "commit message looks like %s, not %s", looks, want.Name)
+			bell()
+			hintf("to recover: git commit -eF .git/COMMIT_EDITMSG")
+			hintf("if it is meant to be, add a trailer: %s: %s", languageTrailer, looks)
+		}
+	}
+	return violationf(idMsgLanguage, "commit message is not in %s", want.Name)
+}
+
+// validateMsgSection checks a file's [msg] settings.
+func validateMsgSection(m msgSection) error {
+	if m.Language != "" && findMsgLanguage(m.Language) == nil {
+		return fmt.Errorf("msg.language: unknown language %q (known: %s)", m.Language, strings.Join(msgLanguageCodes(), ", "))
+	}
+	switch m.OnLanguage {
+	case "", onLanguageBlock, onLanguageWarn:
+	default:
+		return fmt.Errorf("msg.on_language must be %q or %q, got %q", onLanguageBlock, onLanguageWarn, m.OnLanguage)
+	}
+	return nil
+}
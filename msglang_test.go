@@ -0,0 +1,64 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectMsgLanguage(t *testing.T) {
+	en := findMsgLanguage("en")
+	cases := []struct {
+		text string
+		want string // "" = passes
+	}{
+		{"Fix the race in the watcher when the config file is removed", ""},
+		{"Bump deps", ""},
+		{"Corrige le calcul des totaux pour les factures dans la vue mensuelle", "French"},
+		{"Behebt den Absturz, wenn die Datei nicht gefunden wird und der Pfad leer ist", "German"},
+		{"Исправлена ошибка при загрузке конфигурации из домашнего каталога", "Cyrillic"},
+		{"設定ファイルの読み込みを修正しました。キャッシュも更新します", "Japanese"},
+		// Identifiers, paths and code spans don't count as prose.
+		{"Update `la_table_des_matières` in docs/la/les/des.md for the release", ""},
+	}
+	for _, c := range cases {
+		got, wrong := detectMsgLanguage(c.text, en)
+		if wrong != (c.want != "") || got != c.want {
+			t.Errorf("%q: got %q, %v; want %q", c.text, got, wrong, c.want)
+		}
+	}
+	if _, wrong := detectMsgLanguage("Corrige le calcul des totaux pour les factures dans la vue", findMsgLanguage("fr")); wrong {
+		t.Error("French text should pass language = \"fr\"")
+	}
+}
+
+func TestRunMsg_Language(t *testing.T) {
+	dir := t.TempDir()
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+	msgFile := filepath.Join(dir, "COMMIT_EDITMSG")
+	run := func(config, msg string) error {
+		os.WriteFile(filepath.Join(dir, "snag.toml"), []byte(config), 0644)
+		os.WriteFile(msgFile, []byte(msg), 0644)
+		rootCmd := buildRootCmd()
+		rootCmd.SetArgs([]string{"check", "msg", "-q", msgFile})
+		return rootCmd.Execute()
+	}
+
+	french := "Corrige le calcul des totaux pour les factures dans la vue mensuelle\n"
+	err := run("[msg]\nlanguage = \"en\"\n", french)
+	if err == nil || !strings.Contains(err.Error(), idMsgLanguage) {
+		t.Errorf("French message: err = %v", err)
+	}
+	if err := run("[msg]\nlanguage = \"en\"\n", french+"\nSnag-Language: fr\n"); err != nil {
+		t.Errorf("trailer should allow it: %v", err)
+	}
+	if err := run("[msg]\nlanguage = \"en\"\non_language = \"warn\"\n", french); err != nil {
+		t.Errorf("on_language = warn should not block: %v", err)
+	}
+	if err := run("[msg]\nlanguage = \"klingon\"\n", french); err == nil || !strings.Contains(err.Error(), "unknown language") {
+		t.Errorf("unknown language: err = %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+)
+
+// Near-miss tuning. One edit (insert, delete, substitute, or swap of two
+// neighbors) catches "pasword" and "passwrod" for "password"; patterns
+// shorter than nearMissMinLen are skipped because almost every short word
+// is one edit from some other word.
+const (
+	nearMissDetector = "near_miss"
+	nearMissMinLen   = 5
+	nearMissMax      = 3 // warnings printed per check before summarizing
+)
+
+// nearMiss is a token one edit away from a blocked pattern.
+type nearMiss struct {
+	Token   string
+	Pattern string
+	Path    string // "" for commit messages
+	Line    int
+}
+
+// nearMissCandidates returns the patterns eligible for near-miss matching:
+// single words of at least nearMissMinLen runes. Hashed patterns can't be
+// compared by edit distance, and norm: patterns already tolerate variants.
+func nearMissCandidates(patterns []string) []string {
+	var out []string
+	for _, p := range patterns {
+		if isHashPattern(p) || isNormPattern(p) || strings.ContainsFunc(p, unicode.IsSpace) || len([]rune(p)) < nearMissMinLen {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// findNearMisses scans numbered lines for tokens one edit from a candidate.
+// Each pattern is reported at most once per call.
+func findNearMisses(lines []numberedLine, path string, candidates []string) []nearMiss {
+	var out []nearMiss
+	reported := map[string]bool{}
+	for _, l := range lines {
+		for _, tok := range strings.FieldsFunc(lowerBytesafe(l.Text), isNearMissDelim) {
+			for _, p := range candidates {
+				if !reported[p] && nearMissIn(tok, p) {
+					reported[p] = true
+					out = append(out, nearMiss{Token: tok, Pattern: p, Path: path, Line: l.Line})
+				}
+			}
+		}
+	}
+	return out
+}
+
+// nearMissIn reports whether some run of tok is one edit from p, so
+// "db_pasword" counts as well as "pasword". A token containing p itself is
+// a match (warn-only or suppressed), not a near miss.
+func nearMissIn(tok, p string) bool {
+	if strings.Contains(tok, p) {
+		return false
+	}
+	rt, n := []rune(tok), len([]rune(p))
+	for size := n - 1; size <= n+1; size++ {
+		for i := 0; i+size <= len(rt); i++ {
+			if withinOneEdit(string(rt[i:i+size]), p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isNearMissDelim(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-'
+}
+
+// withinOneEdit reports whether a and b differ by exactly one insertion,
+// deletion, substitution, or transposition of adjacent runes.
+func withinOneEdit(a, b string) bool {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) < len(rb) {
+		ra, rb = rb, ra
+	}
+	switch len(ra) - len(rb) {
+	case 0:
+		i := 0
+		for i < len(ra) && ra[i] == rb[i] {
+			i++
+		}
+		if i == len(ra) {
+			return false // identical
+		}
+		if string(ra[i+1:]) == string(rb[i+1:]) {
+			return true // substitution
+		}
+		return i+1 < len(ra) && ra[i] == rb[i+1] && ra[i+1] == rb[i] && string(ra[i+2:]) == string(rb[i+2:])
+	case 1:
+		i := 0
+		for i < len(rb) && ra[i] == rb[i] {
+			i++
+		}
+		return string(ra[i+1:]) == string(rb[i:])
+	}
+	return false
+}
+
+// diffNearMisses finds near misses on added lines of a unified diff,
+// honoring skip rules, .snagignore, and [detect.near_miss] exclude.
+func diffNearMisses(bc *BlockConfig, diff string, patterns []string, rules skipRules) []nearMiss {
+	d := findDetector(nearMissDetector)
+	var out []nearMiss
+	for _, f := range splitDiffFiles(diff) {
+		if rules.reason(f) != "" || bc.detectExcluded(d, f.Path) {
+			continue
+		}
+		kept, _ := rules.unsuppressed(f.Path, patterns)
+		out = append(out, findNearMisses(addedLines(f.Body), f.Path, nearMissCandidates(kept))...)
+	}
+	return out
+}
+
+// textNearMisses finds near misses in a commit message or other plain text.
+func textNearMisses(text string, patterns []string) []nearMiss {
+	var lines []numberedLine
+	for i, l := range strings.Split(text, "\n") {
+		lines = append(lines, numberedLine{Line: i + 1, Text: l})
+	}
+	return findNearMisses(lines, "", nearMissCandidates(patterns))
+}
+
+// nearMissOn reports whether [detect.near_miss] is enabled.
+func (bc *BlockConfig) nearMissOn() bool {
+	return bc.DetectEnabled[nearMissDetector]
+}
+
+// warnNearMisses prints near misses as warnings; they never block. Tokens
+// close to a sensitive pattern are masked like the pattern itself.
+func warnNearMisses(cmd *cobra.Command, bc *BlockConfig, misses []nearMiss, where string) {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	if quiet || len(misses) == 0 {
+		return
+	}
+	for i, m := range misses {
+		if i == nearMissMax {
+			warnf("%d more near miss(es) in %s", len(misses)-i, where)
+			break
+		}
+		tok := m.Token
+		if bc.Sensitive[m.Pattern] {
+			tok = redact(tok)
+		}
+		loc := where
+		if m.Path != "" {
+			loc = fmt.Sprintf("%s:%d", m.Path, m.Line)
+		}
+		if problemOutput(cmd) {
+			file := m.Path
+			if file == "" {
+				file = where
+			}
+			problem(file, m.Line, 1, "warning", "%q is one edit from blocked pattern %q [%s]", tok, bc.display(m.Pattern), idNearMiss)
+			continue
+		}
+		warnf("%q at %s is one edit from blocked pattern %q [%s]", tok, loc, bc.display(m.Pattern), idNearMiss)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWithinOneEdit(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"pasword", "password", true},   // deletion
+		{"passwordd", "password", true}, // insertion
+		{"passw0rd", "password", true},  // substitution
+		{"passowrd", "password", true},  // transposition
+		{"password", "password", false}, // identical
+		{"pasw0rd", "password", false},  // two edits
+		{"passport", "password", false},
+		{"ab", "password", false},
+	}
+	for _, tt := range tests {
+		if got := withinOneEdit(tt.a, tt.b); got != tt.want {
+			t.Errorf("withinOneEdit(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestNearMissCandidates(t *testing.T) {
+	got := nearMissCandidates([]string{"todo", "password", "do not merge", hashToken("secret"), "hunter2"})
+	if strings.Join(got, ",") != "password,hunter2" {
+		t.Errorf("candidates = %v", got)
+	}
+}
+
+func TestRunDiff_NearMiss(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	run := func() (string, error) {
+		oldStderr := os.Stderr
+		r, w, _ := os.Pipe()
+		os.Stderr = w
+		rootCmd := buildRootCmd()
+		rootCmd.SetArgs([]string{"check", "diff"})
+		err := rootCmd.Execute()
+		w.Close()
+		os.Stderr = oldStderr
+		out, _ := io.ReadAll(r)
+		return string(out), err
+	}
+
+	stageFile(t, dir, "config.yml", "db_pasword: x\n")
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"password\"]\n"), 0644)
+	if out, err := run(); err != nil || strings.Contains(out, "one edit") {
+		t.Fatalf("near_miss should be off by default: err=%v out=%q", err, out)
+	}
+
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"password\"]\n[detect.near_miss]\nenabled = true\n"), 0644)
+	out, err := run()
+	if err != nil {
+		t.Fatalf("near misses must not block, got %v", err)
+	}
+	if !strings.Contains(out, `"db_pasword" at config.yml:1 is one edit from blocked pattern "password" [SNAG034]`) {
+		t.Errorf("stderr = %q", out)
+	}
+
+	// [detect] near_miss = true is shorthand for the table.
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"password\"]\n[detect]\nnear_miss = true\n"), 0644)
+	if out, err := run(); err != nil || !strings.Contains(out, "one edit") {
+		t.Errorf("shorthand: err=%v out=%q", err, out)
+	}
+}
+
+func TestNearMissIn(t *testing.T) {
+	for tok, want := range map[string]bool{
+		"db_pasword":  true,
+		"pasword_env": true,
+		"password":    false, // an exact match is a match, not a near miss
+		"passport":    false,
+	} {
+		if got := nearMissIn(tok, "password"); got != want {
+			t.Errorf("nearMissIn(%q) = %v, want %v", tok, got, want)
+		}
+	}
+}
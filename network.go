@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// behaviorSection holds switches that change how snag runs rather than
+// what it blocks:
+//
+//	[behavior]
+//	network = false         # never touch the network, whatever the feature
+//	version_check = false   # no newer-release hint in snag doctor
+//	policy_trailer = true   # commit-msg adds Snag-Policy: <digest>
+type behaviorSection struct {
+	Network       *bool `toml:"network"`        // nil = allowed
+	VersionCheck  *bool `toml:"version_check"`  // nil = enabled
+	PolicyTrailer bool  `toml:"policy_trailer"` // record snag config hash in each commit
+	PlainOutput   bool  `toml:"plain_output"`   // screen-reader-friendly output, see accessibility.go
+}
+
+// networkFeature is one snag feature that can reach the network. Every
+// such feature is listed here and checks networkAllowed before connecting,
+// so snag doctor can report them and the kill switch covers them all.
+type networkFeature struct {
+	Name    string
+	Uses    string // what it contacts
+	Offline string // what it does instead when the network is off
+}
+
+var networkFeatures = []networkFeature{
+	{"audit --remote", "git fetch of the audited branch", "audits the existing remote-tracking ref, as with --no-fetch"},
+	{"version check (snag doctor)", "GitHub releases API, at most once a day", "uses the cached result, if any"},
+	{"ci --report bitbucket", "Bitbucket Code Insights API via the Pipelines proxy", "writes the report without posting it"},
+	{"webhook serve", "git fetch of pushed ranges and forge status APIs", "refuses to start"},
+	{"init --template", "git clone of a templates repository given by URL", "fails; built-in and local-directory templates still work"},
+	{"ticket verification (check msg)", "the [tickets] Jira or Linear API, cached for an hour", "skips verification with a warning"},
+	{"packs update", "the signed rule-pack channel (SNAG_PACKS_URL)", "fails; installed and built-in packs keep working"},
+}
+
+// networkAllowed reports whether snag may use the network, and if not, why.
+// SNAG_OFFLINE=1 wins over config; any config with [behavior] network =
+// false turns it off for the whole resolved chain.
+func networkAllowed(bc *BlockConfig) (bool, string) {
+	if os.Getenv("SNAG_OFFLINE") == "1" {
+		return false, "SNAG_OFFLINE=1"
+	}
+	if bc != nil && bc.NetworkOffBy != "" {
+		return false, fmt.Sprintf("[behavior] network = false in %s", bc.NetworkOffBy)
+	}
+	return true, ""
+}
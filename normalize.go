@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// normPatternPrefix marks a pattern matched after undoing common
+// obfuscation on both the pattern and the text: zero-width characters are
+// dropped, look-alike digits and symbols fold to letters, and separators
+// disappear, so "norm:password" also catches "p@ssw0rd", "p.a.s.s.w.o.r.d",
+// and "pass" + "word". Plain patterns never get this treatment; folding
+// everything would make "l0g" match "log" all over ordinary code.
+const normPatternPrefix = "norm:"
+
+// isNormPattern reports whether p is a norm:<text> pattern.
+func isNormPattern(p string) bool {
+	return strings.HasPrefix(p, normPatternPrefix)
+}
+
+// normBody returns the normalized text a norm: pattern looks for.
+func normBody(p string) string {
+	return deobfuscate(strings.TrimPrefix(p, normPatternPrefix))
+}
+
+// leetFold maps look-alike characters onto one representative. Both sides
+// of a comparison are folded, so ambiguous digits like 1 (i or l) can map
+// to a class instead of guessing: "p1n", "pin", and "pln" all fold alike.
+var leetFold = map[rune]rune{
+	'0': 'o',
+	'1': 'i', 'l': 'i', '!': 'i', '|': 'i',
+	'3': 'e',
+	'4': 'a', '@': 'a',
+	'5': 's', '$': 's',
+	'7': 't',
+}
+
+// isObfuscationSeparator reports whether r is dropped during normalization:
+// whitespace and the punctuation people wedge between letters or use to
+// split a string literal.
+func isObfuscationSeparator(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune(".-_*~'\"`+", r)
+}
+
+// isZeroWidth reports whether r renders as nothing.
+func isZeroWidth(r rune) bool {
+	switch r {
+	case '\u200b', '\u200c', '\u200d', '\u2060', '\ufeff', '\u00ad':
+		return true
+	}
+	return false
+}
+
+// deobfuscate lowercases s and applies the norm: folding.
+func deobfuscate(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range lowerBytesafe(s) {
+		if isZeroWidth(r) || isObfuscationSeparator(r) {
+			continue
+		}
+		if f, ok := leetFold[r]; ok {
+			r = f
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// validateNormPatterns rejects norm: patterns that fold to nothing (they
+// would match every line) or wrap a hashed pattern.
+func validateNormPatterns(b blockSection) error {
+	type entry struct{ key, pattern string }
+	var all []entry
+	for _, p := range b.Diff {
+		all = append(all, entry{"block.diff", p})
+	}
+	for _, p := range b.Msg {
+		all = append(all, entry{"block.msg", p})
+	}
+	if b.Push != nil {
+		for _, p := range *b.Push {
+			all = append(all, entry{"block.push", p})
+		}
+	}
+	for i, r := range b.Rule {
+		p := r.Pattern
+		if r.Normalize {
+			p = normPatternPrefix + p
+		}
+		all = append(all, entry{fmt.Sprintf("block.rule[%d]", i), p})
+	}
+	for _, e := range all {
+		p := lowerBytesafe(e.pattern)
+		if !isNormPattern(p) {
+			continue
+		}
+		if isHashPattern(strings.TrimPrefix(p, normPatternPrefix)) {
+			return fmt.Errorf("%s: %q: hashed patterns can't be normalized", e.key, e.pattern)
+		}
+		if normBody(p) == "" {
+			return fmt.Errorf("%s: %q is empty after normalization", e.key, e.pattern)
+		}
+	}
+	return nil
+}
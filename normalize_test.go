@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMatchesPattern_Norm(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want bool
+	}{
+		{"plain", "password = x", true},
+		{"leet", "p@ssw0rd = x", true},
+		{"dotted", "P.A.S.S.W.O.R.D", true},
+		{"zero width", "pass\u200bword", true},
+		{"split literal", `key := "pass" + "word"`, true},
+		{"dollar and five", "pa$5word", true},
+		{"unrelated", "passport number", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p, ok := matchesPattern(tc.text, []string{"norm:password"})
+			if ok != tc.want {
+				t.Fatalf("matchesPattern(%q) = %v, want %v", tc.text, ok, tc.want)
+			}
+			if ok && p != "norm:password" {
+				t.Errorf("matched %q, want the norm: pattern", p)
+			}
+		})
+	}
+	if _, ok := matchesPattern("p@ssw0rd", []string{"password"}); ok {
+		t.Error("plain patterns must not be normalized")
+	}
+}
+
+func TestDeobfuscate_OneIsIOrL(t *testing.T) {
+	for _, s := range []string{"p1n", "pin", "pln", "P|N"} {
+		if got := deobfuscate(s); got != "pin" {
+			t.Errorf("deobfuscate(%q) = %q, want %q", s, got, "pin")
+		}
+	}
+}
+
+func TestLoadSnagTOML_NormValidation(t *testing.T) {
+	for name, body := range map[string]string{
+		"empty body":    "[block]\ndiff = [\"norm: ._\"]\n",
+		"hashed":        "[block]\nmsg = [\"norm:sha256:abc\"]\n",
+		"rule on hash":  "[[block.rule]]\npattern = \"sha256:abc\"\nnormalize = true\n",
+		"rule on empty": "[[block.rule]]\npattern = \"--\"\nnormalize = true\n",
+	} {
+		path := filepath.Join(t.TempDir(), "snag.toml")
+		os.WriteFile(path, []byte(body), 0644)
+		if _, err := loadSnagTOML(path); err == nil {
+			t.Errorf("%s: expected a config error", name)
+		}
+	}
+}
+
+func TestRunDiff_NormRule(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[block]\ndiff = [\"passwd\"]\n\n[[block.rule]]\npattern = \"hunter2\"\nnormalize = true\n"), 0644)
+	stageFile(t, dir, "notes.txt", "creds: HUNT3R-2\n")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"check", "diff", "--quiet"})
+	err := rootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "norm:hunter2") {
+		t.Fatalf("expected a norm:hunter2 violation, got: %v", err)
+	}
+}
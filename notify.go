@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// notifySection configures how snag gets attention when a hook blocks:
+//
+//	[notify]
+//	desktop = true
+//	interval = "1m"   # at most one notification per interval (default 1m)
+//	bell = false      # no terminal bell on violations
+//	visual_bell = true
+type notifySection struct {
+	Desktop    bool   `toml:"desktop"`
+	Interval   string `toml:"interval"`
+	Bell       *bool  `toml:"bell"` // nil = ring
+	VisualBell bool   `toml:"visual_bell"`
+}
+
+const (
+	defaultNotifyInterval = time.Minute
+	notifyStampFile       = "notify-last" // unix time of the last notification
+	notifyBodyMax         = 200
+)
+
+// notifier shows a desktop notification; tests replace it.
+var notifier = desktopNotify
+
+// notifyBlock pops a desktop notification for a policy violation when
+// [notify] desktop is on, at most once per interval so a rebase replaying
+// twenty blocked commits doesn't bury the screen. Never fails the hook.
+func notifyBlock(dir, hook string, err error) {
+	msg, ok := strings.CutPrefix(err.Error(), "policy violation: ")
+	if !ok || os.Getenv("CI") != "" {
+		return
+	}
+	cwd, werr := os.Getwd()
+	if werr != nil {
+		return
+	}
+	bc, rerr := resolveBlockConfigAt(nil, cwd)
+	if rerr != nil || !bc.NotifyDesktop {
+		return
+	}
+	interval := bc.NotifyInterval
+	if interval == 0 {
+		interval = defaultNotifyInterval
+	}
+	// Checking and refreshing the stamp under its lock means two hooks
+	// blocking at once raise one notification, not two.
+	now := time.Now()
+	due := false
+	updateStateFile(dir, notifyStampFile, func(old []byte) []byte {
+		if last, perr := strconv.ParseInt(strings.TrimSpace(string(old)), 10, 64); perr == nil && now.Sub(time.Unix(last, 0)) < interval {
+			return old
+		}
+		due = true
+		return []byte(strconv.FormatInt(now.Unix(), 10) + "\n")
+	})
+	if !due {
+		return
+	}
+
+	body := strings.ReplaceAll(msg, "\n", " ")
+	if len(body) > notifyBodyMax {
+		// Back off to a character boundary so the notifier isn't handed
+		// half of a multi-byte character.
+		cut := notifyBodyMax - 3
+		for cut > 0 && !utf8.RuneStart(body[cut]) {
+			cut--
+		}
+		body = body[:cut] + "..."
+	}
+	notifier("snag blocked "+hook, body)
+}
+
+// desktopNotify starts the platform's notifier without waiting for it.
+func desktopNotify(title, body string) error {
+	name, args, ok := notifyCommand(runtime.GOOS, title, body)
+	if !ok {
+		return fmt.Errorf("no desktop notifier for %s", runtime.GOOS)
+	}
+	if _, err := exec.LookPath(name); err != nil {
+		return err
+	}
+	return exec.Command(name, args...).Start()
+}
+
+// notifyCommand returns the command that shows a notification on goos:
+// osascript on macOS, a PowerShell toast on Windows, notify-send elsewhere.
+func notifyCommand(goos, title, body string) (name string, args []string, ok bool) {
+	switch goos {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(body), appleScriptString(title))
+		return "osascript", []string{"-e", script}, true
+	case "windows":
+		esc := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "'", "''")
+		script := `[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null;` +
+			`$x = [Windows.Data.Xml.Dom.XmlDocument, Windows.Data.Xml.Dom, ContentType = WindowsRuntime]::new();` +
+			`$x.LoadXml('<toast><visual><binding template="ToastGeneric"><text>` + esc.Replace(title) + `</text><text>` + esc.Replace(body) + `</text></binding></visual></toast>');` +
+			`[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('snag').Show([Windows.UI.Notifications.ToastNotification]::new($x))`
+		return "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", script}, true
+	case "linux", "freebsd", "openbsd", "netbsd", "dragonfly":
+		return "notify-send", []string{"--app-name=snag", title, body}, true
+	}
+	return "", nil, false
+}
+
+// appleScriptString quotes s as an AppleScript string literal.
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestNotifyCommand(t *testing.T) {
+	name, args, ok := notifyCommand("darwin", "snag blocked diff", `match "a\b" found`)
+	if !ok || name != "osascript" || args[1] != `display notification "match \"a\\b\" found" with title "snag blocked diff"` {
+		t.Errorf("darwin: %s %q", name, args)
+	}
+	name, args, ok = notifyCommand("linux", "t", "b")
+	if !ok || name != "notify-send" || !slices.Equal(args[1:], []string{"t", "b"}) {
+		t.Errorf("linux: %s %q", name, args)
+	}
+	name, args, ok = notifyCommand("windows", "t", "it's <b>")
+	if !ok || name != "powershell" || !strings.Contains(args[len(args)-1], "it''s &lt;b&gt;") {
+		t.Errorf("windows: %s %q", name, args)
+	}
+	if _, _, ok := notifyCommand("plan9", "t", "b"); ok {
+		t.Error("plan9 should have no notifier")
+	}
+}
+
+func TestNotifyBlockRateLimited(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[notify]\ndesktop = true\ninterval = \"1h\"\n"), 0644)
+	t.Setenv("CI", "")
+	orig, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(orig)
+
+	var shown []string
+	defer func(prev func(string, string) error) { notifier = prev }(notifier)
+	notifier = func(title, body string) error {
+		shown = append(shown, title+": "+body)
+		return nil
+	}
+	state := t.TempDir()
+
+	notifyBlock(state, "diff", errors.New("not a violation"))
+	notifyBlock(state, "diff", errors.New(`policy violation: "hack" found in staged diff`))
+	notifyBlock(state, "msg", errors.New(`policy violation: "wip" found in commit message`))
+	if len(shown) != 1 || shown[0] != `snag blocked diff: "hack" found in staged diff` {
+		t.Fatalf("shown = %q, want one diff notification", shown)
+	}
+
+	os.WriteFile(filepath.Join(state, notifyStampFile), []byte("0\n"), 0644)
+	notifyBlock(state, "msg", errors.New(`policy violation: "wip" found in commit message`))
+	if len(shown) != 2 {
+		t.Errorf("notification after the interval suppressed: %q", shown)
+	}
+}
+
+func TestNotifyBlockTruncatesOnCharacterBoundary(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[notify]\ndesktop = true\n"), 0644)
+	t.Setenv("CI", "")
+	orig, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(orig)
+
+	var body string
+	defer func(prev func(string, string) error) { notifier = prev }(notifier)
+	notifier = func(_, b string) error {
+		body = b
+		return nil
+	}
+
+	// é is two bytes, so the odd byte limit falls in the middle of one.
+	notifyBlock(t.TempDir(), "msg", errors.New("policy violation: "+strings.Repeat("é", notifyBodyMax)))
+	if !utf8.ValidString(body) || !strings.HasSuffix(body, "é...") || len(body) > notifyBodyMax {
+		t.Errorf("body = %q (%d bytes), want valid UTF-8 of at most %d bytes ending in an ellipsis", body, len(body), notifyBodyMax)
+	}
+}
@@ -1,11 +1,15 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/muesli/termenv"
+	"github.com/spf13/cobra"
 	"golang.org/x/term"
 )
 
@@ -50,27 +54,142 @@ var (
 )
 
 func errorf(format string, a ...any) {
-	msg := fmt.Sprintf(format, a...)
+	msg := plainText(fmt.Sprintf(format, a...))
+	if plainOutput {
+		msg = "error: " + msg
+	}
 	fmt.Fprintln(os.Stderr, errorStyle.Render("snag:")+" "+msg)
 }
 
 func warnf(format string, a ...any) {
-	msg := fmt.Sprintf(format, a...)
+	msg := plainText(fmt.Sprintf(format, a...))
+	if plainOutput {
+		msg = "warning: " + msg
+	}
 	fmt.Fprintln(os.Stderr, warnStyle.Render("snag:")+" "+msg)
 }
 
 func infof(format string, a ...any) {
-	msg := fmt.Sprintf(format, a...)
+	msg := plainText(fmt.Sprintf(format, a...))
 	fmt.Fprintln(os.Stderr, infoStyle.Render("snag:")+" "+msg)
 }
 
 func hintf(format string, a ...any) {
-	msg := fmt.Sprintf(format, a...)
+	msg := plainText(fmt.Sprintf(format, a...))
 	fmt.Fprintln(os.Stderr, hintStyle.Render("  "+msg))
 }
 
+// hintArg quotes a path for a command shown in a hint, so that copying the
+// command still works when the path has spaces. Double quotes are read the
+// same way by sh, cmd.exe and PowerShell for ordinary paths.
+func hintArg(s string) string {
+	if !strings.ContainsAny(s, " \t&()'") {
+		return s
+	}
+	return `"` + s + `"`
+}
+
+// visualBellFlash is how long the visual bell holds reverse video.
+const visualBellFlash = 100 * time.Millisecond
+
 func bell() {
-	if term.IsTerminal(int(os.Stderr.Fd())) {
-		fmt.Fprint(os.Stderr, "\a")
+	if bellMode == bellOff || !term.IsTerminal(int(os.Stderr.Fd())) {
+		return
 	}
+	if bellMode == bellVisual {
+		// DECSCNM: flash the whole screen in reverse video and back.
+		fmt.Fprint(os.Stderr, "\x1b[?5h")
+		time.Sleep(visualBellFlash)
+		fmt.Fprint(os.Stderr, "\x1b[?5l")
+		return
+	}
+	fmt.Fprint(os.Stderr, "\a")
+}
+
+// Output formats accepted by --format.
+const (
+	formatText   = "text"
+	formatVSCode = "vscode"
+	formatJSON   = "json"
+	formatJUnit  = "junit" // audit and ci only
+)
+
+// problemFormat is how problem writes violations: formatVSCode or
+// formatJSON. validateFormat sets it from --format.
+var problemFormat = formatVSCode
+
+// outputFormat returns the --format value, defaulting to text.
+func outputFormat(cmd *cobra.Command) string {
+	f, _ := cmd.Flags().GetString("format")
+	if f == "" {
+		return formatText
+	}
+	return f
+}
+
+// problemOutput reports whether violations go to stdout as problem lines
+// (vscode or json) instead of the human-readable block on stderr.
+func problemOutput(cmd *cobra.Command) bool {
+	f := outputFormat(cmd)
+	return f == formatVSCode || f == formatJSON
+}
+
+// validateFormat rejects unknown --format values before any command runs.
+// Commands that define their own --format (report digest) validate it
+// themselves.
+func validateFormat(cmd *cobra.Command, args []string) error {
+	if cmd.LocalNonPersistentFlags().Lookup("format") != nil {
+		return nil
+	}
+	switch f := outputFormat(cmd); f {
+	case formatText, formatVSCode, formatJSON:
+		problemFormat = formatVSCode
+		if f == formatJSON {
+			problemFormat = formatJSON
+		}
+		return nil
+	case formatJUnit:
+		if cmd.Name() == "audit" || cmd.Name() == "ci" {
+			return nil
+		}
+		return fmt.Errorf("--format junit is only supported by snag audit and snag ci")
+	default:
+		return fmt.Errorf("unknown --format %q (choose text, vscode, json, junit)", f)
+	}
+}
+
+// problem prints one violation as file:line:col: severity: message on
+// stdout — the shape VS Code problem matchers (and vim's errorformat) parse.
+// Unknown positions are reported as line 1, column 1 so the entry still
+// links to the file. With --format json it prints a jsonProblem per line
+// instead.
+func problem(file string, line, col int, severity, format string, a ...any) {
+	if line < 1 {
+		line = 1
+	}
+	if col < 1 {
+		col = 1
+	}
+	msg := fmt.Sprintf(format, a...)
+	if problemFormat == formatJSON {
+		data, _ := json.Marshal(jsonProblem{
+			File: file, Line: line, Col: col, Severity: severity,
+			Check: checkIDIn(msg), Message: msg, Env: currentHookEnv(),
+		})
+		fmt.Fprintf(os.Stdout, "%s\n", data)
+		return
+	}
+	fmt.Fprintf(os.Stdout, "%s:%d:%d: %s: %s\n", file, line, col, severity, msg)
+}
+
+// jsonProblem is one --format json violation. Env describes the repository
+// at the time of the check.
+type jsonProblem struct {
+	File     string  `json:"file"`
+	Line     int     `json:"line"`
+	Col      int     `json:"col"`
+	Severity string  `json:"severity"`
+	Check    string  `json:"check,omitempty"`
+	Message  string  `json:"message"`
+	Env      hookEnv `json:"env"`
 }
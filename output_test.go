@@ -162,3 +162,44 @@ func TestBell_NoOutputWhenPiped(t *testing.T) {
 		t.Errorf("expected no bell in pipe output, got: %q", got)
 	}
 }
+
+func TestProblem_Format(t *testing.T) {
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	problem("src/a.go", 12, 5, "error", "match %q in staged diff", "todo")
+	problem("msg", 0, 0, "warning", "no position")
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	buf := make([]byte, 1024)
+	n, _ := r.Read(buf)
+	want := "src/a.go:12:5: error: match \"todo\" in staged diff\nmsg:1:1: warning: no position\n"
+	if got := string(buf[:n]); got != want {
+		t.Errorf("problem output = %q, want %q", got, want)
+	}
+}
+
+func TestFormatFlag_RejectsUnknown(t *testing.T) {
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"version", "--format", "xml"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Fatal("expected error for unknown --format")
+	}
+}
+
+func TestHintArg(t *testing.T) {
+	tests := map[string]string{
+		"go.sum":                        "go.sum",
+		"My Scripts/scrub.py":           `"My Scripts/scrub.py"`,
+		`C:\Users\Dev\Program Files\x`:  `"C:\Users\Dev\Program Files\x"`,
+		`C:\Users\Dev\R&D\package.json`: `"C:\Users\Dev\R&D\package.json"`,
+	}
+	for in, want := range tests {
+		if got := hintArg(in); got != want {
+			t.Errorf("hintArg(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// languagePack is what packs_auto turns on for one kind of repository.
+type languagePack struct {
+	Name    string   `json:"name"`
+	Markers []string `json:"markers"` // any of these at the repository root detects the pack
+	// Build outputs that should never be committed: directories matched
+	// against any run of path segments, and base-name globs for files.
+	Dirs  []string `json:"dirs,omitempty"`
+	Files []string `json:"files,omitempty"`
+}
+
+// languagePacks is the registry packs_auto detects from. Each detected pack
+// enables the debug detector (whose patterns already key on file extension)
+// and the artifact detector with the pack's build-output paths.
+var languagePacks = []languagePack{
+	{Name: "go", Markers: []string{"go.mod"}, Files: []string{"*.exe", "*.test", "*.prof"}},
+	{Name: "node", Markers: []string{"package.json"}, Dirs: []string{"node_modules", ".next", ".nuxt"}, Files: []string{"npm-debug.log*", "yarn-error.log"}},
+	{Name: "ruby", Markers: []string{"Gemfile"}, Dirs: []string{".bundle", "vendor/bundle"}, Files: []string{"*.gem"}},
+	{Name: "python", Markers: []string{"pyproject.toml", "setup.py", "requirements.txt"}, Dirs: []string{"__pycache__", "*.egg-info", ".venv"}, Files: []string{"*.pyc", "*.pyo"}},
+	{Name: "rust", Markers: []string{"Cargo.toml"}, Dirs: []string{"target"}},
+}
+
+// activeLanguagePacks is languagePacks overlaid with the installed
+// "languages" rule pack: a pack entry replaces the built-in one of the same
+// name, and new names are added.
+func activeLanguagePacks() []languagePack {
+	pack, ok := installedRulePack("languages")
+	if !ok {
+		return languagePacks
+	}
+	out := append([]languagePack{}, pack.Languages...)
+	for _, p := range languagePacks {
+		if !slices.ContainsFunc(out, func(q languagePack) bool { return q.Name == p.Name }) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// packDetectors are the detectors a detected pack switches on.
+var packDetectors = []string{"debug", "artifact"}
+
+// detectPacks returns the packs whose marker files exist in root.
+func detectPacks(root string) []languagePack {
+	var out []languagePack
+	for _, p := range activeLanguagePacks() {
+		for _, m := range p.Markers {
+			if fileExists(filepath.Join(root, m)) {
+				out = append(out, p)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// repoRoot returns the work tree root containing dir, or dir itself.
+func repoRoot(dir string) string {
+	out, err := gitCommand("-C", dir, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return dir
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// applyPacks detects language packs for the repository at dir and enables
+// their detectors. A detector set explicitly under [detect.NAME] keeps that
+// setting, so packs_auto never overrides a deliberate choice.
+func applyPacks(bc *BlockConfig, dir string) {
+	if !bc.PacksAuto {
+		return
+	}
+	bc.Packs = nil
+	for _, p := range detectPacks(repoRoot(dir)) {
+		bc.Packs = append(bc.Packs, p.Name)
+	}
+	if len(bc.Packs) == 0 {
+		return
+	}
+	if bc.DetectEnabled == nil {
+		bc.DetectEnabled = make(map[string]bool)
+	}
+	for _, name := range packDetectors {
+		if _, set := bc.DetectEnabled[name]; !set {
+			bc.DetectEnabled[name] = true
+			bc.PackEnabled = append(bc.PackEnabled, name)
+		}
+	}
+}
+
+// checkArtifactPath is the "artifact" detector: it flags added files that
+// are build outputs of the repository's packs, or of every pack when the
+// detector was enabled by hand.
+func checkArtifactPath(bc *BlockConfig, p string) bool {
+	for _, pack := range activeLanguagePacks() {
+		if len(bc.Packs) > 0 && !slices.Contains(bc.Packs, pack.Name) {
+			continue
+		}
+		if matchPathGlob(pack.Files, p) || underDir(pack.Dirs, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// underDir reports whether p lies inside a directory matching one of dirs,
+// at any depth. A dir may span segments ("vendor/bundle"); each segment is
+// a path.Match glob.
+func underDir(dirs []string, p string) bool {
+	segs := strings.Split(path.Dir(p), "/")
+	for _, d := range dirs {
+		want := strings.Split(d, "/")
+		for i := 0; i+len(want) <= len(segs); i++ {
+			ok := true
+			for j, w := range want {
+				if m, _ := path.Match(w, segs[i+j]); !m {
+					ok = false
+					break
+				}
+			}
+			if ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// packsReport describes what packs_auto did, for snag config.
+func packsReport(bc *BlockConfig, dir string) []string {
+	if !bc.PacksAuto {
+		return nil
+	}
+	root := repoRoot(dir)
+	detected := detectPacks(root)
+	if len(detected) == 0 {
+		return []string{"no language detected at " + root}
+	}
+	var lines []string
+	for _, p := range detected {
+		var markers []string
+		for _, m := range p.Markers {
+			if fileExists(filepath.Join(root, m)) {
+				markers = append(markers, m)
+			}
+		}
+		var artifacts []string
+		for _, d := range p.Dirs {
+			artifacts = append(artifacts, d+"/")
+		}
+		artifacts = append(artifacts, p.Files...)
+		line := p.Name + " (" + strings.Join(markers, ", ") + ")"
+		if len(artifacts) > 0 {
+			line += ": artifacts " + strings.Join(artifacts, " ")
+		}
+		lines = append(lines, line)
+	}
+	if len(bc.PackEnabled) > 0 {
+		lines = append(lines, "enabled: detect."+strings.Join(bc.PackEnabled, ", detect."))
+	}
+	for _, name := range packDetectors {
+		if !slices.Contains(bc.PackEnabled, name) {
+			lines = append(lines, fmt.Sprintf("detect.%s: %v, kept from [detect.%s]", name, bc.DetectEnabled[name], name))
+		}
+	}
+	return lines
+}
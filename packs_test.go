@@ -0,0 +1,89 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestApplyPacks(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module x\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "package.json"), []byte("{}\n"), 0644)
+	sub := filepath.Join(dir, "cmd")
+	os.Mkdir(sub, 0755)
+
+	bc := &BlockConfig{PacksAuto: true}
+	applyPacks(bc, sub)
+	if !slices.Equal(bc.Packs, []string{"go", "node"}) {
+		t.Fatalf("Packs = %v, want [go node]", bc.Packs)
+	}
+	if !bc.DetectEnabled["debug"] || !bc.DetectEnabled["artifact"] {
+		t.Errorf("DetectEnabled = %v, want debug and artifact on", bc.DetectEnabled)
+	}
+
+	// An explicit [detect.debug] setting wins over the pack.
+	bc = &BlockConfig{PacksAuto: true, DetectEnabled: map[string]bool{"debug": false}}
+	applyPacks(bc, dir)
+	if bc.DetectEnabled["debug"] {
+		t.Error("packs_auto overrode [detect.debug] enabled = false")
+	}
+	if !slices.Equal(bc.PackEnabled, []string{"artifact"}) {
+		t.Errorf("PackEnabled = %v, want [artifact]", bc.PackEnabled)
+	}
+
+	// Without packs_auto nothing is detected.
+	bc = &BlockConfig{}
+	applyPacks(bc, dir)
+	if bc.Packs != nil || bc.DetectEnabled != nil {
+		t.Errorf("packs applied without packs_auto: %+v", bc)
+	}
+}
+
+func TestCheckArtifactPath(t *testing.T) {
+	node := &BlockConfig{Packs: []string{"node"}}
+	all := &BlockConfig{}
+	for _, tc := range []struct {
+		bc   *BlockConfig
+		path string
+		want bool
+	}{
+		{node, "node_modules/left-pad/index.js", true},
+		{node, "web/node_modules/x/y.js", true},
+		{node, "src/node_modules.md", false},
+		{node, "app/__pycache__/m.cpython-312.pyc", false}, // not a node artifact
+		{all, "app/__pycache__/m.cpython-312.pyc", true},
+		{all, "vendor/bundle/ruby/3.3/gems/x.rb", true},
+		{all, "vendor/x/bundle.rb", false},
+		{all, "pkg/foo.egg-info/PKG-INFO", true},
+		{all, "bin/app.exe", true},
+		{all, "main.go", false},
+	} {
+		if got := checkArtifactPath(tc.bc, tc.path); got != tc.want {
+			t.Errorf("checkArtifactPath(%v, %q) = %v, want %v", tc.bc.Packs, tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestArtifactDetector(t *testing.T) {
+	bc := &BlockConfig{Packs: []string{"node"}, DetectEnabled: map[string]bool{"artifact": true}}
+	added := "diff --git a/node_modules/x/index.js b/node_modules/x/index.js\n" +
+		"new file mode 100644\n--- /dev/null\n+++ b/node_modules/x/index.js\n@@ -0,0 +1 @@\n+module.exports = 1\n"
+	hit, ok := runDetectors(bc, added, skipRules{})
+	if !ok || hit.Detector.Name != "artifact" || hit.Path != "node_modules/x/index.js" {
+		t.Fatalf("runDetectors = %+v, %v; want artifact hit", hit, ok)
+	}
+
+	// Deleting a committed artifact is the fix, not a violation.
+	deleted := "diff --git a/node_modules/x/index.js b/node_modules/x/index.js\n" +
+		"deleted file mode 100644\n--- a/node_modules/x/index.js\n+++ /dev/null\n@@ -1 +0,0 @@\n-module.exports = 1\n"
+	if hit, ok := runDetectors(bc, deleted, skipRules{}); ok {
+		t.Errorf("deleted artifact flagged: %+v", hit)
+	}
+
+	bc.DetectExclude = map[string][]string{"artifact": {"node_modules/x/*"}}
+	if _, ok := runDetectors(bc, added, skipRules{}); ok {
+		t.Error("[detect.artifact] exclude not honoured")
+	}
+}
@@ -109,7 +109,7 @@ var findDiffPager = func() string {
 	}
 
 	// git config core.pager.
-	if out, err := exec.Command("git", "config", "core.pager").Output(); err == nil {
+	if out, err := gitCommand("config", "core.pager").Output(); err == nil {
 		p := strings.TrimSpace(string(out))
 		if p != "" {
 			if name := firstWord(p); name != "" {
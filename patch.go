@@ -0,0 +1,221 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// mailPatch is one patch from a mailbox or patch file: the commit message
+// reconstructed from the email and the diff that follows it.
+type mailPatch struct {
+	Subject string
+	Message string
+	Diff    string
+}
+
+// mboxFromLine starts each message in an mbox; format-patch writes
+// "From <sha> Mon Sep 17 00:00:00 2001". Requiring the weekday keeps a
+// commit message line starting "From " from splitting a patch.
+var mboxFromLine = regexp.MustCompile(`(?m)^From \S+ (?:Mon|Tue|Wed|Thu|Fri|Sat|Sun) .*$`)
+
+// patchSubjectPrefix is the [PATCH v2 1/3] tag git format-patch and
+// mailing lists put in front of the subject.
+var patchSubjectPrefix = regexp.MustCompile(`^(\[[^\]]*\]\s*)+`)
+
+// parsePatches splits input into patches. Input may be an mbox of
+// format-patch emails, a single email, or a bare diff (an exported PR diff),
+// which yields one patch with no message.
+func parsePatches(input string) ([]mailPatch, error) {
+	input = strings.ReplaceAll(input, "\r\n", "\n")
+	trimmed := strings.TrimLeft(input, "\n")
+	if strings.HasPrefix(trimmed, "diff ") || strings.HasPrefix(trimmed, "--- ") || strings.HasPrefix(trimmed, "Index: ") {
+		return []mailPatch{{Diff: trimmed}}, nil
+	}
+
+	var raw []string
+	if locs := mboxFromLine.FindAllStringIndex(input, -1); len(locs) > 0 && strings.TrimSpace(input[:locs[0][0]]) == "" {
+		for i, loc := range locs {
+			end := len(input)
+			if i+1 < len(locs) {
+				end = locs[i+1][0]
+			}
+			raw = append(raw, input[loc[1]:end])
+		}
+	} else {
+		raw = []string{input}
+	}
+
+	var patches []mailPatch
+	for _, r := range raw {
+		p, err := parseMailPatch(strings.TrimLeft(r, "\n"))
+		if err != nil {
+			return nil, err
+		}
+		patches = append(patches, p)
+	}
+	return patches, nil
+}
+
+// parseMailPatch decodes one email: the subject (RFC 2047 decoded, [PATCH]
+// tag stripped) and the body up to the "---" separator form the message;
+// everything from the first "diff " line is the diff.
+func parseMailPatch(raw string) (mailPatch, error) {
+	msg, err := mail.ReadMessage(strings.NewReader(raw))
+	if err != nil {
+		return mailPatch{}, fmt.Errorf("parsing patch email: %w", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+	subject = patchSubjectPrefix.ReplaceAllString(subject, "")
+
+	var body io.Reader = msg.Body
+	switch strings.ToLower(msg.Header.Get("Content-Transfer-Encoding")) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return mailPatch{}, fmt.Errorf("decoding patch body: %w", err)
+	}
+	text := string(data)
+
+	p := mailPatch{Subject: subject}
+	if i := strings.Index(text, "\ndiff "); i >= 0 {
+		p.Diff = text[i+1:]
+		text = text[:i+1]
+	} else if strings.HasPrefix(text, "diff ") {
+		p.Diff, text = text, ""
+	}
+	if i := strings.Index(text, "\n---\n"); i >= 0 {
+		text = text[:i+1]
+	} else if strings.HasPrefix(text, "---\n") {
+		text = ""
+	}
+	p.Message = strings.TrimSpace(subject + "\n\n" + strings.TrimSpace(text))
+	return p, nil
+}
+
+func runPatch(cmd *cobra.Command, args []string) error {
+	name := "stdin"
+	var r io.Reader = cmd.InOrStdin()
+	if len(args) == 1 && args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("reading patch: %w", err)
+		}
+		defer f.Close()
+		r, name = f, args[0]
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("reading patch: %w", err)
+	}
+	return checkPatches(cmd, name, string(data))
+}
+
+// checkPatches applies msg rules to each patch's message and diff rules and
+// detectors to its diff, reporting every patch with a violation.
+func checkPatches(cmd *cobra.Command, name, input string) error {
+	bc, err := resolveBlockConfig(cmd)
+	if err != nil {
+		return err
+	}
+	patches, err := parsePatches(input)
+	if err != nil {
+		return err
+	}
+
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	rules := bc.skipRulesFor("diff")
+	bad := 0
+	firstID := ""
+	for i, p := range patches {
+		where := name
+		if len(patches) > 1 {
+			where = fmt.Sprintf("patch %d/%d", i+1, len(patches))
+		}
+		if p.Subject != "" {
+			where += fmt.Sprintf(" (%q)", p.Subject)
+		}
+
+		var found []string
+		if pattern, ok := matchesPattern(p.Message, bc.Msg); ok {
+			found = append(found, fmt.Sprintf("match %q in message [%s]", bc.display(pattern), idMsgPattern))
+			if !quiet && problemOutput(cmd) {
+				line, col := locateInText(p.Message, pattern)
+				problemf(idMsgPattern, name, line, col, "match %q in message of %s", bc.display(pattern), where)
+			}
+		}
+		if hit, skipped, ok := matchDiff(p.Diff, bc.Diff, rules); ok {
+			reportSkipped(cmd, skipped)
+			found = append(found, fmt.Sprintf("match %q in diff (%s) [%s]", bc.display(hit.Pattern), hit.Path, idDiffPattern))
+			if !quiet && problemOutput(cmd) {
+				problemf(idDiffPattern, hit.Path, hit.Line, hit.Col, "match %q in diff of %s", bc.display(hit.Pattern), where)
+			}
+		} else if dh, ok := runDetectors(bc, p.Diff, rules); ok {
+			found = append(found, fmt.Sprintf("%s %q at %s:%d [%s]", dh.Detector.Summary, dh.Match, dh.Path, dh.Line, dh.Detector.ID))
+			if !quiet && problemOutput(cmd) {
+				problemf(dh.Detector.ID, dh.Path, dh.Line, dh.Col, "%s %q in diff of %s", dh.Detector.Summary, dh.Match, where)
+			}
+		}
+		if len(found) == 0 {
+			continue
+		}
+		bad++
+		if firstID == "" {
+			firstID = checkIDIn(found[0])
+		}
+		if !quiet && outputFormat(cmd) == formatText {
+			errorf("%s", where)
+			for _, f := range found {
+				hintf("%s", f)
+			}
+		}
+	}
+
+	if bad > 0 {
+		if !quiet && outputFormat(cmd) == formatText {
+			bell()
+		}
+		return violationf(firstID, "%d of %d patches in %s", bad, len(patches), name)
+	}
+	if !quiet {
+		infof("%d patches clean", len(patches))
+	}
+	return nil
+}
+
+func testPatch(cmd *cobra.Command, dir string, patterns []string) bool {
+	orig, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(orig)
+
+	patch := fmt.Sprintf(`From 0000000000000000000000000000000000000000 Mon Sep 17 00:00:00 2001
+From: Contributor <c@example.com>
+Subject: [PATCH] Add feature
+
+---
+diff --git a/feature.go b/feature.go
+--- /dev/null
++++ b/feature.go
+@@ -0,0 +1 @@
++// %s
+`, patterns[0])
+	path := filepath.Join(dir, "feature.patch")
+	os.WriteFile(path, []byte(patch), 0644)
+	return runPatch(cmd, []string{path}) != nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const twoPatchMbox = `From 1111111111111111111111111111111111111111 Mon Sep 17 00:00:00 2001
+From: Contributor <c@example.com>
+Date: Tue, 1 Oct 2024 10:00:00 +0000
+Subject: [PATCH 1/2] Add parser
+
+From now on the parser is lazy.
+---
+ parser.go | 1 +
+diff --git a/parser.go b/parser.go
+--- /dev/null
++++ b/parser.go
+@@ -0,0 +1 @@
++package parser
+-- 
+2.44.0
+
+From 2222222222222222222222222222222222222222 Mon Sep 17 00:00:00 2001
+From: Contributor <c@example.com>
+Subject: [PATCH 2/2] =?UTF-8?q?Caf=C3=A9_support?=
+Content-Transfer-Encoding: quoted-printable
+
+WIP: still rough
+---
+diff --git a/cafe.go b/cafe.go
+--- /dev/null
++++ b/cafe.go
+@@ -0,0 +1 @@
++// HACK caf=C3=A9
+`
+
+func TestParsePatches_Mbox(t *testing.T) {
+	patches, err := parsePatches(twoPatchMbox)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patches) != 2 {
+		t.Fatalf("got %d patches, want 2", len(patches))
+	}
+	if patches[0].Subject != "Add parser" || patches[0].Message != "Add parser\n\nFrom now on the parser is lazy." {
+		t.Errorf("patch 1: subject %q message %q", patches[0].Subject, patches[0].Message)
+	}
+	if !strings.HasPrefix(patches[0].Diff, "diff --git a/parser.go") {
+		t.Errorf("patch 1 diff: %q", patches[0].Diff)
+	}
+	if patches[1].Subject != "Café support" {
+		t.Errorf("patch 2 subject = %q", patches[1].Subject)
+	}
+	if !strings.Contains(patches[1].Diff, "+// HACK café") {
+		t.Errorf("patch 2 diff not decoded: %q", patches[1].Diff)
+	}
+}
+
+func TestParsePatches_BareDiff(t *testing.T) {
+	diff := "diff --git a/x b/x\n--- a/x\n+++ b/x\n@@ -1 +1 @@\n-a\n+b\n"
+	patches, err := parsePatches(diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patches) != 1 || patches[0].Diff != diff || patches[0].Message != "" {
+		t.Errorf("bare diff parsed as %+v", patches)
+	}
+}
+
+func TestCheckPatch(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[block]\ndiff = [\"hack\"]\nmsg = [\"wip\"]\n"), 0644)
+	path := filepath.Join(dir, "series.mbox")
+	os.WriteFile(path, []byte(twoPatchMbox), 0644)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"check", "patch", path, "-q"})
+	err := rootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 patches") {
+		t.Errorf("expected 1 of 2 patches flagged, got %v", err)
+	}
+
+	rootCmd = buildRootCmd()
+	rootCmd.SetIn(strings.NewReader("diff --git a/x b/x\n--- a/x\n+++ b/x\n@@ -0,0 +1 @@\n+fine\n"))
+	rootCmd.SetArgs([]string{"check", "patch", "-", "-q"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Errorf("clean diff on stdin: %v", err)
+	}
+}
@@ -1,13 +1,41 @@
 package main
 
-import "strings"
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
 
 // matchesPattern checks whether text contains any of the given patterns.
-// Comparison is case-insensitive. Returns the matched pattern and true on
-// the first hit, or ("", false) if nothing matches.
+// Comparison is case-insensitive. Patterns of the form sha256:<hex> match a
+// whole token whose digest equals hex (see hashpattern.go); norm:<text>
+// patterns match after de-obfuscation (see normalize.go). Returns the
+// matched pattern and true on the first hit, or ("", false) if nothing matches.
 func matchesPattern(text string, patterns []string) (string, bool) {
-	lower := strings.ToLower(text)
+	lower := lowerBytesafe(text)
+	var hashes map[string]bool // tokenized lazily, only if a hash pattern is present
+	var folded *string         // likewise, only if a norm: pattern is present
 	for _, p := range patterns {
+		if isNormPattern(p) {
+			if folded == nil {
+				f := deobfuscate(lower)
+				folded = &f
+			}
+			if body := normBody(p); body != "" && strings.Contains(*folded, body) {
+				return p, true
+			}
+			continue
+		}
+		if isHashPattern(p) {
+			if hashes == nil {
+				hashes = tokenHashes(lower)
+			}
+			if hashes[p] {
+				return p, true
+			}
+			continue
+		}
 		if strings.Contains(lower, p) {
 			return p, true
 		}
@@ -15,6 +43,27 @@ func matchesPattern(text string, patterns []string) (string, bool) {
 	return "", false
 }
 
+// lowerBytesafe lowercases s like strings.ToLower, but copies invalid UTF-8
+// bytes through unchanged instead of replacing them with U+FFFD. Latin-1 and
+// other legacy-encoded files keep their original bytes and offsets.
+func lowerBytesafe(s string) string {
+	if utf8.ValidString(s) {
+		return strings.ToLower(s)
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b.WriteByte(s[i])
+		} else {
+			b.WriteRune(unicode.ToLower(r))
+		}
+		i += size
+	}
+	return b.String()
+}
+
 // deduplicatePatterns removes duplicate patterns, preserving first-occurrence order.
 func deduplicatePatterns(patterns []string) []string {
 	if len(patterns) == 0 {
@@ -31,20 +80,6 @@ func deduplicatePatterns(patterns []string) []string {
 	return result
 }
 
-// stripDiffNoise keeps only added lines from a unified diff.
-// After stripDiffMeta removes headers, this filters out removed lines
-// (- prefix) and context lines (no prefix), keeping only additions
-// (+ prefix) with the leading + stripped.
-func stripDiffNoise(diff string) string {
-	var added []string
-	for _, line := range strings.Split(diff, "\n") {
-		if strings.HasPrefix(line, "+") {
-			added = append(added, line[1:])
-		}
-	}
-	return strings.Join(added, "\n")
-}
-
 // stripDiffMeta removes unified diff metadata lines (headers, index,
 // hunk markers) so only actual content is checked for policy violations.
 // This prevents filenames in diff headers from triggering false positives.
@@ -63,9 +98,9 @@ func isDiffMeta(line string) bool {
 	switch {
 	case strings.HasPrefix(line, "diff --git "):
 		return true
-	case strings.HasPrefix(line, "--- a/"), line == "--- /dev/null":
+	case strings.HasPrefix(line, "--- a/"), strings.HasPrefix(line, `--- "a/`), line == "--- /dev/null":
 		return true
-	case strings.HasPrefix(line, "+++ b/"), line == "+++ /dev/null":
+	case strings.HasPrefix(line, "+++ b/"), strings.HasPrefix(line, `+++ "b/`), line == "+++ /dev/null":
 		return true
 	case strings.HasPrefix(line, "rename from "),
 		strings.HasPrefix(line, "rename to "),
@@ -90,6 +125,195 @@ func isDiffMeta(line string) bool {
 	return false
 }
 
+// unquoteGitPath reverses git's core.quotepath quoting. Paths containing
+// non-ASCII bytes or control characters are emitted as C-style quoted
+// strings with octal escapes ("caf\303\251.txt"). Unquoted paths are
+// returned as-is, as are quoted paths that fail to parse.
+func unquoteGitPath(p string) string {
+	if len(p) < 2 || p[0] != '"' || p[len(p)-1] != '"' {
+		return p
+	}
+	if s, err := strconv.Unquote(p); err == nil {
+		return s
+	}
+	return p
+}
+
+// diffFile is one file's section of a unified diff.
+type diffFile struct {
+	Path string // post-image path (pre-image for deletions), unquoted
+	Body string // the file's diff lines, headers included
+}
+
+// splitDiffFiles splits a unified diff into per-file sections at each
+// "diff --git" header. Text before the first header is ignored.
+func splitDiffFiles(diff string) []diffFile {
+	var files []diffFile
+	var cur *diffFile
+	var buf strings.Builder
+	flush := func() {
+		if cur != nil {
+			cur.Body = buf.String()
+			files = append(files, *cur)
+		}
+		buf.Reset()
+	}
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			flush()
+			cur = &diffFile{Path: diffGitHeaderPath(line)}
+		}
+		if cur == nil {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "--- ") && line != "--- /dev/null" && cur.Path == "":
+			cur.Path = strings.TrimPrefix(unquoteGitPath(line[4:]), "a/")
+		case strings.HasPrefix(line, "+++ ") && line != "+++ /dev/null":
+			cur.Path = strings.TrimPrefix(unquoteGitPath(line[4:]), "b/")
+		}
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	flush()
+	return files
+}
+
+// diffGitHeaderPath extracts the post-image path from a "diff --git" line.
+// Returns "" when the header is ambiguous (unquoted paths containing spaces);
+// the ---/+++ lines fill it in later.
+func diffGitHeaderPath(line string) string {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	if strings.HasSuffix(rest, `"`) {
+		if i := strings.LastIndex(rest[:len(rest)-1], ` "`); i >= 0 {
+			return strings.TrimPrefix(unquoteGitPath(rest[i+1:]), "b/")
+		}
+		return ""
+	}
+	if i := strings.Index(rest, " b/"); i >= 0 && strings.Count(rest, " b/") == 1 {
+		return rest[i+3:]
+	}
+	return ""
+}
+
+// diffHit locates a pattern match inside a unified diff.
+type diffHit struct {
+	Pattern string
+	Path    string // unquoted post-image path
+	Line    int    // 1-based line in the post-image; 0 if unknown
+	Col     int    // 1-based byte column; 0 if unknown
+}
+
+// matchDiff checks the lines rules.Scan selects (added lines by default) of
+// each file in a unified diff against patterns. Files that rules says to
+// skip are collected rather than scanned. Returns the first hit with the
+// file and post-image line it was found on.
+func matchDiff(diff string, patterns []string, rules skipRules) (hit diffHit, skipped []skippedFile, found bool) {
+	defer traceSpan("match", "diff patterns", "patterns", len(patterns))()
+	for _, f := range splitDiffFiles(diff) {
+		if s, ok := rules.skip(f); ok {
+			skipped = append(skipped, s)
+			continue
+		}
+		lines := scannedLines(f.Body, rules.Scan)
+		body := joinLines(lines)
+		kept, used := rules.unsuppressed(f.Path, patterns)
+		for _, s := range used {
+			if _, ok := matchesPattern(body, []string{s.Pattern}); ok || isHashPattern(s.Pattern) {
+				skipped = append(skipped, skippedFile{Path: f.Path, Reason: "pattern suppressed by " + s.Location()})
+			}
+		}
+		if p, ok := matchesPattern(body, kept); ok {
+			line, col := locateInLines(lines, p)
+			return diffHit{Pattern: p, Path: f.Path, Line: line, Col: col}, skipped, true
+		}
+	}
+	return diffHit{}, skipped, false
+}
+
+// locateInDiff finds the first added line in a single file's diff that
+// matches pattern, returning its post-image line number (from the @@ hunk
+// headers) and column. Returns (0, 0) if no added line matches on its own —
+// e.g. a hashed token split across lines.
+func locateInDiff(body, pattern string) (line, col int) {
+	return locateInLines(addedLines(body), pattern)
+}
+
+// locateInLines is locateInDiff over already selected lines.
+func locateInLines(lines []numberedLine, pattern string) (line, col int) {
+	for _, l := range lines {
+		if _, ok := matchesPattern(l.Text, []string{pattern}); ok {
+			return l.Line, matchColumn(l.Text, pattern)
+		}
+	}
+	return 0, 0
+}
+
+// hunkNewStart parses the post-image start line from "@@ -a,b +c,d @@".
+func hunkNewStart(header string) int {
+	_, rest, ok := strings.Cut(header, " +")
+	if !ok {
+		return 0
+	}
+	end := strings.IndexAny(rest, ", ")
+	if end < 0 {
+		return 0
+	}
+	n, err := strconv.Atoi(rest[:end])
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// matchColumn returns the 1-based byte column of pattern in line, or 1 for
+// hashed and norm: patterns and misses.
+func matchColumn(line, pattern string) int {
+	if start, _, ok := matchSpan(line, pattern); ok {
+		return start + 1
+	}
+	return 1
+}
+
+// matchSpan returns the byte range of line whose lowercase form is the
+// first match of plain pattern. Lowercasing can change a character's length
+// (Ⱥ is 2 bytes, ⱥ is 3), so offsets found in the lowered line are mapped
+// back to line rather than used directly. ok is false for hashed and norm:
+// patterns and misses.
+func matchSpan(line, pattern string) (start, end int, ok bool) {
+	if isHashPattern(pattern) || isNormPattern(pattern) || pattern == "" {
+		return 0, 0, false
+	}
+	// orig[i] is the offset in line of the character lowered byte i came
+	// from; orig[len(lower)] is len(line).
+	var lower strings.Builder
+	orig := make([]int, 0, len(line)+1)
+	for i := 0; i < len(line); {
+		r, size := utf8.DecodeRuneInString(line[i:])
+		n := lower.Len()
+		if r == utf8.RuneError && size == 1 {
+			lower.WriteByte(line[i])
+		} else {
+			lower.WriteRune(unicode.ToLower(r))
+		}
+		for range lower.Len() - n {
+			orig = append(orig, i)
+		}
+		i += size
+	}
+	orig = append(orig, len(line))
+	idx := strings.Index(lower.String(), pattern)
+	if idx < 0 {
+		return 0, 0, false
+	}
+	// A match ending inside a lowered character extends to its end.
+	j := idx + len(pattern)
+	for j < len(orig)-1 && orig[j] == orig[j-1] {
+		j++
+	}
+	return orig[idx], orig[j], true
+}
+
 // isTrailerLine reports whether line is a valid Git trailer (Key: Value).
 // The key must have no spaces, no leading whitespace, and be followed by ": ".
 func isTrailerLine(line string) bool {
@@ -117,50 +117,6 @@ rename to new.env`
 	})
 }
 
-func TestStripDiffNoise(t *testing.T) {
-	t.Run("keeps only added lines", func(t *testing.T) {
-		// After stripDiffMeta, a diff has content lines like:
-		// +added line
-		// -removed line
-		//  context line (space prefix)
-		input := "+added line\n-removed line\n context line\n+another add"
-		got := stripDiffNoise(input)
-		if !strings.Contains(got, "added line") {
-			t.Error("should keep added lines")
-		}
-		if !strings.Contains(got, "another add") {
-			t.Error("should keep second added line")
-		}
-		if strings.Contains(got, "removed line") {
-			t.Error("should exclude removed lines")
-		}
-		if strings.Contains(got, "context line") {
-			t.Error("should exclude context lines")
-		}
-	})
-
-	t.Run("strips leading plus", func(t *testing.T) {
-		got := stripDiffNoise("+hello world")
-		if got != "hello world" {
-			t.Errorf("expected %q, got %q", "hello world", got)
-		}
-	})
-
-	t.Run("empty input", func(t *testing.T) {
-		got := stripDiffNoise("")
-		if got != "" {
-			t.Errorf("expected empty, got %q", got)
-		}
-	})
-
-	t.Run("no added lines", func(t *testing.T) {
-		got := stripDiffNoise("-removed\n context")
-		if got != "" {
-			t.Errorf("expected empty, got %q", got)
-		}
-	})
-}
-
 func TestIsTrailerLine(t *testing.T) {
 	tests := []struct {
 		name string
@@ -185,3 +141,106 @@ func TestIsTrailerLine(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchesPattern_NonUTF8(t *testing.T) {
+	// Latin-1 "café TODO" — 0xE9 is not valid UTF-8 on its own.
+	text := "caf\xe9 TODO"
+	if p, ok := matchesPattern(text, []string{"todo"}); !ok || p != "todo" {
+		t.Errorf("matchesPattern(latin-1) = (%q, %v), want (\"todo\", true)", p, ok)
+	}
+	if got := lowerBytesafe(text); got != "caf\xe9 todo" {
+		t.Errorf("lowerBytesafe = %q, want invalid byte preserved", got)
+	}
+	if got := lowerBytesafe("ÉCOLE"); got != "école" {
+		t.Errorf("lowerBytesafe(valid) = %q, want %q", got, "école")
+	}
+}
+
+func TestUnquoteGitPath(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain.txt", "plain.txt"},
+		{`"caf\303\251.txt"`, "café.txt"},
+		{`"tab\there"`, "tab\there"},
+		{`"quote\"d"`, `quote"d`},
+		{`"unterminated`, `"unterminated`},
+	}
+	for _, tc := range tests {
+		if got := unquoteGitPath(tc.in); got != tc.want {
+			t.Errorf("unquoteGitPath(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestSplitDiffFiles(t *testing.T) {
+	diff := `diff --git "a/caf\303\251.txt" "b/caf\303\251.txt"
+new file mode 100644
+index 0000000..1111111
+--- /dev/null
++++ "b/caf\303\251.txt"
+@@ -0,0 +1 @@
++hello
+diff --git a/gone.txt b/gone.txt
+deleted file mode 100644
+--- a/gone.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-bye
+diff --git a/with space.txt b/with space.txt
+--- a/with space.txt
++++ b/with space.txt
+@@ -1 +1 @@
+-x
++y
+`
+	files := splitDiffFiles(diff)
+	want := []string{"café.txt", "gone.txt", "with space.txt"}
+	if len(files) != len(want) {
+		t.Fatalf("got %d files, want %d", len(files), len(want))
+	}
+	for i, f := range files {
+		if f.Path != want[i] {
+			t.Errorf("file %d path = %q, want %q", i, f.Path, want[i])
+		}
+	}
+}
+
+func TestMatchDiff_QuotedHeaderNotContent(t *testing.T) {
+	// A quoted +++ header must be treated as metadata, not an added line.
+	diff := `diff --git "a/todo-caf\303\251.txt" "b/todo-caf\303\251.txt"
+--- /dev/null
++++ "b/todo-caf\303\251.txt"
+@@ -0,0 +1 @@
++clean content
+`
+	if hit, _, ok := matchDiff(diff, []string{"todo"}, skipRules{}); ok {
+		t.Errorf("filename in quoted header matched pattern %q", hit.Pattern)
+	}
+
+	diff = strings.Replace(diff, "+clean content", "+a TODO here", 1)
+	hit, _, ok := matchDiff(diff, []string{"todo"}, skipRules{})
+	if !ok || hit.Pattern != "todo" || hit.Path != "todo-café.txt" {
+		t.Errorf("matchDiff = (%+v, %v), want todo in todo-café.txt", hit, ok)
+	}
+}
+
+func TestLocateInDiff(t *testing.T) {
+	body := `diff --git a/x.go b/x.go
+--- a/x.go
++++ b/x.go
+@@ -10,4 +10,5 @@ func f() {
+ 	a := 1
+-	b := 2
++	b := 3
++	// TODO: later
+ 	c := 4
+`
+	line, col := locateInDiff(body, "todo")
+	if line != 12 || col != 5 {
+		t.Errorf("locateInDiff = (%d, %d), want (12, 5)", line, col)
+	}
+	if line, col := locateInDiff(body, "absent"); line != 0 || col != 0 {
+		t.Errorf("miss = (%d, %d), want (0, 0)", line, col)
+	}
+}
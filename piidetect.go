@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// piiKind is one personal-data format the "pii" detector can flag. Valid,
+// when set, rejects look-alikes the pattern alone can't: a checksum, or a
+// number range the issuer never assigns.
+type piiKind struct {
+	Name    string
+	Pattern *regexp.Regexp
+	Valid   func(match string) bool
+}
+
+const piiDetector = "pii"
+
+var piiKinds = []piiKind{
+	{"email", regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}\b`), validEmail},
+	{"credit-card", regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), validCardNumber},
+	{"iban", regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]{4}){2,7}(?: ?[A-Z0-9]{1,4})?\b`), validIBAN},
+	{"phone", regexp.MustCompile(`\+\d{1,3}(?:[ .-]?\(?\d{1,4}\)?){2,5}\b`), validIntlPhone},
+	{"us-ssn", regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`), validSSN},
+	{"us-phone", regexp.MustCompile(`(?:\(\d{3}\) ?|\b\d{3}[-.])\d{3}[-.]\d{4}\b`), validNANP},
+	{"uk-nino", regexp.MustCompile(`\b[A-CEGHJ-PR-TW-Z]{2} ?\d{2} ?\d{2} ?\d{2} ?[A-D]\b`), validNINO},
+}
+
+// piiProfiles are country or region shorthands for sets of kinds.
+var piiProfiles = map[string][]string{
+	"us": {"us-ssn", "us-phone"},
+	"uk": {"uk-nino"},
+	"eu": {"iban"},
+}
+
+// defaultPIIKinds apply when the detector is enabled without choosing any.
+var defaultPIIKinds = []string{"email", "credit-card"}
+
+func findPIIKind(name string) *piiKind {
+	for i := range piiKinds {
+		if piiKinds[i].Name == name {
+			return &piiKinds[i]
+		}
+	}
+	return nil
+}
+
+func piiKindNames() []string {
+	names := make([]string, 0, len(piiKinds)+len(piiProfiles))
+	for _, k := range piiKinds {
+		names = append(names, k.Name)
+	}
+	for p := range piiProfiles {
+		names = append(names, p)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// expandPIIKinds resolves profiles to kinds, keeping the first mention.
+func expandPIIKinds(kinds []string) []string {
+	var out []string
+	for _, k := range kinds {
+		k = strings.ToLower(k)
+		names, ok := piiProfiles[k]
+		if !ok {
+			names = []string{k}
+		}
+		for _, n := range names {
+			if !slices.Contains(out, n) {
+				out = append(out, n)
+			}
+		}
+	}
+	return out
+}
+
+// validatePIIKinds rejects kinds and profiles this snag doesn't know.
+func validatePIIKinds(kinds []string) error {
+	for _, k := range expandPIIKinds(kinds) {
+		if findPIIKind(k) == nil {
+			return fmt.Errorf("unknown kind %q (known: %s)", k, strings.Join(piiKindNames(), ", "))
+		}
+	}
+	return nil
+}
+
+// checkPII is the "pii" detector. Like the token detector it reports the
+// match redacted, so the violation doesn't copy the data into logs.
+func checkPII(bc *BlockConfig, _, line string) (string, int, bool) {
+	kinds := bc.PIIKinds
+	if len(kinds) == 0 {
+		kinds = defaultPIIKinds
+	}
+	for _, name := range expandPIIKinds(kinds) {
+		k := findPIIKind(name)
+		if k == nil {
+			continue
+		}
+		for _, loc := range k.Pattern.FindAllStringIndex(line, -1) {
+			if m := line[loc[0]:loc[1]]; k.Valid == nil || k.Valid(m) {
+				return fmt.Sprintf("%s (%s)", redact(m), k.Name), loc[0] + 1, true
+			}
+		}
+	}
+	return "", 0, false
+}
+
+// placeholderEmailDomains are reserved for documentation and tests
+// (RFC 2606) or never deliver mail to a person.
+var placeholderEmailDomains = []string{"example.com", "example.org", "example.net", "example", "test", "invalid", "localhost", "users.noreply.github.com"}
+
+func validEmail(m string) bool {
+	_, domain, _ := strings.Cut(strings.ToLower(m), "@")
+	for _, d := range placeholderEmailDomains {
+		if domain == d || strings.HasSuffix(domain, "."+d) {
+			return false
+		}
+	}
+	return true
+}
+
+func digitsOf(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// testCardNumbers are the processors' published test numbers; fixtures are
+// full of them and none belongs to anyone.
+var testCardNumbers = []string{"4111111111111111", "4242424242424242", "4012888888881881", "5555555555554444", "5105105105105100", "378282246310005", "371449635398431", "6011111111111117"}
+
+// validCardNumber requires a known network prefix, a plausible length and
+// a passing Luhn checksum.
+func validCardNumber(m string) bool {
+	d := digitsOf(m)
+	if slices.Contains(testCardNumbers, d) {
+		return false
+	}
+	var lengths []int
+	switch {
+	case d[0] == '4':
+		lengths = []int{13, 16, 19}
+	case d[:2] >= "51" && d[:2] <= "55", d[:4] >= "2221" && d[:4] <= "2720":
+		lengths = []int{16}
+	case d[:2] == "34" || d[:2] == "37":
+		lengths = []int{15}
+	case d[:4] == "6011" || d[:2] == "65":
+		lengths = []int{16, 19}
+	default:
+		return false
+	}
+	return slices.Contains(lengths, len(d)) && luhnValid(d)
+}
+
+// luhnValid reports whether a digit string passes the Luhn checksum.
+func luhnValid(d string) bool {
+	sum := 0
+	for i := len(d) - 1; i >= 0; i-- {
+		n := int(d[i] - '0')
+		if (len(d)-i)%2 == 0 {
+			if n *= 2; n > 9 {
+				n -= 9
+			}
+		}
+		sum += n
+	}
+	return sum%10 == 0
+}
+
+// ibanLengths is the IBAN length per country, for the countries most
+// likely to appear; others are checked by checksum alone.
+var ibanLengths = map[string]int{
+	"AT": 20, "BE": 16, "CH": 21, "CZ": 24, "DE": 22, "DK": 18, "ES": 24, "FI": 18,
+	"FR": 27, "GB": 22, "IE": 22, "IT": 27, "LU": 20, "NL": 18, "NO": 15, "PL": 28,
+	"PT": 25, "SE": 24,
+}
+
+// validIBAN checks the country's length and the ISO 7064 mod-97 checksum.
+func validIBAN(m string) bool {
+	s := strings.ReplaceAll(m, " ", "")
+	if n, ok := ibanLengths[s[:2]]; ok && len(s) != n {
+		return false
+	}
+	var digits strings.Builder
+	for _, r := range s[4:] + s[:4] {
+		if r >= 'A' && r <= 'Z' {
+			fmt.Fprintf(&digits, "%d", r-'A'+10)
+		} else {
+			digits.WriteRune(r)
+		}
+	}
+	n, ok := new(big.Int).SetString(digits.String(), 10)
+	return ok && new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+// validIntlPhone wants an E.164-sized number: 8 to 15 digits.
+func validIntlPhone(m string) bool {
+	n := len(digitsOf(m))
+	return n >= 8 && n <= 15
+}
+
+// validSSN rejects numbers the SSA never issues: area 000, 666 or 9xx,
+// group 00, serial 0000, and the famous advertising number.
+func validSSN(m string) bool {
+	area, group, serial := m[:3], m[4:6], m[7:]
+	return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000" && m != "078-05-1120"
+}
+
+// validNANP requires area and exchange codes starting 2-9 and skips the
+// 555-01xx range reserved for fiction.
+func validNANP(m string) bool {
+	d := digitsOf(m)
+	if d[0] < '2' || d[3] < '2' {
+		return false
+	}
+	return !(d[3:6] == "555" && d[6:8] == "01")
+}
+
+// validNINO drops the prefixes HMRC never allocates.
+func validNINO(m string) bool {
+	prefix := strings.ToUpper(m[:2])
+	return !slices.Contains([]string{"BG", "GB", "NK", "KN", "TN", "NT", "ZZ"}, prefix) && prefix[1] != 'O'
+}
+
+// UnmarshalTOML accepts the [detect.NAME] table and two shorthands directly
+// under [detect]: NAME = true/false, the same as enabled, and for detectors
+// that take kinds NAME = ["kind", ...], which enables the detector with
+// those kinds.
+func (r *detectRuleSection) UnmarshalTOML(v any) error {
+	switch x := v.(type) {
+	case bool:
+		*r = detectRuleSection{Enabled: &x}
+		return nil
+	case []any:
+		kinds := make([]string, len(x))
+		for i, e := range x {
+			s, ok := e.(string)
+			if !ok {
+				return fmt.Errorf("expected a list of kinds, got %v", e)
+			}
+			kinds[i] = s
+		}
+		on := true
+		*r = detectRuleSection{Enabled: &on, Kinds: kinds}
+		return nil
+	case map[string]any:
+		type plain detectRuleSection
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(x); err != nil {
+			return err
+		}
+		_, err := toml.Decode(buf.String(), (*plain)(r))
+		return err
+	}
+	return fmt.Errorf("expected a [detect.NAME] table, true/false or a list of kinds, got %T", v)
+}
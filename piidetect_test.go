@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCheckPII(t *testing.T) {
+	bc := &BlockConfig{PIIKinds: []string{"email", "credit-card", "iban", "us", "uk-nino", "phone"}}
+	cases := []struct {
+		line string
+		kind string // "" = no match
+	}{
+		{`owner = "jane.doe@acme.io"`, "email"},
+		{`owner = "jane@example.com"`, ""},
+		{`card: 4539 1488 0343 6467`, "credit-card"},
+		{`card: 4539 1488 0343 6468`, ""}, // Luhn fails
+		{`card: 4111 1111 1111 1111`, ""}, // published test number
+		{`iban = "DE89 3704 0044 0532 0130 00"`, "iban"},
+		{`iban = "DE89 3704 0044 0532 0130 01"`, ""}, // mod-97 fails
+		{`ssn: 536-22-8726`, "us-ssn"},
+		{`ssn: 666-22-8726`, ""},
+		{`call (415) 555-2671`, "us-phone"},
+		{`call 415-555-0123`, ""}, // fictional range
+		{`nino AB 12 34 56 C`, "uk-nino"},
+		{`tel: +44 20 7946 0958`, "phone"},
+		{`version 1.2.3-20240101`, ""},
+	}
+	for _, c := range cases {
+		match, _, ok := checkPII(bc, "x.txt", c.line)
+		if ok != (c.kind != "") || ok && !strings.HasSuffix(match, "("+c.kind+")") {
+			t.Errorf("%q: got %q, %v; want %q", c.line, match, ok, c.kind)
+		}
+	}
+	if match, _, _ := checkPII(bc, "x.txt", `ssn: 536-22-8726`); strings.Contains(match, "536-22-8726") {
+		t.Errorf("match %q should be redacted", match)
+	}
+	if _, _, ok := checkPII(&BlockConfig{}, "x.txt", "ssn: 536-22-8726"); ok {
+		t.Error("us-ssn is not a default kind")
+	}
+}
+
+func TestPIIConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "snag.toml")
+	os.WriteFile(path, []byte("[detect]\npii = [\"email\", \"us\"]\n\n[detect.debug]\nenabled = true\n"), 0644)
+	cfg, err := loadSnagTOML(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bc := &BlockConfig{}
+	applyTOML(bc, cfg, path, false)
+	if !bc.DetectEnabled["pii"] || !bc.DetectEnabled["debug"] || !slices.Equal(bc.PIIKinds, []string{"email", "us"}) {
+		t.Errorf("enabled = %v, kinds = %v", bc.DetectEnabled, bc.PIIKinds)
+	}
+
+	for content, want := range map[string]string{
+		"[detect]\npii = [\"passport\"]\n":          `unknown kind "passport"`,
+		"[detect.token]\nkinds = [\"email\"]\n":     "kinds apply only to detect.pii",
+		"[detect.pii]\nenabled = true\nkinds = 3\n": "kinds",
+	} {
+		os.WriteFile(path, []byte(content), 0644)
+		if _, err := loadSnagTOML(path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: err = %v, want %q", content, err, want)
+		}
+	}
+}
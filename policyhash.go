@@ -0,0 +1,156 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+// policyTrailer is the commit trailer that records the governing policy.
+const policyTrailer = "Snag-Policy"
+
+// policyHash returns a sha256 hex digest of the effective policy in bc.
+// The digest ignores list order, unset settings, and where a setting came
+// from, so two machines that resolve the same policy agree, and a new snag
+// release doesn't change the digest of a config that doesn't use its
+// features.
+func policyHash(bc *BlockConfig) (string, error) {
+	norm := *bc
+	if norm.NetworkOffBy != "" {
+		norm.NetworkOffBy = "off"
+	}
+	norm.SuppressRoot = ""
+	data, err := json.Marshal(norm)
+	if err != nil {
+		return "", err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return "", err
+	}
+	canonical, err := json.Marshal(pruneZero(fields))
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// pruneZero drops null, false, zero, and empty values and sorts string
+// lists, recursively. encoding/json already sorts map keys.
+func pruneZero(v any) any {
+	switch x := v.(type) {
+	case map[string]any:
+		out := map[string]any{}
+		for k, val := range x {
+			if val = pruneZero(val); val != nil {
+				out[k] = val
+			}
+		}
+		if len(out) == 0 {
+			return nil
+		}
+		return out
+	case []any:
+		if len(x) == 0 {
+			return nil
+		}
+		strs := make([]string, 0, len(x))
+		for _, e := range x {
+			s, ok := e.(string)
+			if !ok {
+				return x
+			}
+			strs = append(strs, s)
+		}
+		sort.Strings(strs)
+		return strs
+	case bool:
+		if !x {
+			return nil
+		}
+	case float64:
+		if x == 0 {
+			return nil
+		}
+	case string:
+		if x == "" {
+			return nil
+		}
+	}
+	return v
+}
+
+// shortPolicyHash is the abbreviated digest used in trailers and output.
+func shortPolicyHash(bc *BlockConfig) (string, error) {
+	h, err := policyHash(bc)
+	if err != nil {
+		return "", err
+	}
+	return h[:12], nil
+}
+
+func buildConfigHashCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hash",
+		Short: "Print a stable digest of the effective policy",
+		Long: `Print a digest of the effective policy: every resolved pattern and rule
+after merging the config chain, SNAG_CONFIG_DIRS and env overrides.
+
+The digest changes only when the policy does. Pattern order, comments,
+which file a setting lives in, and settings left at their defaults don't
+affect it. Compare digests to spot machines or CI runners that drift from
+the team policy. With [behavior] policy_trailer = true, commit-msg also
+records it in each commit as a Snag-Policy trailer.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			bc, err := resolveBlockConfig(cmd)
+			if err != nil {
+				return err
+			}
+			h, err := policyHash(bc)
+			if err != nil {
+				return err
+			}
+			if full, _ := cmd.Flags().GetBool("full"); !full {
+				h = h[:12]
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), h)
+			return nil
+		},
+	}
+	cmd.Flags().Bool("full", false, "print the full sha256 digest")
+	return cmd
+}
+
+// recordPolicyTrailer adds or replaces the Snag-Policy trailer in the
+// commit message file. An empty message is left alone so git still aborts
+// the commit.
+func recordPolicyTrailer(bc *BlockConfig, path string) error {
+	if !bc.PolicyTrailer {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading commit message: %w", err)
+	}
+	if lines, _ := splitMsgLines(data); len(msgContentLines(lines)) == 0 {
+		return nil
+	}
+	h, err := shortPolicyHash(bc)
+	if err != nil {
+		return err
+	}
+	out, err := gitCommand("interpret-trailers", "--in-place",
+		"--if-exists", "replace", "--trailer", policyTrailer+": "+h, path).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git interpret-trailers: %w\n%s", err, out)
+	}
+	return nil
+}
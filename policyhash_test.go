@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func hashOf(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	}
+	bc, err := resolveBlockConfigAt(nil, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := policyHash(bc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestPolicyHash(t *testing.T) {
+	base := hashOf(t, map[string]string{"snag.toml": "[block]\ndiff = [\"todo\", \"hack\"]\nmsg = [\"wip\"]\n"})
+
+	same := []map[string]string{
+		{"snag.toml": "# reordered\n[block]\nmsg = [\"WIP\"]\ndiff = [\"hack\", \"todo\"]\n"},
+		{"snag.toml": "[block]\ndiff = [\"todo\"]\nmsg = [\"wip\"]\n", "snag-local.toml": "[block]\ndiff = [\"hack\"]\n"},
+		{"snag.toml": "[block]\ndiff = [\"todo\", \"hack\"]\nmsg = [\"wip\"]\n[limits]\nmax_warnings = 0\n"},
+	}
+	for i, files := range same {
+		if h := hashOf(t, files); h != base {
+			t.Errorf("case %d: equivalent policy hashed differently", i)
+		}
+	}
+	if h := hashOf(t, map[string]string{"snag.toml": "[block]\ndiff = [\"todo\", \"hack\", \"xxx\"]\nmsg = [\"wip\"]\n"}); h == base {
+		t.Error("adding a pattern must change the hash")
+	}
+	if h := hashOf(t, map[string]string{"snag.toml": "[block]\ndiff = [\"todo\", \"hack\"]\nmsg = [\"wip\"]\nempty = true\n"}); h == base {
+		t.Error("enabling a rule must change the hash")
+	}
+}
+
+func TestRunMsg_PolicyTrailer(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\nmsg = [\"wip\"]\n[behavior]\npolicy_trailer = true\n"), 0644)
+	orig, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(orig)
+
+	bc, _ := resolveBlockConfig(nil)
+	want, _ := shortPolicyHash(bc)
+
+	msgFile := filepath.Join(dir, "COMMIT_EDITMSG")
+	os.WriteFile(msgFile, []byte("Add feature\n\n# Please enter the commit message\n"), 0644)
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"check", "msg", msgFile, "-q"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("check msg: %v", err)
+	}
+	data, _ := os.ReadFile(msgFile)
+	if !strings.Contains(string(data), policyTrailer+": "+want) {
+		t.Errorf("trailer missing:\n%s", data)
+	}
+
+	// Running again replaces rather than duplicates the trailer.
+	rootCmd = buildRootCmd()
+	rootCmd.SetArgs([]string{"check", "msg", msgFile, "-q"})
+	rootCmd.Execute()
+	data, _ = os.ReadFile(msgFile)
+	if n := strings.Count(string(data), policyTrailer+":"); n != 1 {
+		t.Errorf("got %d trailers:\n%s", n, data)
+	}
+
+	// An empty message stays empty so git still aborts the commit.
+	os.WriteFile(msgFile, []byte("# Please enter the commit message\n"), 0644)
+	rootCmd = buildRootCmd()
+	rootCmd.SetArgs([]string{"check", "msg", msgFile, "-q"})
+	rootCmd.Execute()
+	if data, _ := os.ReadFile(msgFile); strings.Contains(string(data), policyTrailer) {
+		t.Errorf("trailer added to an empty message:\n%s", data)
+	}
+}
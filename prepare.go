@@ -4,25 +4,177 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/spf13/cobra"
 )
 
+// prepareSection configures what snag check prepare adds to new commit
+// messages.
+type prepareSection struct {
+	Ticket bool              `toml:"ticket"` // prepend the ticket ID found in the branch name
+	Type   bool              `toml:"type"`   // prepend a conventional-commit type derived from the branch prefix
+	Types  map[string]string `toml:"types"`  // branch prefix → commit type, over defaultPrepareTypes; "" = none
+}
+
+func (p prepareSection) empty() bool {
+	return !p.Ticket && !p.Type && len(p.Types) == 0
+}
+
+// defaultPrepareTypes maps common branch prefixes (fix/123-crash) to
+// conventional-commit types.
+var defaultPrepareTypes = map[string]string{
+	"feat": "feat", "feature": "feat",
+	"fix": "fix", "bugfix": "fix", "hotfix": "fix",
+	"docs": "docs", "doc": "docs",
+	"chore": "chore", "refactor": "refactor", "perf": "perf",
+	"test": "test", "tests": "test",
+	"build": "build", "ci": "ci", "style": "style", "revert": "revert",
+}
+
+var (
+	// conventionalPrefix matches a subject that already has a type: "fix: ",
+	// "feat(api)!: ".
+	conventionalPrefix = regexp.MustCompile(`^[a-z]+(\([^)]*\))?!?: `)
+	// prepareTypeName is what [prepare.types] values may be.
+	prepareTypeName = regexp.MustCompile(`^[a-z]*$`)
+	// branchIssueNumber is a bare issue number leading a branch name's last
+	// segment, as in fix/123-crash.
+	branchIssueNumber = regexp.MustCompile(`^([1-9][0-9]*)(?:[-_]|$)`)
+)
+
+// prepareOn reports whether snag check prepare adds anything to messages.
+func (bc *BlockConfig) prepareOn() bool {
+	return bc.PrepareTicket || bc.PrepareType
+}
+
+// branchCommitType returns the commit type for branch's prefix (the part
+// before the first "/"), or "" when it has none or isn't mapped.
+func branchCommitType(bc *BlockConfig, branch string) string {
+	prefix, _, ok := strings.Cut(branch, "/")
+	if !ok {
+		return ""
+	}
+	prefix = strings.ToLower(prefix)
+	if t, ok := bc.PrepareTypes[prefix]; ok {
+		return t
+	}
+	return defaultPrepareTypes[prefix]
+}
+
+// branchTicket returns the ticket a branch is for: the first ID matching
+// the [tickets] pattern (ENG-123), else a leading issue number in its last
+// segment as "#123".
+func branchTicket(bc *BlockConfig, branch string) string {
+	if id := bc.ticketPattern().FindString(branch); id != "" {
+		return id
+	}
+	last := branch[strings.LastIndex(branch, "/")+1:]
+	if m := branchIssueNumber.FindStringSubmatch(last); m != nil {
+		return "#" + m[1]
+	}
+	return ""
+}
+
+// prefixSubject adds the type and ticket to subject, skipping whichever it
+// already has, so re-running the hook (or editing an amended message)
+// doesn't stack them: "crash" → "fix: #123 crash".
+func prefixSubject(subject, typ, ticket string) string {
+	head := conventionalPrefix.FindString(subject)
+	rest := subject[len(head):]
+	if ticket != "" && !containsTicket(subject, ticket) {
+		rest = strings.TrimRight(ticket+" "+rest, " ")
+	}
+	if head == "" && typ != "" {
+		head = typ + ": "
+	}
+	return head + rest
+}
+
+// containsTicket reports whether ticket appears in s as a whole token, so
+// #123 doesn't count as #12 already being there.
+func containsTicket(s, ticket string) bool {
+	isWord := func(c byte) bool {
+		return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+	}
+	for i := 0; ; {
+		j := strings.Index(s[i:], ticket)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(ticket)
+		if (start == 0 || !isWord(s[start-1])) && (end == len(s) || !isWord(s[end])) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+// prefixMessage applies [prepare] ticket and type to the message in
+// msgFile. Merges, squashes and reused messages (-c, --amend) are left
+// alone, as is any commit made off a branch.
+func prefixMessage(bc *BlockConfig, msgFile, source string) error {
+	switch source {
+	case "", "message", "template":
+	default:
+		return nil
+	}
+	branch, err := currentBranch()
+	if err != nil {
+		return nil
+	}
+	var typ, ticket string
+	if bc.PrepareType {
+		typ = branchCommitType(bc, branch)
+	}
+	if bc.PrepareTicket {
+		ticket = branchTicket(bc, branch)
+	}
+	if typ == "" && ticket == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(msgFile)
+	if err != nil {
+		return fmt.Errorf("reading commit message: %w", err)
+	}
+	lines := strings.Split(string(data), "\n")
+	i := 0
+	for i < len(lines)-1 && strings.HasPrefix(lines[i], "#") {
+		i++
+	}
+	subject := prefixSubject(lines[i], typ, ticket)
+	if subject == lines[i] {
+		return nil
+	}
+	if !strings.HasSuffix(subject, " ") && lines[i] == "" {
+		subject += " " // leave room to type after the prefix in the editor
+	}
+	lines[i] = subject
+	return os.WriteFile(msgFile, []byte(strings.Join(lines, "\n")), 0644)
+}
+
 func runPrepare(cmd *cobra.Command, args []string) error {
 	// args[0] = message file, args[1] = source (optional), args[2] = sha (optional)
 	msgFile := args[0]
-
-	// When source is "message" the user passed -m; commit-msg will check it later.
-	if len(args) > 1 && args[1] == "message" {
-		return nil
+	source := ""
+	if len(args) > 1 {
+		source = args[1]
 	}
 
 	bc, err := resolveBlockConfig(cmd)
 	if err != nil {
 		return err
 	}
-	if len(bc.Msg) == 0 {
+	if bc.prepareOn() {
+		if err := prefixMessage(bc, msgFile, source); err != nil {
+			return err
+		}
+	}
+
+	// When source is "message" the user passed -m; commit-msg will check it later.
+	if source == "message" || len(bc.Msg) == 0 {
 		return nil
 	}
 
@@ -45,14 +197,17 @@ func runPrepare(cmd *cobra.Command, args []string) error {
 	}
 
 	quiet, _ := cmd.Flags().GetBool("quiet")
-	if !quiet {
-		errorf("match %q in auto-generated commit message", pattern)
+	if !quiet && problemOutput(cmd) {
+		line, col := locateInText(string(data), pattern)
+		problemf(idMsgPattern, msgFile, line, col, "match %q in auto-generated commit message", bc.display(pattern))
+	} else if !quiet {
+		blockf(idMsgPattern, "match %q in auto-generated commit message", bc.display(pattern))
 		bell()
 		hintf("git pre-populated this message (merge, template, or amend)")
 		hintf("to commit with your own message: git commit -m \"your message here\"")
 		hintf("to edit the message first: git commit -e")
 	}
-	return fmt.Errorf("policy violation: %q found in auto-generated commit message", pattern)
+	return violationf(idMsgPattern, "%q found in auto-generated commit message", bc.display(pattern))
 }
 
 func testPrepare(cmd *cobra.Command, dir string, patterns []string) bool {
@@ -71,3 +226,16 @@ func testPrepare(cmd *cobra.Command, dir string, patterns []string) bool {
 	err := runPrepare(cmd, []string{msgFile})
 	return err != nil // error means violation detected = pass
 }
+
+// validatePrepareSection checks a file's [prepare] settings.
+func validatePrepareSection(p prepareSection) error {
+	for prefix, typ := range p.Types {
+		if prefix == "" || strings.Contains(prefix, "/") {
+			return fmt.Errorf("prepare.types: %q is not a branch prefix", prefix)
+		}
+		if !prepareTypeName.MatchString(typ) {
+			return fmt.Errorf("prepare.types.%s: %q must be a lowercase commit type like \"fix\"", prefix, typ)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPrefixSubject(t *testing.T) {
+	cases := []struct {
+		subject, typ, ticket, want string
+	}{
+		{"crash on start", "fix", "#123", "fix: #123 crash on start"},
+		{"", "fix", "#123", "fix: #123"},
+		{"", "fix", "", "fix: "},
+		{"fix: #123 crash", "fix", "#123", "fix: #123 crash"},
+		{"feat(api)!: crash", "fix", "ENG-4", "feat(api)!: ENG-4 crash"},
+		{"ENG-4 crash", "fix", "ENG-4", "fix: ENG-4 crash"},
+		{"crash", "", "ENG-4", "ENG-4 crash"},
+		{"fix: #123 crash", "fix", "#12", "fix: #12 #123 crash"},
+		{"ENG-45 crash", "", "ENG-4", "ENG-4 ENG-45 crash"},
+		{"crash (#12)", "", "#12", "crash (#12)"},
+	}
+	for _, c := range cases {
+		if got := prefixSubject(c.subject, c.typ, c.ticket); got != c.want {
+			t.Errorf("prefixSubject(%q, %q, %q) = %q, want %q", c.subject, c.typ, c.ticket, got, c.want)
+		}
+	}
+}
+
+func TestBranchTypeAndTicket(t *testing.T) {
+	bc := &BlockConfig{PrepareTypes: map[string]string{"bug": "fix", "spike": ""}}
+	cases := []struct {
+		branch, typ, ticket string
+	}{
+		{"fix/123-crash", "fix", "#123"},
+		{"Feature/ENG-42-login", "feat", "ENG-42"},
+		{"bug/crash", "fix", ""},
+		{"spike/456", "", "#456"},
+		{"wip/thing", "", ""},
+		{"main", "", ""},
+		{"fix/v2-crash", "fix", ""},
+	}
+	for _, c := range cases {
+		if got := branchCommitType(bc, c.branch); got != c.typ {
+			t.Errorf("branchCommitType(%q) = %q, want %q", c.branch, got, c.typ)
+		}
+		if got := branchTicket(bc, c.branch); got != c.ticket {
+			t.Errorf("branchTicket(%q) = %q, want %q", c.branch, got, c.ticket)
+		}
+	}
+}
+
+func TestRunPrepare_Prefix(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	if out, err := exec.Command("git", "-C", dir, "checkout", "-q", "-b", "fix/123-crash").CombinedOutput(); err != nil {
+		t.Fatalf("checkout: %v\n%s", err, out)
+	}
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[prepare]\nticket = true\ntype = true\n"), 0644)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	msgFile := filepath.Join(dir, "COMMIT_EDITMSG")
+	prepare := func(content string, args ...string) string {
+		t.Helper()
+		os.WriteFile(msgFile, []byte(content), 0644)
+		rootCmd := buildRootCmd()
+		rootCmd.SetArgs(append([]string{"check", "prepare", "-q", msgFile}, args...))
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("check prepare %v: %v", args, err)
+		}
+		data, _ := os.ReadFile(msgFile)
+		return string(data)
+	}
+
+	if got := prepare("\n# Please enter the commit message\n"); got != "fix: #123 \n# Please enter the commit message\n" {
+		t.Errorf("editor: %q", got)
+	}
+	if got := prepare("handle empty config\n", "message"); got != "fix: #123 handle empty config\n" {
+		t.Errorf("-m: %q", got)
+	}
+	if got := prepare("fix: #123 handle empty config\n", "message"); got != "fix: #123 handle empty config\n" {
+		t.Errorf("already prefixed: %q", got)
+	}
+	for _, source := range []string{"merge", "squash", "commit"} {
+		if got := prepare("Merge branch 'x'\n", source); got != "Merge branch 'x'\n" {
+			t.Errorf("%s: message changed to %q", source, got)
+		}
+	}
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[prepare]\ntype = true\n[prepare.types]\nfix = \"bugfix\"\n"), 0644)
+	if got := prepare("crash\n", "message"); !strings.HasPrefix(got, "bugfix: crash") {
+		t.Errorf("[prepare.types]: %q", got)
+	}
+}
+
+func TestPrepareConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snag.toml")
+	for content, want := range map[string]string{
+		"[prepare.types]\nfix = \"Fix\"\n":                        "lowercase commit type",
+		"[prepare.types]\n\"a/b\" = \"fix\"\n":                    "not a branch prefix",
+		"[prepare]\ntype = true\n[prepare.types]\nspike = \"\"\n": "",
+	} {
+		os.WriteFile(path, []byte(content), 0644)
+		_, err := loadSnagTOML(path)
+		if want == "" && err != nil || want != "" && (err == nil || !strings.Contains(err.Error(), want)) {
+			t.Errorf("%q: err = %v, want %q", content, err, want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Progress tuning. Short scans finish before a meter would be readable, so
+// none is shown below progressMinItems.
+const (
+	progressMinItems = 50
+	progressBarWidth = 30
+	progressRedraw   = 100 * time.Millisecond
+	progressPlainPct = 10 // non-TTY: print a line every this many percent
+)
+
+// progressMeter reports how far a long scan has got on stderr: a redrawn
+// bar with a rate on a terminal, or a line every progressPlainPct percent
+// otherwise (logs, CI, plain output). A nil meter does nothing, so callers
+// needn't check whether one is shown.
+type progressMeter struct {
+	w        io.Writer
+	label    string // "commits"
+	total    int
+	done     int
+	start    time.Time
+	tty      bool
+	drawn    bool // a bar is on screen and must be erased before other output
+	lastDraw time.Time
+	lastPct  int
+}
+
+// stderrIsTerminal reports whether stderr can redraw a bar in place.
+var stderrIsTerminal = func() bool {
+	return term.IsTerminal(int(os.Stderr.Fd()))
+}
+
+// newProgress returns a meter for total items, or nil when the scan is short
+// or -q is set.
+func newProgress(quiet bool, label string, total int) *progressMeter {
+	if quiet || total < progressMinItems {
+		return nil
+	}
+	return &progressMeter{
+		w:     os.Stderr,
+		label: label,
+		total: total,
+		start: now(),
+		tty:   stderrIsTerminal() && !plainOutput,
+	}
+}
+
+// Step records one finished item.
+func (p *progressMeter) Step() {
+	if p == nil {
+		return
+	}
+	p.done++
+	pct := p.done * 100 / p.total
+	if !p.tty {
+		if pct/progressPlainPct > p.lastPct/progressPlainPct && p.done < p.total {
+			p.lastPct = pct
+			fmt.Fprintf(p.w, "snag: %d%% (%d/%d %s, %s)\n", pct, p.done, p.total, p.label, p.rate())
+		}
+		return
+	}
+	if t := now(); p.done == p.total || t.Sub(p.lastDraw) >= progressRedraw {
+		p.lastDraw = t
+		filled := progressBarWidth * p.done / p.total
+		bar := strings.Repeat("=", filled) + strings.Repeat(" ", progressBarWidth-filled)
+		fmt.Fprintf(p.w, "\r\x1b[K[%s] %3d%% %d/%d %s, %s", bar, pct, p.done, p.total, p.label, p.rate())
+		p.drawn = true
+	}
+}
+
+// rate formats throughput so far, e.g. "120 commits/s".
+func (p *progressMeter) rate() string {
+	secs := now().Sub(p.start).Seconds()
+	if secs <= 0 {
+		return "- " + p.label + "/s"
+	}
+	return fmt.Sprintf("%.0f %s/s", float64(p.done)/secs, p.label)
+}
+
+// Clear erases the bar so other output starts on a clean line. The next
+// Step redraws it.
+func (p *progressMeter) Clear() {
+	if p == nil || !p.drawn {
+		return
+	}
+	fmt.Fprint(p.w, "\r\x1b[K")
+	p.drawn = false
+	p.lastDraw = time.Time{}
+}
+
+// Done erases the meter once the scan is over.
+func (p *progressMeter) Done() {
+	p.Clear()
+}
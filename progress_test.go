@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewProgress_SkipsShortAndQuiet(t *testing.T) {
+	if newProgress(false, "commits", progressMinItems-1) != nil {
+		t.Error("short scans should get no meter")
+	}
+	if newProgress(true, "commits", 1000) != nil {
+		t.Error("-q should suppress the meter")
+	}
+	var p *progressMeter
+	p.Step()
+	p.Clear()
+	p.Done() // a nil meter is a no-op
+}
+
+func TestProgressMeter_PlainPercentages(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	orig := now
+	now = func() time.Time { return start.Add(2 * time.Second) }
+	defer func() { now = orig }()
+
+	var buf bytes.Buffer
+	p := &progressMeter{w: &buf, label: "commits", total: 100, start: start}
+	for range 100 {
+		p.Step()
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 9 {
+		t.Fatalf("want a line per 10%% short of 100%%, got %d:\n%s", len(lines), buf.String())
+	}
+	if lines[0] != "snag: 10% (10/100 commits, 5 commits/s)" {
+		t.Errorf("first line = %q", lines[0])
+	}
+}
+
+func TestProgressMeter_BarClears(t *testing.T) {
+	var buf bytes.Buffer
+	p := &progressMeter{w: &buf, label: "commits", total: 4, start: now(), tty: true}
+	p.Step()
+	if !strings.Contains(buf.String(), "25%") || !p.drawn {
+		t.Fatalf("bar not drawn: %q", buf.String())
+	}
+	buf.Reset()
+	p.Clear()
+	if buf.String() != "\r\x1b[K" || p.drawn {
+		t.Errorf("Clear wrote %q", buf.String())
+	}
+}
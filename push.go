@@ -1,33 +1,195 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
-	"os/exec"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
-// unpushedCommits returns commit SHAs not yet on any remote.
-// With an upstream configured it uses @{upstream}..HEAD.
+// pushCommit is one unpushed commit as parsed from the batched git log stream.
+type pushCommit struct {
+	SHA        string
+	Author     string // "Name <email>"; empty when the stream carried none
+	Parents    []string
+	AuthorDate time.Time // zero when the stream carried no dates
+	CommitDate time.Time
+	Message    string
+	Diff       string
+}
+
+// unpushedRange returns the revision arguments selecting commits not yet on
+// any remote. With an upstream configured it uses @{upstream}..HEAD.
 // Without one it uses HEAD --not --remotes to exclude commits
 // already reachable from any remote tracking ref.
-func unpushedCommits() ([]string, error) {
-	var args []string
-	if exec.Command("git", "rev-parse", "--verify", "@{upstream}").Run() == nil {
-		args = []string{"rev-list", "@{upstream}..HEAD"}
-	} else {
-		args = []string{"rev-list", "HEAD", "--not", "--remotes"}
-	}
-	out, err := exec.Command("git", args...).CombinedOutput()
+func unpushedRange() []string {
+	if gitCommand("rev-parse", "--verify", "@{upstream}").Run() == nil {
+		return []string{"@{upstream}..HEAD"}
+	}
+	return []string{"HEAD", "--not", "--remotes"}
+}
+
+// pushLogFormat frames each commit as <sep><sha> <author-unix>
+// <committer-unix> <parents...>\t<author ident>\x00<message>\x00 followed
+// by its patch. sep is NUL plus a per-run random token: patches can carry
+// any byte a committed file holds, so no fixed separator is safe to split
+// on, but nothing committed can predict the token.
+func pushLogFormat(sep string) string {
+	return "--format=" + strings.ReplaceAll(sep, "\x00", "%x00") + "%H %at %ct %P%x09%an <%ae>%x00%B%x00"
+}
+
+// newRecordSeparator returns a fresh separator for pushLogFormat.
+func newRecordSeparator() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return "\x00snag-" + hex.EncodeToString(b[:]) + "\x00"
+}
+
+// pushRanges returns the revision arguments to scan for a push, one set per
+// pushed ref. With the refs git hands pre-push on stdin, each updated ref
+// is scanned as <remote>...<local> keeping only the local side and dropping
+// commits patch-identical to ones already on the remote, so other people's
+// commits replayed by a pull --rebase mishap don't block the push. A new
+// branch, or a remote tip not fetched yet, falls back to what no remote has.
+// strict, or no stdin refs (run by hand), scans unpushedRange.
+func pushRanges(refs []pushRef, strict bool) [][]string {
+	if strict || len(refs) == 0 {
+		return [][]string{unpushedRange()}
+	}
+	var out [][]string
+	for _, r := range refs {
+		switch {
+		case isZeroSHA(r.LocalSHA):
+			// Deleting a remote ref pushes no commits.
+		case !haveCommit(r.LocalSHA):
+			out = append(out, unpushedRange())
+		case !isZeroSHA(r.RemoteSHA) && haveCommit(r.RemoteSHA):
+			out = append(out, []string{"--cherry-pick", "--right-only", r.RemoteSHA + "..." + r.LocalSHA})
+		default:
+			out = append(out, []string{r.LocalSHA, "--not", "--remotes"})
+		}
+	}
+	return out
+}
+
+// haveCommit reports whether sha names a commit in the local object store.
+func haveCommit(sha string) bool {
+	return gitCommand("cat-file", "-e", "--end-of-options", sha+"^{commit}").Run() == nil
+}
+
+// isZeroSHA reports whether sha is git's all-zero "no object" id.
+func isZeroSHA(sha string) bool {
+	return strings.Trim(sha, "0") == ""
+}
+
+// scanUnpushedCommits runs a single `git log -p` over the unpushed range and
+// calls fn for each commit as it is parsed off the stream. Returning false
+// from fn stops the scan early. Returns the number of commits visited.
+func scanUnpushedCommits(fn func(pushCommit) bool) (int, error) {
+	return scanPushRange(unpushedRange(), fn)
+}
+
+// scanPushRange is scanUnpushedCommits over explicit revision arguments.
+func scanPushRange(revs []string, fn func(pushCommit) bool) (int, error) {
+	sep := newRecordSeparator()
+	args := []string{"log", "-p", "--no-color", "--no-ext-diff", "--no-textconv", pushLogFormat(sep)}
+	args = append(args, revs...)
+
+	cmd := gitCommand(args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return nil, fmt.Errorf("git rev-list: %w\n%s", err, out)
+		return 0, fmt.Errorf("git log: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("git log: %w", err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1<<30)
+	scanner.Split(splitRecords([]byte(sep)))
+
+	count := 0
+	stopped := false
+	for scanner.Scan() {
+		c, ok := parsePushRecord(scanner.Text())
+		if !ok {
+			continue
+		}
+		count++
+		if !fn(c) {
+			stopped = true
+			break
+		}
+	}
+
+	if stopped {
+		// We have what we need — don't wait on the rest of the stream.
+		cmd.Process.Kill()
+		cmd.Wait()
+		return count, nil
+	}
+	if err := scanner.Err(); err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return count, fmt.Errorf("reading git log: %w", err)
+	}
+	if err := cmd.Wait(); err != nil {
+		return count, fmt.Errorf("git log: %w\n%s", err, stderr.String())
+	}
+	return count, nil
+}
+
+// splitRecords returns a bufio.SplitFunc that yields sep-separated records.
+func splitRecords(sep []byte) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+		if i := bytes.Index(data, sep); i >= 0 {
+			return i + len(sep), data[:i], nil
+		}
+		if atEOF {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+}
+
+// parsePushRecord splits one <sha>\x00<message>\x00<patch> record.
+func parsePushRecord(rec string) (pushCommit, bool) {
+	sha, rest, ok := strings.Cut(rec, "\x00")
+	if !ok {
+		return pushCommit{}, false
 	}
-	text := strings.TrimSpace(string(out))
-	if text == "" {
-		return nil, nil
+	sha, author, _ := strings.Cut(sha, "\t")
+	head := strings.Fields(sha)
+	if len(head) == 0 {
+		return pushCommit{}, false
 	}
-	return strings.Split(text, "\n"), nil
+	c := pushCommit{SHA: head[0], Author: author}
+	if len(head) >= 3 {
+		if at, err := strconv.ParseInt(head[1], 10, 64); err == nil {
+			c.AuthorDate = time.Unix(at, 0)
+		}
+		if ct, err := strconv.ParseInt(head[2], 10, 64); err == nil {
+			c.CommitDate = time.Unix(ct, 0)
+		}
+		c.Parents = head[3:]
+	}
+	c.Message, c.Diff, _ = strings.Cut(rest, "\x00")
+	return c, true
+}
+
+func pushFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("strict-range", false, "scan every commit not on @{upstream}, ignoring the pushed refs' merge-base")
 }
 
 func runPush(cmd *cobra.Command, args []string) error {
@@ -35,53 +197,148 @@ func runPush(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	patterns := bc.PushPatterns()
-	if len(patterns) == 0 {
-		return nil
+	if err := checkPushRemote(cmd, bc, args); err != nil {
+		return err
 	}
-
-	shas, err := unpushedCommits()
-	if err != nil {
+	refs := readPushRefs(cmd)
+	if err := checkPushRefs(cmd, bc, refs); err != nil {
+		return err
+	}
+	strict, _ := cmd.Flags().GetBool("strict-range")
+	ranges := pushRanges(refs, strict)
+	if err := checkQuarantine(cmd, ranges); err != nil {
 		return err
 	}
-	if len(shas) == 0 {
+	if !bc.hasPushChecks() {
 		return nil
 	}
+	ranges, err = capPushRanges(cmd, bc, ranges)
+	if err != nil {
+		return err
+	}
+	return checkPushCommits(cmd, bc, ranges)
+}
 
+// hasPushChecks reports whether any per-commit push policy is configured.
+func (bc *BlockConfig) hasPushChecks() bool {
+	return len(bc.PushPatterns()) > 0 || bc.CommitHours != "" || bc.DateTolerance != 0 ||
+		bc.ForbidMergeCommits || bc.ForbidFixupCommits || bc.BlockEmpty || bc.BlockWhitespaceOnly ||
+		len(bc.enabledDetectors()) > 0 || bc.changelogOn()
+}
+
+// checkPushCommits runs the per-commit push policy — dates, commit shape,
+// patterns on message and diff, detectors, rollout warnings — over each
+// range of revision arguments, stopping at the first violation. Shared by
+// pre-push and the server-side hooks.
+func checkPushCommits(cmd *cobra.Command, bc *BlockConfig, ranges [][]string) error {
+	defer traceSpan("match", "push commits")()
+	patterns := bc.PushPatterns()
 	quiet, _ := cmd.Flags().GetBool("quiet")
+	rules := bc.skipRulesFor("push")
+	blocking, warn := bc.splitRollout(dropSnoozed(cmd, "push", patterns))
 
-	for _, sha := range shas {
-		short := sha[:7]
+	var violation error
+	var changed []string // paths touched by the range, for [require] changelog
+	exempt := 0
+	seen := map[string]bool{}
+	check := func(c pushCommit) bool {
+		if seen[c.SHA] {
+			return true
+		}
+		seen[c.SHA] = true
+		short := c.SHA[:7]
 
-		// Check commit message
-		msgOut, err := exec.Command("git", "log", "-1", "--format=%B", sha).CombinedOutput()
-		if err != nil {
-			return fmt.Errorf("git log %s: %w\n%s", short, err, msgOut)
+		if err := checkPushDates(cmd, bc, c); err != nil {
+			violation = err
+			return false
+		}
+		if err := checkCommitShape(cmd, bc, c); err != nil {
+			violation = err
+			return false
+		}
+		if bc.exemptAuthor(parseAuthorIdent(c.Author)) {
+			exempt++
+			return true
+		}
+		if bc.changelogOn() {
+			for _, f := range splitDiffFiles(c.Diff) {
+				changed = append(changed, f.Path)
+			}
 		}
-		if pattern, found := matchesPattern(string(msgOut), patterns); found {
+
+		// Check commit message
+		if pattern, found := matchesPattern(c.Message, blocking); found {
 			if !quiet {
-				errorf("match %q in message of %s", pattern, short)
-				bell()
+				if problemOutput(cmd) {
+					line, _ := locateInText(c.Message, pattern)
+					problemf(idMsgPattern, commitProblemFile(c.SHA), 1, 1, "match %q in message of %s (message line %d)", bc.display(pattern), short, max(line, 1))
+				} else {
+					blockf(idMsgPattern, "match %q in message of %s", bc.display(pattern), short)
+					bell()
+					explainViolation(cmd, bc, explanation{Phase: "push", Pattern: pattern, SHA: c.SHA})
+				}
 			}
-			return fmt.Errorf("policy violation: %q found in message of %s", pattern, short)
+			violation = violationf(idMsgPattern, "%q found in message of %s", bc.display(pattern), short)
+			return false
 		}
 
 		// Check commit diff
-		diffOut, err := exec.Command("git", "diff-tree", "-p", sha).CombinedOutput()
-		if err != nil {
-			return fmt.Errorf("git diff-tree %s: %w\n%s", short, err, diffOut)
-		}
-		if pattern, found := matchesPattern(stripDiffNoise(stripDiffMeta(string(diffOut))), patterns); found {
+		hit, skipped, found := matchDiff(c.Diff, blocking, rules)
+		reportSkipped(cmd, skipped)
+		if found {
 			if !quiet {
-				errorf("match %q in diff of %s", pattern, short)
-				bell()
+				if problemOutput(cmd) {
+					problemf(idDiffPattern, hit.Path, hit.Line, hit.Col, "match %q in diff of %s", bc.display(hit.Pattern), short)
+				} else {
+					blockf(idDiffPattern, "match %q in diff of %s", bc.display(hit.Pattern), short)
+					if hit.Path != "" {
+						hintf("in %s", hit.Path)
+					}
+					bell()
+					explainViolation(cmd, bc, explanation{
+						Phase: "push", Pattern: hit.Pattern, Path: hit.Path, Line: hit.Line, Diff: c.Diff, SHA: c.SHA,
+					})
+				}
 			}
-			return fmt.Errorf("policy violation: %q found in diff of %s", pattern, short)
+			violation = violationf(idDiffPattern, "%q found in diff of %s", bc.display(hit.Pattern), short)
+			return false
+		}
+		if dh, ok := runDetectors(bc, c.Diff, rules); ok {
+			violation = reportDetectHit(cmd, dh, "diff of "+short)
+			return false
 		}
+		n := rolloutWarnings(cmd, bc, warn, "commit "+short, func(p string) int {
+			return countTextLines(c.Message, p) + countDiffLines(c.Diff, p, rules)
+		})
+		if err := checkWarningBudget(cmd, bc, n, "commit "+short); err != nil {
+			violation = err
+			return false
+		}
+		return true
+	}
+	for _, revs := range ranges {
+		if _, err := scanPushRange(revs, check); err != nil {
+			return err
+		}
+		if violation != nil {
+			return violation
+		}
+	}
+	if bc.changelogOn() {
+		if err := checkChangelog(cmd, bc, changed, "push range"); err != nil {
+			return err
+		}
+	}
+	count := len(seen)
+	if count == 0 {
+		return nil
 	}
 
 	if !quiet {
-		infof("%d patterns checked against %d commits", len(patterns), len(shas))
+		infof("%d patterns checked against %d commits", len(patterns), count-exempt)
+		if exempt > 0 {
+			hintf("%d commits by [exempt] authors not pattern-checked", exempt)
+		}
 	}
 	return nil
 }
@@ -105,6 +105,43 @@ func TestRunPush_MessageMatch(t *testing.T) {
 	}
 }
 
+// A message has no file, so --format vscode points at a file the commit
+// touched rather than at the SHA.
+func TestRunPush_MessageMatchVSCode(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[block]\nmsg = [\"todo\"]\n"), 0644)
+
+	commitFile(t, dir, "a.txt", "clean content\n", "add a\n\nTODO fix this later")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"check", "push", "--format", "vscode"})
+	err := rootCmd.Execute()
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	if err == nil {
+		t.Fatal("expected violation")
+	}
+	buf := make([]byte, 1024)
+	n, _ := r.Read(buf)
+	got := string(buf[:n])
+	if !strings.HasPrefix(got, "a.txt:1:1: error: match \"todo\" in message of ") || !strings.Contains(got, "(message line 3)") {
+		t.Errorf("stdout = %q", got)
+	}
+}
+
 func TestRunPush_DiffMatchInSecondCommit(t *testing.T) {
 	dir := initGitRepo(t)
 	initialCommit(t, dir)
@@ -302,3 +339,132 @@ func TestRunPush_CatchesNewViolationWithRemote(t *testing.T) {
 		t.Errorf("error should mention matched pattern, got: %v", err)
 	}
 }
+
+func TestParsePushRecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		rec     string
+		wantOK  bool
+		wantSHA string
+		wantMsg string
+		wantDif string
+	}{
+		{"full record", "abc123\x00fix thing\n\x00\ndiff --git a/x b/x\n+hi\n", true, "abc123", "fix thing\n", "\ndiff --git a/x b/x\n+hi\n"},
+		{"no patch", "abc123\x00merge\n\x00\n", true, "abc123", "merge\n", "\n"},
+		{"leading newline", "\nabc123\x00msg\x00", true, "abc123", "msg", ""},
+		{"empty", "", false, "", "", ""},
+		{"no separator", "garbage", false, "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, ok := parsePushRecord(tt.rec)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if c.SHA != tt.wantSHA || c.Message != tt.wantMsg || c.Diff != tt.wantDif {
+				t.Errorf("got %+v", c)
+			}
+		})
+	}
+}
+
+func TestScanUnpushedCommits_SingleStream(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	commitFile(t, dir, "a.txt", "first\n", "one")
+	commitFile(t, dir, "b.txt", "second\n", "two")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	var msgs []string
+	count, err := scanUnpushedCommits(func(c pushCommit) bool {
+		msgs = append(msgs, strings.TrimSpace(c.Message))
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Fatalf("count = %d, want 3", count)
+	}
+	if msgs[0] != "two" || msgs[1] != "one" {
+		t.Errorf("messages out of order: %v", msgs)
+	}
+
+	// Stopping early reports only the commits visited.
+	count, err = scanUnpushedCommits(func(c pushCommit) bool { return false })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("early stop count = %d, want 1", count)
+	}
+}
+
+// A \x01 byte, or text resembling the record separator, in a committed
+// file must not end that commit's scanned diff early.
+func TestRunPush_ControlBytesDontHideDiff(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"secret\"]\n"), 0644)
+	commitFile(t, dir, "a.txt", "header\x01\x01 snag-0000 x\nsecret = hunter2\n", "add a")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"check", "push", "-q"})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "secret") {
+		t.Fatalf("expected the pattern after the control bytes to block, got %v", err)
+	}
+}
+
+func TestRunPush_MergeBaseRange(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"todo\"]\n"), 0644)
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	// Someone else's commit lands on the remote; ours is rebased badly, so
+	// our branch carries a replayed copy of it under a new SHA.
+	base := git("rev-parse", "HEAD")
+	git("checkout", "-q", "-b", "theirs")
+	commitFile(t, dir, "theirs.txt", "TODO from a teammate\n", "their change")
+	remote := git("rev-parse", "HEAD")
+	git("update-ref", "refs/remotes/origin/main", remote)
+	git("checkout", "-q", "-")
+	git("reset", "-q", "--hard", base)
+	commitFile(t, dir, "ours.txt", "clean\n", "our change")
+	git("cherry-pick", remote)
+	local := git("rev-parse", "HEAD")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	run := func(extra ...string) error {
+		rootCmd := buildRootCmd()
+		rootCmd.SetIn(strings.NewReader("refs/heads/main " + local + " refs/heads/main " + remote + "\n"))
+		rootCmd.SetArgs(append([]string{"check", "push", "-q"}, extra...))
+		return rootCmd.Execute()
+	}
+	if err := run(); err != nil {
+		t.Errorf("replayed teammate commit blocked the push: %v", err)
+	}
+	if err := run("--strict-range"); err == nil || !strings.Contains(err.Error(), "todo") {
+		t.Errorf("--strict-range error = %v, want todo violation", err)
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const quarantineStateFile = "quarantine.json"
+
+// quarantineEntry holds one commit back from pre-push until it is resolved.
+// Reason is shown when the push is refused; for commits quarantined by
+// snag audit it names the violation, with sensitive patterns masked.
+type quarantineEntry struct {
+	SHA     string    `json:"sha"`
+	Subject string    `json:"subject"`
+	Reason  string    `json:"reason,omitempty"`
+	Added   time.Time `json:"added"`
+}
+
+// loadQuarantine returns the entries in .git/snag/quarantine.json.
+func loadQuarantine() ([]quarantineEntry, string, error) {
+	dir, err := snagStateDir()
+	if err != nil {
+		return nil, "", err
+	}
+	path := filepath.Join(dir, quarantineStateFile)
+	var entries []quarantineEntry
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, path, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return entries, path, nil
+}
+
+// saveQuarantine replaces the quarantine file; callers hold its lock.
+func saveQuarantine(path string, entries []quarantineEntry) error {
+	if len(entries) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeStateFile(path, append(data, '\n'))
+}
+
+// addQuarantine records entries, replacing any for the same commits.
+func addQuarantine(add []quarantineEntry) error {
+	unlock, err := lockState(quarantineStateFile)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	entries, path, err := loadQuarantine()
+	if err != nil {
+		return err
+	}
+	for _, e := range add {
+		entries = slices.DeleteFunc(entries, func(old quarantineEntry) bool { return old.SHA == e.SHA })
+		entries = append(entries, e)
+	}
+	return saveQuarantine(path, entries)
+}
+
+// checkQuarantine refuses a push that contains a quarantined commit. Every
+// commit in ranges is checked, even when [push] max_commits caps the
+// pattern scan. A quarantine file that can't be read blocks the push
+// rather than letting quarantined commits through.
+func checkQuarantine(cmd *cobra.Command, ranges [][]string) error {
+	entries, path, err := loadQuarantine()
+	if err != nil {
+		if path == "" {
+			return nil // no .git/snag, so nothing is quarantined
+		}
+		return fmt.Errorf("reading quarantine: %w (fix or delete the file, or run snag quarantine resolve --all)", err)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	held := make(map[string]quarantineEntry, len(entries))
+	for _, e := range entries {
+		held[e.SHA] = e
+	}
+	for _, revs := range ranges {
+		out, err := gitCommand(append([]string{"rev-list"}, revs...)...).Output()
+		if err != nil {
+			continue // the scan itself reports a bad range
+		}
+		for _, sha := range strings.Fields(string(out)) {
+			e, ok := held[sha]
+			if !ok {
+				continue
+			}
+			hint := "drop or rewrite it, or clear it once reviewed: snag quarantine resolve " + sha[:7]
+			if e.Reason != "" {
+				hint = "reason: " + e.Reason + "\n  " + hint
+			}
+			return shapeViolation(cmd, idQuarantined, fmt.Sprintf("quarantined commit %s", sha[:7]), hint)
+		}
+	}
+	return nil
+}
+
+// resolveCommit expands rev to a full commit SHA and its subject.
+func resolveCommit(rev string) (sha, subject string, err error) {
+	out, err := gitCommand("log", "-1", "--format=%H%x00%s", rev+"^{commit}", "--").Output()
+	if err != nil {
+		return "", "", fmt.Errorf("%q is not a commit in this repository", rev)
+	}
+	sha, subject, _ = strings.Cut(strings.TrimSpace(string(out)), "\x00")
+	return sha, subject, nil
+}
+
+func buildQuarantineCmd() *cobra.Command {
+	var reason string
+	cmd := &cobra.Command{
+		Use:   "quarantine [SHA...]",
+		Short: "Hold suspect commits back from being pushed",
+		Long: `Record commits that must not be pushed until someone has looked at them.
+
+Quarantined commits live in .git/snag/quarantine.json, shared by every
+worktree of the repository. pre-push refuses any push that contains one,
+even when [push] max_commits limits the pattern scan. Rewriting the commit
+(rebase, amend) gives it a new SHA that is not quarantined; otherwise clear
+it with snag quarantine resolve once reviewed.
+
+snag audit --quarantine quarantines every commit it reports. With no
+arguments, snag quarantine lists the current entries.`,
+		Example: `  snag quarantine 3f9a1c0 --reason "customer name in fixture"
+  snag audit origin/main..HEAD --quarantine
+  snag quarantine resolve 3f9a1c0`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return runQuarantineList(cmd, nil)
+			}
+			var add []quarantineEntry
+			for _, rev := range args {
+				sha, subject, err := resolveCommit(rev)
+				if err != nil {
+					return err
+				}
+				add = append(add, quarantineEntry{SHA: sha, Subject: subject, Reason: reason, Added: now()})
+			}
+			if err := addQuarantine(add); err != nil {
+				return err
+			}
+			if quiet, _ := cmd.Flags().GetBool("quiet"); !quiet {
+				infof("quarantined %d commit(s); pre-push will refuse them until resolved", len(add))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&reason, "reason", "", "why the commits are held, shown when a push is refused")
+
+	list := &cobra.Command{
+		Use:          "list",
+		Short:        "List quarantined commits",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runQuarantineList,
+	}
+	var all bool
+	resolve := &cobra.Command{
+		Use:   "resolve [SHA...]",
+		Short: "Release quarantined commits so they can be pushed",
+		Long: `Release quarantined commits. SHAs may be abbreviated, and may name
+commits that no longer exist (dropped by a rebase). --all clears every
+entry.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all == (len(args) > 0) {
+				return fmt.Errorf("name the commits to resolve, or pass --all")
+			}
+			unlock, err := lockState(quarantineStateFile)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+			entries, path, err := loadQuarantine()
+			if err != nil && (!all || path == "") {
+				return err
+			}
+			n := len(entries)
+			if all {
+				entries = nil
+			}
+			for _, rev := range args {
+				sha := strings.ToLower(rev)
+				if full, _, err := resolveCommit(rev); err == nil {
+					sha = full
+				} else if len(sha) < 4 {
+					return fmt.Errorf("%q is too short to name a commit", rev)
+				}
+				before := len(entries)
+				entries = slices.DeleteFunc(entries, func(e quarantineEntry) bool { return strings.HasPrefix(e.SHA, sha) })
+				if len(entries) == before {
+					return fmt.Errorf("%s is not quarantined", rev)
+				}
+			}
+			if err := saveQuarantine(path, entries); err != nil {
+				return err
+			}
+			if quiet, _ := cmd.Flags().GetBool("quiet"); !quiet {
+				infof("released %d commit(s)", n-len(entries))
+			}
+			return nil
+		},
+	}
+	resolve.Flags().BoolVar(&all, "all", false, "release every quarantined commit")
+	cmd.AddCommand(list, resolve)
+	return cmd
+}
+
+func runQuarantineList(cmd *cobra.Command, args []string) error {
+	entries, _, err := loadQuarantine()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		infof("no quarantined commits")
+		return nil
+	}
+	tw := tabwriter.NewWriter(cmd.OutOrStdout(), 2, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "COMMIT\tSINCE\tSUBJECT\tREASON")
+	for _, e := range entries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", e.SHA[:7], e.Added.Format("2006-01-02"), e.Subject, e.Reason)
+	}
+	return tw.Flush()
+}
+
+// quarantineReports quarantines the commits an audit reported, with the
+// first violation of each as the reason.
+func quarantineReports(bc *BlockConfig, reports []commitReport) error {
+	var add []quarantineEntry
+	for _, r := range reports {
+		if len(r.Matches) == 0 {
+			continue
+		}
+		m := r.Matches[0]
+		reason := fmt.Sprintf("audit: %q in %s", bc.display(m.Pattern), m.Kind)
+		if m.Path != "" {
+			reason += " of " + m.Path
+		}
+		add = append(add, quarantineEntry{SHA: r.SHA, Subject: r.Subject, Reason: reason, Added: now()})
+	}
+	if len(add) == 0 {
+		return nil
+	}
+	return addQuarantine(add)
+}
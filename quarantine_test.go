@@ -0,0 +1,92 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runQuarantineSnag(args ...string) error {
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs(args)
+	return rootCmd.Execute()
+}
+
+func TestQuarantine_BlocksPushUntilResolved(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	commitFile(t, dir, "a.txt", "fixture for acme\n", "add fixture")
+	commitFile(t, dir, "b.txt", "clean\n", "add b")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	if err := runQuarantineSnag("quarantine", "HEAD~1", "--reason", "client name", "--quiet"); err != nil {
+		t.Fatal(err)
+	}
+	err := runQuarantineSnag("check", "push", "--quiet")
+	if err == nil || !strings.Contains(err.Error(), "quarantined commit") {
+		t.Fatalf("push with a quarantined commit: got %v", err)
+	}
+
+	entries, _, err := loadQuarantine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Subject != "add fixture" || entries[0].Reason != "client name" {
+		t.Fatalf("entries = %+v", entries)
+	}
+
+	if err := runQuarantineSnag("quarantine", "resolve", entries[0].SHA[:8], "--quiet"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runQuarantineSnag("check", "push", "--quiet"); err != nil {
+		t.Errorf("push after resolve: %v", err)
+	}
+	if err := runQuarantineSnag("quarantine", "resolve", entries[0].SHA[:8], "--quiet"); err == nil {
+		t.Error("resolving a commit that isn't quarantined should fail")
+	}
+}
+
+func TestQuarantine_FromAudit(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	commitFile(t, dir, "snag.toml", "[block]\ndiff = [\"hack\"]\n", "add policy")
+	commitFile(t, dir, "a.txt", "a hack\n", "quick fix")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	if err := runQuarantineSnag("audit", "HEAD~1..HEAD", "--quarantine", "--quiet"); err == nil {
+		t.Fatal("audit should report the violation")
+	}
+	entries, _, err := loadQuarantine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Subject != "quick fix" || !strings.Contains(entries[0].Reason, `"hack" in diff of a.txt`) {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestCheckQuarantine_CorruptFileBlocks(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	stateDir, err := snagStateDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(stateDir, quarantineStateFile), []byte("{not json"), 0644)
+
+	if err := runQuarantineSnag("check", "push", "--quiet"); err == nil || !strings.Contains(err.Error(), "reading quarantine") {
+		t.Errorf("a corrupt quarantine file should block the push, got %v", err)
+	}
+}
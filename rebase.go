@@ -3,7 +3,6 @@ package main
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path"
 	"strings"
 
@@ -14,7 +13,7 @@ var defaultProtectedBranches = []string{"main", "master"}
 
 // currentBranch returns the short name of HEAD via git symbolic-ref.
 func currentBranch() (string, error) {
-	out, err := exec.Command("git", "symbolic-ref", "--short", "HEAD").CombinedOutput()
+	out, err := gitCommand("symbolic-ref", "--short", "HEAD").CombinedOutput()
 	if err != nil {
 		return "", fmt.Errorf("git symbolic-ref: %w\n%s", err, out)
 	}
@@ -63,11 +62,11 @@ func runRebase(cmd *cobra.Command, args []string) error {
 
 	quiet, _ := cmd.Flags().GetBool("quiet")
 	if !quiet {
-		warnf("rebase of protected branch %q blocked", branch)
+		warnf("rebase of protected branch %q blocked [%s]", branch, idProtectedRebase)
 		hintf("protected branches: %s", strings.Join(patterns, ", "))
 		hintf("to override: SNAG_ALLOW_REBASE=1 git rebase ...")
 	}
-	return fmt.Errorf("rebase blocked: %q is a protected branch", branch)
+	return fmt.Errorf("rebase blocked: %q is a protected branch [%s]", branch, idProtectedRebase)
 }
 
 func testRebase(cmd *cobra.Command, dir string, _ []string) bool {
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// redactRule is one literal → replacement pair from the [redact] table.
+type redactRule struct {
+	From string
+	To   string
+}
+
+// redactRules returns the configured rules, longest literal first so a
+// specific term ("api.internal.corp.com") wins over a shorter one it
+// contains ("internal.corp.com").
+func (bc *BlockConfig) redactRules() []redactRule {
+	rules := make([]redactRule, 0, len(bc.Redact))
+	for from, to := range bc.Redact {
+		if from != "" {
+			rules = append(rules, redactRule{From: from, To: to})
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if len(rules[i].From) != len(rules[j].From) {
+			return len(rules[i].From) > len(rules[j].From)
+		}
+		return rules[i].From < rules[j].From
+	})
+	return rules
+}
+
+// applyRedactions replaces every case-insensitive occurrence of each rule's
+// literal in text. Returns the rewritten text and the number of replacements.
+func applyRedactions(text string, rules []redactRule) (string, int) {
+	total := 0
+	for _, r := range rules {
+		var n int
+		text, n = replaceFold(text, r.From, r.To)
+		total += n
+	}
+	return text, total
+}
+
+// replaceFold is a case-insensitive strings.ReplaceAll. When lowercasing
+// changes byte lengths (rare non-ASCII cases) offsets can't be mapped back,
+// so it falls back to an exact-case replace.
+func replaceFold(s, old, new string) (string, int) {
+	lower := lowerBytesafe(s)
+	needle := lowerBytesafe(old)
+	if len(lower) != len(s) {
+		return strings.ReplaceAll(s, old, new), strings.Count(s, old)
+	}
+	var b strings.Builder
+	n, last := 0, 0
+	for {
+		i := strings.Index(lower[last:], needle)
+		if i < 0 {
+			break
+		}
+		b.WriteString(s[last : last+i])
+		b.WriteString(new)
+		last += i + len(needle)
+		n++
+	}
+	if n == 0 {
+		return s, 0
+	}
+	b.WriteString(s[last:])
+	return b.String(), n
+}
+
+func buildRedactCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "redact",
+		Short: "Rewrite staged content using [redact] replacements",
+		Long: `Rewrite staged content using the [redact] table in snag.toml.
+
+By default every staged file is checked, each proposed rewrite is shown as a
+diff, and you're asked to confirm. The rewritten content replaces the staged
+blob; the working tree file is updated too when it matches what was staged.
+
+--stdin turns snag redact into a filter (stdin → stdout), suitable for a git
+clean filter:
+
+  git config filter.snag-redact.clean "snag redact --stdin"
+  echo "* filter=snag-redact" >> .gitattributes`,
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE:         runRedact,
+	}
+	cmd.Flags().Bool("stdin", false, "filter stdin to stdout (git clean filter mode)")
+	cmd.Flags().BoolP("yes", "y", false, "apply rewrites without prompting")
+	cmd.Flags().BoolP("dry-run", "n", false, "show rewrites without changing anything")
+	return cmd
+}
+
+func runRedact(cmd *cobra.Command, args []string) error {
+	bc, err := resolveBlockConfig(cmd)
+	if err != nil {
+		return err
+	}
+	rules := bc.redactRules()
+
+	if stdin, _ := cmd.Flags().GetBool("stdin"); stdin {
+		data, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return fmt.Errorf("reading stdin: %w", err)
+		}
+		out, _ := applyRedactions(string(data), rules)
+		_, err = io.WriteString(cmd.OutOrStdout(), out)
+		return err
+	}
+
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	if len(rules) == 0 {
+		if !quiet {
+			infof("no [redact] rules configured")
+		}
+		return nil
+	}
+	yes, _ := cmd.Flags().GetBool("yes")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	files, err := stagedFiles()
+	if err != nil {
+		return err
+	}
+
+	rewritten := 0
+	for _, f := range files {
+		staged, err := gitCommand("show", ":"+f.Path).Output()
+		if err != nil {
+			return fmt.Errorf("git show :%s: %w", f.Path, err)
+		}
+		if strings.IndexByte(string(staged), 0) >= 0 {
+			continue // binary
+		}
+		updated, n := applyRedactions(string(staged), rules)
+		if n == 0 {
+			continue
+		}
+		if !quiet || dryRun {
+			showDiffOutput(unifiedDiff(f.Path, string(staged), updated))
+		}
+		if dryRun {
+			continue
+		}
+		if !yes {
+			ok, err := confirmRedaction(f.Path, n)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+		}
+		if err := restageFile(f, string(staged), updated); err != nil {
+			return err
+		}
+		rewritten++
+		if !quiet {
+			infof("redacted %d occurrence(s) in %s", n, f.Path)
+		}
+	}
+
+	if !quiet && !dryRun {
+		infof("%d file(s) rewritten", rewritten)
+	}
+	return nil
+}
+
+// stagedFile is a path in the index along with its file mode.
+type stagedFile struct {
+	Mode string
+	Path string
+}
+
+// stagedFiles lists added/copied/modified staged paths with their index modes.
+func stagedFiles() ([]stagedFile, error) {
+	return stagedFilesFiltered("ACM")
+}
+
+// stagedFilesFiltered is stagedFiles for a git --diff-filter, e.g. "A" for
+// newly added files only. Paths are relative to the repository root
+// wherever snag runs from.
+func stagedFilesFiltered(filter string) ([]stagedFile, error) {
+	top := repoRoot(".")
+	out, err := gitCommand("-C", top, "diff", "--staged", "--name-only", "-z", "--diff-filter="+filter).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff --staged --name-only: %w", err)
+	}
+	var files []stagedFile
+	for _, p := range strings.Split(string(out), "\x00") {
+		if p == "" {
+			continue
+		}
+		ls, err := gitCommand("-C", top, "--literal-pathspecs", "ls-files", "-s", "--", p).Output()
+		if err != nil {
+			return nil, fmt.Errorf("git ls-files -s %s: %w", p, err)
+		}
+		mode, _, _ := strings.Cut(string(ls), " ")
+		files = append(files, stagedFile{Mode: mode, Path: p})
+	}
+	return files, nil
+}
+
+// restageFile writes content as a new blob and points the index at it. The
+// working tree copy is rewritten only if it still matches what was staged,
+// so unstaged edits are never clobbered. f.Path is root-relative, so git
+// runs from the root.
+func restageFile(f stagedFile, staged, content string) error {
+	top := repoRoot(".")
+	hash := gitCommand("hash-object", "-w", "--stdin")
+	hash.Stdin = strings.NewReader(content)
+	sha, err := hash.Output()
+	if err != nil {
+		return fmt.Errorf("git hash-object %s: %w", f.Path, err)
+	}
+	info := fmt.Sprintf("%s,%s,%s", f.Mode, strings.TrimSpace(string(sha)), f.Path)
+	if out, err := gitCommand("-C", top, "update-index", "--cacheinfo", info).CombinedOutput(); err != nil {
+		return fmt.Errorf("git update-index %s: %w\n%s", f.Path, err, out)
+	}
+
+	worktree := filepath.Join(top, filepath.FromSlash(f.Path))
+	if current, err := os.ReadFile(worktree); err == nil && string(current) == staged {
+		info, _ := os.Stat(worktree)
+		if err := os.WriteFile(worktree, []byte(content), info.Mode().Perm()); err != nil {
+			return fmt.Errorf("writing %s: %w", f.Path, err)
+		}
+	}
+	return nil
+}
+
+// confirmRedaction asks whether to apply the rewrite shown for path.
+// Non-interactive sessions decline; pass --yes to apply unattended.
+var confirmRedaction = func(path string, n int) (bool, error) {
+	if !isTTY() {
+		warnf("not a terminal — skipping %s (use --yes to apply)", path)
+		return false, nil
+	}
+	fmt.Fprintf(os.Stderr, "Apply %d replacement(s) to %s? [y/N]: ", n, path)
+	scanner := bufio.NewScanner(os.Stdin)
+	if !scanner.Scan() {
+		return false, fmt.Errorf("prompt cancelled")
+	}
+	answer := strings.ToLower(strings.TrimSpace(scanner.Text()))
+	return answer == "y" || answer == "yes", nil
+}
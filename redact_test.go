@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyRedactions(t *testing.T) {
+	bc := &BlockConfig{Redact: map[string]string{
+		"internal.corp.com":     "example.com",
+		"api.internal.corp.com": "api.example.com",
+	}}
+	rules := bc.redactRules()
+
+	got, n := applyRedactions("curl https://API.internal.corp.com && ping internal.corp.com", rules)
+	want := "curl https://api.example.com && ping example.com"
+	if got != want || n != 2 {
+		t.Errorf("applyRedactions = (%q, %d), want (%q, 2)", got, n, want)
+	}
+
+	if got, n := applyRedactions("nothing to see", rules); got != "nothing to see" || n != 0 {
+		t.Errorf("no-op rewrite = (%q, %d)", got, n)
+	}
+}
+
+func TestRedactCmd_Stdin(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[redact]\n\"acme\" = \"example\"\n"), 0644)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	var out bytes.Buffer
+	rootCmd := buildRootCmd()
+	rootCmd.SetIn(strings.NewReader("hello ACME\n"))
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"redact", "--stdin"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "hello example\n" {
+		t.Errorf("stdin filter output = %q", out.String())
+	}
+}
+
+func TestRedactCmd_RewritesIndexAndWorktree(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[redact]\n\"internal.corp.com\" = \"example.com\"\n"), 0644)
+
+	stageFile(t, dir, "clean.txt", "host = internal.corp.com\n")
+	stageFile(t, dir, "dirty.txt", "host = internal.corp.com\n")
+	// dirty.txt has unstaged edits that must survive.
+	os.WriteFile(filepath.Join(dir, "dirty.txt"), []byte("host = internal.corp.com\nlocal edit\n"), 0644)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"redact", "--yes", "-q"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"clean.txt", "dirty.txt"} {
+		staged, err := exec.Command("git", "show", ":"+name).Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(staged) != "host = example.com\n" {
+			t.Errorf("staged %s = %q, want redacted", name, staged)
+		}
+	}
+	if wt, _ := os.ReadFile("clean.txt"); string(wt) != "host = example.com\n" {
+		t.Errorf("clean.txt worktree = %q, want redacted", wt)
+	}
+	if wt, _ := os.ReadFile("dirty.txt"); !strings.Contains(string(wt), "local edit") {
+		t.Errorf("dirty.txt worktree edits lost: %q", wt)
+	}
+}
+
+// Staged paths are root-relative; redact must work from a subdirectory and
+// on names that look like globs.
+func TestRedactCmd_FromSubdirectory(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[redact]\n\"internal.corp.com\" = \"example.com\"\n"), 0644)
+	os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+	stageFile(t, dir, "sub/a.txt", "host = internal.corp.com\n")
+	stageFile(t, dir, "top[1].txt", "host = internal.corp.com\n")
+	stageFile(t, dir, "top1.txt", "unrelated\n")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(filepath.Join(dir, "sub"))
+	defer os.Chdir(oldDir)
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"redact", "--yes", "-q"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"sub/a.txt", "top[1].txt"} {
+		staged, _ := exec.Command("git", "-C", dir, "show", ":"+name).Output()
+		if string(staged) != "host = example.com\n" {
+			t.Errorf("staged %s = %q, want redacted", name, staged)
+		}
+	}
+	if wt, _ := os.ReadFile("a.txt"); string(wt) != "host = example.com\n" {
+		t.Errorf("sub/a.txt worktree = %q, want redacted", wt)
+	}
+}
+
+func TestRedactCmd_DeclineLeavesIndex(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[redact]\n\"acme\" = \"example\"\n"), 0644)
+	stageFile(t, dir, "a.txt", "acme\n")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	origConfirm := confirmRedaction
+	confirmRedaction = func(string, int) (bool, error) { return false, nil }
+	defer func() { confirmRedaction = origConfirm }()
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"redact", "-q"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	staged, _ := exec.Command("git", "show", ":a.txt").Output()
+	if string(staged) != "acme\n" {
+		t.Errorf("declined rewrite changed index: %q", staged)
+	}
+}
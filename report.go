@@ -0,0 +1,312 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// repoReport is everything the dashboard shows about one repository.
+type repoReport struct {
+	Name      string
+	Path      string
+	Page      string // file name of the repo's page
+	Error     string // set when the repo couldn't be audited
+	Policy    string // short policy digest
+	Hooks     bool
+	Diff      int
+	Msg       int
+	Push      int
+	Detectors int
+	Scanned   int
+	Commits   []reportCommit
+	Patterns  []patternCount
+}
+
+type reportCommit struct {
+	SHA, Subject string
+	Matches      []reportMatch
+}
+
+type reportMatch struct {
+	Pattern, Where string
+}
+
+type patternCount struct {
+	Pattern string
+	Count   int
+}
+
+var unsafeSlug = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func buildReportCmd() *cobra.Command {
+	reportCmd := &cobra.Command{
+		Use:   "report",
+		Short: "Render audit and policy data for sharing",
+	}
+	site := &cobra.Command{
+		Use:   "site [REPO...]",
+		Short: "Render a static HTML dashboard of audit results and policy",
+		Long: `Audit each repository (default: the current one) and render a static HTML
+dashboard: an index with totals across repositories and one page per
+repository with its policy summary, violating commits, and most-hit
+patterns. The output is plain HTML and CSS with no scripts or external
+assets, ready to publish on an internal static host.
+
+Patterns from sensitive = true configs are redacted as in all snag output.`,
+		Example: `  snag report site -o ./snag-report/
+  snag report site ~/src/api ~/src/web ~/src/infra -o /var/www/snag --limit 200`,
+		SilenceUsage: true,
+		RunE:         runReportSite,
+	}
+	site.Flags().StringP("output", "o", "snag-report", "directory to write the site into")
+	site.Flags().Int("limit", -1, "max commits to scan per repo (default: config or 10, 0 = unlimited)")
+	reportCmd.AddCommand(site, buildDigestCmd())
+	return reportCmd
+}
+
+func runReportSite(cmd *cobra.Command, args []string) error {
+	outDir, _ := cmd.Flags().GetString("output")
+	outDir, err := filepath.Abs(outDir)
+	if err != nil {
+		return err
+	}
+	limit, _ := cmd.Flags().GetInt("limit")
+	repos, err := collectRepoReports(cmd, args, limit)
+	if err != nil {
+		return err
+	}
+	if err := writeReportSite(outDir, repos); err != nil {
+		return err
+	}
+	if quiet, _ := cmd.Flags().GetBool("quiet"); !quiet {
+		infof("wrote %s", filepath.Join(outDir, "index.html"))
+	}
+	return nil
+}
+
+// collectRepoReports audits each repository in args (default: the current
+// one). A repo that can't be audited gets an Error rather than failing the
+// whole report.
+func collectRepoReports(cmd *cobra.Command, args []string, limit int) ([]repoReport, error) {
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+	orig, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	defer os.Chdir(orig)
+
+	var repos []repoReport
+	for i, arg := range args {
+		path, err := filepath.Abs(arg)
+		if err != nil {
+			return nil, err
+		}
+		r := repoReport{Name: filepath.Base(path), Path: path}
+		r.Page = fmt.Sprintf("repo-%02d-%s.html", i+1, unsafeSlug.ReplaceAllString(r.Name, "-"))
+		if err := os.Chdir(path); err != nil {
+			r.Error = err.Error()
+		} else if err := auditForReport(cmd, &r, limit); err != nil {
+			r.Error = err.Error()
+		}
+		repos = append(repos, r)
+	}
+	return repos, nil
+}
+
+// reportTotals aggregates repos for summaries.
+type reportTotals struct {
+	Repos, Scanned, Violating, Unprotected int
+	Patterns                               []patternCount
+}
+
+func sumReports(repos []repoReport) reportTotals {
+	t := reportTotals{Repos: len(repos)}
+	counts := map[string]int{}
+	for _, r := range repos {
+		t.Scanned += r.Scanned
+		t.Violating += len(r.Commits)
+		if !r.Hooks && r.Error == "" {
+			t.Unprotected++
+		}
+		for _, p := range r.Patterns {
+			counts[p.Pattern] += p.Count
+		}
+	}
+	t.Patterns = sortedCounts(counts)
+	return t
+}
+
+// auditForReport fills r from the repository in the working directory.
+func auditForReport(cmd *cobra.Command, r *repoReport, limit int) error {
+	bc, err := resolveBlockConfig(cmd)
+	if err != nil {
+		return err
+	}
+	if r.Policy, err = shortPolicyHash(bc); err != nil {
+		return err
+	}
+	r.Hooks = snagHooksInstalled()
+	r.Diff, r.Msg, r.Push = len(bc.Diff), len(bc.Msg), len(bc.PushPatterns())
+	for _, on := range bc.DetectEnabled {
+		if on {
+			r.Detectors++
+		}
+	}
+	if limit < 0 {
+		limit = defaultAuditLimit(bc)
+	}
+	shas, err := auditRevList(nil, limit)
+	if err != nil {
+		return err
+	}
+	r.Scanned = len(shas)
+	if len(shas) == 0 || (len(bc.Diff) == 0 && len(bc.Msg) == 0) {
+		return nil
+	}
+
+	counts := map[string]int{}
+	for _, rep := range scanCommits(shas, bc) {
+		c := reportCommit{SHA: rep.SHA[:min(len(rep.SHA), 10)], Subject: rep.Subject}
+		for _, m := range rep.Matches {
+			where := "commit message"
+			if m.Kind == "diff" {
+				where = fmt.Sprintf("%s:%d", m.Path, m.Line)
+			}
+			shown := bc.display(m.Pattern)
+			c.Matches = append(c.Matches, reportMatch{Pattern: shown, Where: where})
+			counts[shown]++
+		}
+		r.Commits = append(r.Commits, c)
+	}
+	r.Patterns = sortedCounts(counts)
+	return nil
+}
+
+func sortedCounts(counts map[string]int) []patternCount {
+	var out []patternCount
+	for p, n := range counts {
+		out = append(out, patternCount{p, n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Pattern < out[j].Pattern
+	})
+	return out
+}
+
+func writeReportSite(dir string, repos []repoReport) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating %s: %w", dir, err)
+	}
+	tmpl := template.Must(template.New("site").Parse(reportTemplates))
+
+	totals := sumReports(repos)
+	generated := time.Now().Format("2006-01-02 15:04 MST")
+
+	write := func(name, tmplName string, data any) error {
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return tmpl.ExecuteTemplate(f, tmplName, data)
+	}
+	if err := write("index.html", "index", map[string]any{
+		"Repos": repos, "Totals": totals, "Patterns": totals.Patterns,
+		"Generated": generated, "Version": Version,
+	}); err != nil {
+		return fmt.Errorf("writing index.html: %w", err)
+	}
+	for _, r := range repos {
+		if err := write(r.Page, "repo", map[string]any{
+			"Repo": r, "Generated": generated, "Version": Version,
+		}); err != nil {
+			return fmt.Errorf("writing %s: %w", r.Page, err)
+		}
+	}
+	return nil
+}
+
+var reportTemplates = strings.TrimSpace(`
+{{define "style"}}<style>
+body{font:15px/1.5 system-ui,sans-serif;max-width:1100px;margin:2em auto;padding:0 1em;color:#222}
+h1{font-size:1.6em}h2{font-size:1.2em;margin-top:2em}
+table{border-collapse:collapse;width:100%}th,td{text-align:left;padding:.35em .6em;border-bottom:1px solid #ddd;vertical-align:top}
+th{background:#f4f4f4}code{background:#f4f4f4;padding:0 .25em;border-radius:3px}
+.bad{color:#b00020;font-weight:600}.ok{color:#1b7f3b}.muted{color:#777;font-size:.9em}
+.cards{display:flex;gap:1em;flex-wrap:wrap}.card{border:1px solid #ddd;border-radius:6px;padding:.8em 1.2em;min-width:9em}
+.card b{display:block;font-size:1.6em}
+</style>{{end}}
+
+{{define "footer"}}<p class="muted">Generated {{.Generated}} by snag {{.Version}}.</p>{{end}}
+
+{{define "index"}}<!doctype html>
+<html lang="en"><head><meta charset="utf-8"><title>snag report</title>{{template "style"}}</head><body>
+<h1>snag policy report</h1>
+<div class="cards">
+<div class="card"><b>{{.Totals.Repos}}</b>repositories</div>
+<div class="card"><b>{{.Totals.Scanned}}</b>commits scanned</div>
+<div class="card"><b class="{{if .Totals.Violating}}bad{{else}}ok{{end}}">{{.Totals.Violating}}</b>violating commits</div>
+<div class="card"><b class="{{if .Totals.Unprotected}}bad{{else}}ok{{end}}">{{.Totals.Unprotected}}</b>without hooks</div>
+</div>
+<h2>Repositories</h2>
+<table><tr><th>Repository</th><th>Hooks</th><th>Policy</th><th>Patterns (diff / msg / push)</th><th>Scanned</th><th>Violating</th></tr>
+{{range .Repos}}<tr>
+<td><a href="{{.Page}}">{{.Name}}</a><br><span class="muted">{{.Path}}</span></td>
+{{if .Error}}<td colspan="5" class="bad">{{.Error}}</td>{{else}}
+<td>{{if .Hooks}}<span class="ok">installed</span>{{else}}<span class="bad">missing</span>{{end}}</td>
+<td><code>{{.Policy}}</code></td>
+<td>{{.Diff}} / {{.Msg}} / {{.Push}}</td>
+<td>{{.Scanned}}</td>
+<td>{{if .Commits}}<span class="bad">{{len .Commits}}</span>{{else}}<span class="ok">0</span>{{end}}</td>{{end}}
+</tr>{{end}}
+</table>
+{{if .Patterns}}<h2>Most-hit patterns</h2>
+<table><tr><th>Pattern</th><th>Commits</th></tr>
+{{range .Patterns}}<tr><td><code>{{.Pattern}}</code></td><td>{{.Count}}</td></tr>{{end}}
+</table>{{end}}
+{{template "footer" .}}
+</body></html>
+{{end}}
+
+{{define "repo"}}<!doctype html>
+<html lang="en"><head><meta charset="utf-8"><title>snag report: {{.Repo.Name}}</title>{{template "style"}}</head><body>
+<p><a href="index.html">&larr; all repositories</a></p>
+<h1>{{.Repo.Name}}</h1>
+<p class="muted">{{.Repo.Path}}</p>
+{{with .Repo}}{{if .Error}}<p class="bad">Could not audit: {{.Error}}</p>{{else}}
+<h2>Policy</h2>
+<table>
+<tr><th>Digest</th><td><code>{{.Policy}}</code> (<code>snag config hash</code>)</td></tr>
+<tr><th>Hooks</th><td>{{if .Hooks}}<span class="ok">installed</span>{{else}}<span class="bad">not installed</span>{{end}}</td></tr>
+<tr><th>Patterns</th><td>{{.Diff}} diff, {{.Msg}} msg, {{.Push}} push</td></tr>
+<tr><th>Detectors enabled</th><td>{{.Detectors}}</td></tr>
+</table>
+<h2>Audit</h2>
+<p>{{.Scanned}} commits scanned, {{len .Commits}} with violations.</p>
+{{if .Commits}}<table><tr><th>Commit</th><th>Subject</th><th>Violations</th></tr>
+{{range .Commits}}<tr><td><code>{{.SHA}}</code></td><td>{{.Subject}}</td><td>
+{{range .Matches}}<div><code>{{.Pattern}}</code> in {{.Where}}</div>{{end}}
+</td></tr>{{end}}
+</table>{{end}}
+{{if .Patterns}}<h2>Most-hit patterns</h2>
+<table><tr><th>Pattern</th><th>Commits</th></tr>
+{{range .Patterns}}<tr><td><code>{{.Pattern}}</code></td><td>{{.Count}}</td></tr>{{end}}
+</table>{{end}}
+{{end}}{{end}}
+{{template "footer" .}}
+</body></html>
+{{end}}
+`)
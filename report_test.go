@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReportSite(t *testing.T) {
+	dirty := initGitRepo(t)
+	os.WriteFile(filepath.Join(dirty, "snag.toml"), []byte("[block]\ndiff = [\"todo\"]\nmsg = [\"wip\"]\n"), 0644)
+	initialCommit(t, dirty)
+	commitFile(t, dirty, "a.go", "// TODO: <script>alert(1)</script>\n", "wip: first pass")
+	commitFile(t, dirty, "b.go", "package b\n", "Add b")
+
+	sensitive := initGitRepo(t)
+	os.WriteFile(filepath.Join(sensitive, "snag-local.toml"), []byte("[block]\nsensitive = true\ndiff = [\"projectx\"]\n"), 0644)
+	initialCommit(t, sensitive)
+	commitFile(t, sensitive, "c.go", "// projectx launch\n", "Add c")
+
+	out := filepath.Join(t.TempDir(), "site")
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"report", "site", dirty, sensitive, "-o", out, "-q"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("report site: %v", err)
+	}
+
+	index, err := os.ReadFile(filepath.Join(out, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"<b>2</b>repositories", "<b class=\"bad\">2</b>violating commits", filepath.Base(dirty)} {
+		if !strings.Contains(string(index), want) {
+			t.Errorf("index missing %q", want)
+		}
+	}
+
+	pages, _ := filepath.Glob(filepath.Join(out, "repo-*.html"))
+	if len(pages) != 2 {
+		t.Fatalf("got %d repo pages, want 2", len(pages))
+	}
+	var all strings.Builder
+	for _, p := range pages {
+		data, _ := os.ReadFile(p)
+		all.Write(data)
+	}
+	site := all.String() + string(index)
+	if strings.Contains(site, "projectx") {
+		t.Error("sensitive pattern leaked into the report")
+	}
+	if !strings.Contains(site, "pr****tx") || !strings.Contains(site, "a.go:1") || !strings.Contains(site, "wip: first pass") {
+		t.Errorf("repo pages missing violations:\n%s", site)
+	}
+}
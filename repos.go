@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+)
+
+const reposFile = "repos.toml"
+
+// reposScanDepth bounds how deep below a root `snag repos scan` looks for
+// repositories (~/src/org/repo is depth 2).
+const reposScanDepth = 3
+
+// snagConfigHome returns the per-user config directory:
+// $XDG_CONFIG_HOME/snag, else ~/.config/snag.
+func snagConfigHome() (string, error) {
+	if x := os.Getenv("XDG_CONFIG_HOME"); x != "" {
+		return filepath.Join(x, "snag"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "snag"), nil
+}
+
+// reposTOML is the registry of directories holding repositories.
+type reposTOML struct {
+	Roots []string `toml:"roots"`
+}
+
+func loadRepoRoots() ([]string, error) {
+	dir, err := snagConfigHome()
+	if err != nil {
+		return nil, err
+	}
+	var r reposTOML
+	if _, err := toml.DecodeFile(filepath.Join(dir, reposFile), &r); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading %s: %w", reposFile, err)
+	}
+	return r.Roots, nil
+}
+
+// addRepoRoots registers roots (made absolute) and returns the ones that
+// were new.
+func addRepoRoots(roots []string) ([]string, error) {
+	existing, err := loadRepoRoots()
+	if err != nil {
+		return nil, err
+	}
+	var added []string
+	for _, r := range roots {
+		abs, err := filepath.Abs(r)
+		if err != nil {
+			return nil, err
+		}
+		if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("%s is not a directory", r)
+		}
+		if !slices.Contains(existing, abs) {
+			existing = append(existing, abs)
+			added = append(added, abs)
+		}
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+	dir, err := snagConfigHome()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	b.WriteString("# Directories `snag repos scan` searches for repositories.\n")
+	fmt.Fprintf(&b, "roots = [%s]\n", quotedList(existing))
+	return added, os.WriteFile(filepath.Join(dir, reposFile), []byte(b.String()), 0644)
+}
+
+// findRepos returns the git repositories under root, down to reposScanDepth.
+// It stops walking, returning what it found so far, once ctx is canceled.
+func findRepos(ctx context.Context, root string) []string {
+	var repos []string
+	base := strings.Count(filepath.Clean(root), string(filepath.Separator))
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return filepath.SkipAll
+		}
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if strings.Count(path, string(filepath.Separator))-base > reposScanDepth {
+			return filepath.SkipDir
+		}
+		if _, err := os.Stat(filepath.Join(path, ".git")); err == nil {
+			repos = append(repos, path)
+			return filepath.SkipDir
+		}
+		if path != root && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return repos
+}
+
+func buildReposCmd() *cobra.Command {
+	reposCmd := &cobra.Command{
+		Use:   "repos",
+		Short: "Track the directories your repositories live in",
+	}
+	reposCmd.AddCommand(&cobra.Command{
+		Use:          "add DIR...",
+		Short:        "Register directories for snag repos scan",
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			added, err := addRepoRoots(args)
+			if err != nil {
+				return err
+			}
+			quiet, _ := cmd.Flags().GetBool("quiet")
+			if !quiet {
+				infof("%d new root(s) registered", len(added))
+			}
+			return nil
+		},
+	})
+	reposCmd.AddCommand(&cobra.Command{
+		Use:          "scan",
+		Short:        "List repositories under registered roots that have a snag config but no hooks",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runReposScan,
+	})
+	return reposCmd
+}
+
+func runReposScan(cmd *cobra.Command, args []string) error {
+	roots, err := loadRepoRoots()
+	if err != nil {
+		return err
+	}
+	if len(roots) == 0 {
+		return fmt.Errorf("no roots registered — run: snag setup --root DIR (or snag repos add DIR)")
+	}
+	orig, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	defer os.Chdir(orig)
+
+	// Ctrl-C stops the walk; the summary still covers the repos reached.
+	ctx, stop := interruptible(cmd.Context())
+	defer stop()
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	total, unprotected := 0, 0
+	for _, root := range roots {
+		for _, repo := range findRepos(ctx, root) {
+			if ctx.Err() != nil {
+				break
+			}
+			_, found, err := walkConfig(repo)
+			if err != nil || !found {
+				continue
+			}
+			total++
+			if os.Chdir(repo) != nil || snagHooksInstalled() {
+				continue
+			}
+			unprotected++
+			fmt.Fprintln(cmd.OutOrStdout(), repo)
+		}
+	}
+	if !quiet {
+		infof("%d of %d repos with a snag config have no hooks installed", unprotected, total)
+		if unprotected > 0 {
+			hintf("in each: snag install && lefthook install")
+		}
+	}
+	if ctx.Err() != nil {
+		return interruptedf("repos scan stopped early; the counts above cover the repos reached")
+	}
+	return nil
+}
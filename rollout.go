@@ -0,0 +1,189 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// rolloutSection lets a config file introduce its patterns as warn-only:
+//
+//	[rollout]
+//	mode = "warn-until"
+//	date = "2025-12-01"   # block from this date on, or
+//	days = 14             # block 14 days after snag first sees each pattern
+//
+// mode = "warn" keeps the file's patterns warn-level indefinitely; they
+// only block through [limits] max_warnings.
+type rolloutSection struct {
+	Mode string `toml:"mode"`
+	Date string `toml:"date"`
+	Days int    `toml:"days"`
+}
+
+const (
+	rolloutWarnUntil = "warn-until"
+	rolloutWarn      = "warn"
+)
+
+// rolloutRule is the warn-only window for one pattern.
+type rolloutRule struct {
+	Until   time.Time // fixed date blocking starts; zero when Days is used
+	Days    int       // days after first sighting blocking starts
+	Forever bool      // mode = "warn": never blocks on its own
+}
+
+func validateRollout(r rolloutSection) error {
+	if r.Mode == "" && r.Date == "" && r.Days == 0 {
+		return nil
+	}
+	if r.Mode == rolloutWarn {
+		if r.Date != "" || r.Days != 0 {
+			return fmt.Errorf("mode %q takes no date or days", rolloutWarn)
+		}
+		return nil
+	}
+	if r.Mode != rolloutWarnUntil {
+		return fmt.Errorf("mode must be %q or %q, got %q", rolloutWarnUntil, rolloutWarn, r.Mode)
+	}
+	if (r.Date == "") == (r.Days == 0) {
+		return errors.New("set exactly one of date or days")
+	}
+	if r.Days < 0 {
+		return errors.New("days must be positive")
+	}
+	if r.Date != "" {
+		if _, err := time.ParseInLocation("2006-01-02", r.Date, time.Local); err != nil {
+			return fmt.Errorf("date must be YYYY-MM-DD, got %q", r.Date)
+		}
+	}
+	return nil
+}
+
+// markRollout records the file's patterns as warn-only when it has a
+// [rollout] section, and as strict otherwise. A pattern declared strict
+// anywhere always blocks.
+func markRollout(bc *BlockConfig, cfg snagTOML) {
+	all := append(append([]string{}, cfg.Block.Diff...), cfg.Block.Msg...)
+	if cfg.Block.Push != nil {
+		all = append(all, *cfg.Block.Push...)
+	}
+	if cfg.Rollout.Mode != rolloutWarnUntil && cfg.Rollout.Mode != rolloutWarn {
+		if bc.strict == nil {
+			bc.strict = make(map[string]bool)
+		}
+		for _, p := range all {
+			bc.strict[strings.ToLower(p)] = true
+		}
+		return
+	}
+	rule := rolloutRule{Days: cfg.Rollout.Days, Forever: cfg.Rollout.Mode == rolloutWarn}
+	if cfg.Rollout.Date != "" {
+		rule.Until, _ = time.ParseInLocation("2006-01-02", cfg.Rollout.Date, time.Local)
+	}
+	if bc.Rollout == nil {
+		bc.Rollout = make(map[string]rolloutRule)
+	}
+	for _, p := range all {
+		if _, ok := bc.Rollout[strings.ToLower(p)]; !ok {
+			bc.Rollout[strings.ToLower(p)] = rule
+		}
+	}
+}
+
+// rolloutDeadline returns when pattern starts blocking, and false if it
+// isn't in a rollout at all. Warn-forever patterns return the zero time.
+func (bc *BlockConfig) rolloutDeadline(pattern string) (time.Time, bool) {
+	rule, ok := bc.Rollout[pattern]
+	if !ok || bc.strict[pattern] {
+		return time.Time{}, false
+	}
+	if rule.Forever {
+		return time.Time{}, true
+	}
+	if rule.Days > 0 {
+		return firstSeen(pattern).AddDate(0, 0, rule.Days), true
+	}
+	return rule.Until, true
+}
+
+// splitRollout separates patterns that block now from those still inside
+// their warn-only window.
+func (bc *BlockConfig) splitRollout(patterns []string) (block, warn []string) {
+	if len(bc.Rollout) == 0 {
+		return patterns, nil
+	}
+	for _, p := range patterns {
+		if until, ok := bc.rolloutDeadline(p); ok && (until.IsZero() || now().Before(until)) {
+			warn = append(warn, p)
+		} else {
+			block = append(block, p)
+		}
+	}
+	return block, warn
+}
+
+// warnOnly prints a warning for a match on a pattern still in rollout.
+func (bc *BlockConfig) warnOnly(pattern, where string) {
+	until, _ := bc.rolloutDeadline(pattern)
+	if until.IsZero() {
+		warnf("match %q in %s (warn-only)", bc.display(pattern), where)
+		return
+	}
+	warnf("match %q in %s (warn-only until %s)", bc.display(pattern), where, until.Format("2006-01-02"))
+}
+
+const rolloutStateFile = "rollout-first-seen.json"
+
+// firstSeen returns when snag first saw pattern in this repo, recording now
+// on first sighting. Patterns are stored hashed so sensitive terms never
+// land on disk in plaintext. Outside a repo it returns now.
+func firstSeen(pattern string) time.Time {
+	dir, err := snagStateDir()
+	if err != nil {
+		return now()
+	}
+	sum := sha256.Sum256([]byte(pattern))
+	key := hex.EncodeToString(sum[:])
+	t := now()
+	updateStateFile(dir, rolloutStateFile, func(old []byte) []byte {
+		seen := make(map[string]time.Time)
+		json.Unmarshal(old, &seen)
+		if first, ok := seen[key]; ok {
+			t = first
+			return old
+		}
+		seen[key] = t
+		data, err := json.MarshalIndent(seen, "", "  ")
+		if err != nil {
+			return old
+		}
+		return data
+	})
+	return t
+}
+
+// rolloutWarnings warns about each warn-only pattern that matches, where
+// count returns how many lines of the scanned text a pattern hits. Returns
+// the total number of matching lines, which feeds [limits] max_warnings.
+func rolloutWarnings(cmd *cobra.Command, bc *BlockConfig, warn []string, where string, count func(string) int) int {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	total := 0
+	for _, p := range warn {
+		n := count(p)
+		if n == 0 {
+			continue
+		}
+		total += n
+		if !quiet && outputFormat(cmd) == formatText {
+			bc.warnOnly(p, where)
+		}
+	}
+	return total
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateRollout(t *testing.T) {
+	tests := []struct {
+		r       rolloutSection
+		wantErr bool
+	}{
+		{rolloutSection{}, false},
+		{rolloutSection{Mode: "warn-until", Date: "2025-12-01"}, false},
+		{rolloutSection{Mode: "warn-until", Days: 14}, false},
+		{rolloutSection{Mode: "warn-until"}, true},
+		{rolloutSection{Mode: "warn-until", Date: "2025-12-01", Days: 3}, true},
+		{rolloutSection{Mode: "warn-until", Date: "Dec 1"}, true},
+		{rolloutSection{Mode: "warn"}, false},
+		{rolloutSection{Mode: "warn", Days: 3}, true},
+		{rolloutSection{Mode: "block", Days: 3}, true},
+	}
+	for _, tc := range tests {
+		if err := validateRollout(tc.r); (err != nil) != tc.wantErr {
+			t.Errorf("validateRollout(%+v) = %v, wantErr %v", tc.r, err, tc.wantErr)
+		}
+	}
+}
+
+func TestSplitRollout(t *testing.T) {
+	fixed := time.Date(2025, 6, 1, 12, 0, 0, 0, time.Local)
+	old := now
+	now = func() time.Time { return fixed }
+	defer func() { now = old }()
+
+	bc := &BlockConfig{
+		Rollout: map[string]rolloutRule{
+			"future": {Until: fixed.AddDate(0, 1, 0)},
+			"past":   {Until: fixed.AddDate(0, -1, 0)},
+			"shared": {Until: fixed.AddDate(0, 1, 0)},
+		},
+		strict: map[string]bool{"shared": true},
+	}
+	block, warn := bc.splitRollout([]string{"future", "past", "shared", "plain"})
+	if strings.Join(block, ",") != "past,shared,plain" || strings.Join(warn, ",") != "future" {
+		t.Errorf("block = %v, warn = %v", block, warn)
+	}
+}
+
+func TestRunDiff_RolloutWarnsThenBlocks(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	stageFile(t, dir, "code.go", "// legacy thing\n")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	run := func() (string, error) {
+		oldStderr := os.Stderr
+		r, w, _ := os.Pipe()
+		os.Stderr = w
+		rootCmd := buildRootCmd()
+		rootCmd.SetArgs([]string{"check", "diff"})
+		err := rootCmd.Execute()
+		w.Close()
+		os.Stderr = oldStderr
+		out, _ := io.ReadAll(r)
+		return string(out), err
+	}
+
+	future := now().AddDate(0, 0, 30).Format("2006-01-02")
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[block]\ndiff = [\"legacy\"]\n\n[rollout]\nmode = \"warn-until\"\ndate = \""+future+"\"\n"), 0644)
+	stderr, err := run()
+	if err != nil {
+		t.Fatalf("rollout pattern should only warn, got %v", err)
+	}
+	if !strings.Contains(stderr, "warn-only until "+future) {
+		t.Errorf("missing rollout warning: %q", stderr)
+	}
+
+	past := now().AddDate(0, 0, -1).Format("2006-01-02")
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[block]\ndiff = [\"legacy\"]\n\n[rollout]\nmode = \"warn-until\"\ndate = \""+past+"\"\n"), 0644)
+	if _, err := run(); err == nil {
+		t.Error("pattern past its rollout date should block")
+	}
+}
+
+func TestFirstSeen_Persists(t *testing.T) {
+	dir := initGitRepo(t)
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	first := firstSeen("hunter2")
+	old := now
+	now = func() time.Time { return first.Add(48 * time.Hour) }
+	defer func() { now = old }()
+
+	if again := firstSeen("hunter2"); !again.Equal(first) {
+		t.Errorf("firstSeen changed: %v then %v", first, again)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, ".git", "snag", rolloutStateFile))
+	if strings.Contains(string(data), "hunter2") {
+		t.Error("pattern stored in plaintext")
+	}
+}
@@ -0,0 +1,398 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Rule packs are data the built-in detectors read: token formats for the
+// "token" detector and language packs for packs_auto. Each ships built in
+// and can be updated between releases from a signed channel the user
+// configures:
+//
+//	packs.json      {"packs": [{"name": "tokens", "version": 7, "tokens": [...]}, ...]}
+//	packs.json.sig  base64 ed25519 signature of packs.json
+//
+// Installed updates live in the user cache dir. Versions only move forward,
+// so a replayed old bundle changes nothing.
+
+// There is no official channel yet: snag packs update fetches from
+// SNAG_PACKS_URL and trusts only the key in SNAG_PACKS_KEY, and refuses
+// to run without both.
+
+const (
+	packsStateFile = "packs.json"
+	packsTimeout   = 10 * time.Second
+	packsMaxSize   = 4 << 20
+)
+
+// rulePack is one versioned pack as served on the channel. Only the field
+// matching its name is used.
+type rulePack struct {
+	Name      string          `json:"name"`
+	Version   int             `json:"version"`
+	Tokens    []tokenFormat   `json:"tokens,omitempty"`
+	Languages []languagePack  `json:"languages,omitempty"`
+	Terms     []inclusiveTerm `json:"terms,omitempty"`
+}
+
+// rulePackNames are the packs this snag understands; others in a bundle
+// are for newer releases and are skipped.
+var rulePackNames = []string{"tokens", "languages", "inclusive"}
+
+// packState is what snag packs update installed, and the user's pins.
+type packState struct {
+	UpdatedAt time.Time      `json:"updated_at,omitzero"`
+	Source    string         `json:"source,omitempty"`
+	Packs     []rulePack     `json:"packs,omitempty"`
+	Pins      map[string]int `json:"pins,omitempty"` // pack name → frozen version, 0 = built-in
+}
+
+func (s *packState) pack(name string) (rulePack, bool) {
+	for _, p := range s.Packs {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return rulePack{}, false
+}
+
+func (s *packState) installedVersion(name string) int {
+	p, _ := s.pack(name)
+	return p.Version
+}
+
+func packsStatePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "snag", "packs", packsStateFile), nil
+}
+
+// loadPackState reads the installed packs. A missing file is an empty
+// state.
+func loadPackState() (*packState, string, error) {
+	path, err := packsStatePath()
+	if err != nil {
+		return nil, "", err
+	}
+	s := &packState{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, path, nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, "", fmt.Errorf("%s: %w", path, err)
+	}
+	for i := range s.Packs {
+		if err := validateRulePack(&s.Packs[i]); err != nil {
+			return nil, "", fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return s, path, nil
+}
+
+func savePackState(path string, s *packState) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return writeStateFile(path, append(data, '\n'))
+}
+
+// cachedPackState memoizes the installed packs for the detectors, which
+// consult them once per line. A state that fails to load counts as empty:
+// hooks fall back to the built-in packs rather than failing.
+var cachedPackState *packState
+
+func installedRulePack(name string) (rulePack, bool) {
+	if cachedPackState == nil {
+		s, _, err := loadPackState()
+		if err != nil {
+			s = &packState{}
+		}
+		cachedPackState = s
+	}
+	return cachedPackState.pack(name)
+}
+
+// validateRulePack checks a pack's contents and compiles its patterns.
+func validateRulePack(p *rulePack) error {
+	if p.Version < 1 {
+		return fmt.Errorf("pack %s: version must be positive", p.Name)
+	}
+	switch p.Name {
+	case "tokens":
+		for _, f := range p.Tokens {
+			if f.Name == "" || f.Pattern == "" {
+				return fmt.Errorf("pack tokens: format needs a name and a pattern")
+			}
+		}
+		return compileTokenFormats(p.Tokens)
+	case "languages":
+		for _, l := range p.Languages {
+			if l.Name == "" || len(l.Markers) == 0 {
+				return fmt.Errorf("pack languages: %q needs a name and markers", l.Name)
+			}
+		}
+	case "inclusive":
+		for _, t := range p.Terms {
+			if strings.TrimSpace(t.Term) == "" {
+				return fmt.Errorf("pack inclusive: every entry needs a term")
+			}
+		}
+	}
+	return nil
+}
+
+// packsTrustedKeys returns the keys a bundle may be signed with: the one
+// in SNAG_PACKS_KEY.
+func packsTrustedKeys() ([]ed25519.PublicKey, error) {
+	k := os.Getenv("SNAG_PACKS_KEY")
+	if k == "" {
+		return nil, errors.New("SNAG_PACKS_KEY is not set")
+	}
+	raw, err := base64.StdEncoding.DecodeString(k)
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("SNAG_PACKS_KEY is not a base64 ed25519 public key")
+	}
+	return []ed25519.PublicKey{raw}, nil
+}
+
+// verifyPackBundle checks the detached signature and parses the bundle.
+func verifyPackBundle(data, sig []byte) ([]rulePack, error) {
+	keys, err := packsTrustedKeys()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil {
+		return nil, fmt.Errorf("signature is not base64")
+	}
+	if !slices.ContainsFunc(keys, func(k ed25519.PublicKey) bool { return ed25519.Verify(k, data, raw) }) {
+		return nil, errors.New("signature does not match a trusted key; refusing the bundle")
+	}
+	var bundle struct {
+		Packs []rulePack `json:"packs"`
+	}
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("bundle: %w", err)
+	}
+	var out []rulePack
+	for _, p := range bundle.Packs {
+		if !slices.Contains(rulePackNames, p.Name) {
+			continue
+		}
+		if err := validateRulePack(&p); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+func fetchPackFile(client *http.Client, url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, packsMaxSize))
+}
+
+func buildPacksCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "packs",
+		Short: "List, update and pin the rule packs built-in detectors use",
+		Long: `Rule packs are the data behind built-in detectors: token formats for
+[detect.token] and build outputs for packs_auto. They ship with snag and
+can be updated between releases from a signed channel, so detection keeps
+up with new token formats.
+
+There is no official channel yet. snag packs update fetches the bundle from
+SNAG_PACKS_URL, verifies it against the base64 ed25519 key in
+SNAG_PACKS_KEY, and installs it in the user cache dir. A pinned pack stays
+at its version until unpinned.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runPacksList,
+	}
+	list := &cobra.Command{
+		Use:          "list",
+		Short:        "Show each pack's built-in and installed version",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runPacksList,
+	}
+	update := &cobra.Command{
+		Use:          "update",
+		Short:        "Fetch and install newer packs from the channel",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runPacksUpdate,
+	}
+	update.Flags().BoolP("dry-run", "n", false, "show what would change without installing")
+	pin := &cobra.Command{
+		Use:          "pin PACK",
+		Short:        "Freeze a pack at its current version",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE:         func(cmd *cobra.Command, args []string) error { return runPacksPin(cmd, args[0], true) },
+	}
+	unpin := &cobra.Command{
+		Use:          "unpin PACK",
+		Short:        "Let snag packs update move a pack forward again",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE:         func(cmd *cobra.Command, args []string) error { return runPacksPin(cmd, args[0], false) },
+	}
+	cmd.AddCommand(list, update, pin, unpin)
+	return cmd
+}
+
+// packVersion formats an installed version; 0 is the built-in pack.
+func packVersion(v int) string {
+	if v == 0 {
+		return "built-in"
+	}
+	return "v" + strconv.Itoa(v)
+}
+
+func runPacksList(cmd *cobra.Command, args []string) error {
+	s, _, err := loadPackState()
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	for _, name := range rulePackNames {
+		line := fmt.Sprintf("%-10s %s", name, packVersion(s.installedVersion(name)))
+		if v, ok := s.Pins[name]; ok {
+			line += fmt.Sprintf(" (pinned at %s)", packVersion(v))
+		}
+		fmt.Fprintln(out, line)
+	}
+	if !s.UpdatedAt.IsZero() {
+		fmt.Fprintf(out, "last update %s from %s\n", s.UpdatedAt.Local().Format("2006-01-02 15:04"), s.Source)
+	}
+	return nil
+}
+
+func runPacksUpdate(cmd *cobra.Command, args []string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	cwd, _ := os.Getwd()
+	bc, _, _ := walkConfig(cwd)
+	if ok, why := networkAllowed(bc); !ok {
+		return fmt.Errorf("snag packs update needs the network (disabled by %s)", why)
+	}
+	url, key := os.Getenv("SNAG_PACKS_URL"), os.Getenv("SNAG_PACKS_KEY")
+	if url == "" || key == "" {
+		return errors.New("snag packs update needs a channel: set SNAG_PACKS_URL to a packs.json (served next to packs.json.sig) and SNAG_PACKS_KEY to its base64 ed25519 public key; there is no official channel yet")
+	}
+	client := &http.Client{Timeout: packsTimeout}
+	data, err := fetchPackFile(client, url)
+	if err != nil {
+		return fmt.Errorf("fetching packs: %w", err)
+	}
+	sig, err := fetchPackFile(client, url+".sig")
+	if err != nil {
+		return fmt.Errorf("fetching packs signature: %w", err)
+	}
+	fetched, err := verifyPackBundle(data, sig)
+	if err != nil {
+		return fmt.Errorf("%s: %w", url, err)
+	}
+
+	s, path, err := loadPackState()
+	if err != nil {
+		return err
+	}
+	changed := 0
+	for _, p := range fetched {
+		have := s.installedVersion(p.Name)
+		switch pinned, isPinned := s.Pins[p.Name]; {
+		case p.Version <= have:
+			if !quiet {
+				infof("%s is up to date (%s)", p.Name, packVersion(have))
+			}
+		case isPinned:
+			if !quiet {
+				infof("%s is pinned at %s; v%d is available", p.Name, packVersion(pinned), p.Version)
+			}
+		default:
+			if !quiet {
+				infof("%s: %s → v%d", p.Name, packVersion(have), p.Version)
+			}
+			s.Packs = slices.DeleteFunc(s.Packs, func(q rulePack) bool { return q.Name == p.Name })
+			s.Packs = append(s.Packs, p)
+			changed++
+		}
+	}
+	if dryRun {
+		return nil
+	}
+	s.UpdatedAt = now()
+	s.Source = url
+	if err := savePackState(path, s); err != nil {
+		return err
+	}
+	cachedPackState = nil
+	if !quiet && changed == 0 {
+		infof("nothing to update")
+	}
+	return nil
+}
+
+func runPacksPin(cmd *cobra.Command, name string, pin bool) error {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	if !slices.Contains(rulePackNames, name) {
+		return fmt.Errorf("no pack %q (known: %s)", name, strings.Join(rulePackNames, ", "))
+	}
+	s, path, err := loadPackState()
+	if err != nil {
+		return err
+	}
+	v := s.installedVersion(name)
+	if pin {
+		if s.Pins == nil {
+			s.Pins = map[string]int{}
+		}
+		s.Pins[name] = v
+	} else {
+		delete(s.Pins, name)
+	}
+	if err := savePackState(path, s); err != nil {
+		return err
+	}
+	if !quiet {
+		if pin {
+			infof("pinned %s at %s", name, packVersion(v))
+		} else {
+			infof("unpinned %s", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// conditionalRule is one [[block.rule]]: a pattern that applies only in
+// repositories whose metadata matches its when table, and only on the
+// branches it lists.
+//
+//	[[block.rule]]
+//	pattern = "internal-api"
+//	in = ["diff", "msg"]          # default: both
+//	when = { remote_matches = "github.com/org/public-*" }
+//	branches = ["release/*", "main"]  # default: every branch
+//	normalize = true              # match obfuscated spellings too
+type conditionalRule struct {
+	Pattern   string   `toml:"pattern"`
+	In        []string `toml:"in"`
+	When      ruleWhen `toml:"when"`
+	Branches  []string `toml:"branches"`
+	Normalize bool     `toml:"normalize"` // same as writing the pattern as norm:PATTERN
+}
+
+// ruleWhen lists the repository properties a rule requires. Each set field
+// is a path.Match glob; all must match.
+type ruleWhen struct {
+	RemoteMatches string `toml:"remote_matches"` // origin URL as host/owner/repo
+	DefaultBranch string `toml:"default_branch"`
+	RepoName      string `toml:"repo_name"`
+}
+
+// ruleConditions are the when keys, for validating config.
+var ruleConditions = []string{"remote_matches", "default_branch", "repo_name"}
+
+// ruleHooks are the phases a rule may name in "in". Push is left out: an
+// unset push list already inherits diff and msg.
+var ruleHooks = []string{"diff", "msg"}
+
+func validateRules(rules []conditionalRule) error {
+	for i, r := range rules {
+		if strings.TrimSpace(r.Pattern) == "" {
+			return fmt.Errorf("block.rule[%d]: pattern is required", i)
+		}
+		if r.Normalize && (isHashPattern(r.Pattern) || isNormPattern(r.Pattern)) {
+			return fmt.Errorf("block.rule[%d]: normalize needs a plain pattern, got %q", i, r.Pattern)
+		}
+		for _, h := range r.In {
+			if h != "diff" && h != "msg" {
+				return fmt.Errorf("block.rule[%d]: in must list %s, got %q", i, strings.Join(ruleHooks, " or "), h)
+			}
+		}
+		for key, glob := range map[string]string{
+			"remote_matches": r.When.RemoteMatches, "default_branch": r.When.DefaultBranch, "repo_name": r.When.RepoName,
+		} {
+			if _, err := path.Match(glob, ""); err != nil {
+				return fmt.Errorf("block.rule[%d]: when.%s: bad pattern %q", i, key, glob)
+			}
+		}
+		for _, b := range r.Branches {
+			if _, err := path.Match(b, ""); err != nil || strings.TrimSpace(b) == "" {
+				return fmt.Errorf("block.rule[%d]: branches: bad pattern %q", i, b)
+			}
+		}
+	}
+	return nil
+}
+
+// repoMeta is what rule conditions are matched against.
+type repoMeta struct {
+	Remote        string // normalized origin URL, "" without one
+	DefaultBranch string
+	Name          string
+	Branch        string // checked-out branch, "" on a detached HEAD
+}
+
+// repoMetaCache memoizes repoMeta per working directory: config is
+// resolved several times per run and each lookup costs git calls.
+var repoMetaCache = map[string]repoMeta{}
+
+func currentRepoMeta() repoMeta {
+	cwd, _ := os.Getwd()
+	if m, ok := repoMetaCache[cwd]; ok {
+		return m
+	}
+	git := func(args ...string) string {
+		out, err := gitCommand(args...).Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	}
+	var m repoMeta
+	remote := git("remote", "get-url", "origin")
+	if remote == "" {
+		if first, _, _ := strings.Cut(git("remote"), "\n"); first != "" {
+			remote = git("remote", "get-url", first)
+		}
+	}
+	m.Remote = normalizeRemote(remote)
+
+	// The remote's HEAD is authoritative; without one, take the first
+	// conventional branch that exists, then the branch HEAD names.
+	if head := git("symbolic-ref", "--quiet", "refs/remotes/origin/HEAD"); head != "" {
+		m.DefaultBranch = strings.TrimPrefix(head, "refs/remotes/origin/")
+	} else {
+		for _, b := range []string{git("config", "init.defaultBranch"), "main", "master"} {
+			if b != "" && git("rev-parse", "--verify", "--quiet", "refs/heads/"+b) != "" {
+				m.DefaultBranch = b
+				break
+			}
+		}
+		if m.DefaultBranch == "" {
+			m.DefaultBranch = git("symbolic-ref", "--quiet", "--short", "HEAD")
+		}
+	}
+
+	// Mid-rebase HEAD is detached; the branch being rebased is the one
+	// whose rules should apply.
+	m.Branch = git("symbolic-ref", "--quiet", "--short", "HEAD")
+	if m.Branch == "" {
+		for _, dir := range []string{"rebase-merge", "rebase-apply"} {
+			if data, err := os.ReadFile(git("rev-parse", "--git-path", dir+"/head-name")); err == nil {
+				m.Branch = strings.TrimPrefix(strings.TrimSpace(string(data)), "refs/heads/")
+				break
+			}
+		}
+	}
+
+	if m.Remote != "" {
+		m.Name = path.Base(m.Remote)
+	} else if top := git("rev-parse", "--show-toplevel"); top != "" {
+		m.Name = filepath.Base(top)
+	}
+	repoMetaCache[cwd] = m
+	return m
+}
+
+// normalizeRemote reduces a remote URL to host/owner/repo, so one glob
+// covers https, ssh, and scp-style forms:
+// git@github.com:org/x.git and https://github.com/org/x both become
+// github.com/org/x.
+func normalizeRemote(remote string) string {
+	if remote == "" {
+		return ""
+	}
+	var host, p string
+	if u, err := url.Parse(remote); err == nil && u.Scheme != "" && u.Host != "" {
+		host, p = u.Hostname(), u.Path
+	} else if at, rest, ok := strings.Cut(remote, ":"); ok && !strings.Contains(at, "/") {
+		_, host, _ = strings.Cut(at, "@")
+		if host == "" {
+			host = at
+		}
+		p = rest
+	} else {
+		return strings.TrimSuffix(filepath.ToSlash(remote), ".git")
+	}
+	return strings.ToLower(host) + "/" + strings.TrimSuffix(strings.Trim(p, "/"), ".git")
+}
+
+// matches reports whether every condition w sets holds for m. A rule with
+// no conditions always applies.
+func (w ruleWhen) matches(m repoMeta) bool {
+	for _, c := range []struct{ glob, value string }{
+		{w.RemoteMatches, m.Remote},
+		{w.DefaultBranch, m.DefaultBranch},
+		{w.RepoName, m.Name},
+	} {
+		if c.glob == "" {
+			continue
+		}
+		if ok, _ := path.Match(c.glob, c.value); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (w ruleWhen) String() string {
+	var parts []string
+	for _, c := range []struct{ key, glob string }{
+		{"remote_matches", w.RemoteMatches}, {"default_branch", w.DefaultBranch}, {"repo_name", w.RepoName},
+	} {
+		if c.glob != "" {
+			parts = append(parts, fmt.Sprintf("%s=%q", c.key, c.glob))
+		}
+	}
+	if len(parts) == 0 {
+		return "always"
+	}
+	return strings.Join(parts, " ")
+}
+
+// applies reports whether r's when conditions hold for m and m's branch is
+// one r lists. Branches use the same matching as [block] branch.
+func (r conditionalRule) applies(m repoMeta) bool {
+	if !r.When.matches(m) {
+		return false
+	}
+	return len(r.Branches) == 0 || (m.Branch != "" && isProtected(m.Branch, r.Branches))
+}
+
+// scope describes where r applies, for snag config.
+func (r conditionalRule) scope() string {
+	s := r.When.String()
+	if len(r.Branches) > 0 {
+		b := "branches=" + strings.Join(quoteAll(r.Branches), ",")
+		if s == "always" {
+			return b
+		}
+		s += " " + b
+	}
+	return s
+}
+
+// hooks returns the phases r applies to.
+func (r conditionalRule) hooks() []string {
+	if len(r.In) == 0 {
+		return ruleHooks
+	}
+	return r.In
+}
+
+// applyRules adds the patterns of rules whose conditions hold for the
+// current repository to b, so they merge like any other pattern.
+func applyRules(b *blockSection) {
+	if len(b.Rule) == 0 {
+		return
+	}
+	meta := currentRepoMeta()
+	for _, r := range b.Rule {
+		if !r.applies(meta) {
+			continue
+		}
+		p := r.Pattern
+		if r.Normalize {
+			p = normPatternPrefix + p
+		}
+		for _, h := range r.hooks() {
+			switch h {
+			case "diff":
+				b.Diff = append(b.Diff, p)
+			case "msg":
+				b.Msg = append(b.Msg, p)
+			}
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeRemote(t *testing.T) {
+	for in, want := range map[string]string{
+		"git@github.com:Org/public-api.git":       "github.com/Org/public-api",
+		"https://github.com/org/public-api.git":   "github.com/org/public-api",
+		"ssh://git@GitLab.example.com:2222/a/b/c": "gitlab.example.com/a/b/c",
+		"/srv/git/repo.git":                       "/srv/git/repo",
+		"":                                        "",
+	} {
+		if got := normalizeRemote(in); got != want {
+			t.Errorf("normalizeRemote(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestConditionalRules(t *testing.T) {
+	parent := t.TempDir()
+	os.WriteFile(filepath.Join(parent, "snag.toml"), []byte(`[block]
+diff = ["always"]
+
+[[block.rule]]
+pattern = "internal-api"
+when = { remote_matches = "github.com/org/public-*" }
+
+[[block.rule]]
+pattern = "legacy"
+in = ["msg"]
+when = { repo_name = "old-*", default_branch = "master" }
+`), 0644)
+
+	newRepo := func(name, remote string) string {
+		dir := filepath.Join(parent, name)
+		os.Mkdir(dir, 0755)
+		for _, args := range [][]string{{"init", "-q", "-b", "master"}, {"remote", "add", "origin", remote}} {
+			if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+				t.Fatalf("git %v: %v\n%s", args, err, out)
+			}
+		}
+		return dir
+	}
+	public := newRepo("pub", "git@github.com:org/public-site.git")
+	private := newRepo("priv", "https://github.com/org/old-billing.git")
+
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+
+	os.Chdir(public)
+	bc, _, err := walkConfig(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(bc.Diff, "internal-api") || !slices.Contains(bc.Msg, "internal-api") || slices.Contains(bc.Msg, "legacy") {
+		t.Errorf("public repo: diff=%v msg=%v", bc.Diff, bc.Msg)
+	}
+
+	os.Chdir(private)
+	bc, _, err = walkConfig(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(bc.Diff, "internal-api") || !slices.Contains(bc.Msg, "legacy") || slices.Contains(bc.Diff, "legacy") {
+		t.Errorf("private repo: diff=%v msg=%v", bc.Diff, bc.Msg)
+	}
+}
+
+func TestConditionalRulesValidation(t *testing.T) {
+	for name, tc := range map[string]struct{ toml, want string }{
+		"unknown condition": {"[[block.rule]]\npattern = \"x\"\nwhen = { remote = \"a\" }\n", "unknown condition"},
+		"missing pattern":   {"[[block.rule]]\nin = [\"diff\"]\n", "pattern is required"},
+		"bad hook":          {"[[block.rule]]\npattern = \"x\"\nin = [\"push\"]\n", "in must list"},
+		"bad glob":          {"[[block.rule]]\npattern = \"x\"\nwhen = { repo_name = \"[\" }\n", "bad pattern"},
+		"bad branch glob":   {"[[block.rule]]\npattern = \"x\"\nbranches = [\"release/[\"]\n", "branches: bad pattern"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "snag.toml")
+			os.WriteFile(path, []byte(tc.toml), 0644)
+			if _, err := loadSnagTOML(path); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("err = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestBranchScopedRules(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	commitFile(t, dir, "snag.toml", `[[block.rule]]
+pattern = "console.log"
+branches = ["release/*", "main"]
+
+[[block.rule]]
+pattern = "legacy"
+in = ["msg"]
+`, "add policy")
+
+	orig, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(orig)
+
+	for _, tc := range []struct {
+		checkout []string
+		want     bool
+	}{
+		{[]string{"checkout", "-q", "-b", "release/1.2"}, true},
+		{[]string{"checkout", "-q", "-b", "spike/logging"}, false},
+		{[]string{"checkout", "-q", "--detach"}, false},
+		{nil, true}, // detached mid-rebase of release/1.2
+	} {
+		if tc.checkout == nil {
+			os.MkdirAll(filepath.Join(dir, ".git", "rebase-merge"), 0755)
+			os.WriteFile(filepath.Join(dir, ".git", "rebase-merge", "head-name"), []byte("refs/heads/release/1.2\n"), 0644)
+		} else if out, err := exec.Command("git", tc.checkout...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", tc.checkout, err, out)
+		}
+		clear(repoMetaCache)
+		bc, _, err := walkConfig(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := slices.Contains(bc.Diff, "console.log"); got != tc.want {
+			t.Errorf("after git %v: console.log in diff = %v, want %v", tc.checkout, got, tc.want)
+		}
+		if !slices.Contains(bc.Msg, "legacy") {
+			t.Errorf("after git %v: a rule without branches should always apply, msg=%v", tc.checkout, bc.Msg)
+		}
+	}
+}
+
+func TestConditionalRuleScope(t *testing.T) {
+	for _, tc := range []struct {
+		rule conditionalRule
+		want string
+	}{
+		{conditionalRule{}, "always"},
+		{conditionalRule{Branches: []string{"main", "release/*"}}, `branches="main","release/*"`},
+		{conditionalRule{When: ruleWhen{RepoName: "api"}, Branches: []string{"main"}}, `repo_name="api" branches="main"`},
+	} {
+		if got := tc.rule.scope(); got != tc.want {
+			t.Errorf("scope() = %q, want %q", got, tc.want)
+		}
+	}
+}
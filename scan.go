@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Scan modes for [scan]: which lines of a diff patterns are matched against.
+// Detectors and near-miss warnings always look at added lines only.
+const (
+	scanAdded        = "added"         // lines the change adds (default)
+	scanAddedContext = "added+context" // plus the unchanged lines git shows around them
+	scanFull         = "full"          // plus the lines the change removes
+)
+
+// scanSection picks a scan mode per hook:
+//
+//	[scan]
+//	diff = "added"          # pre-commit, check patch, checkout warnings
+//	push = "added+context"  # pre-push, server-hook, webhook
+//	audit = "full"          # audit, simulate, ci, report
+type scanSection struct {
+	Diff  string `toml:"diff"`
+	Push  string `toml:"push"`
+	Audit string `toml:"audit"`
+}
+
+// scanSetting is one hook's entry in [scan].
+type scanSetting struct{ Hook, Mode string }
+
+// settings lists the hooks s sets, in a fixed order.
+func (s scanSection) settings() []scanSetting {
+	var out []scanSetting
+	for _, e := range []scanSetting{{"diff", s.Diff}, {"push", s.Push}, {"audit", s.Audit}} {
+		if e.Mode != "" {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func validateScan(s scanSection) error {
+	for _, e := range s.settings() {
+		switch e.Mode {
+		case scanAdded, scanAddedContext, scanFull:
+		default:
+			return fmt.Errorf("scan.%s must be %q, %q, or %q, got %q", e.Hook, scanAdded, scanAddedContext, scanFull, e.Mode)
+		}
+	}
+	return nil
+}
+
+// scanMode returns the [scan] mode for hook, defaulting to added lines.
+func (bc *BlockConfig) scanMode(hook string) string {
+	if m := bc.Scan[hook]; m != "" {
+		return m
+	}
+	return scanAdded
+}
+
+// skipRulesFor returns skipRules carrying hook's scan mode.
+func (bc *BlockConfig) skipRulesFor(hook string) skipRules {
+	r := bc.skipRules()
+	r.Scan = bc.scanMode(hook)
+	return r
+}
+
+// scannedLines returns the lines of a single file's diff that mode checks,
+// numbered from the @@ hunk headers. Removed lines take the number of the
+// post-image line they sat above.
+func scannedLines(body, mode string) []numberedLine {
+	var out []numberedLine
+	newLine := 0
+	for _, l := range strings.Split(body, "\n") {
+		switch {
+		case strings.HasPrefix(l, "@@ "):
+			newLine = hunkNewStart(l)
+		case isDiffMeta(l):
+		case strings.HasPrefix(l, "+"):
+			out = append(out, numberedLine{Line: newLine, Text: l[1:]})
+			newLine++
+		case strings.HasPrefix(l, "-"):
+			if mode == scanFull {
+				out = append(out, numberedLine{Line: newLine, Text: l[1:]})
+			}
+		case strings.HasPrefix(l, "\\"):
+		default:
+			if newLine > 0 && l != "" && (mode == scanAddedContext || mode == scanFull) {
+				out = append(out, numberedLine{Line: newLine, Text: strings.TrimPrefix(l, " ")})
+			}
+			newLine++
+		}
+	}
+	return out
+}
+
+// joinLines rejoins scanned lines so multi-line matching still works.
+func joinLines(lines []numberedLine) string {
+	texts := make([]string, len(lines))
+	for i, l := range lines {
+		texts[i] = l.Text
+	}
+	return strings.Join(texts, "\n")
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+const scanTestDiff = `diff --git a/a.txt b/a.txt
+--- a/a.txt
++++ b/a.txt
+@@ -1,3 +1,3 @@
+ kept line
+-old line
++new line
+ tail line
+`
+
+func TestScannedLines_Modes(t *testing.T) {
+	tests := []struct {
+		mode string
+		want []numberedLine
+	}{
+		{scanAdded, []numberedLine{{2, "new line"}}},
+		{"", []numberedLine{{2, "new line"}}},
+		{scanAddedContext, []numberedLine{{1, "kept line"}, {2, "new line"}, {3, "tail line"}}},
+		{scanFull, []numberedLine{{1, "kept line"}, {2, "old line"}, {2, "new line"}, {3, "tail line"}}},
+	}
+	for _, tc := range tests {
+		got := scannedLines(scanTestDiff, tc.mode)
+		if len(got) != len(tc.want) {
+			t.Errorf("%q: got %v, want %v", tc.mode, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("%q: line %d = %v, want %v", tc.mode, i, got[i], tc.want[i])
+			}
+		}
+	}
+}
+
+func TestMatchDiff_ScanMode(t *testing.T) {
+	if _, _, found := matchDiff(scanTestDiff, []string{"old"}, skipRules{}); found {
+		t.Error("removed lines should not match by default")
+	}
+	hit, _, found := matchDiff(scanTestDiff, []string{"old"}, skipRules{Scan: scanFull})
+	if !found || hit.Line != 2 {
+		t.Errorf("full scan: hit = %+v, found = %v; want line 2", hit, found)
+	}
+	if _, _, found := matchDiff(scanTestDiff, []string{"tail"}, skipRules{Scan: scanAddedContext}); !found {
+		t.Error("added+context should match context lines")
+	}
+}
+
+func TestLoadSnagTOML_ScanValidation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snag.toml")
+	os.WriteFile(path, []byte("[scan]\npush = \"removed\"\n"), 0644)
+	if _, err := loadSnagTOML(path); err == nil {
+		t.Error("expected an error for an unknown scan mode")
+	}
+}
+
+func TestRunDiff_ScanFullCatchesRemoval(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+
+	pattern := "block" + "ed_scan_word"
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte(pattern+"\n"), 0644)
+	for _, args := range [][]string{{"add", "notes.txt"}, {"commit", "-m", "add notes"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[block]\ndiff = [\""+pattern+"\"]\n\n[scan]\ndiff = \"full\"\n"), 0644)
+	stageFile(t, dir, "notes.txt", "clean\n")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"check", "diff", "--quiet"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Fatal("scan = \"full\" should flag the removed line")
+	}
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	scrubReplacementsFile = "scrub-replacements.txt"
+	scrubPlaceholder      = "***REMOVED***"
+)
+
+// scrubPlan is everything needed to purge patterns from history: the
+// commits that contain them, the refs those commits are reachable from,
+// and the git filter-repo expressions that rewrite them.
+type scrubPlan struct {
+	Patterns     []string
+	Reports      []commitReport
+	Refs         []string // full ref names containing an affected commit
+	Replacements string   // filter-repo --replace-text / --replace-message file body
+}
+
+func buildScrubCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scrub --pattern TEXT [--plan | --execute]",
+		Short: "Plan (and optionally run) a history rewrite that removes a pattern",
+		Long: `Find every commit on any ref whose message or diff contains PATTERN and
+print a concrete remediation plan: the affected commits and refs, the
+git filter-repo invocation that replaces the pattern, and a force-push
+checklist.
+
+--execute runs the rewrite after confirmation (requires git filter-repo).
+Pushing the rewritten refs is always left to you.
+
+--emit-filter-repo-script FILE instead writes a git-filter-repo script
+whose blob and commit callbacks apply the policy's sensitive rules
+(patterns from sensitive = true configs, sha256: patterns, and [redact]
+literals) with the same matching as enforcement.`,
+		Example: `  snag scrub --pattern "oldsecret" --plan
+  snag scrub --pattern "oldsecret" --execute
+  snag scrub --emit-filter-repo-script /tmp/scrub.py`,
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE:         runScrub,
+	}
+	cmd.Flags().StringArray("pattern", nil, "text to remove from history (repeatable, case-insensitive)")
+	cmd.Flags().String("replacement", scrubPlaceholder, "text substituted for each match")
+	cmd.Flags().Bool("plan", false, "print the plan only (default)")
+	cmd.Flags().Bool("execute", false, "run git filter-repo after confirmation")
+	cmd.Flags().Bool("yes", false, "skip the confirmation prompt with --execute")
+	cmd.Flags().String("emit-filter-repo-script", "", `write a filter-repo script for the policy's sensitive rules to FILE ("-" for stdout)`)
+	cmd.MarkFlagsMutuallyExclusive("plan", "execute", "emit-filter-repo-script")
+	return cmd
+}
+
+func runScrub(cmd *cobra.Command, args []string) error {
+	raw, _ := cmd.Flags().GetStringArray("pattern")
+	replacement, _ := cmd.Flags().GetString("replacement")
+	execute, _ := cmd.Flags().GetBool("execute")
+	yes, _ := cmd.Flags().GetBool("yes")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+
+	if script, _ := cmd.Flags().GetString("emit-filter-repo-script"); script != "" {
+		bc, err := resolveBlockConfig(cmd)
+		if err != nil {
+			return err
+		}
+		return emitFilterRepoScript(cmd, bc, raw, replacement, script)
+	}
+	if len(raw) == 0 {
+		return fmt.Errorf("--pattern is required (or use --emit-filter-repo-script)")
+	}
+	// The replacements file is one "pattern==>replacement" rule per line,
+	// split at the last ==>.
+	if strings.ContainsAny(replacement, "\r\n") || strings.Contains(replacement, "==>") {
+		return fmt.Errorf("--replacement %q: must be one line without ==>", replacement)
+	}
+
+	var patterns []string
+	for _, p := range raw {
+		if p == "" {
+			return fmt.Errorf("--pattern must not be empty")
+		}
+		if isHashPattern(p) {
+			return fmt.Errorf("%s: hashed patterns can't be rewritten; pass the original text", p)
+		}
+		if isNormPattern(p) {
+			return fmt.Errorf("%s: norm: patterns match many spellings; pass each literal to rewrite", p)
+		}
+		patterns = append(patterns, strings.ToLower(p))
+	}
+
+	plan, err := buildScrubPlan(deduplicatePatterns(patterns), replacement)
+	if err != nil {
+		return err
+	}
+	if len(plan.Reports) == 0 {
+		if !quiet {
+			infof("no commits on any ref contain the pattern")
+		}
+		return nil
+	}
+
+	dir, err := snagStateDir()
+	if err != nil {
+		return err
+	}
+	replFile := filepath.Join(dir, scrubReplacementsFile)
+	if err := os.WriteFile(replFile, []byte(plan.Replacements), 0600); err != nil {
+		return fmt.Errorf("writing %s: %w", replFile, err)
+	}
+
+	printScrubPlan(cmd.OutOrStdout(), plan, replFile)
+	if !execute {
+		return nil
+	}
+
+	if err := gitCommand("filter-repo", "--version").Run(); err != nil {
+		return fmt.Errorf("git filter-repo not found — install it (pip install git-filter-repo) or run the plan by hand")
+	}
+	if !yes {
+		ok, err := confirmScrub(len(plan.Reports), len(plan.Refs))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			infof("aborted — nothing rewritten")
+			return nil
+		}
+	}
+	rewrite := gitCommand(scrubFilterArgs(replFile)...)
+	rewrite.Stdout, rewrite.Stderr = os.Stderr, os.Stderr
+	if err := rewrite.Run(); err != nil {
+		return fmt.Errorf("git filter-repo: %w", err)
+	}
+	os.Remove(replFile)
+	if !quiet {
+		infof("history rewritten — finish with the force-push checklist above")
+	}
+	return nil
+}
+
+// buildScrubPlan scans every commit reachable from any ref for patterns.
+func buildScrubPlan(patterns []string, replacement string) (scrubPlan, error) {
+	plan := scrubPlan{Patterns: patterns}
+	out, err := gitCommand("rev-list", "--all").Output()
+	if err != nil {
+		return plan, fmt.Errorf("git rev-list --all: %w", err)
+	}
+	shas := strings.Fields(string(out))
+	if len(shas) == 0 {
+		return plan, nil
+	}
+
+	unlimited := 0
+	bc := &BlockConfig{Diff: patterns, Msg: patterns, MaxFileBytes: &unlimited}
+	plan.Reports = scanCommits(shas, bc)
+
+	refs := make(map[string]bool)
+	for _, r := range plan.Reports {
+		out, err := gitCommand("for-each-ref", "--format=%(refname)", "--contains", r.SHA).Output()
+		if err != nil {
+			return plan, fmt.Errorf("git for-each-ref --contains %s: %w", r.SHA[:7], err)
+		}
+		for _, ref := range strings.Fields(string(out)) {
+			refs[ref] = true
+		}
+	}
+	for ref := range refs {
+		plan.Refs = append(plan.Refs, ref)
+	}
+	sort.Strings(plan.Refs)
+	plan.Replacements = scrubReplacements(patterns, replacement)
+	return plan, nil
+}
+
+// scrubReplacements renders filter-repo expressions. Patterns match
+// case-insensitively like everywhere else in snag, so each becomes a
+// quoted (?i) regex rather than a literal. filter-repo hands a regex
+// rule's replacement to Python's re.sub as a template, so its backslashes
+// are doubled to keep \1 or \g<0> from being expanded.
+func scrubReplacements(patterns []string, replacement string) string {
+	replacement = strings.ReplaceAll(replacement, `\`, `\\`)
+	var b strings.Builder
+	for _, p := range patterns {
+		fmt.Fprintf(&b, "regex:(?i)%s==>%s\n", regexp.QuoteMeta(p), replacement)
+	}
+	return b.String()
+}
+
+func scrubFilterArgs(replFile string) []string {
+	return []string{"filter-repo", "--replace-text", replFile, "--replace-message", replFile, "--force"}
+}
+
+func printScrubPlan(w io.Writer, plan scrubPlan, replFile string) {
+	fmt.Fprintf(w, "Affected commits (%d):\n", len(plan.Reports))
+	for _, r := range plan.Reports {
+		kinds := make([]string, 0, len(r.Matches))
+		for _, m := range r.Matches {
+			where := m.Kind
+			if m.Path != "" {
+				where += " " + m.Path
+			}
+			kinds = append(kinds, where)
+		}
+		fmt.Fprintf(w, "  %s %q (%s)\n", r.SHA[:7], r.Subject, strings.Join(kinds, ", "))
+	}
+
+	var pushable, remote []string
+	fmt.Fprintf(w, "\nAffected refs (%d):\n", len(plan.Refs))
+	for _, ref := range plan.Refs {
+		fmt.Fprintf(w, "  %s\n", ref)
+		switch {
+		case strings.HasPrefix(ref, "refs/heads/"), strings.HasPrefix(ref, "refs/tags/"):
+			pushable = append(pushable, ref)
+		case strings.HasPrefix(ref, "refs/remotes/"):
+			remote = append(remote, strings.TrimPrefix(ref, "refs/remotes/"))
+		}
+	}
+
+	fmt.Fprintf(w, "\nPlan:\n")
+	fprintPlain(w, "  1. Rotate the exposed value now — rewriting history doesn't un-leak it.\n")
+	fmt.Fprintf(w, "  2. Back up: git clone --mirror . ../%s-backup.git\n", repoBaseName())
+	fmt.Fprintf(w, "  3. Rewrite (expressions in %s):\n", replFile)
+	fmt.Fprintf(w, "       git %s\n", strings.Join(scrubFilterArgs(replFile), " "))
+	fmt.Fprintf(w, "  4. Verify: snag scrub --pattern ... --plan reports no commits\n")
+	fmt.Fprintf(w, "  5. filter-repo removes the origin remote; re-add it: git remote add origin <url>\n")
+	fmt.Fprintf(w, "  6. Force-push each rewritten ref:\n")
+	for _, ref := range pushable {
+		fmt.Fprintf(w, "       git push --force origin %s\n", ref)
+	}
+	if len(remote) > 0 {
+		fmt.Fprintf(w, "     Remote-only refs also contain it (%s); rewrite them from a fresh mirror clone.\n", strings.Join(remote, ", "))
+	}
+	fmt.Fprintf(w, "  7. Ask collaborators to re-clone, and ask your host to purge cached views and PR refs.\n")
+}
+
+// repoBaseName returns the name of the repository's top-level directory.
+func repoBaseName() string {
+	out, err := gitCommand("rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "repo"
+	}
+	return filepath.Base(strings.TrimSpace(string(out)))
+}
+
+// confirmScrub asks before rewriting history. Non-interactive sessions
+// decline; pass --yes to run unattended.
+var confirmScrub = func(commits, refs int) (bool, error) {
+	if !isTTY() {
+		warnf("not a terminal — not rewriting (use --yes to run unattended)")
+		return false, nil
+	}
+	fmt.Fprintf(os.Stderr, "Rewrite %d commit(s) across %d ref(s)? This cannot be undone without the backup. [y/N]: ", commits, refs)
+	scanner := bufio.NewScanner(os.Stdin)
+	if !scanner.Scan() {
+		return false, fmt.Errorf("prompt cancelled")
+	}
+	answer := strings.ToLower(strings.TrimSpace(scanner.Text()))
+	return answer == "y" || answer == "yes", nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScrubReplacements(t *testing.T) {
+	got := scrubReplacements([]string{"oldsecret", "a.b*c"}, scrubPlaceholder)
+	want := "regex:(?i)oldsecret==>***REMOVED***\nregex:(?i)a\\.b\\*c==>***REMOVED***\n"
+	if got != want {
+		t.Errorf("scrubReplacements =\n%s\nwant\n%s", got, want)
+	}
+	if got := scrubReplacements([]string{"x"}, `$1 \1 \g<0>`); got != `regex:(?i)x==>$1 \\1 \\g<0>`+"\n" {
+		t.Errorf("replacement not escaped for re.sub: %q", got)
+	}
+}
+
+func TestScrub_Plan(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	commitFile(t, dir, "config.yml", "token: OldSecret\n", "add config")
+	commitFile(t, dir, "other.txt", "clean\n", "unrelated")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	var out bytes.Buffer
+	rootCmd := buildRootCmd()
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"scrub", "--pattern", "oldsecret", "-q"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("scrub: %v", err)
+	}
+
+	plan := out.String()
+	for _, want := range []string{
+		"Affected commits (1):", `"add config" (diff config.yml)`,
+		"git filter-repo --replace-text", "git push --force origin refs/heads/",
+	} {
+		if !strings.Contains(plan, want) {
+			t.Errorf("plan missing %q:\n%s", want, plan)
+		}
+	}
+	if strings.Contains(plan, "unrelated") {
+		t.Errorf("plan lists an unaffected commit:\n%s", plan)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, ".git", "snag", scrubReplacementsFile))
+	if err != nil || !strings.Contains(string(data), "oldsecret==>") {
+		t.Errorf("replacements file: %q, %v", data, err)
+	}
+}
+
+func TestScrub_RejectsHashPattern(t *testing.T) {
+	dir := initGitRepo(t)
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"scrub", "--pattern", hashToken("x"), "-q"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("expected error for hashed pattern")
+	}
+}
+
+func TestScrub_RejectsMultilineReplacement(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	for _, r := range []string{"a\nregex:.*==>x", "a==>b"} {
+		rootCmd := buildRootCmd()
+		rootCmd.SetArgs([]string{"scrub", "--pattern", "secret", "--replacement", r, "-q"})
+		if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "--replacement") {
+			t.Errorf("--replacement %q: err = %v", r, err)
+		}
+	}
+}
+
+func TestSensitiveScrubRules(t *testing.T) {
+	bc := &BlockConfig{
+		Sensitive: map[string]bool{"acme.corp": true, hashToken("hunter2"): true},
+		Redact:    map[string]string{"Internal.Corp.com": "example.com"},
+	}
+	rules, hashes := sensitiveScrubRules(bc, []string{"ACME.corp", "extra"}, scrubPlaceholder)
+	want := []filterRepoRule{
+		{From: "internal.corp.com", To: "example.com"},
+		{From: "acme.corp", To: scrubPlaceholder},
+		{From: "extra", To: scrubPlaceholder},
+	}
+	if len(rules) != len(want) {
+		t.Fatalf("rules = %v, want %v", rules, want)
+	}
+	for i := range want {
+		if rules[i] != want[i] {
+			t.Errorf("rules[%d] = %v, want %v", i, rules[i], want[i])
+		}
+	}
+	if len(hashes) != 1 || "sha256:"+hashes[0] != hashToken("hunter2") {
+		t.Errorf("hashes = %v", hashes)
+	}
+}
+
+func TestPyBytes(t *testing.T) {
+	if got := pyBytes("a\"b\\c\xe2\x80\x8b"); got != `b"a\"b\\c\xe2\x80\x8b"` {
+		t.Errorf("pyBytes = %s", got)
+	}
+}
+
+func TestScrub_EmitFilterRepoScript(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"todo\"]\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "snag-local.toml"),
+		[]byte("[block]\nsensitive = true\ndiff = [\"acme.corp\"]\n"), 0644)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	script := filepath.Join(t.TempDir(), "scrub.py")
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"scrub", "--emit-filter-repo-script", script, "-q"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("emit: %v", err)
+	}
+	data, err := os.ReadFile(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(data)
+	if !strings.Contains(s, `re.escape(b"acme.corp")`) || !strings.Contains(s, "blob_callback=blob_callback") {
+		t.Errorf("script missing sensitive rule or callbacks:\n%s", s)
+	}
+	if strings.Contains(s, `b"todo"`) {
+		t.Error("non-sensitive pattern leaked into the script")
+	}
+	if !strings.Contains(s, "pattern.sub(lambda _match, r=replacement: r, data)") {
+		t.Error("literal rules must substitute replacements verbatim, not as re.sub templates")
+	}
+
+	var out bytes.Buffer
+	rootCmd = buildRootCmd()
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"scrub", "--emit-filter-repo-script", "-", "-q"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("emit to stdout: %v", err)
+	}
+	if out.String() != s {
+		t.Errorf("--emit-filter-repo-script - should write the same script to the command's output, got:\n%s", out.String())
+	}
+}
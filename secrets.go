@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// secretService is a credential snag features can read from the OS
+// credential store. The environment variable, when set, wins, so CI jobs
+// keep working without a keychain.
+type secretService struct {
+	Name    string
+	Env     string
+	Summary string
+}
+
+var secretServices = []secretService{
+	{"webhook-secret", "SNAG_WEBHOOK_SECRET", "shared secret for snag webhook serve"},
+	{"gitea", "SNAG_GITEA_TOKEN", "Gitea access token (webhook commit statuses)"},
+	{"gitlab", "SNAG_GITLAB_TOKEN", "GitLab token with api scope (webhook commit statuses)"},
+	{"gerrit", "SNAG_GERRIT_PASSWORD", "Gerrit HTTP password (webhook reviews; user from SNAG_GERRIT_USER)"},
+	{"jira", "SNAG_JIRA_TOKEN", "Jira API token ([tickets] verify; Cloud email from SNAG_JIRA_USER)"},
+	{"linear", "SNAG_LINEAR_TOKEN", "Linear API key ([tickets] verify)"},
+}
+
+func findSecretService(name string) (secretService, bool) {
+	for _, s := range secretServices {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return secretService{}, false
+}
+
+func secretServiceNames() []string {
+	names := make([]string, len(secretServices))
+	for i, s := range secretServices {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// errSecretNotFound is returned by a backend holding nothing for a service.
+var errSecretNotFound = errors.New("not found")
+
+// secretBackend is a place snag keeps credentials. Backends wrap the
+// platform's own command-line tool, so snag needs no cgo. Set and Delete
+// are nil for read-only backends.
+type secretBackend struct {
+	Name      string
+	Summary   string
+	Available func() bool
+	Get       func(service string) (string, error)
+	Set       func(service, secret string) error
+	Delete    func(service string) error
+}
+
+// secretAccount is the service (keychain) or attribute value (Secret
+// Service) every snag credential is filed under.
+const secretAccount = "snag"
+
+// secretBackends are tried in order when SNAG_SECRETS_BACKEND doesn't pick
+// one; the first available is used.
+var secretBackends = []*secretBackend{
+	{
+		Name:      "command",
+		Summary:   "runs $SNAG_SECRETS_COMMAND with the service name as $1 (read-only)",
+		Available: func() bool { return os.Getenv("SNAG_SECRETS_COMMAND") != "" },
+		Get:       commandSecretGet,
+	},
+	{
+		Name:      "keychain",
+		Summary:   "macOS login keychain, via security(1)",
+		Available: func() bool { return runtime.GOOS == "darwin" && haveCommand("security") },
+		Get:       keychainGet,
+		Set:       keychainSet,
+		Delete:    keychainDelete,
+	},
+	{
+		Name:    "secret-service",
+		Summary: "freedesktop Secret Service (GNOME Keyring, KWallet), via secret-tool(1)",
+		Available: func() bool {
+			return runtime.GOOS != "darwin" && runtime.GOOS != "windows" && haveCommand("secret-tool")
+		},
+		Get:    secretToolGet,
+		Set:    secretToolSet,
+		Delete: secretToolDelete,
+	},
+}
+
+func haveCommand(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+// currentSecretBackend returns the backend named by SNAG_SECRETS_BACKEND,
+// else the first available one. "none" turns the store off, leaving only
+// environment variables.
+func currentSecretBackend() (*secretBackend, error) {
+	name := os.Getenv("SNAG_SECRETS_BACKEND")
+	if name == "none" {
+		return nil, errors.New("the credential store is off (SNAG_SECRETS_BACKEND=none)")
+	}
+	var names []string
+	for _, b := range secretBackends {
+		names = append(names, b.Name)
+		if name == "" && b.Available() || b.Name == name {
+			return b, nil
+		}
+	}
+	if name != "" {
+		return nil, fmt.Errorf("unknown SNAG_SECRETS_BACKEND %q (known: %s, none)", name, strings.Join(names, ", "))
+	}
+	return nil, fmt.Errorf("no credential store found on %s; set SNAG_SECRETS_COMMAND or use environment variables", runtime.GOOS)
+}
+
+// lookupSecret returns the credential for a service and where it came
+// from: its environment variable, else the credential store. A missing
+// credential is "", "" with no error; a store that fails is an error.
+func lookupSecret(name string) (secret, source string, err error) {
+	svc, ok := findSecretService(name)
+	if !ok {
+		return "", "", fmt.Errorf("unknown secret %q", name)
+	}
+	if v := os.Getenv(svc.Env); v != "" {
+		return v, "$" + svc.Env, nil
+	}
+	b, err := currentSecretBackend()
+	if err != nil {
+		return "", "", nil // nothing to look in
+	}
+	v, err := b.Get(name)
+	if errors.Is(err, errSecretNotFound) || err == nil && v == "" {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("reading %s from %s: %w", name, b.Name, err)
+	}
+	return v, b.Name, nil
+}
+
+// secretOrWarn is lookupSecret for callers that carry on without the
+// credential: a failing store is reported, not fatal.
+func secretOrWarn(name string) string {
+	v, _, err := lookupSecret(name)
+	if err != nil {
+		warnf("%v", err)
+	}
+	return v
+}
+
+func commandSecretGet(service string) (string, error) {
+	out, err := exec.Command("sh", "-c", os.Getenv("SNAG_SECRETS_COMMAND"), "sh", service).Output()
+	if err != nil {
+		return "", commandError(err)
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// commandError adds a failed command's stderr to its error.
+func commandError(err error) error {
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && len(bytes.TrimSpace(exit.Stderr)) > 0 {
+		return fmt.Errorf("%w: %s", err, bytes.TrimSpace(exit.Stderr))
+	}
+	return err
+}
+
+// keychainNotFound is security(1)'s exit status for a missing item.
+const keychainNotFound = 44
+
+func keychainGet(service string) (string, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", secretAccount, "-a", service, "-w").Output()
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && exit.ExitCode() == keychainNotFound {
+		return "", errSecretNotFound
+	}
+	if err != nil {
+		return "", commandError(err)
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+// keychainSet passes the secret through security's interactive mode on
+// stdin, hex-encoded, so it never shows up in a process listing.
+func keychainSet(service, secret string) error {
+	c := exec.Command("security", "-i")
+	c.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -X %s\n",
+		secretAccount, service, hex.EncodeToString([]byte(secret))))
+	if out, err := c.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func keychainDelete(service string) error {
+	err := exec.Command("security", "delete-generic-password", "-s", secretAccount, "-a", service).Run()
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && exit.ExitCode() == keychainNotFound {
+		return errSecretNotFound
+	}
+	return err
+}
+
+func secretToolAttrs(service string) []string {
+	return []string{"application", secretAccount, "service", service}
+}
+
+// secretToolGet treats any failure with no output as a missing item:
+// secret-tool exits 1 for both, and says nothing when the item is absent.
+func secretToolGet(service string) (string, error) {
+	out, err := exec.Command("secret-tool", append([]string{"lookup"}, secretToolAttrs(service)...)...).Output()
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && len(bytes.TrimSpace(exit.Stderr)) == 0 {
+		return "", errSecretNotFound
+	}
+	if err != nil {
+		return "", commandError(err)
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+func secretToolSet(service, secret string) error {
+	args := append([]string{"store", "--label", "snag " + service}, secretToolAttrs(service)...)
+	c := exec.Command("secret-tool", args...)
+	c.Stdin = strings.NewReader(secret)
+	if out, err := c.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func secretToolDelete(service string) error {
+	out, err := exec.Command("secret-tool", append([]string{"clear"}, secretToolAttrs(service)...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import "strings"
+
+// markSensitive records every pattern in b so violation output redacts it.
+// Patterns are stored lowercased to match what resolveBlockConfig produces.
+func markSensitive(bc *BlockConfig, b blockSection) {
+	if bc.Sensitive == nil {
+		bc.Sensitive = make(map[string]bool)
+	}
+	all := append(append(append([]string{}, b.Diff...), b.Msg...), b.URLs...)
+	if b.Push != nil {
+		all = append(all, *b.Push...)
+	}
+	for _, p := range all {
+		bc.Sensitive[strings.ToLower(p)] = true
+	}
+}
+
+// display returns pattern as it should appear in terminal and CI output:
+// verbatim, or masked via redact when it came from a sensitive config file.
+func (bc *BlockConfig) display(pattern string) string {
+	if bc.Sensitive[pattern] {
+		if body, ok := strings.CutPrefix(pattern, normPatternPrefix); ok {
+			return normPatternPrefix + redact(body)
+		}
+		return redact(pattern)
+	}
+	return pattern
+}
+
+// displayLine returns a line of context around a match of pattern as it
+// should appear in output: verbatim, unless pattern is sensitive and the
+// line matches it. Then each literal occurrence is masked with redact, or
+// for norm: and sha256: patterns, whose text can't be located, the whole
+// line after its diff marker.
+func (bc *BlockConfig) displayLine(line, pattern string) string {
+	if !bc.Sensitive[pattern] {
+		return line
+	}
+	if _, found := matchesPattern(line, []string{pattern}); !found {
+		return line
+	}
+	if isNormPattern(pattern) || isHashPattern(pattern) {
+		if line == "" {
+			return line
+		}
+		return line[:1] + redact(line[1:])
+	}
+	lower := lowerBytesafe(line)
+	if len(lower) != len(line) {
+		return line[:1] + redact(line[1:])
+	}
+	var b strings.Builder
+	for {
+		i := strings.Index(lower, pattern)
+		if i < 0 {
+			b.WriteString(line)
+			return b.String()
+		}
+		b.WriteString(line[:i])
+		b.WriteString(redact(line[i : i+len(pattern)]))
+		line, lower = line[i+len(pattern):], lower[i+len(pattern):]
+	}
+}
+
+// redact keeps the first and last two characters of s and masks the rest,
+// so a violation is recognizable to its owner without echoing the secret
+// into scrollback or build logs. Strings of four runes or fewer are fully
+// masked.
+func redact(s string) string {
+	r := []rune(s)
+	if len(r) <= 4 {
+		return strings.Repeat("*", len(r))
+	}
+	return string(r[:2]) + strings.Repeat("*", len(r)-4) + string(r[len(r)-2:])
+}
+
+// redactAll applies redact to each pattern.
+func redactAll(patterns []string) []string {
+	out := make([]string, len(patterns))
+	for i, p := range patterns {
+		out[i] = redact(p)
+	}
+	return out
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"acme-corp", "ac*****rp"},
+		{"abcde", "ab*de"},
+		{"abcd", "****"},
+		{"", ""},
+		{"café-secret", "ca*******et"},
+	}
+	for _, tc := range tests {
+		if got := redact(tc.in); got != tc.want {
+			t.Errorf("redact(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestDisplay_OnlySensitiveFilesRedacted(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"todo\"]\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "snag-local.toml"),
+		[]byte("[block]\nsensitive = true\ndiff = [\"AcmeCorp\"]\n"), 0644)
+
+	bc, _, err := walkConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := bc.display("todo"); got != "todo" {
+		t.Errorf("display(todo) = %q, want verbatim", got)
+	}
+	if got := bc.display("acmecorp"); got != "ac****rp" {
+		t.Errorf("display(acmecorp) = %q, want redacted", got)
+	}
+}
+
+func TestRunDiff_SensitivePatternRedacted(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+
+	os.WriteFile(filepath.Join(dir, "snag-local.toml"),
+		[]byte("[block]\nsensitive = true\ndiff = [\"hunter2secret\"]\n"), 0644)
+	stageFile(t, dir, "cfg.txt", "password = hunter2secret\n")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	oldStderr := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"check", "diff"})
+	err := rootCmd.Execute()
+
+	w.Close()
+	os.Stderr = oldStderr
+
+	if err == nil {
+		t.Fatal("expected violation")
+	}
+	buf := make([]byte, 1024)
+	n, _ := r.Read(buf)
+	stderr := string(buf[:n])
+	for _, out := range []string{stderr, err.Error()} {
+		if strings.Contains(out, "hunter2secret") {
+			t.Errorf("output leaked sensitive pattern: %q", out)
+		}
+		if !strings.Contains(out, "hu*********et") {
+			t.Errorf("output should contain redacted pattern, got: %q", out)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// refUpdate is one ref change as a server-side hook receives it.
+type refUpdate struct {
+	Old, New, Ref string
+}
+
+func buildServerHookCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "server-hook",
+		Short: "Enforce push policy in a bare repository (pre-receive, update)",
+		Long: `Run the pre-push policy on the server side of a push, where client hooks
+can't be skipped with --no-verify.
+
+Install in a bare repository as hooks/pre-receive (checks every ref of the
+push at once and rejects all of it on a violation) or hooks/update (checks
+and rejects refs one at a time). Policy comes from snag.toml files in and
+above the repository directory and from SNAG_CONFIG_DIRS; snag.toml inside
+the pushed commits is never consulted, so a push can't loosen its own policy.`,
+		Example: `  printf '#!/bin/sh\nexec snag server-hook pre-receive\n' > /srv/git/app.git/hooks/pre-receive
+  chmod +x /srv/git/app.git/hooks/pre-receive`,
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:          "pre-receive",
+		Short:        "Check the \"<old> <new> <ref>\" updates on stdin",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServerHook(cmd, parseRefUpdates(cmd.InOrStdin()))
+		},
+	}, &cobra.Command{
+		Use:          "update REF OLD NEW",
+		Short:        "Check a single ref update",
+		Args:         cobra.ExactArgs(3),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServerHook(cmd, []refUpdate{{Ref: args[0], Old: args[1], New: args[2]}})
+		},
+	})
+	return cmd
+}
+
+func parseRefUpdates(r io.Reader) []refUpdate {
+	var out []refUpdate
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		f := strings.Fields(scanner.Text())
+		if len(f) != 3 {
+			continue
+		}
+		out = append(out, refUpdate{Old: f[0], New: f[1], Ref: f[2]})
+	}
+	return out
+}
+
+// serverRanges returns the revision arguments selecting the commits each
+// update introduces. A new ref brings in what no existing ref reaches;
+// deletions bring in nothing.
+func serverRanges(updates []refUpdate) [][]string {
+	var out [][]string
+	for _, u := range updates {
+		switch {
+		case isZeroSHA(u.New):
+		case isZeroSHA(u.Old):
+			out = append(out, []string{u.New, "--not", "--all"})
+		default:
+			out = append(out, []string{u.Old + ".." + u.New})
+		}
+	}
+	return out
+}
+
+func runServerHook(cmd *cobra.Command, updates []refUpdate) error {
+	bc, err := resolveBlockConfig(cmd)
+	if err != nil {
+		return err
+	}
+	bc.ignoreAuthorExemptions()
+	ranges := serverRanges(updates)
+	if len(ranges) == 0 || !bc.hasPushChecks() {
+		return nil
+	}
+	ranges, err = capPushRanges(cmd, bc, ranges)
+	if err != nil {
+		return err
+	}
+	return checkPushCommits(cmd, bc, ranges)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestServerHook(t *testing.T) {
+	work := initGitRepo(t)
+	initialCommit(t, work)
+	git := func(dir string, args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	base := git(work, "rev-parse", "HEAD")
+	commitFile(t, work, "a.txt", "clean\n", "clean change")
+	clean := git(work, "rev-parse", "HEAD")
+	git(work, "checkout", "-q", "-b", "topic")
+	commitFile(t, work, "b.txt", "TODO later\n", "wip")
+	bad := git(work, "rev-parse", "HEAD")
+
+	bare := filepath.Join(t.TempDir(), "app.git")
+	git(work, "clone", "-q", "--bare", work, bare)
+	// The exemption matches the test identity, which any pusher can claim,
+	// so it must not apply server-side.
+	os.WriteFile(filepath.Join(bare, "snag.toml"), []byte("[block]\ndiff = [\"todo\"]\n[exempt]\nauthors = [\"test@test.com\"]\n"), 0644)
+
+	orig, _ := os.Getwd()
+	os.Chdir(bare)
+	defer os.Chdir(orig)
+
+	run := func(stdin string, args ...string) error {
+		root := buildRootCmd()
+		root.SetIn(strings.NewReader(stdin))
+		root.SetArgs(append([]string{"server-hook", "-q"}, args...))
+		return root.Execute()
+	}
+	zero := strings.Repeat("0", 40)
+
+	if err := run(base+" "+clean+" refs/heads/main\n", "pre-receive"); err != nil {
+		t.Errorf("clean update rejected: %v", err)
+	}
+	if err := run(clean+" "+bad+" refs/heads/main\n", "pre-receive"); err == nil || !strings.Contains(err.Error(), "todo") {
+		t.Errorf("pre-receive error = %v, want todo violation", err)
+	}
+	if err := run("", "update", "refs/heads/main", clean, bad); err == nil {
+		t.Error("update hook accepted a violating commit")
+	}
+	if err := run(bad+" "+zero+" refs/heads/topic\n", "pre-receive"); err != nil {
+		t.Errorf("branch deletion rejected: %v", err)
+	}
+	// A new ref is checked only for commits no existing ref reaches: the
+	// bare clone already has topic, so nothing is new.
+	if err := run(zero+" "+bad+" refs/heads/copy\n", "pre-receive"); err != nil {
+		t.Errorf("new ref at an existing commit rejected: %v", err)
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Markers fence the block snag setup manages in shell rc files, so reruns
+// replace it instead of appending a second copy.
+const (
+	setupMarkerBegin = "# >>> snag setup >>>"
+	setupMarkerEnd   = "# <<< snag setup <<<"
+)
+
+const setupLocalTemplate = `# Personal snag policy, applied to every repository through SNAG_CONFIG_DIRS.
+# Patterns here stay on this machine.
+#
+# [block]
+# diff = ["my-employer-internal.example"]
+`
+
+func buildSetupCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "setup",
+		Short: "First-run machine setup: personal config, shell integration, repo roots",
+		Long: `Set up snag on this machine:
+
+  1. create the personal config directory ($XDG_CONFIG_HOME/snag or
+     ~/.config/snag) with a starter snag-local.toml
+  2. with your consent, add a marked block to your shell rc file that adds
+     the directory to SNAG_CONFIG_DIRS, keeping any entries already there,
+     and loads snag shell integration (reruns update it)
+  3. register --root directories for snag repos scan
+
+Safe to run again.`,
+		Example: `  snag setup
+  snag setup --root ~/src --root ~/work
+  snag setup --shell zsh --yes`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runSetup,
+	}
+	cmd.Flags().String("shell", "", "shell to integrate: bash, zsh, or fish (default: from $SHELL)")
+	cmd.Flags().Bool("no-shell", false, "skip shell integration")
+	cmd.Flags().Bool("yes", false, "edit the shell rc file without asking")
+	cmd.Flags().StringArray("root", nil, "directory containing repositories (repeatable)")
+	return cmd
+}
+
+func runSetup(cmd *cobra.Command, args []string) error {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	dir, err := snagConfigHome()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating %s: %w", dir, err)
+	}
+	local := filepath.Join(dir, "snag-local.toml")
+	if _, err := os.Stat(local); os.IsNotExist(err) {
+		if err := os.WriteFile(local, []byte(setupLocalTemplate), 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", local, err)
+		}
+		if !quiet {
+			infof("created %s", local)
+		}
+	}
+
+	if noShell, _ := cmd.Flags().GetBool("no-shell"); !noShell {
+		if err := setupShell(cmd, dir); err != nil {
+			return err
+		}
+	}
+
+	if roots, _ := cmd.Flags().GetStringArray("root"); len(roots) > 0 {
+		added, err := addRepoRoots(roots)
+		if err != nil {
+			return err
+		}
+		if !quiet && len(added) > 0 {
+			infof("registered %s — check them with: snag repos scan", strings.Join(added, ", "))
+		}
+	}
+	return nil
+}
+
+// setupShell writes the managed block into the rc file for the chosen shell.
+func setupShell(cmd *cobra.Command, configDir string) error {
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	yes, _ := cmd.Flags().GetBool("yes")
+	shell, _ := cmd.Flags().GetString("shell")
+	if shell == "" {
+		shell = filepath.Base(os.Getenv("SHELL"))
+	}
+	rc, block, err := shellSetupBlock(shell, configDir)
+	if err != nil {
+		if !quiet {
+			warnf("skipping shell integration: %v", err)
+		}
+		return nil
+	}
+
+	data, err := os.ReadFile(rc)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading %s: %w", rc, err)
+	}
+	updated := replaceManagedBlock(string(data), block)
+	if updated == string(data) {
+		if !quiet {
+			infof("shell integration already in %s", rc)
+		}
+		return nil
+	}
+	if !yes {
+		ok, err := confirmSetup(rc)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if !quiet {
+				hintf("to add it yourself, put this in %s:\n%s", rc, block)
+			}
+			return nil
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(rc), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(rc, []byte(updated), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", rc, err)
+	}
+	if !quiet {
+		infof("updated %s — open a new shell to pick it up", rc)
+	}
+	return nil
+}
+
+// shellSetupBlock returns the rc file and the marked block for shell.
+func shellSetupBlock(shell, configDir string) (rc, block string, err error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", "", err
+	}
+	// SNAG_CONFIG_DIRS may already list directories from a dotfile manager
+	// or an earlier rc line, so configDir is appended only when missing.
+	sep := string(filepath.ListSeparator)
+	var body string
+	switch shell {
+	case "bash", "zsh":
+		rc = filepath.Join(home, ".bashrc")
+		if shell == "zsh" {
+			zdot := os.Getenv("ZDOTDIR")
+			if zdot == "" {
+				zdot = home
+			}
+			rc = filepath.Join(zdot, ".zshrc")
+		}
+		dir := shQuote(configDir)
+		body = fmt.Sprintf("case \"%[1]s${SNAG_CONFIG_DIRS-}%[1]s\" in\n"+
+			"  *%[1]s%[2]s%[1]s*) ;;\n"+
+			"  *) export SNAG_CONFIG_DIRS=\"${SNAG_CONFIG_DIRS:+$SNAG_CONFIG_DIRS%[1]s}\"%[2]s ;;\n"+
+			"esac\neval \"$(snag shell %[3]s)\"", sep, dir, shell)
+	case "fish":
+		rc = filepath.Join(home, ".config", "fish", "conf.d", "snag.fish")
+		dir := fishQuote(configDir)
+		body = fmt.Sprintf("if not contains -- %[2]s (string split -- '%[1]s' \"$SNAG_CONFIG_DIRS\")\n"+
+			"    set -gx SNAG_CONFIG_DIRS (string join -- '%[1]s' $SNAG_CONFIG_DIRS %[2]s)\n"+
+			"end\nsnag shell fish | source", sep, dir)
+	default:
+		return "", "", fmt.Errorf("unsupported shell %q (use --shell bash, zsh, or fish)", shell)
+	}
+	return rc, setupMarkerBegin + "\n" + body + "\n" + setupMarkerEnd + "\n", nil
+}
+
+// shQuote single-quotes s for bash and zsh, so $, backticks, and
+// backslashes in a path stay literal when the rc file is sourced.
+func shQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// fishQuote single-quotes s for fish, where \\ and \' are the only escapes
+// inside single quotes.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
+
+// replaceManagedBlock swaps the marked block in content for block, or
+// appends block when there is none.
+func replaceManagedBlock(content, block string) string {
+	start := strings.Index(content, setupMarkerBegin)
+	if start >= 0 {
+		if end := strings.Index(content[start:], setupMarkerEnd); end >= 0 {
+			end += start + len(setupMarkerEnd)
+			if end < len(content) && content[end] == '\n' {
+				end++
+			}
+			return content[:start] + block + content[end:]
+		}
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if content != "" {
+		content += "\n"
+	}
+	return content + block
+}
+
+// confirmSetup asks before editing an rc file. Non-interactive sessions
+// decline; pass --yes to edit unattended.
+var confirmSetup = func(rc string) (bool, error) {
+	if !isTTY() {
+		warnf("not a terminal — not editing %s (use --yes)", rc)
+		return false, nil
+	}
+	fmt.Fprintf(os.Stderr, "Add snag shell integration to %s? [y/N]: ", rc)
+	scanner := bufio.NewScanner(os.Stdin)
+	if !scanner.Scan() {
+		return false, fmt.Errorf("prompt cancelled")
+	}
+	answer := strings.ToLower(strings.TrimSpace(scanner.Text()))
+	return answer == "y" || answer == "yes", nil
+}
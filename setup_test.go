@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReplaceManagedBlock(t *testing.T) {
+	block := setupMarkerBegin + "\nnew\n" + setupMarkerEnd + "\n"
+	if got := replaceManagedBlock("", block); got != block {
+		t.Errorf("empty file: %q", got)
+	}
+	if got := replaceManagedBlock("alias g=git", block); got != "alias g=git\n\n"+block {
+		t.Errorf("append: %q", got)
+	}
+	old := "a\n" + setupMarkerBegin + "\nold\n" + setupMarkerEnd + "\nb\n"
+	if got := replaceManagedBlock(old, block); got != "a\n"+block+"b\n" {
+		t.Errorf("replace: %q", got)
+	}
+}
+
+func TestShellSetupBlock_Quoting(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := "/home/o'brien/$HOME/`id`/a\\b"
+	for shell, want := range map[string]string{
+		"bash": `export SNAG_CONFIG_DIRS="${SNAG_CONFIG_DIRS:+$SNAG_CONFIG_DIRS:}"'/home/o'\''brien/$HOME/` + "`id`" + `/a\b' ;;`,
+		"fish": `set -gx SNAG_CONFIG_DIRS (string join -- ':' $SNAG_CONFIG_DIRS '/home/o\'brien/$HOME/` + "`id`" + `/a\\b')`,
+	} {
+		_, block, err := shellSetupBlock(shell, dir)
+		if err != nil || !strings.Contains(block, want+"\n") {
+			t.Errorf("%s block = %q, %v; want line %q", shell, block, err, want)
+		}
+	}
+
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		return
+	}
+	_, block, _ := shellSetupBlock("bash", dir)
+	setDirs, _, _ := strings.Cut(block, "eval ")
+	for _, tc := range []struct{ before, want string }{
+		{"", dir},
+		{"/etc/snag", "/etc/snag:" + dir},
+		{"/etc/snag:" + dir, "/etc/snag:" + dir},
+	} {
+		// Sourcing twice must not add the directory again.
+		script := setDirs + setDirs + "printf %s \"$SNAG_CONFIG_DIRS\""
+		c := exec.Command(bash, "-c", script)
+		c.Env = append(os.Environ(), "SNAG_CONFIG_DIRS="+tc.before)
+		out, err := c.Output()
+		if err != nil || string(out) != tc.want {
+			t.Errorf("with SNAG_CONFIG_DIRS=%q, bash read back %q, %v; want %q", tc.before, out, err, tc.want)
+		}
+	}
+}
+
+func TestSetup_Idempotent(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	src := filepath.Join(home, "src")
+	os.MkdirAll(src, 0755)
+	os.WriteFile(filepath.Join(home, ".zshrc"), []byte("alias g=git\n"), 0644)
+
+	run := func() {
+		t.Helper()
+		rootCmd := buildRootCmd()
+		rootCmd.SetArgs([]string{"setup", "--shell", "zsh", "--yes", "--root", src, "-q"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+	}
+	run()
+	run()
+
+	rc, _ := os.ReadFile(filepath.Join(home, ".zshrc"))
+	if strings.Count(string(rc), setupMarkerBegin) != 1 || !strings.HasPrefix(string(rc), "alias g=git\n") {
+		t.Errorf(".zshrc after two runs:\n%s", rc)
+	}
+	if !strings.Contains(string(rc), `eval "$(snag shell zsh)"`) {
+		t.Errorf(".zshrc missing shell hook:\n%s", rc)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".config", "snag", "snag-local.toml")); err != nil {
+		t.Errorf("personal config not created: %v", err)
+	}
+	roots, err := loadRepoRoots()
+	if err != nil || len(roots) != 1 || roots[0] != src {
+		t.Errorf("roots = %v, %v", roots, err)
+	}
+}
+
+func TestSetup_DeclinedLeavesRCUntouched(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	orig := confirmSetup
+	confirmSetup = func(string) (bool, error) { return false, nil }
+	defer func() { confirmSetup = orig }()
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"setup", "--shell", "bash", "-q"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".bashrc")); !os.IsNotExist(err) {
+		t.Error(".bashrc should not be written without consent")
+	}
+}
+
+func TestReposScan(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	root := t.TempDir()
+
+	guarded := filepath.Join(root, "org", "guarded")
+	bare := filepath.Join(root, "org", "plain")
+	for _, d := range []string{guarded, bare} {
+		os.MkdirAll(filepath.Join(d, ".git"), 0755)
+	}
+	os.WriteFile(filepath.Join(guarded, "snag.toml"), []byte("[block]\ndiff = [\"x\"]\n"), 0644)
+
+	if _, err := addRepoRoots([]string{root}); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	rootCmd := buildRootCmd()
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"repos", "scan", "-q"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("repos scan: %v", err)
+	}
+	if got := strings.TrimSpace(out.String()); got != guarded {
+		t.Errorf("repos scan listed %q, want %q", got, guarded)
+	}
+}
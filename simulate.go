@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// simulateListMax caps the newly blocked commits listed without --verbose.
+const simulateListMax = 20
+
+func buildSimulateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "simulate --config FILE [--range RANGE]",
+		Short: "Measure how many existing commits a proposed policy would block",
+		Long: `Audit a range of history twice, once with the current policy and once with
+a proposed snag.toml standing in for the repository's, and report the
+difference: commits newly blocked, commits no longer blocked, and which
+proposed patterns account for the new blocks.
+
+Local overlays (snag-local.toml) and SNAG_CONFIG_DIRS apply to both runs,
+so the comparison isolates the proposed change. Like snag audit, this
+covers diff and msg patterns.`,
+		Example: `  snag simulate --config proposed-snag.toml --range main~200..main
+  git show origin/policy-update:snag.toml > /tmp/next.toml && snag simulate --config /tmp/next.toml --limit 0`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runSimulate,
+	}
+	cmd.Flags().String("config", "", "proposed snag.toml to compare against the current one")
+	cmd.Flags().String("range", "", "commit range to replay (default: audit default)")
+	cmd.Flags().Int("limit", -1, "max commits when no --range is given (default: config or 10, 0 = unlimited)")
+	cmd.MarkFlagRequired("config")
+	return cmd
+}
+
+func runSimulate(cmd *cobra.Command, args []string) error {
+	proposedPath, _ := cmd.Flags().GetString("config")
+	if !fileExists(proposedPath) {
+		return fmt.Errorf("%s: no such file", proposedPath)
+	}
+	current, err := resolveBlockConfig(cmd)
+	if err != nil {
+		return err
+	}
+	proposed, err := proposedBlockConfig(proposedPath)
+	if err != nil {
+		return err
+	}
+
+	var rangeArgs []string
+	if rng, _ := cmd.Flags().GetString("range"); rng != "" {
+		rangeArgs = []string{rng}
+	}
+	limit, _ := cmd.Flags().GetInt("limit")
+	if limit < 0 {
+		limit = defaultAuditLimit(current)
+	}
+	shas, err := auditRevList(rangeArgs, limit)
+	if err != nil {
+		return err
+	}
+	if len(shas) == 0 {
+		return fmt.Errorf("no commits in range")
+	}
+
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	before := scanCommitsProgress(shas, current, quiet)
+	after := scanCommitsProgress(shas, proposed, quiet)
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	writeSimulation(cmd.OutOrStdout(), len(shas), before, after, proposed, verbose)
+	return nil
+}
+
+// proposedBlockConfig resolves config as usual from the working directory,
+// except that proposed stands in for the repository root's snag.toml.
+func proposedBlockConfig(proposed string) (*BlockConfig, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	out, err := gitCommand("rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return nil, fmt.Errorf("snag simulate must run inside a git work tree")
+	}
+	top := strings.TrimSpace(string(out))
+
+	bc := &BlockConfig{}
+	for _, d := range configChain(cwd) {
+		if dirKey(runtime.GOOS, d) != dirKey(runtime.GOOS, top) {
+			if _, err := mergeConfigDir(bc, d); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		files, err := dirConfigFiles(d, proposed)
+		if err != nil {
+			return nil, err
+		}
+		mergeConfigFiles(bc, files)
+	}
+	if _, err := mergeWorktreeConfig(bc, cwd); err != nil {
+		return nil, err
+	}
+	normalizeBlockConfig(bc, cwd)
+	if err := loadSuppressions(bc, cwd); err != nil {
+		return nil, err
+	}
+	return bc, nil
+}
+
+func writeSimulation(w io.Writer, scanned int, before, after []commitReport, proposed *BlockConfig, verbose bool) {
+	wasBlocked := map[string]bool{}
+	for _, r := range before {
+		wasBlocked[r.SHA] = true
+	}
+	isBlocked := map[string]bool{}
+	var newly []commitReport
+	counts := map[string]int{}
+	for _, r := range after {
+		isBlocked[r.SHA] = true
+		if wasBlocked[r.SHA] {
+			continue
+		}
+		newly = append(newly, r)
+		for _, m := range r.Matches {
+			counts[m.Kind+" "+fmt.Sprintf("%q", proposed.display(m.Pattern))]++
+		}
+	}
+	unblocked := 0
+	for sha := range wasBlocked {
+		if !isBlocked[sha] {
+			unblocked++
+		}
+	}
+
+	fmt.Fprintf(w, "%d commits replayed\n\n", scanned)
+	fmt.Fprintf(w, "  blocked now:            %d\n", len(before))
+	fmt.Fprintf(w, "  blocked with proposal:  %d\n", len(after))
+	fmt.Fprintf(w, "  newly blocked:          %d\n", len(newly))
+	fmt.Fprintf(w, "  no longer blocked:      %d\n", unblocked)
+	if len(newly) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, "\nnew blocks by pattern:")
+	for _, c := range sortedCounts(counts) {
+		fmt.Fprintf(w, "  %-30s %d\n", c.Pattern, c.Count)
+	}
+	fmt.Fprintln(w, "\nnewly blocked commits:")
+	for i, r := range newly {
+		if i == simulateListMax && !verbose {
+			fmt.Fprintf(w, "  ... and %d more (--verbose lists all)\n", len(newly)-i)
+			break
+		}
+		var why []string
+		for _, m := range r.Matches {
+			where := "message"
+			if m.Kind == "diff" {
+				where = fmt.Sprintf("%s:%d", m.Path, m.Line)
+			}
+			why = append(why, fmt.Sprintf("%q in %s", proposed.display(m.Pattern), where))
+		}
+		fprintPlain(w, "  %s  %s — %s\n", r.SHA[:7], r.Subject, strings.Join(why, ", "))
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSimulateReportsBlastRadius(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"todo\"]\n"), 0644)
+	initialCommit(t, dir)
+	commitFile(t, dir, "a.go", "// TODO: a\n", "Add a")
+	commitFile(t, dir, "b.go", "// hack: b\n", "Add b")
+	commitFile(t, dir, "c.go", "// hack: c\n", "Add c")
+	os.WriteFile(filepath.Join(dir, "snag-local.toml"), []byte("[block]\nmsg = [\"add c\"]\n"), 0644)
+
+	proposed := filepath.Join(t.TempDir(), "proposed.toml")
+	os.WriteFile(proposed, []byte("[block]\ndiff = [\"hack\"]\n"), 0644)
+
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+	os.Chdir(dir)
+
+	var out bytes.Buffer
+	rootCmd := buildRootCmd()
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"simulate", "--config", proposed, "--range", "HEAD~3..HEAD"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("simulate: %v", err)
+	}
+	got := out.String()
+	// Now: a (todo) and c (local msg). Proposed: b and c (hack), c (msg).
+	for _, want := range []string{
+		"3 commits replayed",
+		"blocked now:            2",
+		"blocked with proposal:  2",
+		"newly blocked:          1",
+		"no longer blocked:      1",
+		"diff \"hack\"",
+		"Add b — \"hack\" in b.go:1",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestSimulateRequiresConfig(t *testing.T) {
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"simulate", "--config", filepath.Join(t.TempDir(), "missing.toml"), "-q"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("missing proposed config should fail")
+	}
+}
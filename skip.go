@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// builtinMaxFileBytes is the per-file diff size cap when skip.max_file_bytes
+// is not configured. Minified bundles and generated blobs blow past this.
+const builtinMaxFileBytes = 1 << 20
+
+// skipRules decides which files in a diff are too large or binary-ish to scan.
+type skipRules struct {
+	Extensions []string      // lowercased suffixes
+	MaxBytes   int           // 0 = unlimited
+	Suppress   []suppression // active .snagignore entries
+	Scan       string        // [scan] mode; "" = added lines
+}
+
+// skippedFile records a file the scanner passed over and why.
+type skippedFile struct {
+	Path    string
+	Reason  string
+	TooLong bool // over max_file_bytes; content was never looked at
+}
+
+// skipRules returns the resolved skip heuristics for diff scanning.
+func (bc *BlockConfig) skipRules() skipRules {
+	max := builtinMaxFileBytes
+	if bc.MaxFileBytes != nil {
+		max = *bc.MaxFileBytes
+	}
+	var active []suppression
+	for _, s := range bc.Suppressions {
+		if s.Active() {
+			active = append(active, s)
+		}
+	}
+	return skipRules{Extensions: bc.SkipExtensions, MaxBytes: max, Suppress: active}
+}
+
+// reason returns why f should be skipped, or "" if it should be scanned.
+func (r skipRules) reason(f diffFile) string {
+	s, _ := r.skip(f)
+	return s.Reason
+}
+
+// skip returns the skippedFile for f, or false if f should be scanned.
+// Checks run cheapest first: extension, size, then a NUL byte scan of the
+// added lines (text diffs of binary-ish files forced through .gitattributes).
+func (r skipRules) skip(f diffFile) (skippedFile, bool) {
+	lower := strings.ToLower(f.Path)
+	for _, ext := range r.Extensions {
+		if ext != "" && strings.HasSuffix(lower, ext) {
+			return skippedFile{Path: f.Path, Reason: fmt.Sprintf("extension %s", ext)}, true
+		}
+	}
+	if r.MaxBytes > 0 && len(f.Body) > r.MaxBytes {
+		return skippedFile{Path: f.Path, Reason: fmt.Sprintf("%d bytes exceeds max_file_bytes %d", len(f.Body), r.MaxBytes), TooLong: true}, true
+	}
+	if strings.IndexByte(f.Body, 0) >= 0 {
+		return skippedFile{Path: f.Path, Reason: "binary content (NUL byte)"}, true
+	}
+	return skippedFile{}, false
+}
+
+// oversizeWarned remembers, per command run, which oversized files were
+// already warned about, so a file touched by many pushed commits warns once.
+var oversizeWarned = map[*cobra.Command]map[string]bool{}
+
+// reportSkipped warns about files skipped for size, since a large dump is
+// exactly where secrets hide, and prints the rest when --verbose is set.
+func reportSkipped(cmd *cobra.Command, skipped []skippedFile) {
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	if quiet {
+		return
+	}
+	for _, s := range skipped {
+		switch {
+		case s.TooLong:
+			if oversizeWarned[cmd] == nil {
+				oversizeWarned[cmd] = map[string]bool{}
+			}
+			if !oversizeWarned[cmd][s.Path] {
+				oversizeWarned[cmd][s.Path] = true
+				warnf("%s not scanned: %s", s.Path, s.Reason)
+			}
+		case verbose:
+			infof("skipped %s: %s", s.Path, s.Reason)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSkipRules_Reason(t *testing.T) {
+	rules := skipRules{Extensions: []string{".min.js", ".svg"}, MaxBytes: 64}
+
+	tests := []struct {
+		name     string
+		file     diffFile
+		wantSkip bool
+	}{
+		{"plain text", diffFile{Path: "main.go", Body: "+hello\n"}, false},
+		{"extension", diffFile{Path: "dist/app.min.js", Body: "+x\n"}, true},
+		{"extension case-insensitive", diffFile{Path: "LOGO.SVG", Body: "+x\n"}, true},
+		{"over size cap", diffFile{Path: "big.txt", Body: strings.Repeat("+a\n", 40)}, true},
+		{"NUL byte", diffFile{Path: "blob.dat", Body: "+ab\x00cd\n"}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := rules.reason(tc.file)
+			if (got != "") != tc.wantSkip {
+				t.Errorf("reason(%q) = %q, wantSkip %v", tc.file.Path, got, tc.wantSkip)
+			}
+		})
+	}
+
+	if why := (skipRules{}).reason(diffFile{Path: "big.txt", Body: strings.Repeat("+a\n", 1000)}); why != "" {
+		t.Errorf("MaxBytes 0 should be unlimited, got %q", why)
+	}
+}
+
+func TestBlockConfigSkipRules_Default(t *testing.T) {
+	bc := &BlockConfig{}
+	if got := bc.skipRules().MaxBytes; got != builtinMaxFileBytes {
+		t.Errorf("default MaxBytes = %d, want %d", got, builtinMaxFileBytes)
+	}
+	zero := 0
+	bc.MaxFileBytes = &zero
+	if got := bc.skipRules().MaxBytes; got != 0 {
+		t.Errorf("explicit 0 MaxBytes = %d, want 0", got)
+	}
+}
+
+func TestRunDiff_SkipsConfiguredExtension(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[block]\ndiff = [\"todo\"]\n\n[skip]\nextensions = [\".min.js\"]\n"), 0644)
+
+	stageFile(t, dir, "app.min.js", "var todo=1;\n")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	oldStderr := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"check", "diff", "--verbose"})
+	err := rootCmd.Execute()
+
+	w.Close()
+	os.Stderr = oldStderr
+
+	if err != nil {
+		t.Fatalf("skipped file should not trigger a violation, got: %v", err)
+	}
+	buf := make([]byte, 1024)
+	n, _ := r.Read(buf)
+	if stderr := string(buf[:n]); !strings.Contains(stderr, "skipped app.min.js") {
+		t.Errorf("verbose output should report skipped file, got: %q", stderr)
+	}
+}
+
+func TestRunDiff_SizeCapStillScansSmallFiles(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[block]\ndiff = [\"todo\"]\n\n[skip]\nmax_file_bytes = 200\n"), 0644)
+
+	stageFile(t, dir, "huge.txt", strings.Repeat("todo filler line\n", 50))
+	stageFile(t, dir, "small.txt", "a todo\n")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"check", "diff", "-q"})
+	err := rootCmd.Execute()
+	if err == nil {
+		t.Fatal("small file under the cap should still be scanned")
+	}
+}
+
+func TestRunDiff_WarnsOnOversizedFile(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[block]\ndiff = [\"todo\"]\n\n[skip]\nmax_file_bytes = 200\nextensions = [\".min.js\"]\n"), 0644)
+
+	stageFile(t, dir, "dump.sql", strings.Repeat("insert filler line\n", 50))
+	stageFile(t, dir, "app.min.js", "var x=1;\n")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	oldStderr := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"check", "diff"})
+	err := rootCmd.Execute()
+
+	w.Close()
+	os.Stderr = oldStderr
+
+	if err != nil {
+		t.Fatalf("check diff: %v", err)
+	}
+	buf := make([]byte, 4096)
+	n, _ := r.Read(buf)
+	stderr := string(buf[:n])
+	if strings.Count(stderr, "dump.sql not scanned") != 1 || !strings.Contains(stderr, "max_file_bytes") {
+		t.Errorf("oversized file should warn once without --verbose, got: %q", stderr)
+	}
+	if strings.Contains(stderr, "app.min.js") {
+		t.Errorf("extension skips stay behind --verbose, got: %q", stderr)
+	}
+}
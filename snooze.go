@@ -0,0 +1,197 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const snoozeStateFile = "snoozed.json"
+
+// maxSnooze caps --for so a snooze can't quietly become a permanent bypass.
+const maxSnooze = 7 * 24 * time.Hour
+
+// snoozeEntry suppresses one pattern until Until. Key is the sha256 of the
+// lowercased pattern; Label is the pattern as violation output would show
+// it, so terms from sensitive configs stay masked on disk. An empty Hook
+// applies to every hook.
+type snoozeEntry struct {
+	Key   string    `json:"key"`
+	Label string    `json:"label"`
+	Hook  string    `json:"hook,omitempty"`
+	Until time.Time `json:"until"`
+}
+
+func snoozeKey(pattern string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(pattern)))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadSnoozes returns the unexpired entries in .git/snag/snoozed.json.
+func loadSnoozes() ([]snoozeEntry, string, error) {
+	dir, err := snagStateDir()
+	if err != nil {
+		return nil, "", err
+	}
+	path := filepath.Join(dir, snoozeStateFile)
+	var all []snoozeEntry
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &all); err != nil {
+			return nil, path, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	live := all[:0]
+	for _, e := range all {
+		if now().Before(e.Until) {
+			live = append(live, e)
+		}
+	}
+	return live, path, nil
+}
+
+// saveSnoozes replaces the snooze file; callers hold its lock.
+func saveSnoozes(path string, entries []snoozeEntry) error {
+	if len(entries) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeStateFile(path, append(data, '\n'))
+}
+
+// dropSnoozed removes patterns snoozed for hook and reports how many were
+// skipped. State errors are ignored: a broken snooze file must not stop
+// the hook from checking.
+func dropSnoozed(cmd *cobra.Command, hook string, patterns []string) []string {
+	if len(patterns) == 0 {
+		return patterns
+	}
+	entries, _, err := loadSnoozes()
+	if err != nil || len(entries) == 0 {
+		return patterns
+	}
+	snoozed := make(map[string]bool)
+	for _, e := range entries {
+		if e.Hook == "" || e.Hook == hook {
+			snoozed[e.Key] = true
+		}
+	}
+	var kept []string
+	for _, p := range patterns {
+		if !snoozed[snoozeKey(p)] {
+			kept = append(kept, p)
+		}
+	}
+	if n := len(patterns) - len(kept); n > 0 {
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		if !quiet && outputFormat(cmd) == formatText {
+			noun := "patterns"
+			if n == 1 {
+				noun = "pattern"
+			}
+			infof("%d snoozed %s", n, noun)
+		}
+	}
+	return kept
+}
+
+func buildSnoozeCmd() *cobra.Command {
+	var (
+		dur   time.Duration
+		hook  string
+		list  bool
+		clear bool
+	)
+	cmd := &cobra.Command{
+		Use:   "snooze [PATTERN]",
+		Short: "Temporarily suppress one configured pattern in this repo",
+		Long: `Suppress one configured pattern for a limited time, so a refactor in
+progress doesn't force bypassing every check with --no-verify.
+
+Snoozes live in .git/snag/snoozed.json, apply only to this repository,
+and expire on their own. Hooks report how many patterns they skipped.`,
+		Example: `  snag snooze "fixme" --for 2h
+  snag snooze "console.log" --for 30m --hook diff
+  snag snooze --list
+  snag snooze --clear`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			unlock, err := lockState(snoozeStateFile)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+			entries, path, err := loadSnoozes()
+			if err != nil {
+				return err
+			}
+			switch {
+			case clear:
+				if len(args) == 1 {
+					key := snoozeKey(args[0])
+					entries = slices.DeleteFunc(entries, func(e snoozeEntry) bool { return e.Key == key })
+				} else {
+					entries = nil
+				}
+				return saveSnoozes(path, entries)
+			case list || len(args) == 0:
+				for _, e := range entries {
+					scope := "all hooks"
+					if e.Hook != "" {
+						scope = e.Hook
+					}
+					fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\tuntil %s\n", e.Label, scope, e.Until.Format("2006-01-02 15:04"))
+				}
+				return nil
+			}
+
+			if dur <= 0 || dur > maxSnooze {
+				return fmt.Errorf("--for must be between 1s and %s", maxSnooze)
+			}
+			if hook != "" && hook != "diff" && hook != "msg" && hook != "push" {
+				return fmt.Errorf("--hook must be diff, msg, or push, got %q", hook)
+			}
+			bc, err := resolveBlockConfig(cmd)
+			if err != nil {
+				return err
+			}
+			pattern := strings.ToLower(args[0])
+			if !slices.Contains(bc.Diff, pattern) && !slices.Contains(bc.Msg, pattern) &&
+				!slices.Contains(bc.PushPatterns(), pattern) {
+				return fmt.Errorf("%q is not a configured pattern", args[0])
+			}
+
+			key := snoozeKey(pattern)
+			entries = slices.DeleteFunc(entries, func(e snoozeEntry) bool { return e.Key == key && e.Hook == hook })
+			until := now().Add(dur)
+			entries = append(entries, snoozeEntry{Key: key, Label: bc.display(pattern), Hook: hook, Until: until})
+			if err := saveSnoozes(path, entries); err != nil {
+				return err
+			}
+			quiet, _ := cmd.Flags().GetBool("quiet")
+			if !quiet {
+				infof("snoozed %q until %s", bc.display(pattern), until.Format("15:04"))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().DurationVar(&dur, "for", time.Hour, "how long the snooze lasts (max 168h)")
+	cmd.Flags().StringVar(&hook, "hook", "", "limit the snooze to one hook: diff, msg, or push")
+	cmd.Flags().BoolVar(&list, "list", false, "list active snoozes")
+	cmd.Flags().BoolVar(&clear, "clear", false, "remove the snooze for PATTERN, or all snoozes")
+	return cmd
+}
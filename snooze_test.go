@@ -0,0 +1,97 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnooze_SkipsPatternUntilExpiry(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"fixme\", \"hack\"]\n"), 0644)
+	stageFile(t, dir, "a.go", "// FIXME later\n")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+	defer func() { now = time.Now }()
+
+	run := func(args ...string) error {
+		rootCmd := buildRootCmd()
+		rootCmd.SetArgs(append(args, "-q"))
+		return rootCmd.Execute()
+	}
+
+	if err := run("check", "diff"); err == nil {
+		t.Fatal("expected fixme to block before snoozing")
+	}
+	if err := run("snooze", "FIXME", "--for", "2h"); err != nil {
+		t.Fatalf("snooze: %v", err)
+	}
+	if err := run("check", "diff"); err != nil {
+		t.Errorf("snoozed pattern still blocked: %v", err)
+	}
+	if err := run("snooze", "--list"); err != nil {
+		t.Errorf("list: %v", err)
+	}
+
+	now = func() time.Time { return time.Now().Add(3 * time.Hour) }
+	if err := run("check", "diff"); err == nil {
+		t.Error("expired snooze should no longer suppress")
+	}
+
+	now = time.Now
+	if err := run("snooze", "--clear"); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git", "snag", snoozeStateFile)); !os.IsNotExist(err) {
+		t.Error("--clear should remove the snooze file")
+	}
+}
+
+func TestSnooze_ScopedToHook(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"fixme\"]\nmsg = [\"fixme\"]\n"), 0644)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"snooze", "fixme", "--hook", "diff", "-q"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("snooze: %v", err)
+	}
+
+	msgFile := filepath.Join(dir, "COMMIT_MSG")
+	os.WriteFile(msgFile, []byte("tidy up the fixme\n"), 0644)
+	rootCmd = buildRootCmd()
+	rootCmd.SetArgs([]string{"check", "msg", msgFile, "-q"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("diff-scoped snooze should not apply to msg")
+	}
+}
+
+func TestSnooze_RejectsUnknownPattern(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"fixme\"]\n"), 0644)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	for _, args := range [][]string{
+		{"snooze", "fixmee"},
+		{"snooze", "fixme", "--for", "720h"},
+		{"snooze", "fixme", "--hook", "checkout"},
+	} {
+		rootCmd := buildRootCmd()
+		rootCmd.SetArgs(append(args, "-q"))
+		if err := rootCmd.Execute(); err == nil {
+			t.Errorf("%v: expected error", args)
+		}
+	}
+}
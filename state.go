@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// State files are written by hooks that can run at the same time (an IDE
+// committing while a terminal pushes), so every update to .git/snag goes
+// through a lock and an atomic replace. Locks follow git's convention: NAME
+// is guarded by NAME.lock, created exclusively, and holders only keep it for
+// a read-modify-write, so a lock older than stateLockStale was left by a
+// killed process and is broken (see breakStaleLock).
+const (
+	stateLockWait  = 2 * time.Second
+	stateLockStale = 10 * time.Second
+	stateLockPoll  = 10 * time.Millisecond
+)
+
+// snagStateDir returns .git/snag in the current repository (shared by all
+// worktrees), creating it if needed. Local, uncommitted state such as
+// rollout first-seen dates lives here.
+func snagStateDir() (string, error) {
+	out, err := gitCommand("rev-parse", "--git-common-dir").Output()
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse --git-common-dir: %w", err)
+	}
+	dir := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(dir) {
+		if dir, err = filepath.Abs(dir); err != nil {
+			return "", err
+		}
+	}
+	dir = filepath.Join(dir, "snag")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("creating %s: %w", dir, err)
+	}
+	return dir, nil
+}
+
+// lockStateFile takes the advisory lock guarding path, waiting up to
+// stateLockWait for another snag process to finish with it.
+func lockStateFile(path string) (unlock func(), err error) {
+	lock := path + ".lock"
+	deadline := time.Now().Add(stateLockWait)
+	for {
+		f, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return func() { os.Remove(lock) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if info, serr := os.Stat(lock); serr == nil && time.Since(info.ModTime()) > stateLockStale && breakStaleLock(lock) {
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%s is locked by another snag process; if none is running, delete %s", filepath.Base(path), lock)
+		}
+		time.Sleep(stateLockPoll)
+	}
+}
+
+// breakStaleLock removes lock if it is older than stateLockStale and
+// reports whether it did. Processes that all saw the same stale lock race to
+// break it, and a slow one could delete the fresh lock a faster one just
+// took, so breaking is serialized by lock.break, created exclusively, and
+// the age is checked again while holding it. A breaker killed in between
+// leaves a stale lock.break, which is cleared the same way.
+func breakStaleLock(lock string) bool {
+	breaker := lock + ".break"
+	f, err := os.OpenFile(breaker, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		if info, serr := os.Stat(breaker); serr == nil && time.Since(info.ModTime()) > stateLockStale {
+			os.Remove(breaker)
+		}
+		return false
+	}
+	f.Close()
+	defer os.Remove(breaker)
+	info, err := os.Stat(lock)
+	if err != nil || time.Since(info.ModTime()) <= stateLockStale {
+		return false
+	}
+	return os.Remove(lock) == nil
+}
+
+// lockState takes the lock for .git/snag/NAME in the current repository.
+func lockState(name string) (unlock func(), err error) {
+	dir, err := snagStateDir()
+	if err != nil {
+		return nil, err
+	}
+	return lockStateFile(filepath.Join(dir, name))
+}
+
+// updateStateFile rewrites dir/NAME under its lock: fn gets the current
+// contents (nil if the file doesn't exist) and returns the new ones.
+func updateStateFile(dir, name string, fn func(old []byte) []byte) error {
+	path := filepath.Join(dir, name)
+	unlock, err := lockStateFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	old, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return writeStateFile(path, fn(old))
+}
+
+// appendStateLine appends line and a newline to dir/NAME under its lock.
+func appendStateLine(dir, name string, line []byte) error {
+	path := filepath.Join(dir, name)
+	unlock, err := lockStateFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeStateFile replaces path with data atomically. Callers updating a
+// file shared between processes hold its lock.
+func writeStateFile(path string, data []byte) error {
+	return writeFileAtomic(path, func(f *os.File) error {
+		_, err := f.Write(data)
+		return err
+	})
+}
+
+// writeFileAtomic writes data to path through a temporary file in the same
+// directory, so an interrupted run never leaves a half-written report. A
+// symlinked path is written through to its target rather than replaced, and
+// an existing file keeps its permissions; new files get 0644.
+func writeFileAtomic(path string, write func(f *os.File) error) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestUpdateStateFile_Concurrent(t *testing.T) {
+	dir := t.TempDir()
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := updateStateFile(dir, "counter", func(old []byte) []byte {
+				n, _ := strconv.Atoi(string(old))
+				return []byte(strconv.Itoa(n + 1))
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if b, _ := os.ReadFile(filepath.Join(dir, "counter")); string(b) != "20" {
+		t.Errorf("counter = %q, want 20 (lost updates)", b)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "counter.lock")); !os.IsNotExist(err) {
+		t.Error("lock file left behind")
+	}
+}
+
+func TestLockStateFile_BreaksStaleLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	os.WriteFile(path+".lock", []byte("12345\n"), 0644)
+	old := time.Now().Add(-2 * stateLockStale)
+	os.Chtimes(path+".lock", old, old)
+
+	unlock, err := lockStateFile(path)
+	if err != nil {
+		t.Fatalf("stale lock should be broken: %v", err)
+	}
+	unlock()
+}
+
+// A lock that was stale when first seen but has since been replaced by a
+// fresh one belongs to a live process and must be left alone.
+func TestBreakStaleLock_KeepsFreshLock(t *testing.T) {
+	lock := filepath.Join(t.TempDir(), "events.jsonl.lock")
+	os.WriteFile(lock, []byte("12345\n"), 0644)
+	if breakStaleLock(lock) {
+		t.Error("broke a fresh lock")
+	}
+	if _, err := os.Stat(lock); err != nil {
+		t.Errorf("fresh lock removed: %v", err)
+	}
+
+	old := time.Now().Add(-2 * stateLockStale)
+	os.WriteFile(lock+".break", nil, 0644)
+	os.Chtimes(lock+".break", old, old)
+	os.Chtimes(lock, old, old)
+	if breakStaleLock(lock) {
+		t.Error("broke the lock while another breaker held lock.break")
+	}
+	if !breakStaleLock(lock) {
+		t.Error("stale lock.break should be cleared so the next attempt succeeds")
+	}
+	if _, err := os.Stat(lock + ".break"); !os.IsNotExist(err) {
+		t.Error("lock.break left behind")
+	}
+}
+
+// Processes that all find the same stale lock must not each break it and
+// end up holding the lock together.
+func TestLockStateFile_StaleLockRace(t *testing.T) {
+	for range 5 {
+		path := filepath.Join(t.TempDir(), "events.jsonl")
+		os.WriteFile(path+".lock", []byte("12345\n"), 0644)
+		old := time.Now().Add(-2 * stateLockStale)
+		os.Chtimes(path+".lock", old, old)
+
+		var holders, most atomic.Int32
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				unlock, err := lockStateFile(path)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				n := holders.Add(1)
+				for m := most.Load(); n > m && !most.CompareAndSwap(m, n); m = most.Load() {
+				}
+				time.Sleep(time.Millisecond)
+				holders.Add(-1)
+				unlock()
+			}()
+		}
+		wg.Wait()
+		if m := most.Load(); m > 1 {
+			t.Fatalf("%d goroutines held the lock at once", m)
+		}
+	}
+}
+
+func TestAppendStateLine(t *testing.T) {
+	dir := t.TempDir()
+	appendStateLine(dir, "log.jsonl", []byte(`{"a":1}`))
+	appendStateLine(dir, "log.jsonl", []byte(`{"a":2}`))
+	if b, _ := os.ReadFile(filepath.Join(dir, "log.jsonl")); string(b) != "{\"a\":1}\n{\"a\":2}\n" {
+		t.Errorf("got %q", b)
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json")
+	os.WriteFile(path, []byte("old"), 0644)
+
+	err := writeFileAtomic(path, func(f *os.File) error {
+		f.WriteString("partial")
+		return errors.New("boom")
+	})
+	if err == nil {
+		t.Fatal("expected the write error")
+	}
+	if b, _ := os.ReadFile(path); string(b) != "old" {
+		t.Errorf("failed write replaced the file: %q", b)
+	}
+
+	if err := writeFileAtomic(path, func(f *os.File) error {
+		_, err := f.WriteString("new")
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(path); string(b) != "new" {
+		t.Errorf("got %q, want %q", b, "new")
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("temporary files left behind: %v", entries)
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// statsBarWidth is the longest bar snag stats --trend draws.
+const statsBarWidth = 40
+
+func buildStatsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show policy health over time from recorded audits",
+		Long: `Summarize the audits recorded with snag audit --record.
+
+--trend (the default) charts violating commits per recorded run, oldest
+first, and says whether the count is going up or down. Pass it with
+--patterns to print both views.
+
+--patterns counts, per configured rule, the hooks it has blocked (from
+.git/snag/events.jsonl) and the violations recorded audits found. Rules
+that never fired are candidates for pruning; rules that account for most
+firings may be too broad.`,
+		Example: `  snag audit --limit 200 --record   # e.g. nightly
+  snag stats --trend
+  snag stats --trend -n 10
+  snag stats --patterns`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runStats,
+	}
+	cmd.Flags().Bool("trend", false, "chart violations per recorded audit")
+	cmd.Flags().Bool("patterns", false, "show how often each configured rule fires")
+	cmd.Flags().IntP("last", "n", 30, "only the last N recorded audits (0 = all)")
+	return cmd
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	recs, err := loadAuditHistory()
+	if err != nil {
+		return err
+	}
+	trend, _ := cmd.Flags().GetBool("trend")
+	patterns, _ := cmd.Flags().GetBool("patterns")
+	if !patterns {
+		trend = true
+	}
+	if trend {
+		if len(recs) == 0 {
+			return fmt.Errorf("no recorded audits — run: snag audit --record")
+		}
+		shown := recs
+		if n, _ := cmd.Flags().GetInt("last"); n > 0 && len(shown) > n {
+			shown = shown[len(shown)-n:]
+		}
+		fmt.Fprint(cmd.OutOrStdout(), renderTrend(shown))
+	}
+	if patterns {
+		if trend {
+			fmt.Fprintln(cmd.OutOrStdout())
+		}
+		return runPatternStats(cmd, recs)
+	}
+	return nil
+}
+
+// renderTrend draws one bar per record, scaled to the worst run, then a
+// verdict comparing the first and last runs shown.
+func renderTrend(recs []auditRecord) string {
+	peak := 0
+	for _, r := range recs {
+		peak = max(peak, r.Violating)
+	}
+	var b strings.Builder
+	for _, r := range recs {
+		width := 0
+		if peak > 0 {
+			width = (r.Violating*statsBarWidth + peak - 1) / peak
+		}
+		fmt.Fprintf(&b, "%s  %-9s %-*s %d of %d commits violating\n",
+			r.Time.Local().Format("2006-01-02 15:04"), r.Head, statsBarWidth, strings.Repeat("#", width), r.Violating, r.Scanned)
+	}
+	first, last := recs[0], recs[len(recs)-1]
+	switch {
+	case len(recs) == 1:
+		b.WriteString("\none recorded audit; record more to see a trend\n")
+	case last.Violating < first.Violating:
+		fmt.Fprintf(&b, "\nimproving: %d -> %d violating commits over %d audits\n", first.Violating, last.Violating, len(recs))
+	case last.Violating > first.Violating:
+		fmt.Fprintf(&b, "\nworsening: %d -> %d violating commits over %d audits\n", first.Violating, last.Violating, len(recs))
+	default:
+		fmt.Fprintf(&b, "\nflat: %d violating commits over %d audits\n", last.Violating, len(recs))
+	}
+	if first.Policy != last.Policy {
+		b.WriteString("note: the policy changed in this window (snag config history)\n")
+	}
+	return b.String()
+}
+
+// A rule is flagged as noisy once it has fired at least statsNoisyMin
+// times and accounts for at least statsNoisyShare of all firings.
+const (
+	statsNoisyMin   = 10
+	statsNoisyShare = 0.5
+)
+
+// patternStat is how often one configured rule fired.
+type patternStat struct {
+	Pattern string
+	Hooks   int // blocked hook runs
+	Audits  int // violations found by recorded audits
+}
+
+func runPatternStats(cmd *cobra.Command, recs []auditRecord) error {
+	bc, err := resolveBlockConfig(cmd)
+	if err != nil {
+		return err
+	}
+	events, err := loadHookEvents()
+	if err != nil {
+		return err
+	}
+	configured := configuredPatterns(bc)
+	if len(configured) == 0 {
+		return fmt.Errorf("no patterns configured")
+	}
+	fmt.Fprint(cmd.OutOrStdout(), renderPatternStats(patternStats(configured, events, recs), len(events), len(recs)))
+	return nil
+}
+
+// patternStats tallies firings for each configured pattern, most first.
+// Patterns that fired but are no longer configured are left out.
+func patternStats(configured []string, events []hookEvent, recs []auditRecord) []patternStat {
+	stats := make([]patternStat, len(configured))
+	index := map[string]int{}
+	for i, p := range configured {
+		stats[i].Pattern = p
+		index[p] = i
+	}
+	for _, ev := range events {
+		if i, ok := index[ev.Pattern]; ok {
+			stats[i].Hooks++
+		}
+	}
+	for _, r := range recs {
+		for p, n := range r.Patterns {
+			if i, ok := index[p]; ok {
+				stats[i].Audits += n
+			}
+		}
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].Hooks+stats[i].Audits > stats[j].Hooks+stats[j].Audits
+	})
+	return stats
+}
+
+func renderPatternStats(stats []patternStat, events, audits int) string {
+	total := 0
+	for _, s := range stats {
+		total += s.Hooks + s.Audits
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "from %d blocked hook runs and %d recorded audits\n\n", events, audits)
+	fmt.Fprintf(&b, "  %-30s %6s %7s\n", "PATTERN", "HOOKS", "AUDITS")
+	var never, noisy []string
+	for _, s := range stats {
+		fired := s.Hooks + s.Audits
+		note := ""
+		switch {
+		case fired == 0:
+			note = "  never fired"
+			never = append(never, s.Pattern)
+		case fired >= statsNoisyMin && float64(fired) >= statsNoisyShare*float64(total):
+			note = "  fires constantly"
+			noisy = append(noisy, s.Pattern)
+		}
+		fmt.Fprintf(&b, "  %-30s %6d %7d%s\n", strconv.Quote(s.Pattern), s.Hooks, s.Audits, note)
+	}
+	if len(never) > 0 {
+		fmt.Fprintf(&b, "\n%d rule(s) never fired: consider removing them, or keep them if they guard something rare\n", len(never))
+	}
+	if len(noisy) > 0 {
+		fmt.Fprintf(&b, "%d rule(s) account for most firings: check them for false positives or a too-broad pattern\n", len(noisy))
+	}
+	return b.String()
+}
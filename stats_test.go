@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditRecordAndTrend(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag-local.toml"), []byte("[block]\nsensitive = true\ndiff = [\"projectx\"]\n"), 0644)
+	initialCommit(t, dir)
+	commitFile(t, dir, "a.go", "// projectx\n", "Add a")
+
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+	os.Chdir(dir)
+
+	audit := func() {
+		rootCmd := buildRootCmd()
+		rootCmd.SetArgs([]string{"audit", "HEAD~1..HEAD", "--record", "-q"})
+		rootCmd.Execute()
+	}
+	audit()
+	commitFile(t, dir, "a.go", "// clean\n", "Clean a")
+	audit() // HEAD~1..HEAD is now the clean commit
+
+	recs, err := loadAuditHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 2 || recs[0].Violating != 1 || recs[1].Violating != 0 {
+		t.Fatalf("records = %+v", recs)
+	}
+	if recs[0].Patterns["pr****tx"] != 1 {
+		t.Errorf("patterns = %v, want the redacted pattern", recs[0].Patterns)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, ".git", "snag", historyFile))
+	if strings.Contains(string(data), "projectx") {
+		t.Error("sensitive pattern written to history")
+	}
+
+	var out bytes.Buffer
+	rootCmd := buildRootCmd()
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"stats", "--trend"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "improving: 1 -> 0") {
+		t.Errorf("trend output:\n%s", out.String())
+	}
+
+	out.Reset()
+	rootCmd = buildRootCmd()
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"stats", "--trend", "--patterns"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "improving: 1 -> 0") || !strings.Contains(out.String(), `"pr****tx"`) {
+		t.Errorf("--trend --patterns should print both views:\n%s", out.String())
+	}
+}
+
+func TestRenderTrend(t *testing.T) {
+	now := time.Now()
+	got := renderTrend([]auditRecord{
+		{Time: now, Head: "aaa", Scanned: 10, Violating: 2, Policy: "p1"},
+		{Time: now, Head: "bbb", Scanned: 10, Violating: 4, Policy: "p2"},
+	})
+	lines := strings.Split(got, "\n")
+	if strings.Count(lines[0], "#") != statsBarWidth/2 || strings.Count(lines[1], "#") != statsBarWidth {
+		t.Errorf("bars not scaled to the peak:\n%s", got)
+	}
+	for _, want := range []string{"worsening: 2 -> 4", "policy changed"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestStatsWithoutHistory(t *testing.T) {
+	dir := initGitRepo(t)
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+	os.Chdir(dir)
+
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"stats", "-q"})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "--record") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestStatsPatterns(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"todo\", \"never\"]\nmsg = [\"wip\"]\n"), 0644)
+	initialCommit(t, dir)
+
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+	os.Chdir(dir)
+
+	state, err := snagStateDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 12; i++ {
+		recordHookEvent(state, "diff", errors.New(`policy violation: "todo" found in staged diff`))
+	}
+	recordHookEvent(state, "msg", errors.New(`policy violation: "wip" found in commit message`))
+	recordHookEvent(state, "msg", errors.New(`policy violation: "other" found in commit message`))
+	recordHookEvent(state, "push", errors.New(`git push failed: "todo"`))
+
+	events, err := loadHookEvents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 13 {
+		t.Fatalf("got %d events, want 13 (unconfigured and non-violation errors skipped)", len(events))
+	}
+
+	var out bytes.Buffer
+	rootCmd := buildRootCmd()
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"stats", "--patterns"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	for _, want := range []string{
+		`"todo"                             12       0  fires constantly`,
+		`"wip"                               1       0`,
+		`"never"                             0       0  never fired`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q:\n%s", want, got)
+		}
+	}
+}
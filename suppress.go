@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// suppressFile is the checked-in list of reviewed exceptions, read from the
+// repository root:
+//
+//	# Parser fixtures quote the marker on purpose.
+//	"do not merge"  testdata/**  2026-12-31
+//
+// Each entry names a pattern, a path glob, and optionally the last day it
+// applies. The comment lines directly above an entry (or a trailing
+// "# ...") are its justification, which shows up in review and in
+// snag suppressions list.
+const suppressFile = ".snagignore"
+
+// suppression silences one pattern in matching paths.
+type suppression struct {
+	Pattern string    // lowercased; may be a sha256: pattern
+	Path    string    // glob relative to the repo root; "dir/**" matches a subtree
+	Until   time.Time // zero = no expiry; otherwise the first instant it no longer applies
+	Why     string
+	Line    int
+}
+
+// Active reports whether the suppression still applies.
+func (s suppression) Active() bool {
+	return s.Until.IsZero() || now().Before(s.Until)
+}
+
+// Location is "file:line" for messages.
+func (s suppression) Location() string {
+	return fmt.Sprintf("%s:%d", suppressFile, s.Line)
+}
+
+// covers reports whether s silences configured pattern p in file.
+func (s suppression) covers(p, file string) bool {
+	if p != s.Pattern && !(isHashPattern(p) && !isHashPattern(s.Pattern) && hashToken(s.Pattern) == p) {
+		return false
+	}
+	if dir, ok := strings.CutSuffix(s.Path, "/**"); ok {
+		return file == dir || strings.HasPrefix(file, dir+"/")
+	}
+	return matchPathGlob([]string{s.Path}, file)
+}
+
+// loadSuppressions reads .snagignore at the root of the repository
+// containing dir into bc. A missing file is fine; a malformed one is a
+// config error, since silently dropping an entry would block commits the
+// team agreed to allow.
+func loadSuppressions(bc *BlockConfig, dir string) error {
+	root := repoRoot(dir)
+	bc.SuppressRoot = root
+	f, err := os.Open(filepath.Join(root, suppressFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	entries, err := parseSuppressions(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filepath.Join(root, suppressFile), err)
+	}
+	bc.Suppressions = entries
+	return nil
+}
+
+func parseSuppressions(r io.Reader) ([]suppression, error) {
+	var entries []suppression
+	var why []string
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			why = nil
+			continue
+		}
+		if c, ok := strings.CutPrefix(line, "#"); ok {
+			why = append(why, strings.TrimSpace(c))
+			continue
+		}
+		s, err := parseSuppressionLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		s.Line = n
+		if s.Why == "" {
+			s.Why = strings.Join(why, " ")
+		}
+		why = nil
+		entries = append(entries, s)
+	}
+	return entries, sc.Err()
+}
+
+// parseSuppressionLine parses `PATTERN PATH [YYYY-MM-DD] [# why]`. A
+// pattern containing spaces is written as a double-quoted Go string.
+func parseSuppressionLine(line string) (suppression, error) {
+	var s suppression
+	rest := line
+	if strings.HasPrefix(line, `"`) {
+		q, err := strconv.QuotedPrefix(line)
+		if err != nil {
+			return s, fmt.Errorf("unterminated quoted pattern")
+		}
+		s.Pattern, _ = strconv.Unquote(q)
+		rest = line[len(q):]
+	} else {
+		i := strings.IndexAny(line, " \t")
+		if i < 0 {
+			i = len(line)
+		}
+		s.Pattern, rest = line[:i], line[i:]
+	}
+	if i := strings.Index(rest, "#"); i >= 0 {
+		s.Why = strings.TrimSpace(rest[i+1:])
+		rest = rest[:i]
+	}
+	fields := strings.Fields(rest)
+	if s.Pattern == "" || len(fields) == 0 || len(fields) > 2 {
+		return s, fmt.Errorf("want PATTERN PATH [YYYY-MM-DD], got %q", line)
+	}
+	s.Pattern = lowerBytesafe(s.Pattern)
+	s.Path = strings.TrimPrefix(path.Clean(fields[0]), "./")
+	if _, err := path.Match(s.Path, ""); err != nil {
+		return s, fmt.Errorf("bad path glob %q: %w", fields[0], err)
+	}
+	if len(fields) == 2 {
+		day, err := time.ParseInLocation(time.DateOnly, fields[1], time.Local)
+		if err != nil {
+			return s, fmt.Errorf("bad expiry %q (want YYYY-MM-DD)", fields[1])
+		}
+		s.Until = day.AddDate(0, 0, 1)
+	}
+	return s, nil
+}
+
+// unsuppressed returns the patterns still in force for file (a repo-relative
+// slash path), and the active suppressions that removed any.
+func (r skipRules) unsuppressed(file string, patterns []string) (kept []string, used []suppression) {
+	if len(r.Suppress) == 0 {
+		return patterns, nil
+	}
+	for _, p := range patterns {
+		silenced := false
+		for _, s := range r.Suppress {
+			if s.covers(p, file) {
+				used = append(used, s)
+				silenced = true
+				break
+			}
+		}
+		if !silenced {
+			kept = append(kept, p)
+		}
+	}
+	return kept, used
+}
+
+// repoPath converts an absolute path to the repo-relative form suppression
+// globs are written against.
+func (bc *BlockConfig) repoPath(abs string) string {
+	if bc.SuppressRoot == "" {
+		return filepath.ToSlash(abs)
+	}
+	rel, err := filepath.Rel(bc.SuppressRoot, abs)
+	if err != nil {
+		return filepath.ToSlash(abs)
+	}
+	return filepath.ToSlash(rel)
+}
+
+func buildSuppressionsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "suppressions",
+		Short: "Inspect reviewed exceptions in .snagignore",
+	}
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List .snagignore entries and flag expired ones",
+		Long: `List every entry in the repository's .snagignore with its path glob,
+expiry, and justification. Expired entries no longer suppress anything and
+are flagged so they can be renewed or deleted; entries without a
+justification comment are flagged too.
+
+With --check, exit non-zero when any entry is expired or unjustified, for CI.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runSuppressionsList,
+	}
+	list.Flags().Bool("check", false, "exit non-zero if any entry is expired or has no justification")
+	cmd.AddCommand(list)
+	return cmd
+}
+
+func runSuppressionsList(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	bc, err := resolveBlockConfigAt(cmd, cwd)
+	if err != nil {
+		return err
+	}
+	if len(bc.Suppressions) == 0 {
+		infof("no suppressions (%s not found or empty)", suppressFile)
+		return nil
+	}
+
+	expired, unjustified, attention := 0, 0, 0
+	tw := tabwriter.NewWriter(cmd.OutOrStdout(), 2, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "LOCATION\tPATTERN\tPATH\tEXPIRES\tSTATUS\tJUSTIFICATION")
+	for _, s := range bc.Suppressions {
+		expires, status, why := "never", "active", s.Why
+		if !s.Until.IsZero() {
+			expires = s.Until.AddDate(0, 0, -1).Format(time.DateOnly)
+		}
+		if why == "" {
+			why = "(none)"
+			status = "UNJUSTIFIED"
+			unjustified++
+		}
+		if !s.Active() {
+			status = "EXPIRED"
+			expired++
+		}
+		if status != "active" {
+			attention++
+		}
+		fmt.Fprintf(tw, "%s\t%q\t%s\t%s\t%s\t%s\n", s.Location(), bc.display(s.Pattern), s.Path, expires, status, why)
+	}
+	tw.Flush()
+
+	if expired > 0 {
+		hintf("expired entries no longer suppress anything; renew the date or delete the line")
+	}
+	if unjustified > 0 {
+		hintf("add a comment line above each entry saying why it's allowed")
+	}
+	if check, _ := cmd.Flags().GetBool("check"); check && attention > 0 {
+		return fmt.Errorf("%d of %d suppressions need attention", attention, len(bc.Suppressions))
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSuppressions(t *testing.T) {
+	src := `# Parser fixtures quote the marker on purpose.
+# Reviewed in #412.
+"Do Not Merge"  testdata/**  2026-12-31
+
+todo	docs/*.md  # prose about TODO lists
+sha256:abc  vendor/**
+`
+	got, err := parseSuppressions(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d entries, want 3: %+v", len(got), got)
+	}
+	if s := got[0]; s.Pattern != "do not merge" || s.Path != "testdata/**" || s.Line != 3 ||
+		s.Why != "Parser fixtures quote the marker on purpose. Reviewed in #412." ||
+		!s.Until.Equal(time.Date(2027, 1, 1, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("entry 0 = %+v", s)
+	}
+	if s := got[1]; s.Pattern != "todo" || s.Path != "docs/*.md" || s.Why != "prose about TODO lists" || !s.Until.IsZero() {
+		t.Errorf("entry 1 = %+v", s)
+	}
+	if s := got[2]; s.Why != "" {
+		t.Errorf("blank line should end a justification, got %q", s.Why)
+	}
+
+	for _, bad := range []string{"todo", `"todo docs/**`, "todo docs/** tomorrow", "todo a b c"} {
+		if _, err := parseSuppressions(strings.NewReader(bad)); err == nil {
+			t.Errorf("parseSuppressions(%q) should fail", bad)
+		}
+	}
+}
+
+func TestSuppressionCovers(t *testing.T) {
+	s := suppression{Pattern: "todo", Path: "testdata/**"}
+	for file, want := range map[string]bool{
+		"testdata/a.txt":     true,
+		"testdata/sub/b.txt": true,
+		"testdataz/a.txt":    false,
+		"src/testdata.go":    false,
+	} {
+		if got := s.covers("todo", file); got != want {
+			t.Errorf("covers(todo, %s) = %v, want %v", file, got, want)
+		}
+	}
+	if s.covers("fixme", "testdata/a.txt") {
+		t.Error("suppression should only cover its own pattern")
+	}
+	if !(suppression{Pattern: "secret", Path: "*.md"}).covers(hashToken("secret"), "docs/a.md") {
+		t.Error("plain-text entry should cover the hashed form of the pattern")
+	}
+}
+
+func TestRunDiff_Snagignore(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"todo\"]\n"), 0644)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	run := func() error {
+		rootCmd := buildRootCmd()
+		rootCmd.SetArgs([]string{"check", "diff", "-q"})
+		return rootCmd.Execute()
+	}
+
+	os.MkdirAll(filepath.Join(dir, "testdata"), 0755)
+	stageFile(t, dir, "testdata/fixture.txt", "TODO: fixture\n")
+	os.WriteFile(filepath.Join(dir, suppressFile), []byte("# fixtures\ntodo testdata/**\n"), 0644)
+	if err := run(); err != nil {
+		t.Fatalf("suppressed path should pass, got %v", err)
+	}
+
+	stageFile(t, dir, "main.go", "// TODO\n")
+	if err := run(); err == nil {
+		t.Fatal("unsuppressed path should still block")
+	}
+
+	stageFile(t, dir, "main.go", "package main\n")
+	os.WriteFile(filepath.Join(dir, suppressFile), []byte("todo testdata/** 2020-01-01\n"), 0644)
+	if err := run(); err == nil {
+		t.Fatalf("expired suppression should not apply, got %v", err)
+	}
+}
+
+func TestSuppressionsList(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, suppressFile), []byte(
+		"# fixtures\ntodo testdata/**\n\nfixme docs/** 2020-01-01\n"), 0644)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	var out bytes.Buffer
+	rootCmd := buildRootCmd()
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"suppressions", "list", "--check"})
+	err := rootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "1 of 2") {
+		t.Errorf("--check err = %v, want 1 of 2 needing attention", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("output:\n%s", out.String())
+	}
+	if !strings.Contains(lines[1], ".snagignore:2") || !strings.Contains(lines[1], "active") || !strings.Contains(lines[1], "fixtures") {
+		t.Errorf("active row = %q", lines[1])
+	}
+	if !strings.Contains(lines[2], "2020-01-01") || !strings.Contains(lines[2], "EXPIRED") {
+		t.Errorf("expired row = %q", lines[2])
+	}
+}
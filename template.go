@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// initTemplate is a named set of files snag init --template writes into a
+// new repository, keyed by slash-separated path.
+type initTemplate struct {
+	Name    string
+	Summary string
+	Files   func() map[string]string
+}
+
+var defaultSnagignore = `# Reviewed exceptions: PATTERN PATH-GLOB [LAST-DAY]
+# The comment lines above an entry are its justification.
+`
+
+var strictInitConfig = defaultInitConfig + `
+[push]
+forbid_fixup_commits = true
+block_protected_mismatch = true
+
+[branch]
+block_commit = true
+
+[format]
+trailing_whitespace = true
+final_newline = true
+`
+
+// builtinTemplates ship with snag, so init --template works offline and
+// without a templates repository.
+var builtinTemplates = []initTemplate{
+	{"default", "starter snag.toml, lefthook.yml with the snag recipe, empty .snagignore", func() map[string]string {
+		return templateFiles(defaultInitConfig)
+	}},
+	{"strict", "default plus commit-shape, protected-branch, and whitespace rules", func() map[string]string {
+		return templateFiles(strictInitConfig)
+	}},
+}
+
+func templateFiles(config string) map[string]string {
+	return map[string]string{
+		"snag.toml": config,
+		"lefthook.yml": snagRemoteBlockTrimmed(versionRef()) +
+			strings.Replace(missingHookStubs(""), "snag install", "snag init", 1),
+		".snagignore": defaultSnagignore,
+	}
+}
+
+func findBuiltinTemplate(name string) (initTemplate, bool) {
+	for _, t := range builtinTemplates {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return initTemplate{}, false
+}
+
+// templateSource returns the templates repository init --template reads:
+// --from, else SNAG_TEMPLATES, else "" for the built-in set only.
+func templateSource(from string) string {
+	if from != "" {
+		return from
+	}
+	return os.Getenv("SNAG_TEMPLATES")
+}
+
+// openTemplateSource makes source available as a local directory. A
+// directory is used in place; anything else is shallow-cloned with git,
+// which needs the network. cleanup removes the clone.
+func openTemplateSource(source string) (dir string, cleanup func(), err error) {
+	if info, err := os.Stat(source); err == nil && info.IsDir() {
+		return source, func() {}, nil
+	}
+	if ok, why := networkAllowed(initNetworkConfig()); !ok {
+		return "", nil, fmt.Errorf("templates repository %s needs the network, which is disabled by %s", source, why)
+	}
+	tmp, err := os.MkdirTemp("", "snag-templates-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup = func() { os.RemoveAll(tmp) }
+	if out, err := gitCommand("clone", "--quiet", "--depth", "1", "--", source, tmp).CombinedOutput(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("fetching templates from %s: %w\n%s", source, err, strings.TrimSpace(string(out)))
+	}
+	return tmp, cleanup, nil
+}
+
+// initNetworkConfig resolves the config around the directory being
+// initialized, so a parent [behavior] network = false applies. A config
+// that doesn't load leaves the network allowed.
+func initNetworkConfig() *BlockConfig {
+	cwd, _ := os.Getwd()
+	bc, _, err := walkConfig(cwd)
+	if err != nil {
+		return nil
+	}
+	return bc
+}
+
+// sourceTemplates lists the templates in a templates repository: its
+// top-level directories, except hidden ones.
+func sourceTemplates(dir string) []string {
+	entries, _ := os.ReadDir(dir)
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+			names = append(names, e.Name())
+		}
+	}
+	return names
+}
+
+// readTemplateDir reads every regular file below dir. Symlinks are refused
+// so a template can't pull in files from outside itself.
+func readTemplateDir(dir string) (map[string]string, error) {
+	files := map[string]string{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, path)
+		if !d.Type().IsRegular() {
+			return fmt.Errorf("template file %s is not a regular file", filepath.ToSlash(rel))
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("template %s is empty", filepath.Base(dir))
+	}
+	return files, nil
+}
+
+// loadTemplate returns the files of the named template. The templates
+// repository, when one is configured, is searched before the built-ins, so
+// an organization can replace "default" with its own.
+func loadTemplate(name, source string) (map[string]string, string, error) {
+	if strings.ContainsAny(name, `/\`) || name == "." || name == ".." || name == "" {
+		return nil, "", fmt.Errorf("invalid template name %q", name)
+	}
+	var available []string
+	if source != "" {
+		dir, cleanup, err := openTemplateSource(source)
+		if err != nil {
+			return nil, "", err
+		}
+		defer cleanup()
+		if info, err := os.Stat(filepath.Join(dir, name)); err == nil && info.IsDir() {
+			files, err := readTemplateDir(filepath.Join(dir, name))
+			return files, source, err
+		}
+		available = sourceTemplates(dir)
+	}
+	if t, ok := findBuiltinTemplate(name); ok {
+		return t.Files(), "built-in", nil
+	}
+	for _, t := range builtinTemplates {
+		available = append(available, t.Name)
+	}
+	slices.Sort(available)
+	return nil, "", fmt.Errorf("no template %q (available: %s)", name, strings.Join(slices.Compact(available), ", "))
+}
+
+// validateTemplate stages files in a scratch directory and checks that its
+// snag.toml loads, includes and all, and that its .snagignore parses, so a
+// broken template writes nothing.
+func validateTemplate(files map[string]string) error {
+	scratch, err := os.MkdirTemp("", "snag-template-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(scratch)
+	for rel, content := range files {
+		path := filepath.Join(scratch, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+	if _, ok := files["snag.toml"]; ok {
+		if _, err := loadSnagTOML(filepath.Join(scratch, "snag.toml")); err != nil {
+			return fmt.Errorf("template snag.toml: %s", strings.ReplaceAll(err.Error(), scratch+string(filepath.Separator), ""))
+		}
+	}
+	if ignore, ok := files[".snagignore"]; ok {
+		if _, err := parseSuppressions(strings.NewReader(ignore)); err != nil {
+			return fmt.Errorf("template .snagignore: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeTemplate writes files below dir. Unless force is set, it refuses
+// before writing anything if one of them already exists.
+func writeTemplate(dir string, files map[string]string, force bool) ([]string, error) {
+	var names []string
+	for rel := range files {
+		names = append(names, rel)
+	}
+	slices.Sort(names)
+	if !force {
+		var existing []string
+		for _, rel := range names {
+			if fileExists(filepath.Join(dir, filepath.FromSlash(rel))) {
+				existing = append(existing, rel)
+			}
+		}
+		if len(existing) > 0 {
+			return nil, fmt.Errorf("would overwrite %s (use --force to overwrite)", strings.Join(existing, ", "))
+		}
+	}
+	for _, rel := range names {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, err
+		}
+		err := writeFileAtomic(path, func(f *os.File) error {
+			_, err := f.WriteString(files[rel])
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("writing %s: %w", rel, err)
+		}
+	}
+	return names, nil
+}
@@ -25,9 +25,17 @@ func buildDemoCmd() *cobra.Command {
 }
 
 func buildTestCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:          fmt.Sprintf("test [%s]", strings.Join(hookNames(), "|")),
-		Short:        "Smoke-test hooks using your real snag.toml config",
+	cmd := &cobra.Command{
+		Use:   fmt.Sprintf("test [%s]", strings.Join(hookNames(), "|")),
+		Short: "Smoke-test hooks using your real snag.toml config",
+		Long: `Smoke-test hooks using your real snag.toml config.
+
+By default each check runs against a violation in a temporary repository.
+With --live, snag instead attempts a violating commit and push in this
+repository, on a throwaway branch in a temporary worktree, so the installed
+git hooks (lefthook or otherwise) must fire for the test to pass. The push
+is a dry run to an empty local repository. The branch and worktree are
+removed afterwards.`,
 		SilenceUsage: true,
 		Args:         cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -35,6 +43,9 @@ func buildTestCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if live, _ := cmd.Flags().GetBool("live"); live {
+				return runLiveChecks(cmd, args, bc)
+			}
 			patterns := deduplicatePatterns(append(append([]string{}, bc.Diff...), bc.Msg...))
 			if len(patterns) == 0 && !bc.HasAnyPatterns() {
 				infof("nothing to test — no patterns found in snag.toml")
@@ -43,6 +54,8 @@ func buildTestCmd() *cobra.Command {
 			return runChecks(cmd, args, patterns)
 		},
 	}
+	cmd.Flags().Bool("live", false, "exercise the installed hooks in this repository instead of a temp repo")
+	return cmd
 }
 
 func runChecks(cmd *cobra.Command, args []string, patterns []string) error {
@@ -153,7 +166,7 @@ func testDiff(cmd *cobra.Command, dir string, patterns []string) bool {
 	if err := os.WriteFile(fpath, []byte(violation), 0644); err != nil {
 		return false
 	}
-	gitAdd := exec.Command("git", "add", "bad.txt")
+	gitAdd := gitCommand("add", "bad.txt")
 	gitAdd.Dir = dir
 	if out, err := gitAdd.CombinedOutput(); err != nil {
 		fmt.Fprintf(os.Stderr, "git add: %s\n", out)
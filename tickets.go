@@ -0,0 +1,379 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// [tickets] verify = true makes snag check msg look up each ticket ID the
+// message references (ENG-123) in Jira or Linear and block when one doesn't
+// exist or is closed, which catches typo'd IDs. Verification is a
+// convenience, never a gate on being online: no token, the network off, a
+// timeout or an API error all let the commit through with a warning.
+//
+// tickets.url comes from whatever repo is checked out, so the user's token
+// is only sent over https to Linear's API or to a host the user lists in
+// SNAG_TICKETS_HOSTS; a cloned repo can't point it elsewhere.
+
+// ticketsSection configures ticket references in commit messages.
+type ticketsSection struct {
+	Provider  string   `toml:"provider"`   // "jira" or "linear"
+	URL       string   `toml:"url"`        // Jira base URL; Linear's GraphQL endpoint by default
+	Pattern   string   `toml:"pattern"`    // ticket ID regexp; default defaultTicketPattern
+	Projects  []string `toml:"projects"`   // only IDs with these prefixes are verified
+	Verify    bool     `toml:"verify"`     // look tickets up when checking the message
+	OnInvalid string   `toml:"on_invalid"` // "block" (default) or "warn"
+}
+
+const (
+	ticketProviderJira   = "jira"
+	ticketProviderLinear = "linear"
+
+	onTicketBlock = "block"
+	onTicketWarn  = "warn"
+)
+
+const (
+	defaultTicketPattern = `\b[A-Z][A-Z0-9]+-[1-9][0-9]*\b`
+	linearGraphQLURL     = "https://api.linear.app/graphql"
+	linearAPIHost        = "api.linear.app"
+
+	ticketCacheFile     = "tickets.json"
+	ticketCacheTTL      = time.Hour
+	ticketLookupTimeout = 2 * time.Second
+)
+
+// Ticket lookup results.
+const (
+	ticketOpen    = "open"
+	ticketClosed  = "closed"
+	ticketMissing = "missing"
+)
+
+func (t ticketsSection) empty() bool {
+	return t.Provider == "" && t.URL == "" && t.Pattern == "" && len(t.Projects) == 0 && !t.Verify && t.OnInvalid == ""
+}
+
+// ticketsOn reports whether [tickets] verification is configured.
+func (bc *BlockConfig) ticketsOn() bool {
+	return bc.TicketVerify && bc.TicketProvider != ""
+}
+
+// ticketPattern returns the compiled ticket ID pattern.
+func (bc *BlockConfig) ticketPattern() *regexp.Regexp {
+	if bc.TicketPattern == "" {
+		return regexp.MustCompile(defaultTicketPattern)
+	}
+	return regexp.MustCompile(bc.TicketPattern)
+}
+
+// ticketEndpoint is the API base for the configured provider.
+func (bc *BlockConfig) ticketEndpoint() string {
+	if bc.TicketURL == "" && bc.TicketProvider == ticketProviderLinear {
+		return linearGraphQLURL
+	}
+	return strings.TrimRight(bc.TicketURL, "/")
+}
+
+// ticketHostTrusted reports whether the user's token may be sent to
+// endpoint: https, and Linear's API host or one listed in the comma-
+// separated SNAG_TICKETS_HOSTS.
+func ticketHostTrusted(endpoint string) (string, bool) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return endpoint, false
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == linearAPIHost {
+		return host, true
+	}
+	for _, h := range strings.Split(os.Getenv("SNAG_TICKETS_HOSTS"), ",") {
+		if strings.EqualFold(strings.TrimSpace(h), host) {
+			return host, true
+		}
+	}
+	return host, false
+}
+
+// ticketClient makes the lookups; tests swap in one that trusts their
+// server.
+var ticketClient = &http.Client{Timeout: ticketLookupTimeout}
+
+// messageTickets returns the distinct ticket IDs in the message's content
+// lines, in order, limited to [tickets] projects when set.
+func messageTickets(bc *BlockConfig, lines []string) []string {
+	re := bc.ticketPattern()
+	var ids []string
+	for _, line := range msgContentLines(lines) {
+		for _, id := range re.FindAllString(line, -1) {
+			project, _, _ := strings.Cut(id, "-")
+			if len(bc.TicketProjects) > 0 && !slices.Contains(bc.TicketProjects, project) {
+				continue
+			}
+			if !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// ticketLookup is one cached answer about a ticket.
+type ticketLookup struct {
+	State     string    `json:"state"`            // ticketOpen, ticketClosed or ticketMissing
+	Status    string    `json:"status,omitempty"` // the provider's status name, e.g. "Done"
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// ticketCache holds lookups per user, keyed by endpoint and ticket ID, so
+// amending or rewording a commit doesn't ask the API again.
+type ticketCache map[string]ticketLookup
+
+func ticketCachePath() string {
+	dir, err := snagConfigHome()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, ticketCacheFile)
+}
+
+func loadTicketCache() ticketCache {
+	cache := ticketCache{}
+	if path := ticketCachePath(); path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			json.Unmarshal(data, &cache)
+		}
+	}
+	return cache
+}
+
+// save writes the cache, dropping expired entries. Failing to save only
+// costs a repeat lookup, so errors are ignored.
+func (c ticketCache) save() {
+	path := ticketCachePath()
+	if path == "" {
+		return
+	}
+	for k, v := range c {
+		if now().Sub(v.CheckedAt) >= ticketCacheTTL {
+			delete(c, k)
+		}
+	}
+	if data, err := json.Marshal(c); err == nil && os.MkdirAll(filepath.Dir(path), 0755) == nil {
+		writeStateFile(path, data)
+	}
+}
+
+// lookupTicket asks the provider about id.
+func lookupTicket(bc *BlockConfig, token, id string) (ticketLookup, error) {
+	var req *http.Request
+	var err error
+	switch bc.TicketProvider {
+	case ticketProviderJira:
+		req, err = http.NewRequest("GET", bc.ticketEndpoint()+"/rest/api/2/issue/"+url.PathEscape(id)+"?fields=status", nil)
+		if err != nil {
+			return ticketLookup{}, err
+		}
+		// Jira Cloud takes email + API token; Server and Data Center a bearer PAT.
+		if user := os.Getenv("SNAG_JIRA_USER"); user != "" {
+			req.SetBasicAuth(user, token)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	case ticketProviderLinear:
+		body, _ := json.Marshal(map[string]any{
+			"query":     `query($id: String!) { issue(id: $id) { state { name type } } }`,
+			"variables": map[string]string{"id": id},
+		})
+		req, err = http.NewRequest("POST", bc.ticketEndpoint(), bytes.NewReader(body))
+		if err != nil {
+			return ticketLookup{}, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", token)
+	default:
+		return ticketLookup{}, fmt.Errorf("unknown ticket provider %q", bc.TicketProvider)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := ticketClient.Do(req)
+	if err != nil {
+		return ticketLookup{}, err
+	}
+	defer resp.Body.Close()
+	found := ticketLookup{CheckedAt: now()}
+
+	if bc.TicketProvider == ticketProviderJira {
+		switch resp.StatusCode {
+		case http.StatusOK:
+		case http.StatusNotFound:
+			found.State = ticketMissing
+			return found, nil
+		default:
+			return ticketLookup{}, fmt.Errorf("%s: %s", req.URL.Host, resp.Status)
+		}
+		var issue struct {
+			Fields struct {
+				Status struct {
+					Name           string `json:"name"`
+					StatusCategory struct {
+						Key string `json:"key"`
+					} `json:"statusCategory"`
+				} `json:"status"`
+			} `json:"fields"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&issue); err != nil {
+			return ticketLookup{}, fmt.Errorf("%s: %w", req.URL.Host, err)
+		}
+		found.State, found.Status = ticketOpen, issue.Fields.Status.Name
+		if issue.Fields.Status.StatusCategory.Key == "done" {
+			found.State = ticketClosed
+		}
+		return found, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return ticketLookup{}, fmt.Errorf("%s: %s", req.URL.Host, resp.Status)
+	}
+	var result struct {
+		Data struct {
+			Issue *struct {
+				State struct {
+					Name string `json:"name"`
+					Type string `json:"type"`
+				} `json:"state"`
+			} `json:"issue"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return ticketLookup{}, fmt.Errorf("%s: %w", req.URL.Host, err)
+	}
+	switch {
+	case result.Data.Issue != nil:
+		found.State, found.Status = ticketOpen, result.Data.Issue.State.Name
+		if t := result.Data.Issue.State.Type; t == "completed" || t == "canceled" {
+			found.State = ticketClosed
+		}
+	case len(result.Errors) > 0 && strings.Contains(strings.ToLower(result.Errors[0].Message), "not found"):
+		found.State = ticketMissing
+	case len(result.Errors) > 0:
+		return ticketLookup{}, fmt.Errorf("%s: %s", req.URL.Host, result.Errors[0].Message)
+	default:
+		found.State = ticketMissing
+	}
+	return found, nil
+}
+
+// checkMsgTickets verifies the tickets the commit message references. Any
+// failure to ask is a warning; only a definite "missing" or "closed"
+// answer is a violation.
+func checkMsgTickets(cmd *cobra.Command, bc *BlockConfig, path string, lines []string) error {
+	if !bc.ticketsOn() {
+		return nil
+	}
+	ids := messageTickets(bc, lines)
+	if len(ids) == 0 {
+		return nil
+	}
+	quiet, _ := cmd.Flags().GetBool("quiet")
+	skip := func(format string, a ...any) error {
+		if !quiet {
+			warnf("[tickets] not verified: "+format, a...)
+		}
+		return nil
+	}
+	if ok, why := networkAllowed(bc); !ok {
+		return skip("network is off (%s)", why)
+	}
+	if host, ok := ticketHostTrusted(bc.ticketEndpoint()); !ok {
+		return skip("%s is not a trusted https ticket host; add it to SNAG_TICKETS_HOSTS to send it your token", host)
+	}
+	token := secretOrWarn(bc.TicketProvider)
+	if token == "" {
+		svc, _ := findSecretService(bc.TicketProvider)
+		return skip("no token (set %s or run snag auth login %s)", svc.Env, bc.TicketProvider)
+	}
+
+	cache := loadTicketCache()
+	defer cache.save()
+	for _, id := range ids {
+		key := bc.ticketEndpoint() + " " + id
+		found, ok := cache[key]
+		if !ok || now().Sub(found.CheckedAt) >= ticketCacheTTL {
+			var err error
+			if found, err = lookupTicket(bc, token, id); err != nil {
+				return skip("%v", err)
+			}
+			cache[key] = found
+		}
+		if found.State == ticketOpen {
+			continue
+		}
+
+		what := "does not exist"
+		if found.State == ticketClosed {
+			what = fmt.Sprintf("is closed (%s)", found.Status)
+		}
+		if bc.OnTicketInvalid == onTicketWarn {
+			if !quiet {
+				warnf("ticket %s %s [%s]", id, what, idTicket)
+			}
+			continue
+		}
+		if !quiet {
+			if problemOutput(cmd) {
+				line, col := locateInText(strings.Join(lines, "\n"), strings.ToLower(id))
+				problemf(idTicket, path, line, col, "ticket %s %s", id, what)
+			} else {
+				blockf(idTicket, "ticket %s %s", id, what)
+				bell()
+				hintf("to recover: git commit -eF .git/COMMIT_EDITMSG")
+			}
+		}
+		return violationf(idTicket, "ticket %s %s", id, what)
+	}
+	return nil
+}
+
+// validateTicketsSection checks a file's [tickets] settings.
+func validateTicketsSection(t ticketsSection) error {
+	switch t.Provider {
+	case "", ticketProviderJira, ticketProviderLinear:
+	default:
+		return fmt.Errorf("tickets.provider must be %q or %q, got %q", ticketProviderJira, ticketProviderLinear, t.Provider)
+	}
+	if t.Verify && t.Provider == "" {
+		return fmt.Errorf("tickets.verify needs tickets.provider")
+	}
+	if t.Verify && t.Provider == ticketProviderJira && t.URL == "" {
+		return fmt.Errorf("tickets.verify with jira needs tickets.url, e.g. \"https://acme.atlassian.net\"")
+	}
+	if u, err := url.Parse(t.URL); t.URL != "" && (err != nil || u.Scheme != "https" || u.Host == "") {
+		return fmt.Errorf("tickets.url must be an https URL, got %q", t.URL)
+	}
+	if t.Pattern != "" {
+		if _, err := regexp.Compile(t.Pattern); err != nil {
+			return fmt.Errorf("tickets.pattern: %w", err)
+		}
+	}
+	switch t.OnInvalid {
+	case "", onTicketBlock, onTicketWarn:
+	default:
+		return fmt.Errorf("tickets.on_invalid must be %q or %q, got %q", onTicketBlock, onTicketWarn, t.OnInvalid)
+	}
+	return nil
+}
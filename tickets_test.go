@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMessageTickets(t *testing.T) {
+	bc := &BlockConfig{}
+	lines := []string{"ENG-12: fix login", "", "Also OPS-3 and ENG-12 again, not utf-8 or ENG-0.", "# ABC-1 in a comment"}
+	if got := messageTickets(bc, lines); !slices.Equal(got, []string{"ENG-12", "OPS-3"}) {
+		t.Errorf("got %v", got)
+	}
+	bc.TicketProjects = []string{"OPS"}
+	if got := messageTickets(bc, lines); !slices.Equal(got, []string{"OPS-3"}) {
+		t.Errorf("projects: got %v", got)
+	}
+}
+
+// fakeJira serves /rest/api/2/issue/KEY from issues (key → status
+// category), counting requests.
+func fakeJira(t *testing.T, issues map[string]string, calls *int) *httptest.Server {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		cat, ok := issues[strings.TrimPrefix(r.URL.Path, "/rest/api/2/issue/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"fields": map[string]any{"status": map[string]any{
+			"name": map[string]string{"done": "Done", "indeterminate": "In Progress"}[cat], "statusCategory": map[string]string{"key": cat},
+		}}})
+	}))
+	t.Cleanup(srv.Close)
+	trustTicketServer(t, srv)
+	return srv
+}
+
+// trustTicketServer points ticket lookups at srv's certificate and lists
+// its host in SNAG_TICKETS_HOSTS.
+func trustTicketServer(t *testing.T, srv *httptest.Server) {
+	old := ticketClient
+	ticketClient = srv.Client()
+	t.Cleanup(func() { ticketClient = old })
+	u, _ := url.Parse(srv.URL)
+	t.Setenv("SNAG_TICKETS_HOSTS", "tickets.example.com, "+u.Hostname())
+}
+
+func TestCheckMsgTickets(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("SNAG_OFFLINE", "")
+	t.Setenv("SNAG_JIRA_TOKEN", "tok")
+	t.Setenv("SNAG_JIRA_USER", "")
+	calls := 0
+	srv := fakeJira(t, map[string]string{"ENG-1": "indeterminate", "ENG-2": "done"}, &calls)
+	bc := &BlockConfig{TicketProvider: "jira", TicketURL: srv.URL, TicketVerify: true}
+	cmd := buildRootCmd()
+	cmd.PersistentFlags().Set("quiet", "true")
+
+	check := func(msg string) error {
+		return checkMsgTickets(cmd, bc, "COMMIT_EDITMSG", strings.Split(msg, "\n"))
+	}
+	if err := check("ENG-1: fix login"); err != nil {
+		t.Errorf("open ticket: %v", err)
+	}
+	if err := check("ENG-2: fix login"); err == nil || !strings.Contains(err.Error(), "is closed (Done)") {
+		t.Errorf("closed ticket: %v", err)
+	}
+	if err := check("ENG-99: fix login"); err == nil || !strings.Contains(err.Error(), "does not exist") || checkIDOf(err) != idTicket {
+		t.Errorf("missing ticket: %v", err)
+	}
+	before := calls
+	if err := check("ENG-1 again"); err != nil || calls != before {
+		t.Errorf("cached lookup: err = %v, %d new calls", err, calls-before)
+	}
+
+	bc.OnTicketInvalid = onTicketWarn
+	if err := check("ENG-99: fix login"); err != nil {
+		t.Errorf("on_invalid = warn: %v", err)
+	}
+	bc.OnTicketInvalid = ""
+
+	// Fail open: offline, no token, API errors.
+	t.Setenv("SNAG_OFFLINE", "1")
+	if err := check("ENG-98: x"); err != nil {
+		t.Errorf("offline: %v", err)
+	}
+	t.Setenv("SNAG_OFFLINE", "")
+	t.Setenv("SNAG_JIRA_TOKEN", "wrong")
+	if err := check("ENG-97: x"); err != nil {
+		t.Errorf("API error: %v", err)
+	}
+	t.Setenv("SNAG_JIRA_TOKEN", "")
+	t.Setenv("SNAG_SECRETS_BACKEND", "command")
+	t.Setenv("SNAG_SECRETS_COMMAND", "true")
+	if err := check("ENG-96: x"); err != nil {
+		t.Errorf("no token: %v", err)
+	}
+}
+
+func TestLookupTicketLinear(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Variables map[string]string `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		switch req.Variables["id"] {
+		case "LIN-1":
+			w.Write([]byte(`{"data":{"issue":{"state":{"name":"Todo","type":"unstarted"}}}}`))
+		case "LIN-2":
+			w.Write([]byte(`{"data":{"issue":{"state":{"name":"Canceled","type":"canceled"}}}}`))
+		default:
+			w.Write([]byte(`{"data":null,"errors":[{"message":"Entity not found: Issue"}]}`))
+		}
+	}))
+	defer srv.Close()
+	trustTicketServer(t, srv)
+	bc := &BlockConfig{TicketProvider: "linear", TicketURL: srv.URL}
+	for id, want := range map[string]string{"LIN-1": ticketOpen, "LIN-2": ticketClosed, "LIN-3": ticketMissing} {
+		if got, err := lookupTicket(bc, "key", id); err != nil || got.State != want {
+			t.Errorf("%s: got %+v, %v; want %s", id, got, err, want)
+		}
+	}
+}
+
+// A repo's tickets.url must not collect the user's token.
+func TestCheckMsgTickets_UntrustedHost(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("SNAG_OFFLINE", "")
+	t.Setenv("SNAG_JIRA_TOKEN", "tok")
+	var auth []string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = append(auth, r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+	trustTicketServer(t, srv)
+	t.Setenv("SNAG_TICKETS_HOSTS", "jira.example.com")
+
+	bc := &BlockConfig{TicketProvider: "jira", TicketURL: srv.URL, TicketVerify: true}
+	cmd := buildRootCmd()
+	cmd.PersistentFlags().Set("quiet", "true")
+	if err := checkMsgTickets(cmd, bc, "COMMIT_EDITMSG", []string{"ENG-1: x"}); err != nil {
+		t.Errorf("untrusted host should fail open, got %v", err)
+	}
+	if len(auth) != 0 {
+		t.Errorf("untrusted host got requests with Authorization %q", auth)
+	}
+	for endpoint, want := range map[string]bool{
+		"https://api.linear.app/graphql":   true,
+		"https://JIRA.example.com":         true,
+		"http://jira.example.com":          false,
+		"https://jira.example.com.evil.io": false,
+	} {
+		if _, got := ticketHostTrusted(endpoint); got != want {
+			t.Errorf("ticketHostTrusted(%q) = %v, want %v", endpoint, got, want)
+		}
+	}
+}
+
+func TestTicketsConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "snag.toml")
+	for content, want := range map[string]string{
+		"[tickets]\nprovider = \"jira\"\nverify = true\n":                     "needs tickets.url",
+		"[tickets]\nprovider = \"trello\"\n":                                  "tickets.provider",
+		"[tickets]\nverify = true\n":                                          "needs tickets.provider",
+		"[tickets]\nprovider = \"linear\"\npattern = \"([\"\n":                "tickets.pattern",
+		"[tickets]\nprovider = \"linear\"\non_invalid = \"x\"\n":              "on_invalid",
+		"[tickets]\nprovider = \"linear\"\nverify = true\n":                   "",
+		"[tickets]\nprovider = \"jira\"\nurl = \"http://jira.example.com\"\n": "https URL",
+	} {
+		os.WriteFile(path, []byte(content), 0644)
+		_, err := loadSnagTOML(path)
+		if want == "" && err != nil || want != "" && (err == nil || !strings.Contains(err.Error(), want)) {
+			t.Errorf("%q: err = %v, want %q", content, err, want)
+		}
+	}
+}
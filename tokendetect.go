@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// tokenFormat is one recognizable credential format for the "token"
+// detector. Formats ship built in and arrive in the "tokens" rule pack
+// (see rulepacks.go), so new providers don't need a snag release.
+type tokenFormat struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+	re      *regexp.Regexp
+}
+
+// builtinTokenFormats are prefix-anchored formats whose shape alone is
+// near-certain evidence of a live credential.
+var builtinTokenFormats = []tokenFormat{
+	{Name: "aws-access-key", Pattern: `\b(AKIA|ASIA)[0-9A-Z]{16}\b`},
+	{Name: "github-token", Pattern: `\bgh[pousr]_[A-Za-z0-9]{36}\b`},
+	{Name: "github-fine-grained-pat", Pattern: `\bgithub_pat_[A-Za-z0-9_]{82}\b`},
+	{Name: "gitlab-pat", Pattern: `\bglpat-[A-Za-z0-9_-]{20}\b`},
+	{Name: "slack-token", Pattern: `\bxox[abposr]-[A-Za-z0-9-]{10,}`},
+	{Name: "stripe-secret-key", Pattern: `\b[rs]k_live_[A-Za-z0-9]{24,}\b`},
+	{Name: "google-api-key", Pattern: `\bAIza[A-Za-z0-9_-]{35}\b`},
+	{Name: "private-key", Pattern: `-----BEGIN ([A-Z]+ )?PRIVATE KEY-----`},
+}
+
+// compileTokenFormats fills in each format's regexp; it fails on the first bad one.
+func compileTokenFormats(formats []tokenFormat) error {
+	for i := range formats {
+		re, err := regexp.Compile(formats[i].Pattern)
+		if err != nil {
+			return fmt.Errorf("token format %s: %w", formats[i].Name, err)
+		}
+		formats[i].re = re
+	}
+	return nil
+}
+
+func init() {
+	if err := compileTokenFormats(builtinTokenFormats); err != nil {
+		panic(err)
+	}
+}
+
+// activeTokenFormats is the built-in set overlaid with the installed
+// "tokens" pack: a pack entry replaces the built-in one of the same name.
+func activeTokenFormats() []tokenFormat {
+	pack, ok := installedRulePack("tokens")
+	if !ok {
+		return builtinTokenFormats
+	}
+	out := append([]tokenFormat{}, pack.Tokens...)
+	for _, f := range builtinTokenFormats {
+		if !slices.ContainsFunc(out, func(g tokenFormat) bool { return g.Name == f.Name }) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// checkSecretToken is the "token" detector. The match is reported redacted
+// with the format's name, so the violation doesn't leak the credential
+// into terminal scrollback or CI logs.
+func checkSecretToken(_, line string) (string, int, bool) {
+	for _, f := range activeTokenFormats() {
+		if loc := f.re.FindStringIndex(line); loc != nil {
+			return fmt.Sprintf("%s (%s)", redact(line[loc[0]:loc[1]]), f.Name), loc[0] + 1, true
+		}
+	}
+	return "", 0, false
+}
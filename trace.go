@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SNAG_TRACE=1 writes one trace file per invocation in Chrome's
+// trace-event format, which Perfetto (ui.perfetto.dev) and chrome://tracing
+// open directly. snag's own spans (config walk, pattern matching,
+// detectors) come from traceSpan. Git commands aren't wrapped one by one:
+// git's trace2 event stream (GIT_TRACE2_EVENT) is pointed at a scratch
+// file that every child git appends to, and finishTrace turns its
+// start/exit pairs into spans, so no call site needs to know about tracing.
+
+// Trace lanes: snag's spans and the git commands it runs.
+const (
+	traceTidSnag = 1
+	traceTidGit  = 2
+)
+
+type traceEvent struct {
+	Name string         `json:"name"`
+	Cat  string         `json:"cat,omitempty"`
+	Ph   string         `json:"ph"`
+	Ts   int64          `json:"ts"` // microseconds since the trace started
+	Dur  int64          `json:"dur,omitempty"`
+	Pid  int            `json:"pid"`
+	Tid  int            `json:"tid"`
+	Args map[string]any `json:"args,omitempty"`
+}
+
+type tracer struct {
+	mu     sync.Mutex
+	start  time.Time
+	name   string
+	path   string // trace file to write
+	git2   string // trace2 scratch file, "" when git isn't traced
+	events []traceEvent
+}
+
+// activeTrace is nil unless SNAG_TRACE is set, so traceSpan costs nothing
+// in normal runs.
+var activeTrace *tracer
+
+// tracePath returns where this invocation's trace goes: os.TempDir() for
+// SNAG_TRACE=1 or true, otherwise the directory SNAG_TRACE names.
+func tracePath(setting string, start time.Time) string {
+	dir := setting
+	switch strings.ToLower(setting) {
+	case "1", "true", "yes", "on":
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, fmt.Sprintf("snag-trace-%s-%d.json", start.Format("20060102-150405"), os.Getpid()))
+}
+
+// startTrace begins tracing when SNAG_TRACE is set. args name the root span.
+func startTrace(args []string) {
+	setting := os.Getenv("SNAG_TRACE")
+	switch strings.ToLower(setting) {
+	case "", "0", "false", "no", "off":
+		return
+	}
+	t := &tracer{start: now(), name: strings.TrimSpace("snag " + strings.Join(args, " "))}
+	t.path = tracePath(setting, t.start)
+	// A trace2 target the user set is theirs; leave it alone and skip git spans.
+	if os.Getenv("GIT_TRACE2_EVENT") == "" {
+		if f, err := os.CreateTemp("", "snag-trace2-*.json"); err == nil {
+			f.Close()
+			t.git2 = f.Name()
+			os.Setenv("GIT_TRACE2_EVENT", t.git2)
+		}
+	}
+	activeTrace = t
+}
+
+// traceSpan records a span from now until the returned func is called:
+//
+//	defer traceSpan("config", "config walk")()
+//
+// args are key, value pairs shown in the span's details.
+func traceSpan(cat, name string, args ...any) func() {
+	t := activeTrace
+	if t == nil {
+		return func() {}
+	}
+	begin := now()
+	return func() {
+		ev := traceEvent{Name: name, Cat: cat, Ph: "X", Ts: t.micros(begin), Dur: now().Sub(begin).Microseconds(), Pid: 1, Tid: traceTidSnag}
+		for i := 0; i+1 < len(args); i += 2 {
+			if ev.Args == nil {
+				ev.Args = map[string]any{}
+			}
+			ev.Args[fmt.Sprint(args[i])] = args[i+1]
+		}
+		t.mu.Lock()
+		t.events = append(t.events, ev)
+		t.mu.Unlock()
+	}
+}
+
+func (t *tracer) micros(at time.Time) int64 {
+	return at.Sub(t.start).Microseconds()
+}
+
+// finishTrace writes the trace file and stops tracing. Tracing is a
+// diagnostic, so a failure to write is a warning, never the command's error.
+func finishTrace() {
+	t := activeTrace
+	if t == nil {
+		return
+	}
+	activeTrace = nil
+	end := now()
+	if t.git2 != "" {
+		os.Unsetenv("GIT_TRACE2_EVENT")
+		defer os.Remove(t.git2)
+	}
+
+	events := []traceEvent{
+		{Name: "thread_name", Ph: "M", Pid: 1, Tid: traceTidSnag, Args: map[string]any{"name": "snag"}},
+		{Name: "thread_name", Ph: "M", Pid: 1, Tid: traceTidGit, Args: map[string]any{"name": "git"}},
+		{Name: t.name, Cat: "cli", Ph: "X", Ts: 0, Dur: t.micros(end), Pid: 1, Tid: traceTidSnag, Args: map[string]any{"version": Version}},
+	}
+	t.mu.Lock()
+	events = append(events, t.events...)
+	t.mu.Unlock()
+	if t.git2 != "" {
+		events = append(events, t.gitSpans()...)
+	}
+
+	data, err := json.Marshal(map[string]any{"traceEvents": events, "displayTimeUnit": "ms"})
+	if err == nil {
+		err = writeStateFile(t.path, data)
+	}
+	if err != nil {
+		warnf("SNAG_TRACE: %v", err)
+		return
+	}
+	hintf("trace written to %s (open in https://ui.perfetto.dev)", t.path)
+}
+
+// gitSpans converts the trace2 event stream into one span per git process,
+// from its start event to its exit event. Nested gits (hooks, aliases)
+// carry their parent's sid as a prefix and nest inside it.
+func (t *tracer) gitSpans() []traceEvent {
+	f, err := os.Open(t.git2)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	type trace2Event struct {
+		Event string    `json:"event"`
+		SID   string    `json:"sid"`
+		Time  time.Time `json:"time"`
+		Argv  []string  `json:"argv"`
+		Code  int       `json:"code"`
+	}
+	started := map[string]trace2Event{}
+	var spans []traceEvent
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		var ev trace2Event
+		if json.Unmarshal(sc.Bytes(), &ev) != nil {
+			continue
+		}
+		switch ev.Event {
+		case "start":
+			started[ev.SID] = ev
+		case "exit":
+			st, ok := started[ev.SID]
+			if !ok {
+				continue
+			}
+			delete(started, ev.SID)
+			spans = append(spans, traceEvent{
+				Name: strings.Join(st.Argv, " "), Cat: "git", Ph: "X",
+				Ts: t.micros(st.Time), Dur: ev.Time.Sub(st.Time).Microseconds(), Pid: 1, Tid: traceTidGit,
+				Args: map[string]any{"exit": ev.Code},
+			})
+		}
+	}
+	return spans
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"todo\"]\n"), 0644)
+	stageFile(t, dir, "main.go", "package main\n")
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	out := t.TempDir()
+	t.Setenv("SNAG_TRACE", out)
+	t.Setenv("GIT_TRACE2_EVENT", "")
+	args := []string{"check", "diff", "--quiet"}
+	startTrace(args)
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs(args)
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	finishTrace()
+	if os.Getenv("GIT_TRACE2_EVENT") != "" {
+		t.Error("finishTrace should stop tracing child gits")
+	}
+
+	files, _ := filepath.Glob(filepath.Join(out, "snag-trace-*.json"))
+	if len(files) != 1 {
+		t.Fatalf("trace files: %v", files)
+	}
+	data, _ := os.ReadFile(files[0])
+	var trace struct {
+		TraceEvents []traceEvent `json:"traceEvents"`
+	}
+	if err := json.Unmarshal(data, &trace); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, ev := range trace.TraceEvents {
+		if ev.Ph == "X" {
+			names = append(names, ev.Name)
+		}
+	}
+	for _, want := range []string{"snag check diff --quiet", "config walk", "diff patterns", "git diff --staged"} {
+		if !slices.Contains(names, want) {
+			t.Errorf("no %q span in %v", want, names)
+		}
+	}
+}
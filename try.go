@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// tryPhase is one hook whose pattern list snag try checks a snippet against.
+type tryPhase struct {
+	Hook     string // as in SNAG_IGNORE and snag snooze --hook
+	ID       string
+	Patterns func(bc *BlockConfig) []string
+}
+
+var tryPhases = []tryPhase{
+	{"diff", idDiffPattern, func(bc *BlockConfig) []string { return bc.Diff }},
+	{"msg", idMsgPattern, func(bc *BlockConfig) []string { return bc.Msg }},
+	{"push", idDiffPattern, (*BlockConfig).PushPatterns},
+}
+
+func buildTryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "try",
+		Short: "Paste text and see which rules match it, and why",
+		Long: `Check text against the effective policy for the current directory without
+staging or committing anything. For every pattern or detector that fires,
+snag try shows where it matched, which hooks would catch it, whether it
+blocks or only warns (rollout, snooze, .snagignore), and which config file
+the rule comes from.
+
+In a terminal, snag try prompts for snippets: paste text and end it with an
+empty line. Config is re-read for every snippet, so you can edit snag.toml
+in another window and try again. Commands at the prompt:
+
+  :path FILE   treat snippets as content of FILE (language detectors,
+               .snagignore globs); :path alone clears it
+  :quit        leave (or Ctrl-D)
+
+With stdin redirected, the whole input is checked once.`,
+		Example: `  snag try
+  snag try --path src/app.js
+  git show HEAD:notes.txt | snag try`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runTry,
+	}
+	cmd.Flags().String("path", "", "file the text belongs to, for detectors and .snagignore")
+	return cmd
+}
+
+func runTry(cmd *cobra.Command, args []string) error {
+	file, _ := cmd.Flags().GetString("path")
+	in := cmd.InOrStdin()
+	w := cmd.OutOrStdout()
+
+	if in != os.Stdin || !isTTY() {
+		data, err := io.ReadAll(in)
+		if err != nil {
+			return fmt.Errorf("reading stdin: %w", err)
+		}
+		return trySnippet(cmd, w, string(data), file)
+	}
+	return tryPrompt(cmd, in, w, file)
+}
+
+// tryPrompt reads snippets separated by empty lines until :quit or EOF.
+func tryPrompt(cmd *cobra.Command, in io.Reader, w io.Writer, file string) error {
+	fmt.Fprintln(w, dimStyle.Render("paste text, then an empty line to check it (:path FILE, :quit)"))
+	sc := bufio.NewScanner(in)
+	var pending []string
+	for {
+		if len(pending) == 0 {
+			fmt.Fprint(w, "try> ")
+		} else {
+			fmt.Fprint(w, "...> ")
+		}
+		if !sc.Scan() {
+			break
+		}
+		line := sc.Text()
+		if len(pending) == 0 {
+			switch cmdLine := strings.TrimSpace(line); {
+			case cmdLine == ":quit" || cmdLine == ":q":
+				return nil
+			case cmdLine == ":path" || strings.HasPrefix(cmdLine, ":path "):
+				file = strings.TrimSpace(strings.TrimPrefix(cmdLine, ":path"))
+				if file == "" {
+					fmt.Fprintln(w, dimStyle.Render("path cleared"))
+				} else {
+					fmt.Fprintln(w, dimStyle.Render("checking snippets as "+file))
+				}
+				continue
+			case cmdLine == "":
+				continue
+			}
+		}
+		if line != "" {
+			pending = append(pending, line)
+			continue
+		}
+		if err := trySnippet(cmd, w, strings.Join(pending, "\n"), file); err != nil {
+			errorf("%v", err)
+		}
+		pending = nil
+	}
+	fmt.Fprintln(w)
+	if len(pending) > 0 {
+		return trySnippet(cmd, w, strings.Join(pending, "\n"), file)
+	}
+	return sc.Err()
+}
+
+// trySnippet resolves config afresh and reports every rule matching text.
+func trySnippet(cmd *cobra.Command, w io.Writer, text, file string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	bc, err := resolveBlockConfigAt(cmd, cwd)
+	if err != nil {
+		return err
+	}
+	rel := ""
+	if file != "" {
+		abs, _ := filepath.Abs(file)
+		rel = bc.repoPath(abs)
+	}
+	lower := lowerBytesafe(text)
+
+	snoozed := map[string][]string{} // pattern key → hooks ("" = all)
+	if entries, _, err := loadSnoozes(); err == nil {
+		for _, e := range entries {
+			snoozed[e.Key] = append(snoozed[e.Key], e.Hook)
+		}
+	}
+
+	type hit struct {
+		pattern string
+		phases  []tryPhase
+	}
+	var hits []*hit
+	byPattern := map[string]*hit{}
+	for _, ph := range tryPhases {
+		for _, p := range ph.Patterns(bc) {
+			if _, ok := matchesPattern(lower, []string{p}); !ok {
+				continue
+			}
+			h := byPattern[p]
+			if h == nil {
+				h = &hit{pattern: p}
+				byPattern[p] = h
+				hits = append(hits, h)
+			}
+			h.phases = append(h.phases, ph)
+		}
+	}
+
+	blocking := 0
+	for _, h := range hits {
+		line, col := locateInText(text, h.pattern)
+		fmt.Fprintf(w, "\nmatch %s at %d:%d\n", patternStyle.Render(fmt.Sprintf("%q", bc.display(h.pattern))), line, col)
+
+		var hooks, sources []string
+		seen := map[string]bool{}
+		for _, ph := range h.phases {
+			hooks = append(hooks, fmt.Sprintf("%s [%s]", ph.Hook, ph.ID))
+			for _, o := range patternOrigins(cmd, bc, ph.Hook, h.pattern) {
+				if !seen[o] && !strings.HasPrefix(o, "to skip it") {
+					seen[o] = true
+					sources = append(sources, o)
+				}
+			}
+		}
+		severity := trySeverity(bc, h.pattern, h.phases, snoozed[snoozeKey(h.pattern)], rel)
+		if severity == "block" {
+			blocking++
+		}
+		fmt.Fprintf(w, "  hooks:    %s\n", strings.Join(hooks, ", "))
+		fmt.Fprintf(w, "  severity: %s\n", severity)
+		for i, s := range sources {
+			label := "source:  "
+			if i > 0 {
+				label = "         "
+			}
+			fmt.Fprintf(w, "  %s %s\n", label, s)
+		}
+	}
+
+	detected := 0
+	for _, d := range bc.enabledDetectors() {
+		if !d.checksLines() || (rel != "" && bc.detectExcluded(&d, rel)) {
+			continue
+		}
+		for i, l := range strings.Split(text, "\n") {
+			if match, col, ok := d.checkLine(bc, rel, l); ok {
+				fmt.Fprintf(w, "\n%s %s at %d:%d\n", d.Summary, patternStyle.Render(fmt.Sprintf("%q", match)), i+1, col)
+				fmt.Fprintf(w, "  hooks:    diff [%s], push [%s]\n", d.ID, d.ID)
+				fmt.Fprintf(w, "  severity: block\n")
+				fmt.Fprintf(w, "  source:   [detect.%s] (snag explain %s)\n", d.Name, d.ID)
+				detected++
+				blocking++
+				break
+			}
+		}
+	}
+
+	if bc.nearMissOn() && len(hits) == 0 {
+		var all []string
+		for _, ph := range tryPhases {
+			all = append(all, ph.Patterns(bc)...)
+		}
+		for _, m := range textNearMisses(text, deduplicatePatterns(all)) {
+			fmt.Fprintf(w, "\nnear miss %s at %d:1, one edit from %q\n", patternStyle.Render(fmt.Sprintf("%q", m.Token)), m.Line, bc.display(m.Pattern))
+			fmt.Fprintf(w, "  severity: warning [%s]\n", idNearMiss)
+			detected++
+		}
+	}
+
+	if len(hits)+detected == 0 {
+		fmt.Fprintln(w, dimStyle.Render("no rules match"))
+		if rel == "" && len(bc.enabledDetectors()) > 0 {
+			fmt.Fprintln(w, dimStyle.Render("(some detectors only check certain file types; set one with --path or :path)"))
+		}
+		return nil
+	}
+	fmt.Fprintf(w, "\n%s\n", dimStyle.Render(fmt.Sprintf("%d rule(s) match, %d would block", len(hits)+detected, blocking)))
+	return nil
+}
+
+// trySeverity says what a match on pattern would do right now.
+func trySeverity(bc *BlockConfig, pattern string, phases []tryPhase, snoozedHooks []string, file string) string {
+	for _, hook := range snoozedHooks {
+		if hook == "" {
+			return "snoozed for all hooks (snag snooze --list)"
+		}
+	}
+	if len(snoozedHooks) > 0 {
+		return fmt.Sprintf("snoozed for %s (snag snooze --list)", strings.Join(snoozedHooks, ", "))
+	}
+	if file != "" {
+		if _, used := bc.skipRules().unsuppressed(file, []string{pattern}); len(used) > 0 {
+			s := fmt.Sprintf("suppressed in %s by %s", file, used[0].Location())
+			for _, ph := range phases {
+				if ph.Hook == "msg" {
+					s += " (commit messages still block)"
+					break
+				}
+			}
+			return s
+		}
+	}
+	if rule, ok := bc.Rollout[pattern]; ok && !bc.strict[pattern] {
+		switch {
+		case rule.Forever:
+			return "warn-only ([rollout] mode = \"warn\")"
+		case rule.Days > 0:
+			return fmt.Sprintf("warn-only for %d days after snag first sees it, then block", rule.Days)
+		case now().Before(rule.Until):
+			return fmt.Sprintf("warn-only until %s, then block", rule.Until.Format("2006-01-02"))
+		}
+	}
+	return "block"
+}
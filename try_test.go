@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTry_ReportsMatchesAndSources(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte(`[block]
+diff = ["todo"]
+msg = ["wip"]
+`), 0644)
+	os.MkdirAll(filepath.Join(dir, "team"), 0755)
+	os.WriteFile(filepath.Join(dir, "team", "snag.toml"), []byte(`[block]
+diff = ["hack"]
+[rollout]
+mode = "warn"
+`), 0644)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(filepath.Join(dir, "team"))
+	defer os.Chdir(oldDir)
+
+	var out bytes.Buffer
+	rootCmd := buildRootCmd()
+	rootCmd.SetIn(strings.NewReader("WIP hack\n<<<<<<< HEAD\n"))
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"try"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	for _, want := range []string{
+		`match "wip" at 1:1`,
+		"msg [SNAG002], push [SNAG001]",
+		`"wip" from [block] msg in ` + filepath.Join(dir, "snag.toml"),
+		`match "hack" at 1:5`,
+		`warn-only ([rollout] mode = "warn")`,
+		`conflict marker "<<<<<<<" at 2:1`,
+		"3 rule(s) match, 2 would block",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, `"todo"`) {
+		t.Errorf("unmatched pattern reported:\n%s", got)
+	}
+}
+
+func TestTryPrompt(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\ndiff = [\"todo\"]\n"), 0644)
+	os.WriteFile(filepath.Join(dir, suppressFile), []byte("# fixtures\ntodo testdata/**\n"), 0644)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	var out bytes.Buffer
+	in := "clean\n\n:path testdata/a.txt\nTODO\n\n:quit\nTODO\n\n"
+	if err := tryPrompt(buildTryCmd(), strings.NewReader(in), &out, ""); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	if !strings.Contains(got, "no rules match") {
+		t.Errorf("first snippet should match nothing:\n%s", got)
+	}
+	if !strings.Contains(got, "suppressed in testdata/a.txt by .snagignore:2") {
+		t.Errorf(":path should apply .snagignore:\n%s", got)
+	}
+	if strings.Count(got, `match "todo"`) != 1 {
+		t.Errorf(":quit should stop reading:\n%s", got)
+	}
+}
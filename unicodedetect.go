@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// bidiControls are the explicit directional formatting characters used in
+// "trojan source" attacks (CVE-2021-42574) to make code render differently
+// from how it compiles. LRM/RLM marks are left alone; they occur in
+// ordinary RTL text.
+var bidiControls = map[rune]string{
+	'\u202A': "LEFT-TO-RIGHT EMBEDDING",
+	'\u202B': "RIGHT-TO-LEFT EMBEDDING",
+	'\u202C': "POP DIRECTIONAL FORMATTING",
+	'\u202D': "LEFT-TO-RIGHT OVERRIDE",
+	'\u202E': "RIGHT-TO-LEFT OVERRIDE",
+	'\u2066': "LEFT-TO-RIGHT ISOLATE",
+	'\u2067': "RIGHT-TO-LEFT ISOLATE",
+	'\u2068': "FIRST STRONG ISOLATE",
+	'\u2069': "POP DIRECTIONAL ISOLATE",
+}
+
+// invisibleChars render as nothing and can hide inside identifiers.
+var invisibleChars = map[rune]string{
+	'\u200B': "ZERO WIDTH SPACE",
+	'\u2060': "WORD JOINER",
+	'\u00AD': "SOFT HYPHEN",
+}
+
+// confusableScripts are scripts whose letters mimic Latin ones. A single
+// word mixing Latin with one of these is almost always a homoglyph; words
+// written wholly in the script are ordinary text.
+var confusableScripts = []struct {
+	Name  string
+	Table *unicode.RangeTable
+}{
+	{"Cyrillic", unicode.Cyrillic},
+	{"Greek", unicode.Greek},
+}
+
+// checkSuspiciousUnicode is the "unicode" detector: bidi controls,
+// invisible characters, and words mixing Latin with look-alike scripts.
+func checkSuspiciousUnicode(_, line string) (string, int, bool) {
+	if !hasNonASCII(line) {
+		return "", 0, false
+	}
+	for i, r := range line {
+		if name, ok := bidiControls[r]; ok {
+			return fmt.Sprintf("U+%04X %s", r, name), i + 1, true
+		}
+		if name, ok := invisibleChars[r]; ok {
+			return fmt.Sprintf("U+%04X %s", r, name), i + 1, true
+		}
+		if r == '\uFEFF' && i > 0 {
+			return "U+FEFF ZERO WIDTH NO-BREAK SPACE", i + 1, true
+		}
+	}
+	return mixedScriptWord(line)
+}
+
+// mixedScriptWord finds the first word (run of letters, digits and _) that
+// contains both Latin letters and letters from a confusable script.
+func mixedScriptWord(line string) (string, int, bool) {
+	start := -1
+	for i := 0; i <= len(line); {
+		r, size := utf8.RuneError, 1
+		if i < len(line) {
+			r, size = utf8.DecodeRuneInString(line[i:])
+		}
+		inWord := i < len(line) && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_')
+		switch {
+		case inWord && start < 0:
+			start = i
+		case !inWord && start >= 0:
+			if script, ok := mixesLatin(line[start:i]); ok {
+				return fmt.Sprintf("%s (Latin mixed with %s)", line[start:i], script), start + 1, true
+			}
+			start = -1
+		}
+		i += size
+	}
+	return "", 0, false
+}
+
+func mixesLatin(word string) (string, bool) {
+	latin := false
+	other := ""
+	for _, r := range word {
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin = true
+		case other == "":
+			for _, s := range confusableScripts {
+				if unicode.Is(s.Table, r) {
+					other = s.Name
+				}
+			}
+		}
+	}
+	return other, latin && other != ""
+}
+
+func hasNonASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return true
+		}
+	}
+	return false
+}
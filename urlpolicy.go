@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// [block] urls globs are matched only against URL-shaped tokens in added
+// lines, and against the hostnames inside them, never against prose. So
+// "*.corp.internal" catches https://wiki.corp.internal/x and a bare
+// build.corp.internal:8080 while "Corp" in a sentence passes.
+
+const urlDetector = "urls"
+
+var (
+	// urlToken matches scheme URLs (https://, s3://), jdbc: and mailto:
+	// strings, and user@host:path remotes.
+	urlToken = regexp.MustCompile("(?i)\\b(?:[a-z][a-z0-9+.-]*://|jdbc:|mailto:|[a-z0-9._-]+@[a-z0-9-]+(?:\\.[a-z0-9-]+)+:)[^\\s\"'<>`]+")
+	// hostToken matches dotted hostnames, with an optional port, whether in
+	// a URL or standing alone.
+	hostToken = regexp.MustCompile(`(?i)\b(?:[a-z0-9](?:[a-z0-9-]*[a-z0-9])?\.)+[a-z][a-z0-9-]*[a-z0-9](?::\d+)?\b`)
+)
+
+var (
+	urlGlobMu sync.Mutex
+	urlGlobs  = map[string]*regexp.Regexp{}
+)
+
+// urlGlobRE compiles a [block] urls glob: * matches any run of characters,
+// including dots and slashes, ? one character, case-insensitively.
+func urlGlobRE(glob string) *regexp.Regexp {
+	urlGlobMu.Lock()
+	defer urlGlobMu.Unlock()
+	if re, ok := urlGlobs[glob]; ok {
+		return re
+	}
+	pat := regexp.QuoteMeta(glob)
+	pat = strings.NewReplacer(`\*`, `.*`, `\?`, `.`).Replace(pat)
+	re := regexp.MustCompile(`(?i)^` + pat + `$`)
+	urlGlobs[glob] = re
+	return re
+}
+
+// urlCandidate is one URL or bare hostname in a line: the text reported,
+// its 0-based offset, and the forms globs are tried against.
+type urlCandidate struct {
+	Text  string
+	Off   int
+	Forms []string
+}
+
+// urlCandidates returns the URLs and the bare hostnames of line. A URL is
+// tried as a whole and by each hostname inside it, and a hostname with and
+// without its port; a hit always reports the whole URL.
+func urlCandidates(line string) []urlCandidate {
+	var out []urlCandidate
+	var spans [][]int
+	for _, loc := range urlToken.FindAllStringIndex(line, -1) {
+		text := strings.TrimRight(line[loc[0]:loc[1]], ".,;:)]}")
+		out = append(out, urlCandidate{Text: text, Off: loc[0], Forms: []string{text}})
+		spans = append(spans, []int{loc[0], loc[0] + len(text)})
+	}
+	for _, loc := range hostToken.FindAllStringIndex(line, -1) {
+		host := line[loc[0]:loc[1]]
+		forms := []string{host}
+		if h, _, ok := strings.Cut(host, ":"); ok {
+			forms = append(forms, h)
+		}
+		inURL := false
+		for i, sp := range spans {
+			if loc[0] >= sp[0] && loc[1] <= sp[1] {
+				out[i].Forms = append(out[i].Forms, forms...)
+				inURL = true
+			}
+		}
+		if !inURL {
+			out = append(out, urlCandidate{Text: host, Off: loc[0], Forms: forms})
+		}
+	}
+	return out
+}
+
+// checkURLPolicy is the "urls" detector, on whenever [block] urls is set.
+// The match names the glob, so the violation says which rule fired.
+func checkURLPolicy(bc *BlockConfig, _, line string) (string, int, bool) {
+	for _, c := range urlCandidates(line) {
+		for _, glob := range bc.URLs {
+			if !slices.ContainsFunc(c.Forms, urlGlobRE(glob).MatchString) {
+				continue
+			}
+			text := c.Text
+			if bc.Sensitive[glob] {
+				text = redact(text)
+			}
+			return fmt.Sprintf("%s (urls %q)", text, bc.display(glob)), c.Off + 1, true
+		}
+	}
+	return "", 0, false
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckURLPolicy(t *testing.T) {
+	bc := &BlockConfig{URLs: []string{"*.corp.internal", "jdbc:*@*"}}
+	cases := []struct {
+		line string
+		want string // matched token, "" = no match
+	}{
+		{`base := "https://build.corp.internal/job/1"`, "https://build.corp.internal/job/1"},
+		{`DB_HOST=db.corp.internal:5432`, "db.corp.internal:5432"},
+		{`url = jdbc:oracle:thin:scott/tiger@db.example:1521/ORCL`, "jdbc:oracle:thin:scott/tiger@db.example:1521/ORCL"},
+		{`See API.Corp.Internal for details.`, "API.Corp.Internal"},
+		{`// Corp internal tooling lives elsewhere`, ""},
+		{`host = "corp.internal"`, ""},
+		{`url = jdbc:postgresql://db/app`, ""},
+	}
+	for _, c := range cases {
+		match, col, ok := checkURLPolicy(bc, "x.go", c.line)
+		if ok != (c.want != "") || ok && (!strings.HasPrefix(match, c.want+" (urls ") || !strings.HasPrefix(c.line[col-1:], c.want)) {
+			t.Errorf("%q: got %q at %d, %v; want %q", c.line, match, col, ok, c.want)
+		}
+	}
+
+	bc.Sensitive = map[string]bool{"*.corp.internal": true}
+	if match, _, _ := checkURLPolicy(bc, "x.go", "https://build.corp.internal/"); strings.Contains(match, "corp.internal") {
+		t.Errorf("sensitive match %q should be redacted", match)
+	}
+}
+
+func TestURLPolicyConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "snag.toml")
+	os.WriteFile(path, []byte("[block]\nurls = [\"*.Corp.Internal\"]\n"), 0644)
+	bc, err := resolveBlockConfigAt(nil, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bc.URLs) != 1 || bc.URLs[0] != "*.corp.internal" {
+		t.Errorf("URLs = %v", bc.URLs)
+	}
+	if d := bc.enabledDetectors(); len(d) == 0 || d[len(d)-1].Name != urlDetector {
+		t.Errorf("urls detector not enabled: %v", d)
+	}
+
+	os.WriteFile(path, []byte("[block]\nurls = [\"**\"]\n"), 0644)
+	if _, err := loadSnagTOML(path); err == nil || !strings.Contains(err.Error(), "every URL") {
+		t.Errorf("err = %v", err)
+	}
+}
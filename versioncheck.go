@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// latestReleaseURL is the GitHub API endpoint for the newest snag release.
+var latestReleaseURL = "https://api.github.com/repos/dpritchett/snag/releases/latest"
+
+const (
+	versionCheckFile     = "version-check.json"
+	versionCheckInterval = 24 * time.Hour
+	versionCheckTimeout  = 3 * time.Second
+)
+
+// versionCache is the last answer from the releases API, kept in the
+// per-user config dir so every repository shares one check a day.
+type versionCache struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Latest    string    `json:"latest"`
+}
+
+// latestVersion returns the newest release tag, asking GitHub at most once
+// per versionCheckInterval. With the network off it returns whatever is
+// cached. Errors are swallowed: an update hint is never worth a failure.
+func latestVersion(bc *BlockConfig) string {
+	dir, err := snagConfigHome()
+	if err != nil {
+		return ""
+	}
+	path := filepath.Join(dir, versionCheckFile)
+	var cache versionCache
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &cache)
+	}
+	if allowed, _ := networkAllowed(bc); !allowed || time.Since(cache.CheckedAt) < versionCheckInterval {
+		return cache.Latest
+	}
+
+	cache.CheckedAt = time.Now()
+	client := &http.Client{Timeout: versionCheckTimeout}
+	if resp, err := client.Get(latestReleaseURL); err == nil {
+		var release struct {
+			TagName string `json:"tag_name"`
+		}
+		if resp.StatusCode == http.StatusOK && json.NewDecoder(resp.Body).Decode(&release) == nil {
+			cache.Latest = release.TagName
+		}
+		resp.Body.Close()
+	}
+	// Record the attempt even when it failed, so an outage costs one
+	// timeout a day rather than one per run.
+	if data, err := json.Marshal(cache); err == nil && os.MkdirAll(dir, 0755) == nil {
+		writeStateFile(path, data)
+	}
+	return cache.Latest
+}
+
+// updateHint returns a one-line nudge when a newer release exists, or "".
+// Dev builds and [behavior] version_check = false never check.
+func updateHint(bc *BlockConfig) string {
+	if Version == "dev" || strings.HasPrefix(Version, "dev+") || (bc != nil && bc.VersionCheckOff) {
+		return ""
+	}
+	latest := latestVersion(bc)
+	if latest == "" {
+		return ""
+	}
+	if compareSemver(strings.TrimPrefix(Version, "v"), strings.TrimPrefix(latest, "v")) >= 0 {
+		return ""
+	}
+	return fmt.Sprintf("snag %s is available (running %s): go install github.com/dpritchett/snag@latest", latest, Version)
+}
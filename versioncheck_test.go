@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUpdateHint(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, `{"tag_name": "v1.4.0"}`)
+	}))
+	defer srv.Close()
+	origURL, origVersion := latestReleaseURL, Version
+	latestReleaseURL = srv.URL
+	defer func() { latestReleaseURL, Version = origURL, origVersion }()
+
+	Version = "v1.2.0"
+	if hint := updateHint(&BlockConfig{}); !strings.Contains(hint, "v1.4.0 is available") {
+		t.Errorf("expected an update hint, got %q", hint)
+	}
+	// The answer is cached for a day.
+	Version = "v1.4.0"
+	if hint := updateHint(&BlockConfig{}); hint != "" {
+		t.Errorf("up to date, got %q", hint)
+	}
+	if calls != 1 {
+		t.Errorf("releases API called %d times, want 1", calls)
+	}
+
+	Version = "v1.0.0"
+	if hint := updateHint(&BlockConfig{VersionCheckOff: true}); hint != "" {
+		t.Errorf("version_check = false, got %q", hint)
+	}
+	Version = "dev+abc1234"
+	if hint := updateHint(&BlockConfig{}); hint != "" {
+		t.Errorf("dev build, got %q", hint)
+	}
+}
+
+func TestLatestVersion_OfflineUsesCache(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("SNAG_OFFLINE", "1")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("network used while offline")
+	}))
+	defer srv.Close()
+	origURL := latestReleaseURL
+	latestReleaseURL = srv.URL
+	defer func() { latestReleaseURL = origURL }()
+
+	if got := latestVersion(&BlockConfig{}); got != "" {
+		t.Errorf("no cache yet, got %q", got)
+	}
+}
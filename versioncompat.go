@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+)
+
+// semverRef matches refs snag can compare: tags like v0.12.0 or 0.12.
+// Branch refs such as main can't be compared and are reported as such.
+var semverRef = regexp.MustCompile(`^v?\d+(\.\d+){0,2}$`)
+
+// compatItem is one version-bearing source in the compatibility report.
+type compatItem struct {
+	Source  string // "lefthook", "min_version"
+	Where   string // file the version came from
+	Version string
+	Status  string
+	Problem bool
+}
+
+// isDevVersion reports whether v is a dev build, which never compares.
+func isDevVersion(v string) bool {
+	return v == "dev" || strings.HasPrefix(v, "dev+")
+}
+
+// lefthookCompat compares the snag remote ref pinned in the lefthook configs
+// in dir with the running binary. The remote's recipes call this binary,
+// so recipes newer than it can pass commands or flags it doesn't know.
+func lefthookCompat(dir string) []compatItem {
+	var items []compatItem
+	for _, names := range [][]string{lefthookCandidates, lefthookLocalCandidates} {
+		for _, name := range names {
+			data, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				continue
+			}
+			ref, err := findSnagRemote(data)
+			if err != nil {
+				items = append(items, compatItem{Source: "lefthook", Where: name, Version: "?", Status: "can't parse: " + err.Error(), Problem: true})
+				continue
+			}
+			if ref == "" {
+				continue
+			}
+			item := compatItem{Source: "lefthook", Where: name, Version: ref}
+			switch c := compareSemver(strings.TrimPrefix(Version, "v"), strings.TrimPrefix(ref, "v")); {
+			case !semverRef.MatchString(ref):
+				item.Status = "not a version tag; can't compare"
+			case isDevVersion(Version):
+				item.Status = "dev build; not compared"
+			case c < 0:
+				item.Status = fmt.Sprintf("recipes are newer than snag %s; hooks may use commands or flags it lacks", Version)
+				item.Problem = true
+			case c > 0:
+				item.Status = "older than this binary (snag install updates the ref)"
+			default:
+				item.Status = "ok"
+			}
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// minVersionCompat lists the min_version of every config file governing
+// dir — the directory walk, SNAG_CONFIG_DIRS, includes and worktree.toml —
+// without enforcing them, so one failing file doesn't hide the rest.
+func minVersionCompat(dir string) []compatItem {
+	var items []compatItem
+	var visit func(path string, stack []string)
+	visit = func(path string, stack []string) {
+		var cfg struct {
+			MinVersion string   `toml:"min_version"`
+			MinPolicy  string   `toml:"min_version_policy"`
+			Include    []string `toml:"include"`
+		}
+		data, err := readConfigFile(path)
+		if err == nil {
+			_, err = toml.Decode(string(data), &cfg)
+		}
+		if err != nil {
+			items = append(items, compatItem{Source: "min_version", Where: path, Version: "?", Status: "can't read: " + err.Error(), Problem: true})
+			return
+		}
+		if cfg.MinVersion != "" {
+			item := compatItem{Source: "min_version", Where: path, Version: cfg.MinVersion, Status: "ok"}
+			switch {
+			case isDevVersion(Version):
+				item.Status = "dev build; not enforced"
+			case checkMinVersion(cfg.MinVersion, path) == nil:
+			case cfg.MinPolicy == minVersionDegrade:
+				item.Status = fmt.Sprintf("snag %s is older; hooks run degraded, skipping newer settings", Version)
+				item.Problem = true
+			default:
+				item.Status = fmt.Sprintf("snag %s is older; every hook fails to load this file", Version)
+				item.Problem = true
+			}
+			items = append(items, item)
+		}
+		includes, err := includePaths(path, cfg.Include, stack)
+		if err != nil {
+			items = append(items, compatItem{Source: "min_version", Where: path, Version: "?", Status: err.Error(), Problem: true})
+			return
+		}
+		for _, inc := range includes {
+			visit(inc, append(stack, absPath(path)))
+		}
+	}
+	for _, d := range configChain(dir) {
+		for _, name := range append([]string{"snag.toml"}, localConfigNames...) {
+			if path := filepath.Join(d, name); fileExists(path) {
+				visit(path, nil)
+			}
+		}
+	}
+	if path := worktreeConfigPath(dir); path != "" && fileExists(path) {
+		visit(path, nil)
+	}
+	return items
+}
+
+// runVersionCompat prints the compatibility report for the current
+// directory and fails when any source needs a different snag.
+func runVersionCompat(cmd *cobra.Command) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	items := append(lefthookCompat(cwd), minVersionCompat(cwd)...)
+
+	tw := tabwriter.NewWriter(out, 2, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "snag\t%s\tthis binary\n", Version)
+	problems := 0
+	for _, it := range items {
+		mark := ""
+		if it.Problem {
+			mark = "✗ "
+			problems++
+		}
+		fprintPlain(tw, "%s\t%s\t%s%s (%s)\n", it.Source, it.Version, mark, it.Status, relPath(cwd, it.Where))
+	}
+	tw.Flush()
+	if len(items) == 0 {
+		fmt.Fprintln(out, "no lefthook snag remote or min_version found")
+	}
+	if problems > 0 {
+		hintf("upgrade: go install github.com/dpritchett/snag@latest, or pin the lefthook ref to %s with snag install", versionRef())
+		return fmt.Errorf("%d compatibility problem(s) found", problems)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func runCheckCompat(t *testing.T) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	rootCmd := buildRootCmd()
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"version", "--check-compat"})
+	err := rootCmd.Execute()
+	return out.String(), err
+}
+
+func TestVersionCheckCompat(t *testing.T) {
+	dir := t.TempDir()
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+	t.Setenv("SNAG_CONFIG_DIRS", "")
+	old := Version
+	Version = "0.12.0"
+	defer func() { Version = old }()
+
+	os.WriteFile("lefthook.yml", []byte(snagRemoteBlockTrimmed("v0.12.0")), 0644)
+	os.WriteFile("snag.toml", []byte("min_version = \"0.11.0\"\ninclude = [\"team.toml\"]\n"), 0644)
+	os.WriteFile("team.toml", []byte("min_version = \"0.12.0\"\n"), 0644)
+	out, err := runCheckCompat(t)
+	if err != nil {
+		t.Fatalf("compatible setup failed: %v\n%s", err, out)
+	}
+	for _, want := range []string{"v0.12.0  ok (lefthook.yml)", "0.12.0   ok (team.toml)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+
+	// Recipes newer than the binary and a strict min_version it can't meet.
+	os.WriteFile("lefthook-local.yml", []byte(snagRemoteBlockTrimmed("v0.14.0")), 0644)
+	os.WriteFile("team.toml", []byte("min_version = \"0.13.0\"\n"), 0644)
+	out, err = runCheckCompat(t)
+	if err == nil || !strings.Contains(err.Error(), "2 compatibility problem(s)") {
+		t.Fatalf("err = %v\n%s", err, out)
+	}
+	for _, want := range []string{"recipes are newer than snag 0.12.0", "every hook fails to load this file (team.toml)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+
+	// Branch refs and dev builds are reported but never flagged.
+	os.WriteFile("lefthook-local.yml", []byte(snagRemoteBlockTrimmed("main")), 0644)
+	Version = "dev"
+	out, err = runCheckCompat(t)
+	if err != nil || !strings.Contains(out, "not a version tag") || !strings.Contains(out, "dev build; not enforced") {
+		t.Errorf("err = %v\n%s", err, out)
+	}
+}
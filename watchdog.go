@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	onTimeoutBlock = "block"
+	onTimeoutAllow = "allow"
+)
+
+// hookCtx is cancelled when the watchdog gives up on a check, killing the
+// git subprocesses it started.
+var hookCtx = context.Background()
+
+// gitCommand is exec.Command("git", args...) tied to hookCtx. snag runs git
+// through it so a timed-out check leaves no git processes behind.
+func gitCommand(args ...string) *exec.Cmd {
+	return exec.CommandContext(hookCtx, "git", args...)
+}
+
+// withHookTimeout wraps a hook's RunE in the [limits] hook_timeout watchdog.
+// If the check hasn't finished in time — a git subprocess stuck on a
+// credential prompt or a hung network filesystem — snag kills the check's
+// git subprocesses and either fails the hook (on_timeout = "block", the
+// default) or lets it pass with a warning (on_timeout = "allow"). hookCtx
+// stays cancelled, so the abandoned check can't start new ones before the
+// process exits.
+func withHookTimeout(run func(*cobra.Command, []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		bc, err := resolveBlockConfig(cmd)
+		if err != nil || bc.HookTimeout == 0 {
+			return run(cmd, args)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		hookCtx = ctx
+		done := make(chan error, 1)
+		go func() { done <- run(cmd, args) }()
+		timer := time.NewTimer(bc.HookTimeout)
+		defer timer.Stop()
+		select {
+		case err := <-done:
+			cancel()
+			hookCtx = context.Background()
+			return err
+		case <-timer.C:
+			cancel()
+		}
+
+		if bc.OnTimeout == onTimeoutAllow {
+			warnf("check %s gave up after %s — allowing (on_timeout = %q)", cmd.Name(), bc.HookTimeout, onTimeoutAllow)
+			return nil
+		}
+		blockf(idHookTimeout, "check %s gave up after %s", cmd.Name(), bc.HookTimeout)
+		hintf("a git subprocess may be hung; set [limits] on_timeout = %q to fail open", onTimeoutAllow)
+		return fmt.Errorf("check %s timed out after %s [%s]", cmd.Name(), bc.HookTimeout, idHookTimeout)
+	}
+}
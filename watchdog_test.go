@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func TestWithHookTimeout(t *testing.T) {
+	hang := func(*cobra.Command, []string) error {
+		time.Sleep(5 * time.Second)
+		return nil
+	}
+	tests := []struct {
+		name    string
+		limits  string
+		run     func(*cobra.Command, []string) error
+		wantErr bool
+	}{
+		{"no timeout configured runs to completion", "", func(*cobra.Command, []string) error { return nil }, false},
+		{"fail closed by default", "hook_timeout = \"50ms\"\n", hang, true},
+		{"fail open", "hook_timeout = \"50ms\"\non_timeout = \"allow\"\n", hang, false},
+		{"fast check keeps its result", "hook_timeout = \"5s\"\n", func(*cobra.Command, []string) error { return os.ErrInvalid }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[limits]\n"+tt.limits), 0644)
+			orig, _ := os.Getwd()
+			os.Chdir(dir)
+			defer os.Chdir(orig)
+
+			cmd := &cobra.Command{Use: "diff"}
+			start := time.Now()
+			err := withHookTimeout(tt.run)(cmd, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if time.Since(start) > 2*time.Second {
+				t.Errorf("watchdog did not stop waiting")
+			}
+		})
+	}
+}
+
+func TestWithHookTimeout_KillsGit(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[limits]\nhook_timeout = \"100ms\"\n"), 0644)
+	orig, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(orig)
+	defer func() { hookCtx = context.Background() }()
+
+	// git hash-object --stdin waits on a pipe nobody writes to.
+	stdin, w, _ := os.Pipe()
+	defer w.Close()
+	exited := make(chan error, 1)
+	hungGit := func(*cobra.Command, []string) error {
+		c := gitCommand("hash-object", "--stdin")
+		c.Stdin = stdin
+		if err := c.Start(); err != nil {
+			exited <- err
+			return err
+		}
+		err := c.Wait()
+		exited <- err
+		return err
+	}
+	if err := withHookTimeout(hungGit)(&cobra.Command{Use: "diff"}, nil); err == nil {
+		t.Fatal("expected a timeout")
+	}
+	select {
+	case err := <-exited:
+		if err == nil {
+			t.Error("git finished normally; expected it to be killed")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("git still running after the watchdog gave up")
+	}
+}
+
+func TestLoadSnagTOML_HookTimeoutValidation(t *testing.T) {
+	for _, bad := range []string{`hook_timeout = "soon"`, `hook_timeout = "-1s"`, `on_timeout = "retry"`} {
+		path := filepath.Join(t.TempDir(), "snag.toml")
+		os.WriteFile(path, []byte("[limits]\n"+bad+"\n"), 0644)
+		if _, err := loadSnagTOML(path); err == nil || !strings.Contains(err.Error(), "limits.") {
+			t.Errorf("%s: expected validation error, got %v", bad, err)
+		}
+	}
+}
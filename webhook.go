@@ -0,0 +1,653 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	webhookMaxBody     = 5 << 20 // forge push payloads list commits; cap what we buffer
+	webhookQueue       = 64
+	webhookTimeout     = 15 * time.Second
+	webhookContext     = "snag" // status context / check name shown on the forge
+	webhookDescLimit   = 140    // longest status description the forges accept
+	webhookResultPass  = "success"
+	webhookResultFail  = "failure"
+	webhookResultError = "error"
+)
+
+// webhookJob is one push (or Gerrit patch set) to check, as parsed from a
+// forge's event payload.
+type webhookJob struct {
+	Forge    *webhookForge
+	Repo     string // display name, e.g. "org/app"
+	CloneURL string
+	Ref      string // ref to fetch; the pushed branch or a Gerrit change ref
+	Before   string // previous tip; all zeros for a new ref
+	After    string
+	Base     string // default branch, compared against when Before is unusable
+
+	StatusAPI string // forge API base for reporting back; "" = log only
+	ProjectID string // GitLab project id; Gerrit "project~number" change id
+}
+
+// webhookResult is the outcome reported back to the forge.
+type webhookResult struct {
+	State   string // webhookResultPass, webhookResultFail or webhookResultError
+	Summary string
+}
+
+// webhookForge adapts one forge's event format and status API.
+type webhookForge struct {
+	Name string
+	// Detect reports whether the request is this forge's push event.
+	Detect func(r *http.Request, body []byte) bool
+	// Verify checks the shared secret the forge signs or sends with events.
+	Verify func(r *http.Request, body []byte, secret string) bool
+	Parse  func(s *webhookServer, body []byte) (webhookJob, error)
+	Report func(s *webhookServer, job webhookJob, res webhookResult) error
+}
+
+// webhookForges is the registry of supported forges, tried in order.
+var webhookForges = []*webhookForge{
+	{
+		Name: "gitea",
+		Detect: func(r *http.Request, body []byte) bool {
+			return r.Header.Get("X-Gitea-Event") == "push" || r.Header.Get("X-Gogs-Event") == "push"
+		},
+		Verify: verifyGiteaSignature,
+		Parse:  parseGiteaPush,
+		Report: reportGiteaStatus,
+	},
+	{
+		Name: "gitlab",
+		Detect: func(r *http.Request, body []byte) bool {
+			return r.Header.Get("X-Gitlab-Event") == "Push Hook"
+		},
+		Verify: func(r *http.Request, body []byte, secret string) bool {
+			return secretEqual(r.Header.Get("X-Gitlab-Token"), secret)
+		},
+		Parse:  parseGitLabPush,
+		Report: reportGitLabStatus,
+	},
+	{
+		Name: "gerrit",
+		Detect: func(r *http.Request, body []byte) bool {
+			var ev struct{ Type string }
+			return json.Unmarshal(body, &ev) == nil && (ev.Type == "patchset-created" || ev.Type == "ref-updated")
+		},
+		// The webhooks plugin doesn't sign events; put the secret in the
+		// configured URL as ?token=.
+		Verify: func(r *http.Request, body []byte, secret string) bool {
+			return secretEqual(r.URL.Query().Get("token"), secret)
+		},
+		Parse:  parseGerritEvent,
+		Report: reportGerritReview,
+	},
+}
+
+func buildWebhookCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "webhook",
+		Short: "Enforce push policy from forge webhooks",
+	}
+	serve := &cobra.Command{
+		Use:   "serve [--addr :8080]",
+		Short: "Receive Gitea, GitLab and Gerrit push events and report policy status",
+		Long: `Listen for push events from Gitea (or Gogs), GitLab and Gerrit, fetch each
+pushed range into a local mirror, run the snag check push policy over it,
+and report the result to the forge: a commit status on Gitea and GitLab, a
+review message (and optional label vote) on the Gerrit patch set.
+
+Policy comes from the snag config chain of the directory snag was started
+in, and SNAG_CONFIG_DIRS, not from the pushed commits. Events are checked
+one at a time in the order received.
+
+Set the same secret here and in the forge's webhook settings (Gerrit: as a
+?token= query parameter on the URL); without one snag refuses to start
+unless --insecure is given. Only https and ssh repositories on --allow-host
+hosts (and the --gerrit-url host) are fetched or sent credentials.
+Forge API credentials:
+  SNAG_GITEA_TOKEN                         Gitea access token
+  SNAG_GITLAB_TOKEN                        GitLab token with api scope
+  SNAG_GERRIT_USER, SNAG_GERRIT_PASSWORD   Gerrit HTTP credentials
+The secret and tokens can live in the OS credential store instead: see
+snag auth login. Without credentials results are only logged. Fetching uses git's own
+credential configuration.`,
+		Example: `  SNAG_WEBHOOK_SECRET=s3cret SNAG_GITEA_TOKEN=... snag webhook serve --addr :8080 --allow-host git.example.com
+  snag webhook serve --gerrit-url https://review.example.com --gerrit-label Verified`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runWebhookServe,
+	}
+	serve.Flags().String("addr", ":8080", "address to listen on")
+	serve.Flags().String("secret", "", "shared webhook secret (default $SNAG_WEBHOOK_SECRET, then snag auth login webhook-secret)")
+	serve.Flags().String("workdir", "", "directory for fetched mirrors (default: user cache dir)")
+	serve.Flags().String("gerrit-url", "", "Gerrit base URL, for cloning and the REST API")
+	serve.Flags().String("gerrit-label", "", "label to vote +1/-1 on Gerrit patch sets (default: message only)")
+	serve.Flags().StringSlice("allow-host", nil, "forge host whose repositories and API may be used (repeatable)")
+	serve.Flags().Bool("insecure", false, "accept unauthenticated events when no secret is set")
+	cmd.AddCommand(serve)
+	return cmd
+}
+
+// webhookServer holds the receiver's settings and its job queue.
+type webhookServer struct {
+	cmd         *cobra.Command
+	PolicyDir   string // directory whose config chain supplies the policy
+	WorkDir     string
+	Secret      string
+	GerritURL   string
+	GerritLabel string
+	AllowHosts  []string // lowercased hosts event URLs may point at
+	Client      *http.Client
+
+	jobs chan webhookJob
+	mu   sync.Mutex // checks chdir into a mirror; one at a time
+}
+
+func runWebhookServe(cmd *cobra.Command, args []string) error {
+	policyDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting working directory: %w", err)
+	}
+	bc, err := resolveBlockConfig(cmd)
+	if err != nil {
+		return err
+	}
+	if ok, why := networkAllowed(bc); !ok {
+		return fmt.Errorf("snag webhook serve needs the network (disabled by %s)", why)
+	}
+
+	s := &webhookServer{cmd: cmd, PolicyDir: policyDir, Client: &http.Client{Timeout: webhookTimeout}}
+	s.Secret, _ = cmd.Flags().GetString("secret")
+	if s.Secret == "" {
+		if s.Secret, _, err = lookupSecret("webhook-secret"); err != nil {
+			return err
+		}
+	}
+	s.WorkDir, _ = cmd.Flags().GetString("workdir")
+	if s.WorkDir == "" {
+		cache, err := os.UserCacheDir()
+		if err != nil {
+			return fmt.Errorf("no --workdir and no user cache dir: %w", err)
+		}
+		s.WorkDir = filepath.Join(cache, "snag", "webhook")
+	}
+	if s.WorkDir, err = filepath.Abs(s.WorkDir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.WorkDir, 0o755); err != nil {
+		return err
+	}
+	s.GerritURL, _ = cmd.Flags().GetString("gerrit-url")
+	s.GerritURL = strings.TrimSuffix(s.GerritURL, "/")
+	s.GerritLabel, _ = cmd.Flags().GetString("gerrit-label")
+	if s.Secret == "" {
+		if insecure, _ := cmd.Flags().GetBool("insecure"); !insecure {
+			return fmt.Errorf("no webhook secret: set --secret or SNAG_WEBHOOK_SECRET (or pass --insecure to accept unauthenticated events)")
+		}
+		warnf("no webhook secret set — accepting unauthenticated events")
+	}
+	hosts, _ := cmd.Flags().GetStringSlice("allow-host")
+	if u, err := url.Parse(s.GerritURL); err == nil && u.Host != "" {
+		hosts = append(hosts, u.Hostname())
+	}
+	for _, h := range hosts {
+		s.AllowHosts = append(s.AllowHosts, strings.ToLower(h))
+	}
+	if len(s.AllowHosts) == 0 {
+		return fmt.Errorf("no forge hosts allowed: pass --allow-host (or --gerrit-url)")
+	}
+
+	s.jobs = make(chan webhookJob, webhookQueue)
+	go func() {
+		for job := range s.jobs {
+			s.handle(job)
+		}
+	}()
+
+	addr, _ := cmd.Flags().GetString("addr")
+	infof("listening on %s (mirrors in %s)", addr, s.WorkDir)
+	return http.ListenAndServe(addr, s)
+}
+
+// ServeHTTP accepts events, queues the ones it can parse, and answers right
+// away; forges time webhook deliveries out after a few seconds.
+func (s *webhookServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet && r.URL.Path == "/healthz" {
+		io.WriteString(w, "ok\n")
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST push events here", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, webhookMaxBody))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	job, status, err := s.accept(r, body)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if job == nil {
+		w.WriteHeader(status)
+		return
+	}
+	select {
+	case s.jobs <- *job:
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		http.Error(w, "queue full", http.StatusServiceUnavailable)
+	}
+}
+
+// accept identifies, authenticates and parses one event. A nil job with no
+// error means the event was valid but needs no check (deletions, other
+// event types).
+func (s *webhookServer) accept(r *http.Request, body []byte) (*webhookJob, int, error) {
+	for _, f := range webhookForges {
+		if !f.Detect(r, body) {
+			continue
+		}
+		if s.Secret != "" && !f.Verify(r, body, s.Secret) {
+			return nil, http.StatusUnauthorized, fmt.Errorf("bad %s webhook secret", f.Name)
+		}
+		job, err := f.Parse(s, body)
+		if err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("%s event: %w", f.Name, err)
+		}
+		job.Forge = f
+		if isZeroSHA(job.After) {
+			return nil, http.StatusNoContent, nil
+		}
+		// The revisions end up on git command lines; anything but an object
+		// id could be read as an option (--output=FILE).
+		if !objectIDPattern.MatchString(job.After) {
+			return nil, http.StatusBadRequest, fmt.Errorf("%s event: after %q is not a commit id", f.Name, job.After)
+		}
+		if job.Before != "" && !objectIDPattern.MatchString(job.Before) {
+			return nil, http.StatusBadRequest, fmt.Errorf("%s event: before %q is not a commit id", f.Name, job.Before)
+		}
+		if err := s.allowedURL(job.CloneURL, "https", "ssh"); err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("%s event: clone URL: %w", f.Name, err)
+		}
+		if job.StatusAPI != "" {
+			if err := s.allowedURL(job.StatusAPI, "https"); err != nil {
+				return nil, http.StatusBadRequest, fmt.Errorf("%s event: API URL: %w", f.Name, err)
+			}
+		}
+		return &job, http.StatusAccepted, nil
+	}
+	return nil, http.StatusNoContent, nil
+}
+
+// handle checks one job and reports the result, logging rather than
+// returning failures since nobody is waiting on the response.
+func (s *webhookServer) handle(job webhookJob) {
+	res := s.check(job)
+	short := job.After[:min(7, len(job.After))]
+	switch res.State {
+	case webhookResultPass:
+		infof("%s %s %s: %s", job.Repo, job.Ref, short, res.Summary)
+	default:
+		warnf("%s %s %s: %s", job.Repo, job.Ref, short, res.Summary)
+	}
+	if err := job.Forge.Report(s, job, res); err != nil {
+		warnf("reporting to %s: %v", job.Forge.Name, err)
+	}
+}
+
+// check fetches the job's range into a mirror and runs the push policy.
+func (s *webhookServer) check(job webhookJob) webhookResult {
+	mirror, err := s.fetch(job)
+	if err != nil {
+		return webhookResult{State: webhookResultError, Summary: err.Error()}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bc, err := resolveBlockConfigAt(s.cmd, s.PolicyDir)
+	if err != nil {
+		return webhookResult{State: webhookResultError, Summary: err.Error()}
+	}
+	bc.ignoreAuthorExemptions()
+	orig, err := os.Getwd()
+	if err != nil {
+		return webhookResult{State: webhookResultError, Summary: err.Error()}
+	}
+	if err := os.Chdir(mirror); err != nil {
+		return webhookResult{State: webhookResultError, Summary: err.Error()}
+	}
+	defer os.Chdir(orig)
+
+	// The revisions come from the payload: --end-of-options keeps git
+	// from ever reading one as an option, even if accept was bypassed.
+	revs := []string{"--end-of-options", job.After + "^!"}
+	switch {
+	case !isZeroSHA(job.Before) && haveCommit(job.Before):
+		revs = []string{"--end-of-options", job.Before + ".." + job.After}
+	case job.Base != "" && haveCommit(webhookBaseRef):
+		revs = []string{"--end-of-options", webhookBaseRef + ".." + job.After}
+	}
+	n := 0
+	if out, err := gitCommand(append([]string{"rev-list", "--count"}, revs...)...).Output(); err == nil {
+		fmt.Sscan(string(out), &n)
+	}
+	if !bc.hasPushChecks() || n == 0 {
+		return webhookResult{State: webhookResultPass, Summary: "no commits to check"}
+	}
+	ranges, err := capPushRanges(s.cmd, bc, [][]string{revs})
+	if err == nil {
+		err = checkPushCommits(s.cmd, bc, ranges)
+	}
+	if err != nil {
+		if msg, ok := strings.CutPrefix(err.Error(), "policy violation: "); ok {
+			return webhookResult{State: webhookResultFail, Summary: msg}
+		}
+		return webhookResult{State: webhookResultError, Summary: err.Error()}
+	}
+	return webhookResult{State: webhookResultPass, Summary: fmt.Sprintf("%d commits pass snag policy", n)}
+}
+
+// objectIDPattern matches a full SHA-1 or SHA-256 object id.
+var objectIDPattern = regexp.MustCompile(`^([0-9a-fA-F]{40}|[0-9a-fA-F]{64})$`)
+
+// scpLikeURL matches git's scp-style ssh syntax, user@host:path.
+var scpLikeURL = regexp.MustCompile(`^[A-Za-z0-9._-]+@([A-Za-z0-9.-]+):[^/]`)
+
+// allowedURL rejects event URLs that could make git or the status reporter
+// do anything but talk to a configured forge: option-like values (git
+// fetch --upload-pack=...), other schemes (file, ext::) and other hosts.
+func (s *webhookServer) allowedURL(raw string, schemes ...string) error {
+	if strings.HasPrefix(raw, "-") {
+		return fmt.Errorf("%q looks like an option", raw)
+	}
+	var scheme, host string
+	if m := scpLikeURL.FindStringSubmatch(raw); m != nil && !strings.Contains(raw, "://") {
+		scheme, host = "ssh", m[1]
+	} else {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return err
+		}
+		scheme, host = strings.ToLower(u.Scheme), u.Hostname()
+	}
+	if !slices.Contains(schemes, scheme) {
+		return fmt.Errorf("%q: scheme must be %s", raw, strings.Join(schemes, " or "))
+	}
+	if !slices.Contains(s.AllowHosts, strings.ToLower(host)) {
+		return fmt.Errorf("%q: host %q is not allowed (see --allow-host)", raw, host)
+	}
+	return nil
+}
+
+// webhookBaseRef is where a job's default branch is fetched to.
+const webhookBaseRef = "refs/snag/base"
+
+var unsafeMirrorChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// fetch brings the pushed commit (and the default branch, for new refs) into
+// a bare mirror of the job's repository, creating it on first use.
+func (s *webhookServer) fetch(job webhookJob) (string, error) {
+	name := unsafeMirrorChars.ReplaceAllString(job.Forge.Name+"-"+job.Repo, "_") + ".git"
+	mirror := filepath.Join(s.WorkDir, name)
+	if !fileExists(mirror) {
+		if out, err := gitCommand("init", "-q", "--bare", mirror).CombinedOutput(); err != nil {
+			return "", fmt.Errorf("git init %s: %v\n%s", mirror, err, out)
+		}
+	}
+	refspecs := []string{"+" + job.Ref + ":refs/snag/head"}
+	if job.Base != "" {
+		refspecs = append(refspecs, "+refs/heads/"+job.Base+":"+webhookBaseRef)
+	}
+	if strings.HasPrefix(job.CloneURL, "-") {
+		return "", fmt.Errorf("refusing to fetch %q", job.CloneURL)
+	}
+	args := append([]string{"-C", mirror, "fetch", "-q", "--no-tags", "--", job.CloneURL}, refspecs...)
+	if out, err := gitCommand(args...).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("git fetch %s: %v\n%s", job.CloneURL, err, bytes.TrimSpace(out))
+	}
+	return mirror, nil
+}
+
+func secretEqual(got, want string) bool {
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+// verifyGiteaSignature checks the hex HMAC-SHA256 of the body, sent as
+// X-Gitea-Signature (or GitHub-style X-Hub-Signature-256 by Gogs and
+// GitHub-compatible senders).
+func verifyGiteaSignature(r *http.Request, body []byte, secret string) bool {
+	sig := r.Header.Get("X-Gitea-Signature")
+	if sig == "" {
+		sig = strings.TrimPrefix(r.Header.Get("X-Hub-Signature-256"), "sha256=")
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+func parseGiteaPush(s *webhookServer, body []byte) (webhookJob, error) {
+	var ev struct {
+		Ref        string `json:"ref"`
+		Before     string `json:"before"`
+		After      string `json:"after"`
+		Repository struct {
+			FullName      string `json:"full_name"`
+			CloneURL      string `json:"clone_url"`
+			HTMLURL       string `json:"html_url"`
+			DefaultBranch string `json:"default_branch"`
+		} `json:"repository"`
+	}
+	if err := json.Unmarshal(body, &ev); err != nil {
+		return webhookJob{}, err
+	}
+	if ev.Ref == "" || ev.After == "" || ev.Repository.CloneURL == "" {
+		return webhookJob{}, errors.New("missing ref, after or repository.clone_url")
+	}
+	return webhookJob{
+		Repo:      ev.Repository.FullName,
+		CloneURL:  ev.Repository.CloneURL,
+		Ref:       ev.Ref,
+		Before:    ev.Before,
+		After:     ev.After,
+		Base:      ev.Repository.DefaultBranch,
+		StatusAPI: strings.TrimSuffix(ev.Repository.HTMLURL, "/"+ev.Repository.FullName) + "/api/v1",
+	}, nil
+}
+
+func reportGiteaStatus(s *webhookServer, job webhookJob, res webhookResult) error {
+	token := secretOrWarn("gitea")
+	if token == "" {
+		return nil
+	}
+	endpoint := fmt.Sprintf("%s/repos/%s/statuses/%s", job.StatusAPI, job.Repo, url.PathEscape(job.After))
+	return s.send(http.MethodPost, endpoint, map[string]string{
+		"state":       res.State,
+		"context":     webhookContext,
+		"description": statusDescription(res.Summary),
+	}, func(req *http.Request) { req.Header.Set("Authorization", "token "+token) })
+}
+
+func parseGitLabPush(s *webhookServer, body []byte) (webhookJob, error) {
+	var ev struct {
+		Ref       string `json:"ref"`
+		Before    string `json:"before"`
+		After     string `json:"after"`
+		ProjectID int    `json:"project_id"`
+		Project   struct {
+			PathWithNamespace string `json:"path_with_namespace"`
+			GitHTTPURL        string `json:"git_http_url"`
+			WebURL            string `json:"web_url"`
+			DefaultBranch     string `json:"default_branch"`
+		} `json:"project"`
+	}
+	if err := json.Unmarshal(body, &ev); err != nil {
+		return webhookJob{}, err
+	}
+	if ev.Ref == "" || ev.After == "" || ev.Project.GitHTTPURL == "" {
+		return webhookJob{}, errors.New("missing ref, after or project.git_http_url")
+	}
+	return webhookJob{
+		Repo:      ev.Project.PathWithNamespace,
+		CloneURL:  ev.Project.GitHTTPURL,
+		Ref:       ev.Ref,
+		Before:    ev.Before,
+		After:     ev.After,
+		Base:      ev.Project.DefaultBranch,
+		StatusAPI: strings.TrimSuffix(ev.Project.WebURL, "/"+ev.Project.PathWithNamespace) + "/api/v4",
+		ProjectID: fmt.Sprint(ev.ProjectID),
+	}, nil
+}
+
+func reportGitLabStatus(s *webhookServer, job webhookJob, res webhookResult) error {
+	token := secretOrWarn("gitlab")
+	if token == "" {
+		return nil
+	}
+	state := res.State
+	if state != webhookResultPass {
+		state = "failed" // GitLab has no separate error state
+	}
+	endpoint := fmt.Sprintf("%s/projects/%s/statuses/%s", job.StatusAPI, url.PathEscape(job.ProjectID), url.PathEscape(job.After))
+	return s.send(http.MethodPost, endpoint, map[string]string{
+		"state":       state,
+		"name":        webhookContext,
+		"ref":         strings.TrimPrefix(job.Ref, "refs/heads/"),
+		"description": statusDescription(res.Summary),
+	}, func(req *http.Request) { req.Header.Set("PRIVATE-TOKEN", token) })
+}
+
+// parseGerritEvent handles patchset-created (checked and reviewed) and
+// ref-updated (checked and logged: direct pushes have no change to review).
+func parseGerritEvent(s *webhookServer, body []byte) (webhookJob, error) {
+	if s.GerritURL == "" {
+		return webhookJob{}, errors.New("got a Gerrit event but --gerrit-url is not set")
+	}
+	var ev struct {
+		Type   string `json:"type"`
+		Change struct {
+			Project string `json:"project"`
+			Branch  string `json:"branch"`
+			Number  int    `json:"number"`
+		} `json:"change"`
+		PatchSet struct {
+			Revision string   `json:"revision"`
+			Ref      string   `json:"ref"`
+			Parents  []string `json:"parents"`
+		} `json:"patchSet"`
+		RefUpdate struct {
+			OldRev  string `json:"oldRev"`
+			NewRev  string `json:"newRev"`
+			RefName string `json:"refName"`
+			Project string `json:"project"`
+		} `json:"refUpdate"`
+	}
+	if err := json.Unmarshal(body, &ev); err != nil {
+		return webhookJob{}, err
+	}
+	if ev.Type == "ref-updated" {
+		u := ev.RefUpdate
+		if strings.HasPrefix(u.RefName, "refs/changes/") || strings.HasPrefix(u.RefName, "refs/meta/") {
+			return webhookJob{After: strings.Repeat("0", 40)}, nil // covered by patchset-created
+		}
+		ref := u.RefName
+		if !strings.HasPrefix(ref, "refs/") {
+			ref = "refs/heads/" + ref
+		}
+		return webhookJob{Repo: u.Project, CloneURL: s.GerritURL + "/" + u.Project, Ref: ref, Before: u.OldRev, After: u.NewRev}, nil
+	}
+	c, ps := ev.Change, ev.PatchSet
+	if c.Project == "" || ps.Revision == "" || ps.Ref == "" {
+		return webhookJob{}, errors.New("missing change.project, patchSet.revision or patchSet.ref")
+	}
+	job := webhookJob{
+		Repo:      c.Project,
+		CloneURL:  s.GerritURL + "/" + c.Project,
+		Ref:       ps.Ref,
+		After:     ps.Revision,
+		Base:      c.Branch,
+		StatusAPI: s.GerritURL,
+		ProjectID: fmt.Sprintf("%s~%d", c.Project, c.Number),
+	}
+	if len(ps.Parents) > 0 {
+		job.Before = ps.Parents[0]
+	}
+	return job, nil
+}
+
+func reportGerritReview(s *webhookServer, job webhookJob, res webhookResult) error {
+	user := os.Getenv("SNAG_GERRIT_USER")
+	if job.ProjectID == "" || user == "" {
+		return nil
+	}
+	pass := secretOrWarn("gerrit")
+	review := map[string]any{"message": "snag: " + res.Summary}
+	if s.GerritLabel != "" && res.State != webhookResultError {
+		vote := 1
+		if res.State == webhookResultFail {
+			vote = -1
+		}
+		review["labels"] = map[string]int{s.GerritLabel: vote}
+	}
+	endpoint := fmt.Sprintf("%s/a/changes/%s/revisions/%s/review", job.StatusAPI, url.PathEscape(job.ProjectID), url.PathEscape(job.After))
+	return s.send(http.MethodPost, endpoint, review, func(req *http.Request) { req.SetBasicAuth(user, pass) })
+}
+
+// statusDescription fits a summary into a forge status description.
+func statusDescription(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) > webhookDescLimit {
+		s = s[:webhookDescLimit-3] + "..."
+	}
+	return s
+}
+
+func (s *webhookServer) send(method, endpoint string, body any, auth func(*http.Request)) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(method, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	auth(req)
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: %s\n%s", method, endpoint, resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWebhookAccept(t *testing.T) {
+	s := &webhookServer{Secret: "s3cret", GerritURL: "https://review.example.com",
+		AllowHosts: []string{"git.example.com", "gl.example.com", "review.example.com"}}
+	sign := func(body string) string {
+		mac := hmac.New(sha256.New, []byte("s3cret"))
+		mac.Write([]byte(body))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+	gitea := `{"ref":"refs/heads/feat","before":"` + strings.Repeat("0", 40) + `","after":"abc1234000000000000000000000000000000000",
+		"repository":{"full_name":"org/app","clone_url":"https://git.example.com/sub/org/app.git",
+		"html_url":"https://git.example.com/sub/org/app","default_branch":"main"}}`
+	gitlab := `{"ref":"refs/heads/main","before":"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa","after":"bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb","project_id":42,
+		"project":{"path_with_namespace":"grp/app","git_http_url":"https://gl.example.com/grp/app.git","web_url":"https://gl.example.com/grp/app"}}`
+	gerrit := `{"type":"patchset-created","change":{"project":"tools/app","branch":"main","number":77},
+		"patchSet":{"revision":"cccccccccccccccccccccccccccccccccccccccc","ref":"refs/changes/77/77/2","parents":["dddddddddddddddddddddddddddddddddddddddd"]}}`
+
+	for _, tc := range []struct {
+		name    string
+		url     string
+		headers map[string]string
+		body    string
+		status  int
+		check   func(webhookJob) bool
+	}{
+		{"gitea", "/", map[string]string{"X-Gitea-Event": "push", "X-Gitea-Signature": sign(gitea)}, gitea, http.StatusAccepted,
+			func(j webhookJob) bool {
+				return j.Forge.Name == "gitea" && j.Base == "main" && j.StatusAPI == "https://git.example.com/sub/api/v1"
+			}},
+		{"gitea bad signature", "/", map[string]string{"X-Gitea-Event": "push", "X-Gitea-Signature": sign("other")}, gitea, http.StatusUnauthorized, nil},
+		{"gitlab", "/", map[string]string{"X-Gitlab-Event": "Push Hook", "X-Gitlab-Token": "s3cret"}, gitlab, http.StatusAccepted,
+			func(j webhookJob) bool {
+				return j.ProjectID == "42" && j.Before == "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" && j.StatusAPI == "https://gl.example.com/api/v4"
+			}},
+		{"gitlab bad token", "/", map[string]string{"X-Gitlab-Event": "Push Hook", "X-Gitlab-Token": "nope"}, gitlab, http.StatusUnauthorized, nil},
+		{"gerrit", "/?token=s3cret", nil, gerrit, http.StatusAccepted,
+			func(j webhookJob) bool {
+				return j.CloneURL == "https://review.example.com/tools/app" && j.Before == "dddddddddddddddddddddddddddddddddddddddd" && j.ProjectID == "tools/app~77"
+			}},
+		{"branch deletion", "/", map[string]string{"X-Gitlab-Event": "Push Hook", "X-Gitlab-Token": "s3cret"},
+			strings.Replace(gitlab, `"after":"bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"`, `"after":"`+strings.Repeat("0", 40)+`"`, 1), http.StatusNoContent, nil},
+		{"other event", "/", map[string]string{"X-Gitea-Event": "issues"}, `{}`, http.StatusNoContent, nil},
+		{"gitlab ssh clone", "/", map[string]string{"X-Gitlab-Event": "Push Hook", "X-Gitlab-Token": "s3cret"},
+			strings.Replace(gitlab, `https://gl.example.com/grp/app.git`, `git@gl.example.com:grp/app.git`, 1), http.StatusAccepted, nil},
+		{"gitlab option clone URL", "/", map[string]string{"X-Gitlab-Event": "Push Hook", "X-Gitlab-Token": "s3cret"},
+			strings.Replace(gitlab, `https://gl.example.com/grp/app.git`, `--upload-pack=touch pwned`, 1), http.StatusBadRequest, nil},
+		{"gitlab ext clone URL", "/", map[string]string{"X-Gitlab-Event": "Push Hook", "X-Gitlab-Token": "s3cret"},
+			strings.Replace(gitlab, `https://gl.example.com/grp/app.git`, `ext::sh -c touch% pwned`, 1), http.StatusBadRequest, nil},
+		{"gitlab option after", "/", map[string]string{"X-Gitlab-Event": "Push Hook", "X-Gitlab-Token": "s3cret"},
+			strings.Replace(gitlab, `"after":"`+strings.Repeat("b", 40)+`"`, `"after":"--output=pwned"`, 1), http.StatusBadRequest, nil},
+		{"gitlab short before", "/", map[string]string{"X-Gitlab-Event": "Push Hook", "X-Gitlab-Token": "s3cret"},
+			strings.Replace(gitlab, strings.Repeat("a", 40), "aaa", 1), http.StatusBadRequest, nil},
+		{"gitlab foreign host", "/", map[string]string{"X-Gitlab-Event": "Push Hook", "X-Gitlab-Token": "s3cret"},
+			strings.ReplaceAll(gitlab, `gl.example.com`, `evil.example.net`), http.StatusBadRequest, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, tc.url, strings.NewReader(tc.body))
+			for k, v := range tc.headers {
+				r.Header.Set(k, v)
+			}
+			job, status, err := s.accept(r, []byte(tc.body))
+			if status != tc.status {
+				t.Fatalf("status = %d (%v), want %d", status, err, tc.status)
+			}
+			if tc.check != nil && (job == nil || !tc.check(*job)) {
+				t.Errorf("job = %+v", job)
+			}
+		})
+	}
+}
+
+func TestWebhookFetchRejectsOptions(t *testing.T) {
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "pwned")
+	s := &webhookServer{WorkDir: dir}
+	job := webhookJob{Forge: webhookForges[0], Repo: "org/app", CloneURL: "--upload-pack=touch " + marker, Ref: "refs/heads/main"}
+	if _, err := s.fetch(job); err == nil {
+		t.Error("fetch accepted an option as the clone URL")
+	}
+	if fileExists(marker) {
+		t.Fatal("--upload-pack command ran")
+	}
+}
+
+// An option-shaped revision from a payload is refused before git sees it,
+// and check passes revisions after --end-of-options besides.
+func TestWebhookRejectsOptionRevisions(t *testing.T) {
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "pwned")
+	s := &webhookServer{Secret: "s3cret", AllowHosts: []string{"gl.example.com"}}
+	body := `{"ref":"refs/heads/main","before":"` + strings.Repeat("a", 40) + `","after":"--output=` + marker + `","project_id":42,
+		"project":{"path_with_namespace":"grp/app","git_http_url":"https://gl.example.com/grp/app.git","web_url":"https://gl.example.com/grp/app"}}`
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.Header.Set("X-Gitlab-Event", "Push Hook")
+	r.Header.Set("X-Gitlab-Token", "s3cret")
+	if job, status, err := s.accept(r, []byte(body)); status != http.StatusBadRequest || job != nil {
+		t.Fatalf("accept = %+v, %d, %v; want 400", job, status, err)
+	}
+
+	forge := initGitRepo(t)
+	initialCommit(t, forge)
+	policy := t.TempDir()
+	os.WriteFile(policy+"/snag.toml", []byte("[block]\ndiff = [\"todo\"]\n"), 0644)
+	s = &webhookServer{cmd: buildWebhookCmd(), PolicyDir: policy, WorkDir: t.TempDir()}
+	s.check(webhookJob{Forge: webhookForges[0], Repo: "org/app", CloneURL: forge, Ref: "HEAD", After: "--output=" + marker})
+	if matches, _ := filepath.Glob(marker + "*"); len(matches) > 0 {
+		t.Fatalf("git wrote %v", matches)
+	}
+}
+
+func TestWebhookServeNeedsSecret(t *testing.T) {
+	t.Setenv("SNAG_WEBHOOK_SECRET", "")
+	t.Setenv("SNAG_SECRETS_BACKEND", "command")
+	t.Setenv("SNAG_SECRETS_COMMAND", "true")
+	oldDir, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(oldDir)
+	rootCmd := buildRootCmd()
+	rootCmd.SetArgs([]string{"webhook", "serve", "--addr", "127.0.0.1:0", "--workdir", t.TempDir()})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "--insecure") {
+		t.Errorf("serve without a secret: %v", err)
+	}
+}
+
+func TestWebhookCheckReportsStatus(t *testing.T) {
+	forge := initGitRepo(t)
+	initialCommit(t, forge)
+	sha := func() string {
+		out, _ := exec.Command("git", "-C", forge, "rev-parse", "HEAD").Output()
+		return strings.TrimSpace(string(out))
+	}
+	before := sha()
+	commitFile(t, forge, "a.txt", "TODO: remove\n", "add a")
+	bad := sha()
+
+	policy := t.TempDir()
+	os.WriteFile(policy+"/snag.toml", []byte("[block]\ndiff = [\"todo\"]\n"), 0644)
+
+	var mu sync.Mutex
+	var got []map[string]string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token tok" || !strings.HasSuffix(r.URL.Path, "/api/v1/repos/org/app/statuses/"+bad) {
+			http.Error(w, "unexpected "+r.URL.Path, http.StatusBadRequest)
+			return
+		}
+		var status map[string]string
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &status)
+		mu.Lock()
+		got = append(got, status)
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer api.Close()
+	t.Setenv("SNAG_GITEA_TOKEN", "tok")
+
+	s := &webhookServer{cmd: buildWebhookCmd(), PolicyDir: policy, WorkDir: t.TempDir(), Client: api.Client()}
+	job := webhookJob{
+		Forge: webhookForges[0], Repo: "org/app", CloneURL: forge, Ref: "HEAD",
+		Before: before, After: bad, StatusAPI: api.URL + "/api/v1",
+	}
+	s.handle(job)
+
+	if len(got) != 1 || got[0]["state"] != webhookResultFail || got[0]["context"] != "snag" ||
+		!strings.Contains(got[0]["description"], "todo") {
+		t.Fatalf("statuses = %v, want one todo failure", got)
+	}
+
+	// The same commit judged against a clean range passes.
+	job.Before, job.After = bad, bad
+	if res := s.check(job); res.State != webhookResultPass {
+		t.Errorf("empty range: %+v", res)
+	}
+}
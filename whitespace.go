@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// isWhitespaceOnlyDiff reports whether every file in diff changes nothing
+// but whitespace: with all whitespace removed, its removed and added lines
+// are identical. Files without hunks (renames, mode changes, binaries) are
+// real changes, and an empty diff is not whitespace-only.
+func isWhitespaceOnlyDiff(diff string) bool {
+	files := splitDiffFiles(diff)
+	if len(files) == 0 {
+		return false
+	}
+	for _, f := range files {
+		var removed, added strings.Builder
+		changed := false
+		for _, line := range strings.Split(stripDiffMeta(f.Body), "\n") {
+			switch {
+			case strings.HasPrefix(line, "-"):
+				removed.WriteString(stripSpace(line[1:]))
+			case strings.HasPrefix(line, "+"):
+				added.WriteString(stripSpace(line[1:]))
+			default:
+				continue
+			}
+			changed = true
+		}
+		if !changed || removed.String() != added.String() {
+			return false
+		}
+	}
+	return true
+}
+
+func stripSpace(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsWhitespaceOnlyDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		diff string
+		want bool
+	}{
+		{"empty", "", false},
+		{"reindent", "diff --git a/x.go b/x.go\n--- a/x.go\n+++ b/x.go\n@@ -1 +1 @@\n-\tfoo()\n+    foo()\n", true},
+		{"trailing space and blank line", "diff --git a/x b/x\n--- a/x\n+++ b/x\n@@ -1 +1,2 @@\n-a \n+a\n+\n", true},
+		{"real change", "diff --git a/x b/x\n--- a/x\n+++ b/x\n@@ -1 +1 @@\n-a\n+b\n", false},
+		{"one real file among whitespace", "diff --git a/x b/x\n--- a/x\n+++ b/x\n@@ -1 +1 @@\n-a \n+a\ndiff --git a/y b/y\n--- a/y\n+++ b/y\n@@ -1 +1 @@\n-a\n+b\n", false},
+		{"pure rename", "diff --git a/x b/y\nsimilarity index 100%\nrename from x\nrename to y\n", false},
+		{"binary", "diff --git a/x.png b/x.png\nindex 1..2 100644\nBinary files a/x.png and b/x.png differ\n", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isWhitespaceOnlyDiff(tc.diff); got != tc.want {
+				t.Errorf("isWhitespaceOnlyDiff = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRunDiff_WhitespaceOnly(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\nwhitespace_only = true\n"), 0644)
+	commitFile(t, dir, "x.go", "func f() {\n\treturn\n}\n", "add x")
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	run := func() error {
+		rootCmd := buildRootCmd()
+		rootCmd.SetArgs([]string{"check", "diff", "-q"})
+		return rootCmd.Execute()
+	}
+
+	stageFile(t, dir, "x.go", "func f() {\n    return\n}\n")
+	err := run()
+	if err == nil || !strings.Contains(err.Error(), "whitespace-only") {
+		t.Fatalf("expected whitespace-only violation, got %v", err)
+	}
+
+	stageFile(t, dir, "x.go", "func f() {\n    return nil\n}\n")
+	if err := run(); err != nil {
+		t.Errorf("real change blocked: %v", err)
+	}
+}
+
+func TestRunPush_EmptyCommit(t *testing.T) {
+	dir := initGitRepo(t)
+	initialCommit(t, dir)
+	initBareRemote(t, dir)
+	os.WriteFile(filepath.Join(dir, "snag.toml"), []byte("[block]\nempty = true\n"), 0644)
+
+	oldDir, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(oldDir)
+
+	run := func() error {
+		rootCmd := buildRootCmd()
+		rootCmd.SetArgs([]string{"check", "push", "-q"})
+		return rootCmd.Execute()
+	}
+
+	commitFile(t, dir, "a.txt", "a\n", "add a")
+	if err := run(); err != nil {
+		t.Fatalf("normal commit blocked: %v", err)
+	}
+
+	if out, err := exec.Command("git", "commit", "--allow-empty", "-m", "nothing").CombinedOutput(); err != nil {
+		t.Fatalf("git commit: %v\n%s", err, out)
+	}
+	err := run()
+	if err == nil || !strings.Contains(err.Error(), "empty commit") {
+		t.Errorf("expected empty commit violation, got %v", err)
+	}
+}
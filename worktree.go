@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// worktreeConfigName is the per-worktree override file, kept in the
+// worktree's own git dir so it is never committed or shared: .git/snag/ in
+// the main worktree, .git/worktrees/NAME/snag/ in a linked one.
+const worktreeConfigName = "worktree.toml"
+
+// worktreeConfigPath returns where dir's worktree.toml would live, or ""
+// when dir is not inside a git work tree.
+func worktreeConfigPath(dir string) string {
+	out, err := gitCommand("-C", dir, "rev-parse", "--absolute-git-dir").Output()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(strings.TrimSpace(string(out)), "snag", worktreeConfigName)
+}
+
+// mergeWorktreeConfig merges dir's worktree.toml, if any, after the rest of
+// the config chain. Like snag-local.toml its scalar settings win, and its
+// ignore entries drop patterns before SNAG_IGNORE is applied.
+func mergeWorktreeConfig(bc *BlockConfig, dir string) (bool, error) {
+	path := worktreeConfigPath(dir)
+	if path == "" || !fileExists(path) {
+		return false, nil
+	}
+	if err := mergeTOML(bc, path, true); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// validateIgnore checks ignore entries, which use SNAG_IGNORE's syntax:
+// "<phase>" or "<phase>:<pattern>". Only worktree.toml may set them, since a
+// committed ignore would silently unblock every clone.
+func validateIgnore(path string, entries []string) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	if filepath.Base(path) != worktreeConfigName {
+		return fmt.Errorf("ignore is only allowed in .git/snag/%s", worktreeConfigName)
+	}
+	for _, e := range entries {
+		phase, _, _ := strings.Cut(strings.TrimSpace(e), ":")
+		switch strings.ToLower(phase) {
+		case "diff", "msg", "push", "branch":
+		default:
+			return fmt.Errorf("ignore entry %q: phase must be diff, msg, push, or branch", e)
+		}
+	}
+	return nil
+}
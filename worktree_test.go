@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func writeWorktreeConfig(t *testing.T, dir, content string) {
+	t.Helper()
+	path := worktreeConfigPath(dir)
+	if path == "" {
+		t.Fatalf("%s is not a git work tree", dir)
+	}
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWorktreeConfig_OverridesRepoConfig(t *testing.T) {
+	dir := initGitRepo(t)
+	os.WriteFile(filepath.Join(dir, "snag.toml"),
+		[]byte("[block]\ndiff = [\"todo\", \"fixme\"]\n\n[audit]\nlimit = 3\n"), 0644)
+	writeWorktreeConfig(t, dir, "ignore = [\"diff:TODO\"]\n\n[audit]\nlimit = 50\n")
+
+	bc, err := resolveBlockConfigAt(nil, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(bc.Diff, []string{"fixme"}) {
+		t.Errorf("diff = %v, want [fixme]", bc.Diff)
+	}
+	if bc.AuditLimit == nil || *bc.AuditLimit != 50 {
+		t.Errorf("audit limit = %v, want the worktree's 50", bc.AuditLimit)
+	}
+
+	// SNAG_IGNORE still applies on top.
+	t.Setenv("SNAG_IGNORE", "diff")
+	if bc, _ = resolveBlockConfigAt(nil, dir); len(bc.Diff) != 0 {
+		t.Errorf("diff = %v, want SNAG_IGNORE to clear it", bc.Diff)
+	}
+}
+
+func TestWorktreeConfig_LinkedWorktreeOnly(t *testing.T) {
+	dir := initGitRepo(t)
+	commitFile(t, dir, "snag.toml", "[block]\ndiff = [\"todo\"]\n", "add config")
+
+	scratch := filepath.Join(t.TempDir(), "scratch tree")
+	if out, err := exec.Command("git", "-C", dir, "worktree", "add", "-q", "--detach", scratch).CombinedOutput(); err != nil {
+		t.Fatalf("git worktree add: %v\n%s", err, out)
+	}
+	if got := worktreeConfigPath(scratch); !strings.Contains(filepath.ToSlash(got), "/worktrees/") {
+		t.Fatalf("linked worktree config path = %q, want one under .git/worktrees", got)
+	}
+	if bc, _ := resolveBlockConfigAt(nil, scratch); !slices.Equal(bc.Diff, []string{"todo"}) {
+		t.Fatalf("scratch worktree before override: diff = %v, want [todo]", bc.Diff)
+	}
+	writeWorktreeConfig(t, scratch, "ignore = [\"diff\"]\n")
+
+	if bc, _ := resolveBlockConfigAt(nil, scratch); len(bc.Diff) != 0 {
+		t.Errorf("scratch worktree: diff = %v, want none", bc.Diff)
+	}
+	if bc, _ := resolveBlockConfigAt(nil, dir); !slices.Equal(bc.Diff, []string{"todo"}) {
+		t.Errorf("main worktree: diff = %v, want [todo]", bc.Diff)
+	}
+}
+
+func TestLoadSnagTOML_IgnoreValidation(t *testing.T) {
+	dir := t.TempDir()
+	repo := filepath.Join(dir, "snag.toml")
+	os.WriteFile(repo, []byte("ignore = [\"diff\"]\n"), 0644)
+	if _, err := loadSnagTOML(repo); err == nil {
+		t.Error("ignore in snag.toml should be rejected")
+	}
+	wt := filepath.Join(dir, worktreeConfigName)
+	os.WriteFile(wt, []byte("ignore = [\"commits:todo\"]\n"), 0644)
+	if _, err := loadSnagTOML(wt); err == nil {
+		t.Error("expected an error for an unknown ignore phase")
+	}
+}